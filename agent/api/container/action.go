@@ -0,0 +1,40 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package container
+
+const defaultHTTPGetActionPath = "/"
+
+// ExecAction is a command the agent runs inside the container
+type ExecAction struct {
+	Command []string `json:"command"`
+}
+
+// HTTPGetAction is an HTTP endpoint on the container the agent sends a GET request to
+type HTTPGetAction struct {
+	Port uint16 `json:"port"`
+	Path string `json:"path,omitempty"`
+}
+
+// TCPSocketAction is a port on the container the agent opens a TCP connection to
+type TCPSocketAction struct {
+	Port uint16 `json:"port"`
+}
+
+// GetPath returns the request path of the HTTP action
+func (action *HTTPGetAction) GetPath() string {
+	if action.Path == "" {
+		return defaultHTTPGetActionPath
+	}
+	return action.Path
+}
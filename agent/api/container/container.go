@@ -30,6 +30,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/tmds"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/cihub/seelog"
 	"github.com/docker/docker/api/types"
@@ -77,6 +78,12 @@ const (
 	// AgentURIFormat defines the URI format for Agent endpoints
 	AgentURIFormat = "http://169.254.170.2/api/%s"
 
+	// MetadataURIFormatIPv6, MetadataURIFormatV4IPv6 and AgentURIFormatIPv6 define the URI formats of
+	// the v3 and v4 metadata endpoints and of the Agent endpoints for containers that reach them over IPv6
+	MetadataURIFormatIPv6   = "http://[" + tmds.IPv6 + "]/v3/%s"
+	MetadataURIFormatV4IPv6 = "http://[" + tmds.IPv6 + "]/v4/%s"
+	AgentURIFormatIPv6      = "http://[" + tmds.IPv6 + "]/api/%s"
+
 	// SecretProviderSSM is to show secret provider being SSM
 	SecretProviderSSM = "ssm"
 
@@ -86,6 +93,10 @@ const (
 	// SecretTypeEnv is to show secret type being ENVIRONMENT_VARIABLE
 	SecretTypeEnv = "ENVIRONMENT_VARIABLE"
 
+	// SecretTypeMountPoint is to show secret type being MOUNT_POINT, which writes the secret to a file
+	// that is mounted into the container
+	SecretTypeMountPoint = "MOUNT_POINT"
+
 	// SecretTargetLogDriver is to show secret target being "LOG_DRIVER", the default will be "CONTAINER"
 	SecretTargetLogDriver = "LOG_DRIVER"
 
@@ -337,6 +348,9 @@ type Container struct {
 
 	labels map[string]string
 
+	// readinessProbeRunning is set while the engine is probing the container for readiness
+	readinessProbeRunning bool
+
 	// ContainerHasPortRange is set to true when the container has at least 1 port range requested.
 	ContainerHasPortRange bool
 	// ContainerPortSet is a set of singular container ports that don't belong to a containerPortRange request
@@ -344,8 +358,19 @@ type Container struct {
 	// ContainerPortRangeMap is a map of containerPortRange to its associated hostPortRange
 	ContainerPortRangeMap map[string]string
 
+	// PreStopHook is run against the container before it is sent the stop signal
+	PreStopHook *PreStopHook `json:"preStopHook,omitempty"`
+	// ReadinessProbe is run by the agent against the container until it succeeds
+	ReadinessProbe *ReadinessProbe `json:"readinessProbe,omitempty"`
+	// ReadinessStatus is the result of the readiness probe
+	ReadinessStatus ReadinessStatus `json:"readinessStatus,omitempty"`
+
 	// RestartPolicy is an object representing the restart policy of the container
 	RestartPolicy *restart.RestartPolicy `json:"restartPolicy,omitempty"`
+
+	// RoleArn is the role of the container, which is assumed with the task role credentials. The
+	// container is given the credentials of this role instead of those of the task role.
+	RoleArn string `json:"roleArn,omitempty"`
 	// RestartTracker tracks this container's restart policy metadata, such
 	// as restart count and last restart time. This is only initialized if the container
 	// has a restart policy defined and enabled.
@@ -382,6 +407,9 @@ type DockerContainer struct {
 type EnvironmentFile struct {
 	Value string `json:"value"`
 	Type  string `json:"type"`
+	// Templated indicates that the values of the environment file can reference task metadata and
+	// other variables, which are resolved when the container is created
+	Templated bool `json:"templated,omitempty"`
 }
 
 // MountPoint describes the in-container location of a Volume and references
@@ -413,6 +441,12 @@ type Secret struct {
 	Type          string `json:"type"`
 	Provider      string `json:"provider"`
 	Target        string `json:"target"`
+	// FileMode is the octal mode of the file of a MOUNT_POINT secret, "0400" if unset.
+	FileMode string `json:"fileMode,omitempty"`
+	// UID and GID own the file of a MOUNT_POINT secret. They default to the container user when it
+	// is numeric, and to root otherwise.
+	UID *int64 `json:"uid,omitempty"`
+	GID *int64 `json:"gid,omitempty"`
 }
 
 // GetSecretResourceCacheKey returns the key required to access the secret
@@ -876,6 +910,15 @@ func (c *Container) SetHealthStatus(health HealthStatus) {
 	}
 }
 
+// ResetHealthStatus clears the container health information, so that a restarted
+// container is not considered healthy or unhealthy based on its previous run
+func (c *Container) ResetHealthStatus() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.Health = HealthStatus{}
+}
+
 // GetHealthStatus returns the container health information
 func (c *Container) GetHealthStatus() HealthStatus {
 	c.lock.RLock()
@@ -1035,6 +1078,17 @@ func (c *Container) InjectV1AgentAPIEndpoint() {
 	c.Environment[AgentURIEnvVarName] = fmt.Sprintf(AgentURIFormat, c.V3EndpointID)
 }
 
+// InjectIPv6MetadataEndpoints replaces the metadata and Agent API endpoints injected into the container
+// by their IPv6 counterparts, for containers that cannot reach the IPv4 link-local endpoints.
+func (c *Container) InjectIPv6MetadataEndpoints() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.ensureEnvironmentIsInitialized()
+	c.Environment[MetadataURIEnvironmentVariableName] = fmt.Sprintf(MetadataURIFormatIPv6, c.V3EndpointID)
+	c.Environment[MetadataURIEnvVarNameV4] = fmt.Sprintf(MetadataURIFormatV4IPv6, c.V3EndpointID)
+	c.Environment[AgentURIEnvVarName] = fmt.Sprintf(AgentURIFormatIPv6, c.V3EndpointID)
+}
+
 // Initializes Environment Map if it is nil
 func (c *Container) ensureEnvironmentIsInitialized() {
 	if c.Environment == nil {
@@ -1093,6 +1147,19 @@ func (c *Container) ShouldCreateWithEnvFiles() bool {
 	return len(c.EnvironmentFiles) != 0
 }
 
+// HasTemplatedEnvfiles returns true if any of the environment files of the container is templated
+func (c *Container) HasTemplatedEnvfiles() bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	for _, envfile := range c.EnvironmentFiles {
+		if envfile.Templated {
+			return true
+		}
+	}
+	return false
+}
+
 // MergeEnvironmentVariables appends additional envVarName:envVarValue pairs to
 // the the container's environment values structure
 func (c *Container) MergeEnvironmentVariables(envVars map[string]string) {
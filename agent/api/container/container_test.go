@@ -252,6 +252,13 @@ func TestSetHealthStatus(t *testing.T) {
 	assert.Equal(t, health3.Status, apicontainerstatus.ContainerUnhealthy)
 	assert.Equal(t, health3.ExitCode, 1)
 	assert.NotEqual(t, health3.Since, health2.Since)
+
+	// resetting the health status clears it
+	container.ResetHealthStatus()
+	health4 := container.GetHealthStatus()
+	assert.Equal(t, apicontainerstatus.ContainerHealthUnknown, health4.Status)
+	assert.Nil(t, health4.Since)
+	assert.Zero(t, health4.ExitCode)
 }
 
 func TestHealthStatusShouldBeReported(t *testing.T) {
@@ -824,6 +831,19 @@ func TestShouldCreateWithEnvfiles(t *testing.T) {
 	}
 }
 
+func TestHasTemplatedEnvfiles(t *testing.T) {
+	container := &Container{
+		EnvironmentFiles: []EnvironmentFile{
+			{Value: "s3://bucket/envfile", Type: "s3"},
+		},
+	}
+	assert.False(t, container.HasTemplatedEnvfiles())
+
+	container.EnvironmentFiles = append(container.EnvironmentFiles,
+		EnvironmentFile{Value: "s3://bucket/templated", Type: "s3", Templated: true})
+	assert.True(t, container.HasTemplatedEnvfiles())
+}
+
 func TestMergeEnvironmentVariablesFromEnvfiles(t *testing.T) {
 	cases := []struct {
 		Name                   string
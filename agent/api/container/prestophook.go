@@ -0,0 +1,73 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package container
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	// DefaultPreStopHookTimeout is the time a pre-stop hook is allowed to run when
+	// the hook does not specify a timeout
+	DefaultPreStopHookTimeout = 30 * time.Second
+)
+
+// PreStopHook is an action run by the agent against a running container before the
+// container is sent its stop signal, so that the application can drain connections
+// or deregister from service discovery. Exactly one of Exec and HTTPGet is set.
+type PreStopHook struct {
+	// Exec runs a command inside the container
+	Exec *ExecAction `json:"exec,omitempty"`
+	// HTTPGet sends an HTTP GET request to the container
+	HTTPGet *HTTPGetAction `json:"httpGet,omitempty"`
+	// Timeout is the number of seconds the hook is allowed to run before the
+	// container is stopped regardless
+	Timeout uint `json:"timeout,omitempty"`
+}
+
+// Validate checks that the pre-stop hook defines exactly one usable action
+func (hook *PreStopHook) Validate() error {
+	switch {
+	case hook.Exec != nil && hook.HTTPGet != nil:
+		return errors.New("pre-stop hook must define only one of exec and httpGet")
+	case hook.Exec != nil:
+		if len(hook.Exec.Command) == 0 {
+			return errors.New("pre-stop hook exec command is empty")
+		}
+	case hook.HTTPGet != nil:
+		if hook.HTTPGet.Port == 0 {
+			return errors.New("pre-stop hook httpGet port is not set")
+		}
+	default:
+		return errors.New("pre-stop hook must define one of exec and httpGet")
+	}
+	return nil
+}
+
+// GetTimeout returns the duration the pre-stop hook is allowed to run
+func (hook *PreStopHook) GetTimeout() time.Duration {
+	if hook.Timeout == 0 {
+		return DefaultPreStopHookTimeout
+	}
+	return time.Duration(hook.Timeout) * time.Second
+}
+
+// GetPreStopHook returns the pre-stop hook of the container
+func (c *Container) GetPreStopHook() *PreStopHook {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.PreStopHook
+}
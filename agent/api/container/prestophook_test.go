@@ -0,0 +1,98 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package container
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreStopHookValidate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		hook        PreStopHook
+		expectError bool
+	}{
+		{
+			name: "exec",
+			hook: PreStopHook{Exec: &ExecAction{Command: []string{"/bin/deregister"}}},
+		},
+		{
+			name: "http get",
+			hook: PreStopHook{HTTPGet: &HTTPGetAction{Port: 8080}},
+		},
+		{
+			name:        "no action",
+			hook:        PreStopHook{Timeout: 10},
+			expectError: true,
+		},
+		{
+			name: "both actions",
+			hook: PreStopHook{
+				Exec:    &ExecAction{Command: []string{"/bin/deregister"}},
+				HTTPGet: &HTTPGetAction{Port: 8080},
+			},
+			expectError: true,
+		},
+		{
+			name:        "empty command",
+			hook:        PreStopHook{Exec: &ExecAction{}},
+			expectError: true,
+		},
+		{
+			name:        "missing port",
+			hook:        PreStopHook{HTTPGet: &HTTPGetAction{Path: "/drain"}},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.hook.Validate()
+			if tc.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPreStopHookDefaults(t *testing.T) {
+	hook := &PreStopHook{HTTPGet: &HTTPGetAction{Port: 8080}}
+	assert.Equal(t, DefaultPreStopHookTimeout, hook.GetTimeout())
+	assert.Equal(t, "/", hook.HTTPGet.GetPath())
+
+	hook = &PreStopHook{HTTPGet: &HTTPGetAction{Port: 8080, Path: "/drain"}, Timeout: 5}
+	assert.Equal(t, 5*time.Second, hook.GetTimeout())
+	assert.Equal(t, "/drain", hook.HTTPGet.GetPath())
+}
+
+func TestPreStopHookUnmarshal(t *testing.T) {
+	container := &Container{}
+	err := json.Unmarshal([]byte(`{"name": "app", "preStopHook": {"exec": {"command": ["sh", "-c", "sleep 1"]}, "timeout": 20}}`),
+		container)
+	require.NoError(t, err)
+	hook := container.GetPreStopHook()
+	require.NotNil(t, hook)
+	assert.Equal(t, []string{"sh", "-c", "sleep 1"}, hook.Exec.Command)
+	assert.Equal(t, 20*time.Second, hook.GetTimeout())
+}
@@ -0,0 +1,172 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package container
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	// DefaultReadinessProbeInterval is the time between two attempts of a readiness probe
+	DefaultReadinessProbeInterval = 5 * time.Second
+	// DefaultReadinessProbeTimeout is the time a single attempt of a readiness probe is allowed to take
+	DefaultReadinessProbeTimeout = 5 * time.Second
+)
+
+// ReadinessProbe is a check executed by the agent against a running container until it
+// succeeds once. Containers can depend on another container with the READY condition to
+// wait for its probe to succeed, which does not require a HEALTHCHECK in the image.
+// Exactly one of Exec, HTTPGet and TCPSocket is set.
+type ReadinessProbe struct {
+	// Exec runs a command inside the container, the probe succeeds if it exits with 0
+	Exec *ExecAction `json:"exec,omitempty"`
+	// HTTPGet sends an HTTP GET request to the container, the probe succeeds on a 2xx or 3xx response
+	HTTPGet *HTTPGetAction `json:"httpGet,omitempty"`
+	// TCPSocket opens a TCP connection to the container, the probe succeeds if the connection is accepted
+	TCPSocket *TCPSocketAction `json:"tcpSocket,omitempty"`
+	// Interval is the number of seconds between two probe attempts
+	Interval uint `json:"interval,omitempty"`
+	// Timeout is the number of seconds a single probe attempt is allowed to take
+	Timeout uint `json:"timeout,omitempty"`
+}
+
+// ReadinessStatus contains the result of the readiness probe of a container
+type ReadinessStatus struct {
+	// Ready is set once the probe has succeeded
+	Ready bool `json:"ready"`
+	// Since is when the container became ready
+	Since *time.Time `json:"since,omitempty"`
+	// LastProbedAt is when the probe last ran
+	LastProbedAt *time.Time `json:"lastProbedAt,omitempty"`
+	// Attempts is the number of times the probe ran
+	Attempts int `json:"attempts,omitempty"`
+	// Output is the error of the last failed probe attempt
+	Output string `json:"output,omitempty"`
+}
+
+// Validate checks that the readiness probe defines exactly one usable action
+func (probe *ReadinessProbe) Validate() error {
+	actions := 0
+	if probe.Exec != nil {
+		actions++
+		if len(probe.Exec.Command) == 0 {
+			return errors.New("readiness probe exec command is empty")
+		}
+	}
+	if probe.HTTPGet != nil {
+		actions++
+		if probe.HTTPGet.Port == 0 {
+			return errors.New("readiness probe httpGet port is not set")
+		}
+	}
+	if probe.TCPSocket != nil {
+		actions++
+		if probe.TCPSocket.Port == 0 {
+			return errors.New("readiness probe tcpSocket port is not set")
+		}
+	}
+	if actions != 1 {
+		return errors.New("readiness probe must define exactly one of exec, httpGet and tcpSocket")
+	}
+	return nil
+}
+
+// GetInterval returns the time between two probe attempts
+func (probe *ReadinessProbe) GetInterval() time.Duration {
+	if probe.Interval == 0 {
+		return DefaultReadinessProbeInterval
+	}
+	return time.Duration(probe.Interval) * time.Second
+}
+
+// GetTimeout returns the time a single probe attempt is allowed to take
+func (probe *ReadinessProbe) GetTimeout() time.Duration {
+	if probe.Timeout == 0 {
+		return DefaultReadinessProbeTimeout
+	}
+	return time.Duration(probe.Timeout) * time.Second
+}
+
+// GetReadinessProbe returns the readiness probe of the container
+func (c *Container) GetReadinessProbe() *ReadinessProbe {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.ReadinessProbe
+}
+
+// HasReadinessProbe returns true if the container defines a readiness probe
+func (c *Container) HasReadinessProbe() bool {
+	return c.GetReadinessProbe() != nil
+}
+
+// RecordReadinessProbeResult records the result of a readiness probe attempt. A nil
+// error marks the container ready; once ready, the container stays ready.
+func (c *Container) RecordReadinessProbeResult(err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := time.Now()
+	c.ReadinessStatus.LastProbedAt = &now
+	c.ReadinessStatus.Attempts++
+	if err != nil {
+		c.ReadinessStatus.Output = err.Error()
+		return
+	}
+	c.ReadinessStatus.Output = ""
+	if !c.ReadinessStatus.Ready {
+		c.ReadinessStatus.Ready = true
+		c.ReadinessStatus.Since = &now
+	}
+}
+
+// GetReadinessStatus returns a copy of the readiness probe status of the container
+func (c *Container) GetReadinessStatus() ReadinessStatus {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	status := c.ReadinessStatus
+	if status.Since != nil {
+		since := *status.Since
+		status.Since = &since
+	}
+	if status.LastProbedAt != nil {
+		lastProbedAt := *status.LastProbedAt
+		status.LastProbedAt = &lastProbedAt
+	}
+	return status
+}
+
+// IsReady returns true if the readiness probe of the container has succeeded
+func (c *Container) IsReady() bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.ReadinessStatus.Ready
+}
+
+// SetReadinessProbeRunning marks whether a readiness prober is running for the container.
+// It returns false if the prober was already in the requested state, so that only
+// one prober is ever started per container.
+func (c *Container) SetReadinessProbeRunning(running bool) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.readinessProbeRunning == running {
+		return false
+	}
+	c.readinessProbeRunning = running
+	return true
+}
@@ -0,0 +1,111 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package container
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadinessProbeValidate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		probe       ReadinessProbe
+		expectError bool
+	}{
+		{
+			name:  "exec",
+			probe: ReadinessProbe{Exec: &ExecAction{Command: []string{"/bin/ready"}}},
+		},
+		{
+			name:  "http get",
+			probe: ReadinessProbe{HTTPGet: &HTTPGetAction{Port: 8080, Path: "/ready"}},
+		},
+		{
+			name:  "tcp socket",
+			probe: ReadinessProbe{TCPSocket: &TCPSocketAction{Port: 5432}},
+		},
+		{
+			name:        "no action",
+			probe:       ReadinessProbe{Interval: 1},
+			expectError: true,
+		},
+		{
+			name: "multiple actions",
+			probe: ReadinessProbe{
+				HTTPGet:   &HTTPGetAction{Port: 8080},
+				TCPSocket: &TCPSocketAction{Port: 8080},
+			},
+			expectError: true,
+		},
+		{
+			name:        "missing tcp port",
+			probe:       ReadinessProbe{TCPSocket: &TCPSocketAction{}},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.probe.Validate()
+			if tc.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestReadinessProbeDefaults(t *testing.T) {
+	probe := &ReadinessProbe{}
+	assert.Equal(t, DefaultReadinessProbeInterval, probe.GetInterval())
+	assert.Equal(t, DefaultReadinessProbeTimeout, probe.GetTimeout())
+
+	probe = &ReadinessProbe{Interval: 2, Timeout: 1}
+	assert.Equal(t, 2*time.Second, probe.GetInterval())
+	assert.Equal(t, time.Second, probe.GetTimeout())
+}
+
+func TestRecordReadinessProbeResult(t *testing.T) {
+	container := &Container{}
+	assert.False(t, container.IsReady())
+
+	container.RecordReadinessProbeResult(errors.New("connection refused"))
+	status := container.GetReadinessStatus()
+	assert.False(t, status.Ready)
+	assert.Equal(t, 1, status.Attempts)
+	assert.Equal(t, "connection refused", status.Output)
+	assert.Nil(t, status.Since)
+
+	container.RecordReadinessProbeResult(nil)
+	status = container.GetReadinessStatus()
+	assert.True(t, container.IsReady())
+	assert.Equal(t, 2, status.Attempts)
+	assert.Empty(t, status.Output)
+	assert.NotNil(t, status.Since)
+}
+
+func TestSetReadinessProbeRunning(t *testing.T) {
+	container := &Container{}
+	assert.True(t, container.SetReadinessProbeRunning(true))
+	assert.False(t, container.SetReadinessProbeRunning(true))
+	assert.True(t, container.SetReadinessProbeRunning(false))
+}
@@ -0,0 +1,110 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package task
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/amazon-ecs-agent/agent/api/serviceconnect"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/pkg/errors"
+)
+
+const (
+	// dryRunServiceConnectContainerName is the name of the Service Connect container that ECS adds
+	// to the tasks of a task definition.
+	dryRunServiceConnectContainerName = "ecs-service-connect"
+	dryRunTaskARNFormat               = "arn:aws:ecs:us-east-1:000000000000:task/dry-run/%s"
+	dryRunAttachmentARN               = "arn:aws:ecs:us-east-1:000000000000:attachment/dry-run"
+)
+
+// taskDefinition is the part of a task definition, in the JSON format of the ECS API, that the
+// Service Connect configuration of its tasks depends on.
+type taskDefinition struct {
+	Family               string                `json:"family"`
+	Revision             int64                 `json:"revision"`
+	NetworkMode          string                `json:"networkMode"`
+	ContainerDefinitions []containerDefinition `json:"containerDefinitions"`
+}
+
+type containerDefinition struct {
+	Name         string                `json:"name"`
+	Essential    *bool                 `json:"essential"`
+	PortMappings []*ecsacs.PortMapping `json:"portMappings"`
+}
+
+// ServiceConnectTaskFromTaskDefinition returns the ACS task that ECS would send for a task of the
+// task definition, with the Service Connect config that ECS delivers for its service. The task
+// definition is in the JSON format of the ECS API, either bare or in the "taskDefinition" field of a
+// DescribeTaskDefinition response. The task has no elastic network interface.
+func ServiceConnectTaskFromTaskDefinition(data []byte, scConfig string) (*ecsacs.Task, error) {
+	var described struct {
+		TaskDefinition *taskDefinition `json:"taskDefinition"`
+	}
+	if err := json.Unmarshal(data, &described); err != nil {
+		return nil, errors.Wrap(err, "invalid task definition")
+	}
+	taskDef := described.TaskDefinition
+	if taskDef == nil {
+		taskDef = &taskDefinition{}
+		if err := json.Unmarshal(data, taskDef); err != nil {
+			return nil, errors.Wrap(err, "invalid task definition")
+		}
+	}
+	if len(taskDef.ContainerDefinitions) == 0 {
+		return nil, errors.New("task definition has no container definitions")
+	}
+	if taskDef.NetworkMode == "" {
+		taskDef.NetworkMode = BridgeNetworkMode
+	}
+
+	acsTask := &ecsacs.Task{
+		Arn:           aws.String(fmt.Sprintf(dryRunTaskARNFormat, taskDef.Family)),
+		DesiredStatus: aws.String("RUNNING"),
+		Family:        aws.String(taskDef.Family),
+		Version:       aws.String(strconv.FormatInt(taskDef.Revision, 10)),
+		NetworkMode:   aws.String(taskDef.NetworkMode),
+		Attachments: []*ecsacs.Attachment{
+			{
+				AttachmentArn:  aws.String(dryRunAttachmentARN),
+				AttachmentType: aws.String(serviceConnectAttachmentType),
+				AttachmentProperties: []*ecsacs.AttachmentProperty{
+					{
+						Name:  aws.String(serviceconnect.GetServiceConnectContainerNameKey()),
+						Value: aws.String(dryRunServiceConnectContainerName),
+					},
+					{
+						Name:  aws.String(serviceconnect.GetServiceConnectConfigKey()),
+						Value: aws.String(scConfig),
+					},
+				},
+			},
+		},
+	}
+	for _, containerDef := range taskDef.ContainerDefinitions {
+		acsTask.Containers = append(acsTask.Containers, &ecsacs.Container{
+			Name:         aws.String(containerDef.Name),
+			Essential:    containerDef.Essential,
+			PortMappings: containerDef.PortMappings,
+		})
+	}
+	acsTask.Containers = append(acsTask.Containers, &ecsacs.Container{
+		Name: aws.String(dryRunServiceConnectContainerName),
+	})
+	return acsTask, nil
+}
@@ -0,0 +1,92 @@
+//go:build linux
+// +build linux
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package task
+
+import (
+	"encoding/json"
+
+	"github.com/aws/amazon-ecs-agent/agent/api/serviceconnect"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/ecscni"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
+	ni "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+
+	"github.com/pkg/errors"
+)
+
+// ServiceConnectDryRun describes what the agent would set up for the Service Connect configuration
+// of a task.
+type ServiceConnectDryRun struct {
+	// Config is the validated Service Connect config of the task, with its listener ports assigned.
+	Config *serviceconnect.Config `json:"config"`
+	// ListenerPortMapping is the listener port mapping passed to the Service Connect container.
+	ListenerPortMapping map[string]uint16 `json:"listenerPortMapping,omitempty"`
+	// CNINetworkConfig is the ecs-serviceconnect CNI plugin config of the task network namespace.
+	CNINetworkConfig json.RawMessage `json:"cniNetworkConfig,omitempty"`
+	// NetfilterRules are the iptables commands the ecs-serviceconnect CNI plugin runs for its config.
+	NetfilterRules []string `json:"netfilterRules,omitempty"`
+}
+
+// DryRunServiceConnect validates the Service Connect configuration of an ACS task the same way it
+// is validated when the task is accepted, and returns the listener config, the CNI plugin config and
+// the netfilter rules the CNI plugin would set up for it, without setting anything up. Listener ports
+// that ACS leaves unset are picked from the ephemeral port range, so they can differ between runs.
+//
+// The CNI plugin config and its rules are only returned for awsvpc tasks with an elastic network
+// interface. For bridge mode tasks, they depend on the addresses docker assigns to the Service Connect
+// pause container.
+func DryRunServiceConnect(acsTask *ecsacs.Task) (*ServiceConnectDryRun, error) {
+	task, err := TaskFromACS(acsTask, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !task.IsServiceConnectEnabled() {
+		return nil, errors.New("task has no service connect configuration")
+	}
+	for _, acsENI := range acsTask.ElasticNetworkInterfaces {
+		eni, err := ni.InterfaceFromACS(acsENI)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid elastic network interface")
+		}
+		task.AddTaskENI(eni)
+	}
+	if err := task.initServiceConnectEphemeralPorts(); err != nil {
+		return nil, err
+	}
+
+	dryRun := &ServiceConnectDryRun{Config: task.ServiceConnectConfig}
+	portMapping := task.GetServiceConnectContainer().Environment[serviceConnectListenerPortMappingEnvVar]
+	if err := json.Unmarshal([]byte(portMapping), &dryRun.ListenerPortMapping); err != nil {
+		return nil, errors.Wrap(err, "invalid listener port mapping")
+	}
+	if !task.IsNetworkModeAWSVPC() || task.GetPrimaryENI() == nil {
+		return dryRun, nil
+	}
+
+	_, netconf, err := ecscni.NewServiceConnectNetworkConfig(task.ServiceConnectConfig, ecscni.NAT, false,
+		task.shouldEnableIPv4(), task.shouldEnableIPv6(),
+		&ecscni.Config{MinSupportedCNIVersion: config.DefaultMinSupportedCNIVersion})
+	if err != nil {
+		return nil, err
+	}
+	dryRun.CNINetworkConfig = netconf.Bytes
+	dryRun.NetfilterRules, err = ecscni.ServiceConnectNetfilterRules(netconf)
+	if err != nil {
+		return nil, err
+	}
+	return dryRun, nil
+}
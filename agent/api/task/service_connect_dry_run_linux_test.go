@@ -0,0 +1,140 @@
+//go:build linux && unit
+// +build linux,unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package task
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/api/serviceconnect"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serviceConnectDryRunACSTask(networkMode, scConfigValue string) *ecsacs.Task {
+	return &ecsacs.Task{
+		Arn:           strptr("myArn"),
+		DesiredStatus: strptr("RUNNING"),
+		Family:        strptr("myFamily"),
+		Version:       strptr("1"),
+		ElasticNetworkInterfaces: []*ecsacs.ElasticNetworkInterface{
+			{
+				Ec2Id:      strptr("eni-0123456789abcdef0"),
+				MacAddress: strptr("0a:1b:2c:3d:4e:5f"),
+				Ipv4Addresses: []*ecsacs.IPv4AddressAssignment{
+					{Primary: aws.Bool(true), PrivateAddress: aws.String(ipv4)},
+				},
+				SubnetGatewayIpv4Address: strptr("10.0.0.1/24"),
+			},
+		},
+		Containers: []*ecsacs.Container{
+			containerFromACS("C1", 8080, 0, networkMode),
+			containerFromACS(serviceConnectContainerTestName, 0, 0, networkMode),
+		},
+		Attachments: []*ecsacs.Attachment{
+			{
+				AttachmentArn: strptr("attachmentArn"),
+				AttachmentProperties: []*ecsacs.AttachmentProperty{
+					{
+						Name:  strptr(serviceconnect.GetServiceConnectConfigKey()),
+						Value: strptr(scConfigValue),
+					},
+					{
+						Name:  strptr(serviceconnect.GetServiceConnectContainerNameKey()),
+						Value: strptr(serviceConnectContainerTestName),
+					},
+				},
+				AttachmentType: strptr(serviceConnectAttachmentType),
+			},
+		},
+		NetworkMode: strptr(networkMode),
+	}
+}
+
+func TestDryRunServiceConnectAWSVPC(t *testing.T) {
+	acsTask := serviceConnectDryRunACSTask(AWSVPCNetworkMode,
+		`{"egressConfig":{"listenerName":"egress","vip":{"ipv4Cidr":"169.254.0.0/16"}},`+
+			`"ingressConfig":[{"listenerName":"web","listenerPort":15000,"interceptPort":8080},{"listenerName":"admin"}]}`)
+
+	dryRun, err := DryRunServiceConnect(acsTask)
+	require.NoError(t, err)
+
+	// Listener ports that ACS leaves unset are assigned from the ephemeral range.
+	egressPort := dryRun.Config.EgressConfig.ListenerPort
+	adminPort := dryRun.Config.IngressConfig[1].ListenerPort
+	assert.NotZero(t, egressPort)
+	assert.NotZero(t, adminPort)
+	assert.Equal(t, map[string]uint16{"egress": egressPort, "admin": adminPort}, dryRun.ListenerPortMapping)
+
+	var cniConfig map[string]interface{}
+	require.NoError(t, json.Unmarshal(dryRun.CNINetworkConfig, &cniConfig))
+	assert.Equal(t, "ecs-serviceconnect", cniConfig["type"])
+	assert.Equal(t, true, cniConfig["enableIPv4"])
+	assert.Equal(t, []string{
+		"iptables -t nat -A PREROUTING -p tcp --dport 8080 -j REDIRECT --to-port 15000",
+		fmt.Sprintf("iptables -t nat -A OUTPUT -p tcp -d 169.254.0.0/16 -j REDIRECT --to-port %d", egressPort),
+	}, dryRun.NetfilterRules)
+}
+
+func TestDryRunServiceConnectBridge(t *testing.T) {
+	acsTask := serviceConnectDryRunACSTask(BridgeNetworkMode,
+		`{"egressConfig":{"listenerName":"egress","vip":{"ipv4Cidr":"169.254.0.0/16"}},`+
+			`"ingressConfig":[{"listenerName":"web","listenerPort":15000}]}`)
+
+	dryRun, err := DryRunServiceConnect(acsTask)
+	require.NoError(t, err)
+	assert.NotZero(t, dryRun.Config.EgressConfig.ListenerPort)
+	// The CNI plugin config of bridge mode tasks depends on the addresses of the Service Connect
+	// pause container.
+	assert.Nil(t, dryRun.CNINetworkConfig)
+	assert.Nil(t, dryRun.NetfilterRules)
+}
+
+func TestDryRunServiceConnectInvalid(t *testing.T) {
+	// The listener port collides with the intercept port.
+	acsTask := serviceConnectDryRunACSTask(AWSVPCNetworkMode,
+		`{"ingressConfig":[{"listenerName":"web","listenerPort":8080,"interceptPort":8080}]}`)
+	_, err := DryRunServiceConnect(acsTask)
+	assert.Error(t, err)
+
+	acsTask = serviceConnectDryRunACSTask(AWSVPCNetworkMode, `{}`)
+	acsTask.Attachments = nil
+	_, err = DryRunServiceConnect(acsTask)
+	assert.Error(t, err)
+}
+
+func TestDryRunServiceConnectTaskDefinition(t *testing.T) {
+	taskDef := `{"family":"web","revision":2,"networkMode":"bridge","containerDefinitions":[` +
+		`{"name":"C1","essential":true,"portMappings":[{"name":"web","containerPort":8080,"protocol":"tcp"}]}]}`
+	acsTask, err := ServiceConnectTaskFromTaskDefinition([]byte(taskDef),
+		`{"ingressConfig":[{"listenerName":"web","listenerPort":15000}]}`)
+	require.NoError(t, err)
+	require.Len(t, acsTask.Containers, 2)
+	assert.Equal(t, dryRunServiceConnectContainerName, aws.ToString(acsTask.Containers[1].Name))
+
+	dryRun, err := DryRunServiceConnect(acsTask)
+	require.NoError(t, err)
+	assert.Equal(t, dryRunServiceConnectContainerName, dryRun.Config.ContainerName)
+	assert.Equal(t, uint16(15000), dryRun.Config.IngressConfig[0].ListenerPort)
+
+	_, err = ServiceConnectTaskFromTaskDefinition([]byte(`{"taskDefinition":{"family":"web"}}`), `{}`)
+	assert.Error(t, err)
+}
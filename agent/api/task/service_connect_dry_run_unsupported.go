@@ -0,0 +1,35 @@
+//go:build !linux
+// +build !linux
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package task
+
+import (
+	"github.com/aws/amazon-ecs-agent/agent/api/serviceconnect"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
+
+	"github.com/pkg/errors"
+)
+
+// ServiceConnectDryRun describes what the agent would set up for the Service Connect configuration
+// of a task.
+type ServiceConnectDryRun struct {
+	Config *serviceconnect.Config `json:"config"`
+}
+
+// DryRunServiceConnect returns an error as Service Connect is only supported on Linux.
+func DryRunServiceConnect(acsTask *ecsacs.Task) (*ServiceConnectDryRun, error) {
+	return nil, errors.New("service connect is not supported on this platform")
+}
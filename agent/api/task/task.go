@@ -33,8 +33,11 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/asmauth"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/asmsecret"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/credentialspec"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource/csivolume"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/envFiles"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource/ephemeralvolume"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/firelens"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource/secretfiles"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/ssmsecret"
 	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
 	resourcetype "github.com/aws/amazon-ecs-agent/agent/taskresource/types"
@@ -51,6 +54,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
 	nlappmesh "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/appmesh"
 	ni "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/tmds"
 	commonutils "github.com/aws/amazon-ecs-agent/ecs-agent/utils"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/arn"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/ttime"
@@ -80,6 +84,14 @@ const (
 	// credentials.
 	awsSDKCredentialsRelativeURIPathEnvironmentVariableName = "AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"
 
+	// awsSDKCredentialsFullURIEnvironmentVariableName defines the name of the environment variable
+	// holding the full URI of the credentials endpoint, which the AWS SDK uses when the relative URI
+	// is not set. It is used for the IPv6 address of the endpoint.
+	awsSDKCredentialsFullURIEnvironmentVariableName = "AWS_CONTAINER_CREDENTIALS_FULL_URI"
+
+	// credentialsEndpointIPv6 is the base URI of the credentials endpoint over IPv6
+	credentialsEndpointIPv6 = "http://[" + tmds.IPv6 + "]"
+
 	NvidiaVisibleDevicesEnvVar = "NVIDIA_VISIBLE_DEVICES"
 	GPUAssociationType         = "gpu"
 
@@ -309,6 +321,15 @@ type Task struct {
 	// For all other network modes (i.e. bridge, none, etc.), DefaultIfname is currently not being initialized/set. In order to use this task field for these
 	// network modes, changes will need to be made in the corresponding task provisioning workflows.
 	DefaultIfname string `json:"DefaultIfname,omitempty"`
+
+	// VolumeExportConfig is the task level configuration for archiving the contents of the task
+	// volumes before the task is cleaned up. It applies to every volume of the task that does
+	// not have an export configuration of its own.
+	VolumeExportConfig *VolumeExportConfig `json:"volumeExportConfiguration,omitempty"`
+
+	// VolumeExportsUnsafe tracks the exports of the task volumes. This field should be accessed
+	// via GetVolumeExports and SetVolumeExport.
+	VolumeExportsUnsafe []VolumeExportStatus `json:"volumeExports,omitempty"`
 }
 
 // TaskFromACS translates ecsacs.Task to apitask.Task by first marshaling the received
@@ -418,7 +439,8 @@ func (task *Task) PostUnmarshalTask(cfg *config.Config,
 
 	task.initSecretResources(cfg, credentialsManager, resourceFields)
 
-	task.initializeCredentialsEndpoint(credentialsManager)
+	uuidProvider := utils.NewDynamicUUIDProvider()
+	task.initializeCredentialsEndpoint(credentialsManager, uuidProvider)
 
 	// NOTE: initializeVolumes needs to be after initializeCredentialsEndpoint, because EFS volume might
 	// need the credentials endpoint constructed by it.
@@ -434,9 +456,10 @@ func (task *Task) PostUnmarshalTask(cfg *config.Config,
 		return apierrors.NewResourceInitError(task.Arn, err)
 	}
 
-	task.initializeContainersV3MetadataEndpoint(utils.NewDynamicUUIDProvider())
-	task.initializeContainersV4MetadataEndpoint(utils.NewDynamicUUIDProvider())
-	task.initializeContainersV1AgentAPIEndpoint(utils.NewDynamicUUIDProvider())
+	task.initializeContainersV3MetadataEndpoint(uuidProvider)
+	task.initializeContainersV4MetadataEndpoint(uuidProvider)
+	task.initializeContainersV1AgentAPIEndpoint(uuidProvider)
+	task.initializeIPv6MetadataEndpoints(cfg)
 	if err := task.addNetworkResourceProvisioningDependency(cfg); err != nil {
 		logger.Error("Could not provision network resource", logger.Fields{
 			field.TaskID: task.GetID(),
@@ -481,6 +504,36 @@ func (task *Task) PostUnmarshalTask(cfg *config.Config,
 		}
 	}
 
+	if task.requiresCSIVolumeResource() {
+		if err := task.initializeCSIVolumeResource(cfg); err != nil {
+			logger.Error("Could not initialize CSI volume resource", logger.Fields{
+				field.TaskID: task.GetID(),
+				field.Error:  err,
+			})
+			return apierrors.NewResourceInitError(task.Arn, err)
+		}
+	}
+
+	if task.requiresEphemeralVolumeResource() {
+		if err := task.initializeEphemeralVolumeResource(cfg, dockerClient, ctx); err != nil {
+			logger.Error("Could not initialize ephemeral volume resource", logger.Fields{
+				field.TaskID: task.GetID(),
+				field.Error:  err,
+			})
+			return apierrors.NewResourceInitError(task.Arn, err)
+		}
+	}
+
+	if task.requiresSecretFilesResource() {
+		if err := task.initializeSecretFilesResource(cfg); err != nil {
+			logger.Error("Could not initialize secret files resource", logger.Fields{
+				field.TaskID: task.GetID(),
+				field.Error:  err,
+			})
+			return apierrors.NewResourceInitError(task.Arn, err)
+		}
+	}
+
 	task.initRestartTrackers()
 
 	for _, opt := range options {
@@ -971,6 +1024,164 @@ func (task *Task) addSharedVolumes(SharedVolumeMatchFullConfig bool, ctx context
 	return nil
 }
 
+// requiresCSIVolumeResource returns true if at least one volume in the task is of type 'csi'
+func (task *Task) requiresCSIVolumeResource() bool {
+	for _, volume := range task.Volumes {
+		if volume.Type == CSIVolumeType {
+			return true
+		}
+	}
+	return false
+}
+
+// initializeCSIVolumeResource creates a csivolume resource for every csi task volume, served by the
+// node plugin that the agent is configured with for the driver of the volume.
+func (task *Task) initializeCSIVolumeResource(cfg *config.Config) error {
+	for i, vol := range task.Volumes {
+		if vol.Type != CSIVolumeType {
+			continue
+		}
+
+		csiVol, ok := vol.Volume.(*csivolume.CSIVolumeConfig)
+		if !ok {
+			return errors.New("task volume: volume configuration does not match the type 'csi'")
+		}
+		socketPath, ok := cfg.CSIDriverSockets[csiVol.Driver]
+		if !ok {
+			return errors.Errorf("task volume %s: no node plugin socket configured for csi driver %q",
+				vol.Name, csiVol.Driver)
+		}
+
+		csiVolumeResource, err := csivolume.NewCSIVolumeResource(task.Arn, task.GetID(), vol.Name, csiVol,
+			socketPath, cfg.DataDir, cfg.DataDirOnHost)
+		if err != nil {
+			return err
+		}
+		task.Volumes[i].Volume = &csiVolumeResource.VolumeConfig
+		task.AddResource(resourcetype.CSIVolumeKey, csiVolumeResource)
+		task.updateContainerVolumeDependency(vol.Name)
+	}
+	return nil
+}
+
+// requiresEphemeralVolumeResource returns true if at least one volume in the task is of type 'ephemeral'
+func (task *Task) requiresEphemeralVolumeResource() bool {
+	for _, volume := range task.Volumes {
+		if volume.Type == EphemeralVolumeType {
+			return true
+		}
+	}
+	return false
+}
+
+// initializeEphemeralVolumeResource creates an ephemeralvolume resource for every ephemeral task volume.
+func (task *Task) initializeEphemeralVolumeResource(cfg *config.Config, dockerClient dockerapi.DockerClient,
+	ctx context.Context) error {
+	for i, vol := range task.Volumes {
+		if vol.Type != EphemeralVolumeType {
+			continue
+		}
+
+		ephemeralVol, ok := vol.Volume.(*ephemeralvolume.EphemeralVolumeConfig)
+		if !ok {
+			return errors.New("task volume: volume configuration does not match the type 'ephemeral'")
+		}
+
+		ephemeralVolumeResource, err := ephemeralvolume.NewEphemeralVolumeResource(ctx, task.Arn, task.GetID(),
+			vol.Name, ephemeralVol, cfg.EphemeralVolumeXFSMountPoint, dockerClient)
+		if err != nil {
+			return err
+		}
+		task.Volumes[i].Volume = &ephemeralVolumeResource.VolumeConfig
+		task.AddResource(resourcetype.EphemeralVolumeKey, ephemeralVolumeResource)
+		task.updateContainerVolumeDependency(vol.Name)
+	}
+	return nil
+}
+
+// IsSecretMountedAsFile returns true if the secret is written to a file that is mounted into the
+// container. MOUNT_POINT secrets without a container path are ignored.
+func IsSecretMountedAsFile(s apicontainer.Secret) bool {
+	return s.Type == apicontainer.SecretTypeMountPoint && s.ContainerPath != ""
+}
+
+// requiresSecretFilesResource returns true if at least one container in the task mounts a secret as a file
+func (task *Task) requiresSecretFilesResource() bool {
+	for _, container := range task.Containers {
+		if container.HasSecret(IsSecretMountedAsFile) {
+			return true
+		}
+	}
+	return false
+}
+
+// initializeSecretFilesResource validates the secrets that are mounted as files and creates the
+// secretfiles resource that holds them.
+func (task *Task) initializeSecretFilesResource(cfg *config.Config) error {
+	for _, container := range task.Containers {
+		if _, err := secretFilesOfContainer(container, nil); err != nil {
+			return errors.Wrapf(err, "container %s", container.Name)
+		}
+	}
+
+	secretFilesResource := secretfiles.NewSecretFilesResource(task.Arn, task.GetID(), cfg.DataDir,
+		cfg.DataDirOnHost)
+	task.AddResource(resourcetype.SecretFilesKey, secretFilesResource)
+
+	for _, container := range task.Containers {
+		if container.HasSecret(IsSecretMountedAsFile) {
+			container.BuildResourceDependency(secretFilesResource.GetName(),
+				resourcestatus.ResourceStatus(secretfiles.SecretFilesCreated),
+				apicontainerstatus.ContainerCreated)
+		}
+	}
+	return nil
+}
+
+// secretFilesOfContainer returns the files of the secrets that the container mounts. The values of the
+// files are only set if secretValue is not nil.
+func secretFilesOfContainer(container *apicontainer.Container,
+	secretValue func(apicontainer.Secret) (string, error)) ([]secretfiles.File, error) {
+	var files []secretfiles.File
+	containerPaths := make(map[string]struct{})
+	user := container.GetUser()
+	for i, secret := range container.Secrets {
+		if !IsSecretMountedAsFile(secret) {
+			continue
+		}
+		file, err := secretfiles.FileFromSecret(i, secret, user)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := containerPaths[file.ContainerPath]; ok {
+			return nil, errors.Errorf("secret %s: container path %s is used by another secret",
+				secret.Name, file.ContainerPath)
+		}
+		containerPaths[file.ContainerPath] = struct{}{}
+		if secretValue != nil {
+			if file.Value, err = secretValue(secret); err != nil {
+				return nil, err
+			}
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// GetEphemeralVolumeResources returns the ephemeralvolume resources of the task.
+func (task *Task) GetEphemeralVolumeResources() []*ephemeralvolume.EphemeralVolumeResource {
+	task.lock.RLock()
+	defer task.lock.RUnlock()
+
+	var resources []*ephemeralvolume.EphemeralVolumeResource
+	for _, res := range task.ResourcesMapUnsafe[ephemeralvolume.ResourceName] {
+		if ephemeralVolumeResource, ok := res.(*ephemeralvolume.EphemeralVolumeResource); ok {
+			resources = append(resources, ephemeralVolumeResource)
+		}
+	}
+	return resources
+}
+
 // updateContainerVolumeDependency adds the volume resource to container dependency
 func (task *Task) updateContainerVolumeDependency(name string) {
 	// Find all the container that depends on the volume
@@ -986,7 +1197,8 @@ func (task *Task) updateContainerVolumeDependency(name string) {
 }
 
 // initializeCredentialsEndpoint sets the credentials endpoint for all containers in a task if needed.
-func (task *Task) initializeCredentialsEndpoint(credentialsManager credentials.Manager) {
+// The ID of the metadata endpoints of the containers is generated using the passed in UUIDProvider.
+func (task *Task) initializeCredentialsEndpoint(credentialsManager credentials.Manager, uuidProvider utils.UUIDProvider) {
 	id := task.GetCredentialsID()
 	if id == "" {
 		// No credentials set for the task. Do not inject the endpoint environment variable.
@@ -1004,6 +1216,10 @@ func (task *Task) initializeCredentialsEndpoint(credentialsManager credentials.M
 		return
 	}
 
+	// Containers with a role of their own are identified by the id of their metadata endpoints
+	// when they ask for credentials.
+	task.initializeV3EndpointIDForAllContainers(uuidProvider)
+
 	credentialsEndpointRelativeURI := taskCredentials.IAMRoleCredentials.GenerateCredentialsEndpointRelativeURI()
 	for _, container := range task.Containers {
 		// container.Environment map would not be initialized if there are
@@ -1012,6 +1228,13 @@ func (task *Task) initializeCredentialsEndpoint(credentialsManager credentials.M
 		if container.Environment == nil {
 			container.Environment = make(map[string]string)
 		}
+		if container.RoleArn != "" {
+			// The container must not learn the credentials id of the task role, which it could
+			// exchange for the credentials of the task role.
+			container.Environment[awsSDKCredentialsRelativeURIPathEnvironmentVariableName] =
+				credentials.GenerateContainerCredentialsEndpointRelativeURI(container.GetV3EndpointID())
+			continue
+		}
 		container.Environment[awsSDKCredentialsRelativeURIPathEnvironmentVariableName] = credentialsEndpointRelativeURI
 	}
 
@@ -1037,6 +1260,26 @@ func (task *Task) initializeContainersV4MetadataEndpoint(uuidProvider utils.UUID
 	}
 }
 
+// initializeIPv6MetadataEndpoints points the containers of the task at the IPv6 address of the metadata,
+// Agent API and credentials endpoints on IPv6-only instances, where ecs-init redirects that address to
+// the agent. Tasks in awsvpc mode keep using the IPv4 endpoints, which they reach through the ecs-bridge.
+func (task *Task) initializeIPv6MetadataEndpoints(cfg *config.Config) {
+	if !cfg.InstanceIPCompatibility.IsIPv6Only() || task.IsNetworkModeAWSVPC() {
+		return
+	}
+	for _, container := range task.Containers {
+		container.InjectIPv6MetadataEndpoints()
+
+		relativeURI, ok := container.Environment[awsSDKCredentialsRelativeURIPathEnvironmentVariableName]
+		if !ok {
+			continue
+		}
+		// The relative URI takes precedence over the full URI in the AWS SDKs.
+		delete(container.Environment, awsSDKCredentialsRelativeURIPathEnvironmentVariableName)
+		container.Environment[awsSDKCredentialsFullURIEnvironmentVariableName] = credentialsEndpointIPv6 + relativeURI
+	}
+}
+
 // For each container of the task, initializeContainersV1AgentAPIEndpoint initializes
 // its V3EndpointID (if not already initialized), and injects V1 Agent API Endpoint
 // into the container.
@@ -1113,7 +1356,8 @@ func (task *Task) initializeSSMSecretResource(cfg *config.Config,
 	credentialsManager credentials.Manager,
 	resourceFields *taskresource.ResourceFields) {
 	ssmSecretResource := ssmsecret.NewSSMSecretResource(task.Arn, task.getAllSSMSecretRequirements(),
-		task.ExecutionCredentialsID, credentialsManager, resourceFields.SSMClientCreator, cfg.InstanceIPCompatibility)
+		task.ExecutionCredentialsID, credentialsManager, resourceFields.SSMClientCreator, cfg.InstanceIPCompatibility,
+		resourceFields.SecretCache)
 	task.AddResource(ssmsecret.ResourceName, ssmSecretResource)
 
 	// for every container that needs ssm secret vending as env, it needs to wait all secrets got retrieved
@@ -1181,7 +1425,7 @@ func (task *Task) requiresASMSecret() bool {
 func (task *Task) initializeASMSecretResource(credentialsManager credentials.Manager,
 	resourceFields *taskresource.ResourceFields) {
 	asmSecretResource := asmsecret.NewASMSecretResource(task.Arn, task.getAllASMSecretRequirements(),
-		task.ExecutionCredentialsID, credentialsManager, resourceFields.ASMClientCreator)
+		task.ExecutionCredentialsID, credentialsManager, resourceFields.ASMClientCreator, resourceFields.SecretCache)
 	task.AddResource(asmsecret.ResourceName, asmSecretResource)
 
 	// for every container that needs asm secret vending as envvar, it needs to wait all secrets got retrieved
@@ -1228,6 +1472,19 @@ func (task *Task) GetFirelensContainer() *apicontainer.Container {
 	return nil
 }
 
+// GetFirelensResource returns the firelens resource of the task, if the task has one.
+func (task *Task) GetFirelensResource() (*firelens.FirelensResource, bool) {
+	task.lock.RLock()
+	defer task.lock.RUnlock()
+
+	for _, res := range task.ResourcesMapUnsafe[firelens.ResourceName] {
+		if firelensResource, ok := res.(*firelens.FirelensResource); ok {
+			return firelensResource, true
+		}
+	}
+	return nil, false
+}
+
 // initializeFirelensResource initializes the firelens task resource and adds it as a dependency of the
 // firelens container.
 func (task *Task) initializeFirelensResource(config *config.Config, resourceFields *taskresource.ResourceFields,
@@ -2408,8 +2665,19 @@ func (task *Task) dockerLinks(container *apicontainer.Container, dockerContainer
 	return dockerLinkArr, nil
 }
 
-var getHostPortRange = utils.GetHostPortRange
-var getHostPort = utils.GetHostPort
+var allocateHostPortRange = utils.AllocateHostPortRange
+var allocateHostPort = utils.AllocateHostPort
+
+// hostPortAllocationKey identifies a container port across the tasks of the same service, or of the same
+// task definition family for tasks that do not belong to a service, so that dynamic host ports can stick
+// to it across task replacements.
+func (task *Task) hostPortAllocationKey(containerName, containerPort, protocol string) string {
+	group := "family:" + task.Family
+	if task.ServiceName != "" {
+		group = "service:" + task.ServiceName
+	}
+	return strings.Join([]string{group, containerName, containerPort, protocol}, "/")
+}
 
 // In buildPortMapWithSCIngressConfig, the dockerPortMap and the containerPortSet will be constructed
 // for ingress listeners under two service connect bridge mode cases:
@@ -2439,7 +2707,9 @@ func (task *Task) buildPortMapWithSCIngressConfig(dynamicHostPortRange string) (
 			// thus the host port will be assigned by ECS Agent.
 			// ECS Agent will find an available host port within the given dynamic host port range,
 			// or return an error if no host port is available within the range.
-			hostPortStr, err = getHostPort(protocolStr, dynamicHostPortRange)
+			hostPortStr, err = allocateHostPort(task.Arn,
+				task.hostPortAllocationKey(scContainer.Name, strconv.Itoa(listenerPortInt), protocolStr),
+				protocolStr, dynamicHostPortRange)
 			if err != nil {
 				return nil, err
 			}
@@ -2545,7 +2815,9 @@ func (task *Task) dockerPortMap(container *apicontainer.Container, dynamicHostPo
 					field.Container:        containerToCheck.Name,
 					"dynamicHostPortRange": dynamicHostPortRange,
 				})
-				hostPortStr, err = getHostPort(protocolStr, dynamicHostPortRange)
+				hostPortStr, err = allocateHostPort(task.Arn,
+					task.hostPortAllocationKey(containerToCheck.Name, strconv.Itoa(containerPort), protocolStr),
+					protocolStr, dynamicHostPortRange)
 				if err != nil {
 					logger.Error("Unable to find a host port for container within the given dynamic host port range", logger.Fields{
 						field.TaskID:           task.GetID(),
@@ -2576,7 +2848,9 @@ func (task *Task) dockerPortMap(container *apicontainer.Container, dynamicHostPo
 			// This is to ensure that docker maps host ports in a contiguous manner, and
 			// we are guaranteed to have the entire hostPortRange in a single network binding while sending this info to ECS;
 			// therefore, an error will be returned if we cannot find a contiguous set of host ports.
-			hostPortRange, err := getHostPortRange(numberOfPorts, protocol, dynamicHostPortRange)
+			hostPortRange, err := allocateHostPortRange(task.Arn,
+				task.hostPortAllocationKey(containerToCheck.Name, containerPortRange, protocol),
+				numberOfPorts, protocol, dynamicHostPortRange)
 			if err != nil {
 				logger.Error("Unable to find contiguous host ports for container", logger.Fields{
 					field.TaskID:         task.GetID(),
@@ -3150,6 +3424,15 @@ func (task *Task) getSSMSecretsResource() ([]taskresource.TaskResource, bool) {
 	return res, ok
 }
 
+// getSecretFilesResource retrieves secretfiles resource from resource map
+func (task *Task) getSecretFilesResource() ([]taskresource.TaskResource, bool) {
+	task.lock.RLock()
+	defer task.lock.RUnlock()
+
+	res, ok := task.ResourcesMapUnsafe[secretfiles.ResourceName]
+	return res, ok
+}
+
 // PopulateSecrets appends secrets to container's env var map and hostconfig section
 func (task *Task) PopulateSecrets(hostConfig *dockercontainer.HostConfig, container *apicontainer.Container) *apierrors.DockerClientConfigError {
 	var ssmRes *ssmsecret.SSMSecretResource
@@ -3175,6 +3458,48 @@ func (task *Task) PopulateSecrets(hostConfig *dockercontainer.HostConfig, contai
 	return nil
 }
 
+// PopulateSecretFiles writes the secrets that the container mounts as files to the secretfiles resource
+// of the task, and returns the binds that mount them into the container
+func (task *Task) PopulateSecretFiles(container *apicontainer.Container) ([]string, *apierrors.DockerClientConfigError) {
+	resource, ok := task.getSecretFilesResource()
+	if !ok || len(resource) == 0 {
+		return nil, &apierrors.DockerClientConfigError{Msg: "task secret data: unable to fetch secret files resource"}
+	}
+	secretFilesRes := resource[0].(*secretfiles.SecretFilesResource)
+
+	var ssmRes *ssmsecret.SSMSecretResource
+	var asmRes *asmsecret.ASMSecretResource
+	if res, ok := task.getSSMSecretsResource(); ok {
+		ssmRes = res[0].(*ssmsecret.SSMSecretResource)
+	}
+	if res, ok := task.getASMSecretsResource(); ok {
+		asmRes = res[0].(*asmsecret.ASMSecretResource)
+	}
+
+	files, err := secretFilesOfContainer(container, func(secret apicontainer.Secret) (string, error) {
+		value, ok := "", false
+		switch {
+		case secret.Provider == apicontainer.SecretProviderSSM && ssmRes != nil:
+			value, ok = ssmRes.GetCachedSecretValue(secret.GetSecretResourceCacheKey())
+		case secret.Provider == apicontainer.SecretProviderASM && asmRes != nil:
+			value, ok = asmRes.GetCachedSecretValue(secret.GetSecretResourceCacheKey())
+		}
+		if !ok {
+			return "", errors.Errorf("missing secret value for secret %s", secret.Name)
+		}
+		return value, nil
+	})
+	if err != nil {
+		return nil, &apierrors.DockerClientConfigError{Msg: fmt.Sprintf("task secret data: %v", err)}
+	}
+
+	binds, err := secretFilesRes.WriteContainerFiles(container.Name, files)
+	if err != nil {
+		return nil, &apierrors.DockerClientConfigError{Msg: err.Error()}
+	}
+	return binds, nil
+}
+
 func populateContainerSecrets(hostConfig *dockercontainer.HostConfig, container *apicontainer.Container,
 	ssmRes *ssmsecret.SSMSecretResource, asmRes *asmsecret.ASMSecretResource) {
 	envVars := make(map[string]string)
@@ -3275,6 +3600,31 @@ func (task *Task) PopulateSecretLogOptionsToFirelensContainer(firelensContainer
 	return nil
 }
 
+// GetLogDriverSecretOptions returns the values of the secret log options of a container, which are not part of the
+// log config saved with the container. It fails if a value is not cached by the secret resources of the task, as
+// happens when the agent restarts, since secret values are not saved in the agent state.
+func (task *Task) GetLogDriverSecretOptions(container *apicontainer.Container) (map[string]string, error) {
+	var ssmRes *ssmsecret.SSMSecretResource
+	var asmRes *asmsecret.ASMSecretResource
+	if res, ok := task.getSSMSecretsResource(); ok {
+		ssmRes = res[0].(*ssmsecret.SSMSecretResource)
+	}
+	if res, ok := task.getASMSecretsResource(); ok {
+		asmRes = res[0].(*asmsecret.ASMSecretResource)
+	}
+
+	secretData, err := collectLogDriverSecretData(container.Secrets, ssmRes, asmRes)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range secretData {
+		if value == "" {
+			return nil, errors.Errorf("missing secret value for secret %s", name)
+		}
+	}
+	return secretData, nil
+}
+
 // collectLogDriverSecretData collects all the secret values for log driver secrets.
 func collectLogDriverSecretData(secrets []apicontainer.Secret, ssmRes *ssmsecret.SSMSecretResource,
 	asmRes *asmsecret.ASMSecretResource) (map[string]string, error) {
@@ -3406,7 +3756,8 @@ func (task *Task) initializeEnvfilesResource(config *config.Config, credentialsM
 	for _, container := range task.Containers {
 		if container.ShouldCreateWithEnvFiles() {
 			envfileResource, err := envFiles.NewEnvironmentFileResource(config.Cluster, task.Arn, config.AWSRegion, config.DataDir,
-				container.Name, container.EnvironmentFiles, credentialsManager, task.ExecutionCredentialsID, config.InstanceIPCompatibility)
+				container.Name, container.EnvironmentFiles, credentialsManager, task.ExecutionCredentialsID,
+				config.InstanceIPCompatibility, config.EnvironmentFileHostPathAllowlist)
 			if err != nil {
 				return errors.Wrapf(err, "unable to initialize envfiles resource for container %s", container.Name)
 			}
@@ -3440,8 +3791,11 @@ func (task *Task) getEnvfilesResource(containerName string) (taskresource.TaskRe
 
 // MergeEnvVarsFromEnvfiles should be called when creating a container -
 // this method reads the environment variables specified in the environment files
-// that was downloaded to disk and merges it with existing environment variables
-func (task *Task) MergeEnvVarsFromEnvfiles(container *apicontainer.Container) *apierrors.ResourceInitError {
+// that was downloaded to disk and merges it with existing environment variables.
+// The values of templated environment files are resolved with templateVariables,
+// see EnvfileTemplateVariables
+func (task *Task) MergeEnvVarsFromEnvfiles(container *apicontainer.Container,
+	templateVariables map[string]string) *apierrors.ResourceInitError {
 	var envfileResource *envFiles.EnvironmentFileResource
 	resource, ok := task.getEnvfilesResource(container.Name)
 	if !ok {
@@ -3455,6 +3809,12 @@ func (task *Task) MergeEnvVarsFromEnvfiles(container *apicontainer.Container) *a
 		return apierrors.NewResourceInitError(task.Arn, err)
 	}
 
+	if container.HasTemplatedEnvfiles() {
+		if err := envfileResource.ResolveTemplates(envVarsList, templateVariables); err != nil {
+			return apierrors.NewResourceInitError(task.Arn, err)
+		}
+	}
+
 	err = container.MergeEnvironmentVariablesFromEnvfiles(envVarsList)
 	if err != nil {
 		return apierrors.NewResourceInitError(task.Arn, err)
@@ -3463,6 +3823,52 @@ func (task *Task) MergeEnvVarsFromEnvfiles(container *apicontainer.Container) *a
 	return nil
 }
 
+// EnvfileTemplateVariables returns the variables that the values of the templated environment
+// files of a container can reference: the environment variables of the container, and the task
+// metadata, which takes precedence over them. The host ports bound to the ports of the container
+// are read from its host config, in which the agent has allocated the dynamic host ports.
+func (task *Task) EnvfileTemplateVariables(container *apicontainer.Container, hostConfig *dockercontainer.HostConfig,
+	cluster, availabilityZone, hostIPAddress string) map[string]string {
+	variables := make(map[string]string, len(container.Environment))
+	for key, value := range container.Environment {
+		variables[key] = value
+	}
+
+	variables[envFiles.TemplateVariableTaskARN] = task.Arn
+	variables[envFiles.TemplateVariableTaskFamily] = task.Family
+	variables[envFiles.TemplateVariableTaskRevision] = task.Version
+	variables[envFiles.TemplateVariableCluster] = cluster
+	variables[envFiles.TemplateVariableContainerName] = container.Name
+	if availabilityZone != "" {
+		variables[envFiles.TemplateVariableAvailabilityZone] = availabilityZone
+	}
+	if hostIPAddress != "" {
+		variables[envFiles.TemplateVariableHostIP] = hostIPAddress
+	}
+
+	// Containers that share the network namespace of the host or of the task ENI listen on the host
+	// port that is their container port.
+	if task.IsNetworkModeHost() || task.IsNetworkModeAWSVPC() {
+		for _, portBinding := range container.Ports {
+			if portBinding.ContainerPort != 0 {
+				containerPort := strconv.Itoa(int(portBinding.ContainerPort))
+				variables[envFiles.HostPortTemplateVariable(containerPort, portBinding.Protocol.String())] = containerPort
+			}
+		}
+	}
+	if hostConfig != nil {
+		for port, bindings := range hostConfig.PortBindings {
+			for _, binding := range bindings {
+				if binding.HostPort != "" {
+					variables[envFiles.HostPortTemplateVariable(port.Port(), port.Proto())] = binding.HostPort
+					break
+				}
+			}
+		}
+	}
+	return variables
+}
+
 // GetLocalIPAddress returns the local IP address of the task.
 func (task *Task) GetLocalIPAddress() string {
 	task.lock.RLock()
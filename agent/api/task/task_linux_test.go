@@ -19,6 +19,8 @@ package task
 import (
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -1342,6 +1344,24 @@ func TestCollectLogDriverSecretData(t *testing.T) {
 	assert.Equal(t, "secret-val-asm", secretData["secret-name-asm"])
 }
 
+func TestGetLogDriverSecretOptions(t *testing.T) {
+	task := getFirelensTask(t)
+	container := task.Containers[0]
+
+	// The secret value is not cached, as happens once the agent restarts.
+	_, err := task.GetLogDriverSecretOptions(container)
+	assert.Error(t, err)
+
+	ssmRes := &ssmsecret.SSMSecretResource{}
+	ssmRes.SetCachedSecretValue("secret-value-from_us-west-2", "secret-val")
+	task.AddResource(ssmsecret.ResourceName, ssmRes)
+
+	options, err := task.GetLogDriverSecretOptions(container)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"secret-name": "secret-val"}, options)
+	assert.Empty(t, container.Environment, "secret log options must not be set as environment variables")
+}
+
 // getFirelensTask returns a sample firelens task.
 func getFirelensTask(t *testing.T) *Task {
 	rawHostConfigInput := dockercontainer.HostConfig{
@@ -1763,3 +1783,127 @@ func TestDockerCPUShares(t *testing.T) {
 		})
 	}
 }
+
+func getSecretFilesTask() *Task {
+	uid := int64(os.Getuid())
+	gid := int64(os.Getgid())
+	return &Task{
+		Arn:                validTaskArn,
+		ResourcesMapUnsafe: make(map[string][]taskresource.TaskResource),
+		Containers: []*apicontainer.Container{
+			{
+				Name: "app",
+				Secrets: []apicontainer.Secret{
+					{
+						Provider:  apicontainer.SecretProviderSSM,
+						Name:      "env-secret",
+						Region:    "us-west-2",
+						Type:      apicontainer.SecretTypeEnv,
+						ValueFrom: "/test/secretName",
+					},
+					{
+						Provider:      apicontainer.SecretProviderSSM,
+						Name:          "db-password",
+						Region:        "us-west-2",
+						Type:          apicontainer.SecretTypeMountPoint,
+						ValueFrom:     "/test/secretName1",
+						ContainerPath: "/etc/app/db-password",
+						FileMode:      "0440",
+						UID:           &uid,
+						GID:           &gid,
+					},
+					{
+						Provider:      apicontainer.SecretProviderASM,
+						Name:          "api-key",
+						Region:        "us-west-2",
+						Type:          apicontainer.SecretTypeMountPoint,
+						ValueFrom:     "arn:aws:secretsmanager:us-west-2:11111:secret:/test/secretName",
+						ContainerPath: "/run/secrets/api-key",
+						UID:           &uid,
+						GID:           &gid,
+					},
+					{
+						// MOUNT_POINT secrets without a container path are ignored.
+						Provider:  apicontainer.SecretProviderSSM,
+						Name:      "ignored",
+						Region:    "us-west-2",
+						Type:      apicontainer.SecretTypeMountPoint,
+						ValueFrom: "/test/ignored",
+					},
+				},
+				TransitionDependenciesMap: make(map[apicontainerstatus.ContainerStatus]apicontainer.TransitionDependencySet),
+			},
+			{
+				Name:                      "sidecar",
+				TransitionDependenciesMap: make(map[apicontainerstatus.ContainerStatus]apicontainer.TransitionDependencySet),
+			},
+		},
+	}
+}
+
+func TestInitializeSecretFilesResource(t *testing.T) {
+	task := getSecretFilesTask()
+	require.True(t, task.requiresSecretFilesResource())
+	require.NoError(t, task.initializeSecretFilesResource(&config.Config{DataDir: "/data"}))
+
+	resources, ok := task.getSecretFilesResource()
+	require.True(t, ok)
+	require.Len(t, resources, 1)
+	assert.Len(t, task.Containers[0].TransitionDependenciesMap, 1)
+	assert.Empty(t, task.Containers[1].TransitionDependenciesMap)
+}
+
+func TestInitializeSecretFilesResourceInvalidSecrets(t *testing.T) {
+	for name, update := range map[string]func(secrets []apicontainer.Secret){
+		"relative container path": func(secrets []apicontainer.Secret) {
+			secrets[1].ContainerPath = "etc/app/db-password"
+		},
+		"invalid file mode": func(secrets []apicontainer.Secret) {
+			secrets[1].FileMode = "rw"
+		},
+		"duplicate container path": func(secrets []apicontainer.Secret) {
+			secrets[2].ContainerPath = secrets[1].ContainerPath
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			task := getSecretFilesTask()
+			update(task.Containers[0].Secrets)
+			assert.Error(t, task.initializeSecretFilesResource(&config.Config{DataDir: "/data"}))
+		})
+	}
+}
+
+func TestPopulateSecretFiles(t *testing.T) {
+	task := getSecretFilesTask()
+	dataDir := t.TempDir()
+	require.NoError(t, task.initializeSecretFilesResource(&config.Config{DataDir: dataDir,
+		DataDirOnHost: "/var/lib/ecs"}))
+
+	ssmRes := &ssmsecret.SSMSecretResource{}
+	ssmRes.SetCachedSecretValue(secretKeyWest1, "secretValue1")
+	ssmRes.SetCachedSecretValue("/test/secretName1_us-west-2", "secretValue2")
+	task.AddResource(ssmsecret.ResourceName, ssmRes)
+
+	container := task.Containers[0]
+	_, configErr := task.PopulateSecretFiles(container)
+	assert.NotNil(t, configErr, "the ASM secret value is missing")
+
+	asmRes := &asmsecret.ASMSecretResource{}
+	asmRes.SetCachedSecretValue(asmSecretKeyWest1, "secretValue3")
+	task.AddResource(asmsecret.ResourceName, asmRes)
+
+	binds, configErr := task.PopulateSecretFiles(container)
+	require.Nil(t, configErr)
+	assert.Equal(t, []string{
+		"/var/lib/ecs/data/secretfiles/task-id/app/1:/etc/app/db-password:ro",
+		"/var/lib/ecs/data/secretfiles/task-id/app/2:/run/secrets/api-key:ro",
+	}, binds)
+
+	value, err := os.ReadFile(filepath.Join(dataDir, "secretfiles", "task-id", "app", "1"))
+	require.NoError(t, err)
+	assert.Equal(t, "secretValue2", string(value))
+	info, err := os.Stat(filepath.Join(dataDir, "secretfiles", "task-id", "app", "2"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0400), info.Mode().Perm())
+	assert.Empty(t, container.Environment, "secrets mounted as files must not be set as environment variables")
+}
@@ -402,7 +402,7 @@ func TestDockerHostConfigPortBinding(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.testName, func(t *testing.T) {
 			defer func() {
-				getHostPortRange = utils.GetHostPortRange
+				allocateHostPortRange = utils.AllocateHostPortRange
 			}()
 
 			// Get the Docker host config for the task container
@@ -1011,7 +1011,7 @@ func TestGetCredentialsEndpointWhenCredentialsAreSet(t *testing.T) {
 		IAMRoleCredentials: credentials.IAMRoleCredentials{CredentialsID: "credsid"},
 	}
 	credentialsManager.EXPECT().GetTaskCredentials(credentialsIDInTask).Return(taskCredentials, true)
-	task.initializeCredentialsEndpoint(credentialsManager)
+	task.initializeCredentialsEndpoint(credentialsManager, utils.NewDynamicUUIDProvider())
 
 	// Test if all containers in the task have the environment variable for
 	// credentials endpoint set correctly.
@@ -1024,6 +1024,37 @@ func TestGetCredentialsEndpointWhenCredentialsAreSet(t *testing.T) {
 	}
 }
 
+func TestGetCredentialsEndpointWithContainerRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	credentialsManager := mock_credentials.NewMockManager(ctrl)
+
+	task := Task{
+		Containers: []*apicontainer.Container{
+			{
+				Name: "app",
+			},
+			{
+				Name:    "sidecar",
+				RoleArn: "arn:aws:iam::123456789012:role/sidecar",
+			}},
+		credentialsID: "credsid",
+	}
+
+	taskCredentials := credentials.TaskIAMRoleCredentials{
+		IAMRoleCredentials: credentials.IAMRoleCredentials{CredentialsID: "credsid"},
+	}
+	credentialsManager.EXPECT().GetTaskCredentials("credsid").Return(taskCredentials, true)
+	task.initializeCredentialsEndpoint(credentialsManager, utils.NewStaticUUIDProvider("new-uuid"))
+
+	assert.Equal(t, "/v2/credentials/credsid",
+		task.Containers[0].Environment[awsSDKCredentialsRelativeURIPathEnvironmentVariableName])
+	assert.Equal(t, "new-uuid", task.Containers[1].GetV3EndpointID())
+	assert.Equal(t, "/v2/credentials/container/new-uuid",
+		task.Containers[1].Environment[awsSDKCredentialsRelativeURIPathEnvironmentVariableName])
+	assert.Equal(t, "/v2/credentials/credsid", task.GetCredentialsRelativeURI())
+}
+
 func TestGetCredentialsEndpointWhenCredentialsAreNotSet(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -1041,7 +1072,7 @@ func TestGetCredentialsEndpointWhenCredentialsAreNotSet(t *testing.T) {
 			}},
 	}
 
-	task.initializeCredentialsEndpoint(credentialsManager)
+	task.initializeCredentialsEndpoint(credentialsManager, utils.NewDynamicUUIDProvider())
 
 	for _, container := range task.Containers {
 		env := container.Environment
@@ -1376,6 +1407,64 @@ func TestInitializeContainersV4MetadataEndpoint(t *testing.T) {
 		fmt.Sprintf(apicontainer.MetadataURIFormatV4, "new-uuid"))
 }
 
+func TestInitializeIPv6MetadataEndpoints(t *testing.T) {
+	newTask := func(networkMode string) *Task {
+		task := &Task{
+			NetworkMode: networkMode,
+			Containers: []*apicontainer.Container{
+				{
+					Name: "c1",
+					Environment: map[string]string{
+						awsSDKCredentialsRelativeURIPathEnvironmentVariableName: "/v2/credentials/credsid",
+					},
+				},
+				{Name: "c2"},
+			},
+		}
+		uuidProvider := utils.NewStaticUUIDProvider("new-uuid")
+		task.initializeContainersV3MetadataEndpoint(uuidProvider)
+		task.initializeContainersV4MetadataEndpoint(uuidProvider)
+		task.initializeContainersV1AgentAPIEndpoint(uuidProvider)
+		return task
+	}
+	ipv6OnlyConfig := &config.Config{InstanceIPCompatibility: ipcompatibility.NewIPv6OnlyCompatibility()}
+
+	t.Run("bridge task on IPv6-only instance", func(t *testing.T) {
+		task := newTask(BridgeNetworkMode)
+		task.initializeIPv6MetadataEndpoints(ipv6OnlyConfig)
+
+		env := task.Containers[0].Environment
+		assert.Equal(t, "http://[fd00:ec2::23]/v3/new-uuid", env[apicontainer.MetadataURIEnvironmentVariableName])
+		assert.Equal(t, "http://[fd00:ec2::23]/v4/new-uuid", env[apicontainer.MetadataURIEnvVarNameV4])
+		assert.Equal(t, "http://[fd00:ec2::23]/api/new-uuid", env[apicontainer.AgentURIEnvVarName])
+		assert.Equal(t, "http://[fd00:ec2::23]/v2/credentials/credsid",
+			env[awsSDKCredentialsFullURIEnvironmentVariableName])
+		assert.NotContains(t, env, awsSDKCredentialsRelativeURIPathEnvironmentVariableName)
+		assert.NotContains(t, task.Containers[1].Environment, awsSDKCredentialsFullURIEnvironmentVariableName)
+	})
+	t.Run("bridge task on dual-stack instance", func(t *testing.T) {
+		task := newTask(BridgeNetworkMode)
+		task.initializeIPv6MetadataEndpoints(&config.Config{
+			InstanceIPCompatibility: ipcompatibility.NewDualStackCompatibility(),
+		})
+
+		env := task.Containers[0].Environment
+		assert.Equal(t, fmt.Sprintf(apicontainer.MetadataURIFormat, "new-uuid"),
+			env[apicontainer.MetadataURIEnvironmentVariableName])
+		assert.Equal(t, "/v2/credentials/credsid", env[awsSDKCredentialsRelativeURIPathEnvironmentVariableName])
+		assert.NotContains(t, env, awsSDKCredentialsFullURIEnvironmentVariableName)
+	})
+	t.Run("awsvpc task on IPv6-only instance", func(t *testing.T) {
+		task := newTask(AWSVPCNetworkMode)
+		task.initializeIPv6MetadataEndpoints(ipv6OnlyConfig)
+
+		env := task.Containers[0].Environment
+		assert.Equal(t, fmt.Sprintf(apicontainer.MetadataURIFormatV4, "new-uuid"),
+			env[apicontainer.MetadataURIEnvVarNameV4])
+		assert.Equal(t, "/v2/credentials/credsid", env[awsSDKCredentialsRelativeURIPathEnvironmentVariableName])
+	})
+}
+
 // Tests that task.initializeContainersV1AgentAPIEndpoint method initializes
 // V3EndpointID for all containers of the task and injects v1 Agent API Endpoint
 // as an environment variable into each container.
@@ -1758,8 +1847,9 @@ func TestTaskFromACS(t *testing.T) {
 				},
 				EnvironmentFiles: []*ecsacs.EnvironmentFile{
 					{
-						Value: strptr("s3://bucketName/envFile"),
-						Type:  strptr("s3"),
+						Value:     strptr("s3://bucketName/envFile"),
+						Type:      strptr("s3"),
+						Templated: boolptr(true),
 					},
 				},
 			},
@@ -1861,8 +1951,9 @@ func TestTaskFromACS(t *testing.T) {
 				},
 				EnvironmentFiles: []apicontainer.EnvironmentFile{
 					{
-						Value: "s3://bucketName/envFile",
-						Type:  "s3",
+						Value:     "s3://bucketName/envFile",
+						Type:      "s3",
+						Templated: true,
 					},
 				},
 				TransitionDependenciesMap: make(map[apicontainerstatus.ContainerStatus]apicontainer.TransitionDependencySet),
@@ -2321,7 +2412,7 @@ func TestApplyExecutionRoleLogsAuthSet(t *testing.T) {
 		IAMRoleCredentials: credentials.IAMRoleCredentials{CredentialsID: "credsid"},
 	}
 	credentialsManager.EXPECT().GetTaskCredentials(credentialsIDInTask).Return(taskCredentials, true)
-	task.initializeCredentialsEndpoint(credentialsManager)
+	task.initializeCredentialsEndpoint(credentialsManager, utils.NewDynamicUUIDProvider())
 
 	cfg, err := task.DockerHostConfig(task.Containers[0], dockerMap(task), defaultDockerClientAPIVersion,
 		&config.Config{})
@@ -2371,7 +2462,7 @@ func TestApplyExecutionRoleLogsAuthNoConfigInHostConfig(t *testing.T) {
 		IAMRoleCredentials: credentials.IAMRoleCredentials{CredentialsID: "credsid"},
 	}
 	credentialsManager.EXPECT().GetTaskCredentials(credentialsIDInTask).Return(taskCredentials, true)
-	task.initializeCredentialsEndpoint(credentialsManager)
+	task.initializeCredentialsEndpoint(credentialsManager, utils.NewDynamicUUIDProvider())
 
 	cfg, err := task.DockerHostConfig(task.Containers[0], dockerMap(task), defaultDockerClientAPIVersion,
 		&config.Config{})
@@ -2414,7 +2505,7 @@ func TestApplyExecutionRoleLogsAuthFailEmptyCredentialsID(t *testing.T) {
 		},
 	}
 
-	task.initializeCredentialsEndpoint(credentialsManager)
+	task.initializeCredentialsEndpoint(credentialsManager, utils.NewDynamicUUIDProvider())
 
 	cfg, err := task.DockerHostConfig(task.Containers[0], dockerMap(task), defaultDockerClientAPIVersion,
 		&config.Config{})
@@ -2457,7 +2548,7 @@ func TestApplyExecutionRoleLogsAuthFailNoCredentialsForTask(t *testing.T) {
 	}
 
 	credentialsManager.EXPECT().GetTaskCredentials(credentialsIDInTask).Return(credentials.TaskIAMRoleCredentials{}, false)
-	task.initializeCredentialsEndpoint(credentialsManager)
+	task.initializeCredentialsEndpoint(credentialsManager, utils.NewDynamicUUIDProvider())
 
 	cfg, err := task.DockerHostConfig(task.Containers[0], dockerMap(task), defaultDockerClientAPIVersion, &config.Config{})
 	assert.Error(t, err)
@@ -4049,6 +4140,66 @@ func requireEnvfiles(task *Task) bool {
 	return false
 }
 
+func TestEnvfileTemplateVariables(t *testing.T) {
+	container := &apicontainer.Container{
+		Name: "web",
+		Environment: map[string]string{
+			"SERVICE":     "web",
+			"ECS_CLUSTER": "overridden",
+		},
+		Ports: []apicontainer.PortBinding{
+			{ContainerPort: 8080, Protocol: apicontainer.TransportProtocolTCP},
+			{ContainerPort: 53, Protocol: apicontainer.TransportProtocolUDP},
+		},
+	}
+	task := &Task{
+		Arn:         testTaskARN,
+		Family:      "family",
+		Version:     "3",
+		NetworkMode: BridgeNetworkMode,
+		Containers:  []*apicontainer.Container{container},
+	}
+	hostConfig := &dockercontainer.HostConfig{
+		PortBindings: nat.PortMap{
+			"8080/tcp": {{HostPort: "32768"}},
+			"53/udp":   {{HostIP: "::", HostPort: ""}, {HostPort: "32769"}},
+		},
+	}
+
+	variables := task.EnvfileTemplateVariables(container, hostConfig, "cluster", "us-west-2a", "10.0.0.1")
+	assert.Equal(t, map[string]string{
+		"SERVICE":               "web",
+		"ECS_TASK_ARN":          testTaskARN,
+		"ECS_TASK_FAMILY":       "family",
+		"ECS_TASK_REVISION":     "3",
+		"ECS_CLUSTER":           "cluster",
+		"ECS_CONTAINER_NAME":    "web",
+		"ECS_AVAILABILITY_ZONE": "us-west-2a",
+		"ECS_HOST_IP":           "10.0.0.1",
+		"ECS_HOST_PORT_8080":    "32768",
+		"ECS_HOST_PORT_53_UDP":  "32769",
+	}, variables)
+}
+
+func TestEnvfileTemplateVariablesHostNetworkMode(t *testing.T) {
+	container := &apicontainer.Container{
+		Name: "web",
+		Ports: []apicontainer.PortBinding{
+			{ContainerPort: 8080, Protocol: apicontainer.TransportProtocolTCP},
+		},
+	}
+	task := &Task{
+		Arn:         testTaskARN,
+		NetworkMode: HostNetworkMode,
+		Containers:  []*apicontainer.Container{container},
+	}
+
+	variables := task.EnvfileTemplateVariables(container, &dockercontainer.HostConfig{}, "cluster", "", "")
+	assert.Equal(t, "8080", variables["ECS_HOST_PORT_8080"])
+	assert.NotContains(t, variables, "ECS_AVAILABILITY_ZONE")
+	assert.NotContains(t, variables, "ECS_HOST_IP")
+}
+
 func TestPopulateTaskARN(t *testing.T) {
 	task := &Task{
 		Arn: testTaskARN,
@@ -18,6 +18,8 @@ import (
 
 	"github.com/aws/amazon-ecs-agent/agent/config"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource/csivolume"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource/ephemeralvolume"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/fsxwindowsfileserver"
 	taskresourcetypes "github.com/aws/amazon-ecs-agent/agent/taskresource/types"
 	taskresourcevolume "github.com/aws/amazon-ecs-agent/agent/taskresource/volume"
@@ -32,6 +34,8 @@ const (
 	DockerVolumeType               = "docker"
 	EFSVolumeType                  = "efs"
 	FSxWindowsFileServerVolumeType = "fsxWindowsFileServer"
+	CSIVolumeType                  = "csi"
+	EphemeralVolumeType            = "ephemeral"
 	AttachmentType                 = "attachment"
 )
 
@@ -41,6 +45,8 @@ type TaskVolume struct {
 	Type   string `json:"type"`
 	Name   string `json:"name"`
 	Volume taskresourcevolume.Volume
+	// ExportConfig, if set, archives the contents of the volume before the task is cleaned up.
+	ExportConfig *VolumeExportConfig `json:"exportConfiguration,omitempty"`
 }
 
 // UnmarshalJSON for TaskVolume determines the name and volume type, and
@@ -68,6 +74,13 @@ func (tv *TaskVolume) UnmarshalJSON(b []byte) error {
 		return err
 	}
 
+	if exportConfig, ok := intermediate["exportConfiguration"]; ok && string(exportConfig) != "null" {
+		tv.ExportConfig = &VolumeExportConfig{}
+		if err := json.Unmarshal(exportConfig, tv.ExportConfig); err != nil {
+			return err
+		}
+	}
+
 	switch tv.Type {
 	case HostVolumeType:
 		return tv.unmarshalHostVolume(intermediate["host"])
@@ -77,6 +90,10 @@ func (tv *TaskVolume) UnmarshalJSON(b []byte) error {
 		return tv.unmarshalEFSVolume(intermediate["efsVolumeConfiguration"])
 	case FSxWindowsFileServerVolumeType:
 		return tv.unmarshalFSxWindowsFileServerVolume(intermediate["fsxWindowsFileServerVolumeConfiguration"])
+	case CSIVolumeType:
+		return tv.unmarshalCSIVolume(intermediate["csiVolumeConfiguration"])
+	case EphemeralVolumeType:
+		return tv.unmarshalEphemeralVolume(intermediate["ephemeralVolumeConfiguration"])
 	case apiresource.EBSTaskAttach:
 		return tv.unmarshalEBSVolume(intermediate["ebsVolumeConfiguration"])
 	case AttachmentType:
@@ -97,6 +114,9 @@ func (tv *TaskVolume) MarshalJSON() ([]byte, error) {
 
 	result["name"] = tv.Name
 	result["type"] = tv.Type
+	if tv.ExportConfig != nil {
+		result["exportConfiguration"] = tv.ExportConfig
+	}
 
 	switch tv.Type {
 	case DockerVolumeType:
@@ -107,6 +127,10 @@ func (tv *TaskVolume) MarshalJSON() ([]byte, error) {
 		result["efsVolumeConfiguration"] = tv.Volume
 	case FSxWindowsFileServerVolumeType:
 		result["fsxWindowsFileServerVolumeConfiguration"] = tv.Volume
+	case CSIVolumeType:
+		result["csiVolumeConfiguration"] = tv.Volume
+	case EphemeralVolumeType:
+		result["ephemeralVolumeConfiguration"] = tv.Volume
 	case apiresource.EBSTaskAttach:
 		result["ebsVolumeConfiguration"] = tv.Volume
 	default:
@@ -158,6 +182,34 @@ func (tv *TaskVolume) unmarshalFSxWindowsFileServerVolume(data json.RawMessage)
 	return nil
 }
 
+func (tv *TaskVolume) unmarshalCSIVolume(data json.RawMessage) error {
+	if data == nil {
+		return errors.New("invalid volume: empty volume configuration")
+	}
+	var csiVolumeConfig csivolume.CSIVolumeConfig
+	err := json.Unmarshal(data, &csiVolumeConfig)
+	if err != nil {
+		return err
+	}
+
+	tv.Volume = &csiVolumeConfig
+	return nil
+}
+
+func (tv *TaskVolume) unmarshalEphemeralVolume(data json.RawMessage) error {
+	if data == nil {
+		return errors.New("invalid volume: empty volume configuration")
+	}
+	var ephemeralVolumeConfig ephemeralvolume.EphemeralVolumeConfig
+	err := json.Unmarshal(data, &ephemeralVolumeConfig)
+	if err != nil {
+		return err
+	}
+
+	tv.Volume = &ephemeralVolumeConfig
+	return nil
+}
+
 func (tv *TaskVolume) unmarshalHostVolume(data json.RawMessage) error {
 	if data == nil {
 		return errors.New("invalid volume: empty volume configuration")
@@ -17,6 +17,7 @@
 package task
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"runtime"
@@ -27,10 +28,14 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
 	mock_dockerapi "github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi/mocks"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource/csivolume"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource/ephemeralvolume"
 	taskresourcevolume "github.com/aws/amazon-ecs-agent/agent/taskresource/volume"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
 	apiresource "github.com/aws/amazon-ecs-agent/ecs-agent/api/attachment/resource"
 	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/docker/docker/api/types/volume"
 	"github.com/golang/mock/gomock"
 	"github.com/pkg/errors"
@@ -807,3 +812,177 @@ func TestSetPausePIDInVolumeResources(t *testing.T) {
 	task.SetPausePIDInVolumeResources("pid")
 	assert.Equal(t, "pid", volRes.GetPauseContainerPID())
 }
+
+func getCSITask() *Task {
+	return &Task{
+		Arn: "arn:aws:ecs:us-west-2:123456789012:task/test-cluster/abc",
+		Volumes: []TaskVolume{
+			{
+				Name: "data",
+				Type: CSIVolumeType,
+				Volume: &csivolume.CSIVolumeConfig{
+					Driver:   "csi.vendor.example.com",
+					VolumeID: "vol-12345",
+				},
+			},
+		},
+		Containers: []*apicontainer.Container{
+			{
+				Name:                      "app",
+				MountPoints:               []apicontainer.MountPoint{{SourceVolume: "data", ContainerPath: "/data"}},
+				TransitionDependenciesMap: make(map[apicontainerstatus.ContainerStatus]apicontainer.TransitionDependencySet),
+			},
+		},
+		ResourcesMapUnsafe: make(map[string][]taskresource.TaskResource),
+	}
+}
+
+func TestMarshalUnmarshalTaskVolumesCSI(t *testing.T) {
+	task := getCSITask()
+	bytes, err := json.Marshal(task)
+	require.NoError(t, err)
+
+	var out Task
+	require.NoError(t, json.Unmarshal(bytes, &out))
+	require.Len(t, out.Volumes, 1)
+	assert.Equal(t, CSIVolumeType, out.Volumes[0].Type)
+	csiVol, ok := out.Volumes[0].Volume.(*csivolume.CSIVolumeConfig)
+	require.True(t, ok)
+	assert.Equal(t, "csi.vendor.example.com", csiVol.Driver)
+	assert.Equal(t, "vol-12345", csiVol.VolumeID)
+}
+
+func TestInitializeCSIVolumeResource(t *testing.T) {
+	task := getCSITask()
+	cfg := &config.Config{
+		DataDir:          "/data",
+		DataDirOnHost:    "/var/lib/ecs",
+		CSIDriverSockets: map[string]string{"csi.vendor.example.com": "/var/run/csi/vendor/csi.sock"},
+	}
+	require.True(t, task.requiresCSIVolumeResource())
+	require.NoError(t, task.initializeCSIVolumeResource(cfg))
+
+	resources := task.GetResources()
+	require.Len(t, resources, 1)
+	_, ok := resources[0].(*csivolume.CSIVolumeResource)
+	assert.True(t, ok)
+	assert.Equal(t, "/var/lib/ecs/csi/abc/data/mount", task.Volumes[0].Volume.Source())
+	assert.Len(t, task.Containers[0].TransitionDependenciesMap, 1)
+}
+
+func TestInitializeCSIVolumeResourceUnknownDriver(t *testing.T) {
+	task := getCSITask()
+	err := task.initializeCSIVolumeResource(&config.Config{})
+	assert.Error(t, err)
+	assert.Empty(t, task.GetResources())
+}
+
+func getEphemeralTask() *Task {
+	return &Task{
+		Arn: "arn:aws:ecs:us-west-2:123456789012:task/test-cluster/abc",
+		Volumes: []TaskVolume{
+			{
+				Name:   "scratch",
+				Type:   EphemeralVolumeType,
+				Volume: &ephemeralvolume.EphemeralVolumeConfig{SizeMiB: 2048},
+			},
+		},
+		Containers: []*apicontainer.Container{
+			{
+				Name:                      "app",
+				MountPoints:               []apicontainer.MountPoint{{SourceVolume: "scratch", ContainerPath: "/scratch"}},
+				TransitionDependenciesMap: make(map[apicontainerstatus.ContainerStatus]apicontainer.TransitionDependencySet),
+			},
+		},
+		ResourcesMapUnsafe: make(map[string][]taskresource.TaskResource),
+	}
+}
+
+func TestMarshalUnmarshalTaskVolumesEphemeral(t *testing.T) {
+	task := getEphemeralTask()
+	bytes, err := json.Marshal(task)
+	require.NoError(t, err)
+
+	var out Task
+	require.NoError(t, json.Unmarshal(bytes, &out))
+	require.Len(t, out.Volumes, 1)
+	assert.Equal(t, EphemeralVolumeType, out.Volumes[0].Type)
+	ephemeralVol, ok := out.Volumes[0].Volume.(*ephemeralvolume.EphemeralVolumeConfig)
+	require.True(t, ok)
+	assert.Equal(t, int64(2048), ephemeralVol.SizeMiB)
+}
+
+func TestInitializeEphemeralVolumeResource(t *testing.T) {
+	task := getEphemeralTask()
+	cfg := &config.Config{
+		DataDir:                      "/data",
+		EphemeralVolumeXFSMountPoint: "/mnt/xfs",
+	}
+	require.True(t, task.requiresEphemeralVolumeResource())
+	require.NoError(t, task.initializeEphemeralVolumeResource(cfg, nil, context.TODO()))
+
+	resources := task.GetEphemeralVolumeResources()
+	require.Len(t, resources, 1)
+	assert.Equal(t, int64(2048), resources[0].GetSizeMiB())
+	assert.Equal(t, "/mnt/xfs/ephemeral/abc/scratch", task.Volumes[0].Volume.Source())
+	assert.Len(t, task.Containers[0].TransitionDependenciesMap, 1)
+}
+
+func TestInitializeEphemeralVolumeResourceNoXFSMountPoint(t *testing.T) {
+	task := getEphemeralTask()
+	err := task.initializeEphemeralVolumeResource(&config.Config{DataDir: "/data"}, nil, context.TODO())
+	assert.Error(t, err)
+	assert.Empty(t, task.GetResources())
+}
+
+func TestTaskFromACSWithCSIVolume(t *testing.T) {
+	taskFromACS := &ecsacs.Task{
+		Arn: aws.String("arn:aws:ecs:us-west-2:123456789012:task/test-cluster/abc"),
+		Volumes: []*ecsacs.Volume{
+			{
+				Name: aws.String("data"),
+				Type: aws.String(CSIVolumeType),
+				CsiVolumeConfiguration: &ecsacs.CSIVolumeConfiguration{
+					Driver:        aws.String("csi.vendor.example.com"),
+					VolumeId:      aws.String("vol-12345"),
+					ReadOnly:      aws.Bool(true),
+					VolumeContext: map[string]*string{"tier": aws.String("fast")},
+				},
+			},
+		},
+	}
+	seqNum := int64(42)
+	task, err := TaskFromACS(taskFromACS, &ecsacs.PayloadMessage{SeqNum: &seqNum})
+	require.NoError(t, err)
+	require.Len(t, task.Volumes, 1)
+	csiVol, ok := task.Volumes[0].Volume.(*csivolume.CSIVolumeConfig)
+	require.True(t, ok)
+	assert.Equal(t, "csi.vendor.example.com", csiVol.Driver)
+	assert.Equal(t, "vol-12345", csiVol.VolumeID)
+	assert.True(t, csiVol.ReadOnly)
+	assert.Equal(t, map[string]string{"tier": "fast"}, csiVol.VolumeContext)
+}
+
+func TestTaskFromACSWithEphemeralVolume(t *testing.T) {
+	taskFromACS := &ecsacs.Task{
+		Arn: aws.String("arn:aws:ecs:us-west-2:123456789012:task/test-cluster/abc"),
+		Volumes: []*ecsacs.Volume{
+			{
+				Name: aws.String("scratch"),
+				Type: aws.String(EphemeralVolumeType),
+				EphemeralVolumeConfiguration: &ecsacs.EphemeralVolumeConfiguration{
+					Backend: aws.String(ephemeralvolume.BackendTmpfs),
+					SizeMiB: aws.Int64(256),
+				},
+			},
+		},
+	}
+	seqNum := int64(42)
+	task, err := TaskFromACS(taskFromACS, &ecsacs.PayloadMessage{SeqNum: &seqNum})
+	require.NoError(t, err)
+	require.Len(t, task.Volumes, 1)
+	ephemeralVol, ok := task.Volumes[0].Volume.(*ephemeralvolume.EphemeralVolumeConfig)
+	require.True(t, ok)
+	assert.Equal(t, ephemeralvolume.BackendTmpfs, ephemeralVol.Backend)
+	assert.Equal(t, int64(256), ephemeralVol.SizeMiB)
+}
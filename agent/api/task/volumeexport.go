@@ -0,0 +1,102 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package task
+
+import (
+	"time"
+)
+
+const (
+	// VolumeExportPending means the export of the volume has not started yet.
+	VolumeExportPending = "PENDING"
+	// VolumeExportInProgress means the contents of the volume are being archived.
+	VolumeExportInProgress = "IN_PROGRESS"
+	// VolumeExportCompleted means the archive of the volume has been written to its destination.
+	VolumeExportCompleted = "COMPLETED"
+	// VolumeExportFailed means the volume could not be exported.
+	VolumeExportFailed = "FAILED"
+)
+
+// VolumeExportConfig is the configuration for archiving the contents of a task volume before the
+// task is cleaned up. An empty S3Location means that the archive is written to the local export
+// directory of the agent.
+type VolumeExportConfig struct {
+	// S3Location is the S3 URI, in the form of s3://bucket/prefix, to upload the archive to.
+	S3Location string `json:"s3Location,omitempty"`
+	// MaxSizeBytes limits the size of the archive. Zero means the agent default applies.
+	MaxSizeBytes int64 `json:"maxSizeBytes,omitempty"`
+}
+
+// VolumeExportStatus is the status of the export of a task volume.
+type VolumeExportStatus struct {
+	VolumeName  string     `json:"VolumeName"`
+	Destination string     `json:"Destination,omitempty"`
+	Status      string     `json:"Status"`
+	SizeBytes   int64      `json:"SizeBytes,omitempty"`
+	Reason      string     `json:"Reason,omitempty"`
+	StartedAt   *time.Time `json:"StartedAt,omitempty"`
+	CompletedAt *time.Time `json:"CompletedAt,omitempty"`
+}
+
+// Terminal returns true if the export of the volume has either completed or failed.
+func (status VolumeExportStatus) Terminal() bool {
+	return status.Status == VolumeExportCompleted || status.Status == VolumeExportFailed
+}
+
+// GetVolumeExportConfig returns the export configuration of the volume with the given name. The
+// configuration of the volume takes precedence over the configuration of the task. nil is
+// returned if the volume is not to be exported.
+func (task *Task) GetVolumeExportConfig(volumeName string) *VolumeExportConfig {
+	task.lock.RLock()
+	defer task.lock.RUnlock()
+
+	for _, volume := range task.Volumes {
+		if volume.Name != volumeName {
+			continue
+		}
+		if volume.ExportConfig != nil {
+			return volume.ExportConfig
+		}
+		return task.VolumeExportConfig
+	}
+	return nil
+}
+
+// GetVolumeExports returns a copy of the export statuses of the task volumes.
+func (task *Task) GetVolumeExports() []VolumeExportStatus {
+	task.lock.RLock()
+	defer task.lock.RUnlock()
+
+	if len(task.VolumeExportsUnsafe) == 0 {
+		return nil
+	}
+	exports := make([]VolumeExportStatus, len(task.VolumeExportsUnsafe))
+	copy(exports, task.VolumeExportsUnsafe)
+	return exports
+}
+
+// SetVolumeExport records the export status of a task volume, replacing any status previously
+// recorded for the same volume.
+func (task *Task) SetVolumeExport(status VolumeExportStatus) {
+	task.lock.Lock()
+	defer task.lock.Unlock()
+
+	for i := range task.VolumeExportsUnsafe {
+		if task.VolumeExportsUnsafe[i].VolumeName == status.VolumeName {
+			task.VolumeExportsUnsafe[i] = status
+			return
+		}
+	}
+	task.VolumeExportsUnsafe = append(task.VolumeExportsUnsafe, status)
+}
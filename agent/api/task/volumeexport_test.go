@@ -0,0 +1,111 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package task
+
+import (
+	"encoding/json"
+	"testing"
+
+	taskresourcevolume "github.com/aws/amazon-ecs-agent/agent/taskresource/volume"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetVolumeExportConfig(t *testing.T) {
+	volumeExportConfig := &VolumeExportConfig{S3Location: "s3://bucket/volume"}
+	taskExportConfig := &VolumeExportConfig{MaxSizeBytes: 1024}
+	task := &Task{
+		Volumes: []TaskVolume{
+			{Name: "withconfig", Type: HostVolumeType, ExportConfig: volumeExportConfig},
+			{Name: "withoutconfig", Type: HostVolumeType},
+		},
+	}
+	assert.Equal(t, volumeExportConfig, task.GetVolumeExportConfig("withconfig"))
+	assert.Nil(t, task.GetVolumeExportConfig("withoutconfig"))
+
+	task.VolumeExportConfig = taskExportConfig
+	assert.Equal(t, volumeExportConfig, task.GetVolumeExportConfig("withconfig"))
+	assert.Equal(t, taskExportConfig, task.GetVolumeExportConfig("withoutconfig"))
+	assert.Nil(t, task.GetVolumeExportConfig("unknown"))
+}
+
+func TestSetVolumeExport(t *testing.T) {
+	task := &Task{}
+	assert.Nil(t, task.GetVolumeExports())
+
+	task.SetVolumeExport(VolumeExportStatus{VolumeName: "a", Status: VolumeExportInProgress})
+	task.SetVolumeExport(VolumeExportStatus{VolumeName: "b", Status: VolumeExportInProgress})
+	task.SetVolumeExport(VolumeExportStatus{VolumeName: "a", Status: VolumeExportCompleted, SizeBytes: 10})
+
+	exports := task.GetVolumeExports()
+	require.Len(t, exports, 2)
+	assert.Equal(t, VolumeExportStatus{VolumeName: "a", Status: VolumeExportCompleted, SizeBytes: 10}, exports[0])
+	assert.True(t, exports[0].Terminal())
+	assert.False(t, exports[1].Terminal())
+}
+
+func TestVolumeExportMarshal(t *testing.T) {
+	task := &Task{
+		Arn: "arn:aws:ecs:us-west-2:123456789012:task/cluster/taskid",
+		Volumes: []TaskVolume{
+			{
+				Name:         "data",
+				Type:         HostVolumeType,
+				Volume:       &taskresourcevolume.FSHostVolume{FSSourcePath: "/data"},
+				ExportConfig: &VolumeExportConfig{S3Location: "s3://bucket", MaxSizeBytes: 1024},
+			},
+		},
+		VolumeExportConfig: &VolumeExportConfig{},
+	}
+	task.SetVolumeExport(VolumeExportStatus{VolumeName: "data", Status: VolumeExportFailed, Reason: "reason"})
+
+	data, err := json.Marshal(task)
+	require.NoError(t, err)
+	unmarshalled := &Task{}
+	require.NoError(t, json.Unmarshal(data, unmarshalled))
+
+	assert.Equal(t, task.Volumes[0].ExportConfig, unmarshalled.Volumes[0].ExportConfig)
+	assert.Equal(t, task.VolumeExportConfig, unmarshalled.VolumeExportConfig)
+	assert.Equal(t, task.GetVolumeExports(), unmarshalled.GetVolumeExports())
+}
+
+func TestTaskFromACSWithVolumeExport(t *testing.T) {
+	taskFromACS := &ecsacs.Task{
+		Arn: aws.String("arn:aws:ecs:us-west-2:123456789012:task/test-cluster/abc"),
+		Volumes: []*ecsacs.Volume{
+			{
+				Name: aws.String("data"),
+				Type: aws.String(HostVolumeType),
+				Host: &ecsacs.HostVolumeProperties{SourcePath: aws.String("/data")},
+				ExportConfiguration: &ecsacs.VolumeExportConfiguration{
+					S3Location:   aws.String("s3://bucket/prefix"),
+					MaxSizeBytes: aws.Int64(1024),
+				},
+			},
+		},
+		VolumeExportConfiguration: &ecsacs.VolumeExportConfiguration{},
+	}
+	seqNum := int64(42)
+	task, err := TaskFromACS(taskFromACS, &ecsacs.PayloadMessage{SeqNum: &seqNum})
+	require.NoError(t, err)
+	require.Len(t, task.Volumes, 1)
+	assert.Equal(t, &VolumeExportConfig{S3Location: "s3://bucket/prefix", MaxSizeBytes: 1024}, task.Volumes[0].ExportConfig)
+	assert.Equal(t, &VolumeExportConfig{}, task.VolumeExportConfig)
+}
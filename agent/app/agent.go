@@ -23,6 +23,7 @@ import (
 	agentacs "github.com/aws/amazon-ecs-agent/agent/acs/session"
 	"github.com/aws/amazon-ecs-agent/agent/acs/updater"
 	"github.com/aws/amazon-ecs-agent/agent/app/factory"
+	asmfactory "github.com/aws/amazon-ecs-agent/agent/asm/factory"
 	"github.com/aws/amazon-ecs-agent/agent/config"
 	"github.com/aws/amazon-ecs-agent/agent/containermetadata"
 	"github.com/aws/amazon-ecs-agent/agent/data"
@@ -41,8 +42,11 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/eni/watcher"
 	"github.com/aws/amazon-ecs-agent/agent/eventhandler"
 	"github.com/aws/amazon-ecs-agent/agent/handlers"
+	"github.com/aws/amazon-ecs-agent/agent/logshipper"
+	"github.com/aws/amazon-ecs-agent/agent/secretcache"
 	"github.com/aws/amazon-ecs-agent/agent/sighandlers"
 	"github.com/aws/amazon-ecs-agent/agent/sighandlers/exitcodes"
+	ssmfactory "github.com/aws/amazon-ecs-agent/agent/ssm/factory"
 	"github.com/aws/amazon-ecs-agent/agent/statemanager"
 	"github.com/aws/amazon-ecs-agent/agent/stats"
 	"github.com/aws/amazon-ecs-agent/agent/stats/reporter"
@@ -528,6 +532,7 @@ func (agent *ecsAgent) doStart(containerChangeEventStream *eventstream.EventStre
 
 	// Begin listening to the docker daemon and saving changes
 	taskEngine.SetDataClient(agent.dataClient)
+	taskEngine.SetInstanceMetadata(agent.availabilityZone, agent.ec2MetadataClient)
 	imageManager.SetDataClient(agent.dataClient)
 	taskEngine.MustInit(agent.ctx)
 
@@ -719,6 +724,32 @@ func (agent *ecsAgent) setClusterInConfig(previousCluster string) error {
 	return nil
 }
 
+// newSecretCache creates the host level cache of secret values shared by the secret resources of
+// every task. It returns nil, so that secrets are retrieved for each task, unless ECS_SECRET_CACHE_TTL is set.
+func (agent *ecsAgent) newSecretCache(ssmClientCreator ssmfactory.SSMClientCreator,
+	asmClientCreator asmfactory.ClientCreator) *secretcache.Cache {
+	if agent.cfg.SecretCacheTTL <= 0 {
+		return nil
+	}
+	cache, err := secretcache.NewCache(agent.cfg.SecretCacheTTL, ssmClientCreator, asmClientCreator,
+		agent.cfg.InstanceIPCompatibility)
+	if err != nil {
+		logger.Warn("Unable to create the secret cache, secrets will be retrieved for each task", logger.Fields{
+			field.Error: err,
+		})
+		return nil
+	}
+	return cache
+}
+
+// secretCache returns the host level cache of secret values, if there is one
+func (agent *ecsAgent) secretCache() *secretcache.Cache {
+	if agent.resourceFields == nil || agent.resourceFields.ResourceFieldsCommon == nil {
+		return nil
+	}
+	return agent.resourceFields.SecretCache
+}
+
 // getEC2InstanceID gets the EC2 instance ID from the metadata service
 func (agent *ecsAgent) getEC2InstanceID() string {
 	var instanceID string
@@ -978,14 +1009,32 @@ func (agent *ecsAgent) startAsyncRoutines(
 		go agent.startSpotInstanceDrainingPoller(agent.ctx, client)
 	}
 
-	// Agent introspection api
-	go handlers.ServeIntrospectionHTTPEndpoint(agent.ctx, &agent.containerInstanceARN, taskEngine, agent.cfg)
-
 	telemetryMessages := make(chan ecstcs.TelemetryMessage, telemetryChannelDefaultBufferSize)
 	healthMessages := make(chan ecstcs.HealthMessage, telemetryChannelDefaultBufferSize)
 
 	statsEngine := stats.NewDockerStatsEngine(agent.cfg, agent.dockerClient, containerChangeEventStream, telemetryMessages, healthMessages, agent.dataClient)
 
+	// Ship the logs of the containers that use the awslogshipper log driver
+	var logShipper *logshipper.Shipper
+	if agent.cfg.LogShippingEnabled.Enabled() {
+		logShipper = logshipper.NewShipper(agent.cfg, agent.dockerClient, state, credentialsManager,
+			containerChangeEventStream)
+		if err := logShipper.Init(agent.ctx); err != nil {
+			seelog.Warnf("Error initializing log shipper: %v", err)
+			logShipper = nil
+		}
+	}
+
+	// Packet captures run tcpdump, which may not be installed
+	if agent.cfg.TaskPacketCaptureEnabled.Enabled() && !isPacketCaptureToolingAvailable() {
+		seelog.Warn("Disabling task packet capture as the tools it needs are not available")
+		agent.cfg.TaskPacketCaptureEnabled = config.BooleanDefaultFalse{Value: config.ExplicitlyDisabled}
+	}
+
+	// Agent introspection api
+	go handlers.ServeIntrospectionHTTPEndpoint(agent.ctx, &agent.containerInstanceARN, taskEngine, statsEngine,
+		agent.secretCache(), logShipper, agent.dataClient, agent.cfg, agent.startNetworkReconciler(state)...)
+
 	// Start serving the endpoint to fetch IAM Role credentials and other task metadata
 	if agent.cfg.TaskMetadataAZDisabled {
 		// send empty availability zone
@@ -85,6 +85,7 @@ const (
 	capabilityEBSTaskAttach                                = "storage.ebs-task-volume-attach"
 	capabilityContainerRestartPolicy                       = "container-restart-policy"
 	capabilityFaultInjection                               = "fault-injection"
+	capabilityLogShipperLoggingDriver                      = "logging-driver.awslogshipper"
 
 	// network capabilities, going forward, please append "network." prefix to any new networking capability we introduce
 	networkCapabilityPrefix      = "network."
@@ -204,6 +205,7 @@ var (
 //	ecs.capability.network.container-port-range
 //	ecs.capability.container-restart-policy
 //	ecs.capability.fault-injection
+//	com.amazonaws.ecs.capability.logging-driver.awslogshipper
 func (agent *ecsAgent) capabilities() ([]types.Attribute, error) {
 	var capabilities []types.Attribute
 
@@ -323,6 +325,9 @@ func (agent *ecsAgent) capabilities() ([]types.Attribute, error) {
 
 	capabilities = agent.appendFaultInjectionCapabilities(capabilities)
 
+	// support shipping container logs from the agent
+	capabilities = agent.appendLogShipperCapabilities(capabilities)
+
 	return capabilities, nil
 }
 
@@ -565,6 +570,13 @@ func (agent *ecsAgent) appendFaultInjectionCapabilities(capabilities []types.Att
 	return capabilities
 }
 
+func (agent *ecsAgent) appendLogShipperCapabilities(capabilities []types.Attribute) []types.Attribute {
+	if agent.cfg.LogShippingEnabled.Enabled() {
+		capabilities = appendNameOnlyAttribute(capabilities, capabilityPrefix+capabilityLogShipperLoggingDriver)
+	}
+	return capabilities
+}
+
 func defaultGetSubDirectories(path string) ([]string, error) {
 	var subDirectories []string
 
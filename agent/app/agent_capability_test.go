@@ -1599,3 +1599,24 @@ func TestAppendFaultInjectionCapabilities(t *testing.T) {
 		assert.Empty(t, capabilities)
 	})
 }
+
+func TestAppendLogShipperCapabilities(t *testing.T) {
+	t.Run("log shipping enabled", func(t *testing.T) {
+		agent := &ecsAgent{
+			cfg: &config.Config{
+				LogShippingEnabled: config.BooleanDefaultFalse{Value: config.ExplicitlyEnabled},
+			},
+		}
+		capabilities := agent.appendLogShipperCapabilities([]types.Attribute{})
+		require.Len(t, capabilities, 1)
+		assert.Equal(t, "com.amazonaws.ecs.capability.logging-driver.awslogshipper",
+			aws.ToString(capabilities[0].Name))
+	})
+	t.Run("log shipping disabled", func(t *testing.T) {
+		agent := &ecsAgent{
+			cfg: &config.Config{},
+		}
+		capabilities := agent.appendLogShipperCapabilities([]types.Attribute{})
+		assert.Empty(t, capabilities)
+	})
+}
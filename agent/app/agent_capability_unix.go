@@ -281,6 +281,21 @@ func checkFaultInjectionTooling(cfg *config.Config) bool {
 	return checkFaultInjectionModules() && checkTCShowTooling()
 }
 
+// var to allow mocking for checkPacketCaptureTooling
+var isPacketCaptureToolingAvailable = checkPacketCaptureTooling
+
+// checkPacketCaptureTooling checks for the tools that packet captures run inside the network namespace
+// of a task to be available before task packet capture can be enabled
+func checkPacketCaptureTooling() bool {
+	for _, tool := range []string{"nsenter", "tcpdump"} {
+		if _, err := lookPathFunc(tool); err != nil {
+			seelog.Warnf("Failed to find tool %s that is needed for task packet capture: %v", tool, err)
+			return false
+		}
+	}
+	return true
+}
+
 // checkFaultInjectionModules checks for the required kernel modules such as sch_netem to be installed
 // and avaialble on the host before ecs.capability.fault-injection can be advertised
 func checkFaultInjectionModules() bool {
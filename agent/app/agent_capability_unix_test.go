@@ -1147,6 +1147,30 @@ func TestCheckFaultInjectionTooling(t *testing.T) {
 	})
 }
 
+func TestCheckPacketCaptureTooling(t *testing.T) {
+	originalLookPath := exec.LookPath
+	defer func() {
+		lookPathFunc = originalLookPath
+	}()
+
+	t.Run("all tools available", func(t *testing.T) {
+		lookPathFunc = func(file string) (string, error) {
+			return "/usr/bin/" + file, nil
+		}
+		assert.True(t, checkPacketCaptureTooling())
+	})
+
+	t.Run("tcpdump missing", func(t *testing.T) {
+		lookPathFunc = func(file string) (string, error) {
+			if file == "tcpdump" {
+				return "", exec.ErrNotFound
+			}
+			return "/usr/bin/" + file, nil
+		}
+		assert.False(t, checkPacketCaptureTooling())
+	})
+}
+
 func convertToInterfaceList(strings []string) []interface{} {
 	interfaces := make([]interface{}, len(strings))
 	for i, s := range strings {
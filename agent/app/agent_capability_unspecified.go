@@ -158,3 +158,12 @@ var isFaultInjectionToolingAvailable = checkFaultInjectionTooling
 func checkFaultInjectionTooling(_ *config.Config) bool {
 	return false
 }
+
+// var to allow mocking for checkPacketCaptureTooling
+var isPacketCaptureToolingAvailable = checkPacketCaptureTooling
+
+// checkPacketCaptureTooling checks for the tools that packet captures run inside the network namespace
+// of a task to be available before task packet capture can be enabled
+func checkPacketCaptureTooling() bool {
+	return false
+}
@@ -157,3 +157,13 @@ func checkFaultInjectionTooling(_ *config.Config) bool {
 	seelog.Warnf("Fault injection tooling is not supported on windows")
 	return false
 }
+
+// var to allow mocking for checkPacketCaptureTooling
+var isPacketCaptureToolingAvailable = checkPacketCaptureTooling
+
+// checkPacketCaptureTooling checks for the tools that packet captures run inside the network namespace
+// of a task to be available before task packet capture can be enabled
+func checkPacketCaptureTooling() bool {
+	seelog.Warnf("Task packet capture is not supported on windows")
+	return false
+}
@@ -35,6 +35,10 @@ import (
 	cgroup "github.com/aws/amazon-ecs-agent/agent/taskresource/cgroup/control"
 	"github.com/aws/amazon-ecs-agent/agent/utils/ioutilwrapper"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/introspection"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/metrics"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/platform"
 
 	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
 	"github.com/cihub/seelog"
@@ -164,17 +168,46 @@ func (agent *ecsAgent) startEBSWatcher(
 	}
 }
 
+// startNetworkReconciler periodically cleans up the network namespaces, addresses and DNS configs
+// that netlib left behind for tasks the task engine no longer knows about. It returns the options
+// serving the reconciliation reports on the introspection server.
+func (agent *ecsAgent) startNetworkReconciler(state dockerstate.TaskEngineState) []introspection.ConfigOpt {
+	if !agent.cfg.NetworkReconciliationEnabled.Enabled() {
+		return nil
+	}
+	builder, err := netlib.NewNetworkBuilder(platform.Config{Name: platform.ManagedPlatform},
+		metrics.NewNopEntryFactory(), nil, agent.dataClient, agent.cfg.DataDir)
+	if err != nil {
+		seelog.Warnf("Error initializing network reconciliation: %v", err)
+		return nil
+	}
+	reconciler := netlib.NewNetworkReconciler(builder, func() ([]string, error) {
+		var taskIDs []string
+		for _, task := range state.AllTasks() {
+			taskIDs = append(taskIDs, task.GetID())
+		}
+		return taskIDs, nil
+	}, agent.cfg.NetworkReconciliationInterval)
+	go reconciler.Run(agent.ctx)
+	return []introspection.ConfigOpt{
+		introspection.WithHandler(netlib.ReconcilerIntrospectionPath, reconciler.IntrospectionHandler()),
+	}
+}
+
 // initializeResourceFields exists mainly for testing doStart() to use mock Control
 // object
 func (agent *ecsAgent) initializeResourceFields(credentialsManager credentials.Manager) {
+	asmClientCreator := asmfactory.NewClientCreator()
+	ssmClientCreator := ssmfactory.NewSSMClientCreator()
 	agent.resourceFields = &taskresource.ResourceFields{
 		Control: cgroup.New(),
 		ResourceFieldsCommon: &taskresource.ResourceFieldsCommon{
 			IOUtil:             ioutilwrapper.NewIOUtil(),
-			ASMClientCreator:   asmfactory.NewClientCreator(),
-			SSMClientCreator:   ssmfactory.NewSSMClientCreator(),
+			ASMClientCreator:   asmClientCreator,
+			SSMClientCreator:   ssmClientCreator,
 			S3ClientCreator:    s3factory.NewS3ClientCreator(),
 			CredentialsManager: credentialsManager,
+			SecretCache:        agent.newSecretCache(ssmClientCreator, asmClientCreator),
 			EC2InstanceID:      agent.getEC2InstanceID(),
 		},
 		Ctx:              agent.ctx,
@@ -39,6 +39,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/statechange"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/introspection"
 
 	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
 	"github.com/cihub/seelog"
@@ -113,6 +114,11 @@ func (agent *ecsAgent) startEBSWatcher(
 	}
 }
 
+// startNetworkReconciler is not supported on Windows, where netlib does not create network namespaces.
+func (agent *ecsAgent) startNetworkReconciler(state dockerstate.TaskEngineState) []introspection.ConfigOpt {
+	return nil
+}
+
 // handler implements https://godoc.org/golang.org/x/sys/windows/svc#Handler
 type handler struct {
 	ecsAgent agent
@@ -316,13 +322,16 @@ func (t *termHandlerIndicator) wait() uint32 {
 }
 
 func (agent *ecsAgent) initializeResourceFields(credentialsManager credentials.Manager) {
+	asmClientCreator := asmfactory.NewClientCreator()
+	ssmClientCreator := ssmfactory.NewSSMClientCreator()
 	agent.resourceFields = &taskresource.ResourceFields{
 		ResourceFieldsCommon: &taskresource.ResourceFieldsCommon{
-			ASMClientCreator:   asmfactory.NewClientCreator(),
-			SSMClientCreator:   ssmfactory.NewSSMClientCreator(),
+			ASMClientCreator:   asmClientCreator,
+			SSMClientCreator:   ssmClientCreator,
 			FSxClientCreator:   fsxfactory.NewFSxClientCreator(),
 			S3ClientCreator:    s3factory.NewS3ClientCreator(),
 			CredentialsManager: credentialsManager,
+			SecretCache:        agent.newSecretCache(ssmClientCreator, asmClientCreator),
 		},
 		Ctx:          agent.ctx,
 		DockerClient: agent.dockerClient,
@@ -28,6 +28,8 @@ const (
 	blacholeEC2MetadataUsage = "Blackhole the EC2 Metadata requests. Setting this option can cause the ECS Agent to fail to work properly.  We do not recommend setting this option"
 	windowsServiceUsage      = "Run the ECS agent as a Windows Service"
 	healthcheckServiceUsage  = "Run the agent healthcheck"
+	validateSCUsage          = "Validate the Service Connect configuration of the ACS task JSON in the given file, or of the task definition JSON in the given file with -service-connect-config, print the listener config and CNI plugin config that would be set up for it and exit"
+	scConfigUsage            = "The Service Connect config JSON file that ECS delivers for the service of the task definition validated with -validate-service-connect"
 
 	versionFlagName              = "version"
 	logLevelFlagName             = "loglevel"
@@ -39,6 +41,8 @@ const (
 	blackholeEC2MetadataFlagName = "blackhole-ec2-metadata"
 	windowsServiceFlagName       = "windows-service"
 	healthCheckFlagName          = "healthcheck"
+	validateSCFlagName           = "validate-service-connect"
+	scConfigFlagName             = "service-connect-config"
 )
 
 // Args wraps various ECS Agent arguments
@@ -65,6 +69,12 @@ type Args struct {
 	WindowsService *bool
 	// Healthcheck indicates that agent should run healthcheck
 	Healthcheck *bool
+	// ValidateServiceConnect is the path of an ACS task JSON or task definition JSON file whose
+	// Service Connect configuration should be validated and dry run
+	ValidateServiceConnect *string
+	// ServiceConnectConfig is the path of the Service Connect config JSON file to validate a task
+	// definition with
+	ServiceConnectConfig *string
 }
 
 // New creates a new Args object from the argument list
@@ -72,16 +82,18 @@ func New(arguments []string) (*Args, error) {
 	flagset := flag.NewFlagSet("Amazon ECS Agent", flag.ContinueOnError)
 
 	args := &Args{
-		Version:              flagset.Bool(versionFlagName, false, versionUsage),
-		LogLevel:             flagset.String(logLevelFlagName, "", logLevelUsage),
-		DriverLogLevel:       flagset.String(driverLogLevelFlagName, "", driverLogLevelUsage),
-		InstanceLogLevel:     flagset.String(instanceLogLevelFlagName, "", instanceLogLevelUsage),
-		AcceptInsecureCert:   flagset.Bool(acceptInsecureCertFlagName, false, acceptInsecureCertUsage),
-		License:              flagset.Bool(licenseFlagName, false, licenseUsage),
-		BlackholeEC2Metadata: flagset.Bool(blackholeEC2MetadataFlagName, false, blacholeEC2MetadataUsage),
-		ECSAttributes:        flagset.Bool(ecsAttributesFlagName, false, ecsAttributesUsage),
-		WindowsService:       flagset.Bool(windowsServiceFlagName, false, windowsServiceUsage),
-		Healthcheck:          flagset.Bool(healthCheckFlagName, false, healthcheckServiceUsage),
+		Version:                flagset.Bool(versionFlagName, false, versionUsage),
+		LogLevel:               flagset.String(logLevelFlagName, "", logLevelUsage),
+		DriverLogLevel:         flagset.String(driverLogLevelFlagName, "", driverLogLevelUsage),
+		InstanceLogLevel:       flagset.String(instanceLogLevelFlagName, "", instanceLogLevelUsage),
+		AcceptInsecureCert:     flagset.Bool(acceptInsecureCertFlagName, false, acceptInsecureCertUsage),
+		License:                flagset.Bool(licenseFlagName, false, licenseUsage),
+		BlackholeEC2Metadata:   flagset.Bool(blackholeEC2MetadataFlagName, false, blacholeEC2MetadataUsage),
+		ECSAttributes:          flagset.Bool(ecsAttributesFlagName, false, ecsAttributesUsage),
+		WindowsService:         flagset.Bool(windowsServiceFlagName, false, windowsServiceUsage),
+		Healthcheck:            flagset.Bool(healthCheckFlagName, false, healthcheckServiceUsage),
+		ValidateServiceConnect: flagset.String(validateSCFlagName, "", validateSCUsage),
+		ServiceConnectConfig:   flagset.String(scConfigFlagName, "", scConfigUsage),
 	}
 
 	err := flagset.Parse(arguments)
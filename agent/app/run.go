@@ -58,6 +58,8 @@ func Run(arguments []string) int {
 		}
 		healthcheckUrl := fmt.Sprintf("http://%s:51678/v1/metadata", localhost)
 		return runHealthcheck(healthcheckUrl, time.Second*25)
+	} else if *parsedArgs.ValidateServiceConnect != "" {
+		return validateServiceConnect(*parsedArgs.ValidateServiceConnect, *parsedArgs.ServiceConnectConfig, os.Stdout)
 	}
 
 	if *parsedArgs.LogLevel != "" {
@@ -0,0 +1,65 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/sighandlers/exitcodes"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
+)
+
+// validateServiceConnect validates the Service Connect configuration of the ACS task in the
+// file and prints what would be set up for it. When a Service Connect config file is given, the
+// file is a task definition instead, whose tasks get the Service Connect config. It exits with a
+// terminal exit code when the configuration is invalid, so that it can gate CI pipelines.
+func validateServiceConnect(path, scConfigPath string, out io.Writer) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to read task file: %v\n", err)
+		return exitcodes.ExitError
+	}
+	acsTask := &ecsacs.Task{}
+	if scConfigPath != "" {
+		scConfig, err := os.ReadFile(scConfigPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Unable to read service connect config file: %v\n", err)
+			return exitcodes.ExitError
+		}
+		acsTask, err = apitask.ServiceConnectTaskFromTaskDefinition(data, string(scConfig))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Unable to parse task definition: %v\n", err)
+			return exitcodes.ExitTerminal
+		}
+	} else if err := json.Unmarshal(data, acsTask); err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to parse ACS task: %v\n", err)
+		return exitcodes.ExitTerminal
+	}
+	dryRun, err := apitask.DryRunServiceConnect(acsTask)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid service connect configuration: %v\n", err)
+		return exitcodes.ExitTerminal
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(dryRun); err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to print service connect dry run: %v\n", err)
+		return exitcodes.ExitError
+	}
+	return exitcodes.ExitSuccess
+}
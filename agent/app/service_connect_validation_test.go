@@ -0,0 +1,59 @@
+//go:build linux && unit
+// +build linux,unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/sighandlers/exitcodes"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateServiceConnect(t *testing.T) {
+	var out bytes.Buffer
+	require.Equal(t, exitcodes.ExitSuccess, validateServiceConnect("testdata/service_connect_task.json", "", &out))
+	assert.Contains(t, out.String(), `"type": "ecs-serviceconnect"`)
+
+	invalid := filepath.Join(t.TempDir(), "task.json")
+	require.NoError(t, os.WriteFile(invalid, []byte(`{"networkMode":"awsvpc"}`), 0600))
+	assert.Equal(t, exitcodes.ExitTerminal, validateServiceConnect(invalid, "", &out))
+	assert.Equal(t, exitcodes.ExitError, validateServiceConnect(filepath.Join(t.TempDir(), "missing.json"), "", &out))
+}
+
+func TestValidateServiceConnectTaskDefinition(t *testing.T) {
+	var out bytes.Buffer
+	require.Equal(t, exitcodes.ExitSuccess, validateServiceConnect("testdata/service_connect_task_definition.json",
+		"testdata/service_connect_config.json", &out))
+	assert.Contains(t, out.String(), `"listenerPortMapping"`)
+	// The CNI plugin config depends on the elastic network interface of the task.
+	assert.NotContains(t, out.String(), `"cniNetworkConfig"`)
+
+	invalid := filepath.Join(t.TempDir(), "sc.json")
+	require.NoError(t, os.WriteFile(invalid,
+		[]byte(`{"ingressConfig":[{"listenerName":"web","listenerPort":8080,"interceptPort":8080}]}`), 0600))
+	assert.Equal(t, exitcodes.ExitTerminal, validateServiceConnect("testdata/service_connect_task_definition.json",
+		invalid, &out))
+	assert.Equal(t, exitcodes.ExitTerminal, validateServiceConnect("testdata/service_connect_task.json",
+		"testdata/service_connect_config.json", &out))
+	assert.Equal(t, exitcodes.ExitError, validateServiceConnect("testdata/service_connect_task_definition.json",
+		filepath.Join(t.TempDir(), "missing.json"), &out))
+}
@@ -17,6 +17,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
 
@@ -31,8 +32,17 @@ import (
 // For mocking purpose
 type SecretsManagerAPI interface {
 	GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+	BatchGetSecretValue(ctx context.Context, params *secretsmanager.BatchGetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.BatchGetSecretValueOutput, error)
 }
 
+// MaxBatchGetSecretValueNum is the maximum number of secrets that the BatchGetSecretValue API
+// accepts at one time
+const MaxBatchGetSecretValueNum = 20
+
+// secretARNSuffixLength is the number of random characters that Secrets Manager appends to the
+// ARNs of the secrets, after a dash
+const secretARNSuffixLength = 6
+
 // AuthDataValue is the schema for
 // the SecretStringValue returned by ASM
 type AuthDataValue struct {
@@ -126,26 +136,111 @@ func GetSecretFromASMWithInput(input *secretsmanager.GetSecretValueInput,
 		return "", errors.Wrap(err, augmentErrMsg(secretID, err))
 	}
 
+	return GetSecretValueFromJSONKey(secretID, aws.ToString(out.SecretString), jsonKey)
+}
+
+// GetSecretValueFromJSONKey returns the value of the given key of a secret string that holds a
+// JSON object. The secret string is returned as is if the key is empty.
+func GetSecretValueFromJSONKey(secretID, secretString, jsonKey string) (string, error) {
 	if jsonKey == "" {
-		return aws.ToString(out.SecretString), nil
+		return secretString, nil
 	}
 
 	secretMap := make(map[string]interface{})
-	jsonErr := json.Unmarshal([]byte(*out.SecretString), &secretMap)
+	jsonErr := json.Unmarshal([]byte(secretString), &secretMap)
 	if jsonErr != nil {
-		seelog.Warnf("Error when treating retrieved secret value with secret id %s as JSON and calling unmarshal.", *input.SecretId)
+		seelog.Warnf("Error when treating retrieved secret value with secret id %s as JSON and calling unmarshal.", secretID)
 		return "", jsonErr
 	}
 
 	secretValue, ok := secretMap[jsonKey]
 	if !ok {
-		err = errors.New(fmt.Sprintf("retrieved secret from Secrets Manager did not contain json key %s", jsonKey))
+		err := errors.New(fmt.Sprintf("retrieved secret from Secrets Manager did not contain json key %s", jsonKey))
 		return "", err
 	}
 
 	return fmt.Sprintf("%v", secretValue), nil
 }
 
+// BatchGetSecretsFromASM makes the api call to the AWS Secrets Manager service to retrieve the
+// current value of up to MaxBatchGetSecretValueNum secrets at once. The values are keyed by the
+// requested secret ID. Secrets that could not be retrieved are returned with an error each, so that
+// they do not fail the retrieval of the other secrets of the batch.
+func BatchGetSecretsFromASM(secretIDs []string, client SecretsManagerAPI) (map[string]string, map[string]error, error) {
+	in := &secretsmanager.BatchGetSecretValueInput{
+		SecretIdList: secretIDs,
+	}
+
+	out, err := client.BatchGetSecretValue(context.TODO(), in)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "asm batch fetching secrets %s", strings.Join(secretIDs, ","))
+	}
+
+	values := make(map[string]string)
+	secretErrors := make(map[string]error)
+	for _, secretID := range secretIDs {
+		if entry, ok := findSecretValueEntry(secretID, out.SecretValues); ok {
+			values[secretID] = aws.ToString(entry.SecretString)
+			continue
+		}
+		if apiErr, ok := findSecretError(secretID, out.Errors); ok {
+			secretErrors[secretID] = batchErrorMsg(secretID, apiErr)
+			continue
+		}
+		secretErrors[secretID] = errors.Errorf("secret %s: not returned by the service", secretID)
+	}
+	return values, secretErrors, nil
+}
+
+// matchesSecretID returns true if the secret ID, which may be a name, a full ARN or a partial ARN
+// without the random suffix added by Secrets Manager, refers to the secret with the given ARN and name.
+func matchesSecretID(secretID, secretARN, secretName string) bool {
+	return secretID == secretARN || secretID == secretName || isPartialARN(secretID, secretARN)
+}
+
+// isPartialARN returns true if the secret ID is the ARN of the secret without the random suffix
+// that Secrets Manager adds to it, which is a dash followed by six characters. The partial ARN of a
+// secret whose name is a prefix of the name of another secret does not match the other secret.
+func isPartialARN(secretID, secretARN string) bool {
+	suffix, ok := strings.CutPrefix(secretARN, secretID+"-")
+	if !ok || len(suffix) != secretARNSuffixLength {
+		return false
+	}
+	for _, c := range suffix {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func findSecretValueEntry(secretID string, entries []types.SecretValueEntry) (types.SecretValueEntry, bool) {
+	for _, entry := range entries {
+		if matchesSecretID(secretID, aws.ToString(entry.ARN), aws.ToString(entry.Name)) {
+			return entry, true
+		}
+	}
+	return types.SecretValueEntry{}, false
+}
+
+func findSecretError(secretID string, apiErrors []types.APIErrorType) (types.APIErrorType, bool) {
+	for _, apiErr := range apiErrors {
+		errSecretID := aws.ToString(apiErr.SecretId)
+		if errSecretID == secretID || isPartialARN(secretID, errSecretID) {
+			return apiErr, true
+		}
+	}
+	return types.APIErrorType{}, false
+}
+
+func batchErrorMsg(secretID string, apiErr types.APIErrorType) error {
+	errorCode := aws.ToString(apiErr.ErrorCode)
+	if errorCode == (&types.ResourceNotFoundException{}).ErrorCode() {
+		return errors.New(resourceInitializationErrMsg("'" + secretID + "' "))
+	}
+	return errors.Errorf("secret %s: %s: %s", secretID, errorCode, aws.ToString(apiErr.Message))
+}
+
 // GetSecretFromASM makes the api call to the AWS Secrets Manager service to
 // retrieve the secret value
 func GetSecretFromASM(secretID string, client SecretsManagerAPI) (string, error) {
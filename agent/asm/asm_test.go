@@ -283,3 +283,90 @@ func TestGetSecretFromASMWithInputErrorMessageUnknownError(t *testing.T) {
 	assert.Equal(t, (&types.InternalServiceError{}).ErrorCode(), ae.ErrorCode())
 	assert.Contains(t, err.Error(), fmt.Sprintf("secret %s: InternalServiceError: uhoh", valueFrom))
 }
+
+func TestBatchGetSecretsFromASM(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fullARN := "arn:aws:secretsmanager:region:account-id:secret:full-AbCdEf"
+	partialARN := "arn:aws:secretsmanager:region:account-id:secret:partial"
+	missingARN := "arn:aws:secretsmanager:region:account-id:secret:missing"
+	deniedARN := "arn:aws:secretsmanager:region:account-id:secret:denied"
+	omittedARN := "arn:aws:secretsmanager:region:account-id:secret:omitted"
+
+	asmClient := mocks.NewMockSecretsManagerAPI(ctrl)
+	asmClient.EXPECT().BatchGetSecretValue(gomock.Any(), &secretsmanager.BatchGetSecretValueInput{
+		SecretIdList: []string{fullARN, partialARN, missingARN, deniedARN, omittedARN},
+	}).Return(&secretsmanager.BatchGetSecretValueOutput{
+		SecretValues: []types.SecretValueEntry{
+			{ARN: aws.String(fullARN), Name: aws.String("full"), SecretString: aws.String("full-value")},
+			{ARN: aws.String(partialARN + "-GhIjKl"), Name: aws.String("partial"), SecretString: aws.String("partial-value")},
+		},
+		Errors: []types.APIErrorType{
+			{SecretId: aws.String(missingARN), ErrorCode: aws.String("ResourceNotFoundException")},
+			{SecretId: aws.String(deniedARN), ErrorCode: aws.String("AccessDeniedException"), Message: aws.String("denied")},
+		},
+	}, nil)
+
+	values, secretErrors, err := BatchGetSecretsFromASM(
+		[]string{fullARN, partialARN, missingARN, deniedARN, omittedARN}, asmClient)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{fullARN: "full-value", partialARN: "partial-value"}, values)
+	require.Len(t, secretErrors, 3)
+	assert.Contains(t, secretErrors[missingARN].Error(), "ResourceNotFoundException")
+	assert.Equal(t, fmt.Sprintf("secret %s: AccessDeniedException: denied", deniedARN), secretErrors[deniedARN].Error())
+	assert.Contains(t, secretErrors[omittedARN].Error(), "not returned")
+}
+
+func TestBatchGetSecretsFromASMPartialARNPrefixOfOtherSecret(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// The name of the first secret is a prefix of the name of the second one.
+	dbARN := "arn:aws:secretsmanager:region:account-id:secret:db"
+	dbProdARN := "arn:aws:secretsmanager:region:account-id:secret:db-prod"
+
+	asmClient := mocks.NewMockSecretsManagerAPI(ctrl)
+	asmClient.EXPECT().BatchGetSecretValue(gomock.Any(), gomock.Any()).Return(&secretsmanager.BatchGetSecretValueOutput{
+		SecretValues: []types.SecretValueEntry{
+			{ARN: aws.String(dbProdARN + "-AbCdEf"), Name: aws.String("db-prod"), SecretString: aws.String("prod-value")},
+			{ARN: aws.String(dbARN + "-GhIjKl"), Name: aws.String("db"), SecretString: aws.String("value")},
+		},
+	}, nil)
+
+	values, secretErrors, err := BatchGetSecretsFromASM([]string{dbARN, dbProdARN}, asmClient)
+	require.NoError(t, err)
+	assert.Empty(t, secretErrors)
+	assert.Equal(t, map[string]string{dbARN: "value", dbProdARN: "prod-value"}, values)
+
+	// The partial ARN of the first secret does not match the error of the second one.
+	asmClient.EXPECT().BatchGetSecretValue(gomock.Any(), gomock.Any()).Return(&secretsmanager.BatchGetSecretValueOutput{
+		Errors: []types.APIErrorType{
+			{SecretId: aws.String(dbProdARN + "-AbCdEf"), ErrorCode: aws.String("AccessDeniedException")},
+		},
+	}, nil)
+
+	_, secretErrors, err = BatchGetSecretsFromASM([]string{dbARN}, asmClient)
+	require.NoError(t, err)
+	assert.Contains(t, secretErrors[dbARN].Error(), "not returned")
+}
+
+func TestIsPartialARN(t *testing.T) {
+	partialARN := "arn:aws:secretsmanager:region:account-id:secret:db"
+	assert.True(t, isPartialARN(partialARN, partialARN+"-AbCd01"))
+	assert.False(t, isPartialARN(partialARN, partialARN))
+	assert.False(t, isPartialARN(partialARN, partialARN+"-prod-AbCdEf"))
+	assert.False(t, isPartialARN(partialARN, partialARN+"-AbCdE"))
+	assert.False(t, isPartialARN(partialARN, partialARN+"-AbC_Ef"))
+}
+
+func TestBatchGetSecretsFromASMError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	asmClient := mocks.NewMockSecretsManagerAPI(ctrl)
+	asmClient.EXPECT().BatchGetSecretValue(gomock.Any(), gomock.Any()).Return(nil, errors.New("throttled"))
+
+	_, _, err := BatchGetSecretsFromASM([]string{valueFrom}, asmClient)
+	assert.Error(t, err)
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"time"
@@ -59,6 +60,28 @@ const (
 	// clean up task's containers.
 	DefaultTaskCleanupWaitDuration = 3 * time.Hour
 
+	// DefaultTaskPacketCaptureRetention specifies how long finished packet captures are kept by default.
+	DefaultTaskPacketCaptureRetention = time.Hour
+
+	// DefaultVolumeExportMaxSizeBytes specifies the default upper bound on the size of a volume archive.
+	DefaultVolumeExportMaxSizeBytes = 1024 * 1024 * 1024
+
+	// DefaultLogShippingTaskBufferLimitBytes specifies the default upper bound on the size of the log
+	// records of a task buffered by the agent before they are delivered.
+	DefaultLogShippingTaskBufferLimitBytes = 8 * 1024 * 1024
+
+	// maximumSecretCacheTTL bounds how long a secret value may be cached, so that rotated secrets
+	// reach the tasks that start after the rotation.
+	maximumSecretCacheTTL = time.Hour
+
+	// defaultLogShippingFileDirName is the name of the directory under DataDir that the logs shipped to
+	// the 'file' destination are written to by default.
+	defaultLogShippingFileDirName = "containerlogs"
+
+	// defaultVolumeExportDirName is the name of the directory under DataDir that volume archives are
+	// written to by default.
+	defaultVolumeExportDirName = "volumeexports"
+
 	// DefaultPollingMetricsWaitDuration specifies the default value for polling metrics wait duration
 	// This is only used when PollMetrics is set to true
 	DefaultPollingMetricsWaitDuration = DefaultContainerMetricsPublishInterval / 2
@@ -82,6 +105,11 @@ const (
 	// has been pulled before it can be deleted.
 	DefaultImageDeletionAge = 1 * time.Hour
 
+	// DefaultHostPortReuseCooldown specifies the default value for how long the host ports released by a
+	// stopped task are kept from the containers of other services. It matches the TIME_WAIT duration of
+	// Linux, so that the connections of the stopped task are closed before its host ports are reused.
+	DefaultHostPortReuseCooldown = 1 * time.Minute
+
 	// DefaultNonECSImageDeletionAge specifies the default value for minimum amount of elapsed time after an image
 	// has been created before it can be deleted
 	DefaultNonECSImageDeletionAge = 1 * time.Hour
@@ -372,6 +400,14 @@ func (cfg *Config) validateAndOverrideBounds() error {
 	// check the PollMetrics specific configurations
 	cfg.pollMetricsOverrides()
 
+	cfg.packetCaptureOverrides()
+
+	cfg.volumeExportOverrides()
+
+	cfg.secretCacheOverrides()
+
+	cfg.logShippingOverrides()
+
 	cfg.platformOverrides()
 
 	return nil
@@ -393,6 +429,49 @@ func (cfg *Config) pollMetricsOverrides() {
 	}
 }
 
+func (cfg *Config) packetCaptureOverrides() {
+	if cfg.TaskPacketCaptureEnabled.Enabled() && cfg.TaskPacketCaptureAuthToken == "" {
+		seelog.Warn("ECS_ENABLE_TASK_PACKET_CAPTURE is set without ECS_TASK_PACKET_CAPTURE_AUTH_TOKEN. Disabling packet capture.")
+		cfg.TaskPacketCaptureEnabled = BooleanDefaultFalse{Value: ExplicitlyDisabled}
+	}
+
+	if cfg.TaskPacketCaptureRetention <= 0 {
+		cfg.TaskPacketCaptureRetention = DefaultTaskPacketCaptureRetention
+	}
+}
+
+func (cfg *Config) volumeExportOverrides() {
+	if cfg.VolumeExportDir == "" {
+		cfg.VolumeExportDir = filepath.Join(cfg.DataDir, defaultVolumeExportDirName)
+	}
+
+	if cfg.VolumeExportMaxSizeBytes <= 0 {
+		cfg.VolumeExportMaxSizeBytes = DefaultVolumeExportMaxSizeBytes
+	}
+}
+
+func (cfg *Config) secretCacheOverrides() {
+	if cfg.SecretCacheTTL < 0 {
+		cfg.SecretCacheTTL = 0
+	}
+
+	if cfg.SecretCacheTTL > maximumSecretCacheTTL {
+		seelog.Warnf("ECS_SECRET_CACHE_TTL parsed value (%s) is greater than the maximum of %s. Setting TTL to maximum.",
+			cfg.SecretCacheTTL, maximumSecretCacheTTL)
+		cfg.SecretCacheTTL = maximumSecretCacheTTL
+	}
+}
+
+func (cfg *Config) logShippingOverrides() {
+	if cfg.LogShippingFileDir == "" {
+		cfg.LogShippingFileDir = filepath.Join(cfg.DataDir, defaultLogShippingFileDirName)
+	}
+
+	if cfg.LogShippingTaskBufferLimitBytes <= 0 {
+		cfg.LogShippingTaskBufferLimitBytes = DefaultLogShippingTaskBufferLimitBytes
+	}
+}
+
 // checkMissingAndDeprecated checks all zero-valued fields for tags of the form
 // missing:STRING and acts based on that string. Current options are: fatal,
 // warn. Fatal will result in an error being returned, warn will result in a
@@ -527,6 +606,12 @@ func environmentConfig() (Config, error) {
 
 	additionalLocalRoutes, errs := parseAdditionalLocalRoutes(errs)
 
+	networkFlowRemoteCIDRs, errs := parseCIDRList("ECS_TASK_NETWORK_FLOW_REMOTE_CIDRS", errs)
+
+	networkFlowAWSServiceCIDRs, errs := parseCIDRList("ECS_TASK_NETWORK_FLOW_AWS_SERVICE_CIDRS", errs)
+
+	csiDriverSockets, errs := parseCSIDriverSockets(errs)
+
 	var err error
 	if len(errs) > 0 {
 		err = apierrors.NewMultiError(errs...)
@@ -606,8 +691,29 @@ func environmentConfig() (Config, error) {
 		ShouldExcludeIPv6PortBinding:        parseBooleanDefaultTrueConfig("ECS_EXCLUDE_IPV6_PORTBINDING"),
 		WarmPoolsSupport:                    parseBooleanDefaultFalseConfig("ECS_WARM_POOLS_CHECK"),
 		DynamicHostPortRange:                parseDynamicHostPortRange("ECS_DYNAMIC_HOST_PORT_RANGE"),
+		HostPortAllocationStrategy:          parseHostPortAllocationStrategy(),
+		HostPortReuseCooldown:               parseEnvVariableDuration("ECS_HOST_PORT_REUSE_COOLDOWN"),
 		TaskPidsLimit:                       parseTaskPidsLimit(),
 		FirelensAsyncEnabled:                parseBooleanDefaultTrueConfig("ECS_ENABLE_FIRELENS_ASYNC"),
+		TaskNetworkFlowStatsEnabled:         parseBooleanDefaultFalseConfig("ECS_ENABLE_TASK_NETWORK_FLOW_STATS"),
+		TaskNetworkFlowRemoteCIDRs:          networkFlowRemoteCIDRs,
+		TaskNetworkFlowRemotePorts:          parseReservedPorts("ECS_TASK_NETWORK_FLOW_REMOTE_PORTS"),
+		TaskNetworkFlowAWSServiceCIDRs:      networkFlowAWSServiceCIDRs,
+		TaskPacketCaptureEnabled:            parseBooleanDefaultFalseConfig("ECS_ENABLE_TASK_PACKET_CAPTURE"),
+		TaskPacketCaptureAuthToken:          os.Getenv("ECS_TASK_PACKET_CAPTURE_AUTH_TOKEN"),
+		TaskPacketCaptureRetention:          parseEnvVariableDuration("ECS_TASK_PACKET_CAPTURE_RETENTION"),
+		FirelensConfigReloadAuthToken:       os.Getenv("ECS_FIRELENS_CONFIG_RELOAD_AUTH_TOKEN"),
+		NetworkReconciliationEnabled:        parseBooleanDefaultFalseConfig("ECS_ENABLE_NETWORK_RECONCILIATION"),
+		NetworkReconciliationInterval:       parseEnvVariableDuration("ECS_NETWORK_RECONCILIATION_INTERVAL"),
+		CSIDriverSockets:                    csiDriverSockets,
+		EphemeralVolumeXFSMountPoint:        os.Getenv("ECS_EPHEMERAL_VOLUME_XFS_MOUNT_POINT"),
+		VolumeExportDir:                     os.Getenv("ECS_VOLUME_EXPORT_DIR"),
+		VolumeExportMaxSizeBytes:            parseEnvVariableInt64("ECS_VOLUME_EXPORT_MAX_SIZE_BYTES"),
+		SecretCacheTTL:                      parseEnvVariableDuration("ECS_SECRET_CACHE_TTL"),
+		EnvironmentFileHostPathAllowlist:    parseEnvironmentFileHostPathAllowlist(),
+		LogShippingEnabled:                  parseBooleanDefaultFalseConfig("ECS_ENABLE_LOG_SHIPPING"),
+		LogShippingTaskBufferLimitBytes:     parseEnvVariableInt64("ECS_LOG_SHIPPING_TASK_BUFFER_LIMIT_BYTES"),
+		LogShippingFileDir:                  os.Getenv("ECS_LOG_SHIPPING_FILE_DIR"),
 	}, err
 }
 
@@ -776,6 +776,157 @@ func TestInvalidAWSVPCAdditionalLocalRoutes(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestTaskNetworkFlowStatsConfig(t *testing.T) {
+	defer setTestEnv("ECS_ENABLE_TASK_NETWORK_FLOW_STATS", "true")()
+	defer setTestEnv("ECS_TASK_NETWORK_FLOW_REMOTE_CIDRS", `["10.0.0.0/8","2600:1f00::/24"]`)()
+	defer setTestEnv("ECS_TASK_NETWORK_FLOW_REMOTE_PORTS", "[443,5432]")()
+	defer setTestEnv("ECS_TASK_NETWORK_FLOW_AWS_SERVICE_CIDRS", `["52.216.0.0/15"]`)()
+	conf, err := environmentConfig()
+	assert.NoError(t, err)
+	assert.True(t, conf.TaskNetworkFlowStatsEnabled.Enabled())
+	assert.Equal(t, []string{"10.0.0.0/8", "2600:1f00::/24"}, conf.TaskNetworkFlowRemoteCIDRs)
+	assert.Equal(t, []uint16{443, 5432}, conf.TaskNetworkFlowRemotePorts)
+	assert.Equal(t, []string{"52.216.0.0/15"}, conf.TaskNetworkFlowAWSServiceCIDRs)
+}
+
+func TestInvalidTaskNetworkFlowRemoteCIDRs(t *testing.T) {
+	defer setTestEnv("ECS_TASK_NETWORK_FLOW_REMOTE_CIDRS", `["10.0.0.0/33"]`)()
+	_, err := environmentConfig()
+	assert.Error(t, err)
+}
+
+func TestHostPortAllocationConfig(t *testing.T) {
+	conf, err := environmentConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, utils.HostPortAllocationSequential, conf.HostPortAllocationStrategy)
+	// Left unset, so that the default is applied when the configuration is merged.
+	assert.Zero(t, conf.HostPortReuseCooldown)
+
+	defer setTestEnv("ECS_HOST_PORT_ALLOCATION_STRATEGY", "sticky")()
+	defer setTestEnv("ECS_HOST_PORT_REUSE_COOLDOWN", "5m")()
+	conf, err = environmentConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, utils.HostPortAllocationSticky, conf.HostPortAllocationStrategy)
+	assert.Equal(t, 5*time.Minute, conf.HostPortReuseCooldown)
+
+	os.Setenv("ECS_HOST_PORT_ALLOCATION_STRATEGY", "round-robin")
+	conf, err = environmentConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, utils.HostPortAllocationSequential, conf.HostPortAllocationStrategy)
+}
+
+func TestTaskPacketCaptureConfig(t *testing.T) {
+	defer setTestEnv("ECS_ENABLE_TASK_PACKET_CAPTURE", "true")()
+	defer setTestEnv("ECS_TASK_PACKET_CAPTURE_RETENTION", "30m")()
+	conf, err := environmentConfig()
+	assert.NoError(t, err)
+	assert.True(t, conf.TaskPacketCaptureEnabled.Enabled())
+	assert.Equal(t, 30*time.Minute, conf.TaskPacketCaptureRetention)
+
+	// Packet capture is not served without an auth token.
+	conf.packetCaptureOverrides()
+	assert.False(t, conf.TaskPacketCaptureEnabled.Enabled())
+
+	defer setTestEnv("ECS_TASK_PACKET_CAPTURE_AUTH_TOKEN", "secret")()
+	os.Unsetenv("ECS_TASK_PACKET_CAPTURE_RETENTION")
+	conf, err = environmentConfig()
+	assert.NoError(t, err)
+	conf.packetCaptureOverrides()
+	assert.True(t, conf.TaskPacketCaptureEnabled.Enabled())
+	assert.Equal(t, "secret", conf.TaskPacketCaptureAuthToken)
+	assert.Equal(t, DefaultTaskPacketCaptureRetention, conf.TaskPacketCaptureRetention)
+}
+
+func TestFirelensConfigReloadAuthToken(t *testing.T) {
+	defer setTestEnv("ECS_FIRELENS_CONFIG_RELOAD_AUTH_TOKEN", " secret ")()
+	conf, err := environmentConfig()
+	assert.NoError(t, err)
+	conf.trimWhitespace()
+	assert.Equal(t, "secret", conf.FirelensConfigReloadAuthToken)
+}
+
+func TestNetworkReconciliationConfig(t *testing.T) {
+	defer setTestEnv("ECS_ENABLE_NETWORK_RECONCILIATION", "true")()
+	defer setTestEnv("ECS_NETWORK_RECONCILIATION_INTERVAL", "5m")()
+	conf, err := environmentConfig()
+	assert.NoError(t, err)
+	assert.True(t, conf.NetworkReconciliationEnabled.Enabled())
+	assert.Equal(t, 5*time.Minute, conf.NetworkReconciliationInterval)
+}
+
+func TestCSIDriverSocketsConfig(t *testing.T) {
+	defer setTestEnv("ECS_CSI_DRIVER_SOCKETS", `{"csi.vendor.example.com":"/var/run/csi/vendor/csi.sock"}`)()
+	conf, err := environmentConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"csi.vendor.example.com": "/var/run/csi/vendor/csi.sock"}, conf.CSIDriverSockets)
+}
+
+func TestInvalidCSIDriverSockets(t *testing.T) {
+	defer setTestEnv("ECS_CSI_DRIVER_SOCKETS", `{"csi.vendor.example.com":"csi.sock"}`)()
+	_, err := environmentConfig()
+	assert.Error(t, err)
+}
+
+func TestVolumeExportConfig(t *testing.T) {
+	defer setTestEnv("ECS_VOLUME_EXPORT_DIR", "/var/lib/ecs/exports")()
+	defer setTestEnv("ECS_VOLUME_EXPORT_MAX_SIZE_BYTES", "1048576")()
+	conf, err := environmentConfig()
+	assert.NoError(t, err)
+	conf.volumeExportOverrides()
+	assert.Equal(t, "/var/lib/ecs/exports", conf.VolumeExportDir)
+	assert.Equal(t, int64(1048576), conf.VolumeExportMaxSizeBytes)
+
+	os.Unsetenv("ECS_VOLUME_EXPORT_DIR")
+	os.Setenv("ECS_VOLUME_EXPORT_MAX_SIZE_BYTES", "invalid")
+	conf, err = environmentConfig()
+	assert.NoError(t, err)
+	conf.DataDir = "/data"
+	conf.volumeExportOverrides()
+	assert.Equal(t, "/data/volumeexports", conf.VolumeExportDir)
+	assert.Equal(t, int64(DefaultVolumeExportMaxSizeBytes), conf.VolumeExportMaxSizeBytes)
+}
+
+func TestSecretCacheTTL(t *testing.T) {
+	defer setTestEnv("ECS_SECRET_CACHE_TTL", "5m")()
+	conf, err := environmentConfig()
+	assert.NoError(t, err)
+	conf.secretCacheOverrides()
+	assert.Equal(t, 5*time.Minute, conf.SecretCacheTTL)
+
+	os.Setenv("ECS_SECRET_CACHE_TTL", "2h")
+	conf, err = environmentConfig()
+	assert.NoError(t, err)
+	conf.secretCacheOverrides()
+	assert.Equal(t, maximumSecretCacheTTL, conf.SecretCacheTTL)
+
+	os.Setenv("ECS_SECRET_CACHE_TTL", "-1s")
+	conf, err = environmentConfig()
+	assert.NoError(t, err)
+	conf.secretCacheOverrides()
+	assert.Zero(t, conf.SecretCacheTTL)
+}
+
+func TestLogShippingConfig(t *testing.T) {
+	defer setTestEnv("ECS_ENABLE_LOG_SHIPPING", "true")()
+	defer setTestEnv("ECS_LOG_SHIPPING_TASK_BUFFER_LIMIT_BYTES", "1048576")()
+	defer setTestEnv("ECS_LOG_SHIPPING_FILE_DIR", "/var/log/ecs/containers")()
+	conf, err := environmentConfig()
+	assert.NoError(t, err)
+	conf.logShippingOverrides()
+	assert.True(t, conf.LogShippingEnabled.Enabled())
+	assert.Equal(t, int64(1048576), conf.LogShippingTaskBufferLimitBytes)
+	assert.Equal(t, "/var/log/ecs/containers", conf.LogShippingFileDir)
+
+	os.Unsetenv("ECS_LOG_SHIPPING_FILE_DIR")
+	os.Setenv("ECS_LOG_SHIPPING_TASK_BUFFER_LIMIT_BYTES", "-1")
+	conf, err = environmentConfig()
+	assert.NoError(t, err)
+	conf.DataDir = "/data"
+	conf.logShippingOverrides()
+	assert.Equal(t, "/data/containerlogs", conf.LogShippingFileDir)
+	assert.Equal(t, int64(DefaultLogShippingTaskBufferLimitBytes), conf.LogShippingTaskBufferLimitBytes)
+}
+
 func TestAWSLogsExecutionRole(t *testing.T) {
 	setTestEnv("ECS_ENABLE_AWSLOGS_EXECUTIONROLE_OVERRIDE", "true")
 	conf, err := environmentConfig()
@@ -134,10 +134,16 @@ func DefaultConfig(instanceIPCompatibility ipcompatibility.IPCompatibility) Conf
 		RuntimeStatsLogFile:                 defaultRuntimeStatsLogFile,
 		EnableRuntimeStats:                  BooleanDefaultFalse{Value: NotSet},
 		ShouldExcludeIPv6PortBinding:        shouldExcludeIPv6PortBinding,
+		HostPortReuseCooldown:               DefaultHostPortReuseCooldown,
 		CSIDriverSocketPath:                 defaultCSIDriverSocketPath,
 		NodeStageTimeout:                    nodeStageTimeout,
 		NodeUnstageTimeout:                  nodeUnstageTimeout,
 		FirelensAsyncEnabled:                BooleanDefaultTrue{Value: ExplicitlyEnabled},
+		TaskNetworkFlowStatsEnabled:         BooleanDefaultFalse{Value: NotSet},
+		TaskPacketCaptureEnabled:            BooleanDefaultFalse{Value: NotSet},
+		TaskPacketCaptureRetention:          DefaultTaskPacketCaptureRetention,
+		NetworkReconciliationEnabled:        BooleanDefaultFalse{Value: NotSet},
+		VolumeExportMaxSizeBytes:            DefaultVolumeExportMaxSizeBytes,
 	}
 }
 
@@ -83,6 +83,7 @@ func TestConfigDefault(t *testing.T) {
 	assert.False(t, cfg.PollMetrics.Enabled(), "ECS_POLL_METRICS default should be false")
 	assert.False(t, cfg.EnableRuntimeStats.Enabled(), "Default EnableRuntimeStats set incorrectly")
 	assert.True(t, cfg.ShouldExcludeIPv6PortBinding.Enabled(), "Default ShouldExcludeIPv6PortBinding set incorrectly")
+	assert.Equal(t, DefaultHostPortReuseCooldown, cfg.HostPortReuseCooldown, "Default HostPortReuseCooldown set incorrectly")
 	assert.False(t, cfg.FSxWindowsFileServerCapable.Enabled(), "Default FSxWindowsFileServerCapable set incorrectly")
 	assert.Equal(t, "/var/run/ecs/ebs-csi-driver/csi-driver.sock", cfg.CSIDriverSocketPath, "Default CSIDriverSocketPath set incorrectly")
 	assert.Equal(t, 2*time.Second, cfg.NodeStageTimeout, "Default NodeStage timeout set incorrectly")
@@ -390,3 +391,15 @@ func TestShouldExcludeIPv6PortBindingDefault(t *testing.T) {
 				ShouldExcludeIPv6PortBinding.Enabled())
 	})
 }
+
+func TestEnvironmentFileHostPathAllowlist(t *testing.T) {
+	defer setTestEnv("ECS_ENVFILE_HOST_PATH_ALLOWLIST", `["/etc/ecs/envfiles/", "relative/path"]`)()
+	conf, err := environmentConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/etc/ecs/envfiles"}, conf.EnvironmentFileHostPathAllowlist)
+
+	os.Setenv("ECS_ENVFILE_HOST_PATH_ALLOWLIST", "/etc/ecs/envfiles")
+	conf, err = environmentConfig()
+	assert.NoError(t, err)
+	assert.Empty(t, conf.EnvironmentFileHostPathAllowlist)
+}
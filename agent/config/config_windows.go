@@ -166,10 +166,16 @@ func DefaultConfig(instanceIPCompatibility ipcompatibility.IPCompatibility) Conf
 		RuntimeStatsLogFile:                 filepath.Join(ecsRoot, defaultRuntimeStatsLogFile),
 		EnableRuntimeStats:                  BooleanDefaultFalse{Value: NotSet},
 		ShouldExcludeIPv6PortBinding:        BooleanDefaultTrue{Value: ExplicitlyEnabled},
+		HostPortReuseCooldown:               DefaultHostPortReuseCooldown,
 		CSIDriverSocketPath:                 defaultCSIDriverSocketPath,
 		NodeStageTimeout:                    nodeStageTimeout,
 		NodeUnstageTimeout:                  nodeUnstageTimeout,
 		FirelensAsyncEnabled:                BooleanDefaultTrue{Value: ExplicitlyEnabled},
+		TaskNetworkFlowStatsEnabled:         BooleanDefaultFalse{Value: NotSet},
+		TaskPacketCaptureEnabled:            BooleanDefaultFalse{Value: NotSet},
+		TaskPacketCaptureRetention:          DefaultTaskPacketCaptureRetention,
+		NetworkReconciliationEnabled:        BooleanDefaultFalse{Value: NotSet},
+		VolumeExportMaxSizeBytes:            DefaultVolumeExportMaxSizeBytes,
 	}
 }
 
@@ -72,6 +72,7 @@ func TestConfigDefault(t *testing.T) {
 	assert.False(t, cfg.DependentContainersPullUpfront.Enabled(), "Default DependentContainersPullUpfront set incorrectly")
 	assert.False(t, cfg.EnableRuntimeStats.Enabled(), "Default EnableRuntimeStats set incorrectly")
 	assert.True(t, cfg.ShouldExcludeIPv6PortBinding.Enabled(), "Default ShouldExcludeIPv6PortBinding set incorrectly")
+	assert.Equal(t, DefaultHostPortReuseCooldown, cfg.HostPortReuseCooldown, "Default HostPortReuseCooldown set incorrectly")
 	assert.True(t, cfg.FSxWindowsFileServerCapable.Enabled(), "Default FSxWindowsFileServerCapable set incorrectly")
 	assert.Equal(t, "C:\\ProgramData\\Amazon\\ECS\\ebs-csi-driver\\csi-driver.sock", cfg.CSIDriverSocketPath, "Default CSIDriverSocketPath set incorrectly")
 	assert.Equal(t, 600*time.Second, cfg.NodeStageTimeout, "Default NodeStage timeout set incorrectly")
@@ -17,7 +17,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -243,6 +245,52 @@ func parseAdditionalLocalRoutes(errs []error) ([]cniTypes.IPNet, []error) {
 	return additionalLocalRoutes, errs
 }
 
+// parseCIDRList parses a JSON array of CIDRs, e.g. ["10.0.0.0/8","2600:1f00::/24"], from the
+// environment variable.
+func parseCIDRList(envVarName string, errs []error) ([]string, []error) {
+	var cidrs []string
+	cidrsEnv := os.Getenv(envVarName)
+	if cidrsEnv == "" {
+		return nil, errs
+	}
+	if err := json.Unmarshal([]byte(cidrsEnv), &cidrs); err != nil {
+		seelog.Errorf("Invalid format for %s, expected a json array of CIDRs: %v", envVarName, err)
+		return nil, append(errs, err)
+	}
+	for _, cidr := range cidrs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			seelog.Errorf("Invalid CIDR in %s: %v", envVarName, err)
+			return nil, append(errs, err)
+		}
+	}
+	return cidrs, errs
+}
+
+// parseCSIDriverSockets parses the JSON hash of CSI driver names to node plugin socket paths set by
+// ECS_CSI_DRIVER_SOCKETS.
+func parseCSIDriverSockets(errs []error) (map[string]string, []error) {
+	var csiDriverSockets map[string]string
+	csiDriverSocketsEnv := os.Getenv("ECS_CSI_DRIVER_SOCKETS")
+	if csiDriverSocketsEnv == "" {
+		return nil, errs
+	}
+	if err := json.Unmarshal([]byte(csiDriverSocketsEnv), &csiDriverSockets); err != nil {
+		wrappedErr := fmt.Errorf("Invalid format for ECS_CSI_DRIVER_SOCKETS. Expected a json hash: %v", err)
+		seelog.Error(wrappedErr)
+		return nil, append(errs, wrappedErr)
+	}
+	for driver, socketPath := range csiDriverSockets {
+		if !filepath.IsAbs(socketPath) {
+			wrappedErr := fmt.Errorf("Invalid socket path for CSI driver %s in ECS_CSI_DRIVER_SOCKETS: %q is not absolute",
+				driver, socketPath)
+			seelog.Error(wrappedErr)
+			return nil, append(errs, wrappedErr)
+		}
+		seelog.Debugf("Setting CSI driver %v socket: %v", driver, socketPath)
+	}
+	return csiDriverSockets, errs
+}
+
 func parseBooleanDefaultFalseConfig(envVarName string) BooleanDefaultFalse {
 	boolDefaultFalseConfig := BooleanDefaultFalse{Value: NotSet}
 	configString := strings.TrimSpace(os.Getenv(envVarName))
@@ -357,6 +405,19 @@ func parseEnvVariableUint16(envVar string) uint16 {
 	return var16
 }
 
+func parseEnvVariableInt64(envVar string) int64 {
+	envVal := os.Getenv(envVar)
+	var var64 int64
+	if envVal != "" {
+		var err error
+		var64, err = strconv.ParseInt(envVal, 10, 64)
+		if err != nil {
+			seelog.Warnf("Invalid format for \""+envVar+"\" environment variable; expected integer. err %v", err)
+		}
+	}
+	return var64
+}
+
 func parseEnvVariableDuration(envVar string) time.Duration {
 	var duration time.Duration
 	envVal := os.Getenv(envVar)
@@ -372,6 +433,27 @@ func parseEnvVariableDuration(envVar string) time.Duration {
 	return duration
 }
 
+func parseEnvironmentFileHostPathAllowlist() []string {
+	allowlistFromEnv := os.Getenv("ECS_ENVFILE_HOST_PATH_ALLOWLIST")
+	if allowlistFromEnv == "" {
+		return nil
+	}
+	var paths []string
+	if err := json.Unmarshal([]byte(allowlistFromEnv), &paths); err != nil {
+		seelog.Warnf("Invalid format for \"ECS_ENVFILE_HOST_PATH_ALLOWLIST\", expected a json list of string. error: %v", err)
+		return nil
+	}
+	var allowlist []string
+	for _, path := range paths {
+		if !filepath.IsAbs(path) {
+			seelog.Warnf("Ignoring path %q of \"ECS_ENVFILE_HOST_PATH_ALLOWLIST\", it is not absolute", path)
+			continue
+		}
+		allowlist = append(allowlist, filepath.Clean(path))
+	}
+	return allowlist
+}
+
 func parseImageCleanupExclusionList(envVar string) []string {
 	imageEnv := os.Getenv(envVar)
 	var imageCleanupExclusionList []string
@@ -414,6 +496,19 @@ func parseDynamicHostPortRange(dynamicHostPortRangeEnv string) string {
 	return dynamicHostPortRange
 }
 
+func parseHostPortAllocationStrategy() string {
+	strategy := strings.TrimSpace(os.Getenv("ECS_HOST_PORT_ALLOCATION_STRATEGY"))
+	if strategy == "" {
+		return utils.HostPortAllocationSequential
+	}
+	if !utils.IsValidHostPortAllocationStrategy(strategy) {
+		seelog.Warnf("Invalid value for ECS_HOST_PORT_ALLOCATION_STRATEGY: %s, using the %s strategy",
+			strategy, utils.HostPortAllocationSequential)
+		return utils.HostPortAllocationSequential
+	}
+	return strategy
+}
+
 func getDefaultDynamicHostPortRange() string {
 	startHostPortRange, endHostPortRange, err := getDynamicHostPortRange()
 	if err != nil {
@@ -380,6 +380,16 @@ type Config struct {
 	// This defaults to the platform specific ephemeral host port range
 	DynamicHostPortRange string
 
+	// HostPortAllocationStrategy specifies how the agent picks host ports from the dynamic host port
+	// range: "sequential" (the default), "random", "least-recently-used" or "sticky", which reuses the
+	// host ports of the previous tasks of the same service. It is set by ECS_HOST_PORT_ALLOCATION_STRATEGY.
+	HostPortAllocationStrategy string
+
+	// HostPortReuseCooldown is how long the host ports released by a stopped task are kept from the
+	// containers of other services, e.g. while load balancer targets are draining. It is set by
+	// ECS_HOST_PORT_REUSE_COOLDOWN and defaults to DefaultHostPortReuseCooldown.
+	HostPortReuseCooldown time.Duration
+
 	// TaskPidsLimit specifies the per-task pids limit cgroup setting for each
 	// task launched on this container instance. This setting maps to the pids.max
 	// cgroup setting at the ECS task level.
@@ -400,6 +410,93 @@ type Config struct {
 	// fluentd log driver. Ref: https://docs.docker.com/engine/logging/drivers/fluentd/#fluentd-async
 	FirelensAsyncEnabled BooleanDefaultTrue
 
+	// TaskNetworkFlowStatsEnabled specifies whether the agent should account the traffic of awsvpc tasks
+	// by remote endpoint, in addition to the interface level counters. This is disabled by default and
+	// can be enabled by means of the ECS_ENABLE_TASK_NETWORK_FLOW_STATS environment variable.
+	TaskNetworkFlowStatsEnabled BooleanDefaultFalse
+
+	// TaskNetworkFlowRemoteCIDRs are the remote CIDRs that task traffic is grouped by when network flow
+	// accounting is enabled, as a JSON array set by ECS_TASK_NETWORK_FLOW_REMOTE_CIDRS.
+	TaskNetworkFlowRemoteCIDRs []string
+
+	// TaskNetworkFlowRemotePorts are the remote TCP and UDP ports that task traffic is grouped by when
+	// network flow accounting is enabled, as a JSON array set by ECS_TASK_NETWORK_FLOW_REMOTE_PORTS.
+	TaskNetworkFlowRemotePorts []uint16
+
+	// TaskNetworkFlowAWSServiceCIDRs are the CIDRs of the AWS service prefix lists. Task traffic to them
+	// is accounted to a separate bucket, ahead of the remote CIDRs and ports. They are set as a JSON
+	// array by ECS_TASK_NETWORK_FLOW_AWS_SERVICE_CIDRS.
+	TaskNetworkFlowAWSServiceCIDRs []string
+
+	// TaskPacketCaptureEnabled specifies whether the introspection endpoint should let operators capture
+	// the traffic of awsvpc tasks. This is disabled by default and can be enabled by means of the
+	// ECS_ENABLE_TASK_PACKET_CAPTURE environment variable.
+	TaskPacketCaptureEnabled BooleanDefaultFalse
+
+	// TaskPacketCaptureAuthToken is the bearer token that packet capture requests to the introspection
+	// endpoint must carry. Packet capture stays disabled unless it is set by ECS_TASK_PACKET_CAPTURE_AUTH_TOKEN.
+	TaskPacketCaptureAuthToken string `trim:"true"`
+
+	// FirelensConfigReloadAuthToken is the bearer token that firelens config reload requests to the introspection
+	// endpoint must carry. The reload endpoint is only served when it is set by ECS_FIRELENS_CONFIG_RELOAD_AUTH_TOKEN.
+	FirelensConfigReloadAuthToken string `trim:"true"`
+
+	// TaskPacketCaptureRetention is how long a finished packet capture is kept in the data directory
+	// before it is removed, set by ECS_TASK_PACKET_CAPTURE_RETENTION.
+	TaskPacketCaptureRetention time.Duration
+
+	// NetworkReconciliationEnabled specifies whether the agent periodically cleans up the network
+	// namespaces, addresses and DNS configs that netlib left behind for tasks that are no longer
+	// running. It is only supported on Linux, and is enabled by ECS_ENABLE_NETWORK_RECONCILIATION.
+	NetworkReconciliationEnabled BooleanDefaultFalse
+
+	// NetworkReconciliationInterval is the interval between two network reconciliation passes, set by
+	// ECS_NETWORK_RECONCILIATION_INTERVAL. It defaults to 15 minutes.
+	NetworkReconciliationInterval time.Duration
+
+	// CSIDriverSockets maps the name of a CSI driver to the path of the socket of its node plugin on
+	// the host, for the 'csi' task volumes that use it. They are set as a JSON hash by
+	// ECS_CSI_DRIVER_SOCKETS, e.g. {"csi.vendor.example.com":"/var/run/csi/vendor/csi.sock"}.
+	CSIDriverSockets map[string]string
+
+	// EphemeralVolumeXFSMountPoint is the mount point of an XFS filesystem mounted with project quotas,
+	// under which the 'ephemeral' task volumes with the 'xfsquota' backend are created, set by
+	// ECS_EPHEMERAL_VOLUME_XFS_MOUNT_POINT. The path must be the same for the agent and the host.
+	EphemeralVolumeXFSMountPoint string `trim:"true"`
+
+	// VolumeExportDir is the directory that the archives of the task volumes exported before task
+	// cleanup are written to, set by ECS_VOLUME_EXPORT_DIR. It defaults to a directory under DataDir.
+	// Archives destined for S3 are staged here and removed once uploaded.
+	VolumeExportDir string `trim:"true"`
+
+	// VolumeExportMaxSizeBytes is the upper bound on the size of a single volume archive, set by
+	// ECS_VOLUME_EXPORT_MAX_SIZE_BYTES. A task may ask for a lower limit, but not a higher one.
+	VolumeExportMaxSizeBytes int64
+
+	// SecretCacheTTL is how long the values of SSM and Secrets Manager secrets are cached on the host
+	// and shared by the tasks with the same execution role, set by ECS_SECRET_CACHE_TTL. Secrets are
+	// retrieved for each task, as they are by default, when it is not set.
+	SecretCacheTTL time.Duration
+
+	// EnvironmentFileHostPathAllowlist are the directories of the host that the environment files of
+	// type 'host' may be read from, set as a JSON list by ECS_ENVFILE_HOST_PATH_ALLOWLIST, e.g.
+	// ["/etc/ecs/envfiles"]. When the agent runs in a container, the directories must be bind mounted
+	// at the same path in the agent container. Environment files cannot be read from the host when it
+	// is not set.
+	EnvironmentFileHostPathAllowlist []string
+
+	// LogShippingEnabled specifies whether the agent ships the logs of the containers that use the
+	// 'awslogshipper' log driver itself, without a log router container, set by ECS_ENABLE_LOG_SHIPPING.
+	LogShippingEnabled BooleanDefaultFalse
+
+	// LogShippingTaskBufferLimitBytes is the upper bound on the size of the log records of a task that
+	// are read from its containers but not delivered yet, set by ECS_LOG_SHIPPING_TASK_BUFFER_LIMIT_BYTES.
+	LogShippingTaskBufferLimitBytes int64
+
+	// LogShippingFileDir is the directory that the logs shipped to the 'file' destination are written
+	// to, set by ECS_LOG_SHIPPING_FILE_DIR. It defaults to a directory under DataDir.
+	LogShippingFileDir string `trim:"true"`
+
 	// IP version compatibility for the container instance's default network
 	InstanceIPCompatibility ipcompatibility.IPCompatibility
 }
@@ -24,6 +24,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/ecs-agent/api/attachment/resource"
 	generaldata "github.com/aws/amazon-ecs-agent/ecs-agent/data"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/modeltransformer"
+	netlibdata "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/data"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
 	bolt "go.etcd.io/bbolt"
 )
@@ -32,13 +33,17 @@ const (
 	dbName = "agent.db"
 	dbMode = 0600
 
-	containersBucketName     = "containers"
-	tasksBucketName          = "tasks"
-	imagesBucketName         = "images"
-	eniAttachmentsBucketName = "eniattachments"
-	resAttachmentsBucketName = "resattachments"
-	metadataBucketName       = "metadata"
-	emptyAgentVersionMsg     = "No version info available in boltDB. Either this is a fresh instance, or we were using state file to persist data. Transformer not applicable."
+	containersBucketName          = "containers"
+	tasksBucketName               = "tasks"
+	imagesBucketName              = "images"
+	eniAttachmentsBucketName      = "eniattachments"
+	resAttachmentsBucketName      = "resattachments"
+	metadataBucketName            = "metadata"
+	hostPortAllocationsBucketName = "hostportallocations"
+	networkNamespacesBucketName   = "networknamespaces"
+	geneveDstPortsBucketName      = "genevedstports"
+	xfsProjectIDsBucketName       = "xfsprojectids"
+	emptyAgentVersionMsg          = "No version info available in boltDB. Either this is a fresh instance, or we were using state file to persist data. Transformer not applicable."
 )
 
 var (
@@ -52,6 +57,10 @@ var (
 		eniAttachmentsBucketName,
 		resAttachmentsBucketName,
 		metadataBucketName,
+		hostPortAllocationsBucketName,
+		networkNamespacesBucketName,
+		geneveDstPortsBucketName,
+		xfsProjectIDsBucketName,
 	}
 )
 
@@ -98,6 +107,23 @@ type Client interface {
 	// GetResourceAttachments gets the data of all the resouce attachments.
 	GetResourceAttachments() ([]*resource.ResourceAttachment, error)
 
+	// SaveHostPortAllocation saves the data of a host port allocation.
+	SaveHostPortAllocation(*HostPortAllocation) error
+	// DeleteHostPortAllocation deletes the data of a host port allocation.
+	DeleteHostPortAllocation(string) error
+	// GetHostPortAllocations gets the data of all the host port allocations.
+	GetHostPortAllocations() ([]*HostPortAllocation, error)
+
+	// SaveXFSProjectID saves the XFS project ID allocated to the directory of an ephemeral volume.
+	SaveXFSProjectID(uint32, string) error
+	// DeleteXFSProjectID deletes the data of an XFS project ID.
+	DeleteXFSProjectID(uint32) error
+	// GetXFSProjectIDs gets the directories of all the allocated XFS project IDs.
+	GetXFSProjectIDs() (map[uint32]string, error)
+
+	// NetworkDataClient persists the network namespaces created by netlib.
+	netlibdata.NetworkDataClient
+
 	// SaveMetadata saves a key value pair of metadata.
 	SaveMetadata(string, string) error
 	// GetMetadata gets the value of a certain kind of metadata.
@@ -0,0 +1,70 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package data
+
+import (
+	"encoding/json"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// HostPortAllocation is the saved data of a host port range allocated to a container port of a task.
+type HostPortAllocation struct {
+	// Key identifies the container port across the tasks of a service.
+	Key      string `json:"key"`
+	TaskARN  string `json:"taskArn"`
+	Protocol string `json:"protocol"`
+	// HostPortRange is the allocated host port range, e.g. "32768-32768" for a single host port.
+	HostPortRange string    `json:"hostPortRange"`
+	AllocatedAt   time.Time `json:"allocatedAt"`
+	// ReleasedAt is when the task of the allocation stopped, zero while it is running.
+	ReleasedAt time.Time `json:"releasedAt"`
+}
+
+// ID returns the key of the allocation in the database. Allocating the same host port range again
+// replaces the previous allocation.
+func (a *HostPortAllocation) ID() string {
+	return a.Protocol + "/" + a.HostPortRange
+}
+
+func (c *client) SaveHostPortAllocation(allocation *HostPortAllocation) error {
+	return c.DB.Batch(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(hostPortAllocationsBucketName))
+		return c.Accessor.PutObject(b, allocation.ID(), allocation)
+	})
+}
+
+func (c *client) DeleteHostPortAllocation(id string) error {
+	return c.DB.Batch(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(hostPortAllocationsBucketName))
+		return b.Delete([]byte(id))
+	})
+}
+
+func (c *client) GetHostPortAllocations() ([]*HostPortAllocation, error) {
+	var allocations []*HostPortAllocation
+	err := c.DB.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(hostPortAllocationsBucketName))
+		return c.Accessor.Walk(bucket, func(id string, data []byte) error {
+			allocation := HostPortAllocation{}
+			if err := json.Unmarshal(data, &allocation); err != nil {
+				return err
+			}
+			allocations = append(allocations, &allocation)
+			return nil
+		})
+	})
+	return allocations, err
+}
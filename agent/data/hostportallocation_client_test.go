@@ -0,0 +1,51 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManageHostPortAllocations(t *testing.T) {
+	testClient := newTestClient(t)
+
+	allocation := &HostPortAllocation{
+		Key:           "service/web/80/tcp",
+		TaskARN:       "task-arn",
+		Protocol:      "tcp",
+		HostPortRange: "32768-32768",
+		AllocatedAt:   time.Now().UTC().Round(0),
+	}
+	require.NoError(t, testClient.SaveHostPortAllocation(allocation))
+	allocation.ReleasedAt = allocation.AllocatedAt.Add(time.Minute)
+	require.NoError(t, testClient.SaveHostPortAllocation(allocation))
+
+	res, err := testClient.GetHostPortAllocations()
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, allocation.Key, res[0].Key)
+	assert.True(t, allocation.ReleasedAt.Equal(res[0].ReleasedAt))
+
+	require.NoError(t, testClient.DeleteHostPortAllocation(allocation.ID()))
+	res, err = testClient.GetHostPortAllocations()
+	require.NoError(t, err)
+	assert.Len(t, res, 0)
+}
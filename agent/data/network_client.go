@@ -0,0 +1,135 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// defaultGeneveDstPort is the IANA assigned destination port of GENEVE.
+	defaultGeneveDstPort = 6081
+	// geneveDstPortRangeStart and geneveDstPortRangeEnd bound the ports assigned to the GENEVE
+	// interfaces of a VNI once the default port is taken.
+	geneveDstPortRangeStart = 6082
+	geneveDstPortRangeEnd   = 6181
+)
+
+// SaveNetworkNamespace saves the data of a network namespace created by netlib. Network namespaces
+// are keyed by name, which starts with the ID of their task.
+func (c *client) SaveNetworkNamespace(netNS *tasknetworkconfig.NetworkNamespace) error {
+	if netNS == nil || netNS.Name == "" {
+		return errors.New("invalid network namespace: name is empty")
+	}
+	return c.DB.Batch(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(networkNamespacesBucketName))
+		return c.Accessor.PutObject(b, netNS.Name, netNS)
+	})
+}
+
+func (c *client) GetNetworkNamespace(netNSName string) (*tasknetworkconfig.NetworkNamespace, error) {
+	netNS := &tasknetworkconfig.NetworkNamespace{}
+	err := c.DB.View(func(tx *bolt.Tx) error {
+		return c.Accessor.GetObject(tx, networkNamespacesBucketName, netNSName, netNS)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return netNS, nil
+}
+
+func (c *client) GetNetworkNamespacesByTaskID(taskID string) ([]*tasknetworkconfig.NetworkNamespace, error) {
+	var netNSs []*tasknetworkconfig.NetworkNamespace
+	err := c.DB.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(networkNamespacesBucketName))
+		return c.Accessor.WalkPrefix(bucket, taskID+"-", func(id string, data []byte) error {
+			netNS := &tasknetworkconfig.NetworkNamespace{}
+			if err := json.Unmarshal(data, netNS); err != nil {
+				return err
+			}
+			netNSs = append(netNSs, netNS)
+			return nil
+		})
+	})
+	return netNSs, err
+}
+
+func (c *client) ListNetworkNamespaces() ([]*tasknetworkconfig.NetworkNamespace, error) {
+	var netNSs []*tasknetworkconfig.NetworkNamespace
+	err := c.DB.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(networkNamespacesBucketName))
+		return c.Accessor.Walk(bucket, func(id string, data []byte) error {
+			netNS := &tasknetworkconfig.NetworkNamespace{}
+			if err := json.Unmarshal(data, netNS); err != nil {
+				return err
+			}
+			netNSs = append(netNSs, netNS)
+			return nil
+		})
+	})
+	return netNSs, err
+}
+
+func (c *client) DeleteNetworkNamespace(netNSName string) error {
+	return c.DB.Batch(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(networkNamespacesBucketName))
+		return b.Delete([]byte(netNSName))
+	})
+}
+
+// AssignGeneveDstPort returns the default GENEVE destination port if no other interface of the
+// VNI uses it, or else a free port picked at random from the configured range.
+func (c *client) AssignGeneveDstPort(vni string) (uint16, error) {
+	var port uint16
+	err := c.DB.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(geneveDstPortsBucketName))
+		if b.Get([]byte(geneveDstPortKey(vni, defaultGeneveDstPort))) == nil {
+			port = defaultGeneveDstPort
+			return b.Put([]byte(geneveDstPortKey(vni, port)), []byte{})
+		}
+
+		size := geneveDstPortRangeEnd - geneveDstPortRangeStart + 1
+		offset := rand.Intn(size)
+		for i := 0; i < size; i++ {
+			candidate := uint16(geneveDstPortRangeStart + (offset+i)%size)
+			if b.Get([]byte(geneveDstPortKey(vni, candidate))) == nil {
+				port = candidate
+				return b.Put([]byte(geneveDstPortKey(vni, port)), []byte{})
+			}
+		}
+		return errors.Errorf("no GENEVE destination port is available for VNI %s", vni)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return port, nil
+}
+
+func (c *client) ReleaseGeneveDstPort(port uint16, vni string) error {
+	return c.DB.Batch(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(geneveDstPortsBucketName))
+		return b.Delete([]byte(geneveDstPortKey(vni, port)))
+	})
+}
+
+func geneveDstPortKey(vni string, port uint16) string {
+	return fmt.Sprintf("%s/%d", vni, port)
+}
@@ -0,0 +1,99 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package data
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/status"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testNetNSTaskID      = "0123456789abcdef0123456789abcdef"
+	testOtherNetNSTaskID = "0123456789abcdef0123456789abcdee"
+)
+
+func TestManageNetworkNamespaces(t *testing.T) {
+	testClient := newTestClient(t)
+
+	bridgeNetNS := &tasknetworkconfig.NetworkNamespace{
+		Name:       testNetNSTaskID + "-bridge",
+		Path:       "/var/run/netns/" + testNetNSTaskID + "-bridge",
+		KnownState: status.NetworkReady,
+	}
+	otherNetNS := &tasknetworkconfig.NetworkNamespace{
+		Name: testOtherNetNSTaskID + "-bridge",
+	}
+	require.NoError(t, testClient.SaveNetworkNamespace(bridgeNetNS))
+	require.NoError(t, testClient.SaveNetworkNamespace(otherNetNS))
+	require.Error(t, testClient.SaveNetworkNamespace(&tasknetworkconfig.NetworkNamespace{}))
+
+	res, err := testClient.GetNetworkNamespace(bridgeNetNS.Name)
+	require.NoError(t, err)
+	assert.Equal(t, bridgeNetNS.Path, res.Path)
+	assert.Equal(t, status.NetworkReady, res.KnownState)
+
+	byTask, err := testClient.GetNetworkNamespacesByTaskID(testNetNSTaskID)
+	require.NoError(t, err)
+	require.Len(t, byTask, 1)
+	assert.Equal(t, bridgeNetNS.Name, byTask[0].Name)
+
+	all, err := testClient.ListNetworkNamespaces()
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+
+	require.NoError(t, testClient.DeleteNetworkNamespace(bridgeNetNS.Name))
+	_, err = testClient.GetNetworkNamespace(bridgeNetNS.Name)
+	assert.Error(t, err)
+	all, err = testClient.ListNetworkNamespaces()
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	assert.Equal(t, otherNetNS.Name, all[0].Name)
+}
+
+func TestAssignGeneveDstPort(t *testing.T) {
+	testClient := newTestClient(t)
+
+	port, err := testClient.AssignGeneveDstPort("vni-1")
+	require.NoError(t, err)
+	assert.Equal(t, uint16(defaultGeneveDstPort), port)
+
+	// The default port of another VNI is still free.
+	otherPort, err := testClient.AssignGeneveDstPort("vni-2")
+	require.NoError(t, err)
+	assert.Equal(t, uint16(defaultGeneveDstPort), otherPort)
+
+	assigned := map[uint16]bool{port: true}
+	for i := geneveDstPortRangeStart; i <= geneveDstPortRangeEnd; i++ {
+		port, err = testClient.AssignGeneveDstPort("vni-1")
+		require.NoError(t, err)
+		assert.False(t, assigned[port], "port %d assigned twice", port)
+		assert.True(t, port >= geneveDstPortRangeStart && port <= geneveDstPortRangeEnd)
+		assigned[port] = true
+	}
+	_, err = testClient.AssignGeneveDstPort("vni-1")
+	assert.Error(t, err)
+
+	require.NoError(t, testClient.ReleaseGeneveDstPort(geneveDstPortRangeStart, "vni-1"))
+	port, err = testClient.AssignGeneveDstPort("vni-1")
+	require.NoError(t, err)
+	assert.Equal(t, uint16(geneveDstPortRangeStart), port)
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/engine/image"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/api/attachment/resource"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
 )
 
 type noopClient struct{}
@@ -93,6 +94,58 @@ func (c *noopClient) GetResourceAttachments() ([]*resource.ResourceAttachment, e
 	return nil, nil
 }
 
+func (c *noopClient) SaveHostPortAllocation(*HostPortAllocation) error {
+	return nil
+}
+
+func (c *noopClient) DeleteHostPortAllocation(string) error {
+	return nil
+}
+
+func (c *noopClient) GetHostPortAllocations() ([]*HostPortAllocation, error) {
+	return nil, nil
+}
+
+func (c *noopClient) SaveXFSProjectID(uint32, string) error {
+	return nil
+}
+
+func (c *noopClient) DeleteXFSProjectID(uint32) error {
+	return nil
+}
+
+func (c *noopClient) GetXFSProjectIDs() (map[uint32]string, error) {
+	return nil, nil
+}
+
+func (c *noopClient) SaveNetworkNamespace(*tasknetworkconfig.NetworkNamespace) error {
+	return nil
+}
+
+func (c *noopClient) GetNetworkNamespace(string) (*tasknetworkconfig.NetworkNamespace, error) {
+	return nil, nil
+}
+
+func (c *noopClient) GetNetworkNamespacesByTaskID(string) ([]*tasknetworkconfig.NetworkNamespace, error) {
+	return nil, nil
+}
+
+func (c *noopClient) ListNetworkNamespaces() ([]*tasknetworkconfig.NetworkNamespace, error) {
+	return nil, nil
+}
+
+func (c *noopClient) DeleteNetworkNamespace(string) error {
+	return nil
+}
+
+func (c *noopClient) AssignGeneveDstPort(string) (uint16, error) {
+	return defaultGeneveDstPort, nil
+}
+
+func (c *noopClient) ReleaseGeneveDstPort(uint16, string) error {
+	return nil
+}
+
 func (c *noopClient) SaveMetadata(string, string) error {
 	return nil
 }
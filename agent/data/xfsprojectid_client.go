@@ -0,0 +1,56 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package data
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+func (c *client) SaveXFSProjectID(id uint32, path string) error {
+	return c.DB.Batch(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(xfsProjectIDsBucketName))
+		return c.Accessor.PutObject(b, strconv.FormatUint(uint64(id), 10), path)
+	})
+}
+
+func (c *client) DeleteXFSProjectID(id uint32) error {
+	return c.DB.Batch(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(xfsProjectIDsBucketName))
+		return b.Delete([]byte(strconv.FormatUint(uint64(id), 10)))
+	})
+}
+
+func (c *client) GetXFSProjectIDs() (map[uint32]string, error) {
+	projectIDs := make(map[uint32]string)
+	err := c.DB.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(xfsProjectIDsBucketName))
+		return c.Accessor.Walk(bucket, func(key string, data []byte) error {
+			id, err := strconv.ParseUint(key, 10, 32)
+			if err != nil {
+				return errors.Wrapf(err, "invalid XFS project id %q", key)
+			}
+			var path string
+			if err := json.Unmarshal(data, &path); err != nil {
+				return err
+			}
+			projectIDs[uint32(id)] = path
+			return nil
+		})
+	})
+	return projectIDs, err
+}
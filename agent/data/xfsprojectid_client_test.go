@@ -0,0 +1,42 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManageXFSProjectIDs(t *testing.T) {
+	testClient := newTestClient(t)
+
+	require.NoError(t, testClient.SaveXFSProjectID(100000, "/mnt/xfs/ephemeral/task/scratch"))
+	require.NoError(t, testClient.SaveXFSProjectID(100001, "/mnt/xfs/ephemeral/task/cache"))
+	res, err := testClient.GetXFSProjectIDs()
+	require.NoError(t, err)
+	assert.Equal(t, map[uint32]string{
+		100000: "/mnt/xfs/ephemeral/task/scratch",
+		100001: "/mnt/xfs/ephemeral/task/cache",
+	}, res)
+
+	require.NoError(t, testClient.DeleteXFSProjectID(100000))
+	res, err = testClient.GetXFSProjectIDs()
+	require.NoError(t, err)
+	assert.Equal(t, map[uint32]string{100001: "/mnt/xfs/ephemeral/task/cache"}, res)
+}
@@ -169,6 +169,10 @@ type DockerClient interface {
 	// A timeout value and a context should be provided for the request.
 	RemoveContainer(context.Context, string, time.Duration) error
 
+	// SignalContainer sends a signal, such as SIGHUP, to the main process of a running container identified by
+	// the name. A timeout value and a context should be provided for the request.
+	SignalContainer(context.Context, string, string, time.Duration) error
+
 	// InspectContainer returns information about the specified container. A timeout value and a context should be
 	// provided for the request.
 	InspectContainer(context.Context, string, time.Duration) (*types.ContainerJSON, error)
@@ -185,6 +189,10 @@ type DockerClient interface {
 	// and a context should be provided for the request.
 	InspectContainerExec(ctx context.Context, execID string, timeout time.Duration) (*types.ContainerExecInspect, error)
 
+	// CopyFromContainer returns a tar archive of the given path of the container, which may be stopped. The caller
+	// must close the archive. A context should be provided so the request can be canceled.
+	CopyFromContainer(ctx context.Context, containerID string, srcPath string) (io.ReadCloser, error)
+
 	// ListContainers returns the set of containers known to the Docker daemon. A timeout value and a context
 	// should be provided for the request.
 	ListContainers(context.Context, bool, time.Duration) ListContainersResponse
@@ -217,6 +225,12 @@ type DockerClient interface {
 	// be canceled.
 	Stats(context.Context, string, time.Duration) (<-chan *types.StatsJSON, <-chan error)
 
+	// ContainerLogs returns the stream of the stdout and stderr logs of a container recorded by its log driver
+	// since the given time, in the multiplexed format of the docker API, with the timestamp of each line at its
+	// start. The stream follows the logs until the container stops. A context should be provided so the request can
+	// be canceled.
+	ContainerLogs(context.Context, string, time.Time) (io.ReadCloser, error)
+
 	// Version returns the version of the Docker daemon.
 	Version(context.Context, time.Duration) (string, error)
 
@@ -910,6 +924,60 @@ func (dg *dockerGoClient) removeContainer(ctx context.Context, dockerID string)
 		})
 }
 
+func (dg *dockerGoClient) SignalContainer(ctx context.Context, dockerID string, signal string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	// Buffered channel so in the case of timeout it takes one write, never gets
+	// read, and can still be GC'd
+	response := make(chan error, 1)
+	go func() { response <- dg.signalContainer(ctx, dockerID, signal) }()
+	// Wait until we get a response or for the 'done' context channel
+	select {
+	case resp := <-response:
+		return resp
+	case <-ctx.Done():
+		err := ctx.Err()
+		// Context has either expired or canceled. If it has timed out,
+		// send back the DockerTimeoutError
+		if err == context.DeadlineExceeded {
+			return &DockerTimeoutError{dockerclient.SignalContainerTimeout, "signaling"}
+		}
+		return &CannotSignalContainerError{err}
+	}
+}
+
+func (dg *dockerGoClient) signalContainer(ctx context.Context, dockerID string, signal string) error {
+	client, err := dg.sdkDockerClient()
+	if err != nil {
+		return &CannotSignalContainerError{err}
+	}
+	if err := client.ContainerKill(ctx, dockerID, signal); err != nil {
+		return &CannotSignalContainerError{err}
+	}
+	return nil
+}
+
+func (dg *dockerGoClient) ContainerLogs(ctx context.Context, dockerID string, since time.Time) (io.ReadCloser, error) {
+	client, err := dg.sdkDockerClient()
+	if err != nil {
+		return nil, &CannotGetContainerLogsError{err}
+	}
+	options := dockercontainer.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Timestamps: true,
+		Follow:     true,
+	}
+	if !since.IsZero() {
+		options.Since = fmt.Sprintf("%d.%09d", since.Unix(), since.Nanosecond())
+	}
+	logs, err := client.ContainerLogs(ctx, dockerID, options)
+	if err != nil {
+		return nil, &CannotGetContainerLogsError{err}
+	}
+	return logs, nil
+}
+
 func (dg *dockerGoClient) containerMetadata(ctx context.Context, id string) DockerContainerMetadata {
 	ctx, cancel := context.WithTimeout(ctx, dockerclient.InspectContainerTimeout)
 	defer cancel()
@@ -1699,6 +1767,19 @@ func (dg *dockerGoClient) removeImage(ctx context.Context, imageName string) err
 	return err
 }
 
+// CopyFromContainer returns a tar archive of the given path of the container
+func (dg *dockerGoClient) CopyFromContainer(ctx context.Context, containerID string, srcPath string) (io.ReadCloser, error) {
+	client, err := dg.sdkDockerClient()
+	if err != nil {
+		return nil, err
+	}
+	archive, _, err := client.CopyFromContainer(ctx, containerID, srcPath)
+	if err != nil {
+		return nil, err
+	}
+	return archive, nil
+}
+
 // LoadImage invokes loads an image from an input stream, with a specified timeout
 func (dg *dockerGoClient) LoadImage(ctx context.Context, inputStream io.Reader, timeout time.Duration) error {
 	ctx, cancel := context.WithTimeout(ctx, timeout)
@@ -888,6 +888,67 @@ func TestRemoveContainerTimeout(t *testing.T) {
 	wait.Done()
 }
 
+func TestSignalContainer(t *testing.T) {
+	mockDockerSDK, client, _, _, _, done := dockerClientSetup(t)
+	defer done()
+
+	mockDockerSDK.EXPECT().ContainerKill(gomock.Any(), "id", "SIGHUP").Return(nil)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	err := client.SignalContainer(ctx, "id", "SIGHUP", dockerclient.SignalContainerTimeout)
+	assert.NoError(t, err)
+}
+
+func TestSignalContainerError(t *testing.T) {
+	mockDockerSDK, client, _, _, _, done := dockerClientSetup(t)
+	defer done()
+
+	mockDockerSDK.EXPECT().ContainerKill(gomock.Any(), "id", "SIGHUP").Return(errors.New("container is not running"))
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	err := client.SignalContainer(ctx, "id", "SIGHUP", dockerclient.SignalContainerTimeout)
+	assert.Error(t, err)
+	assert.Equal(t, "CannotSignalContainerError", err.(apierrors.NamedError).ErrorName())
+}
+
+func TestContainerLogs(t *testing.T) {
+	mockDockerSDK, client, _, _, _, done := dockerClientSetup(t)
+	defer done()
+
+	since := time.Unix(1700000000, 5)
+	mockDockerSDK.EXPECT().ContainerLogs(gomock.Any(), "id", dockercontainer.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Timestamps: true,
+		Follow:     true,
+		Since:      "1700000000.000000005",
+	}).Return(io.NopCloser(strings.NewReader("logs")), nil)
+
+	logs, err := client.ContainerLogs(context.TODO(), "id", since)
+	require.NoError(t, err)
+	content, err := io.ReadAll(logs)
+	require.NoError(t, err)
+	assert.Equal(t, "logs", string(content))
+}
+
+func TestContainerLogsError(t *testing.T) {
+	mockDockerSDK, client, _, _, _, done := dockerClientSetup(t)
+	defer done()
+
+	mockDockerSDK.EXPECT().ContainerLogs(gomock.Any(), "id", dockercontainer.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Timestamps: true,
+		Follow:     true,
+	}).Return(nil, errors.New("configured logging driver does not support reading"))
+
+	_, err := client.ContainerLogs(context.TODO(), "id", time.Time{})
+	assert.Error(t, err)
+	assert.Equal(t, "CannotGetContainerLogsError", err.(apierrors.NamedError).ErrorName())
+}
+
 func TestRemoveContainer(t *testing.T) {
 	mockDockerSDK, client, _, _, _, done := dockerClientSetup(t)
 	defer done()
@@ -1710,6 +1771,25 @@ func TestRemoveImage(t *testing.T) {
 	assert.NoError(t, err, "Did not expect error, err: %v", err)
 }
 
+func TestCopyFromContainer(t *testing.T) {
+	mockDockerSDK, client, _, _, _, done := dockerClientSetup(t)
+	defer done()
+
+	mockDockerSDK.EXPECT().CopyFromContainer(gomock.Any(), "id", "/data").Return(
+		ioutil.NopCloser(strings.NewReader("archive")), types.ContainerPathStat{}, nil)
+	archive, err := client.CopyFromContainer(context.TODO(), "id", "/data")
+	require.NoError(t, err)
+	defer archive.Close()
+	contents, err := ioutil.ReadAll(archive)
+	require.NoError(t, err)
+	assert.Equal(t, "archive", string(contents))
+
+	mockDockerSDK.EXPECT().CopyFromContainer(gomock.Any(), "id", "/missing").Return(
+		nil, types.ContainerPathStat{}, errors.New("no such file"))
+	_, err = client.CopyFromContainer(context.TODO(), "id", "/missing")
+	assert.Error(t, err)
+}
+
 func TestLoadImageHappyPath(t *testing.T) {
 	mockDockerSDK, client, _, _, _, done := dockerClientSetup(t)
 	defer done()
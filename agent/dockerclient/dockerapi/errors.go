@@ -285,6 +285,34 @@ func (err CannotRemoveContainerError) ErrorName() string {
 	return "CannotRemoveContainerError"
 }
 
+// CannotSignalContainerError indicates any error when trying to send a signal to a container
+type CannotSignalContainerError struct {
+	FromError error
+}
+
+func (err CannotSignalContainerError) Error() string {
+	return err.FromError.Error()
+}
+
+// ErrorName returns name of the CannotSignalContainerError
+func (err CannotSignalContainerError) ErrorName() string {
+	return "CannotSignalContainerError"
+}
+
+// CannotGetContainerLogsError indicates any error when trying to get the logs of a container
+type CannotGetContainerLogsError struct {
+	FromError error
+}
+
+func (err CannotGetContainerLogsError) Error() string {
+	return err.FromError.Error()
+}
+
+// ErrorName returns name of the CannotGetContainerLogsError
+func (err CannotGetContainerLogsError) ErrorName() string {
+	return "CannotGetContainerLogsError"
+}
+
 // CannotDescribeContainerError indicates any error when trying to describe a container
 type CannotDescribeContainerError struct {
 	FromError error
@@ -45,6 +45,8 @@ const (
 	StopContainerTimeout = 30 * time.Second
 	// RemoveContainerTimeout is the timeout for the RemoveContainer API.
 	RemoveContainerTimeout = 5 * time.Minute
+	// SignalContainerTimeout is the timeout for the SignalContainer API.
+	SignalContainerTimeout = 30 * time.Second
 
 	// CreateVolumeTimeout is the timeout for CreateVolume API.
 	CreateVolumeTimeout = 5 * time.Minute
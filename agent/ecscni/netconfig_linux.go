@@ -17,6 +17,7 @@
 package ecscni
 
 import (
+	"encoding/json"
 	"fmt"
 	"net"
 
@@ -258,3 +259,48 @@ func NewServiceConnectNetworkConfig(
 	}
 	return defaultServiceConnectIfName, networkConfig, nil
 }
+
+// ServiceConnectNetfilterRules returns the netfilter rules that the ecs-serviceconnect CNI plugin sets
+// up for a network config created by NewServiceConnectNetworkConfig, as the iptables commands the plugin
+// runs. Traffic to the intercept ports is redirected to the ingress listeners, and traffic to the VIP
+// CIDRs to the egress listener, for each IP family enabled in the config. Only the NAT redirect mode
+// is supported, since the rules of the TPROXY redirect mode depend on the Service Connect container.
+func ServiceConnectNetfilterRules(networkConfig *libcni.NetworkConfig) ([]string, error) {
+	var scConfig ServiceConnectConfig
+	if err := json.Unmarshal(networkConfig.Bytes, &scConfig); err != nil {
+		return nil, fmt.Errorf("ServiceConnectNetfilterRules: invalid network config: %w", err)
+	}
+	if scConfig.EgressConfig != nil && scConfig.EgressConfig.RedirectMode != string(NAT) {
+		return nil, fmt.Errorf("ServiceConnectNetfilterRules: unsupported redirect mode %s",
+			scConfig.EgressConfig.RedirectMode)
+	}
+
+	var rules []string
+	for _, family := range []struct {
+		enabled bool
+		command string
+		vipCIDR func(*EgressConfigJSON) string
+	}{
+		{scConfig.EnableIPv4, "iptables", func(egress *EgressConfigJSON) string { return egress.VIP.IPv4CIDR }},
+		{scConfig.EnableIPv6, "ip6tables", func(egress *EgressConfigJSON) string { return egress.VIP.IPv6CIDR }},
+	} {
+		if !family.enabled {
+			continue
+		}
+		for _, ingress := range scConfig.IngressConfig {
+			if ingress.InterceptPort == 0 {
+				continue
+			}
+			rules = append(rules, fmt.Sprintf("%s -t nat -A PREROUTING -p tcp --dport %d -j REDIRECT --to-port %d",
+				family.command, ingress.InterceptPort, ingress.ListenerPort))
+		}
+		if scConfig.EgressConfig == nil {
+			continue
+		}
+		if vipCIDR := family.vipCIDR(scConfig.EgressConfig); vipCIDR != "" {
+			rules = append(rules, fmt.Sprintf("%s -t nat -A OUTPUT -p tcp -d %s -j REDIRECT --to-port %d",
+				family.command, vipCIDR, scConfig.EgressConfig.ListenerPort))
+		}
+	}
+	return rules, nil
+}
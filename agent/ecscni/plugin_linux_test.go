@@ -855,6 +855,33 @@ func TestConstructServiceConnectNetworkConfig(t *testing.T) {
 	}
 }
 
+func TestServiceConnectNetfilterRules(t *testing.T) {
+	config := defaultTestServiceConnectConfig()
+	interceptPort := uint16(8080)
+	config.IngressConfig[0].InterceptPort = &interceptPort
+	config.EgressConfig.VIP.IPV6CIDR = "fd00:ec2::/64"
+
+	_, netConfig, err := NewServiceConnectNetworkConfig(config, NAT, false, true, true, &Config{})
+	require.NoError(t, err)
+	rules, err := ServiceConnectNetfilterRules(netConfig)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		fmt.Sprintf("iptables -t nat -A PREROUTING -p tcp --dport 8080 -j REDIRECT --to-port %d",
+			testIngressListenerPort),
+		fmt.Sprintf("iptables -t nat -A OUTPUT -p tcp -d 169.254.0.0/16 -j REDIRECT --to-port %d",
+			testEgressConfigListenerPort),
+		fmt.Sprintf("ip6tables -t nat -A PREROUTING -p tcp --dport 8080 -j REDIRECT --to-port %d",
+			testIngressListenerPort),
+		fmt.Sprintf("ip6tables -t nat -A OUTPUT -p tcp -d fd00:ec2::/64 -j REDIRECT --to-port %d",
+			testEgressConfigListenerPort),
+	}, rules)
+
+	_, netConfig, err = NewServiceConnectNetworkConfig(config, TPROXY, false, true, false, &Config{})
+	require.NoError(t, err)
+	_, err = ServiceConnectNetfilterRules(netConfig)
+	assert.Error(t, err)
+}
+
 func TestConstructServiceConnectNetworkConfig_EmptyEgress(t *testing.T) {
 	testCases := []struct {
 		redirectMode            RedirectMode
@@ -0,0 +1,131 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
+
+	"github.com/docker/docker/api/types"
+)
+
+const (
+	hostModeContainerAddress = "127.0.0.1"
+)
+
+// execActionPollInterval is how often the exec process of an exec action is inspected
+// to find out whether it has exited. It is a variable so tests can shorten it.
+var execActionPollInterval = time.Second
+
+// runExecAction runs the action command inside the container and waits for it to exit
+// successfully or for the context to be done.
+func (engine *DockerTaskEngine) runExecAction(ctx context.Context, dockerID string,
+	action *apicontainer.ExecAction) error {
+	execRes, err := engine.client.CreateContainerExec(ctx, dockerID, types.ExecConfig{
+		Detach: true,
+		Cmd:    action.Command,
+	}, dockerclient.ContainerExecCreateTimeout)
+	if err != nil {
+		return fmt.Errorf("unable to create exec: %w", err)
+	}
+	err = engine.client.StartContainerExec(ctx, execRes.ID, types.ExecStartCheck{Detach: true, Tty: false},
+		dockerclient.ContainerExecStartTimeout)
+	if err != nil {
+		return fmt.Errorf("unable to start exec: %w", err)
+	}
+
+	ticker := time.NewTicker(execActionPollInterval)
+	defer ticker.Stop()
+	for {
+		inspect, err := engine.client.InspectContainerExec(ctx, execRes.ID, dockerclient.ContainerExecInspectTimeout)
+		if err != nil {
+			return fmt.Errorf("unable to inspect exec: %w", err)
+		}
+		if !inspect.Running {
+			if inspect.ExitCode != 0 {
+				return fmt.Errorf("exec exited with exit code: %d", inspect.ExitCode)
+			}
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("exec did not finish in time: %w", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// runHTTPGetAction sends a GET request to the action endpoint of the container and
+// expects a successful response.
+func runHTTPGetAction(ctx context.Context, task *apitask.Task, container *apicontainer.Container,
+	action *apicontainer.HTTPGetAction) error {
+	address, err := containerAddress(task, container)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("http://%s%s", net.JoinHostPort(address, strconv.Itoa(int(action.Port))), action.GetPath())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("unable to create request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("request returned status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// runTCPSocketAction opens a TCP connection to the action port of the container.
+func runTCPSocketAction(ctx context.Context, task *apitask.Task, container *apicontainer.Container,
+	action *apicontainer.TCPSocketAction) error {
+	address, err := containerAddress(task, container)
+	if err != nil {
+		return err
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(address, strconv.Itoa(int(action.Port))))
+	if err != nil {
+		return fmt.Errorf("unable to connect: %w", err)
+	}
+	return conn.Close()
+}
+
+// containerAddress returns the address at which the agent can reach the container.
+func containerAddress(task *apitask.Task, container *apicontainer.Container) (string, error) {
+	switch {
+	case task.IsNetworkModeAWSVPC():
+		if address := task.GetLocalIPAddress(); address != "" {
+			return address, nil
+		}
+	case task.IsNetworkModeHost():
+		return hostModeContainerAddress, nil
+	default:
+		if address, ok := getContainerHostIP(container.GetNetworkSettings()); ok {
+			return address, nil
+		}
+	}
+	return "", fmt.Errorf("unable to determine address of container in %s network mode", task.GetNetworkMode())
+}
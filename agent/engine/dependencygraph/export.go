@@ -0,0 +1,281 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package dependencygraph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource"
+	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
+)
+
+const (
+	// NodeTypeContainer is the node type of a container in the exported graph
+	NodeTypeContainer = "container"
+	// NodeTypeResource is the node type of a task resource in the exported graph
+	NodeTypeResource = "resource"
+
+	// EdgeKindOrdering is an edge created from a container's dependsOn configuration
+	EdgeKindOrdering = "ordering"
+	// EdgeKindTransition is an edge created from a container's transition dependencies
+	EdgeKindTransition = "transition"
+	// EdgeKindResource is an edge created from a task resource's dependencies on containers
+	EdgeKindResource = "resource"
+	// EdgeKindSteadyState is an edge created from a container's steady state dependencies
+	EdgeKindSteadyState = "steadyState"
+)
+
+// Graph is an exportable snapshot of the dependencies between the containers
+// and resources of a task.
+type Graph struct {
+	TaskARN string `json:"TaskARN"`
+	Nodes   []Node `json:"Nodes"`
+	Edges   []Edge `json:"Edges"`
+}
+
+// Node is a container or task resource in the dependency graph.
+type Node struct {
+	ID            string `json:"ID"`
+	Name          string `json:"Name"`
+	Type          string `json:"Type"`
+	KnownStatus   string `json:"KnownStatus"`
+	DesiredStatus string `json:"DesiredStatus"`
+}
+
+// Edge is a dependency of the 'From' node on the 'To' node. Condition is the
+// dependsOn condition or the status that satisfies the dependency, and
+// DependentStatus is the status of 'From' that is blocked until then.
+type Edge struct {
+	From            string `json:"From"`
+	To              string `json:"To"`
+	Kind            string `json:"Kind"`
+	Condition       string `json:"Condition"`
+	DependentStatus string `json:"DependentStatus,omitempty"`
+	Satisfied       bool   `json:"Satisfied"`
+}
+
+// BuildGraph builds a snapshot of the dependency graph of a task, reporting
+// whether each dependency is currently satisfied.
+func BuildGraph(task *apitask.Task) *Graph {
+	graph := &Graph{
+		TaskARN: task.Arn,
+		Nodes:   []Node{},
+		Edges:   []Edge{},
+	}
+
+	containers := make(map[string]*apicontainer.Container)
+	for _, container := range task.Containers {
+		containers[container.Name] = container
+		graph.Nodes = append(graph.Nodes, Node{
+			ID:            containerNodeID(container.Name),
+			Name:          container.Name,
+			Type:          NodeTypeContainer,
+			KnownStatus:   container.GetKnownStatus().String(),
+			DesiredStatus: container.GetDesiredStatus().String(),
+		})
+	}
+
+	resources := make(map[string]taskresource.TaskResource)
+	for _, resource := range task.GetResources() {
+		resources[resource.GetName()] = resource
+		graph.Nodes = append(graph.Nodes, Node{
+			ID:            resourceNodeID(resource.GetName()),
+			Name:          resource.GetName(),
+			Type:          NodeTypeResource,
+			KnownStatus:   resource.StatusString(resource.GetKnownStatus()),
+			DesiredStatus: resource.StatusString(resource.GetDesiredStatus()),
+		})
+	}
+
+	for _, container := range task.Containers {
+		graph.Edges = append(graph.Edges, containerEdges(container, containers, resources)...)
+	}
+	for _, resource := range task.GetResources() {
+		graph.Edges = append(graph.Edges, resourceEdges(resource, containers)...)
+	}
+
+	sort.SliceStable(graph.Nodes, func(i, j int) bool {
+		return graph.Nodes[i].ID < graph.Nodes[j].ID
+	})
+	sort.SliceStable(graph.Edges, func(i, j int) bool {
+		a, b := graph.Edges[i], graph.Edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.DependentStatus < b.DependentStatus
+	})
+	return graph
+}
+
+func containerEdges(container *apicontainer.Container,
+	containers map[string]*apicontainer.Container,
+	resources map[string]taskresource.TaskResource) []Edge {
+	var edges []Edge
+	from := containerNodeID(container.Name)
+
+	for _, dependsOn := range container.GetDependsOn() {
+		dep, ok := containers[dependsOn.ContainerName]
+		edges = append(edges, Edge{
+			From:      from,
+			To:        containerNodeID(dependsOn.ContainerName),
+			Kind:      EdgeKindOrdering,
+			Condition: dependsOn.Condition,
+			Satisfied: ok && orderingConditionSatisfied(dep, dependsOn.Condition),
+		})
+	}
+
+	for dependentStatus, dependencySet := range container.TransitionDependenciesMap {
+		for _, containerDependency := range dependencySet.ContainerDependencies {
+			dep, ok := containers[containerDependency.ContainerName]
+			edges = append(edges, Edge{
+				From:            from,
+				To:              containerNodeID(containerDependency.ContainerName),
+				Kind:            EdgeKindTransition,
+				Condition:       containerDependency.SatisfiedStatus.String(),
+				DependentStatus: dependentStatus.String(),
+				Satisfied:       ok && dep.GetKnownStatus() >= containerDependency.SatisfiedStatus,
+			})
+		}
+		for _, resourceDependency := range dependencySet.ResourceDependencies {
+			condition := fmt.Sprintf("%d", resourceDependency.GetRequiredStatus())
+			dep, ok := resources[resourceDependency.Name]
+			if ok {
+				condition = dep.StatusString(resourceDependency.GetRequiredStatus())
+			}
+			edges = append(edges, Edge{
+				From:            from,
+				To:              resourceNodeID(resourceDependency.Name),
+				Kind:            EdgeKindTransition,
+				Condition:       condition,
+				DependentStatus: dependentStatus.String(),
+				Satisfied:       ok && dep.GetKnownStatus() >= resourceDependency.GetRequiredStatus(),
+			})
+		}
+	}
+
+	for _, name := range container.SteadyStateDependencies {
+		dep, ok := containers[name]
+		satisfied := false
+		if ok {
+			satisfied = dep.GetKnownStatus() >= dep.GetSteadyStateStatus()
+		}
+		edges = append(edges, Edge{
+			From:            from,
+			To:              containerNodeID(name),
+			Kind:            EdgeKindSteadyState,
+			Condition:       apicontainerstatus.ContainerRunning.String(),
+			DependentStatus: apicontainerstatus.ContainerCreated.String(),
+			Satisfied:       satisfied,
+		})
+	}
+	return edges
+}
+
+func resourceEdges(resource taskresource.TaskResource, containers map[string]*apicontainer.Container) []Edge {
+	var edges []Edge
+	from := resourceNodeID(resource.GetName())
+	for status := resourcestatus.ResourceStatus(0); status <= resource.TerminalStatus(); status++ {
+		for _, containerDependency := range resource.GetContainerDependencies(status) {
+			dep, ok := containers[containerDependency.ContainerName]
+			edges = append(edges, Edge{
+				From:            from,
+				To:              containerNodeID(containerDependency.ContainerName),
+				Kind:            EdgeKindResource,
+				Condition:       containerDependency.SatisfiedStatus.String(),
+				DependentStatus: resource.StatusString(status),
+				Satisfied:       ok && dep.GetKnownStatus() >= containerDependency.SatisfiedStatus,
+			})
+		}
+	}
+	return edges
+}
+
+// orderingConditionSatisfied returns true if the known state of the dependency
+// container currently fulfills the dependsOn condition.
+func orderingConditionSatisfied(dependsOnContainer *apicontainer.Container, condition string) bool {
+	knownStatus := dependsOnContainer.GetKnownStatus()
+	switch condition {
+	case createCondition:
+		return knownStatus >= apicontainerstatus.ContainerCreated
+	case startCondition:
+		return dependsOnContainer.IsKnownSteadyState() || knownStatus == apicontainerstatus.ContainerStopped
+	case successCondition:
+		return knownStatus == apicontainerstatus.ContainerStopped && hasDependencyStoppedSuccessfully(dependsOnContainer)
+	case completeCondition:
+		return knownStatus == apicontainerstatus.ContainerStopped && dependsOnContainer.GetKnownExitCode() != nil
+	case healthyCondition:
+		return dependsOnContainer.HealthStatusShouldBeReported() &&
+			dependsOnContainer.GetHealthStatus().Status == apicontainerstatus.ContainerHealthy
+	case readyCondition:
+		return dependsOnContainer.HasReadinessProbe() && dependsOnContainer.IsReady()
+	default:
+		return false
+	}
+}
+
+// DOT renders the graph in the Graphviz DOT language. Satisfied dependencies
+// are drawn as solid edges and unsatisfied ones as dashed edges.
+func (g *Graph) DOT() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", dotQuote(g.TaskARN))
+	b.WriteString("  rankdir=LR;\n")
+	for _, node := range g.Nodes {
+		shape := "box"
+		if node.Type == NodeTypeResource {
+			shape = "ellipse"
+		}
+		label := fmt.Sprintf("%s\n%s -> %s", node.Name, node.KnownStatus, node.DesiredStatus)
+		fmt.Fprintf(&b, "  %s [shape=%s, label=%s];\n", dotQuote(node.ID), shape, dotQuote(label))
+	}
+	for _, edge := range g.Edges {
+		style := "dashed"
+		if edge.Satisfied {
+			style = "solid"
+		}
+		label := edge.Kind + ":" + edge.Condition
+		if edge.DependentStatus != "" {
+			label += " (" + edge.DependentStatus + ")"
+		}
+		fmt.Fprintf(&b, "  %s -> %s [style=%s, label=%s];\n",
+			dotQuote(edge.From), dotQuote(edge.To), style, dotQuote(label))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func containerNodeID(name string) string {
+	return NodeTypeContainer + "/" + name
+}
+
+func resourceNodeID(name string) string {
+	return NodeTypeResource + "/" + name
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
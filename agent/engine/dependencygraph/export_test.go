@@ -0,0 +1,184 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package dependencygraph
+
+import (
+	"encoding/json"
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource"
+	mock_taskresource "github.com/aws/amazon-ecs-agent/agent/taskresource/mocks"
+	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildGraph(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	resource := mock_taskresource.NewMockTaskResource(ctrl)
+	resource.EXPECT().GetName().Return("cgroup").AnyTimes()
+	resource.EXPECT().GetKnownStatus().Return(resourcestatus.ResourceStatus(1)).AnyTimes()
+	resource.EXPECT().GetDesiredStatus().Return(resourcestatus.ResourceStatus(1)).AnyTimes()
+	resource.EXPECT().TerminalStatus().Return(resourcestatus.ResourceStatus(2)).AnyTimes()
+	resource.EXPECT().GetContainerDependencies(gomock.Any()).DoAndReturn(
+		func(status resourcestatus.ResourceStatus) []apicontainer.ContainerDependency {
+			if status == resourcestatus.ResourceStatus(2) {
+				return []apicontainer.ContainerDependency{{
+					ContainerName:   "app",
+					SatisfiedStatus: apicontainerstatus.ContainerStopped,
+				}}
+			}
+			return nil
+		}).AnyTimes()
+	resource.EXPECT().StatusString(gomock.Any()).DoAndReturn(
+		func(status resourcestatus.ResourceStatus) string {
+			return map[resourcestatus.ResourceStatus]string{0: "NONE", 1: "CREATED", 2: "REMOVED"}[status]
+		}).AnyTimes()
+
+	sidecar := &apicontainer.Container{
+		Name:              "sidecar",
+		KnownStatusUnsafe: apicontainerstatus.ContainerRunning,
+	}
+	sidecar.SetDesiredStatus(apicontainerstatus.ContainerRunning)
+	app := &apicontainer.Container{
+		Name:              "app",
+		KnownStatusUnsafe: apicontainerstatus.ContainerCreated,
+		DependsOnUnsafe: []apicontainer.DependsOn{
+			{ContainerName: "sidecar", Condition: startCondition},
+			{ContainerName: "init", Condition: successCondition},
+		},
+		TransitionDependenciesMap: apicontainer.TransitionDependenciesMap{
+			apicontainerstatus.ContainerPulled: {
+				ResourceDependencies: []apicontainer.ResourceDependency{{
+					Name:           "cgroup",
+					RequiredStatus: resourcestatus.ResourceStatus(1),
+				}},
+			},
+		},
+	}
+	app.SetDesiredStatus(apicontainerstatus.ContainerRunning)
+	task := &apitask.Task{
+		Arn:                "arn:aws:ecs:us-west-2:123456789012:task/cluster/abc",
+		Containers:         []*apicontainer.Container{app, sidecar},
+		ResourcesMapUnsafe: map[string][]taskresource.TaskResource{"cgroup": {resource}},
+	}
+
+	graph := BuildGraph(task)
+	assert.Equal(t, task.Arn, graph.TaskARN)
+	assert.Equal(t, []Node{
+		{ID: "container/app", Name: "app", Type: NodeTypeContainer, KnownStatus: "CREATED", DesiredStatus: "RUNNING"},
+		{ID: "container/sidecar", Name: "sidecar", Type: NodeTypeContainer, KnownStatus: "RUNNING", DesiredStatus: "RUNNING"},
+		{ID: "resource/cgroup", Name: "cgroup", Type: NodeTypeResource, KnownStatus: "CREATED", DesiredStatus: "CREATED"},
+	}, graph.Nodes)
+	assert.Equal(t, []Edge{
+		{From: "container/app", To: "container/init", Kind: EdgeKindOrdering, Condition: successCondition, Satisfied: false},
+		{From: "container/app", To: "container/sidecar", Kind: EdgeKindOrdering, Condition: startCondition, Satisfied: true},
+		{From: "container/app", To: "resource/cgroup", Kind: EdgeKindTransition, Condition: "CREATED",
+			DependentStatus: "PULLED", Satisfied: true},
+		{From: "resource/cgroup", To: "container/app", Kind: EdgeKindResource, Condition: "STOPPED",
+			DependentStatus: "REMOVED", Satisfied: false},
+	}, graph.Edges)
+
+	_, err := json.Marshal(graph)
+	require.NoError(t, err)
+}
+
+func TestOrderingConditionSatisfied(t *testing.T) {
+	exitCode := 0
+	failedExitCode := 1
+	testCases := []struct {
+		name        string
+		container   *apicontainer.Container
+		condition   string
+		isSatisfied bool
+	}{
+		{
+			name:        "create satisfied by created container",
+			container:   &apicontainer.Container{KnownStatusUnsafe: apicontainerstatus.ContainerCreated},
+			condition:   createCondition,
+			isSatisfied: true,
+		},
+		{
+			name:        "start not satisfied by created container",
+			container:   &apicontainer.Container{KnownStatusUnsafe: apicontainerstatus.ContainerCreated},
+			condition:   startCondition,
+			isSatisfied: false,
+		},
+		{
+			name: "success satisfied by zero exit code",
+			container: &apicontainer.Container{KnownStatusUnsafe: apicontainerstatus.ContainerStopped,
+				KnownExitCodeUnsafe: &exitCode},
+			condition:   successCondition,
+			isSatisfied: true,
+		},
+		{
+			name: "success not satisfied by non-zero exit code",
+			container: &apicontainer.Container{KnownStatusUnsafe: apicontainerstatus.ContainerStopped,
+				KnownExitCodeUnsafe: &failedExitCode},
+			condition:   successCondition,
+			isSatisfied: false,
+		},
+		{
+			name: "complete satisfied by non-zero exit code",
+			container: &apicontainer.Container{KnownStatusUnsafe: apicontainerstatus.ContainerStopped,
+				KnownExitCodeUnsafe: &failedExitCode},
+			condition:   completeCondition,
+			isSatisfied: true,
+		},
+		{
+			name:        "unknown condition",
+			container:   &apicontainer.Container{KnownStatusUnsafe: apicontainerstatus.ContainerRunning},
+			condition:   "UNKNOWN",
+			isSatisfied: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.isSatisfied, orderingConditionSatisfied(tc.container, tc.condition))
+		})
+	}
+}
+
+func TestGraphDOT(t *testing.T) {
+	graph := &Graph{
+		TaskARN: "task-arn",
+		Nodes: []Node{
+			{ID: "container/app", Name: "app", Type: NodeTypeContainer, KnownStatus: "NONE", DesiredStatus: "RUNNING"},
+			{ID: "resource/cgroup", Name: "cgroup", Type: NodeTypeResource, KnownStatus: "NONE", DesiredStatus: "CREATED"},
+		},
+		Edges: []Edge{
+			{From: "container/app", To: "resource/cgroup", Kind: EdgeKindTransition, Condition: "CREATED",
+				DependentStatus: "PULLED", Satisfied: false},
+		},
+	}
+	expected := `digraph "task-arn" {
+  rankdir=LR;
+  "container/app" [shape=box, label="app\nNONE -> RUNNING"];
+  "resource/cgroup" [shape=ellipse, label="cgroup\nNONE -> CREATED"];
+  "container/app" -> "resource/cgroup" [style=dashed, label="transition:CREATED (PULLED)"];
+}
+`
+	assert.Equal(t, expected, graph.DOT())
+}
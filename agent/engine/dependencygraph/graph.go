@@ -40,6 +40,9 @@ const (
 	completeCondition = "COMPLETE"
 	// HealthyCondition ensures that a container progresses to next state only when dependency container is healthy
 	healthyCondition = "HEALTHY"
+	// ReadyCondition ensures that a container progresses to next state only when the readiness probe
+	// of the dependency container has succeeded
+	readyCondition = "READY"
 	// 0 is the standard exit code for success.
 	successExitCode = 0
 )
@@ -88,28 +91,12 @@ func (de *dependencyError) IsTerminal() bool {
 
 // ValidDependencies takes a task and verifies that it is possible to allow all
 // containers within it to reach the desired status by proceeding in some
-// order.
+// order. Use ValidateDependencies to find out why it is not possible.
 func ValidDependencies(task *apitask.Task, cfg *config.Config) bool {
-	unresolved := make([]*apicontainer.Container, len(task.Containers))
-	resolved := make([]*apicontainer.Container, 0, len(task.Containers))
-
-	copy(unresolved, task.Containers)
-
-OuterLoop:
-	for len(unresolved) > 0 {
-		for i, tryResolve := range unresolved {
-			if dependenciesCanBeResolved(tryResolve, resolved, cfg) {
-				resolved = append(resolved, tryResolve)
-				unresolved = append(unresolved[:i], unresolved[i+1:]...)
-				// Break out of the inner loop now that we modified the slice
-				// we're looping over
-				continue OuterLoop
-			}
-		}
-		log.Warnf("Could not resolve some containers: [%v] for task %v", unresolved, task)
+	if _, err := ValidateDependencies(task, cfg); err != nil {
+		log.Warnf("Could not resolve dependencies for task %v: %v", task, err)
 		return false
 	}
-
 	return true
 }
 
@@ -300,7 +287,7 @@ func verifyContainerOrderingStatusResolvable(target *apicontainer.Container, exi
 			return nil, &dependencyError{err: fmt.Errorf("dependency graph: failed to resolve container ordering dependency [%v] for target [%v] as dependency did not exit successfully.", dependencyContainer, target), isTerminal: true}
 		}
 
-		// For any of the dependency conditions - START/COMPLETE/SUCCESS/HEALTHY, if the dependency container has
+		// For any of the dependency conditions - START/COMPLETE/SUCCESS/HEALTHY/READY, if the dependency container has
 		// not started and will not start in the future, this dependency can never be resolved.
 		if dependencyContainer.HasNotAndWillNotStart() {
 			return nil, &dependencyError{err: fmt.Errorf("dependency graph: failed to resolve container ordering dependency [%v] for target [%v] because dependency will never start", dependencyContainer, target), isTerminal: true}
@@ -401,6 +388,9 @@ func containerOrderingDependenciesCanResolve(target *apicontainer.Container,
 	case healthyCondition:
 		return verifyContainerOrderingStatus(dependsOnContainer) && dependsOnContainer.HealthStatusShouldBeReported()
 
+	case readyCondition:
+		return verifyContainerOrderingStatus(dependsOnContainer) && dependsOnContainer.HasReadinessProbe()
+
 	default:
 		return false
 	}
@@ -472,6 +462,9 @@ func containerOrderingDependenciesIsResolved(target *apicontainer.Container,
 		return dependsOnContainer.HealthStatusShouldBeReported() &&
 			dependsOnContainer.GetHealthStatus().Status == apicontainerstatus.ContainerHealthy
 
+	case readyCondition:
+		return dependsOnContainer.HasReadinessProbe() && dependsOnContainer.IsReady()
+
 	default:
 		return false
 	}
@@ -482,7 +475,7 @@ func hasDependencyTimedOut(dependOnContainer *apicontainer.Container, dependency
 		return false
 	}
 	switch dependencyCondition {
-	case successCondition, completeCondition, healthyCondition:
+	case successCondition, completeCondition, healthyCondition, readyCondition:
 		return time.Now().After(dependOnContainer.GetStartedAt().Add(dependOnContainer.GetStartTimeout()))
 	default:
 		return false
@@ -1435,6 +1435,75 @@ func TestContainerOrderingHealthyConditionIsResolved(t *testing.T) {
 	}
 }
 
+func TestContainerOrderingReadyConditionIsResolved(t *testing.T) {
+	testcases := []struct {
+		name          string
+		probe         *apicontainer.ReadinessProbe
+		ready         bool
+		expectResolve bool
+	}{
+		{
+			name:          "probe succeeded",
+			probe:         &apicontainer.ReadinessProbe{TCPSocket: &apicontainer.TCPSocketAction{Port: 80}},
+			ready:         true,
+			expectResolve: true,
+		},
+		{
+			name:          "probe not succeeded yet",
+			probe:         &apicontainer.ReadinessProbe{TCPSocket: &apicontainer.TCPSocketAction{Port: 80}},
+			expectResolve: false,
+		},
+		{
+			name:          "no probe",
+			expectResolve: false,
+		},
+	}
+	cfg := config.Config{}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			target := &apicontainer.Container{
+				KnownStatusUnsafe:   apicontainerstatus.ContainerManifestPulled,
+				DesiredStatusUnsafe: apicontainerstatus.ContainerCreated,
+			}
+			dep := &apicontainer.Container{
+				KnownStatusUnsafe: apicontainerstatus.ContainerRunning,
+				ReadinessProbe:    tc.probe,
+			}
+			if tc.ready {
+				dep.RecordReadinessProbeResult(nil)
+			}
+			assert.Equal(t, tc.expectResolve, containerOrderingDependenciesIsResolved(target, dep, readyCondition, &cfg))
+		})
+	}
+}
+
+func TestValidDependenciesReadyConditionRequiresProbe(t *testing.T) {
+	dependencyName := "dependency"
+	newTask := func(probe *apicontainer.ReadinessProbe) *apitask.Task {
+		return &apitask.Task{
+			Containers: []*apicontainer.Container{
+				{
+					Name:                dependencyName,
+					DesiredStatusUnsafe: apicontainerstatus.ContainerRunning,
+					ReadinessProbe:      probe,
+				},
+				{
+					Name:                "target",
+					DesiredStatusUnsafe: apicontainerstatus.ContainerRunning,
+					DependsOnUnsafe: []apicontainer.DependsOn{
+						{ContainerName: dependencyName, Condition: readyCondition},
+					},
+				},
+			},
+		}
+	}
+	cfg := config.Config{}
+	assert.True(t, ValidDependencies(newTask(&apicontainer.ReadinessProbe{
+		HTTPGet: &apicontainer.HTTPGetAction{Port: 8080},
+	}), &cfg))
+	assert.False(t, ValidDependencies(newTask(nil), &cfg))
+}
+
 func TestContainerOrderingHealthyConditionIsResolvedWithDependentContainersPullUpfront(t *testing.T) {
 	testcases := []struct {
 		TargetDesired                 apicontainerstatus.ContainerStatus
@@ -1591,6 +1660,12 @@ func TestStartTimeoutForContainerOrdering(t *testing.T) {
 			DependencyCondition:    healthyCondition,
 			ExpectedTimedOut:       true,
 		},
+		{
+			DependencyStartedAt:    time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+			DependencyStartTimeout: 10,
+			DependencyCondition:    readyCondition,
+			ExpectedTimedOut:       true,
+		},
 		{
 			DependencyStartedAt:    time.Date(4000, 1, 1, 0, 0, 0, 0, time.UTC),
 			DependencyStartTimeout: 10,
@@ -0,0 +1,253 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package dependencygraph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+)
+
+const (
+	// ProblemKindCycle is a set of containers that (transitively) depend on each other
+	ProblemKindCycle = "Cycle"
+	// ProblemKindMissingContainer is a dependency on a container that is not part of the task
+	ProblemKindMissingContainer = "MissingContainer"
+	// ProblemKindUnsatisfiableCondition is a dependency condition that the dependency container can never meet
+	ProblemKindUnsatisfiableCondition = "UnsatisfiableCondition"
+	// ProblemKindEssentialCompletion is an essential container waiting for a non-essential container to
+	// exit with a COMPLETE or SUCCESS dependency. If the non-essential container keeps running, the
+	// essential container never starts
+	ProblemKindEssentialCompletion = "EssentialCompletion"
+	// ProblemKindUnresolvable is a set of containers whose dependencies cannot be resolved in any order
+	ProblemKindUnresolvable = "Unresolvable"
+)
+
+// DependencyProblem describes a single reason why the dependencies of a task cannot be resolved.
+type DependencyProblem struct {
+	Kind       string
+	Container  string
+	Dependency string
+	Condition  string
+	// Path is the list of containers forming a cycle, starting and ending with the same container
+	Path    []string
+	Message string
+}
+
+// DependencyValidationError is returned when the dependencies of a task cannot be resolved. It
+// lists every problem that was found so that all of them can be fixed at once.
+type DependencyValidationError struct {
+	Problems []DependencyProblem
+}
+
+func (e *DependencyValidationError) Error() string {
+	messages := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		messages[i] = problem.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// ValidateDependencies takes a task and verifies that it is possible to allow all containers
+// within it to reach the desired status by proceeding in some order. If that is not possible, a
+// DependencyValidationError describing the problems is returned. Otherwise, any problems found
+// in the dependsOn configuration are returned as warnings: the task can still be started, but
+// some of its containers may wait on a dependency that is never satisfied.
+func ValidateDependencies(task *apitask.Task, cfg *config.Config) ([]DependencyProblem, error) {
+	containers := make(map[string]*apicontainer.Container)
+	for _, container := range task.Containers {
+		containers[container.Name] = container
+	}
+
+	problems := findCycles(task.Containers, containers)
+	for _, container := range task.Containers {
+		problems = append(problems, findDependsOnProblems(container, containers)...)
+	}
+
+	unresolved := unresolvableContainers(task, cfg)
+	if len(unresolved) == 0 {
+		return problems, nil
+	}
+	explained := false
+	for _, problem := range problems {
+		// Waiting for a container to exit does not by itself prevent the dependencies from resolving
+		explained = explained || problem.Kind != ProblemKindEssentialCompletion
+	}
+	if !explained {
+		problems = append(problems, DependencyProblem{
+			Kind:    ProblemKindUnresolvable,
+			Message: fmt.Sprintf("dependencies of containers [%s] cannot be resolved", strings.Join(unresolved, ", ")),
+		})
+	}
+	return nil, &DependencyValidationError{Problems: problems}
+}
+
+// findDependsOnProblems returns the problems with the dependsOn configuration of a container
+// that can be detected without resolving the graph.
+func findDependsOnProblems(container *apicontainer.Container, containers map[string]*apicontainer.Container) []DependencyProblem {
+	var problems []DependencyProblem
+	for _, dependsOn := range container.GetDependsOn() {
+		problem := DependencyProblem{
+			Container:  container.Name,
+			Dependency: dependsOn.ContainerName,
+			Condition:  dependsOn.Condition,
+		}
+		dependency, ok := containers[dependsOn.ContainerName]
+		if !ok {
+			problem.Kind = ProblemKindMissingContainer
+			problem.Message = fmt.Sprintf("container %s depends on container %s which is not part of the task",
+				container.Name, dependsOn.ContainerName)
+			problems = append(problems, problem)
+			continue
+		}
+
+		switch dependsOn.Condition {
+		case createCondition, startCondition:
+		case successCondition, completeCondition:
+			if container.IsEssential() && !dependency.IsEssential() {
+				problem.Kind = ProblemKindEssentialCompletion
+				problem.Message = fmt.Sprintf("essential container %s depends on non-essential container %s with "+
+					"condition %s, and does not start until %s exits", container.Name, dependency.Name,
+					dependsOn.Condition, dependency.Name)
+			}
+		case healthyCondition:
+			if !dependency.HealthStatusShouldBeReported() {
+				problem.Kind = ProblemKindUnsatisfiableCondition
+				problem.Message = fmt.Sprintf("container %s depends on container %s with condition %s, "+
+					"but %s has no health check", container.Name, dependency.Name, dependsOn.Condition, dependency.Name)
+			}
+		case readyCondition:
+			if !dependency.HasReadinessProbe() {
+				problem.Kind = ProblemKindUnsatisfiableCondition
+				problem.Message = fmt.Sprintf("container %s depends on container %s with condition %s, "+
+					"but %s has no readiness probe", container.Name, dependency.Name, dependsOn.Condition, dependency.Name)
+			}
+		default:
+			problem.Kind = ProblemKindUnsatisfiableCondition
+			problem.Message = fmt.Sprintf("container %s depends on container %s with unsupported condition %s",
+				container.Name, dependency.Name, dependsOn.Condition)
+		}
+		if problem.Kind != "" {
+			problems = append(problems, problem)
+		}
+	}
+	return problems
+}
+
+// findCycles returns a problem for every distinct cycle formed by the dependsOn and steady state
+// dependencies of the containers.
+func findCycles(ordered []*apicontainer.Container, containers map[string]*apicontainer.Container) []DependencyProblem {
+	edges := make(map[string][]string)
+	for _, container := range ordered {
+		for _, dependsOn := range container.GetDependsOn() {
+			if _, ok := containers[dependsOn.ContainerName]; ok {
+				edges[container.Name] = append(edges[container.Name], dependsOn.ContainerName)
+			}
+		}
+		for _, name := range container.SteadyStateDependencies {
+			if _, ok := containers[name]; ok {
+				edges[container.Name] = append(edges[container.Name], name)
+			}
+		}
+	}
+
+	var problems []DependencyProblem
+	seen := make(map[string]bool)
+	visited := make(map[string]bool)
+	var stack []string
+	onStack := make(map[string]int)
+
+	var visit func(name string)
+	visit = func(name string) {
+		onStack[name] = len(stack)
+		stack = append(stack, name)
+		for _, next := range edges[name] {
+			if idx, ok := onStack[next]; ok {
+				path := append(append([]string{}, stack[idx:]...), next)
+				key := cycleKey(path)
+				if !seen[key] {
+					seen[key] = true
+					problems = append(problems, DependencyProblem{
+						Kind:       ProblemKindCycle,
+						Container:  name,
+						Dependency: next,
+						Path:       path,
+						Message:    "dependency cycle: " + strings.Join(path, " -> "),
+					})
+				}
+				continue
+			}
+			if !visited[next] {
+				visit(next)
+			}
+		}
+		stack = stack[:len(stack)-1]
+		delete(onStack, name)
+		visited[name] = true
+	}
+
+	for _, container := range ordered {
+		if !visited[container.Name] {
+			visit(container.Name)
+		}
+	}
+	return problems
+}
+
+// cycleKey returns a key identifying a cycle regardless of the container it starts from.
+func cycleKey(path []string) string {
+	cycle := path[:len(path)-1]
+	start := 0
+	for i, name := range cycle {
+		if name < cycle[start] {
+			start = i
+		}
+	}
+	rotated := append(append([]string{}, cycle[start:]...), cycle[:start]...)
+	return strings.Join(rotated, "\x00")
+}
+
+// unresolvableContainers returns the sorted names of the containers that cannot reach their
+// desired status in any order.
+func unresolvableContainers(task *apitask.Task, cfg *config.Config) []string {
+	unresolved := make([]*apicontainer.Container, len(task.Containers))
+	resolved := make([]*apicontainer.Container, 0, len(task.Containers))
+
+	copy(unresolved, task.Containers)
+
+OuterLoop:
+	for len(unresolved) > 0 {
+		for i, tryResolve := range unresolved {
+			if dependenciesCanBeResolved(tryResolve, resolved, cfg) {
+				resolved = append(resolved, tryResolve)
+				unresolved = append(unresolved[:i], unresolved[i+1:]...)
+				// Break out of the inner loop now that we modified the slice
+				// we're looping over
+				continue OuterLoop
+			}
+		}
+		break
+	}
+
+	names := make([]string, len(unresolved))
+	for i, container := range unresolved {
+		names[i] = container.Name
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,216 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package dependencygraph
+
+import (
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validationContainer(name string, dependsOn ...apicontainer.DependsOn) *apicontainer.Container {
+	return steadyStateContainer(name, dependsOn, apicontainerstatus.ContainerRunning, apicontainerstatus.ContainerRunning)
+}
+
+func TestValidateDependenciesNoProblems(t *testing.T) {
+	task := &apitask.Task{
+		Containers: []*apicontainer.Container{
+			validationContainer("app", apicontainer.DependsOn{ContainerName: "db", Condition: startCondition}),
+			validationContainer("db"),
+		},
+	}
+	warnings, err := ValidateDependencies(task, &config.Config{})
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+}
+
+func TestValidateDependenciesProblems(t *testing.T) {
+	unhealthy := validationContainer("proxy")
+
+	testCases := []struct {
+		name             string
+		containers       []*apicontainer.Container
+		expectedProblems []DependencyProblem
+	}{
+		{
+			name: "cycle",
+			containers: []*apicontainer.Container{
+				validationContainer("a", apicontainer.DependsOn{ContainerName: "b", Condition: createCondition}),
+				validationContainer("b", apicontainer.DependsOn{ContainerName: "c", Condition: createCondition}),
+				validationContainer("c", apicontainer.DependsOn{ContainerName: "a", Condition: createCondition}),
+			},
+			expectedProblems: []DependencyProblem{{
+				Kind:       ProblemKindCycle,
+				Container:  "c",
+				Dependency: "a",
+				Path:       []string{"a", "b", "c", "a"},
+				Message:    "dependency cycle: a -> b -> c -> a",
+			}},
+		},
+		{
+			name: "self dependency",
+			containers: []*apicontainer.Container{
+				validationContainer("a", apicontainer.DependsOn{ContainerName: "a", Condition: startCondition}),
+			},
+			expectedProblems: []DependencyProblem{{
+				Kind:       ProblemKindCycle,
+				Container:  "a",
+				Dependency: "a",
+				Path:       []string{"a", "a"},
+				Message:    "dependency cycle: a -> a",
+			}},
+		},
+		{
+			name: "missing container",
+			containers: []*apicontainer.Container{
+				validationContainer("app", apicontainer.DependsOn{ContainerName: "db", Condition: startCondition}),
+			},
+			expectedProblems: []DependencyProblem{{
+				Kind:       ProblemKindMissingContainer,
+				Container:  "app",
+				Dependency: "db",
+				Condition:  startCondition,
+				Message:    "container app depends on container db which is not part of the task",
+			}},
+		},
+		{
+			name: "healthy without health check",
+			containers: []*apicontainer.Container{
+				validationContainer("app", apicontainer.DependsOn{ContainerName: "proxy", Condition: healthyCondition}),
+				unhealthy,
+			},
+			expectedProblems: []DependencyProblem{{
+				Kind:       ProblemKindUnsatisfiableCondition,
+				Container:  "app",
+				Dependency: "proxy",
+				Condition:  healthyCondition,
+				Message:    "container app depends on container proxy with condition HEALTHY, but proxy has no health check",
+			}},
+		},
+		{
+			name: "unsupported condition",
+			containers: []*apicontainer.Container{
+				validationContainer("app", apicontainer.DependsOn{ContainerName: "db", Condition: "STARTED"}),
+				validationContainer("db"),
+			},
+			expectedProblems: []DependencyProblem{{
+				Kind:       ProblemKindUnsatisfiableCondition,
+				Container:  "app",
+				Dependency: "db",
+				Condition:  "STARTED",
+				Message:    "container app depends on container db with unsupported condition STARTED",
+			}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			task := &apitask.Task{Containers: tc.containers}
+			warnings, err := ValidateDependencies(task, &config.Config{})
+			require.Error(t, err)
+			assert.Empty(t, warnings)
+			validationErr, ok := err.(*DependencyValidationError)
+			require.True(t, ok)
+			assert.Equal(t, tc.expectedProblems, validationErr.Problems)
+			assert.False(t, ValidDependencies(task, &config.Config{}))
+		})
+	}
+}
+
+func TestValidateDependenciesUnresolvable(t *testing.T) {
+	// 'app' wants to reach RUNNING, but 'db' is only ever going to be created
+	db := createdContainer("db", nil, apicontainerstatus.ContainerRunning)
+	task := &apitask.Task{
+		Containers: []*apicontainer.Container{
+			validationContainer("app", apicontainer.DependsOn{ContainerName: "db", Condition: startCondition}),
+			db,
+		},
+	}
+	_, err := ValidateDependencies(task, &config.Config{})
+	require.Error(t, err)
+	assert.Equal(t, "dependencies of containers [app] cannot be resolved", err.Error())
+}
+
+func TestValidateDependenciesWarnings(t *testing.T) {
+	essentialApp := func(dependsOn ...apicontainer.DependsOn) *apicontainer.Container {
+		app := validationContainer("app", dependsOn...)
+		app.Essential = true
+		return app
+	}
+	essentialInit := validationContainer("init")
+	essentialInit.Essential = true
+
+	testCases := []struct {
+		name             string
+		containers       []*apicontainer.Container
+		expectedWarnings []DependencyProblem
+	}{
+		{
+			name: "essential container waits for non-essential container to complete",
+			containers: []*apicontainer.Container{
+				essentialApp(apicontainer.DependsOn{ContainerName: "init", Condition: completeCondition}),
+				validationContainer("init"),
+			},
+			expectedWarnings: []DependencyProblem{{
+				Kind:       ProblemKindEssentialCompletion,
+				Container:  "app",
+				Dependency: "init",
+				Condition:  completeCondition,
+				Message: "essential container app depends on non-essential container init with condition COMPLETE, " +
+					"and does not start until init exits",
+			}},
+		},
+		{
+			name: "non-essential container waits for non-essential container to succeed",
+			containers: []*apicontainer.Container{
+				validationContainer("app", apicontainer.DependsOn{ContainerName: "init", Condition: successCondition}),
+				validationContainer("init"),
+			},
+		},
+		{
+			name: "essential container waits for essential container to complete",
+			containers: []*apicontainer.Container{
+				essentialApp(apicontainer.DependsOn{ContainerName: "init", Condition: completeCondition}),
+				essentialInit,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			task := &apitask.Task{Containers: tc.containers}
+			warnings, err := ValidateDependencies(task, &config.Config{})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedWarnings, warnings)
+			assert.True(t, ValidDependencies(task, &config.Config{}))
+		})
+	}
+}
+
+func TestDependencyValidationErrorJoinsProblems(t *testing.T) {
+	err := &DependencyValidationError{Problems: []DependencyProblem{
+		{Message: "first"},
+		{Message: "second"},
+	}}
+	assert.Equal(t, "first; second", err.Error())
+}
@@ -17,6 +17,7 @@ package engine
 import (
 	"context"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -40,18 +41,23 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
 	"github.com/aws/amazon-ecs-agent/agent/engine/execcmd"
 	"github.com/aws/amazon-ecs-agent/agent/engine/serviceconnect"
+	"github.com/aws/amazon-ecs-agent/agent/logshipper"
 	"github.com/aws/amazon-ecs-agent/agent/statechange"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/credentialspec"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource/ephemeralvolume"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/firelens"
 	"github.com/aws/amazon-ecs-agent/agent/utils"
 	referenceutil "github.com/aws/amazon-ecs-agent/agent/utils/reference"
+	"github.com/aws/amazon-ecs-agent/agent/volumeexport"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/api/appnet"
 	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
 	apierrors "github.com/aws/amazon-ecs-agent/ecs-agent/api/errors"
 	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/ec2"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/eventstream"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/ipcompatibility"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/retry"
@@ -144,6 +150,15 @@ var (
 		"us-isof-east-1":  true,
 		"us-isob-west-1":  true,
 	}
+
+	// ErrNoFirelensContainer is returned when the firelens config of a task without a firelens container is reloaded.
+	ErrNoFirelensContainer = errors.New("task has no firelens container")
+	// ErrFirelensHotReloadDisabled is returned when the firelens config of a task is reloaded without the hot
+	// reload option of its firelens container.
+	ErrFirelensHotReloadDisabled = errors.New("hot reload of the firelens config is not enabled for the task")
+	// ErrFirelensContainerNotRunning is returned when the firelens config of a task is reloaded while its
+	// firelens container is not running.
+	ErrFirelensContainerNotRunning = errors.New("firelens container of the task is not running")
 )
 
 // DockerTaskEngine is a state machine for managing a task and its containers
@@ -233,6 +248,16 @@ type DockerTaskEngine struct {
 	stopContainerBackoffMin   time.Duration
 	stopContainerBackoffMax   time.Duration
 	namespaceHelper           ecscni.NamespaceHelper
+	// volumeExporter archives the task volumes that are to be exported before the task is cleaned up
+	volumeExporter *volumeexport.Exporter
+
+	// availabilityZone and ec2MetadataClient provide the instance metadata that the values of
+	// templated environment files can reference. hostIPAddress caches the private IP address of
+	// the host once it has been retrieved.
+	availabilityZone     string
+	ec2MetadataClient    ec2.EC2MetadataClient
+	hostIPAddress        string
+	instanceMetadataLock sync.Mutex
 }
 
 // NewDockerTaskEngine returns a created, but uninitialized, DockerTaskEngine.
@@ -282,6 +307,7 @@ func NewDockerTaskEngine(cfg *config.Config,
 		stopContainerBackoffMax:           defaultStopContainerBackoffMax,
 		namespaceHelper:                   ecscni.NewNamespaceHelper(client),
 		daemonTasks:                       make(map[string]*apitask.Task),
+		volumeExporter:                    volumeexport.NewExporter(cfg, client, credentialsManager),
 	}
 
 	dockerTaskEngine.initializeContainerStatusToTransitionFunction()
@@ -598,6 +624,43 @@ func (engine *DockerTaskEngine) SetDataClient(client data.Client) {
 	engine.dataClient = client
 }
 
+// SetInstanceMetadata sets the availability zone of the container instance and the client that
+// the private IP address of the host is retrieved with, for templated environment files.
+func (engine *DockerTaskEngine) SetInstanceMetadata(availabilityZone string, ec2MetadataClient ec2.EC2MetadataClient) {
+	engine.instanceMetadataLock.Lock()
+	defer engine.instanceMetadataLock.Unlock()
+
+	engine.availabilityZone = availabilityZone
+	engine.ec2MetadataClient = ec2MetadataClient
+}
+
+// instanceMetadata returns the availability zone of the container instance and the private IP
+// address of the host. The IP address is retrieved from the instance metadata service the first
+// time it is needed, and is empty if it cannot be retrieved. The lock is not held while the instance
+// metadata service is queried, so that a slow query does not block the other tasks.
+func (engine *DockerTaskEngine) instanceMetadata() (string, string) {
+	engine.instanceMetadataLock.Lock()
+	availabilityZone, hostIPAddress, ec2MetadataClient := engine.availabilityZone, engine.hostIPAddress,
+		engine.ec2MetadataClient
+	engine.instanceMetadataLock.Unlock()
+	if hostIPAddress != "" || ec2MetadataClient == nil {
+		return availabilityZone, hostIPAddress
+	}
+
+	hostIPAddress, err := ec2MetadataClient.PrivateIPv4Address()
+	if err != nil {
+		logger.Warn("Unable to retrieve the private IP address of the host", logger.Fields{
+			field.Error: err,
+		})
+		return availabilityZone, ""
+	}
+
+	engine.instanceMetadataLock.Lock()
+	defer engine.instanceMetadataLock.Unlock()
+	engine.hostIPAddress = hostIPAddress
+	return availabilityZone, hostIPAddress
+}
+
 func (engine *DockerTaskEngine) Context() context.Context {
 	return engine.ctx
 }
@@ -672,6 +735,8 @@ func (engine *DockerTaskEngine) synchronizeState() {
 	}
 
 	tasks := engine.state.AllTasks()
+	engine.configureHostPortAllocation(tasks)
+	engine.configureEphemeralVolumeProjectIDs()
 	// For normal task progress, overseeTask 'consume's resources through waitForHostResources in host_resource_manager before progressing
 	// For agent restarts (state restore), we pre-consume resources for tasks that had progressed beyond waitForHostResources stage -
 	// so these tasks do not wait during 'waitForHostResources' call again - do not go through queuing again
@@ -691,6 +756,39 @@ func (engine *DockerTaskEngine) synchronizeState() {
 	}
 }
 
+// configureHostPortAllocation sets up the allocation of dynamic host ports with the recent allocations
+// of the previous agent, releasing the ones of the tasks that are not known anymore.
+func (engine *DockerTaskEngine) configureHostPortAllocation(tasks []*apitask.Task) {
+	taskARNs := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		taskARNs = append(taskARNs, task.Arn)
+	}
+	var store utils.HostPortAllocationStore
+	if engine.dataClient != nil {
+		store = &hostPortAllocationStore{dataClient: engine.dataClient}
+	}
+	err := utils.ConfigureHostPortAllocation(engine.cfg.HostPortAllocationStrategy, engine.cfg.HostPortReuseCooldown,
+		store, taskARNs)
+	if err != nil {
+		logger.Warn("Unable to load the recent host port allocations", logger.Fields{
+			field.Error: err,
+		})
+	}
+}
+
+// configureEphemeralVolumeProjectIDs loads the XFS project IDs of the ephemeral volumes of the previous
+// agent, so that they are not allocated again while their volume exists.
+func (engine *DockerTaskEngine) configureEphemeralVolumeProjectIDs() {
+	if engine.dataClient == nil {
+		return
+	}
+	if err := ephemeralvolume.ConfigureProjectIDStore(engine.dataClient); err != nil {
+		logger.Warn("Unable to load the XFS project ids of the ephemeral volumes", logger.Fields{
+			field.Error: err,
+		})
+	}
+}
+
 // filterTasksToStartUnsafe filters only the tasks that need to be started after
 // the agent has been restarted. It also synchronizes states of all of the containers
 // in tasks that need to be started.
@@ -717,7 +815,8 @@ func (engine *DockerTaskEngine) filterTasksToStartUnsafe(tasks []*apitask.Task)
 
 // updateContainerMetadata sets the container metadata from the docker inspect,
 // and update port mappings for bridge mode containers with service connect enabled
-func updateContainerMetadata(metadata *dockerapi.DockerContainerMetadata, container *apicontainer.Container, task *apitask.Task) {
+func updateContainerMetadata(metadata *dockerapi.DockerContainerMetadata, container *apicontainer.Container,
+	task *apitask.Task, instanceIPCompatibility ipcompatibility.IPCompatibility) {
 	container.SetCreatedAt(metadata.CreatedAt)
 	container.SetStartedAt(metadata.StartedAt)
 	container.SetFinishedAt(metadata.FinishedAt)
@@ -743,7 +842,7 @@ func updateContainerMetadata(metadata *dockerapi.DockerContainerMetadata, contai
 
 	// Set port mappings
 	if len(metadata.PortBindings) != 0 && len(container.GetKnownPortBindings()) == 0 {
-		container.SetKnownPortBindings(metadata.PortBindings)
+		container.SetKnownPortBindings(reachablePortBindings(metadata.PortBindings, instanceIPCompatibility))
 	}
 
 	// update port mappings for service connect bridge mode.
@@ -791,7 +890,7 @@ func (engine *DockerTaskEngine) synchronizeContainerStatus(container *apicontain
 		} else {
 			// update the container metadata in case the container was created during agent restart
 			metadata := dockerapi.MetadataFromContainer(describedContainer)
-			updateContainerMetadata(&metadata, container.Container, task)
+			updateContainerMetadata(&metadata, container.Container, task, engine.cfg.InstanceIPCompatibility)
 			container.DockerID = describedContainer.ID
 
 			container.Container.SetKnownStatus(dockerapi.DockerStateToState(describedContainer.State))
@@ -834,12 +933,12 @@ func (engine *DockerTaskEngine) synchronizeContainerStatus(container *apicontain
 			}
 		} else {
 			// If this is a container state error
-			updateContainerMetadata(&metadata, container.Container, task)
+			updateContainerMetadata(&metadata, container.Container, task, engine.cfg.InstanceIPCompatibility)
 			container.Container.ApplyingError = apierrors.NewNamedError(metadata.Error)
 		}
 	} else {
 		// update the container metadata in case the container status/metadata changed during agent restart
-		updateContainerMetadata(&metadata, container.Container, task)
+		updateContainerMetadata(&metadata, container.Container, task, engine.cfg.InstanceIPCompatibility)
 		err := engine.imageManager.RecordContainerReference(container.Container)
 		if err != nil {
 			logger.Warn("Unable to add container reference to image state", logger.Fields{
@@ -885,6 +984,86 @@ func (engine *DockerTaskEngine) checkTaskState(task *apitask.Task) {
 	}
 }
 
+// scheduleUnhealthyRestart arranges for the container to be checked against its restart policy once
+// it has been UNHEALTHY for the policy's unhealthy restart period. Docker only emits health events when
+// the health status changes, so the check is also repeated whenever the task is checked at steady state.
+func (engine *DockerTaskEngine) scheduleUnhealthyRestart(task *apitask.Task, container *apicontainer.Container) {
+	if !container.RestartPolicyEnabled() || !container.RestartPolicy.UnhealthyRestartEnabled() {
+		return
+	}
+	health := container.GetHealthStatus()
+	if health.Status != apicontainerstatus.ContainerUnhealthy {
+		return
+	}
+	period := time.Duration(container.RestartPolicy.UnhealthyRestartPeriod) * time.Second
+	delay := period - time.Since(aws.ToTime(health.Since))
+	engine.time().AfterFunc(delay, func() {
+		engine.restartUnhealthyContainers(task)
+	})
+}
+
+// restartUnhealthyContainers stops every running container of the task that has been UNHEALTHY for
+// longer than its restart policy allows. The resulting container stopped event is then handled by the
+// task manager, which restarts the container regardless of its exit code. A container is only
+// stopped once per restart, however many of the scheduled and steady state checks see it unhealthy.
+func (engine *DockerTaskEngine) restartUnhealthyContainers(task *apitask.Task) {
+	if engine.ctx.Err() != nil {
+		return
+	}
+	for _, container := range task.Containers {
+		if !container.RestartPolicyEnabled() || container.RestartTracker == nil ||
+			!container.RestartPolicy.UnhealthyRestartEnabled() ||
+			container.GetKnownStatus() != apicontainerstatus.ContainerRunning {
+			continue
+		}
+		health := container.GetHealthStatus()
+		shouldRestart, reason := container.RestartTracker.ShouldRestartUnhealthy(health.Status,
+			aws.ToTime(health.Since), container.GetStartedAt(), container.GetDesiredStatus())
+		if !shouldRestart {
+			logger.Debug("Not restarting unhealthy container", logger.Fields{
+				field.TaskID:    task.GetID(),
+				field.Container: container.Name,
+				"reason":        reason,
+			})
+			continue
+		}
+		dockerID, err := engine.getDockerID(task, container)
+		if err != nil {
+			logger.Warn("Unable to restart unhealthy container", logger.Fields{
+				field.TaskID:    task.GetID(),
+				field.Container: container.Name,
+				field.Error:     err,
+			})
+			continue
+		}
+
+		if !container.RestartTracker.RecordUnhealthyStop() {
+			// The container is already being stopped by another check
+			continue
+		}
+		logger.Info("Stopping unhealthy container to restart it", logger.Fields{
+			field.TaskID:     task.GetID(),
+			field.Container:  container.Name,
+			field.RuntimeID:  dockerID,
+			"unhealthySince": aws.ToTime(health.Since).UTC().Format(time.RFC3339),
+		})
+		engine.runPreStopHook(task, container, dockerID)
+		apiTimeoutStopContainer := container.GetStopTimeout()
+		if apiTimeoutStopContainer <= 0 {
+			apiTimeoutStopContainer = engine.cfg.DockerStopTimeout
+		}
+		if md := engine.stopDockerContainer(dockerID, container.Name, apiTimeoutStopContainer); md.Error != nil {
+			logger.Error("Unable to stop unhealthy container", logger.Fields{
+				field.TaskID:    task.GetID(),
+				field.Container: container.Name,
+				field.RuntimeID: dockerID,
+				field.Error:     md.Error,
+			})
+			container.RestartTracker.ClearPendingRestart()
+		}
+	}
+}
+
 // sweepTask deletes all the containers associated with a task
 func (engine *DockerTaskEngine) sweepTask(task *apitask.Task) {
 	for _, cont := range task.Containers {
@@ -1137,6 +1316,7 @@ func (engine *DockerTaskEngine) handleDockerEvent(event dockerapi.DockerContaine
 				"output":        event.DockerContainerMetadata.Health.Output,
 			})
 			cont.Container.SetHealthStatus(event.DockerContainerMetadata.Health)
+			engine.scheduleUnhealthyRestart(task, cont.Container)
 		}
 		return
 	}
@@ -1224,15 +1404,24 @@ func (engine *DockerTaskEngine) UpsertTask(task *apitask.Task) {
 		engine.updateTaskENIDependencies(task)
 
 		engine.state.AddTask(task)
-		if dependencygraph.ValidDependencies(task, engine.cfg) {
+		warnings, validationErr := dependencygraph.ValidateDependencies(task, engine.cfg)
+		for _, warning := range warnings {
+			logger.Warn("Task has a container dependency that may never be satisfied", logger.Fields{
+				field.TaskID:  task.GetID(),
+				"problemKind": warning.Kind,
+				"problem":     warning.Message,
+			})
+		}
+		if validationErr == nil {
 			engine.startTask(task)
 		} else {
-			logger.Error("Task has circular dependencies; unable to start", logger.Fields{
+			logger.Error("Task has unresolvable dependencies; unable to start", logger.Fields{
 				field.TaskID: task.GetID(),
+				field.Error:  validationErr,
 			})
 			task.SetKnownStatus(apitaskstatus.TaskStopped)
 			task.SetDesiredStatus(apitaskstatus.TaskStopped)
-			err := TaskDependencyError{task.Arn}
+			err := TaskDependencyError{taskArn: task.Arn, cause: validationErr}
 			engine.EmitTaskEvent(task, err.Error())
 		}
 		return
@@ -1240,6 +1429,42 @@ func (engine *DockerTaskEngine) UpsertTask(task *apitask.Task) {
 	engine.updateTaskDesiredStatusUnsafe(existingTask, task.GetDesiredStatus())
 }
 
+// ReloadFirelensConfig regenerates the firelens config of a running task with the given log options of its
+// containers, and signals the firelens container of the task to reload it. The log options of a task don't change
+// when it's upserted again, so the config is only reloaded when this is called.
+func (engine *DockerTaskEngine) ReloadFirelensConfig(taskARN string,
+	containerToLogOptions map[string]map[string]string) error {
+	task, ok := engine.state.TaskByArn(taskARN)
+	if !ok {
+		return errors.Errorf("task %s is not managed by the task engine", taskARN)
+	}
+	resource, ok := task.GetFirelensResource()
+	if !ok {
+		return errors.Wrapf(ErrNoFirelensContainer, "task %s", taskARN)
+	}
+	if !resource.HotReloadEnabled() {
+		return errors.Wrapf(ErrFirelensHotReloadDisabled, "task %s", taskARN)
+	}
+	firelensContainer := task.GetFirelensContainer()
+	if firelensContainer == nil || firelensContainer.GetKnownStatus() != apicontainerstatus.ContainerRunning {
+		return errors.Wrapf(ErrFirelensContainerNotRunning, "task %s", taskARN)
+	}
+
+	if err := resource.ReloadConfig(containerToLogOptions); err != nil {
+		return err
+	}
+	if err := engine.client.SignalContainer(engine.ctx, firelensContainer.GetRuntimeID(), resource.ReloadSignal(),
+		dockerclient.SignalContainerTimeout); err != nil {
+		return errors.Wrapf(err, "unable to signal firelens container %s to reload its config",
+			firelensContainer.Name)
+	}
+	logger.Info("Reloaded firelens config", logger.Fields{
+		field.TaskID:        task.GetID(),
+		field.ContainerName: firelensContainer.Name,
+	})
+	return nil
+}
+
 // ListTasks returns the tasks currently managed by the DockerTaskEngine
 func (engine *DockerTaskEngine) ListTasks() ([]*apitask.Task, error) {
 	return engine.state.AllTasks(), nil
@@ -1994,6 +2219,41 @@ func (engine *DockerTaskEngine) createContainer(task *apitask.Task, container *a
 		}
 	}
 
+	// If the container is using the log driver type "awslogshipper", its logs are shipped by the agent. The container
+	// is created with the json-file log driver instead, which the agent reads the logs from. This is done once the
+	// secret log options are populated, so that they are validated and are not passed on to the json-file log driver.
+	if hostConfig.LogConfig.Type == logshipper.LogDriverName {
+		if !engine.cfg.LogShippingEnabled.Enabled() {
+			return dockerapi.DockerContainerMetadata{
+				Error: dockerapi.CannotCreateContainerError{FromError: errors.New(
+					"failed to create container - container uses awslogshipper log driver but log shipping " +
+						"is not enabled on the container instance")},
+			}
+		}
+		logConfig, err := logshipper.JSONFileLogConfig(hostConfig.LogConfig)
+		if err != nil {
+			logger.Error("Invalid log shipping config", logger.Fields{
+				field.TaskID:    task.GetID(),
+				field.Container: container.Name,
+				field.Error:     err,
+			})
+			return dockerapi.DockerContainerMetadata{
+				Error: dockerapi.CannotCreateContainerError{FromError: errors.Wrapf(err,
+					"failed to create container - container uses awslogshipper log driver with an invalid config")},
+			}
+		}
+		hostConfig.LogConfig = logConfig
+	}
+
+	// Write the secrets mounted as files and bind mount them read-only into the container
+	if container.HasSecret(apitask.IsSecretMountedAsFile) {
+		binds, err := task.PopulateSecretFiles(container)
+		if err != nil {
+			return dockerapi.DockerContainerMetadata{Error: apierrors.NamedError(err)}
+		}
+		hostConfig.Binds = append(hostConfig.Binds, binds...)
+	}
+
 	// Populate credentialspec resource
 	if container.RequiresAnyCredentialSpec() {
 		logger.Debug("Obtained container with credentialspec resource requirement for task", logger.Fields{
@@ -2025,7 +2285,13 @@ func (engine *DockerTaskEngine) createContainer(task *apitask.Task, container *a
 	}
 
 	if container.ShouldCreateWithEnvFiles() {
-		err := task.MergeEnvVarsFromEnvfiles(container)
+		var templateVariables map[string]string
+		if container.HasTemplatedEnvfiles() {
+			availabilityZone, hostIPAddress := engine.instanceMetadata()
+			templateVariables = task.EnvfileTemplateVariables(container, hostConfig, engine.cfg.Cluster,
+				availabilityZone, hostIPAddress)
+		}
+		err := task.MergeEnvVarsFromEnvfiles(container, templateVariables)
 		if err != nil {
 			logger.Error("Error populating environment variables from specified files into container", logger.Fields{
 				field.TaskID:    task.GetID(),
@@ -2716,6 +2982,8 @@ func (engine *DockerTaskEngine) stopContainer(task *apitask.Task, container *api
 		}
 	}
 
+	engine.runPreStopHook(task, container, dockerID)
+
 	// Cleanup the pause container network namespace before stop the container
 	if container.Type == apicontainer.ContainerCNIPause {
 		if task.IsNetworkModeAWSVPC() || (task.IsNetworkModeBridge() && task.IsServiceConnectEnabled()) {
@@ -2903,17 +3171,40 @@ func (engine *DockerTaskEngine) updateMetadataFile(task *apitask.Task, cont *api
 	}
 }
 
+// reachablePortBindings drops the port bindings on IPv4 addresses on IPv6-only instances, where they
+// cannot be reached, so that only the IPv6 host ports of the container are reported.
+func reachablePortBindings(bindings []apicontainer.PortBinding,
+	instanceIPCompatibility ipcompatibility.IPCompatibility) []apicontainer.PortBinding {
+	if !instanceIPCompatibility.IsIPv6Only() {
+		return bindings
+	}
+	var reachable []apicontainer.PortBinding
+	for _, binding := range bindings {
+		if ip := net.ParseIP(binding.BindIP); ip != nil && ip.To4() != nil {
+			continue
+		}
+		reachable = append(reachable, binding)
+	}
+	return reachable
+}
+
+// getContainerHostIP returns the address of a bridge mode container that can be reached from the host.
+// The IPv4 address of the container is preferred, and its global IPv6 address is used for containers
+// on IPv6-only networks.
 func getContainerHostIP(networkSettings *types.NetworkSettings) (string, bool) {
 	if networkSettings == nil {
 		return "", false
-	} else if networkSettings.IPAddress != "" {
+	}
+	bridgeNetwork := networkSettings.Networks[apitask.BridgeNetworkMode]
+	switch {
+	case networkSettings.IPAddress != "":
 		return networkSettings.IPAddress, true
-	} else if len(networkSettings.Networks) > 0 {
-		for mode, network := range networkSettings.Networks {
-			if mode == apitask.BridgeNetworkMode && network.IPAddress != "" {
-				return network.IPAddress, true
-			}
-		}
+	case bridgeNetwork != nil && bridgeNetwork.IPAddress != "":
+		return bridgeNetwork.IPAddress, true
+	case networkSettings.GlobalIPv6Address != "":
+		return networkSettings.GlobalIPv6Address, true
+	case bridgeNetwork != nil && bridgeNetwork.GlobalIPv6Address != "":
+		return bridgeNetwork.GlobalIPv6Address, true
 	}
 	return "", false
 }
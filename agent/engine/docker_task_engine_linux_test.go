@@ -1624,3 +1624,102 @@ func TestCredentialSpecResourceTaskFile(t *testing.T) {
 	ret := taskEngine.(*DockerTaskEngine).createContainer(testTask, testTask.Containers[0])
 	assert.Nil(t, ret.Error)
 }
+
+func getTestTaskWithHotReloadFirelens(t *testing.T, containerToLogOptions map[string]map[string]string) *apitask.Task {
+	firelensContainer := &apicontainer.Container{
+		Name: "firelens",
+		FirelensConfig: &apicontainer.FirelensConfig{
+			Type:    firelens.FirelensConfigTypeFluentbit,
+			Options: map[string]string{"enable-hot-reload": "true"},
+		},
+		KnownStatusUnsafe: apicontainerstatus.ContainerRunning,
+	}
+	firelensContainer.SetRuntimeID("firelens-runtime-id")
+	task := &apitask.Task{
+		Arn:                testTaskARN,
+		Containers:         []*apicontainer.Container{firelensContainer},
+		ResourcesMapUnsafe: make(map[string][]taskresource.TaskResource),
+	}
+
+	firelensResource, err := firelens.NewFirelensResource("cluster", testTaskARN, "family:1", "", t.TempDir(),
+		firelens.FirelensConfigTypeFluentbit, "us-west-2", apitask.BridgeNetworkMode, "",
+		firelensContainer.FirelensConfig.Options, containerToLogOptions, nil, "", 0,
+		ipcompatibility.NewIPv4OnlyCompatibility())
+	require.NoError(t, err)
+	task.AddResource(firelens.ResourceName, firelensResource)
+	return task
+}
+
+func TestReloadFirelensConfig(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	ctrl, client, _, taskEngine, _, _, _, _ := mocks(t, ctx, &defaultConfig)
+	defer ctrl.Finish()
+
+	task := getTestTaskWithHotReloadFirelens(t, map[string]map[string]string{
+		"app": {"Name": "cloudwatch", "region": "us-west-2"},
+	})
+	firelensResource, ok := task.GetFirelensResource()
+	require.True(t, ok)
+	require.NoError(t, firelensResource.Create())
+	taskEngine.(*DockerTaskEngine).state.AddTask(task)
+
+	newLogOptions := map[string]map[string]string{
+		"app": {"Name": "cloudwatch", "region": "us-east-1"},
+	}
+	client.EXPECT().SignalContainer(gomock.Any(), "firelens-runtime-id", "SIGHUP",
+		dockerclient.SignalContainerTimeout).Return(nil)
+
+	require.NoError(t, taskEngine.ReloadFirelensConfig(testTaskARN, newLogOptions))
+	assert.Equal(t, newLogOptions, firelensResource.GetContainerToLogOptions())
+}
+
+func TestReloadFirelensConfigErrors(t *testing.T) {
+	testCases := []struct {
+		name          string
+		setup         func(task *apitask.Task)
+		expectedError error
+	}{
+		{
+			name: "firelens container not running",
+			setup: func(task *apitask.Task) {
+				task.Containers[0].SetKnownStatus(apicontainerstatus.ContainerCreated)
+			},
+			expectedError: ErrFirelensContainerNotRunning,
+		},
+		{
+			name: "hot reload not enabled",
+			setup: func(task *apitask.Task) {
+				res, err := firelens.NewFirelensResource("cluster", testTaskARN, "family:1", "", t.TempDir(),
+					firelens.FirelensConfigTypeFluentbit, "us-west-2", apitask.BridgeNetworkMode, "", nil, nil, nil,
+					"", 0, ipcompatibility.NewIPv4OnlyCompatibility())
+				require.NoError(t, err)
+				task.ResourcesMapUnsafe[firelens.ResourceName] = []taskresource.TaskResource{res}
+			},
+			expectedError: ErrFirelensHotReloadDisabled,
+		},
+		{
+			name: "no firelens resource",
+			setup: func(task *apitask.Task) {
+				delete(task.ResourcesMapUnsafe, firelens.ResourceName)
+			},
+			expectedError: ErrNoFirelensContainer,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.TODO())
+			defer cancel()
+			ctrl, _, _, taskEngine, _, _, _, _ := mocks(t, ctx, &defaultConfig)
+			defer ctrl.Finish()
+
+			task := getTestTaskWithHotReloadFirelens(t, nil)
+			tc.setup(task)
+			taskEngine.(*DockerTaskEngine).state.AddTask(task)
+
+			err := taskEngine.ReloadFirelensConfig(testTaskARN, map[string]map[string]string{})
+			assert.ErrorIs(t, err, tc.expectedError)
+		})
+	}
+}
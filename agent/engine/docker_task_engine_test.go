@@ -58,11 +58,13 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/ssmsecret"
 	taskresourcevolume "github.com/aws/amazon-ecs-agent/agent/taskresource/volume"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/api/attachment"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/api/container/restart"
 	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
 	apierrors "github.com/aws/amazon-ecs-agent/ecs-agent/api/errors"
 	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
 	mock_credentials "github.com/aws/amazon-ecs-agent/ecs-agent/credentials/mocks"
+	mock_ec2 "github.com/aws/amazon-ecs-agent/ecs-agent/ec2/mocks"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/eventstream"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/ipcompatibility"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/appmesh"
@@ -1373,6 +1375,8 @@ func TestTaskWithCircularDependency(t *testing.T) {
 	go taskEngine.AddTask(task)
 	event := <-events
 	assert.Equal(t, event.(api.TaskStateChange).Status, apitaskstatus.TaskStopped, "Expected task to move to stopped directly")
+	assert.Equal(t, "Task dependencies cannot be resolved: dependency cycle: web -> web-db -> web",
+		event.(api.TaskStateChange).Reason)
 	_, ok := taskEngine.(*DockerTaskEngine).state.TaskByArn(task.Arn)
 	assert.True(t, ok, "Task state should be added to the agent state")
 
@@ -2332,6 +2336,84 @@ func TestHandleDockerHealthEvent(t *testing.T) {
 	assert.Equal(t, testContainer.Health.Status, apicontainerstatus.ContainerHealthy)
 }
 
+// TestRestartUnhealthyContainers tests that only containers which have been unhealthy for longer
+// than their restart policy's unhealthy restart period are stopped to be restarted
+func TestRestartUnhealthyContainers(t *testing.T) {
+	testCases := []struct {
+		name           string
+		healthStatus   apicontainerstatus.ContainerHealthStatus
+		unhealthySince time.Time
+		expectStop     bool
+	}{
+		{
+			name:           "unhealthy for longer than the period",
+			healthStatus:   apicontainerstatus.ContainerUnhealthy,
+			unhealthySince: time.Now().Add(-time.Minute),
+			expectStop:     true,
+		},
+		{
+			name:           "unhealthy for less than the period",
+			healthStatus:   apicontainerstatus.ContainerUnhealthy,
+			unhealthySince: time.Now(),
+			expectStop:     false,
+		},
+		{
+			name:           "healthy",
+			healthStatus:   apicontainerstatus.ContainerHealthy,
+			unhealthySince: time.Now().Add(-time.Minute),
+			expectStop:     false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.TODO())
+			defer cancel()
+			ctrl, client, _, taskEngine, _, _, _, _ := mocks(t, ctx, &defaultConfig)
+			defer ctrl.Finish()
+
+			state := taskEngine.(*DockerTaskEngine).State()
+			testTask := testdata.LoadTask("sleep5")
+			testContainer := testTask.Containers[0]
+			testContainer.HealthCheckType = apicontainer.DockerHealthCheckType
+			testContainer.RestartPolicy = &restart.RestartPolicy{
+				Enabled:                true,
+				RestartAttemptPeriod:   60,
+				UnhealthyRestartPeriod: 30,
+			}
+			testContainer.RestartTracker = restart.NewRestartTracker(*testContainer.RestartPolicy)
+			testContainer.SetKnownStatus(apicontainerstatus.ContainerRunning)
+			testContainer.SetDesiredStatus(apicontainerstatus.ContainerRunning)
+			testContainer.SetStartedAt(time.Now().Add(-time.Hour))
+			testContainer.Health = apicontainer.HealthStatus{
+				Status: tc.healthStatus,
+				Since:  aws.Time(tc.unhealthySince),
+			}
+
+			state.AddTask(testTask)
+			state.AddContainer(&apicontainer.DockerContainer{DockerID: "id",
+				DockerName: "container_name",
+				Container:  testContainer,
+			}, testTask)
+
+			if tc.expectStop {
+				client.EXPECT().StopContainer(gomock.Any(), "id", gomock.Any()).Return(
+					dockerapi.DockerContainerMetadata{DockerID: "id"})
+			}
+			taskEngine.(*DockerTaskEngine).restartUnhealthyContainers(testTask)
+			// A second check before the container has stopped must not stop it again
+			taskEngine.(*DockerTaskEngine).restartUnhealthyContainers(testTask)
+
+			testContainer.RestartTracker.RecordRestart()
+			if tc.expectStop {
+				assert.Equal(t, restart.RestartReasonUnhealthy, testContainer.RestartTracker.GetLastRestartReason())
+			} else {
+				assert.Equal(t, restart.RestartReasonExited, testContainer.RestartTracker.GetLastRestartReason())
+			}
+		})
+	}
+}
+
 func TestContainerMetadataUpdatedOnRestart(t *testing.T) {
 	dockerID := "dockerID_created"
 	labels := map[string]string{
@@ -2886,7 +2968,8 @@ func TestTaskSecretsEnvironmentVariables(t *testing.T) {
 				credentialsID,
 				credentialsManager,
 				ssmClientCreator,
-				testIPCompatibility)
+				testIPCompatibility,
+				nil)
 
 			// required for validating asm workflows
 			asmClientCreator := mock_asm_factory.NewMockClientCreator(ctrl)
@@ -2901,7 +2984,8 @@ func TestTaskSecretsEnvironmentVariables(t *testing.T) {
 				asmRequirements,
 				credentialsID,
 				credentialsManager,
-				asmClientCreator)
+				asmClientCreator,
+				nil)
 
 			testTask.ResourcesMapUnsafe = map[string][]taskresource.TaskResource{
 				ssmsecret.ResourceName: {ssmSecretRes},
@@ -3227,6 +3311,100 @@ func TestCreateContainerLogDriverBufferSize(t *testing.T) {
 	}
 }
 
+// TestCreateContainerLogShipperLogDriver tests that in createContainer, a container using the awslogshipper log
+// driver is created with the json-file log driver, and fails to be created when log shipping is disabled or when
+// its log options are invalid.
+func TestCreateContainerLogShipperLogDriver(t *testing.T) {
+	testCases := []struct {
+		name               string
+		logShippingEnabled bool
+		logOptions         map[string]string
+		expectedLogConfig  dockercontainer.LogConfig
+		expectedError      bool
+	}{
+		{
+			name:               "json-file log driver with json-file options",
+			logShippingEnabled: true,
+			logOptions: map[string]string{
+				"destination": "file",
+				"max-size":    "10m",
+			},
+			expectedLogConfig: dockercontainer.LogConfig{
+				Type:   "json-file",
+				Config: map[string]string{"max-size": "10m"},
+			},
+		},
+		{
+			name:               "log shipping disabled",
+			logShippingEnabled: false,
+			logOptions:         map[string]string{"destination": "file"},
+			expectedError:      true,
+		},
+		{
+			name:               "invalid log options",
+			logShippingEnabled: true,
+			logOptions:         map[string]string{"destination": "unknown"},
+			expectedError:      true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.TODO())
+			defer cancel()
+			cfg := defaultConfig
+			cfg.LogShippingEnabled = config.BooleanDefaultFalse{Value: config.ExplicitlyDisabled}
+			if tc.logShippingEnabled {
+				cfg.LogShippingEnabled = config.BooleanDefaultFalse{Value: config.ExplicitlyEnabled}
+			}
+			ctrl, client, _, taskEngine, _, _, _, _ := mocks(t, ctx, &cfg)
+			defer ctrl.Finish()
+
+			rawHostConfig, err := json.Marshal(&dockercontainer.HostConfig{
+				LogConfig: dockercontainer.LogConfig{
+					Type:   "awslogshipper",
+					Config: tc.logOptions,
+				},
+			})
+			require.NoError(t, err)
+			testTask := &apitask.Task{
+				Arn: "arn:aws:ecs:region:account-id:task/test-task-arn",
+				Containers: []*apicontainer.Container{
+					{
+						Name: "test-container",
+						DockerConfig: apicontainer.DockerConfig{
+							HostConfig: func() *string {
+								s := string(rawHostConfig)
+								return &s
+							}(),
+						},
+					},
+				},
+			}
+
+			client.EXPECT().APIVersion().Return(defaultDockerClientAPIVersion, nil).AnyTimes()
+			if !tc.expectedError {
+				client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
+					func(ctx context.Context,
+						config *dockercontainer.Config,
+						hostConfig *dockercontainer.HostConfig,
+						name string,
+						timeout time.Duration) {
+						assert.Equal(t, tc.expectedLogConfig, hostConfig.LogConfig)
+					})
+			}
+
+			ret := taskEngine.(*DockerTaskEngine).createContainer(testTask, testTask.Containers[0])
+			if tc.expectedError {
+				assert.Error(t, ret.Error)
+				assert.Equal(t, "CannotCreateContainerError", ret.Error.ErrorName())
+			} else {
+				assert.NoError(t, ret.Error)
+			}
+		})
+	}
+}
+
 // TestCreateContainerAddFirelensLogDriverConfig tests that in createContainer, when the
 // container is using firelens log driver, its logConfig is properly set.
 func TestCreateContainerAddFirelensLogDriverConfig(t *testing.T) {
@@ -3580,6 +3758,79 @@ func TestGetBridgeIP(t *testing.T) {
 	}
 }
 
+func TestReachablePortBindings(t *testing.T) {
+	bindings := []apicontainer.PortBinding{
+		{ContainerPort: 80, HostPort: 32768, BindIP: "0.0.0.0", Protocol: apicontainer.TransportProtocolTCP},
+		{ContainerPort: 80, HostPort: 32768, BindIP: "::", Protocol: apicontainer.TransportProtocolTCP},
+	}
+
+	assert.Equal(t, bindings, reachablePortBindings(bindings, ipcompatibility.NewDualStackCompatibility()))
+	assert.Equal(t, bindings, reachablePortBindings(bindings, ipcompatibility.NewIPv4OnlyCompatibility()))
+	assert.Equal(t, bindings[1:], reachablePortBindings(bindings, ipcompatibility.NewIPv6OnlyCompatibility()))
+}
+
+func TestGetBridgeIPv6(t *testing.T) {
+	networkDefaultIPv6 := "2600:1f14::1"
+	networkBridgeIPv6 := "2600:1f14::2"
+	testCases := []struct {
+		name              string
+		networkSettings   *types.NetworkSettings
+		expectedOk        bool
+		expectedIPAddress string
+	}{
+		{
+			name: "IPv4 address is preferred",
+			networkSettings: &types.NetworkSettings{
+				DefaultNetworkSettings: types.DefaultNetworkSettings{
+					GlobalIPv6Address: networkDefaultIPv6,
+				},
+				Networks: map[string]*network.EndpointSettings{
+					networkModeBridge: {IPAddress: networkBridgeIP, GlobalIPv6Address: networkBridgeIPv6},
+				},
+			},
+			expectedOk:        true,
+			expectedIPAddress: networkBridgeIP,
+		},
+		{
+			name: "default IPv6 address",
+			networkSettings: &types.NetworkSettings{
+				DefaultNetworkSettings: types.DefaultNetworkSettings{
+					GlobalIPv6Address: networkDefaultIPv6,
+				},
+			},
+			expectedOk:        true,
+			expectedIPAddress: networkDefaultIPv6,
+		},
+		{
+			name: "bridge IPv6 address",
+			networkSettings: &types.NetworkSettings{
+				Networks: map[string]*network.EndpointSettings{
+					networkModeBridge: {GlobalIPv6Address: networkBridgeIPv6},
+				},
+			},
+			expectedOk:        true,
+			expectedIPAddress: networkBridgeIPv6,
+		},
+		{
+			name: "IPv6 address of another network",
+			networkSettings: &types.NetworkSettings{
+				Networks: map[string]*network.EndpointSettings{
+					networkModeAWSVPC: {GlobalIPv6Address: networkBridgeIPv6},
+				},
+			},
+			expectedOk: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			IPAddress, ok := getContainerHostIP(tc.networkSettings)
+			assert.Equal(t, tc.expectedOk, ok)
+			assert.Equal(t, tc.expectedIPAddress, IPAddress)
+		})
+	}
+}
+
 func TestStartFirelensContainerRetryForContainerIP(t *testing.T) {
 	applicationContainerName := "logSenderTask"
 	firelensContainerName := "test-firelens"
@@ -5520,3 +5771,33 @@ func TestSetAWSLogsDualStackEndpoint(t *testing.T) {
 		})
 	}
 }
+
+func TestInstanceMetadata(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ec2MetadataClient := mock_ec2.NewMockEC2MetadataClient(ctrl)
+
+	taskEngine := &DockerTaskEngine{}
+	taskEngine.SetInstanceMetadata("us-west-2a", ec2MetadataClient)
+
+	gomock.InOrder(
+		ec2MetadataClient.EXPECT().PrivateIPv4Address().DoAndReturn(func() (string, error) {
+			// The instance metadata service is queried without holding the lock.
+			require.True(t, taskEngine.instanceMetadataLock.TryLock())
+			taskEngine.instanceMetadataLock.Unlock()
+			return "", errors.New("error")
+		}),
+		// The address is retrieved again after a failure, and is cached once it is retrieved.
+		ec2MetadataClient.EXPECT().PrivateIPv4Address().Return("10.0.0.1", nil),
+	)
+
+	availabilityZone, hostIPAddress := taskEngine.instanceMetadata()
+	assert.Equal(t, "us-west-2a", availabilityZone)
+	assert.Empty(t, hostIPAddress)
+
+	for i := 0; i < 2; i++ {
+		availabilityZone, hostIPAddress = taskEngine.instanceMetadata()
+		assert.Equal(t, "us-west-2a", availabilityZone)
+		assert.Equal(t, "10.0.0.1", hostIPAddress)
+	}
+}
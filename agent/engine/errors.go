@@ -46,9 +46,14 @@ func (err ContainerVanishedError) ErrorName() string { return "ContainerVanished
 // be resolved
 type TaskDependencyError struct {
 	taskArn string
+	// cause describes why the dependencies cannot be resolved, if known
+	cause error
 }
 
 func (err TaskDependencyError) Error() string {
+	if err.cause != nil {
+		return "Task dependencies cannot be resolved: " + err.cause.Error()
+	}
 	return "Task dependencies cannot be resolved, taskArn: " + err.taskArn
 }
 
@@ -0,0 +1,58 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"github.com/aws/amazon-ecs-agent/agent/data"
+	"github.com/aws/amazon-ecs-agent/agent/utils"
+)
+
+// hostPortAllocationStore saves the host port allocations of the allocator in the agent database.
+type hostPortAllocationStore struct {
+	dataClient data.Client
+}
+
+func (s *hostPortAllocationStore) SaveHostPortAllocation(allocation *utils.HostPortAllocation) error {
+	return s.dataClient.SaveHostPortAllocation(&data.HostPortAllocation{
+		Key:           allocation.Key,
+		TaskARN:       allocation.TaskARN,
+		Protocol:      allocation.Protocol,
+		HostPortRange: allocation.HostPortRange,
+		AllocatedAt:   allocation.AllocatedAt,
+		ReleasedAt:    allocation.ReleasedAt,
+	})
+}
+
+func (s *hostPortAllocationStore) DeleteHostPortAllocation(id string) error {
+	return s.dataClient.DeleteHostPortAllocation(id)
+}
+
+func (s *hostPortAllocationStore) GetHostPortAllocations() ([]*utils.HostPortAllocation, error) {
+	saved, err := s.dataClient.GetHostPortAllocations()
+	if err != nil {
+		return nil, err
+	}
+	allocations := make([]*utils.HostPortAllocation, 0, len(saved))
+	for _, allocation := range saved {
+		allocations = append(allocations, &utils.HostPortAllocation{
+			Key:           allocation.Key,
+			TaskARN:       allocation.TaskARN,
+			Protocol:      allocation.Protocol,
+			HostPortRange: allocation.HostPortRange,
+			AllocatedAt:   allocation.AllocatedAt,
+			ReleasedAt:    allocation.ReleasedAt,
+		})
+	}
+	return allocations, nil
+}
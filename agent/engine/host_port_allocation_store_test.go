@@ -0,0 +1,49 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostPortAllocationStore(t *testing.T) {
+	store := &hostPortAllocationStore{dataClient: newTestDataClient(t)}
+	allocation := &utils.HostPortAllocation{
+		Key:           "service/container/80/tcp",
+		TaskARN:       "arn:aws:ecs:us-west-2:1234567890:task/test-cluster/abc",
+		Protocol:      "tcp",
+		HostPortRange: "32768-32768",
+		AllocatedAt:   time.Now().UTC().Truncate(time.Second),
+	}
+	require.NoError(t, store.SaveHostPortAllocation(allocation))
+
+	allocations, err := store.GetHostPortAllocations()
+	require.NoError(t, err)
+	require.Len(t, allocations, 1)
+	assert.Equal(t, allocation, allocations[0])
+
+	require.NoError(t, store.DeleteHostPortAllocation(allocation.ID()))
+	allocations, err = store.GetHostPortAllocations()
+	require.NoError(t, err)
+	assert.Empty(t, allocations)
+}
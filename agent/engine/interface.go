@@ -21,6 +21,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/data"
 	dm "github.com/aws/amazon-ecs-agent/agent/engine/daemonmanager"
 	"github.com/aws/amazon-ecs-agent/agent/statechange"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/ec2"
 )
 
 // TaskEngine is an interface for the DockerTaskEngine
@@ -38,6 +39,9 @@ type TaskEngine interface {
 	StateChangeEvents() chan statechange.Event
 	// SetDataClient sets the data client that is used by the task engine.
 	SetDataClient(data.Client)
+	// SetInstanceMetadata sets the availability zone of the container instance and the client
+	// that the private IP address of the host is retrieved with, for templated environment files.
+	SetInstanceMetadata(string, ec2.EC2MetadataClient)
 
 	// AddTask adds a new task to the task engine and manages its container's
 	// lifecycle. If it returns an error, the task was not added.
@@ -49,6 +53,10 @@ type TaskEngine interface {
 	//   - else the upserted task is inserted into the task engine's state
 	UpsertTask(*apitask.Task)
 
+	// ReloadFirelensConfig regenerates the firelens config of a running task with the given log options of its
+	// containers, and signals the firelens container of the task to reload it.
+	ReloadFirelensConfig(string, map[string]map[string]string) error
+
 	// ListTasks lists all the tasks being managed by the TaskEngine.
 	ListTasks() ([]*apitask.Task, error)
 
@@ -28,6 +28,7 @@ import (
 	daemonmanager "github.com/aws/amazon-ecs-agent/agent/engine/daemonmanager"
 	image "github.com/aws/amazon-ecs-agent/agent/engine/image"
 	statechange "github.com/aws/amazon-ecs-agent/agent/statechange"
+	ec2 "github.com/aws/amazon-ecs-agent/ecs-agent/ec2"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -191,6 +192,20 @@ func (mr *MockTaskEngineMockRecorder) MustInit(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MustInit", reflect.TypeOf((*MockTaskEngine)(nil).MustInit), arg0)
 }
 
+// ReloadFirelensConfig mocks base method.
+func (m *MockTaskEngine) ReloadFirelensConfig(arg0 string, arg1 map[string]map[string]string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReloadFirelensConfig", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReloadFirelensConfig indicates an expected call of ReloadFirelensConfig.
+func (mr *MockTaskEngineMockRecorder) ReloadFirelensConfig(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReloadFirelensConfig", reflect.TypeOf((*MockTaskEngine)(nil).ReloadFirelensConfig), arg0, arg1)
+}
+
 // SaveState mocks base method.
 func (m *MockTaskEngine) SaveState() error {
 	m.ctrl.T.Helper()
@@ -229,6 +244,18 @@ func (mr *MockTaskEngineMockRecorder) SetDataClient(arg0 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDataClient", reflect.TypeOf((*MockTaskEngine)(nil).SetDataClient), arg0)
 }
 
+// SetInstanceMetadata mocks base method.
+func (m *MockTaskEngine) SetInstanceMetadata(arg0 string, arg1 ec2.EC2MetadataClient) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetInstanceMetadata", arg0, arg1)
+}
+
+// SetInstanceMetadata indicates an expected call of SetInstanceMetadata.
+func (mr *MockTaskEngineMockRecorder) SetInstanceMetadata(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetInstanceMetadata", reflect.TypeOf((*MockTaskEngine)(nil).SetInstanceMetadata), arg0, arg1)
+}
+
 // StateChangeEvents mocks base method.
 func (m *MockTaskEngine) StateChangeEvents() chan statechange.Event {
 	m.ctrl.T.Helper()
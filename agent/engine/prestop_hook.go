@@ -0,0 +1,59 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"context"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+)
+
+// runPreStopHook runs the pre-stop hook of the container, if any, and waits for it to finish
+// or time out. It is called right before the container is sent its stop signal, which only
+// happens once the dependency graph allows the container to stop, so hooks run in the same
+// order as container shutdown. Failures are logged and never prevent the container from stopping.
+func (engine *DockerTaskEngine) runPreStopHook(task *apitask.Task, container *apicontainer.Container, dockerID string) {
+	hook := container.GetPreStopHook()
+	if hook == nil || !container.IsRunning() {
+		return
+	}
+	fields := logger.Fields{
+		field.TaskID:    task.GetID(),
+		field.Container: container.Name,
+		field.RuntimeID: dockerID,
+	}
+	if err := hook.Validate(); err != nil {
+		logger.Warn("Skipping invalid pre-stop hook", fields, logger.Fields{field.Error: err})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(engine.ctx, hook.GetTimeout())
+	defer cancel()
+
+	logger.Info("Running pre-stop hook for container", fields)
+	var err error
+	if hook.Exec != nil {
+		err = engine.runExecAction(ctx, dockerID, hook.Exec)
+	} else {
+		err = runHTTPGetAction(ctx, task, container, hook.HTTPGet)
+	}
+	if err != nil {
+		logger.Warn("Pre-stop hook for container failed", fields, logger.Fields{field.Error: err})
+		return
+	}
+	logger.Info("Pre-stop hook for container completed", fields)
+}
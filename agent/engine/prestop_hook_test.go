@@ -0,0 +1,159 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
+	"github.com/aws/amazon-ecs-agent/agent/engine/testdata"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
+
+	"github.com/docker/docker/api/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const preStopTestDockerID = "dockerID"
+
+func preStopHookTestTask(hook *apicontainer.PreStopHook) (*apitask.Task, *apicontainer.Container) {
+	testTask := testdata.LoadTask("sleep5")
+	testContainer := testTask.Containers[0]
+	testContainer.PreStopHook = hook
+	testContainer.SetKnownStatus(apicontainerstatus.ContainerRunning)
+	testContainer.SetRuntimeID(preStopTestDockerID)
+	return testTask, testContainer
+}
+
+func TestStopContainerRunsPreStopExecHook(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	ctrl, client, _, taskEngine, _, _, _, _ := mocks(t, ctx, &defaultConfig)
+	defer ctrl.Finish()
+	execActionPollInterval = time.Millisecond
+
+	testTask, testContainer := preStopHookTestTask(&apicontainer.PreStopHook{
+		Exec: &apicontainer.ExecAction{Command: []string{"/bin/deregister"}},
+	})
+
+	gomock.InOrder(
+		client.EXPECT().CreateContainerExec(gomock.Any(), preStopTestDockerID, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, execConfig types.ExecConfig,
+				_ time.Duration) (*types.IDResponse, error) {
+				assert.Equal(t, []string{"/bin/deregister"}, execConfig.Cmd)
+				return &types.IDResponse{ID: "execID"}, nil
+			}),
+		client.EXPECT().StartContainerExec(gomock.Any(), "execID", gomock.Any(), gomock.Any()).Return(nil),
+		client.EXPECT().InspectContainerExec(gomock.Any(), "execID", gomock.Any()).Return(
+			&types.ContainerExecInspect{Running: true}, nil),
+		client.EXPECT().InspectContainerExec(gomock.Any(), "execID", gomock.Any()).Return(
+			&types.ContainerExecInspect{Running: false, ExitCode: 0}, nil),
+		client.EXPECT().StopContainer(gomock.Any(), preStopTestDockerID, gomock.Any()).Return(
+			dockerapi.DockerContainerMetadata{DockerID: preStopTestDockerID}),
+	)
+
+	md := taskEngine.(*DockerTaskEngine).stopContainer(testTask, testContainer)
+	assert.NoError(t, md.Error)
+}
+
+func TestStopContainerPreStopExecHookTimesOut(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	ctrl, client, _, taskEngine, _, _, _, _ := mocks(t, ctx, &defaultConfig)
+	defer ctrl.Finish()
+	execActionPollInterval = time.Millisecond
+
+	testTask, testContainer := preStopHookTestTask(&apicontainer.PreStopHook{
+		Exec:    &apicontainer.ExecAction{Command: []string{"sleep", "3600"}},
+		Timeout: 1,
+	})
+
+	client.EXPECT().CreateContainerExec(gomock.Any(), preStopTestDockerID, gomock.Any(), gomock.Any()).Return(
+		&types.IDResponse{ID: "execID"}, nil)
+	client.EXPECT().StartContainerExec(gomock.Any(), "execID", gomock.Any(), gomock.Any()).Return(nil)
+	client.EXPECT().InspectContainerExec(gomock.Any(), "execID", gomock.Any()).Return(
+		&types.ContainerExecInspect{Running: true}, nil).MinTimes(1)
+	client.EXPECT().StopContainer(gomock.Any(), preStopTestDockerID, gomock.Any()).Return(
+		dockerapi.DockerContainerMetadata{DockerID: preStopTestDockerID})
+
+	start := time.Now()
+	md := taskEngine.(*DockerTaskEngine).stopContainer(testTask, testContainer)
+	assert.NoError(t, md.Error)
+	assert.Less(t, time.Since(start), 10*time.Second)
+}
+
+func TestStopContainerRunsPreStopHTTPGetHook(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	ctrl, client, _, taskEngine, _, _, _, _ := mocks(t, ctx, &defaultConfig)
+	defer ctrl.Finish()
+
+	requested := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested <- r.URL.Path
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	_, portStr, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+	port, err := strconv.Atoi(portStr)
+	require.NoError(t, err)
+
+	testTask, testContainer := preStopHookTestTask(&apicontainer.PreStopHook{
+		HTTPGet: &apicontainer.HTTPGetAction{Port: uint16(port), Path: "/drain"},
+	})
+	testTask.NetworkMode = apitask.HostNetworkMode
+
+	client.EXPECT().StopContainer(gomock.Any(), preStopTestDockerID, gomock.Any()).Return(
+		dockerapi.DockerContainerMetadata{DockerID: preStopTestDockerID})
+
+	md := taskEngine.(*DockerTaskEngine).stopContainer(testTask, testContainer)
+	assert.NoError(t, md.Error)
+	select {
+	case path := <-requested:
+		assert.Equal(t, "/drain", path)
+	default:
+		t.Fatal("pre-stop hook endpoint was not called")
+	}
+}
+
+func TestStopContainerSkipsPreStopHookForStoppedContainer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	ctrl, client, _, taskEngine, _, _, _, _ := mocks(t, ctx, &defaultConfig)
+	defer ctrl.Finish()
+
+	testTask, testContainer := preStopHookTestTask(&apicontainer.PreStopHook{
+		Exec: &apicontainer.ExecAction{Command: []string{"/bin/deregister"}},
+	})
+	testContainer.SetKnownStatus(apicontainerstatus.ContainerCreated)
+
+	client.EXPECT().StopContainer(gomock.Any(), preStopTestDockerID, gomock.Any()).Return(
+		dockerapi.DockerContainerMetadata{DockerID: preStopTestDockerID})
+
+	md := taskEngine.(*DockerTaskEngine).stopContainer(testTask, testContainer)
+	assert.NoError(t, md.Error)
+}
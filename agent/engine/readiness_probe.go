@@ -0,0 +1,98 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+)
+
+// startReadinessProbe starts probing a running container in the background if it defines a
+// readiness probe that has not succeeded yet. Containers depending on it with the READY
+// condition are resolved by the task manager once the probe has succeeded.
+func (engine *DockerTaskEngine) startReadinessProbe(task *apitask.Task, container *apicontainer.Container) {
+	probe := container.GetReadinessProbe()
+	if probe == nil || container.IsReady() || !container.IsRunning() {
+		return
+	}
+	if err := probe.Validate(); err != nil {
+		logger.Warn("Skipping invalid readiness probe", logger.Fields{
+			field.TaskID:    task.GetID(),
+			field.Container: container.Name,
+			field.Error:     err,
+		})
+		return
+	}
+	if !container.SetReadinessProbeRunning(true) {
+		return
+	}
+	go engine.runReadinessProbe(task, container, probe)
+}
+
+// runReadinessProbe runs the probe every probe interval until it succeeds, the container stops
+// running or the engine exits.
+func (engine *DockerTaskEngine) runReadinessProbe(task *apitask.Task, container *apicontainer.Container,
+	probe *apicontainer.ReadinessProbe) {
+	defer container.SetReadinessProbeRunning(false)
+
+	ticker := time.NewTicker(probe.GetInterval())
+	defer ticker.Stop()
+	for {
+		if !container.IsRunning() || container.DesiredTerminal() {
+			return
+		}
+		err := engine.probeContainer(task, container, probe)
+		container.RecordReadinessProbeResult(err)
+		if err == nil {
+			logger.Info("Container readiness probe succeeded", logger.Fields{
+				field.TaskID:    task.GetID(),
+				field.Container: container.Name,
+			})
+			engine.saveContainerData(container)
+			return
+		}
+		logger.Debug("Container readiness probe failed", logger.Fields{
+			field.TaskID:    task.GetID(),
+			field.Container: container.Name,
+			field.Error:     err,
+		})
+
+		select {
+		case <-engine.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// probeContainer runs a single attempt of the readiness probe, bounded by the probe timeout.
+func (engine *DockerTaskEngine) probeContainer(task *apitask.Task, container *apicontainer.Container,
+	probe *apicontainer.ReadinessProbe) error {
+	ctx, cancel := context.WithTimeout(engine.ctx, probe.GetTimeout())
+	defer cancel()
+
+	switch {
+	case probe.Exec != nil:
+		return engine.runExecAction(ctx, container.GetRuntimeID(), probe.Exec)
+	case probe.HTTPGet != nil:
+		return runHTTPGetAction(ctx, task, container, probe.HTTPGet)
+	default:
+		return runTCPSocketAction(ctx, task, container, probe.TCPSocket)
+	}
+}
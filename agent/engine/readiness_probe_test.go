@@ -0,0 +1,116 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/engine/testdata"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
+
+	"github.com/docker/docker/api/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readinessProbeTestTask(probe *apicontainer.ReadinessProbe) (*apitask.Task, *apicontainer.Container) {
+	testTask := testdata.LoadTask("sleep5")
+	testTask.NetworkMode = apitask.HostNetworkMode
+	testContainer := testTask.Containers[0]
+	testContainer.ReadinessProbe = probe
+	testContainer.SetKnownStatus(apicontainerstatus.ContainerRunning)
+	testContainer.SetDesiredStatus(apicontainerstatus.ContainerRunning)
+	testContainer.SetRuntimeID("dockerID")
+	return testTask, testContainer
+}
+
+func TestReadinessProbeTCPSocket(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	ctrl, _, _, taskEngine, _, _, _, _ := mocks(t, ctx, &defaultConfig)
+	defer ctrl.Finish()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	_, portStr, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+	port, err := strconv.Atoi(portStr)
+	require.NoError(t, err)
+
+	testTask, testContainer := readinessProbeTestTask(&apicontainer.ReadinessProbe{
+		TCPSocket: &apicontainer.TCPSocketAction{Port: uint16(port)},
+	})
+	taskEngine.(*DockerTaskEngine).startReadinessProbe(testTask, testContainer)
+
+	assert.Eventually(t, testContainer.IsReady, 5*time.Second, 10*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		// the prober stops once the container is ready
+		return testContainer.SetReadinessProbeRunning(true)
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestReadinessProbeExecRetriesUntilSuccess(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	ctrl, client, _, taskEngine, _, _, _, _ := mocks(t, ctx, &defaultConfig)
+	defer ctrl.Finish()
+	execActionPollInterval = time.Millisecond
+
+	testTask, testContainer := readinessProbeTestTask(&apicontainer.ReadinessProbe{
+		Exec:     &apicontainer.ExecAction{Command: []string{"/bin/ready"}},
+		Interval: 1,
+	})
+
+	client.EXPECT().CreateContainerExec(gomock.Any(), "dockerID", gomock.Any(), gomock.Any()).Return(
+		&types.IDResponse{ID: "execID"}, nil).Times(2)
+	client.EXPECT().StartContainerExec(gomock.Any(), "execID", gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	gomock.InOrder(
+		client.EXPECT().InspectContainerExec(gomock.Any(), "execID", gomock.Any()).Return(
+			&types.ContainerExecInspect{Running: false, ExitCode: 1}, nil),
+		client.EXPECT().InspectContainerExec(gomock.Any(), "execID", gomock.Any()).Return(
+			&types.ContainerExecInspect{Running: false, ExitCode: 0}, nil),
+	)
+
+	taskEngine.(*DockerTaskEngine).startReadinessProbe(testTask, testContainer)
+	assert.Eventually(t, testContainer.IsReady, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 2, testContainer.GetReadinessStatus().Attempts)
+}
+
+func TestReadinessProbeNotStartedForStoppedContainer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	ctrl, _, _, taskEngine, _, _, _, _ := mocks(t, ctx, &defaultConfig)
+	defer ctrl.Finish()
+
+	testTask, testContainer := readinessProbeTestTask(&apicontainer.ReadinessProbe{
+		TCPSocket: &apicontainer.TCPSocketAction{Port: 1},
+	})
+	testContainer.SetKnownStatus(apicontainerstatus.ContainerStopped)
+
+	taskEngine.(*DockerTaskEngine).startReadinessProbe(testTask, testContainer)
+	// no prober was started, so the running flag can still be set
+	assert.True(t, testContainer.SetReadinessProbeRunning(true))
+	assert.Equal(t, 0, testContainer.GetReadinessStatus().Attempts)
+}
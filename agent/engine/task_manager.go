@@ -35,6 +35,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
 	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
 	taskresourcevolume "github.com/aws/amazon-ecs-agent/agent/taskresource/volume"
+	"github.com/aws/amazon-ecs-agent/agent/utils"
 	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
 	apierrors "github.com/aws/amazon-ecs-agent/ecs-agent/api/errors"
 	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
@@ -219,6 +220,11 @@ func (mtask *managedTask) overseeTask() {
 	// If this was a 'state restore', send all unsent statuses
 	mtask.emitCurrentStatus()
 
+	// Resume readiness probes of containers that were already running before a 'state restore'
+	for _, container := range mtask.Containers {
+		mtask.engine.startReadinessProbe(mtask.Task, container)
+	}
+
 	// Main infinite loop. This is where we receive messages and dispatch work.
 	for {
 		if mtask.shouldExit() {
@@ -260,6 +266,8 @@ func (mtask *managedTask) overseeTask() {
 	mtask.engine.wakeUpTaskQueueMonitor()
 	// TODO: make this idempotent on agent restart
 	go mtask.releaseIPInIPAM()
+	// Start the reuse cooldown of the dynamic host ports of the task
+	utils.ReleaseHostPorts(mtask.Arn)
 
 	if mtask.Task.IsEBSTaskAttachEnabled() {
 		csiClient := csiclient.NewCSIClient(filepath.Join(csiclient.DefaultSocketHostPath, csiclient.DefaultImageName,
@@ -339,6 +347,7 @@ func (mtask *managedTask) waitSteady() {
 			field.TaskID: mtask.GetID(),
 		})
 		go mtask.engine.checkTaskState(mtask.Task)
+		go mtask.engine.restartUnhealthyContainers(mtask.Task)
 	}
 }
 
@@ -467,7 +476,7 @@ func (mtask *managedTask) handleContainerChange(containerChange dockerContainerC
 
 		// Only update container metadata when status stays RUNNING
 		if event.Status == containerKnownStatus && event.Status == apicontainerstatus.ContainerRunning {
-			updateContainerMetadata(&event.DockerContainerMetadata, container, mtask.Task)
+			updateContainerMetadata(&event.DockerContainerMetadata, container, mtask.Task, mtask.cfg.InstanceIPCompatibility)
 		}
 		return
 	}
@@ -483,12 +492,14 @@ func (mtask *managedTask) handleContainerChange(containerChange dockerContainerC
 			container.GetDesiredStatus())
 		if shouldRestart {
 			container.RestartTracker.RecordRestart()
+			container.ResetHealthStatus()
 			resp := mtask.engine.startContainer(mtask.Task, container)
 			if resp.Error == nil {
 				logger.Info("Restarted container", eventLogFields,
 					logger.Fields{
-						"restartCount":  container.RestartTracker.GetRestartCount(),
-						"lastRestartAt": container.RestartTracker.GetLastRestartAt().UTC().Format(time.RFC3339),
+						"restartCount":      container.RestartTracker.GetRestartCount(),
+						"lastRestartAt":     container.RestartTracker.GetLastRestartAt().UTC().Format(time.RFC3339),
+						"lastRestartReason": container.RestartTracker.GetLastRestartReason(),
 					})
 				// return here because we have now restarted the container, and we don't
 				// want to complete the rest of the "container stop" workflow
@@ -501,6 +512,7 @@ func (mtask *managedTask) handleContainerChange(containerChange dockerContainerC
 					field.Error:     resp.Error,
 				})
 		} else {
+			container.RestartTracker.ClearPendingRestart()
 			logger.Info("Not Restarting container", eventLogFields,
 				logger.Fields{
 					"restartCount":  container.RestartTracker.GetRestartCount(),
@@ -513,7 +525,7 @@ func (mtask *managedTask) handleContainerChange(containerChange dockerContainerC
 	// Update the container to be known
 	currentKnownStatus := containerKnownStatus
 	container.SetKnownStatus(event.Status)
-	updateContainerMetadata(&event.DockerContainerMetadata, container, mtask.Task)
+	updateContainerMetadata(&event.DockerContainerMetadata, container, mtask.Task, mtask.cfg.InstanceIPCompatibility)
 
 	if event.Error != nil {
 		proceedAnyway := mtask.handleEventError(containerChange, currentKnownStatus)
@@ -527,6 +539,10 @@ func (mtask *managedTask) handleContainerChange(containerChange dockerContainerC
 		mtask.handleManagedAgentStoppedTransition(container, execcmd.ExecuteCommandAgentName)
 	}
 
+	if container.GetKnownStatus() == apicontainerstatus.ContainerRunning {
+		mtask.engine.startReadinessProbe(mtask.Task, container)
+	}
+
 	mtask.RecordExecutionStoppedAt(container)
 	logger.Debug("Sending container change event to tcs", eventLogFields)
 	err := mtask.containerChangeEventStream.WriteToEventStream(event)
@@ -1550,6 +1566,9 @@ func (mtask *managedTask) time() ttime.Time {
 
 func (mtask *managedTask) cleanupTask(taskStoppedDuration time.Duration) {
 	taskExecutionCredentialsID := mtask.GetExecutionCredentialsID()
+	// Archive the volumes that are to be exported while the cleanup time elapses, so that their
+	// contents are preserved before the containers and volumes of the task are removed
+	volumeExportsDone := mtask.exportVolumes()
 	cleanupTimeDuration := mtask.GetKnownStatusTime().Add(taskStoppedDuration).Sub(ttime.Now())
 	cleanupTime := make(<-chan time.Time)
 	if cleanupTimeDuration < 0 {
@@ -1579,6 +1598,10 @@ func (mtask *managedTask) cleanupTask(taskStoppedDuration time.Duration) {
 		return
 	}
 
+	// wait for the volume exports to complete
+	for !mtask.waitEvent(volumeExportsDone) {
+	}
+
 	logger.Info("Cleaning up task's containers and data", logger.Fields{
 		field.TaskID: mtask.GetID(),
 	})
@@ -1604,6 +1627,24 @@ func (mtask *managedTask) cleanupTask(taskStoppedDuration time.Duration) {
 	mtask.cancel()
 }
 
+// exportVolumes archives the task volumes that have an export configuration in the background. The
+// returned channel is closed once every export has either completed or failed.
+func (mtask *managedTask) exportVolumes() chan struct{} {
+	done := make(chan struct{})
+	if mtask.engine.volumeExporter == nil {
+		close(done)
+		return done
+	}
+	go func() {
+		defer close(done)
+		mtask.engine.volumeExporter.ExportTask(mtask.ctx, mtask.Task)
+		if len(mtask.GetVolumeExports()) > 0 {
+			mtask.engine.saveTaskData(mtask.Task)
+		}
+	}()
+	return done
+}
+
 func (mtask *managedTask) discardEvents() {
 	for {
 		select {
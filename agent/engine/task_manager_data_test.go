@@ -22,6 +22,7 @@ import (
 
 	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
 	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/config"
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
 	"github.com/aws/amazon-ecs-agent/agent/statechange"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
@@ -156,6 +157,7 @@ func TestHandleContainerStateChangeSaveData(t *testing.T) {
 				engine: &DockerTaskEngine{
 					dataClient: dataClient,
 				},
+				cfg:                        &config.Config{},
 				ctx:                        context.TODO(),
 				containerChangeEventStream: containerChangeEventStream,
 				stateChangeEvents:          make(chan statechange.Event),
@@ -209,6 +211,7 @@ func TestHandleContainerChangeWithTaskStateChangeSaveData(t *testing.T) {
 		engine: &DockerTaskEngine{
 			dataClient: dataClient,
 		},
+		cfg:                        &config.Config{},
 		ctx:                        context.TODO(),
 		containerChangeEventStream: containerChangeEventStream,
 		stateChangeEvents:          make(chan statechange.Event),
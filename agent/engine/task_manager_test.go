@@ -906,6 +906,7 @@ func TestStartContainerTransitionsInvokesHandleContainerChange(t *testing.T) {
 	stateChangeEvents := make(chan statechange.Event)
 
 	task := &managedTask{
+		cfg: &config.Config{},
 		Task: &apitask.Task{
 			Containers: []*apicontainer.Container{
 				firstContainer,
@@ -1764,6 +1765,7 @@ func TestHandleContainerChangeUpdateContainerHealth(t *testing.T) {
 	containerChangeEventStream.StartListening()
 
 	mTask := &managedTask{
+		cfg:                        &config.Config{},
 		Task:                       testdata.LoadTask("sleep5TaskCgroup"),
 		containerChangeEventStream: containerChangeEventStream,
 		stateChangeEvents:          make(chan statechange.Event),
@@ -1809,6 +1811,7 @@ func TestHandleContainerChangeUpdateMetadataRedundant(t *testing.T) {
 	containerChangeEventStream.StartListening()
 
 	mTask := &managedTask{
+		cfg:                        &config.Config{},
 		Task:                       testdata.LoadTask("sleep5TaskCgroup"),
 		containerChangeEventStream: containerChangeEventStream,
 		stateChangeEvents:          make(chan statechange.Event),
@@ -1884,6 +1887,7 @@ func TestHandleContainerChangeStopped(t *testing.T) {
 
 	hostResourceManager := NewHostResourceManager(getTestHostResources())
 	mTask := &managedTask{
+		cfg:                        &config.Config{},
 		Task:                       testdata.LoadTask("sleep5"),
 		containerChangeEventStream: containerChangeEventStream,
 		stateChangeEvents:          make(chan statechange.Event),
@@ -1978,6 +1982,7 @@ func TestHandleContainerChangeStopped_WithRestartPolicy_RestartFails(t *testing.
 	cfg := getTestConfig()
 	hostResourceManager := NewHostResourceManager(getTestHostResources())
 	mTask := &managedTask{
+		cfg:                        &config.Config{},
 		Task:                       testdata.LoadTask("sleep5RestartPolicy"),
 		containerChangeEventStream: containerChangeEventStream,
 		stateChangeEvents:          make(chan statechange.Event),
@@ -2030,6 +2035,7 @@ func TestHandleContainerChangeStopped_WithRestartPolicy_DesiredStopped(t *testin
 	cfg := getTestConfig()
 	hostResourceManager := NewHostResourceManager(getTestHostResources())
 	mTask := &managedTask{
+		cfg:                        &config.Config{},
 		Task:                       testdata.LoadTask("sleep5RestartPolicy"),
 		containerChangeEventStream: containerChangeEventStream,
 		stateChangeEvents:          make(chan statechange.Event),
@@ -0,0 +1,51 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package handlers
+
+import (
+	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials/scoped"
+)
+
+// containerRoleResolver resolves the roles of containers from the state of the task engine.
+type containerRoleResolver struct {
+	state dockerstate.TaskEngineState
+}
+
+// ContainerRole returns the role of the container with the given v3 endpoint id.
+func (r *containerRoleResolver) ContainerRole(endpointContainerID string) (scoped.ContainerRole, bool) {
+	taskARN, ok := r.state.TaskARNByV3EndpointID(endpointContainerID)
+	if !ok {
+		return scoped.ContainerRole{}, false
+	}
+	task, ok := r.state.TaskByArn(taskARN)
+	if !ok {
+		return scoped.ContainerRole{}, false
+	}
+	for _, container := range task.Containers {
+		if container.GetV3EndpointID() != endpointContainerID {
+			continue
+		}
+		if container.RoleArn == "" {
+			return scoped.ContainerRole{}, false
+		}
+		return scoped.ContainerRole{
+			TaskARN:       taskARN,
+			ContainerName: container.Name,
+			CredentialsID: task.GetCredentialsID(),
+			RoleARN:       container.RoleArn,
+		}, true
+	}
+	return scoped.ContainerRole{}, false
+}
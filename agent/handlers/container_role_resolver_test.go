@@ -0,0 +1,74 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package handlers
+
+import (
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	mock_dockerstate "github.com/aws/amazon-ecs-agent/agent/engine/dockerstate/mocks"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials/scoped"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContainerRoleResolver(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	state := mock_dockerstate.NewMockTaskEngineState(ctrl)
+	resolver := &containerRoleResolver{state: state}
+
+	task := &apitask.Task{
+		Arn: taskARN,
+		Containers: []*apicontainer.Container{
+			{
+				Name:         "app",
+				V3EndpointID: "app-endpoint-id",
+			},
+			{
+				Name:         "sidecar",
+				V3EndpointID: "sidecar-endpoint-id",
+				RoleArn:      "arn:aws:iam::123456789012:role/sidecar",
+			},
+		},
+	}
+	task.SetCredentialsID("credsid")
+
+	state.EXPECT().TaskARNByV3EndpointID("sidecar-endpoint-id").Return(taskARN, true)
+	state.EXPECT().TaskARNByV3EndpointID("app-endpoint-id").Return(taskARN, true)
+	state.EXPECT().TaskARNByV3EndpointID("unknown").Return("", false)
+	state.EXPECT().TaskByArn(taskARN).Return(task, true).Times(2)
+
+	role, ok := resolver.ContainerRole("sidecar-endpoint-id")
+	assert.True(t, ok)
+	assert.Equal(t, scoped.ContainerRole{
+		TaskARN:       taskARN,
+		ContainerName: "sidecar",
+		CredentialsID: "credsid",
+		RoleARN:       "arn:aws:iam::123456789012:role/sidecar",
+	}, role)
+
+	// Containers without a role of their own use the credentials of the task role.
+	_, ok = resolver.ContainerRole("app-endpoint-id")
+	assert.False(t, ok)
+
+	_, ok = resolver.ContainerRole("unknown")
+	assert.False(t, ok)
+}
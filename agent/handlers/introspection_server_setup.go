@@ -22,15 +22,24 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/config"
 	"github.com/aws/amazon-ecs-agent/agent/engine"
 	v1 "github.com/aws/amazon-ecs-agent/agent/handlers/v1"
+	"github.com/aws/amazon-ecs-agent/agent/logshipper"
+	"github.com/aws/amazon-ecs-agent/agent/packetcapture"
+	"github.com/aws/amazon-ecs-agent/agent/secretcache"
+	"github.com/aws/amazon-ecs-agent/agent/stats"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/introspection"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/metrics"
+	netlibdata "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/data"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/retry"
 
 	"github.com/cihub/seelog"
 )
 
 // ServeIntrospectionHTTPEndpoint serves information about this agent/containerInstance and tasks running on it.
-func ServeIntrospectionHTTPEndpoint(ctx context.Context, containerInstanceArn *string, taskEngine engine.TaskEngine, cfg *config.Config) {
+// networkDAO looks up the network configuration of tasks, and platformOptions register the handlers of
+// the features that are only available on some platforms.
+func ServeIntrospectionHTTPEndpoint(ctx context.Context, containerInstanceArn *string, taskEngine engine.TaskEngine,
+	statsEngine stats.Engine, secretCache *secretcache.Cache, logShipper *logshipper.Shipper,
+	networkDAO netlibdata.NetworkDataClient, cfg *config.Config, platformOptions ...introspection.ConfigOpt) {
 	// Is this the right level to type assert, assuming we'd abstract multiple taskengines here?
 	// Revisit if we ever add another type..
 	dockerTaskEngine := taskEngine.(*engine.DockerTaskEngine)
@@ -40,13 +49,43 @@ func ServeIntrospectionHTTPEndpoint(ctx context.Context, containerInstanceArn *s
 		TaskEngine:           dockerTaskEngine,
 	}
 
-	server, err := introspection.NewServer(
-		agentState,
-		metrics.NewNopEntryFactory(),
+	options := []introspection.ConfigOpt{
 		introspection.WithReadTimeout(readTimeout),
 		introspection.WithWriteTimeout(writeTimeout),
 		introspection.WithRuntimeStats(cfg.EnableRuntimeStats.Enabled()),
-	)
+		introspection.WithHandler(v1.TaskDependencyGraphPath, v1.TaskDependencyGraphHandler(dockerTaskEngine)),
+		introspection.WithHandler(v1.TaskNetworkFlowsPath, v1.TaskNetworkFlowsHandler(statsEngine)),
+		introspection.WithHandler(v1.TaskServiceConnectPath, v1.TaskServiceConnectHandler(dockerTaskEngine, statsEngine)),
+	}
+	if secretCache != nil {
+		options = append(options,
+			introspection.WithHandler(v1.SecretCacheStatsPath, v1.SecretCacheStatsHandler(secretCache)))
+	}
+	if logShipper != nil {
+		options = append(options,
+			introspection.WithHandler(v1.LogShippingStatsPath, v1.LogShippingStatsHandler(logShipper)))
+	}
+	if cfg.TaskPacketCaptureEnabled.Enabled() {
+		manager := packetcapture.NewManager(cfg.DataDir, cfg.TaskPacketCaptureRetention, networkDAO)
+		go manager.StartCleanup(ctx)
+		options = append(options,
+			introspection.WithHandler(v1.TaskPacketCapturesPath,
+				v1.TaskPacketCapturesHandler(dockerTaskEngine, manager, cfg.TaskPacketCaptureAuthToken)),
+			introspection.WithHandler(v1.PacketCapturePath,
+				v1.PacketCaptureHandler(manager, cfg.TaskPacketCaptureAuthToken)),
+			introspection.WithHandler(v1.PacketCaptureDownloadPath,
+				v1.PacketCaptureDownloadHandler(manager, cfg.TaskPacketCaptureAuthToken)),
+		)
+	}
+	if cfg.FirelensConfigReloadAuthToken != "" {
+		options = append(options,
+			introspection.WithHandler(v1.TaskFirelensConfigPath,
+				v1.TaskFirelensConfigHandler(dockerTaskEngine, cfg.FirelensConfigReloadAuthToken)))
+	}
+
+	options = append(options, platformOptions...)
+
+	server, err := introspection.NewServer(agentState, metrics.NewNopEntryFactory(), options...)
 
 	if err != nil {
 		seelog.Criticalf("Failed to set up Introspection Server: %v", err)
@@ -26,10 +26,12 @@ import (
 
 	"github.com/aws/amazon-ecs-agent/agent/config"
 	"github.com/aws/amazon-ecs-agent/agent/engine"
+	mock_stats "github.com/aws/amazon-ecs-agent/agent/stats/mock"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/introspection"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/introspection/v1/handlers"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -49,7 +51,10 @@ func TestServeIntrospectionHTTPEndpoint(t *testing.T) {
 		return fmt.Errorf("timed out waiting for server %s to come up: %w", serverAddress, err)
 	}
 
-	go ServeIntrospectionHTTPEndpoint(context.Background(), aws.String("test_container_instance_arn"), &engine.DockerTaskEngine{}, &config.Config{Cluster: clusterName})
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	go ServeIntrospectionHTTPEndpoint(context.Background(), aws.String("test_container_instance_arn"), &engine.DockerTaskEngine{},
+		mock_stats.NewMockEngine(ctrl), nil, nil, nil, &config.Config{Cluster: clusterName})
 
 	client := http.DefaultClient
 	err := waitForServer(client, serverAddress)
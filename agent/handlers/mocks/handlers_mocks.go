@@ -13,7 +13,7 @@
 //
 
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/aws/amazon-ecs-agent/agent/handlers/utils (interfaces: DockerStateResolver)
+// Source: github.com/aws/amazon-ecs-agent/agent/handlers/utils (interfaces: DockerStateResolver,FirelensConfigReloader)
 
 // Package mock_utils is a generated GoMock package.
 package mock_utils
@@ -61,3 +61,54 @@ func (mr *MockDockerStateResolverMockRecorder) State() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "State", reflect.TypeOf((*MockDockerStateResolver)(nil).State))
 }
+
+// MockFirelensConfigReloader is a mock of FirelensConfigReloader interface.
+type MockFirelensConfigReloader struct {
+	ctrl     *gomock.Controller
+	recorder *MockFirelensConfigReloaderMockRecorder
+}
+
+// MockFirelensConfigReloaderMockRecorder is the mock recorder for MockFirelensConfigReloader.
+type MockFirelensConfigReloaderMockRecorder struct {
+	mock *MockFirelensConfigReloader
+}
+
+// NewMockFirelensConfigReloader creates a new mock instance.
+func NewMockFirelensConfigReloader(ctrl *gomock.Controller) *MockFirelensConfigReloader {
+	mock := &MockFirelensConfigReloader{ctrl: ctrl}
+	mock.recorder = &MockFirelensConfigReloaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFirelensConfigReloader) EXPECT() *MockFirelensConfigReloaderMockRecorder {
+	return m.recorder
+}
+
+// ReloadFirelensConfig mocks base method.
+func (m *MockFirelensConfigReloader) ReloadFirelensConfig(arg0 string, arg1 map[string]map[string]string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReloadFirelensConfig", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReloadFirelensConfig indicates an expected call of ReloadFirelensConfig.
+func (mr *MockFirelensConfigReloaderMockRecorder) ReloadFirelensConfig(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReloadFirelensConfig", reflect.TypeOf((*MockFirelensConfigReloader)(nil).ReloadFirelensConfig), arg0, arg1)
+}
+
+// State mocks base method.
+func (m *MockFirelensConfigReloader) State() dockerstate.TaskEngineState {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "State")
+	ret0, _ := ret[0].(dockerstate.TaskEngineState)
+	return ret0
+}
+
+// State indicates an expected call of State.
+func (mr *MockFirelensConfigReloaderMockRecorder) State() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "State", reflect.TypeOf((*MockFirelensConfigReloader)(nil).State))
+}
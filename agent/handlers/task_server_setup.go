@@ -15,7 +15,10 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"net"
 	"net/http"
+	"syscall"
 	"time"
 
 	"github.com/aws/amazon-ecs-agent/agent/config"
@@ -28,6 +31,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/stats"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/api/ecs"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials/scoped"
 	auditinterface "github.com/aws/amazon-ecs-agent/ecs-agent/logger/audit"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/metrics"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/tmds"
@@ -72,6 +76,7 @@ func taskServerSetup(
 	vpcID string,
 	containerInstanceArn string,
 	taskProtectionClientFactory tp.TaskProtectionClientFactoryInterface,
+	scopedCredentialsManager scoped.Manager,
 ) (*http.Server, error) {
 	muxRouter := mux.NewRouter()
 
@@ -85,7 +90,8 @@ func taskServerSetup(
 	tmdsAgentState := v4.NewTMDSAgentState(state, statsEngine, ecsClient, cluster, availabilityZone, vpcID, containerInstanceArn)
 	metricsFactory := metrics.NewNopEntryFactory()
 
-	v2HandlersSetup(muxRouter, state, ecsClient, statsEngine, cluster, credentialsManager, auditLogger, availabilityZone, containerInstanceArn)
+	v2HandlersSetup(muxRouter, state, ecsClient, statsEngine, cluster, credentialsManager, scopedCredentialsManager,
+		auditLogger, availabilityZone, containerInstanceArn)
 
 	v3HandlersSetup(muxRouter, state, ecsClient, statsEngine, cluster, availabilityZone, containerInstanceArn)
 
@@ -114,10 +120,23 @@ func v2HandlersSetup(muxRouter *mux.Router,
 	statsEngine stats.Engine,
 	cluster string,
 	credentialsManager credentials.Manager,
+	scopedCredentialsManager scoped.Manager,
 	auditLogger auditinterface.AuditLogger,
 	availabilityZone string,
 	containerInstanceArn string,
 ) {
+	// The paths of scoped credentials are under the credentials path, which matches any path
+	// under it, and have to be registered before it.
+	muxRouter.HandleFunc(tmdsv2.SessionCredentialsProcessPath,
+		tmdsv2.SessionCredentialsHandler(scopedCredentialsManager, auditLogger, tmdsv1.FormatProcessCredentials))
+	muxRouter.HandleFunc(tmdsv2.SessionCredentialsPath,
+		tmdsv2.SessionCredentialsHandler(scopedCredentialsManager, auditLogger, tmdsv1.FormatCredentials))
+	muxRouter.HandleFunc(tmdsv2.ContainerCredentialsProcessPath,
+		tmdsv2.ContainerCredentialsHandler(scopedCredentialsManager, auditLogger, tmdsv1.FormatProcessCredentials))
+	muxRouter.HandleFunc(tmdsv2.ContainerCredentialsPath,
+		tmdsv2.ContainerCredentialsHandler(scopedCredentialsManager, auditLogger, tmdsv1.FormatCredentials))
+	muxRouter.HandleFunc(tmdsv2.CredentialsProcessPath, tmdsv2.CredentialsProcessHandler(credentialsManager, auditLogger))
+	muxRouter.HandleFunc(tmdsv2.SessionPath, tmdsv2.SessionHandler(scopedCredentialsManager, auditLogger))
 	muxRouter.HandleFunc(tmdsv2.CredentialsPath, tmdsv2.CredentialsHandler(credentialsManager, auditLogger))
 	muxRouter.HandleFunc(v2.ContainerMetadataPath, v2.TaskContainerMetadataHandler(state, ecsClient, cluster, availabilityZone, containerInstanceArn, false))
 	muxRouter.HandleFunc(v2.TaskMetadataPath, v2.TaskContainerMetadataHandler(state, ecsClient, cluster, availabilityZone, containerInstanceArn, false))
@@ -358,9 +377,11 @@ func ServeTaskHTTPEndpoint(
 	taskProtectionClientFactory := tpfactory.TaskProtectionClientFactory{
 		Region: cfg.AWSRegion, Endpoint: cfg.APIEndpoint, AcceptInsecureCert: cfg.AcceptInsecureCert, IPCompatibility: cfg.InstanceIPCompatibility,
 	}
+	scopedCredentialsManager := scoped.NewManager(credentialsManager, &containerRoleResolver{state: state},
+		cfg.AWSRegion, cfg.InstanceIPCompatibility)
 	server, err := taskServerSetup(credentialsManager, auditLogger, state, ecsClient, cfg.Cluster,
 		statsEngine, cfg.TaskMetadataSteadyStateRate, cfg.TaskMetadataBurstRate,
-		availabilityZone, vpcID, containerInstanceArn, taskProtectionClientFactory)
+		availabilityZone, vpcID, containerInstanceArn, taskProtectionClientFactory, scopedCredentialsManager)
 	if err != nil {
 		seelog.Criticalf("Failed to set up Task Metadata Server: %v", err)
 		return
@@ -374,6 +395,10 @@ func ServeTaskHTTPEndpoint(
 		}
 	}()
 
+	if cfg.InstanceIPCompatibility.IsIPv6Compatible() {
+		go serveTaskHTTPEndpointIPv6(server)
+	}
+
 	for {
 		retry.RetryWithBackoff(retry.NewExponentialBackoff(time.Second, time.Minute, 0.2, 2), func() error {
 			if err := server.ListenAndServe(); err != http.ErrServerClosed {
@@ -385,3 +410,26 @@ func ServeTaskHTTPEndpoint(
 		})
 	}
 }
+
+// serveTaskHTTPEndpointIPv6 serves the task HTTP endpoint on its IPv6 address, for tasks using IPv6.
+// The address is assigned to the loopback interface of the host by ecs-init, and the endpoint is not
+// served over IPv6 if it is not.
+func serveTaskHTTPEndpointIPv6(server *http.Server) {
+	retry.RetryWithBackoff(retry.NewExponentialBackoff(time.Second, time.Minute, 0.2, 2), func() error {
+		listener, err := net.Listen("tcp6", tmds.AddressIPv6())
+		if err != nil {
+			if errors.Is(err, syscall.EADDRNOTAVAIL) {
+				seelog.Infof("Not serving task api over IPv6: address %s is not assigned to the host", tmds.IPv6)
+				return nil
+			}
+			seelog.Errorf("Error listening for task api over IPv6: %v", err)
+			return err
+		}
+		if err := server.Serve(listener); err != http.ErrServerClosed {
+			seelog.Errorf("Error running task api over IPv6: %v", err)
+			return err
+		}
+		// server was cleanly closed via context
+		return nil
+	})
+}
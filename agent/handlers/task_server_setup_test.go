@@ -43,6 +43,7 @@ import (
 	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
 	mock_credentials "github.com/aws/amazon-ecs-agent/ecs-agent/credentials/mocks"
+	mock_scoped "github.com/aws/amazon-ecs-agent/ecs-agent/credentials/scoped/mocks"
 	mock_audit "github.com/aws/amazon-ecs-agent/ecs-agent/logger/audit/mocks"
 	mock_metrics "github.com/aws/amazon-ecs-agent/ecs-agent/metrics/mocks"
 	ni "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
@@ -1052,7 +1053,7 @@ func testErrorResponsesFromServer(t *testing.T, path string, expectedErrorMessag
 	ecsClient := mock_ecs.NewMockECSClient(ctrl)
 	server, err := taskServerSetup(credentialsManager, auditLog, nil, ecsClient, "", nil,
 		config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", vpcID,
-		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl))
+		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl), nil)
 	require.NoError(t, err)
 
 	recorder := httptest.NewRecorder()
@@ -1089,7 +1090,7 @@ func getResponseForCredentialsRequest(t *testing.T, expectedStatus int,
 	ecsClient := mock_ecs.NewMockECSClient(ctrl)
 	server, err := taskServerSetup(credentialsManager, auditLog, nil, ecsClient, "", nil,
 		config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", vpcID,
-		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl))
+		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl), nil)
 	require.NoError(t, err)
 
 	recorder := httptest.NewRecorder()
@@ -1148,7 +1149,7 @@ func TestV3ContainerAssociations(t *testing.T) {
 	)
 	server, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
 		config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", vpcID,
-		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl))
+		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl), nil)
 	require.NoError(t, err)
 	recorder := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", v3BasePath+v3EndpointID+"/associations/"+associationType, nil)
@@ -1180,7 +1181,7 @@ func TestV3ContainerAssociation(t *testing.T) {
 	)
 	server, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
 		config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", vpcID,
-		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl))
+		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl), nil)
 	require.NoError(t, err)
 	recorder := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", v3BasePath+v3EndpointID+"/associations/"+associationType+"/"+associationName, nil)
@@ -1211,7 +1212,7 @@ func TestV4ContainerAssociations(t *testing.T) {
 	)
 	server, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
 		config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", vpcID,
-		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl))
+		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl), nil)
 	require.NoError(t, err)
 	recorder := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", v4BasePath+v3EndpointID+"/associations/"+associationType, nil)
@@ -1243,7 +1244,7 @@ func TestV4ContainerAssociation(t *testing.T) {
 	)
 	server, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
 		config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", vpcID,
-		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl))
+		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl), nil)
 	require.NoError(t, err)
 	recorder := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", v4BasePath+v3EndpointID+"/associations/"+associationType+"/"+associationName, nil)
@@ -1270,7 +1271,7 @@ func TestTaskHTTPEndpoint301Redirect(t *testing.T) {
 
 	server, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
 		config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", vpcID,
-		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl))
+		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl), nil)
 	require.NoError(t, err)
 
 	for testPath, expectedPath := range testPathsMap {
@@ -1313,7 +1314,7 @@ func TestTaskHTTPEndpointErrorCode404(t *testing.T) {
 
 	server, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
 		config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", vpcID,
-		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl))
+		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl), nil)
 	require.NoError(t, err)
 
 	for _, testPath := range testPaths {
@@ -1353,7 +1354,7 @@ func TestTaskHTTPEndpointErrorCode400(t *testing.T) {
 
 	server, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
 		config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", vpcID,
-		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl))
+		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl), nil)
 	require.NoError(t, err)
 
 	for _, testPath := range testPaths {
@@ -1392,7 +1393,7 @@ func TestTaskHTTPEndpointErrorCode500(t *testing.T) {
 
 	server, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
 		config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", vpcID,
-		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl))
+		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl), nil)
 	require.NoError(t, err)
 
 	for _, testPath := range testPaths {
@@ -1462,7 +1463,7 @@ func TestV4TaskNotFoundError404(t *testing.T) {
 
 			server, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
 				config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", vpcID,
-				containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl))
+				containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl), nil)
 			require.NoError(t, err)
 
 			state.EXPECT().TaskARNByV3EndpointID(gomock.Any()).Return("", tc.taskFound).AnyTimes()
@@ -1518,7 +1519,7 @@ func TestV4Unexpected500Error(t *testing.T) {
 
 			server, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
 				config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", vpcID,
-				containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl))
+				containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl), nil)
 			require.NoError(t, err)
 
 			// Initial lookups succeed
@@ -1556,6 +1557,9 @@ type TMDSResponse interface {
 		v4.StatsResponse |
 		map[string]*types.StatsJSON |
 		map[string]*v4.StatsResponse |
+		credentials.IAMRoleCredentials |
+		credentials.ProcessCredentials |
+		v2.SessionResponse |
 		string
 }
 
@@ -1578,6 +1582,8 @@ type TMDSTestCase[R TMDSResponse] struct {
 		ctrl *gomock.Controller, factory *tp.MockTaskProtectionClientFactoryInterface)
 	// Function to set expectations on mock Credentials Manager
 	setCredentialsManagerExpectations func(credsManager *mock_credentials.MockManager)
+	// Function to set expectations on mock scoped Credentials Manager
+	setScopedCredentialsManagerExpectations func(scopedManager *mock_scoped.MockManager)
 	// Expected HTTP status code of the response
 	expectedStatusCode int
 	// Expected response body, all JSON compatible types are accepted
@@ -1602,6 +1608,7 @@ func testTMDSRequest[R TMDSResponse](t *testing.T, tc TMDSTestCase[R]) {
 	statsEngine := mock_stats.NewMockEngine(ctrl)
 	ecsClient := mock_ecs.NewMockECSClient(ctrl)
 	credsManager := mock_credentials.NewMockManager(ctrl)
+	scopedCredsManager := mock_scoped.NewMockManager(ctrl)
 	taskProtectionClientFactory := tp.NewMockTaskProtectionClientFactoryInterface(ctrl)
 
 	// Set expectations on mocks
@@ -1621,12 +1628,15 @@ func testTMDSRequest[R TMDSResponse](t *testing.T, tc TMDSTestCase[R]) {
 	if tc.setCredentialsManagerExpectations != nil {
 		tc.setCredentialsManagerExpectations(credsManager)
 	}
+	if tc.setScopedCredentialsManagerExpectations != nil {
+		tc.setScopedCredentialsManagerExpectations(scopedCredsManager)
+	}
 
 	// Initialize server
 	server, err := taskServerSetup(credsManager, auditLog, state, ecsClient,
 		clusterName, statsEngine,
 		config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, availabilityzone, vpcID,
-		containerInstanceArn, taskProtectionClientFactory)
+		containerInstanceArn, taskProtectionClientFactory, scopedCredsManager)
 	require.NoError(t, err)
 
 	// Create the request
@@ -2222,6 +2232,32 @@ func TestV4ContainerMetadata(t *testing.T) {
 		expectedNetwork.Network.IPv6Addresses = []string{"5:6:7:8::"}
 		expectedResponse.Networks = []v4.Network{{Network: expectedNetwork.Network}}
 
+		testTMDSRequest(t, TMDSTestCase[v4.ContainerResponse]{
+			path: v4BasePath + v3EndpointID,
+			setStateExpectations: func(state *mock_dockerstate.MockTaskEngineState) {
+				state.EXPECT().ContainerByID(containerID).Return(bridgeContainer, true).AnyTimes()
+				state.EXPECT().DockerIDByV3EndpointID(v3EndpointID).Return(containerID, true)
+				state.EXPECT().TaskByID(containerID).Return(bridgeTask, true)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: *expectedResponse,
+		})
+	})
+	t.Run("happy case bridge mode IPv6-only", func(t *testing.T) {
+		bridgeTask := standardBridgeTask()
+		bridgeContainer := standardBridgeDockerContainer()
+		bridgeContainer.Container.SetNetworkSettings(&types.NetworkSettings{
+			Networks: map[string]*network.EndpointSettings{
+				"bridge": {GlobalIPv6Address: "5:6:7:8::"},
+			},
+		})
+
+		expectedResponse := standardV4BridgeContainerResponse()
+		expectedNetwork := expectedResponse.Networks[0]
+		expectedNetwork.Network.IPv4Addresses = nil
+		expectedNetwork.Network.IPv6Addresses = []string{"5:6:7:8::"}
+		expectedResponse.Networks = []v4.Network{{Network: expectedNetwork.Network}}
+
 		testTMDSRequest(t, TMDSTestCase[v4.ContainerResponse]{
 			path: v4BasePath + v3EndpointID,
 			setStateExpectations: func(state *mock_dockerstate.MockTaskEngineState) {
@@ -3353,6 +3389,10 @@ func TestV4TaskStats(t *testing.T) {
 					state.EXPECT().ContainerMapByArn(taskARN).Return(containerMap, true),
 				)
 			},
+			setStatsEngineExpectations: func(engine *mock_stats.MockEngine) {
+				engine.EXPECT().TaskNetworkFlowStats(taskARN).Return(nil, errors.New("not available"))
+				engine.EXPECT().TaskServiceConnectStats(taskARN).Return(nil, errors.New("not available"))
+			},
 			expectedStatusCode:   http.StatusOK,
 			expectedResponseBody: map[string]*v4.StatsResponse{},
 		})
@@ -3370,6 +3410,8 @@ func TestV4TaskStats(t *testing.T) {
 				)
 			},
 			setStatsEngineExpectations: func(engine *mock_stats.MockEngine) {
+				engine.EXPECT().TaskNetworkFlowStats(taskARN).Return(nil, errors.New("not available"))
+				engine.EXPECT().TaskServiceConnectStats(taskARN).Return(nil, errors.New("not available"))
 				engine.EXPECT().ContainerDockerStats(taskARN, containerID).
 					Return(nil, nil, errors.New("some error"))
 			},
@@ -3389,6 +3431,18 @@ func TestV4TaskStats(t *testing.T) {
 			TxBytesPerSecond: 84,
 		}
 		dockerStats := types.StatsJSON{Stats: types.Stats{NumProcs: 2}}
+		networkFlowStats := stats.NetworkFlowStats{
+			Flows: []stats.NetworkFlow{
+				{Remote: stats.NetworkFlowRemoteAWSServices, RxBytes: 1024, RxPackets: 4, TxBytes: 512, TxPackets: 4},
+				{Remote: stats.NetworkFlowRemoteOther, RxBytes: 64, RxPackets: 1, TxBytes: 64, TxPackets: 1},
+			},
+		}
+		latencyP50 := 12.5
+		serviceConnectStats := stats.ServiceConnectStats{
+			Upstreams: []stats.ServiceConnectUpstreamStat{
+				{Name: "backend", Direction: "egress", RequestCount: 10, RetryCount: 1, LatencyP50: &latencyP50},
+			},
+		}
 		testTMDSRequest(t, TMDSTestCase[map[string]*v4.StatsResponse]{
 			path: path,
 			setStateExpectations: func(state *mock_dockerstate.MockTaskEngineState) {
@@ -3398,13 +3452,17 @@ func TestV4TaskStats(t *testing.T) {
 				)
 			},
 			setStatsEngineExpectations: func(engine *mock_stats.MockEngine) {
+				engine.EXPECT().TaskNetworkFlowStats(taskARN).Return(&networkFlowStats, nil)
+				engine.EXPECT().TaskServiceConnectStats(taskARN).Return(&serviceConnectStats, nil)
 				engine.EXPECT().ContainerDockerStats(taskARN, containerID).
 					Return(&dockerStats, &networkStats, nil)
 			},
 			expectedStatusCode: http.StatusOK,
 			expectedResponseBody: map[string]*v4.StatsResponse{containerID: {
-				StatsJSON:          &dockerStats,
-				Network_rate_stats: &networkStats,
+				StatsJSON:             &dockerStats,
+				Network_rate_stats:    &networkStats,
+				Network_flow_stats:    &networkFlowStats,
+				Service_connect_stats: &serviceConnectStats,
 			}},
 		})
 	})
@@ -4289,3 +4347,70 @@ func testRegisterFaultHandler(t *testing.T, tcs []networkFaultTestCase, tmdsEndp
 		})
 	}
 }
+
+func TestCredentials(t *testing.T) {
+	credsID := "credsid"
+	roleCredentials := credentials.IAMRoleCredentials{
+		CredentialsID:   credsID,
+		RoleArn:         "rolearn",
+		AccessKeyID:     "access_key_id",
+		SecretAccessKey: "secret_access_key",
+		SessionToken:    "session_token",
+		Expiration:      "expiration",
+		RoleType:        credentials.ApplicationRoleType,
+	}
+	expectedRoleCredentials := credentials.IAMRoleCredentials{
+		RoleArn:         "rolearn",
+		AccessKeyID:     "access_key_id",
+		SecretAccessKey: "secret_access_key",
+		SessionToken:    "session_token",
+		Expiration:      "expiration",
+	}
+	credentialsManagerExpectations := func(credsManager *mock_credentials.MockManager) {
+		credsManager.EXPECT().GetTaskCredentials(credsID).Return(credentials.TaskIAMRoleCredentials{
+			ARN:                taskARN,
+			IAMRoleCredentials: roleCredentials,
+		}, true)
+	}
+
+	t.Run("task credentials", func(t *testing.T) {
+		testTMDSRequest(t, TMDSTestCase[credentials.IAMRoleCredentials]{
+			path:                              "/v2/credentials/" + credsID,
+			setCredentialsManagerExpectations: credentialsManagerExpectations,
+			expectedStatusCode:                http.StatusOK,
+			expectedResponseBody:              expectedRoleCredentials,
+		})
+	})
+	t.Run("task credentials for credential_process", func(t *testing.T) {
+		testTMDSRequest(t, TMDSTestCase[credentials.ProcessCredentials]{
+			path:                              "/v2/credentials/" + credsID + "/process",
+			setCredentialsManagerExpectations: credentialsManagerExpectations,
+			expectedStatusCode:                http.StatusOK,
+			expectedResponseBody:              roleCredentials.ProcessCredentials(),
+		})
+	})
+	t.Run("session", func(t *testing.T) {
+		policy := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:GetObject","Resource":"*"}]}`
+		testTMDSRequest(t, TMDSTestCase[v2.SessionResponse]{
+			path:        "/v2/credentials/" + credsID + "/session",
+			method:      "POST",
+			requestBody: v2.SessionRequest{Policy: policy, DurationSeconds: 900},
+			setScopedCredentialsManagerExpectations: func(scopedManager *mock_scoped.MockManager) {
+				scopedManager.EXPECT().CreateSession(credsID, policy, 15*time.Minute).Return("token", nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: v2.SessionResponse{Token: "token"},
+		})
+	})
+	t.Run("container credentials", func(t *testing.T) {
+		testTMDSRequest(t, TMDSTestCase[credentials.IAMRoleCredentials]{
+			path: "/v2/credentials/container/" + v3EndpointID,
+			setScopedCredentialsManagerExpectations: func(scopedManager *mock_scoped.MockManager) {
+				scopedManager.EXPECT().GetContainerCredentials(gomock.Any(), v3EndpointID).Return(
+					credentials.TaskIAMRoleCredentials{ARN: taskARN, IAMRoleCredentials: roleCredentials}, nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: expectedRoleCredentials,
+		})
+	})
+}
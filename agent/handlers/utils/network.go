@@ -0,0 +1,27 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package utils
+
+// ContainerIPAddresses returns the addresses of a container network for the network responses.
+// The IPv4 address is always returned, even if empty, for backwards compatibility, unless the
+// container network is IPv6-only.
+func ContainerIPAddresses(ipv4Address, ipv6Address string) (ipv4Addresses []string, ipv6Addresses []string) {
+	if ipv6Address == "" {
+		return []string{ipv4Address}, nil
+	}
+	if ipv4Address != "" {
+		ipv4Addresses = []string{ipv4Address}
+	}
+	return ipv4Addresses, []string{ipv6Address}
+}
@@ -0,0 +1,46 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContainerIPAddresses(t *testing.T) {
+	testCases := []struct {
+		name          string
+		ipv4Address   string
+		ipv6Address   string
+		expectedIPv4s []string
+		expectedIPv6s []string
+	}{
+		{"IPv4-only", "172.17.0.2", "", []string{"172.17.0.2"}, nil},
+		{"dual-stack", "172.17.0.2", "2600:1f14::2", []string{"172.17.0.2"}, []string{"2600:1f14::2"}},
+		{"IPv6-only", "", "2600:1f14::2", nil, []string{"2600:1f14::2"}},
+		// The empty IPv4 address is kept for backwards compatibility, e.g. for host mode containers
+		{"no address", "", "", []string{""}, nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ipv4s, ipv6s := ContainerIPAddresses(tc.ipv4Address, tc.ipv6Address)
+			assert.Equal(t, tc.expectedIPv4s, ipv4s)
+			assert.Equal(t, tc.expectedIPv6s, ipv6s)
+		})
+	}
+}
@@ -20,3 +20,10 @@ import "github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
 type DockerStateResolver interface {
 	State() dockerstate.TaskEngineState
 }
+
+// FirelensConfigReloader is a sub-interface for the engine.TaskEngine interface
+// that reloads the firelens config of the tasks it manages
+type FirelensConfigReloader interface {
+	DockerStateResolver
+	ReloadFirelensConfig(string, map[string]map[string]string) error
+}
@@ -0,0 +1,80 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/aws/amazon-ecs-agent/agent/engine/dependencygraph"
+	handlerutils "github.com/aws/amazon-ecs-agent/agent/handlers/utils"
+	tmdsutils "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/utils"
+)
+
+const (
+	// TaskDependencyGraphPath is the introspection path that exports the dependency graph of a task
+	TaskDependencyGraphPath = "/v1/tasks/dependencygraph"
+
+	dependencyGraphTaskARNQueryField = "taskarn"
+	dependencyGraphFormatQueryField  = "format"
+	dependencyGraphFormatJSON        = "json"
+	dependencyGraphFormatDOT         = "dot"
+	requestTypeDependencyGraph       = "introspection/dependencygraph"
+)
+
+// dependencyGraphErrorResponse is returned when the dependency graph cannot be exported
+type dependencyGraphErrorResponse struct {
+	Error string `json:"Error"`
+}
+
+// TaskDependencyGraphHandler returns a handler that exports the dependency graph of the task
+// identified by the 'taskarn' query parameter, either as JSON (the default) or, with
+// 'format=dot', in the Graphviz DOT language.
+func TaskDependencyGraphHandler(taskEngine handlerutils.DockerStateResolver) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		taskARN, ok := tmdsutils.ValueFromRequest(r, dependencyGraphTaskARNQueryField)
+		if !ok {
+			tmdsutils.WriteJSONResponse(w, http.StatusBadRequest, dependencyGraphErrorResponse{
+				Error: fmt.Sprintf("missing required query parameter '%s'", dependencyGraphTaskARNQueryField),
+			}, requestTypeDependencyGraph)
+			return
+		}
+		format, ok := tmdsutils.ValueFromRequest(r, dependencyGraphFormatQueryField)
+		if !ok {
+			format = dependencyGraphFormatJSON
+		}
+		if format != dependencyGraphFormatJSON && format != dependencyGraphFormatDOT {
+			tmdsutils.WriteJSONResponse(w, http.StatusBadRequest, dependencyGraphErrorResponse{
+				Error: fmt.Sprintf("unsupported format '%s', expected '%s' or '%s'",
+					format, dependencyGraphFormatJSON, dependencyGraphFormatDOT),
+			}, requestTypeDependencyGraph)
+			return
+		}
+
+		task, found := taskEngine.State().TaskByArn(taskARN)
+		if !found {
+			tmdsutils.WriteJSONResponse(w, http.StatusNotFound, dependencyGraphErrorResponse{
+				Error: fmt.Sprintf("no task found with arn %s", taskARN),
+			}, requestTypeDependencyGraph)
+			return
+		}
+
+		graph := dependencygraph.BuildGraph(task)
+		if format == dependencyGraphFormatDOT {
+			tmdsutils.WriteStringToResponse(w, http.StatusOK, graph.DOT(), requestTypeDependencyGraph)
+			return
+		}
+		tmdsutils.WriteJSONResponse(w, http.StatusOK, graph, requestTypeDependencyGraph)
+	}
+}
@@ -0,0 +1,116 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/engine/dependencygraph"
+	mock_dockerstate "github.com/aws/amazon-ecs-agent/agent/engine/dockerstate/mocks"
+	mock_utils "github.com/aws/amazon-ecs-agent/agent/handlers/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskDependencyGraphHandler(t *testing.T) {
+	task := testTask()
+	task.Containers = append(task.Containers, testContainer())
+
+	testCases := []struct {
+		name           string
+		path           string
+		taskFound      bool
+		expectLookup   bool
+		expectedStatus int
+		expectedType   string
+	}{
+		{
+			name:           "json by default",
+			path:           TaskDependencyGraphPath + "?taskarn=" + taskARN,
+			taskFound:      true,
+			expectLookup:   true,
+			expectedStatus: http.StatusOK,
+			expectedType:   "application/json",
+		},
+		{
+			name:           "dot format",
+			path:           TaskDependencyGraphPath + "?taskarn=" + taskARN + "&format=dot",
+			taskFound:      true,
+			expectLookup:   true,
+			expectedStatus: http.StatusOK,
+			expectedType:   "text/plain",
+		},
+		{
+			name:           "missing task arn",
+			path:           TaskDependencyGraphPath,
+			expectedStatus: http.StatusBadRequest,
+			expectedType:   "application/json",
+		},
+		{
+			name:           "unsupported format",
+			path:           TaskDependencyGraphPath + "?taskarn=" + taskARN + "&format=svg",
+			expectedStatus: http.StatusBadRequest,
+			expectedType:   "application/json",
+		},
+		{
+			name:           "task not found",
+			path:           TaskDependencyGraphPath + "?taskarn=" + taskARN,
+			expectLookup:   true,
+			expectedStatus: http.StatusNotFound,
+			expectedType:   "application/json",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDockerState := mock_utils.NewMockDockerStateResolver(ctrl)
+			mockState := mock_dockerstate.NewMockTaskEngineState(ctrl)
+			if tc.expectLookup {
+				mockDockerState.EXPECT().State().Return(mockState)
+				mockState.EXPECT().TaskByArn(taskARN).Return(task, tc.taskFound)
+			}
+
+			req, err := http.NewRequest("GET", tc.path, nil)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			TaskDependencyGraphHandler(mockDockerState)(recorder, req)
+
+			assert.Equal(t, tc.expectedStatus, recorder.Code)
+			assert.Equal(t, tc.expectedType, recorder.Header().Get("Content-Type"))
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+			if tc.expectedType == "text/plain" {
+				assert.Equal(t, dependencygraph.BuildGraph(task).DOT(), recorder.Body.String())
+				return
+			}
+			var graph dependencygraph.Graph
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &graph))
+			assert.Equal(t, taskARN, graph.TaskARN)
+			require.Len(t, graph.Nodes, 1)
+			assert.Equal(t, "container/"+containerName, graph.Nodes[0].ID)
+		})
+	}
+}
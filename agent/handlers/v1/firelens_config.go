@@ -0,0 +1,101 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/amazon-ecs-agent/agent/engine"
+	handlerutils "github.com/aws/amazon-ecs-agent/agent/handlers/utils"
+	tmdsutils "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/utils"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// TaskFirelensConfigPath is the introspection path that reloads the firelens config of a task
+	// with new log options on PUT
+	TaskFirelensConfigPath = "/v1/tasks/firelensconfig"
+
+	firelensConfigTaskARNQueryField = "taskarn"
+	requestTypeFirelensConfig       = "introspection/firelensconfig"
+	maxFirelensConfigRequestBytes   = 1 << 20
+)
+
+// firelensConfigErrorResponse is returned when a firelens config reload request fails
+type firelensConfigErrorResponse struct {
+	Error string `json:"Error"`
+}
+
+// TaskFirelensConfigHandler returns a handler that, on PUT, regenerates the firelens config of the
+// task identified by the 'taskarn' query parameter with the log options of the request body, a JSON
+// object of log options by container name, and signals the firelens container of the task to reload
+// it. The firelens container must enable hot reload, and the request must carry authToken as a bearer
+// token, since log options hold the destinations of the logs of the task.
+func TaskFirelensConfigHandler(taskEngine handlerutils.FirelensConfigReloader,
+	authToken string) func(http.ResponseWriter, *http.Request) {
+	return authorizeBearerToken(authToken, writeFirelensConfigError, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			writeFirelensConfigError(w, http.StatusMethodNotAllowed,
+				fmt.Sprintf("unsupported method %s, expected PUT", r.Method))
+			return
+		}
+		taskARN, ok := tmdsutils.ValueFromRequest(r, firelensConfigTaskARNQueryField)
+		if !ok {
+			writeFirelensConfigError(w, http.StatusBadRequest,
+				fmt.Sprintf("missing required query parameter '%s'", firelensConfigTaskARNQueryField))
+			return
+		}
+		var containerToLogOptions map[string]map[string]string
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxFirelensConfigRequestBytes)).
+			Decode(&containerToLogOptions); err != nil {
+			writeFirelensConfigError(w, http.StatusBadRequest,
+				fmt.Sprintf("unable to decode the log options of the request: %v", err))
+			return
+		}
+		task, found := taskEngine.State().TaskByArn(taskARN)
+		if !found {
+			writeFirelensConfigError(w, http.StatusNotFound, fmt.Sprintf("no task found with arn %s", taskARN))
+			return
+		}
+		for containerName := range containerToLogOptions {
+			if _, ok := task.ContainerByName(containerName); !ok {
+				writeFirelensConfigError(w, http.StatusBadRequest,
+					fmt.Sprintf("no container %s found in task %s", containerName, taskARN))
+				return
+			}
+		}
+
+		if err := taskEngine.ReloadFirelensConfig(taskARN, containerToLogOptions); err != nil {
+			status := http.StatusInternalServerError
+			switch errors.Cause(err) {
+			case engine.ErrNoFirelensContainer:
+				status = http.StatusBadRequest
+			case engine.ErrFirelensHotReloadDisabled:
+				status = http.StatusForbidden
+			case engine.ErrFirelensContainerNotRunning:
+				status = http.StatusConflict
+			}
+			writeFirelensConfigError(w, status, err.Error())
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func writeFirelensConfigError(w http.ResponseWriter, status int, message string) {
+	tmdsutils.WriteJSONResponse(w, status, firelensConfigErrorResponse{Error: message}, requestTypeFirelensConfig)
+}
@@ -0,0 +1,208 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/engine"
+	mock_dockerstate "github.com/aws/amazon-ecs-agent/agent/engine/dockerstate/mocks"
+	mock_handlerutils "github.com/aws/amazon-ecs-agent/agent/handlers/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const firelensConfigReloadAuthToken = "secret"
+
+func TestTaskFirelensConfigHandler(t *testing.T) {
+	logOptions := `{"` + containerName + `": {"Name": "cloudwatch", "region": "us-east-1"}}`
+	testCases := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		authorization  string
+		authToken      string
+		task           *apitask.Task
+		reloadErr      error
+		expectReload   bool
+		expectedStatus int
+	}{
+		{
+			name:           "missing bearer token",
+			method:         http.MethodPut,
+			path:           TaskFirelensConfigPath + "?taskarn=" + taskARN,
+			body:           logOptions,
+			authToken:      firelensConfigReloadAuthToken,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "wrong bearer token",
+			method:         http.MethodPut,
+			path:           TaskFirelensConfigPath + "?taskarn=" + taskARN,
+			body:           logOptions,
+			authorization:  "Bearer wrong",
+			authToken:      firelensConfigReloadAuthToken,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "no auth token configured",
+			method:         http.MethodPut,
+			path:           TaskFirelensConfigPath + "?taskarn=" + taskARN,
+			body:           logOptions,
+			authorization:  "Bearer ",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "unsupported method",
+			method:         http.MethodGet,
+			path:           TaskFirelensConfigPath + "?taskarn=" + taskARN,
+			authorization:  "Bearer " + firelensConfigReloadAuthToken,
+			authToken:      firelensConfigReloadAuthToken,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:           "missing task arn",
+			method:         http.MethodPut,
+			path:           TaskFirelensConfigPath,
+			body:           logOptions,
+			authorization:  "Bearer " + firelensConfigReloadAuthToken,
+			authToken:      firelensConfigReloadAuthToken,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid log options",
+			method:         http.MethodPut,
+			path:           TaskFirelensConfigPath + "?taskarn=" + taskARN,
+			body:           `{"` + containerName + `": "cloudwatch"}`,
+			authorization:  "Bearer " + firelensConfigReloadAuthToken,
+			authToken:      firelensConfigReloadAuthToken,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown task",
+			method:         http.MethodPut,
+			path:           TaskFirelensConfigPath + "?taskarn=" + taskARN,
+			body:           logOptions,
+			authorization:  "Bearer " + firelensConfigReloadAuthToken,
+			authToken:      firelensConfigReloadAuthToken,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "unknown container",
+			method:         http.MethodPut,
+			path:           TaskFirelensConfigPath + "?taskarn=" + taskARN,
+			body:           `{"unknown": {"Name": "cloudwatch"}}`,
+			authorization:  "Bearer " + firelensConfigReloadAuthToken,
+			authToken:      firelensConfigReloadAuthToken,
+			task:           firelensConfigTask(),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "hot reload not enabled",
+			method:         http.MethodPut,
+			path:           TaskFirelensConfigPath + "?taskarn=" + taskARN,
+			body:           logOptions,
+			authorization:  "Bearer " + firelensConfigReloadAuthToken,
+			authToken:      firelensConfigReloadAuthToken,
+			task:           firelensConfigTask(),
+			reloadErr:      errors.Wrapf(engine.ErrFirelensHotReloadDisabled, "task %s", taskARN),
+			expectReload:   true,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "firelens container not running",
+			method:         http.MethodPut,
+			path:           TaskFirelensConfigPath + "?taskarn=" + taskARN,
+			body:           logOptions,
+			authorization:  "Bearer " + firelensConfigReloadAuthToken,
+			authToken:      firelensConfigReloadAuthToken,
+			task:           firelensConfigTask(),
+			reloadErr:      errors.Wrapf(engine.ErrFirelensContainerNotRunning, "task %s", taskARN),
+			expectReload:   true,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "reload failure",
+			method:         http.MethodPut,
+			path:           TaskFirelensConfigPath + "?taskarn=" + taskARN,
+			body:           logOptions,
+			authorization:  "Bearer " + firelensConfigReloadAuthToken,
+			authToken:      firelensConfigReloadAuthToken,
+			task:           firelensConfigTask(),
+			reloadErr:      errors.New("unable to rewrite firelens config file"),
+			expectReload:   true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "reload",
+			method:         http.MethodPut,
+			path:           TaskFirelensConfigPath + "?taskarn=" + taskARN,
+			body:           logOptions,
+			authorization:  "Bearer " + firelensConfigReloadAuthToken,
+			authToken:      firelensConfigReloadAuthToken,
+			task:           firelensConfigTask(),
+			expectReload:   true,
+			expectedStatus: http.StatusNoContent,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			state := mock_dockerstate.NewMockTaskEngineState(ctrl)
+			reloader := mock_handlerutils.NewMockFirelensConfigReloader(ctrl)
+			reloader.EXPECT().State().Return(state).AnyTimes()
+			state.EXPECT().TaskByArn(taskARN).Return(tc.task, tc.task != nil).AnyTimes()
+			if tc.expectReload {
+				reloader.EXPECT().ReloadFirelensConfig(taskARN, map[string]map[string]string{
+					containerName: {"Name": "cloudwatch", "region": "us-east-1"},
+				}).Return(tc.reloadErr)
+			}
+
+			req, err := http.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			require.NoError(t, err)
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+			recorder := httptest.NewRecorder()
+			TaskFirelensConfigHandler(reloader, tc.authToken)(recorder, req)
+
+			assert.Equal(t, tc.expectedStatus, recorder.Code)
+			if tc.expectedStatus == http.StatusUnauthorized {
+				assert.Equal(t, "Bearer", recorder.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func firelensConfigTask() *apitask.Task {
+	return &apitask.Task{
+		Arn:        taskARN,
+		Containers: []*apicontainer.Container{{Name: containerName}},
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"net/http"
+
+	"github.com/aws/amazon-ecs-agent/agent/logshipper"
+	tmdsutils "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/utils"
+)
+
+const (
+	// LogShippingStatsPath is the introspection path that exports the log shipping metrics of the tasks
+	LogShippingStatsPath = "/v1/logshipping"
+
+	requestTypeLogShippingStats = "introspection/logshipping"
+)
+
+// LogShippingStatsHandler returns a handler that exports the delivery, drop and backpressure metrics of the
+// containers whose logs are shipped by the agent, along with the buffer usage of their tasks.
+func LogShippingStatsHandler(shipper *logshipper.Shipper) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tmdsutils.WriteJSONResponse(w, http.StatusOK, shipper.Stats(), requestTypeLogShippingStats)
+	}
+}
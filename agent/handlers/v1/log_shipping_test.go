@@ -0,0 +1,46 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
+	"github.com/aws/amazon-ecs-agent/agent/logshipper"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogShippingStatsHandler(t *testing.T) {
+	shipper := logshipper.NewShipper(&config.Config{DataDir: t.TempDir()}, nil, dockerstate.NewTaskEngineState(),
+		nil, nil)
+
+	recorder := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", LogShippingStatsPath, nil)
+	require.NoError(t, err)
+	LogShippingStatsHandler(shipper)(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var stats []logshipper.TaskStats
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &stats))
+	assert.Empty(t, stats)
+}
@@ -0,0 +1,58 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/aws/amazon-ecs-agent/agent/stats"
+	tmdsutils "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/utils"
+)
+
+const (
+	// TaskNetworkFlowsPath is the introspection path that exports the traffic of a task by remote
+	TaskNetworkFlowsPath = "/v1/tasks/networkflows"
+
+	networkFlowsTaskARNQueryField = "taskarn"
+	requestTypeNetworkFlows       = "introspection/networkflows"
+)
+
+// networkFlowsErrorResponse is returned when the network flow stats cannot be exported
+type networkFlowsErrorResponse struct {
+	Error string `json:"Error"`
+}
+
+// TaskNetworkFlowsHandler returns a handler that exports the network flow stats of the awsvpc
+// task identified by the 'taskarn' query parameter.
+func TaskNetworkFlowsHandler(statsEngine stats.Engine) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		taskARN, ok := tmdsutils.ValueFromRequest(r, networkFlowsTaskARNQueryField)
+		if !ok {
+			tmdsutils.WriteJSONResponse(w, http.StatusBadRequest, networkFlowsErrorResponse{
+				Error: fmt.Sprintf("missing required query parameter '%s'", networkFlowsTaskARNQueryField),
+			}, requestTypeNetworkFlows)
+			return
+		}
+
+		flowStats, err := statsEngine.TaskNetworkFlowStats(taskARN)
+		if err != nil {
+			tmdsutils.WriteJSONResponse(w, http.StatusNotFound, networkFlowsErrorResponse{
+				Error: err.Error(),
+			}, requestTypeNetworkFlows)
+			return
+		}
+		tmdsutils.WriteJSONResponse(w, http.StatusOK, flowStats, requestTypeNetworkFlows)
+	}
+}
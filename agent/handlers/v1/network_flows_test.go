@@ -0,0 +1,97 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mock_stats "github.com/aws/amazon-ecs-agent/agent/stats/mock"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/stats"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskNetworkFlowsHandler(t *testing.T) {
+	flowStats := &stats.NetworkFlowStats{
+		Flows: []stats.NetworkFlow{
+			{Remote: "10.0.0.0/8", RxBytes: 2048, RxPackets: 8, TxBytes: 1024, TxPackets: 8},
+			{Remote: stats.NetworkFlowRemoteOther},
+		},
+	}
+
+	testCases := []struct {
+		name           string
+		path           string
+		expectLookup   bool
+		lookupErr      error
+		expectedStatus int
+	}{
+		{
+			name:           "happy case",
+			path:           TaskNetworkFlowsPath + "?taskarn=" + taskARN,
+			expectLookup:   true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing task arn",
+			path:           TaskNetworkFlowsPath,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "flow stats not available",
+			path:           TaskNetworkFlowsPath + "?taskarn=" + taskARN,
+			expectLookup:   true,
+			lookupErr:      errors.New("not available"),
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			statsEngine := mock_stats.NewMockEngine(ctrl)
+			if tc.expectLookup {
+				if tc.lookupErr != nil {
+					statsEngine.EXPECT().TaskNetworkFlowStats(taskARN).Return(nil, tc.lookupErr)
+				} else {
+					statsEngine.EXPECT().TaskNetworkFlowStats(taskARN).Return(flowStats, nil)
+				}
+			}
+
+			req, err := http.NewRequest("GET", tc.path, nil)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			TaskNetworkFlowsHandler(statsEngine)(recorder, req)
+
+			assert.Equal(t, tc.expectedStatus, recorder.Code)
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+			var actual stats.NetworkFlowStats
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &actual))
+			assert.Equal(t, flowStats.Flows, actual.Flows)
+		})
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	handlerutils "github.com/aws/amazon-ecs-agent/agent/handlers/utils"
+	"github.com/aws/amazon-ecs-agent/agent/packetcapture"
+	tmdsutils "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/utils"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// TaskPacketCapturesPath is the introspection path that starts a packet capture of a task on POST,
+	// and lists the packet captures on GET
+	TaskPacketCapturesPath = "/v1/tasks/packetcaptures"
+	// PacketCapturePath is the introspection path that describes a packet capture
+	PacketCapturePath = "/v1/packetcaptures"
+	// PacketCaptureDownloadPath is the introspection path that downloads the pcap file of a packet capture
+	PacketCaptureDownloadPath = "/v1/packetcaptures/download"
+
+	packetCaptureTaskARNQueryField  = "taskarn"
+	packetCaptureDurationQueryField = "duration"
+	packetCaptureMaxSizeQueryField  = "maxsize"
+	packetCaptureFilterQueryField   = "filter"
+	packetCaptureIDQueryField       = "id"
+	requestTypePacketCapture        = "introspection/packetcapture"
+	pcapContentType                 = "application/vnd.tcpdump.pcap"
+	bearerAuthPrefix                = "Bearer "
+)
+
+// packetCaptureErrorResponse is returned when a packet capture request fails
+type packetCaptureErrorResponse struct {
+	Error string `json:"Error"`
+}
+
+// TaskPacketCapturesHandler returns a handler that, on POST, starts a packet capture of the awsvpc
+// task identified by the 'taskarn' query parameter, bounded by the optional 'duration' (a Go
+// duration) and 'maxsize' (in bytes) query parameters, and filtered by the optional 'filter' BPF
+// expression. On GET, it lists the packet captures, of the task when 'taskarn' is set.
+func TaskPacketCapturesHandler(taskEngine handlerutils.DockerStateResolver, manager *packetcapture.Manager,
+	authToken string) func(http.ResponseWriter, *http.Request) {
+	return authorizePacketCapture(authToken, func(w http.ResponseWriter, r *http.Request) {
+		taskARN, hasTaskARN := tmdsutils.ValueFromRequest(r, packetCaptureTaskARNQueryField)
+		switch r.Method {
+		case http.MethodGet:
+			tmdsutils.WriteJSONResponse(w, http.StatusOK, manager.List(taskARN), requestTypePacketCapture)
+			return
+		case http.MethodPost:
+		default:
+			writePacketCaptureError(w, http.StatusMethodNotAllowed,
+				fmt.Sprintf("unsupported method %s, expected GET or POST", r.Method))
+			return
+		}
+
+		if !hasTaskARN {
+			writePacketCaptureError(w, http.StatusBadRequest,
+				fmt.Sprintf("missing required query parameter '%s'", packetCaptureTaskARNQueryField))
+			return
+		}
+		request, err := packetCaptureRequest(r)
+		if err != nil {
+			writePacketCaptureError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		task, found := taskEngine.State().TaskByArn(taskARN)
+		if !found {
+			writePacketCaptureError(w, http.StatusNotFound, fmt.Sprintf("no task found with arn %s", taskARN))
+			return
+		}
+
+		capture, err := manager.Start(task, request)
+		if err != nil {
+			status := http.StatusInternalServerError
+			switch errors.Cause(err) {
+			case packetcapture.ErrNoTaskNetNS:
+				status = http.StatusBadRequest
+			case packetcapture.ErrCaptureInProgress:
+				status = http.StatusConflict
+			case packetcapture.ErrTooManyCaptures:
+				status = http.StatusTooManyRequests
+			}
+			writePacketCaptureError(w, status, err.Error())
+			return
+		}
+		tmdsutils.WriteJSONResponse(w, http.StatusAccepted, capture, requestTypePacketCapture)
+	})
+}
+
+// PacketCaptureHandler returns a handler that describes the packet capture identified by the
+// 'id' query parameter.
+func PacketCaptureHandler(manager *packetcapture.Manager, authToken string) func(http.ResponseWriter, *http.Request) {
+	return authorizePacketCapture(authToken, func(w http.ResponseWriter, r *http.Request) {
+		id, ok := tmdsutils.ValueFromRequest(r, packetCaptureIDQueryField)
+		if !ok {
+			writePacketCaptureError(w, http.StatusBadRequest,
+				fmt.Sprintf("missing required query parameter '%s'", packetCaptureIDQueryField))
+			return
+		}
+		capture, err := manager.Get(id)
+		if err != nil {
+			writePacketCaptureError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		tmdsutils.WriteJSONResponse(w, http.StatusOK, capture, requestTypePacketCapture)
+	})
+}
+
+// PacketCaptureDownloadHandler returns a handler that downloads the pcap file of the packet
+// capture identified by the 'id' query parameter, once the capture is no longer running.
+func PacketCaptureDownloadHandler(manager *packetcapture.Manager,
+	authToken string) func(http.ResponseWriter, *http.Request) {
+	return authorizePacketCapture(authToken, func(w http.ResponseWriter, r *http.Request) {
+		id, ok := tmdsutils.ValueFromRequest(r, packetCaptureIDQueryField)
+		if !ok {
+			writePacketCaptureError(w, http.StatusBadRequest,
+				fmt.Sprintf("missing required query parameter '%s'", packetCaptureIDQueryField))
+			return
+		}
+		file, capture, err := manager.Open(id)
+		if err != nil {
+			status := http.StatusInternalServerError
+			switch errors.Cause(err) {
+			case packetcapture.ErrCaptureNotFound:
+				status = http.StatusNotFound
+			case packetcapture.ErrCaptureInProgress:
+				status = http.StatusConflict
+			}
+			writePacketCaptureError(w, status, err.Error())
+			return
+		}
+		defer file.Close()
+
+		name := capture.ID + ".pcap"
+		w.Header().Set("Content-Type", pcapContentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+		var modTime time.Time
+		if capture.StoppedAt != nil {
+			modTime = *capture.StoppedAt
+		}
+		http.ServeContent(w, r, name, modTime, file)
+	})
+}
+
+// packetCaptureRequest parses the bounds and the filter of a packet capture from the query.
+func packetCaptureRequest(r *http.Request) (packetcapture.Request, error) {
+	var request packetcapture.Request
+	if value, ok := tmdsutils.ValueFromRequest(r, packetCaptureDurationQueryField); ok {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return request, errors.Wrapf(err, "invalid value for query parameter '%s'", packetCaptureDurationQueryField)
+		}
+		request.Duration = duration
+	}
+	if value, ok := tmdsutils.ValueFromRequest(r, packetCaptureMaxSizeQueryField); ok {
+		maxSize, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return request, errors.Wrapf(err, "invalid value for query parameter '%s'", packetCaptureMaxSizeQueryField)
+		}
+		request.MaxSizeBytes = maxSize
+	}
+	request.Filter, _ = tmdsutils.ValueFromRequest(r, packetCaptureFilterQueryField)
+	return request, request.Validate()
+}
+
+// authorizePacketCapture only lets the requests that carry the packet capture auth token as a
+// bearer token through to the handler. Packet captures hold the traffic of tasks, so they are
+// not served to everyone that can reach the introspection endpoint.
+func authorizePacketCapture(authToken string,
+	handler func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
+	return authorizeBearerToken(authToken, writePacketCaptureError, handler)
+}
+
+// authorizeBearerToken only lets the requests that carry authToken as a bearer token through to
+// the handler, and writes the unauthorized error of the other requests with writeError.
+func authorizeBearerToken(authToken string, writeError func(http.ResponseWriter, int, string),
+	handler func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		token := strings.TrimPrefix(header, bearerAuthPrefix)
+		if authToken == "" || token == header ||
+			subtle.ConstantTimeCompare([]byte(token), []byte(authToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+			return
+		}
+		handler(w, r)
+	}
+}
+
+func writePacketCaptureError(w http.ResponseWriter, status int, message string) {
+	tmdsutils.WriteJSONResponse(w, status, packetCaptureErrorResponse{Error: message}, requestTypePacketCapture)
+}
@@ -0,0 +1,173 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	mock_dockerstate "github.com/aws/amazon-ecs-agent/agent/engine/dockerstate/mocks"
+	mock_handlerutils "github.com/aws/amazon-ecs-agent/agent/handlers/mocks"
+	"github.com/aws/amazon-ecs-agent/agent/packetcapture"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const packetCaptureAuthToken = "secret"
+
+func TestTaskPacketCapturesHandler(t *testing.T) {
+	testCases := []struct {
+		name           string
+		method         string
+		path           string
+		authorization  string
+		task           *apitask.Task
+		expectedStatus int
+	}{
+		{
+			name:           "missing bearer token",
+			method:         http.MethodPost,
+			path:           TaskPacketCapturesPath + "?taskarn=" + taskARN,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "wrong bearer token",
+			method:         http.MethodPost,
+			path:           TaskPacketCapturesPath + "?taskarn=" + taskARN,
+			authorization:  "Bearer wrong",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "list captures",
+			method:         http.MethodGet,
+			path:           TaskPacketCapturesPath,
+			authorization:  "Bearer " + packetCaptureAuthToken,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing task arn",
+			method:         http.MethodPost,
+			path:           TaskPacketCapturesPath,
+			authorization:  "Bearer " + packetCaptureAuthToken,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "duration over the limit",
+			method:         http.MethodPost,
+			path:           TaskPacketCapturesPath + "?taskarn=" + taskARN + "&duration=1h",
+			authorization:  "Bearer " + packetCaptureAuthToken,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid maximum size",
+			method:         http.MethodPost,
+			path:           TaskPacketCapturesPath + "?taskarn=" + taskARN + "&maxsize=large",
+			authorization:  "Bearer " + packetCaptureAuthToken,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown task",
+			method:         http.MethodPost,
+			path:           TaskPacketCapturesPath + "?taskarn=" + taskARN,
+			authorization:  "Bearer " + packetCaptureAuthToken,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "task without a network namespace",
+			method:         http.MethodPost,
+			path:           TaskPacketCapturesPath + "?taskarn=" + taskARN + "&filter=port+80",
+			authorization:  "Bearer " + packetCaptureAuthToken,
+			task:           &apitask.Task{Arn: taskARN, NetworkMode: apitask.BridgeNetworkMode},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unsupported method",
+			method:         http.MethodDelete,
+			path:           TaskPacketCapturesPath,
+			authorization:  "Bearer " + packetCaptureAuthToken,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			state := mock_dockerstate.NewMockTaskEngineState(ctrl)
+			stateResolver := mock_handlerutils.NewMockDockerStateResolver(ctrl)
+			stateResolver.EXPECT().State().Return(state).AnyTimes()
+			state.EXPECT().TaskByArn(taskARN).Return(tc.task, tc.task != nil).AnyTimes()
+			manager := packetcapture.NewManager(t.TempDir(), time.Hour, nil)
+
+			req, err := http.NewRequest(tc.method, tc.path, nil)
+			require.NoError(t, err)
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+			recorder := httptest.NewRecorder()
+			TaskPacketCapturesHandler(stateResolver, manager, packetCaptureAuthToken)(recorder, req)
+
+			assert.Equal(t, tc.expectedStatus, recorder.Code)
+			if tc.expectedStatus == http.StatusOK {
+				assert.JSONEq(t, "[]", recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestPacketCaptureHandlers(t *testing.T) {
+	manager := packetcapture.NewManager(t.TempDir(), time.Hour, nil)
+
+	for _, path := range []string{PacketCapturePath, PacketCaptureDownloadPath} {
+		handler := PacketCaptureHandler(manager, packetCaptureAuthToken)
+		if path == PacketCaptureDownloadPath {
+			handler = PacketCaptureDownloadHandler(manager, packetCaptureAuthToken)
+		}
+
+		req, err := http.NewRequest(http.MethodGet, path+"?id=unknown", nil)
+		require.NoError(t, err)
+		recorder := httptest.NewRecorder()
+		handler(recorder, req)
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code, path)
+
+		req.Header.Set("Authorization", "Bearer "+packetCaptureAuthToken)
+		recorder = httptest.NewRecorder()
+		handler(recorder, req)
+		assert.Equal(t, http.StatusNotFound, recorder.Code, path)
+
+		req, err = http.NewRequest(http.MethodGet, path, nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+packetCaptureAuthToken)
+		recorder = httptest.NewRecorder()
+		handler(recorder, req)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, path)
+	}
+
+	// Packet capture is never served without an auth token configured.
+	req, err := http.NewRequest(http.MethodGet, PacketCapturePath+"?id=unknown", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer ")
+	recorder := httptest.NewRecorder()
+	PacketCaptureHandler(manager, "")(recorder, req)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
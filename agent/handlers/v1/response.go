@@ -49,9 +49,31 @@ func NewTaskResponse(task *apitask.Task, containerMap map[string]*apicontainer.D
 		Family:        task.Family,
 		Version:       task.Version,
 		Containers:    containers,
+		VolumeExports: NewVolumeExportsResponse(task),
 	}
 }
 
+// NewVolumeExportsResponse converts the export statuses of the task volumes to their response format.
+func NewVolumeExportsResponse(task *apitask.Task) []tmdsresponse.VolumeExportResponse {
+	exports := task.GetVolumeExports()
+	if len(exports) == 0 {
+		return nil
+	}
+	resp := make([]tmdsresponse.VolumeExportResponse, 0, len(exports))
+	for _, export := range exports {
+		resp = append(resp, tmdsresponse.VolumeExportResponse{
+			VolumeName:  export.VolumeName,
+			Destination: export.Destination,
+			Status:      export.Status,
+			SizeBytes:   export.SizeBytes,
+			Reason:      export.Reason,
+			StartedAt:   export.StartedAt,
+			CompletedAt: export.CompletedAt,
+		})
+	}
+	return resp
+}
+
 // NewContainerResponse converts the given container to a v1 server ContainerResponse.
 func NewContainerResponse(dockerContainer *apicontainer.DockerContainer, eni *ni.NetworkInterface) v1.ContainerResponse {
 	container := dockerContainer.Container
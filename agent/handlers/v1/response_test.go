@@ -18,10 +18,13 @@ package v1
 
 import (
 	"testing"
+	"time"
 
 	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/api/container/restart"
 	ni "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+	tmdsresponse "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/response"
 
 	"github.com/docker/docker/api/types"
 	"github.com/stretchr/testify/assert"
@@ -35,6 +38,26 @@ func TestTaskResponse(t *testing.T) {
 	assert.Equal(t, expectedTaskResponse(), *taskResponse)
 }
 
+func TestTaskResponseWithVolumeExports(t *testing.T) {
+	task := testTask()
+	startedAt := time.Now()
+	task.SetVolumeExport(apitask.VolumeExportStatus{
+		VolumeName:  volName,
+		Destination: "s3://bucket/prefix/taskid/volume.tar.zst",
+		Status:      apitask.VolumeExportInProgress,
+		StartedAt:   &startedAt,
+	})
+	taskResponse := NewTaskResponse(task, testContainerMap(testContainer()))
+	assert.Equal(t, []tmdsresponse.VolumeExportResponse{
+		{
+			VolumeName:  volName,
+			Destination: "s3://bucket/prefix/taskid/volume.tar.zst",
+			Status:      apitask.VolumeExportInProgress,
+			StartedAt:   &startedAt,
+		},
+	}, taskResponse.VolumeExports)
+}
+
 func TestContainerResponse(t *testing.T) {
 	container := testContainer()
 
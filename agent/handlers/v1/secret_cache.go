@@ -0,0 +1,36 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"net/http"
+
+	"github.com/aws/amazon-ecs-agent/agent/secretcache"
+	tmdsutils "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/utils"
+)
+
+const (
+	// SecretCacheStatsPath is the introspection path that exports the counters of the host level secret cache
+	SecretCacheStatsPath = "/v1/secrets/cache"
+
+	requestTypeSecretCacheStats = "introspection/secretcache"
+)
+
+// SecretCacheStatsHandler returns a handler that exports the hit, miss and call counters of the
+// secret cache. Secret values are never exported.
+func SecretCacheStatsHandler(cache *secretcache.Cache) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tmdsutils.WriteJSONResponse(w, http.StatusOK, cache.Stats(), requestTypeSecretCacheStats)
+	}
+}
@@ -0,0 +1,46 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/secretcache"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/ipcompatibility"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretCacheStatsHandler(t *testing.T) {
+	cache, err := secretcache.NewCache(time.Minute, nil, nil, ipcompatibility.NewIPv4OnlyCompatibility())
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", SecretCacheStatsPath, nil)
+	require.NoError(t, err)
+	SecretCacheStatsHandler(cache)(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var stats secretcache.Stats
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &stats))
+	assert.Equal(t, secretcache.Stats{}, stats)
+}
@@ -0,0 +1,124 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"fmt"
+	"net/http"
+
+	handlerutils "github.com/aws/amazon-ecs-agent/agent/handlers/utils"
+	"github.com/aws/amazon-ecs-agent/agent/stats"
+	ecsstats "github.com/aws/amazon-ecs-agent/ecs-agent/stats"
+	tmdsutils "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/utils"
+)
+
+const (
+	// TaskServiceConnectPath is the introspection path that exports the Service Connect listeners
+	// of a task and their health
+	TaskServiceConnectPath = "/v1/tasks/serviceconnect"
+
+	serviceConnectTaskARNQueryField = "taskarn"
+	requestTypeServiceConnect       = "introspection/serviceconnect"
+
+	serviceConnectDirectionIngress = "ingress"
+	serviceConnectDirectionEgress  = "egress"
+)
+
+// ServiceConnectResponse is the introspection view of the Service Connect proxy of a task
+type ServiceConnectResponse struct {
+	TaskARN            string                        `json:"TaskARN"`
+	ContainerName      string                        `json:"ContainerName"`
+	KnownStatus        string                        `json:"KnownStatus"`
+	HealthStatus       string                        `json:"HealthStatus,omitempty"`
+	ConnectionDraining bool                          `json:"ConnectionDraining"`
+	Listeners          []ServiceConnectListener      `json:"Listeners"`
+	Stats              *ecsstats.ServiceConnectStats `json:"Stats,omitempty"`
+}
+
+// ServiceConnectListener is a listener of the Service Connect proxy of a task
+type ServiceConnectListener struct {
+	Name          string  `json:"Name"`
+	Direction     string  `json:"Direction"`
+	Port          uint16  `json:"Port"`
+	InterceptPort *uint16 `json:"InterceptPort,omitempty"`
+	HostPort      *uint16 `json:"HostPort,omitempty"`
+}
+
+// serviceConnectErrorResponse is returned when the Service Connect view cannot be exported
+type serviceConnectErrorResponse struct {
+	Error string `json:"Error"`
+}
+
+// TaskServiceConnectHandler returns a handler that exports the Service Connect listeners of the
+// task identified by the 'taskarn' query parameter, along with the health of its proxy and the
+// stats last retrieved from the AppNet agent, if any.
+func TaskServiceConnectHandler(taskEngine handlerutils.DockerStateResolver,
+	statsEngine stats.Engine) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		taskARN, ok := tmdsutils.ValueFromRequest(r, serviceConnectTaskARNQueryField)
+		if !ok {
+			tmdsutils.WriteJSONResponse(w, http.StatusBadRequest, serviceConnectErrorResponse{
+				Error: fmt.Sprintf("missing required query parameter '%s'", serviceConnectTaskARNQueryField),
+			}, requestTypeServiceConnect)
+			return
+		}
+
+		task, found := taskEngine.State().TaskByArn(taskARN)
+		if !found {
+			tmdsutils.WriteJSONResponse(w, http.StatusNotFound, serviceConnectErrorResponse{
+				Error: fmt.Sprintf("no task found with arn %s", taskARN),
+			}, requestTypeServiceConnect)
+			return
+		}
+		scContainer := task.GetServiceConnectContainer()
+		if scContainer == nil {
+			tmdsutils.WriteJSONResponse(w, http.StatusNotFound, serviceConnectErrorResponse{
+				Error: fmt.Sprintf("task %s is not a Service Connect task", taskARN),
+			}, requestTypeServiceConnect)
+			return
+		}
+
+		resp := ServiceConnectResponse{
+			TaskARN:            taskARN,
+			ContainerName:      scContainer.Name,
+			KnownStatus:        scContainer.GetKnownStatus().String(),
+			ConnectionDraining: task.IsServiceConnectConnectionDraining(),
+			Listeners:          []ServiceConnectListener{},
+		}
+		if scContainer.HealthStatusShouldBeReported() {
+			resp.HealthStatus = scContainer.GetHealthStatus().Status.String()
+		}
+		for _, ingress := range task.ServiceConnectConfig.IngressConfig {
+			resp.Listeners = append(resp.Listeners, ServiceConnectListener{
+				Name:          ingress.ListenerName,
+				Direction:     serviceConnectDirectionIngress,
+				Port:          ingress.ListenerPort,
+				InterceptPort: ingress.InterceptPort,
+				HostPort:      ingress.HostPort,
+			})
+		}
+		if egress := task.ServiceConnectConfig.EgressConfig; egress != nil {
+			resp.Listeners = append(resp.Listeners, ServiceConnectListener{
+				Name:      egress.ListenerName,
+				Direction: serviceConnectDirectionEgress,
+				Port:      egress.ListenerPort,
+			})
+		}
+		// The stats are only available once they have been retrieved from the AppNet agent.
+		if upstreamStats, err := statsEngine.TaskServiceConnectStats(taskARN); err == nil {
+			resp.Stats = upstreamStats
+		}
+		tmdsutils.WriteJSONResponse(w, http.StatusOK, resp, requestTypeServiceConnect)
+	}
+}
@@ -0,0 +1,152 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/api/serviceconnect"
+	mock_dockerstate "github.com/aws/amazon-ecs-agent/agent/engine/dockerstate/mocks"
+	mock_utils "github.com/aws/amazon-ecs-agent/agent/handlers/mocks"
+	mock_stats "github.com/aws/amazon-ecs-agent/agent/stats/mock"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/stats"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskServiceConnectHandler(t *testing.T) {
+	interceptPort := uint16(80)
+	scTask := testTask()
+	scTask.Containers = append(scTask.Containers, testContainer())
+	scTask.ServiceConnectConfig = &serviceconnect.Config{
+		ContainerName: containerName,
+		IngressConfig: []serviceconnect.IngressConfigEntry{
+			{ListenerName: "web", ListenerPort: 15000, InterceptPort: &interceptPort},
+		},
+		EgressConfig: &serviceconnect.EgressConfig{ListenerName: "outbound", ListenerPort: 15001},
+	}
+	nonSCTask := testTask()
+	upstreamStats := &stats.ServiceConnectStats{
+		Upstreams: []stats.ServiceConnectUpstreamStat{
+			{Name: "backend", Direction: "egress", RequestCount: 10, CircuitBreakerOpen: true},
+		},
+	}
+
+	testCases := []struct {
+		name           string
+		path           string
+		expectLookup   bool
+		taskFound      bool
+		nonSCTask      bool
+		expectStats    bool
+		statsErr       error
+		expectedStatus int
+	}{
+		{
+			name:           "happy case",
+			path:           TaskServiceConnectPath + "?taskarn=" + taskARN,
+			expectLookup:   true,
+			taskFound:      true,
+			expectStats:    true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "stats not retrieved yet",
+			path:           TaskServiceConnectPath + "?taskarn=" + taskARN,
+			expectLookup:   true,
+			taskFound:      true,
+			expectStats:    true,
+			statsErr:       errors.New("not retrieved yet"),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing task arn",
+			path:           TaskServiceConnectPath,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "task not found",
+			path:           TaskServiceConnectPath + "?taskarn=" + taskARN,
+			expectLookup:   true,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "not a service connect task",
+			path:           TaskServiceConnectPath + "?taskarn=" + taskARN,
+			expectLookup:   true,
+			taskFound:      true,
+			nonSCTask:      true,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDockerState := mock_utils.NewMockDockerStateResolver(ctrl)
+			mockState := mock_dockerstate.NewMockTaskEngineState(ctrl)
+			statsEngine := mock_stats.NewMockEngine(ctrl)
+			if tc.expectLookup {
+				task := scTask
+				if tc.nonSCTask {
+					task = nonSCTask
+				}
+				mockDockerState.EXPECT().State().Return(mockState)
+				mockState.EXPECT().TaskByArn(taskARN).Return(task, tc.taskFound)
+			}
+			if tc.expectStats {
+				if tc.statsErr != nil {
+					statsEngine.EXPECT().TaskServiceConnectStats(taskARN).Return(nil, tc.statsErr)
+				} else {
+					statsEngine.EXPECT().TaskServiceConnectStats(taskARN).Return(upstreamStats, nil)
+				}
+			}
+
+			req, err := http.NewRequest("GET", tc.path, nil)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			TaskServiceConnectHandler(mockDockerState, statsEngine)(recorder, req)
+
+			assert.Equal(t, tc.expectedStatus, recorder.Code)
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+			var actual ServiceConnectResponse
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &actual))
+			assert.Equal(t, taskARN, actual.TaskARN)
+			assert.Equal(t, containerName, actual.ContainerName)
+			assert.Equal(t, []ServiceConnectListener{
+				{Name: "web", Direction: "ingress", Port: 15000, InterceptPort: &interceptPort},
+				{Name: "outbound", Direction: "egress", Port: 15001},
+			}, actual.Listeners)
+			if tc.statsErr != nil {
+				assert.Nil(t, actual.Stats)
+				return
+			}
+			require.NotNil(t, actual.Stats)
+			assert.Equal(t, upstreamStats.Upstreams, actual.Stats.Upstreams)
+		})
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"net/http"
 
 	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
+	handlerutils "github.com/aws/amazon-ecs-agent/agent/handlers/utils"
 	v2 "github.com/aws/amazon-ecs-agent/agent/handlers/v2"
 	tmdsresponse "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/response"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/utils"
@@ -95,6 +96,7 @@ func GetContainerNetworkMetadata(containerID string, state dockerstate.TaskEngin
 	// We get the NetworkMode (Network interface name) from the HostConfig because this
 	// this is the network with which the container is created
 	ipv4AddressFromSettings := settings.IPAddress
+	ipv6AddressFromSettings := settings.GlobalIPv6Address
 	networkModeFromHostConfig := dockerContainer.Container.GetNetworkMode()
 
 	// Extensive Network information is not available for Docker API versions 1.17-1.20
@@ -103,13 +105,16 @@ func GetContainerNetworkMetadata(containerID string, state dockerstate.TaskEngin
 	if len(settings.Networks) > 0 {
 		for modeFromSettings, containerNetwork := range settings.Networks {
 			networkMode := modeFromSettings
-			ipv4Addresses := []string{containerNetwork.IPAddress}
-			network := tmdsresponse.Network{NetworkMode: networkMode, IPv4Addresses: ipv4Addresses}
+			ipv4Addresses, ipv6Addresses := handlerutils.ContainerIPAddresses(containerNetwork.IPAddress,
+				containerNetwork.GlobalIPv6Address)
+			network := tmdsresponse.Network{NetworkMode: networkMode, IPv4Addresses: ipv4Addresses,
+				IPv6Addresses: ipv6Addresses}
 			networks = append(networks, network)
 		}
 	} else {
-		ipv4Addresses := []string{ipv4AddressFromSettings}
-		network := tmdsresponse.Network{NetworkMode: networkModeFromHostConfig, IPv4Addresses: ipv4Addresses}
+		ipv4Addresses, ipv6Addresses := handlerutils.ContainerIPAddresses(ipv4AddressFromSettings, ipv6AddressFromSettings)
+		network := tmdsresponse.Network{NetworkMode: networkModeFromHostConfig, IPv4Addresses: ipv4Addresses,
+			IPv6Addresses: ipv6Addresses}
 		networks = append(networks, network)
 	}
 	return networks, nil
@@ -15,6 +15,7 @@ package v4
 
 import (
 	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
+	handlerutils "github.com/aws/amazon-ecs-agent/agent/handlers/utils"
 	tmdsresponse "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/response"
 	tmdsv4 "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/v4/state"
 
@@ -46,11 +47,8 @@ func GetContainerNetworkMetadata(containerID string, state dockerstate.TaskEngin
 	if len(settings.Networks) > 0 {
 		for modeFromSettings, containerNetwork := range settings.Networks {
 			networkMode := modeFromSettings
-			ipv4Addresses := []string{containerNetwork.IPAddress}
-			var ipv6Addresses []string
-			if containerNetwork.GlobalIPv6Address != "" {
-				ipv6Addresses = []string{containerNetwork.GlobalIPv6Address}
-			}
+			ipv4Addresses, ipv6Addresses := handlerutils.ContainerIPAddresses(containerNetwork.IPAddress,
+				containerNetwork.GlobalIPv6Address)
 			network := tmdsv4.Network{
 				Network: tmdsresponse.Network{
 					NetworkMode:   networkMode,
@@ -61,11 +59,7 @@ func GetContainerNetworkMetadata(containerID string, state dockerstate.TaskEngin
 			networks = append(networks, network)
 		}
 	} else {
-		ipv4Addresses := []string{ipv4AddressFromSettings}
-		var ipv6Addresses []string
-		if ipv6AddressFromSettings != "" {
-			ipv6Addresses = []string{ipv6AddressFromSettings}
-		}
+		ipv4Addresses, ipv6Addresses := handlerutils.ContainerIPAddresses(ipv4AddressFromSettings, ipv6AddressFromSettings)
 		network := tmdsv4.Network{
 			Network: tmdsresponse.Network{
 				NetworkMode:   networkModeFromHostConfig,
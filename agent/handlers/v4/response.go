@@ -146,6 +146,10 @@ func augmentContainerResponse(
 	if dockerContainer.Container.RestartPolicyEnabled() {
 		restartCount := dockerContainer.Container.RestartTracker.GetRestartCount()
 		v4Response.RestartCount = &restartCount
+		v4Response.LastRestartReason = dockerContainer.Container.RestartTracker.GetLastRestartReason()
+	}
+	if dockerContainer.Container.HasReadinessProbe() {
+		v4Response.Readiness = newReadinessStatus(dockerContainer.Container.GetReadinessStatus())
 	}
 	return v4Response
 }
@@ -194,3 +198,45 @@ func NewPulledContainerResponse(
 		ContainerResponse: &resp,
 	}
 }
+
+// newReadinessStatus converts the readiness probe status of a container to the v4 response format.
+func newReadinessStatus(status apicontainer.ReadinessStatus) *tmdsv4.ReadinessStatus {
+	readiness := &tmdsv4.ReadinessStatus{
+		Status:       tmdsv4.ReadinessStatusNotReady,
+		Since:        status.Since,
+		LastProbedAt: status.LastProbedAt,
+		Attempts:     status.Attempts,
+		Output:       status.Output,
+	}
+	if status.Ready {
+		readiness.Status = tmdsv4.ReadinessStatusReady
+	}
+	return readiness
+}
+
+// newEphemeralStorageMetrics sums the size limits and the utilization of the ephemeral volumes of
+// the task, if it has any.
+func newEphemeralStorageMetrics(task *apitask.Task) *tmdsv4.EphemeralStorageMetrics {
+	volumes := task.GetEphemeralVolumeResources()
+	if len(volumes) == 0 {
+		return nil
+	}
+	metrics := &tmdsv4.EphemeralStorageMetrics{}
+	for _, volume := range volumes {
+		metrics.ReservedMiBs += volume.GetSizeMiB()
+		if !volume.KnownCreated() {
+			continue
+		}
+		utilized, err := volume.GetUtilizedMiB()
+		if err != nil {
+			logger.Warn("Unable to get the utilization of the ephemeral volume", logger.Fields{
+				field.TaskARN: task.Arn,
+				field.Volume:  volume.GetName(),
+				field.Error:   err,
+			})
+			continue
+		}
+		metrics.UtilizedMiBs += utilized
+	}
+	return metrics
+}
@@ -17,17 +17,24 @@
 package v4
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"os"
 	"testing"
 	"time"
 
 	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
 	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
 	mock_dockerstate "github.com/aws/amazon-ecs-agent/agent/engine/dockerstate/mocks"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource/ephemeralvolume"
+	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
 	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
 	mock_ecs "github.com/aws/amazon-ecs-agent/ecs-agent/api/ecs/mocks"
 	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
 	ni "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+	tmdsv4 "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/v4/state"
 
 	"github.com/docker/docker/api/types"
 	"github.com/golang/mock/gomock"
@@ -159,3 +166,49 @@ func TestNewTaskContainerResponses(t *testing.T) {
 	assert.Equal(t, "192.168.0.0/24", containerResponse.Networks[0].IPV4SubnetCIDRBlock)
 	assert.Equal(t, subnetGatewayIPV4Address, containerResponse.Networks[0].SubnetGatewayIPV4Address)
 }
+
+func TestNewReadinessStatus(t *testing.T) {
+	container := &apicontainer.Container{
+		ReadinessProbe: &apicontainer.ReadinessProbe{
+			TCPSocket: &apicontainer.TCPSocketAction{Port: 80},
+		},
+	}
+	container.RecordReadinessProbeResult(errors.New("connection refused"))
+	readiness := newReadinessStatus(container.GetReadinessStatus())
+	assert.Equal(t, tmdsv4.ReadinessStatusNotReady, readiness.Status)
+	assert.Equal(t, "connection refused", readiness.Output)
+	assert.Equal(t, 1, readiness.Attempts)
+	assert.Nil(t, readiness.Since)
+
+	container.RecordReadinessProbeResult(nil)
+	readiness = newReadinessStatus(container.GetReadinessStatus())
+	assert.Equal(t, tmdsv4.ReadinessStatusReady, readiness.Status)
+	assert.NotNil(t, readiness.Since)
+	assert.Equal(t, 2, readiness.Attempts)
+}
+
+func TestNewEphemeralStorageMetrics(t *testing.T) {
+	task := &apitask.Task{
+		Arn:                taskARN,
+		ResourcesMapUnsafe: make(map[string][]taskresource.TaskResource),
+	}
+	assert.Nil(t, newEphemeralStorageMetrics(task))
+
+	xfsMountPoint := t.TempDir()
+	for name, sizeMiB := range map[string]int64{"created": 512, "pending": 1024} {
+		volume, err := ephemeralvolume.NewEphemeralVolumeResource(context.TODO(), taskARN, "task-id", name,
+			&ephemeralvolume.EphemeralVolumeConfig{SizeMiB: sizeMiB}, xfsMountPoint, nil)
+		require.NoError(t, err)
+		if name == "created" {
+			// Stands in for the XFS project directory of the volume.
+			require.NoError(t, os.MkdirAll(volume.VolumeConfig.Source(), 0755))
+			volume.SetKnownStatus(resourcestatus.ResourceStatus(ephemeralvolume.EphemeralVolumeCreated))
+		}
+		task.AddResource(ephemeralvolume.ResourceName, volume)
+	}
+
+	metrics := newEphemeralStorageMetrics(task)
+	require.NotNil(t, metrics)
+	assert.Equal(t, int64(1536), metrics.ReservedMiBs)
+	assert.GreaterOrEqual(t, metrics.UtilizedMiBs, int64(0))
+}
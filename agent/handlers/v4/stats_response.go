@@ -34,6 +34,22 @@ func NewV4TaskStatsResponse(taskARN string,
 			taskARN)
 	}
 
+	// Network flow stats are only available for awsvpc tasks with network flow accounting
+	// enabled, and are the same for all the containers of the task.
+	networkFlowStats, err := statsEngine.TaskNetworkFlowStats(taskARN)
+	if err != nil {
+		seelog.Debugf("V4 task stats response: Network flow stats not available for task '%s': %v",
+			taskARN, err)
+	}
+
+	// Service Connect stats are only available for Service Connect tasks once they have been
+	// retrieved from the AppNet agent, and are the same for all the containers of the task.
+	serviceConnectStats, err := statsEngine.TaskServiceConnectStats(taskARN)
+	if err != nil {
+		seelog.Debugf("V4 task stats response: Service Connect stats not available for task '%s': %v",
+			taskARN, err)
+	}
+
 	resp := make(map[string]*response.StatsResponse)
 	for _, dockerContainer := range containerMap {
 		containerID := dockerContainer.DockerID
@@ -46,8 +62,10 @@ func NewV4TaskStatsResponse(taskARN string,
 		}
 
 		statsResponse := response.StatsResponse{
-			StatsJSON:          dockerStats,
-			Network_rate_stats: network_rate_stats,
+			StatsJSON:             dockerStats,
+			Network_rate_stats:    network_rate_stats,
+			Network_flow_stats:    networkFlowStats,
+			Service_connect_stats: serviceConnectStats,
 		}
 
 		resp[containerID] = &statsResponse
@@ -17,6 +17,7 @@ import (
 	"fmt"
 
 	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
+	v1 "github.com/aws/amazon-ecs-agent/agent/handlers/v1"
 	"github.com/aws/amazon-ecs-agent/agent/stats"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/api/ecs"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
@@ -156,6 +157,8 @@ func (s *TMDSAgentState) getTaskMetadata(v3EndpointID string, includeTags bool,
 			NewPulledContainerResponse(dockerContainer, task.GetPrimaryENI()))
 	}
 
+	taskResponse.EphemeralStorageMetrics = newEphemeralStorageMetrics(task)
+	taskResponse.VolumeExports = v1.NewVolumeExportsResponse(task)
 	taskResponse.FaultInjectionEnabled = task.IsFaultInjectionEnabled()
 	if includeTaskNetworkConfig {
 		var taskNetworkConfig *tmdsv4.TaskNetworkConfig
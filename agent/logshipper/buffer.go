@@ -0,0 +1,85 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package logshipper
+
+import (
+	"context"
+	"sync"
+)
+
+// taskBuffer bounds the size of the log records of a task that are read from its containers but not delivered yet.
+// It is shared by the containers of the task, so that a task cannot make the agent hold more than the limit in
+// memory however many containers it has.
+type taskBuffer struct {
+	lock  sync.Mutex
+	limit int64
+	used  int64
+	// released is closed, and replaced, whenever space is released.
+	released chan struct{}
+}
+
+func newTaskBuffer(limit int64) *taskBuffer {
+	return &taskBuffer{
+		limit:    limit,
+		released: make(chan struct{}),
+	}
+}
+
+// reserve reserves space for a record. When the buffer is full, it waits for space to be released if block is set,
+// and fails otherwise. A record larger than the limit is let in when the buffer is empty, so that it cannot block
+// the logs forever. It returns whether the space was reserved, and whether it had to wait for it.
+func (b *taskBuffer) reserve(ctx context.Context, size int64, block bool) (bool, bool) {
+	waited := false
+	for {
+		b.lock.Lock()
+		if b.used == 0 || b.used+size <= b.limit {
+			b.used += size
+			b.lock.Unlock()
+			return true, waited
+		}
+		released := b.released
+		b.lock.Unlock()
+
+		if !block {
+			return false, waited
+		}
+		waited = true
+		select {
+		case <-released:
+		case <-ctx.Done():
+			return false, waited
+		}
+	}
+}
+
+// release releases the space of records that were delivered or dropped.
+func (b *taskBuffer) release(size int64) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.used -= size
+	if b.used < 0 {
+		b.used = 0
+	}
+	close(b.released)
+	b.released = make(chan struct{})
+}
+
+// usage returns the size of the records in the buffer and the limit of the buffer.
+func (b *taskBuffer) usage() (int64, int64) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.used, b.limit
+}
@@ -0,0 +1,86 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package logshipper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskBufferDrop(t *testing.T) {
+	buffer := newTaskBuffer(10)
+
+	reserved, waited := buffer.reserve(context.Background(), 8, false)
+	assert.True(t, reserved)
+	assert.False(t, waited)
+
+	reserved, waited = buffer.reserve(context.Background(), 8, false)
+	assert.False(t, reserved)
+	assert.False(t, waited)
+
+	used, limit := buffer.usage()
+	assert.Equal(t, int64(8), used)
+	assert.Equal(t, int64(10), limit)
+
+	buffer.release(8)
+	used, _ = buffer.usage()
+	assert.Equal(t, int64(0), used)
+}
+
+func TestTaskBufferBlock(t *testing.T) {
+	buffer := newTaskBuffer(10)
+	buffer.reserve(context.Background(), 10, true)
+
+	done := make(chan bool)
+	go func() {
+		reserved, waited := buffer.reserve(context.Background(), 5, true)
+		assert.True(t, waited)
+		done <- reserved
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("reserve did not wait for the buffer to have room")
+	case <-time.After(50 * time.Millisecond):
+	}
+	buffer.release(10)
+	assert.True(t, <-done)
+}
+
+func TestTaskBufferBlockContextDone(t *testing.T) {
+	buffer := newTaskBuffer(10)
+	buffer.reserve(context.Background(), 10, true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	reserved, waited := buffer.reserve(ctx, 5, true)
+	assert.False(t, reserved)
+	assert.True(t, waited)
+}
+
+func TestTaskBufferOversizedRecord(t *testing.T) {
+	buffer := newTaskBuffer(10)
+
+	// A record larger than the limit is let in when the buffer is empty.
+	reserved, _ := buffer.reserve(context.Background(), 20, false)
+	assert.True(t, reserved)
+	reserved, _ = buffer.reserve(context.Background(), 1, false)
+	assert.False(t, reserved)
+}
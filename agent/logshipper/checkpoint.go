@@ -0,0 +1,111 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package logshipper
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	checkpointDirName  = "logshipping"
+	checkpointDirPerm  = 0700
+	checkpointTempFile = "tmp_checkpoint"
+)
+
+// checkpoints are the timestamps of the last log records delivered for each container, saved under the data
+// directory of the agent. Reading the logs of a container after the agent restarts resumes right after its
+// checkpoint, so that the records that were not delivered yet are delivered and the ones that were are not delivered
+// again. The timestamps of docker have a precision of a nanosecond, so only the records with the exact timestamp of
+// the checkpoint that were not delivered along with it are skipped.
+type checkpoints struct {
+	dir string
+}
+
+func newCheckpoints(dataDir string) *checkpoints {
+	return &checkpoints{dir: filepath.Join(dataDir, checkpointDirName)}
+}
+
+func (c *checkpoints) path(taskID, containerName string) string {
+	return filepath.Join(c.dir, taskID, containerName)
+}
+
+// read returns the checkpoint of a container, which is zero if there is none.
+func (c *checkpoints) read(taskID, containerName string) (time.Time, error) {
+	data, err := os.ReadFile(c.path(taskID, containerName))
+	if os.IsNotExist(err) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	checkpoint, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(string(data)))
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "invalid log shipping checkpoint of container %s", containerName)
+	}
+	return checkpoint, nil
+}
+
+// write saves the checkpoint of a container. The file is replaced atomically so that a crash cannot leave a
+// partial checkpoint behind.
+func (c *checkpoints) write(taskID, containerName string, checkpoint time.Time) error {
+	taskDir := filepath.Join(c.dir, taskID)
+	if err := os.MkdirAll(taskDir, checkpointDirPerm); err != nil {
+		return err
+	}
+	tmpfile, err := os.CreateTemp(taskDir, checkpointTempFile)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpfile.Name())
+
+	_, err = tmpfile.WriteString(checkpoint.UTC().Format(time.RFC3339Nano))
+	if err == nil {
+		err = tmpfile.Sync()
+	}
+	if closeErr := tmpfile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpfile.Name(), c.path(taskID, containerName))
+}
+
+// removeTask removes the checkpoints of the containers of a task.
+func (c *checkpoints) removeTask(taskID string) error {
+	return os.RemoveAll(filepath.Join(c.dir, taskID))
+}
+
+// taskIDs returns the IDs of the tasks that have checkpoints.
+func (c *checkpoints) taskIDs() ([]string, error) {
+	entries, err := os.ReadDir(c.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var taskIDs []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			taskIDs = append(taskIDs, entry.Name())
+		}
+	}
+	return taskIDs, nil
+}
@@ -0,0 +1,67 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package logshipper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckpoints(t *testing.T) {
+	c := newCheckpoints(t.TempDir())
+
+	checkpoint, err := c.read("task-id", "container")
+	require.NoError(t, err)
+	assert.True(t, checkpoint.IsZero())
+	taskIDs, err := c.taskIDs()
+	require.NoError(t, err)
+	assert.Empty(t, taskIDs)
+
+	expected := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	require.NoError(t, c.write("task-id", "container", expected))
+	checkpoint, err = c.read("task-id", "container")
+	require.NoError(t, err)
+	assert.True(t, expected.Equal(checkpoint))
+
+	// Only the checkpoint is left in the task directory.
+	entries, err := os.ReadDir(filepath.Join(c.dir, "task-id"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	taskIDs, err = c.taskIDs()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"task-id"}, taskIDs)
+
+	require.NoError(t, c.removeTask("task-id"))
+	taskIDs, err = c.taskIDs()
+	require.NoError(t, err)
+	assert.Empty(t, taskIDs)
+}
+
+func TestCheckpointsInvalid(t *testing.T) {
+	c := newCheckpoints(t.TempDir())
+	require.NoError(t, os.MkdirAll(filepath.Join(c.dir, "task-id"), checkpointDirPerm))
+	require.NoError(t, os.WriteFile(c.path("task-id", "container"), []byte("invalid"), 0600))
+
+	_, err := c.read("task-id", "container")
+	assert.Error(t, err)
+}
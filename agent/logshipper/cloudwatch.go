@@ -0,0 +1,231 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package logshipper
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	agentversion "github.com/aws/amazon-ecs-agent/agent/version"
+	apierrors "github.com/aws/amazon-ecs-agent/ecs-agent/api/errors"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/httpclient"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/ipcompatibility"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	cloudWatchRoundtripTimeout = 30 * time.Second
+	// These are the limits of PutLogEvents, see
+	// https://docs.aws.amazon.com/AmazonCloudWatchLogs/latest/APIReference/API_PutLogEvents.html
+	cloudWatchMaximumBatchRecords = 10000
+	cloudWatchMaximumBatchBytes   = 1024 * 1024
+	cloudWatchEventOverhead       = 26
+	cloudWatchMaximumEventBytes   = 256*1024 - cloudWatchEventOverhead
+)
+
+// cloudWatchClient is the subset of the CloudWatch Logs API used to ship logs.
+type cloudWatchClient interface {
+	CreateLogGroup(ctx context.Context, input *cloudwatchlogs.CreateLogGroupInput,
+		optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.CreateLogGroupOutput, error)
+	CreateLogStream(ctx context.Context, input *cloudwatchlogs.CreateLogStreamInput,
+		optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.CreateLogStreamOutput, error)
+	PutLogEvents(ctx context.Context, input *cloudwatchlogs.PutLogEventsInput,
+		optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.PutLogEventsOutput, error)
+}
+
+// cloudWatchClientCreator creates the CloudWatch Logs client of a container. The credentials provider is nil
+// when the task has no execution role, in which case the credentials of the container instance are used.
+type cloudWatchClientCreator func(region string, credentialsProvider aws.CredentialsProvider,
+	ipCompatibility ipcompatibility.IPCompatibility) (cloudWatchClient, error)
+
+func newCloudWatchClient(region string, credentialsProvider aws.CredentialsProvider,
+	ipCompatibility ipcompatibility.IPCompatibility) (cloudWatchClient, error) {
+	opts := []func(*awsconfig.LoadOptions) error{
+		awsconfig.WithHTTPClient(httpclient.New(cloudWatchRoundtripTimeout, false, agentversion.String(),
+			config.OSType)),
+		awsconfig.WithRegion(region),
+	}
+	if credentialsProvider != nil {
+		opts = append(opts, awsconfig.WithCredentialsProvider(aws.NewCredentialsCache(credentialsProvider)))
+	}
+	if ipCompatibility.IsIPv6Only() {
+		opts = append(opts, awsconfig.WithUseDualStackEndpoint(aws.DualStackEndpointStateEnabled))
+	}
+
+	cfg, err := awsconfig.LoadDefaultConfig(context.TODO(), opts...)
+	if err != nil {
+		return nil, err
+	}
+	return cloudwatchlogs.NewFromConfig(cfg), nil
+}
+
+// executionRoleCredentialsProvider provides the credentials of the execution role of a task, as they are refreshed
+// by the credentials manager.
+type executionRoleCredentialsProvider struct {
+	credentialsManager credentials.Manager
+	credentialsID      string
+}
+
+func (p *executionRoleCredentialsProvider) Retrieve(ctx context.Context) (aws.Credentials, error) {
+	taskCredentials, ok := p.credentialsManager.GetTaskCredentials(p.credentialsID)
+	if !ok {
+		return aws.Credentials{}, errors.New("unable to get the execution role credentials of the task")
+	}
+	creds := taskCredentials.GetIAMRoleCredentials()
+	awsCredentials := aws.Credentials{
+		AccessKeyID:     creds.AccessKeyID,
+		SecretAccessKey: creds.SecretAccessKey,
+		SessionToken:    creds.SessionToken,
+		Source:          "ExecutionRoleCredentials",
+	}
+	if expiration, err := time.Parse(time.RFC3339, creds.Expiration); err == nil {
+		awsCredentials.CanExpire = true
+		awsCredentials.Expires = expiration
+	}
+	return awsCredentials, nil
+}
+
+// cloudWatchDestination ships the logs of a container to a log stream of CloudWatch Logs.
+type cloudWatchDestination struct {
+	client      cloudWatchClient
+	group       string
+	stream      string
+	createGroup bool
+	// streamCreated is set once the log stream is known to exist.
+	streamCreated bool
+}
+
+func newCloudWatchDestination(client cloudWatchClient, options *Options, containerName,
+	taskID string) *cloudWatchDestination {
+	return &cloudWatchDestination{
+		client:      client,
+		group:       options.CloudWatchGroup,
+		stream:      options.CloudWatchStream(containerName, taskID),
+		createGroup: options.CloudWatchCreateGroup,
+	}
+}
+
+func (d *cloudWatchDestination) limits() batchLimits {
+	return batchLimits{
+		records:        cloudWatchMaximumBatchRecords,
+		bytes:          cloudWatchMaximumBatchBytes,
+		recordOverhead: cloudWatchEventOverhead,
+		recordBytes:    cloudWatchMaximumEventBytes,
+	}
+}
+
+func (d *cloudWatchDestination) deliver(ctx context.Context, records []*record) error {
+	if !d.streamCreated {
+		if err := d.createStream(ctx); err != nil {
+			return err
+		}
+		d.streamCreated = true
+	}
+
+	events := make([]types.InputLogEvent, 0, len(records))
+	for _, r := range records {
+		// CloudWatch Logs rejects empty messages, they are skipped as they are by the awslogs log driver.
+		if r.message == "" {
+			continue
+		}
+		events = append(events, types.InputLogEvent{
+			Message:   aws.String(r.message),
+			Timestamp: aws.Int64(r.timestamp.UnixMilli()),
+		})
+	}
+	if len(events) == 0 {
+		return nil
+	}
+	// The events of a request must be in chronological order, which the records of stdout and stderr may not be.
+	sort.SliceStable(events, func(i, j int) bool {
+		return *events[i].Timestamp < *events[j].Timestamp
+	})
+
+	output, err := d.client.PutLogEvents(ctx, &cloudwatchlogs.PutLogEventsInput{
+		LogGroupName:  aws.String(d.group),
+		LogStreamName: aws.String(d.stream),
+		LogEvents:     events,
+	})
+	if err != nil {
+		var notFound *types.ResourceNotFoundException
+		if errors.As(err, &notFound) {
+			// The log stream, or its group, was deleted. It is created again on retry.
+			d.streamCreated = false
+			return err
+		}
+		var invalidParameter *types.InvalidParameterException
+		if errors.As(err, &invalidParameter) {
+			return apierrors.NewRetriableError(apierrors.NewRetriable(false), err)
+		}
+		return err
+	}
+	if output.RejectedLogEventsInfo != nil {
+		logger.Warn("Some log events were rejected by CloudWatch Logs", logger.Fields{
+			"logGroup":  d.group,
+			"logStream": d.stream,
+		})
+	}
+	return nil
+}
+
+// createStream creates the log stream, and the log group if it does not exist and it should be created.
+func (d *cloudWatchDestination) createStream(ctx context.Context) error {
+	err := d.createStreamOnce(ctx)
+	var notFound *types.ResourceNotFoundException
+	if err == nil || !d.createGroup || !errors.As(err, &notFound) {
+		return err
+	}
+
+	logger.Info("Creating log group to ship container logs to", logger.Fields{
+		"logGroup": d.group,
+	})
+	_, err = d.client.CreateLogGroup(ctx, &cloudwatchlogs.CreateLogGroupInput{
+		LogGroupName: aws.String(d.group),
+	})
+	var alreadyExists *types.ResourceAlreadyExistsException
+	if err != nil && !errors.As(err, &alreadyExists) {
+		logger.Warn("Unable to create log group", logger.Fields{
+			"logGroup":  d.group,
+			field.Error: err,
+		})
+		return err
+	}
+	return d.createStreamOnce(ctx)
+}
+
+func (d *cloudWatchDestination) createStreamOnce(ctx context.Context) error {
+	_, err := d.client.CreateLogStream(ctx, &cloudwatchlogs.CreateLogStreamInput{
+		LogGroupName:  aws.String(d.group),
+		LogStreamName: aws.String(d.stream),
+	})
+	var alreadyExists *types.ResourceAlreadyExistsException
+	if err != nil && !errors.As(err, &alreadyExists) {
+		return err
+	}
+	return nil
+}
+
+func (d *cloudWatchDestination) close() error {
+	return nil
+}
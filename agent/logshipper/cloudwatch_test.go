@@ -0,0 +1,193 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package logshipper
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	apierrors "github.com/aws/amazon-ecs-agent/ecs-agent/api/errors"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
+	mock_credentials "github.com/aws/amazon-ecs-agent/ecs-agent/credentials/mocks"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCloudWatchClient records the calls made to CloudWatch Logs, and fails them with the errors queued for them.
+type fakeCloudWatchClient struct {
+	createGroupErrs  []error
+	createStreamErrs []error
+	putErrs          []error
+	groupsCreated    []string
+	streamsCreated   []string
+	events           [][]types.InputLogEvent
+}
+
+func popError(errs *[]error) error {
+	if len(*errs) == 0 {
+		return nil
+	}
+	err := (*errs)[0]
+	*errs = (*errs)[1:]
+	return err
+}
+
+func (c *fakeCloudWatchClient) CreateLogGroup(ctx context.Context, input *cloudwatchlogs.CreateLogGroupInput,
+	optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.CreateLogGroupOutput, error) {
+	if err := popError(&c.createGroupErrs); err != nil {
+		return nil, err
+	}
+	c.groupsCreated = append(c.groupsCreated, aws.ToString(input.LogGroupName))
+	return &cloudwatchlogs.CreateLogGroupOutput{}, nil
+}
+
+func (c *fakeCloudWatchClient) CreateLogStream(ctx context.Context, input *cloudwatchlogs.CreateLogStreamInput,
+	optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.CreateLogStreamOutput, error) {
+	if err := popError(&c.createStreamErrs); err != nil {
+		return nil, err
+	}
+	c.streamsCreated = append(c.streamsCreated, aws.ToString(input.LogStreamName))
+	return &cloudwatchlogs.CreateLogStreamOutput{}, nil
+}
+
+func (c *fakeCloudWatchClient) PutLogEvents(ctx context.Context, input *cloudwatchlogs.PutLogEventsInput,
+	optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.PutLogEventsOutput, error) {
+	if err := popError(&c.putErrs); err != nil {
+		return nil, err
+	}
+	c.events = append(c.events, input.LogEvents)
+	return &cloudwatchlogs.PutLogEventsOutput{}, nil
+}
+
+func testCloudWatchOptions(createGroup bool) *Options {
+	return &Options{
+		Destination:            DestinationCloudWatch,
+		CloudWatchGroup:        "group",
+		CloudWatchStreamPrefix: "prefix",
+		CloudWatchCreateGroup:  createGroup,
+	}
+}
+
+func TestCloudWatchDestinationDeliver(t *testing.T) {
+	client := &fakeCloudWatchClient{}
+	dest := newCloudWatchDestination(client, testCloudWatchOptions(false), "container", "task-id")
+
+	now := time.Now()
+	err := dest.deliver(context.Background(), []*record{
+		{timestamp: now.Add(time.Second), stream: streamStdout, message: "second"},
+		{timestamp: now, stream: streamStderr, message: "first"},
+		{timestamp: now, stream: streamStdout, message: ""},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"prefix/container/task-id"}, client.streamsCreated)
+	require.Len(t, client.events, 1)
+	// Empty messages are skipped, and the events are sorted by timestamp.
+	require.Len(t, client.events[0], 2)
+	assert.Equal(t, "first", aws.ToString(client.events[0][0].Message))
+	assert.Equal(t, "second", aws.ToString(client.events[0][1].Message))
+
+	// The log stream is created once.
+	require.NoError(t, dest.deliver(context.Background(), []*record{{timestamp: now, message: "third"}}))
+	assert.Len(t, client.streamsCreated, 1)
+}
+
+func TestCloudWatchDestinationCreateGroup(t *testing.T) {
+	client := &fakeCloudWatchClient{
+		createStreamErrs: []error{&types.ResourceNotFoundException{}},
+	}
+	dest := newCloudWatchDestination(client, testCloudWatchOptions(true), "container", "task-id")
+
+	require.NoError(t, dest.deliver(context.Background(), []*record{{timestamp: time.Now(), message: "log"}}))
+	assert.Equal(t, []string{"group"}, client.groupsCreated)
+	assert.Equal(t, []string{"prefix/container/task-id"}, client.streamsCreated)
+}
+
+func TestCloudWatchDestinationMissingGroup(t *testing.T) {
+	client := &fakeCloudWatchClient{
+		createStreamErrs: []error{&types.ResourceNotFoundException{}},
+	}
+	dest := newCloudWatchDestination(client, testCloudWatchOptions(false), "container", "task-id")
+
+	err := dest.deliver(context.Background(), []*record{{timestamp: time.Now(), message: "log"}})
+	assert.Error(t, err)
+	assert.Empty(t, client.groupsCreated)
+}
+
+func TestCloudWatchDestinationStreamDeleted(t *testing.T) {
+	client := &fakeCloudWatchClient{
+		putErrs: []error{&types.ResourceNotFoundException{}},
+	}
+	dest := newCloudWatchDestination(client, testCloudWatchOptions(false), "container", "task-id")
+	records := []*record{{timestamp: time.Now(), message: "log"}}
+
+	assert.Error(t, dest.deliver(context.Background(), records))
+	// The log stream is created again on retry.
+	require.NoError(t, dest.deliver(context.Background(), records))
+	assert.Len(t, client.streamsCreated, 2)
+	assert.Len(t, client.events, 1)
+}
+
+func TestCloudWatchDestinationInvalidParameter(t *testing.T) {
+	client := &fakeCloudWatchClient{
+		putErrs: []error{&types.InvalidParameterException{}},
+	}
+	dest := newCloudWatchDestination(client, testCloudWatchOptions(false), "container", "task-id")
+
+	err := dest.deliver(context.Background(), []*record{{timestamp: time.Now(), message: "log"}})
+	require.Error(t, err)
+	var retriable apierrors.Retriable
+	require.True(t, errors.As(err, &retriable))
+	assert.False(t, retriable.Retry())
+}
+
+func TestExecutionRoleCredentialsProvider(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	credentialsManager := mock_credentials.NewMockManager(ctrl)
+	provider := &executionRoleCredentialsProvider{
+		credentialsManager: credentialsManager,
+		credentialsID:      "credentials-id",
+	}
+
+	credentialsManager.EXPECT().GetTaskCredentials("credentials-id").Return(credentials.TaskIAMRoleCredentials{
+		IAMRoleCredentials: credentials.IAMRoleCredentials{
+			AccessKeyID:     "access-key",
+			SecretAccessKey: "secret-key",
+			SessionToken:    "token",
+			Expiration:      "2030-01-01T00:00:00Z",
+		},
+	}, true)
+	creds, err := provider.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "access-key", creds.AccessKeyID)
+	assert.Equal(t, "secret-key", creds.SecretAccessKey)
+	assert.Equal(t, "token", creds.SessionToken)
+	assert.True(t, creds.CanExpire)
+	assert.Equal(t, time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), creds.Expires)
+
+	credentialsManager.EXPECT().GetTaskCredentials("credentials-id").Return(credentials.TaskIAMRoleCredentials{},
+		false)
+	_, err = provider.Retrieve(context.Background())
+	assert.Error(t, err)
+}
@@ -0,0 +1,321 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package logshipper
+
+import (
+	"context"
+	"io"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/retry"
+
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+const (
+	// recordsChannelSize is the number of records read from a container that wait to be batched.
+	recordsChannelSize = 1024
+
+	// deliveryAttempts is the number of attempts to deliver a batch of records before it is dropped.
+	deliveryAttempts        = 5
+	deliveryBackoffMin      = time.Second
+	deliveryBackoffMax      = 30 * time.Second
+	deliveryBackoffJitter   = 0.2
+	deliveryBackoffMultiple = 2
+
+	// readAttempts is the number of consecutive failures to read the logs of a container, or to find out whether
+	// it is still running, before the logs of the container are no longer read.
+	readAttempts        = 10
+	readBackoffMin      = time.Second
+	readBackoffMax      = 30 * time.Second
+	readBackoffJitter   = 0.2
+	readBackoffMultiple = 2
+)
+
+// containerShipper ships the logs of a container. The logs are read from the docker API, from the checkpoint of the
+// container on, and each record takes room in the buffer of the task until it is delivered or dropped.
+type containerShipper struct {
+	taskID        string
+	containerName string
+	dockerID      string
+	options       *Options
+	dest          destination
+	// tty is set for containers created with a TTY, whose stdout and stderr are not multiplexed.
+	tty         bool
+	client      dockerapi.DockerClient
+	buffer      *taskBuffer
+	checkpoints *checkpoints
+	stats       *containerStats
+	// checkpoint is the timestamp of the last record delivered.
+	checkpoint time.Time
+}
+
+// run ships the logs of the container until the container stops and its logs are all delivered, or until the
+// context is done.
+func (c *containerShipper) run(ctx context.Context) {
+	defer func() {
+		if err := c.dest.close(); err != nil {
+			logger.Warn("Unable to close log shipping destination", c.fields(), logger.Fields{
+				field.Error: err,
+			})
+		}
+	}()
+
+	records := make(chan *record, recordsChannelSize)
+	go c.read(ctx, records)
+	c.batch(ctx, records)
+}
+
+// read reads the logs of the container into the records channel, and closes it once the container stops.
+func (c *containerShipper) read(ctx context.Context, records chan<- *record) {
+	defer close(records)
+
+	since := time.Time{}
+	if !c.checkpoint.IsZero() {
+		since = c.checkpoint.Add(time.Nanosecond)
+	}
+	backoff := retry.NewExponentialBackoff(readBackoffMin, readBackoffMax, readBackoffJitter, readBackoffMultiple)
+	failures := 0
+	for {
+		lastRead, err := c.readOnce(ctx, since, records)
+		if ctx.Err() != nil {
+			return
+		}
+		if !lastRead.IsZero() {
+			since = lastRead.Add(time.Nanosecond)
+		}
+		if err != nil {
+			logger.Warn("Unable to read container logs", c.fields(), logger.Fields{
+				field.Error: err,
+			})
+		}
+
+		// The logs end once the container stops, but they also end when the docker daemon restarts.
+		running, inspectErr := c.running(ctx)
+		if inspectErr == nil && !running {
+			return
+		}
+		if err == nil && inspectErr == nil {
+			failures = 0
+			backoff.Reset()
+		} else {
+			failures++
+			if failures >= readAttempts {
+				logger.Error("Giving up reading container logs", c.fields(), logger.Fields{
+					field.Error: inspectErr,
+				})
+				return
+			}
+		}
+
+		select {
+		case <-time.After(backoff.Duration()):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// readOnce reads the logs of the container since a time, until they end. It returns the timestamp of the last
+// record read.
+func (c *containerShipper) readOnce(ctx context.Context, since time.Time, records chan<- *record) (time.Time, error) {
+	logs, err := c.client.ContainerLogs(ctx, c.dockerID, since)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer logs.Close()
+
+	var lastRead time.Time
+	emit := func(r *record) error {
+		for _, part := range c.split(r) {
+			if err := c.enqueue(ctx, part, records); err != nil {
+				return err
+			}
+		}
+		lastRead = r.timestamp
+		return nil
+	}
+	stdout := newLineWriter(streamStdout, emit)
+	stderr := newLineWriter(streamStderr, emit)
+	if c.tty {
+		_, err = io.Copy(stdout, logs)
+	} else {
+		_, err = stdcopy.StdCopy(stdout, stderr, logs)
+	}
+	if err == nil {
+		err = stdout.flush()
+	}
+	if err == nil {
+		err = stderr.flush()
+	}
+	return lastRead, err
+}
+
+// split splits a record whose message is longer than a record of the destination can be. Messages are split on
+// UTF-8 character boundaries.
+func (c *containerShipper) split(r *record) []*record {
+	limit := int(c.dest.limits().recordBytes)
+	if limit <= 0 || len(r.message) <= limit {
+		return []*record{r}
+	}
+	var parts []*record
+	message := r.message
+	for len(message) > limit {
+		i := limit
+		for i > 0 && !utf8.RuneStart(message[i]) {
+			i--
+		}
+		if i == 0 {
+			i = limit
+		}
+		parts = append(parts, &record{timestamp: r.timestamp, stream: r.stream, message: message[:i]})
+		message = message[i:]
+	}
+	return append(parts, &record{timestamp: r.timestamp, stream: r.stream, message: message})
+}
+
+// enqueue takes room for a record in the buffer of the task, and queues it to be batched. With the drop
+// backpressure mode, the record is dropped if the buffer is full.
+func (c *containerShipper) enqueue(ctx context.Context, r *record, records chan<- *record) error {
+	size := r.size()
+	c.stats.recordRead(size)
+	reserved, waited := c.buffer.reserve(ctx, size, c.options.Backpressure == BackpressureBlock)
+	if waited {
+		c.stats.backpressureEvent()
+	}
+	if !reserved {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		c.stats.recordsDropped(1)
+		return nil
+	}
+
+	select {
+	case records <- r:
+		return nil
+	case <-ctx.Done():
+		c.buffer.release(size)
+		return ctx.Err()
+	}
+}
+
+// batch batches the records read from the container, and delivers a batch when the flush interval elapses or when
+// the batch is full.
+func (c *containerShipper) batch(ctx context.Context, records <-chan *record) {
+	limits := c.dest.limits()
+	ticker := time.NewTicker(c.options.FlushInterval)
+	defer ticker.Stop()
+
+	var batch []*record
+	var batchBytes int64
+	flush := func() {
+		if len(batch) > 0 {
+			c.deliver(ctx, batch)
+		}
+		batch = nil
+		batchBytes = 0
+	}
+	for {
+		select {
+		case r, ok := <-records:
+			if !ok {
+				flush()
+				return
+			}
+			recordBytes := r.size() + limits.recordOverhead
+			if len(batch) > 0 && (len(batch) >= limits.records || batchBytes+recordBytes > limits.bytes) {
+				flush()
+			}
+			batch = append(batch, r)
+			batchBytes += recordBytes
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// deliver delivers a batch of records, and saves the timestamp of its last record as the checkpoint of the
+// container. The batch is dropped if it cannot be delivered after all the attempts.
+func (c *containerShipper) deliver(ctx context.Context, batch []*record) {
+	var size int64
+	for _, r := range batch {
+		size += r.size()
+	}
+	defer c.buffer.release(size)
+
+	delivered := false
+	backoff := retry.NewExponentialBackoff(deliveryBackoffMin, deliveryBackoffMax, deliveryBackoffJitter,
+		deliveryBackoffMultiple)
+	err := retry.RetryNWithBackoffCtx(ctx, backoff, deliveryAttempts, func() error {
+		if err := c.dest.deliver(ctx, batch); err != nil {
+			c.stats.deliveryError()
+			logger.Warn("Unable to deliver container logs", c.fields(), logger.Fields{
+				field.Error: err,
+			})
+			return err
+		}
+		delivered = true
+		return nil
+	})
+	if !delivered {
+		// The records are not dropped when the agent stops, they are read again from the checkpoint once it
+		// starts again.
+		if ctx.Err() == nil {
+			logger.Error("Dropping container logs that could not be delivered", c.fields(), logger.Fields{
+				"records":   len(batch),
+				field.Error: err,
+			})
+			c.stats.recordsDropped(int64(len(batch)))
+		}
+		return
+	}
+	c.stats.recordsDelivered(int64(len(batch)), size)
+
+	for _, r := range batch {
+		if r.timestamp.After(c.checkpoint) {
+			c.checkpoint = r.timestamp
+		}
+	}
+	if err := c.checkpoints.write(c.taskID, c.containerName, c.checkpoint); err != nil {
+		logger.Warn("Unable to save log shipping checkpoint", c.fields(), logger.Fields{
+			field.Error: err,
+		})
+	}
+}
+
+// running returns whether the container is running, or is being restarted by docker.
+func (c *containerShipper) running(ctx context.Context) (bool, error) {
+	container, err := c.client.InspectContainer(ctx, c.dockerID, dockerclient.InspectContainerTimeout)
+	if err != nil {
+		return false, err
+	}
+	if container.State == nil {
+		return false, nil
+	}
+	return container.State.Running || container.State.Restarting, nil
+}
+
+func (c *containerShipper) fields() logger.Fields {
+	return logger.Fields{
+		field.TaskID:    c.taskID,
+		field.Container: c.containerName,
+		field.DockerId:  c.dockerID,
+	}
+}
@@ -0,0 +1,267 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package logshipper
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	mock_dockerapi "github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi/mocks"
+	apierrors "github.com/aws/amazon-ecs-agent/ecs-agent/api/errors"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDestination records the batches delivered to it, and fails the deliveries with the errors queued for them.
+type fakeDestination struct {
+	lock       sync.Mutex
+	batchLimit batchLimits
+	errs       []error
+	batches    [][]*record
+	closed     bool
+}
+
+func (d *fakeDestination) limits() batchLimits {
+	return d.batchLimit
+}
+
+func (d *fakeDestination) deliver(ctx context.Context, records []*record) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if err := popError(&d.errs); err != nil {
+		return err
+	}
+	d.batches = append(d.batches, records)
+	return nil
+}
+
+func (d *fakeDestination) close() error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.closed = true
+	return nil
+}
+
+func (d *fakeDestination) messages() []string {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	var messages []string
+	for _, batch := range d.batches {
+		for _, r := range batch {
+			messages = append(messages, r.message)
+		}
+	}
+	return messages
+}
+
+// dockerLogs returns the logs of a container as they are returned by the docker API for a container without TTY.
+func dockerLogs(t *testing.T, stdout, stderr string) io.ReadCloser {
+	var logs bytes.Buffer
+	_, err := stdcopy.NewStdWriter(&logs, stdcopy.Stdout).Write([]byte(stdout))
+	require.NoError(t, err)
+	if stderr != "" {
+		_, err = stdcopy.NewStdWriter(&logs, stdcopy.Stderr).Write([]byte(stderr))
+		require.NoError(t, err)
+	}
+	return io.NopCloser(&logs)
+}
+
+func stoppedContainer() *types.ContainerJSON {
+	return &types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{State: &types.ContainerState{Running: false}},
+	}
+}
+
+func newTestContainerShipper(t *testing.T, client *mock_dockerapi.MockDockerClient, dest destination,
+	options *Options, bufferLimit int64) *containerShipper {
+	return &containerShipper{
+		taskID:        "task-id",
+		containerName: "container",
+		dockerID:      "docker-id",
+		options:       options,
+		dest:          dest,
+		client:        client,
+		buffer:        newTaskBuffer(bufferLimit),
+		checkpoints:   newCheckpoints(t.TempDir()),
+		stats:         &containerStats{},
+	}
+}
+
+func TestContainerShipperRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+	dest := &fakeDestination{batchLimit: batchLimits{records: 2, bytes: 1024}}
+	shipper := newTestContainerShipper(t, client, dest, &Options{
+		Backpressure:  BackpressureBlock,
+		FlushInterval: time.Minute,
+	}, 1024)
+
+	client.EXPECT().ContainerLogs(gomock.Any(), "docker-id", time.Time{}).Return(dockerLogs(t,
+		"2024-01-02T03:04:01Z one\n2024-01-02T03:04:02Z two\n2024-01-02T03:04:03Z three\n",
+		"2024-01-02T03:04:04Z four\n"), nil)
+	client.EXPECT().InspectContainer(gomock.Any(), "docker-id", gomock.Any()).Return(stoppedContainer(), nil)
+
+	shipper.run(context.Background())
+
+	assert.Equal(t, []string{"one", "two", "three", "four"}, dest.messages())
+	assert.Len(t, dest.batches, 2)
+	assert.Equal(t, streamStderr, dest.batches[1][1].stream)
+	assert.True(t, dest.closed)
+
+	checkpoint, err := shipper.checkpoints.read("task-id", "container")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 4, 0, time.UTC), checkpoint)
+
+	stats := shipper.stats.snapshot()
+	assert.Equal(t, int64(4), stats.RecordsRead)
+	assert.Equal(t, int64(4), stats.RecordsDelivered)
+	assert.Equal(t, int64(len("onetwothreefour")), stats.BytesDelivered)
+	assert.NotNil(t, stats.LastDeliveryTime)
+	used, _ := shipper.buffer.usage()
+	assert.Zero(t, used)
+}
+
+func TestContainerShipperRunFromCheckpoint(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+	dest := &fakeDestination{batchLimit: batchLimits{records: 10, bytes: 1024}}
+	shipper := newTestContainerShipper(t, client, dest, &Options{
+		Backpressure:  BackpressureBlock,
+		FlushInterval: time.Minute,
+	}, 1024)
+	shipper.checkpoint = time.Date(2024, 1, 2, 3, 4, 1, 0, time.UTC)
+
+	client.EXPECT().ContainerLogs(gomock.Any(), "docker-id", shipper.checkpoint.Add(time.Nanosecond)).Return(
+		dockerLogs(t, "2024-01-02T03:04:02Z two\n", ""), nil)
+	client.EXPECT().InspectContainer(gomock.Any(), "docker-id", gomock.Any()).Return(stoppedContainer(), nil)
+
+	shipper.run(context.Background())
+	assert.Equal(t, []string{"two"}, dest.messages())
+}
+
+func TestContainerShipperRunDrop(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+	dest := &fakeDestination{batchLimit: batchLimits{records: 10, bytes: 1024}}
+	// The records are not delivered before the logs end, so the buffer only has room for two of them.
+	shipper := newTestContainerShipper(t, client, dest, &Options{
+		Backpressure:  BackpressureDrop,
+		FlushInterval: time.Minute,
+	}, 10)
+
+	client.EXPECT().ContainerLogs(gomock.Any(), "docker-id", time.Time{}).Return(dockerLogs(t,
+		"2024-01-02T03:04:01Z aaaa\n2024-01-02T03:04:02Z bbbb\n2024-01-02T03:04:03Z cccc\n", ""), nil)
+	client.EXPECT().InspectContainer(gomock.Any(), "docker-id", gomock.Any()).Return(stoppedContainer(), nil)
+
+	shipper.run(context.Background())
+	assert.Equal(t, []string{"aaaa", "bbbb"}, dest.messages())
+	stats := shipper.stats.snapshot()
+	assert.Equal(t, int64(3), stats.RecordsRead)
+	assert.Equal(t, int64(1), stats.RecordsDropped)
+}
+
+func TestContainerShipperRunReconnect(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+	dest := &fakeDestination{batchLimit: batchLimits{records: 10, bytes: 1024}}
+	shipper := newTestContainerShipper(t, client, dest, &Options{
+		Backpressure:  BackpressureBlock,
+		FlushInterval: time.Minute,
+	}, 1024)
+
+	lastRead := time.Date(2024, 1, 2, 3, 4, 1, 0, time.UTC)
+	gomock.InOrder(
+		client.EXPECT().ContainerLogs(gomock.Any(), "docker-id", time.Time{}).Return(
+			dockerLogs(t, "2024-01-02T03:04:01Z one\n", ""), nil),
+		// The logs end while the container is still running, as they do when the docker daemon restarts.
+		client.EXPECT().InspectContainer(gomock.Any(), "docker-id", gomock.Any()).Return(&types.ContainerJSON{
+			ContainerJSONBase: &types.ContainerJSONBase{State: &types.ContainerState{Running: true}},
+		}, nil),
+		client.EXPECT().ContainerLogs(gomock.Any(), "docker-id", lastRead.Add(time.Nanosecond)).Return(
+			dockerLogs(t, "2024-01-02T03:04:02Z two\n", ""), nil),
+		client.EXPECT().InspectContainer(gomock.Any(), "docker-id", gomock.Any()).Return(stoppedContainer(), nil),
+	)
+
+	shipper.run(context.Background())
+	assert.Equal(t, []string{"one", "two"}, dest.messages())
+}
+
+func TestContainerShipperDeliveryFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+	dest := &fakeDestination{
+		batchLimit: batchLimits{records: 10, bytes: 1024},
+		errs:       []error{nonRetriableTestError()},
+	}
+	shipper := newTestContainerShipper(t, client, dest, &Options{
+		Backpressure:  BackpressureBlock,
+		FlushInterval: time.Minute,
+	}, 1024)
+
+	client.EXPECT().ContainerLogs(gomock.Any(), "docker-id", time.Time{}).Return(
+		dockerLogs(t, "2024-01-02T03:04:01Z one\n", ""), nil)
+	client.EXPECT().InspectContainer(gomock.Any(), "docker-id", gomock.Any()).Return(stoppedContainer(), nil)
+
+	shipper.run(context.Background())
+	assert.Empty(t, dest.messages())
+	stats := shipper.stats.snapshot()
+	assert.Equal(t, int64(1), stats.DeliveryErrors)
+	assert.Equal(t, int64(1), stats.RecordsDropped)
+	used, _ := shipper.buffer.usage()
+	assert.Zero(t, used)
+	// The checkpoint only moves past the records that are delivered.
+	checkpoint, err := shipper.checkpoints.read("task-id", "container")
+	require.NoError(t, err)
+	assert.True(t, checkpoint.IsZero())
+}
+
+func TestContainerShipperSplit(t *testing.T) {
+	shipper := &containerShipper{dest: &fakeDestination{batchLimit: batchLimits{recordBytes: 4}}}
+
+	parts := shipper.split(&record{message: "abcdefghij"})
+	var messages []string
+	for _, part := range parts {
+		messages = append(messages, part.message)
+	}
+	assert.Equal(t, []string{"abcd", "efgh", "ij"}, messages)
+
+	// Multi-byte characters are not split.
+	parts = shipper.split(&record{message: "abéé"})
+	messages = nil
+	for _, part := range parts {
+		messages = append(messages, part.message)
+	}
+	assert.Equal(t, []string{"abé", "é"}, messages)
+}
+
+func nonRetriableTestError() error {
+	return apierrors.NewRetriableError(apierrors.NewRetriable(false), errors.New("invalid logs"))
+}
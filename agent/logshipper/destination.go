@@ -0,0 +1,42 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package logshipper
+
+import (
+	"context"
+)
+
+// batchLimits are the limits of a batch of records delivered in a single request to a destination.
+type batchLimits struct {
+	// records is the maximum number of records of a batch.
+	records int
+	// bytes is the maximum size of a batch, counting the size of the message of each record and the overhead.
+	bytes int64
+	// recordOverhead is the size added to the message of each record to count it against the bytes limit.
+	recordOverhead int64
+	// recordBytes is the maximum size of the message of a record, longer messages are split into several records.
+	// Zero means that there is no limit.
+	recordBytes int64
+}
+
+// destination is where the logs of a container are shipped to.
+type destination interface {
+	// limits returns the limits of a batch of records delivered to the destination.
+	limits() batchLimits
+	// deliver delivers a batch of records, in the order they were read. An error that is not retriable, per
+	// apierrors.Retriable, fails the delivery of the batch without retrying it.
+	deliver(ctx context.Context, records []*record) error
+	// close releases the resources of the destination once the logs of the container are shipped.
+	close() error
+}
@@ -0,0 +1,153 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package logshipper
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	fileDestinationDirPerm  = 0755
+	fileDestinationFilePerm = 0644
+	fileDestinationSuffix   = ".log"
+)
+
+// fileRecord is a line of the file that the logs of a container are shipped to.
+type fileRecord struct {
+	Time   string `json:"time"`
+	Stream string `json:"stream"`
+	Log    string `json:"log"`
+}
+
+// fileDestination ships the logs of a container to a file on the host, as a JSON object per line. The file is
+// ${ECS_LOG_SHIPPING_FILE_DIR}/task-id/container-name.log, and it is appended to. It is rotated once it reaches
+// the max size, as container-name.log.1, container-name.log.2 and so on, and only the last max files are kept. The
+// files are removed along with the task.
+type fileDestination struct {
+	path     string
+	maxSize  int64
+	maxFiles int
+	file     *os.File
+	size     int64
+}
+
+func newFileDestination(dir, taskID, containerName string, maxSize int64, maxFiles int) (*fileDestination, error) {
+	taskDir := filepath.Join(dir, taskID)
+	if err := os.MkdirAll(taskDir, fileDestinationDirPerm); err != nil {
+		return nil, errors.Wrapf(err, "unable to create log directory %s", taskDir)
+	}
+	d := &fileDestination{
+		path:     filepath.Join(taskDir, containerName+fileDestinationSuffix),
+		maxSize:  maxSize,
+		maxFiles: maxFiles,
+	}
+	if err := d.open(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *fileDestination) open() error {
+	file, err := os.OpenFile(d.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, fileDestinationFilePerm)
+	if err != nil {
+		return errors.Wrapf(err, "unable to open log file %s", d.path)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return errors.Wrapf(err, "unable to stat log file %s", d.path)
+	}
+	d.file = file
+	d.size = info.Size()
+	return nil
+}
+
+// rotate renames the file to container-name.log.1, after shifting the files rotated before, and opens a new file.
+func (d *fileDestination) rotate() error {
+	if err := d.file.Close(); err != nil {
+		return err
+	}
+	if err := os.Remove(d.rotatedPath(d.maxFiles - 1)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i := d.maxFiles - 2; i >= 0; i-- {
+		if err := os.Rename(d.rotatedPath(i), d.rotatedPath(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return d.open()
+}
+
+// rotatedPath returns the path of the file rotated n times, which is the file being written to for 0.
+func (d *fileDestination) rotatedPath(n int) string {
+	if n == 0 {
+		return d.path
+	}
+	return d.path + "." + strconv.Itoa(n)
+}
+
+func (d *fileDestination) limits() batchLimits {
+	return batchLimits{records: 10000, bytes: 4 * 1024 * 1024}
+}
+
+func (d *fileDestination) deliver(ctx context.Context, records []*record) error {
+	var line bytes.Buffer
+	encoder := json.NewEncoder(&line)
+	writer := bufio.NewWriter(d.file)
+	for _, r := range records {
+		line.Reset()
+		if err := encoder.Encode(fileRecord{
+			Time:   r.timestamp.UTC().Format(time.RFC3339Nano),
+			Stream: r.stream,
+			Log:    r.message,
+		}); err != nil {
+			return err
+		}
+		if d.size > 0 && d.size+int64(line.Len()) > d.maxSize {
+			if err := writer.Flush(); err != nil {
+				return err
+			}
+			if err := d.rotate(); err != nil {
+				return errors.Wrapf(err, "unable to rotate log file %s", d.path)
+			}
+			writer.Reset(d.file)
+		}
+		if _, err := writer.Write(line.Bytes()); err != nil {
+			return err
+		}
+		d.size += int64(line.Len())
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	return d.file.Sync()
+}
+
+func (d *fileDestination) close() error {
+	return d.file.Close()
+}
+
+// removeFileDestinations removes the files that the logs of the containers of a task are shipped to.
+func removeFileDestinations(dir, taskID string) error {
+	return os.RemoveAll(filepath.Join(dir, taskID))
+}
@@ -0,0 +1,93 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package logshipper
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileDestination(t *testing.T) {
+	dir := t.TempDir()
+	dest, err := newFileDestination(dir, "task-id", "container", defaultFileMaxSize, defaultFileMaxFiles)
+	require.NoError(t, err)
+
+	timestamp := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	require.NoError(t, dest.deliver(context.Background(), []*record{
+		{timestamp: timestamp, stream: streamStdout, message: "first"},
+		{timestamp: timestamp, stream: streamStderr, message: "second \"quoted\""},
+	}))
+	require.NoError(t, dest.close())
+
+	// The file is appended to when the destination is created again.
+	dest, err = newFileDestination(dir, "task-id", "container", defaultFileMaxSize, defaultFileMaxFiles)
+	require.NoError(t, err)
+	require.NoError(t, dest.deliver(context.Background(), []*record{
+		{timestamp: timestamp, stream: streamStdout, message: "third"},
+	}))
+	require.NoError(t, dest.close())
+
+	data, err := os.ReadFile(filepath.Join(dir, "task-id", "container.log"))
+	require.NoError(t, err)
+	assert.Equal(t,
+		`{"time":"2024-01-02T03:04:05.000000006Z","stream":"stdout","log":"first"}`+"\n"+
+			`{"time":"2024-01-02T03:04:05.000000006Z","stream":"stderr","log":"second \"quoted\""}`+"\n"+
+			`{"time":"2024-01-02T03:04:05.000000006Z","stream":"stdout","log":"third"}`+"\n",
+		string(data))
+}
+
+func TestFileDestinationRotation(t *testing.T) {
+	dir := t.TempDir()
+	r := &record{timestamp: time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC), stream: streamStdout, message: "line"}
+	line := `{"time":"2024-01-02T03:04:05.000000006Z","stream":"stdout","log":"line"}` + "\n"
+
+	// Each file holds two records, and two rotated files are kept.
+	dest, err := newFileDestination(dir, "task-id", "container", int64(2*len(line)), 3)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, dest.deliver(context.Background(), []*record{r, r}))
+	}
+	require.NoError(t, dest.close())
+
+	// The size of the file is known when the destination is created again.
+	dest, err = newFileDestination(dir, "task-id", "container", int64(2*len(line)), 3)
+	require.NoError(t, err)
+	require.NoError(t, dest.deliver(context.Background(), []*record{r}))
+	require.NoError(t, dest.close())
+
+	path := filepath.Join(dir, "task-id", "container.log")
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		data, err := os.ReadFile(name)
+		require.NoError(t, err)
+		expectedLines := 2
+		if name == path {
+			expectedLines = 1
+		}
+		assert.Equal(t, strings.Repeat(line, expectedLines), string(data), name)
+	}
+	assert.NoFileExists(t, path+".3")
+
+	require.NoError(t, removeFileDestinations(dir, "task-id"))
+	assert.NoDirExists(t, filepath.Join(dir, "task-id"))
+}
@@ -0,0 +1,261 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package logshipper
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/go-units"
+	"github.com/pkg/errors"
+)
+
+const (
+	// LogDriverName is the log driver of the containers whose logs are shipped by the agent. The containers are
+	// created with the json-file log driver instead, which the agent reads the logs from, so that docker logs keeps
+	// working for them.
+	LogDriverName = "awslogshipper"
+
+	jsonFileLogDriverName = "json-file"
+
+	// DestinationCloudWatch ships the logs to a log stream of CloudWatch Logs.
+	DestinationCloudWatch = "cloudwatch"
+	// DestinationFile ships the logs to a file on the host, under the directory set by ECS_LOG_SHIPPING_FILE_DIR.
+	DestinationFile = "file"
+	// DestinationOTLP ships the logs to an OpenTelemetry collector, with the OTLP/HTTP protocol.
+	DestinationOTLP = "otlp"
+
+	// BackpressureBlock stops reading the logs of a container while the buffer of its task is full. The logs are
+	// kept by the json-file log driver in the meantime.
+	BackpressureBlock = "block"
+	// BackpressureDrop drops the log records read while the buffer of the task is full.
+	BackpressureDrop = "drop"
+
+	destinationOption           = "destination"
+	backpressureOption          = "backpressure"
+	flushIntervalOption         = "flush-interval"
+	cloudWatchGroupOption       = "awslogs-group"
+	cloudWatchStreamOption      = "awslogs-stream-prefix"
+	cloudWatchRegionOption      = "awslogs-region"
+	cloudWatchCreateGroupOption = "awslogs-create-group"
+	otlpEndpointOption          = "otlp-endpoint"
+	otlpHeadersOption           = "otlp-headers"
+	fileMaxSizeOption           = "file-max-size"
+	fileMaxFilesOption          = "file-max-files"
+	defaultFlushInterval        = 5 * time.Second
+	minimumFlushInterval        = 100 * time.Millisecond
+	maximumFlushInterval        = time.Minute
+	defaultFileMaxSize          = 10 * units.MiB
+	minimumFileMaxSize          = units.MiB
+	defaultFileMaxFiles         = 5
+	otlpHeaderSeparator         = ","
+	otlpHeaderValueSeparator    = "="
+	cloudWatchStreamSeparator   = "/"
+)
+
+// jsonFileOptions are the options that are passed on to the json-file log driver, which keeps the logs on the host.
+var jsonFileOptions = map[string]bool{
+	"max-size":        true,
+	"max-file":        true,
+	"compress":        true,
+	"labels":          true,
+	"labels-regex":    true,
+	"env":             true,
+	"env-regex":       true,
+	"tag":             true,
+	"mode":            true,
+	"max-buffer-size": true,
+}
+
+// Options are the log shipping options of a container, set as the options of the awslogshipper log driver.
+type Options struct {
+	// Destination is where the logs are shipped to.
+	Destination string
+	// Backpressure is what happens to the logs read while the buffer of the task is full.
+	Backpressure string
+	// FlushInterval is how long the log records are batched for before they are delivered.
+	FlushInterval time.Duration
+
+	// CloudWatchGroup is the log group that the logs are shipped to.
+	CloudWatchGroup string
+	// CloudWatchStreamPrefix is the prefix of the log stream that the logs are shipped to. The log stream is named
+	// prefix/container-name/task-id, as it is by the awslogs log driver.
+	CloudWatchStreamPrefix string
+	// CloudWatchRegion is the region of the log group. It defaults to the region of the container instance.
+	CloudWatchRegion string
+	// CloudWatchCreateGroup specifies whether the log group is created if it does not exist.
+	CloudWatchCreateGroup bool
+
+	// FileMaxSize is the size that the file the logs are shipped to is rotated at, set with the units of the
+	// max-size option of the json-file log driver, e.g. 10m.
+	FileMaxSize int64
+	// FileMaxFiles is the number of files kept for the logs of a container, including the file being written to.
+	FileMaxFiles int
+
+	// OTLPEndpoint is the URL that the OTLP/HTTP requests are sent to, e.g. http://localhost:4318/v1/logs.
+	OTLPEndpoint string
+	// OTLPHeaders are the headers added to the OTLP/HTTP requests, set as key1=value1,key2=value2. They are
+	// typically set as secret options of the log driver, since they usually carry credentials.
+	OTLPHeaders map[string]string
+}
+
+// ParseOptions parses and validates the options of the awslogshipper log driver of a container.
+func ParseOptions(logOptions map[string]string) (*Options, error) {
+	options := &Options{
+		Destination:   logOptions[destinationOption],
+		Backpressure:  BackpressureBlock,
+		FlushInterval: defaultFlushInterval,
+	}
+
+	if backpressure, ok := logOptions[backpressureOption]; ok {
+		if backpressure != BackpressureBlock && backpressure != BackpressureDrop {
+			return nil, errors.Errorf("invalid value %s for log option %s, must be %s or %s", backpressure,
+				backpressureOption, BackpressureBlock, BackpressureDrop)
+		}
+		options.Backpressure = backpressure
+	}
+
+	if flushInterval, ok := logOptions[flushIntervalOption]; ok {
+		interval, err := time.ParseDuration(flushInterval)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid value %s for log option %s", flushInterval, flushIntervalOption)
+		}
+		if interval < minimumFlushInterval || interval > maximumFlushInterval {
+			return nil, errors.Errorf("log option %s must be between %s and %s", flushIntervalOption,
+				minimumFlushInterval, maximumFlushInterval)
+		}
+		options.FlushInterval = interval
+	}
+
+	switch options.Destination {
+	case DestinationCloudWatch:
+		options.CloudWatchGroup = logOptions[cloudWatchGroupOption]
+		if options.CloudWatchGroup == "" {
+			return nil, errors.Errorf("log option %s is required for destination %s", cloudWatchGroupOption,
+				DestinationCloudWatch)
+		}
+		options.CloudWatchStreamPrefix = logOptions[cloudWatchStreamOption]
+		options.CloudWatchRegion = logOptions[cloudWatchRegionOption]
+		options.CloudWatchCreateGroup = logOptions[cloudWatchCreateGroupOption] == "true"
+	case DestinationFile:
+		options.FileMaxSize = defaultFileMaxSize
+		if maxSize, ok := logOptions[fileMaxSizeOption]; ok {
+			size, err := units.RAMInBytes(maxSize)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid value %s for log option %s", maxSize, fileMaxSizeOption)
+			}
+			if size < minimumFileMaxSize {
+				return nil, errors.Errorf("log option %s must be at least %s", fileMaxSizeOption,
+					units.BytesSize(minimumFileMaxSize))
+			}
+			options.FileMaxSize = size
+		}
+		options.FileMaxFiles = defaultFileMaxFiles
+		if maxFiles, ok := logOptions[fileMaxFilesOption]; ok {
+			files, err := strconv.Atoi(maxFiles)
+			if err != nil || files < 1 {
+				return nil, errors.Errorf("invalid value %s for log option %s, must be a positive integer",
+					maxFiles, fileMaxFilesOption)
+			}
+			options.FileMaxFiles = files
+		}
+	case DestinationOTLP:
+		endpoint, err := url.Parse(logOptions[otlpEndpointOption])
+		if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+			return nil, errors.Errorf("log option %s must be an http or https URL for destination %s",
+				otlpEndpointOption, DestinationOTLP)
+		}
+		options.OTLPEndpoint = endpoint.String()
+		headers, err := parseOTLPHeaders(logOptions[otlpHeadersOption])
+		if err != nil {
+			return nil, err
+		}
+		options.OTLPHeaders = headers
+	case "":
+		return nil, errors.Errorf("log option %s is required", destinationOption)
+	default:
+		return nil, errors.Errorf("invalid value %s for log option %s, must be %s, %s or %s", options.Destination,
+			destinationOption, DestinationCloudWatch, DestinationFile, DestinationOTLP)
+	}
+
+	for key := range logOptions {
+		if !jsonFileOptions[key] && !options.isOption(key) {
+			return nil, errors.Errorf("log option %s is not supported with destination %s", key,
+				options.Destination)
+		}
+	}
+	return options, nil
+}
+
+// isOption returns whether the key is a log shipping option of the destination of the options.
+func (options *Options) isOption(key string) bool {
+	switch key {
+	case destinationOption, backpressureOption, flushIntervalOption:
+		return true
+	case cloudWatchGroupOption, cloudWatchStreamOption, cloudWatchRegionOption, cloudWatchCreateGroupOption:
+		return options.Destination == DestinationCloudWatch
+	case otlpEndpointOption, otlpHeadersOption:
+		return options.Destination == DestinationOTLP
+	case fileMaxSizeOption, fileMaxFilesOption:
+		return options.Destination == DestinationFile
+	}
+	return false
+}
+
+// CloudWatchStream returns the name of the log stream that the logs of a container are shipped to.
+func (options *Options) CloudWatchStream(containerName, taskID string) string {
+	if options.CloudWatchStreamPrefix == "" {
+		return containerName + cloudWatchStreamSeparator + taskID
+	}
+	return strings.Join([]string{options.CloudWatchStreamPrefix, containerName, taskID}, cloudWatchStreamSeparator)
+}
+
+func parseOTLPHeaders(value string) (map[string]string, error) {
+	headers := make(map[string]string)
+	if value == "" {
+		return headers, nil
+	}
+	for _, header := range strings.Split(value, otlpHeaderSeparator) {
+		name, headerValue, ok := strings.Cut(header, otlpHeaderValueSeparator)
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, errors.Errorf("invalid value for log option %s, must be a list of key=value",
+				otlpHeadersOption)
+		}
+		headers[name] = strings.TrimSpace(headerValue)
+	}
+	return headers, nil
+}
+
+// JSONFileLogConfig validates the log config of a container that uses the awslogshipper log driver, and returns the
+// json-file log config that the container is created with. The options of the json-file log driver, such as
+// max-size and max-file, are passed on to it.
+func JSONFileLogConfig(logConfig dockercontainer.LogConfig) (dockercontainer.LogConfig, error) {
+	if _, err := ParseOptions(logConfig.Config); err != nil {
+		return dockercontainer.LogConfig{}, err
+	}
+	jsonFileConfig := make(map[string]string)
+	for key, value := range logConfig.Config {
+		if jsonFileOptions[key] {
+			jsonFileConfig[key] = value
+		}
+	}
+	return dockercontainer.LogConfig{
+		Type:   jsonFileLogDriverName,
+		Config: jsonFileConfig,
+	}, nil
+}
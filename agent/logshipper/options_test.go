@@ -0,0 +1,242 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package logshipper
+
+import (
+	"testing"
+	"time"
+
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOptions(t *testing.T) {
+	testCases := []struct {
+		name            string
+		logOptions      map[string]string
+		expectedOptions *Options
+		expectedError   bool
+	}{
+		{
+			name: "cloudwatch with defaults",
+			logOptions: map[string]string{
+				"destination":   "cloudwatch",
+				"awslogs-group": "group",
+			},
+			expectedOptions: &Options{
+				Destination:     DestinationCloudWatch,
+				Backpressure:    BackpressureBlock,
+				FlushInterval:   defaultFlushInterval,
+				CloudWatchGroup: "group",
+			},
+		},
+		{
+			name: "cloudwatch with all options",
+			logOptions: map[string]string{
+				"destination":           "cloudwatch",
+				"backpressure":          "drop",
+				"flush-interval":        "1s",
+				"awslogs-group":         "group",
+				"awslogs-stream-prefix": "prefix",
+				"awslogs-region":        "us-west-2",
+				"awslogs-create-group":  "true",
+				"max-size":              "10m",
+			},
+			expectedOptions: &Options{
+				Destination:            DestinationCloudWatch,
+				Backpressure:           BackpressureDrop,
+				FlushInterval:          time.Second,
+				CloudWatchGroup:        "group",
+				CloudWatchStreamPrefix: "prefix",
+				CloudWatchRegion:       "us-west-2",
+				CloudWatchCreateGroup:  true,
+			},
+		},
+		{
+			name:       "file",
+			logOptions: map[string]string{"destination": "file"},
+			expectedOptions: &Options{
+				Destination:   DestinationFile,
+				Backpressure:  BackpressureBlock,
+				FlushInterval: defaultFlushInterval,
+				FileMaxSize:   10 * 1024 * 1024,
+				FileMaxFiles:  5,
+			},
+		},
+		{
+			name: "file with rotation",
+			logOptions: map[string]string{
+				"destination":    "file",
+				"file-max-size":  "100m",
+				"file-max-files": "2",
+			},
+			expectedOptions: &Options{
+				Destination:   DestinationFile,
+				Backpressure:  BackpressureBlock,
+				FlushInterval: defaultFlushInterval,
+				FileMaxSize:   100 * 1024 * 1024,
+				FileMaxFiles:  2,
+			},
+		},
+		{
+			name: "otlp with headers",
+			logOptions: map[string]string{
+				"destination":   "otlp",
+				"otlp-endpoint": "http://localhost:4318/v1/logs",
+				"otlp-headers":  "authorization=Bearer token, x-tenant = tenant",
+			},
+			expectedOptions: &Options{
+				Destination:   DestinationOTLP,
+				Backpressure:  BackpressureBlock,
+				FlushInterval: defaultFlushInterval,
+				OTLPEndpoint:  "http://localhost:4318/v1/logs",
+				OTLPHeaders: map[string]string{
+					"authorization": "Bearer token",
+					"x-tenant":      "tenant",
+				},
+			},
+		},
+		{
+			name:          "missing destination",
+			logOptions:    map[string]string{},
+			expectedError: true,
+		},
+		{
+			name:          "unknown destination",
+			logOptions:    map[string]string{"destination": "kinesis"},
+			expectedError: true,
+		},
+		{
+			name:          "cloudwatch without group",
+			logOptions:    map[string]string{"destination": "cloudwatch"},
+			expectedError: true,
+		},
+		{
+			name:          "otlp without endpoint",
+			logOptions:    map[string]string{"destination": "otlp"},
+			expectedError: true,
+		},
+		{
+			name: "otlp with invalid headers",
+			logOptions: map[string]string{
+				"destination":   "otlp",
+				"otlp-endpoint": "https://collector:4318/v1/logs",
+				"otlp-headers":  "authorization",
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid backpressure",
+			logOptions: map[string]string{
+				"destination":  "file",
+				"backpressure": "wait",
+			},
+			expectedError: true,
+		},
+		{
+			name: "flush interval out of bounds",
+			logOptions: map[string]string{
+				"destination":    "file",
+				"flush-interval": "10ms",
+			},
+			expectedError: true,
+		},
+		{
+			name: "file max size too small",
+			logOptions: map[string]string{
+				"destination":   "file",
+				"file-max-size": "10k",
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid file max files",
+			logOptions: map[string]string{
+				"destination":    "file",
+				"file-max-files": "0",
+			},
+			expectedError: true,
+		},
+		{
+			name: "option of another destination",
+			logOptions: map[string]string{
+				"destination":   "file",
+				"awslogs-group": "group",
+			},
+			expectedError: true,
+		},
+		{
+			name: "unknown option",
+			logOptions: map[string]string{
+				"destination": "file",
+				"unknown":     "value",
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			options, err := ParseOptions(tc.logOptions)
+			if tc.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tc.expectedOptions.OTLPHeaders == nil && options.OTLPHeaders != nil {
+				assert.Empty(t, options.OTLPHeaders)
+				options.OTLPHeaders = nil
+			}
+			assert.Equal(t, tc.expectedOptions, options)
+		})
+	}
+}
+
+func TestCloudWatchStream(t *testing.T) {
+	options := &Options{}
+	assert.Equal(t, "container/task-id", options.CloudWatchStream("container", "task-id"))
+	options.CloudWatchStreamPrefix = "prefix"
+	assert.Equal(t, "prefix/container/task-id", options.CloudWatchStream("container", "task-id"))
+}
+
+func TestJSONFileLogConfig(t *testing.T) {
+	logConfig, err := JSONFileLogConfig(dockercontainer.LogConfig{
+		Type: LogDriverName,
+		Config: map[string]string{
+			"destination":   "otlp",
+			"otlp-endpoint": "http://localhost:4318/v1/logs",
+			"otlp-headers":  "authorization=secret",
+			"max-size":      "10m",
+			"max-file":      "3",
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, dockercontainer.LogConfig{
+		Type: "json-file",
+		Config: map[string]string{
+			"max-size": "10m",
+			"max-file": "3",
+		},
+	}, logConfig)
+
+	_, err = JSONFileLogConfig(dockercontainer.LogConfig{
+		Type:   LogDriverName,
+		Config: map[string]string{"destination": "cloudwatch"},
+	})
+	assert.Error(t, err)
+}
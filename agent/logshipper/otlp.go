@@ -0,0 +1,191 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package logshipper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	agentversion "github.com/aws/amazon-ecs-agent/agent/version"
+	apierrors "github.com/aws/amazon-ecs-agent/ecs-agent/api/errors"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/httpclient"
+)
+
+const (
+	otlpRoundtripTimeout   = 30 * time.Second
+	otlpMaximumBatchBytes  = 4 * 1024 * 1024
+	otlpMaximumBatchRecord = 10000
+	otlpScopeName          = "amazon-ecs-agent"
+	otlpStreamAttribute    = "log.iostream"
+	otlpContentType        = "application/json"
+	// otlpMaximumErrorBodyBytes is how much of the body of a failed response is read into the error.
+	otlpMaximumErrorBodyBytes = 1024
+
+	// These are the severity numbers of the OpenTelemetry log data model, see
+	// https://opentelemetry.io/docs/specs/otel/logs/data-model/#field-severitynumber
+	otlpSeverityInfo  = 9
+	otlpSeverityError = 17
+)
+
+// These are the types of the OTLP/HTTP JSON encoding of an ExportLogsServiceRequest, see
+// https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding
+type otlpLogsRequest struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+type otlpResourceLogs struct {
+	Resource  otlpResource    `json:"resource"`
+	ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeLogs struct {
+	Scope      otlpScope       `json:"scope"`
+	LogRecords []otlpLogRecord `json:"logRecords"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano         string          `json:"timeUnixNano"`
+	ObservedTimeUnixNano string          `json:"observedTimeUnixNano"`
+	SeverityNumber       int             `json:"severityNumber"`
+	Body                 otlpValue       `json:"body"`
+	Attributes           []otlpAttribute `json:"attributes"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+// otlpDestination ships the logs of a container to an OpenTelemetry collector with the OTLP/HTTP protocol, with
+// the JSON encoding.
+type otlpDestination struct {
+	client   *http.Client
+	endpoint string
+	headers  map[string]string
+	resource otlpResource
+}
+
+// newOTLPDestination creates an OTLP destination whose records have the attributes of the resource, which
+// identify the task and the container.
+func newOTLPDestination(options *Options, resourceAttributes map[string]string) *otlpDestination {
+	keys := make([]string, 0, len(resourceAttributes))
+	for key := range resourceAttributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	resource := otlpResource{Attributes: make([]otlpAttribute, 0, len(keys))}
+	for _, key := range keys {
+		resource.Attributes = append(resource.Attributes, otlpAttribute{
+			Key:   key,
+			Value: otlpValue{StringValue: resourceAttributes[key]},
+		})
+	}
+
+	return &otlpDestination{
+		client:   httpclient.New(otlpRoundtripTimeout, false, agentversion.String(), config.OSType),
+		endpoint: options.OTLPEndpoint,
+		headers:  options.OTLPHeaders,
+		resource: resource,
+	}
+}
+
+func (d *otlpDestination) limits() batchLimits {
+	return batchLimits{records: otlpMaximumBatchRecord, bytes: otlpMaximumBatchBytes}
+}
+
+func (d *otlpDestination) deliver(ctx context.Context, records []*record) error {
+	observed := strconv.FormatInt(time.Now().UnixNano(), 10)
+	logRecords := make([]otlpLogRecord, 0, len(records))
+	for _, r := range records {
+		severity := otlpSeverityInfo
+		if r.stream == streamStderr {
+			severity = otlpSeverityError
+		}
+		logRecords = append(logRecords, otlpLogRecord{
+			TimeUnixNano:         strconv.FormatInt(r.timestamp.UnixNano(), 10),
+			ObservedTimeUnixNano: observed,
+			SeverityNumber:       severity,
+			Body:                 otlpValue{StringValue: r.message},
+			Attributes: []otlpAttribute{
+				{Key: otlpStreamAttribute, Value: otlpValue{StringValue: r.stream}},
+			},
+		})
+	}
+	body, err := json.Marshal(otlpLogsRequest{
+		ResourceLogs: []otlpResourceLogs{{
+			Resource: d.resource,
+			ScopeLogs: []otlpScopeLogs{{
+				Scope:      otlpScope{Name: otlpScopeName, Version: agentversion.Version},
+				LogRecords: logRecords,
+			}},
+		}},
+	})
+	if err != nil {
+		return apierrors.NewRetriableError(apierrors.NewRetriable(false), err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return apierrors.NewRetriableError(apierrors.NewRetriable(false), err)
+	}
+	req.Header.Set("Content-Type", otlpContentType)
+	for name, value := range d.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, otlpMaximumErrorBodyBytes))
+	err = fmt.Errorf("OTLP endpoint %s responded with status %d: %s", d.endpoint, resp.StatusCode, message)
+	switch resp.StatusCode {
+	// These are the status codes that the OTLP/HTTP specification asks clients to retry on.
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return err
+	}
+	return apierrors.NewRetriableError(apierrors.NewRetriable(false), err)
+}
+
+func (d *otlpDestination) close() error {
+	d.client.CloseIdleConnections()
+	return nil
+}
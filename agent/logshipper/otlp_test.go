@@ -0,0 +1,107 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package logshipper
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	apierrors "github.com/aws/amazon-ecs-agent/ecs-agent/api/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOTLPDestinationDeliver(t *testing.T) {
+	var request otlpLogsRequest
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(body, &request))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	dest := newOTLPDestination(&Options{
+		OTLPEndpoint: server.URL,
+		OTLPHeaders:  map[string]string{"Authorization": "Bearer token"},
+	}, map[string]string{
+		"container.name":   "container",
+		"aws.ecs.task.arn": "task-arn",
+	})
+	defer dest.close()
+
+	timestamp := time.Unix(1700000000, 5)
+	require.NoError(t, dest.deliver(context.Background(), []*record{
+		{timestamp: timestamp, stream: streamStdout, message: "out"},
+		{timestamp: timestamp, stream: streamStderr, message: "err"},
+	}))
+
+	assert.Equal(t, "Bearer token", headers.Get("Authorization"))
+	assert.Equal(t, "application/json", headers.Get("Content-Type"))
+	require.Len(t, request.ResourceLogs, 1)
+	assert.Equal(t, []otlpAttribute{
+		{Key: "aws.ecs.task.arn", Value: otlpValue{StringValue: "task-arn"}},
+		{Key: "container.name", Value: otlpValue{StringValue: "container"}},
+	}, request.ResourceLogs[0].Resource.Attributes)
+	require.Len(t, request.ResourceLogs[0].ScopeLogs, 1)
+	logRecords := request.ResourceLogs[0].ScopeLogs[0].LogRecords
+	require.Len(t, logRecords, 2)
+	assert.Equal(t, "1700000000000000005", logRecords[0].TimeUnixNano)
+	assert.Equal(t, "out", logRecords[0].Body.StringValue)
+	assert.Equal(t, otlpSeverityInfo, logRecords[0].SeverityNumber)
+	assert.Equal(t, "err", logRecords[1].Body.StringValue)
+	assert.Equal(t, otlpSeverityError, logRecords[1].SeverityNumber)
+	assert.Equal(t, []otlpAttribute{{Key: "log.iostream", Value: otlpValue{StringValue: "stderr"}}},
+		logRecords[1].Attributes)
+}
+
+func TestOTLPDestinationErrors(t *testing.T) {
+	testCases := []struct {
+		name      string
+		status    int
+		retriable bool
+	}{
+		{name: "throttled", status: http.StatusTooManyRequests, retriable: true},
+		{name: "unavailable", status: http.StatusServiceUnavailable, retriable: true},
+		{name: "bad request", status: http.StatusBadRequest, retriable: false},
+		{name: "internal error", status: http.StatusInternalServerError, retriable: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+			dest := newOTLPDestination(&Options{OTLPEndpoint: server.URL}, nil)
+			defer dest.close()
+
+			err := dest.deliver(context.Background(), []*record{{timestamp: time.Now(), message: "log"}})
+			require.Error(t, err)
+			retriable, ok := err.(apierrors.Retriable)
+			assert.Equal(t, !tc.retriable, ok && !retriable.Retry())
+		})
+	}
+}
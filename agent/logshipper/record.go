@@ -0,0 +1,87 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package logshipper
+
+import (
+	"bytes"
+	"time"
+)
+
+const (
+	streamStdout = "stdout"
+	streamStderr = "stderr"
+)
+
+// record is a line of the logs of a container.
+type record struct {
+	timestamp time.Time
+	stream    string
+	message   string
+}
+
+// size is the number of bytes of the record counted against the buffer of the task.
+func (r *record) size() int64 {
+	return int64(len(r.message))
+}
+
+// parseRecord parses a line of the logs returned by the docker API with timestamps, which starts with the
+// RFC3339Nano timestamp of the line followed by a space.
+func parseRecord(stream string, line []byte) *record {
+	if timestamp, message, ok := bytes.Cut(line, []byte(" ")); ok {
+		if t, err := time.Parse(time.RFC3339Nano, string(timestamp)); err == nil {
+			return &record{timestamp: t, stream: stream, message: string(message)}
+		}
+	}
+	return &record{timestamp: time.Now(), stream: stream, message: string(line)}
+}
+
+// lineWriter splits what is written to it into lines, and emits a record for each of them.
+type lineWriter struct {
+	stream  string
+	partial []byte
+	emit    func(*record) error
+}
+
+func newLineWriter(stream string, emit func(*record) error) *lineWriter {
+	return &lineWriter{stream: stream, emit: emit}
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		line := bytes.TrimSuffix(w.partial[:i], []byte("\r"))
+		w.partial = w.partial[i+1:]
+		if err := w.emit(parseRecord(w.stream, line)); err != nil {
+			return len(p), err
+		}
+	}
+	if len(w.partial) == 0 {
+		w.partial = nil
+	}
+	return len(p), nil
+}
+
+// flush emits the last line if it was not terminated by a newline.
+func (w *lineWriter) flush() error {
+	if len(w.partial) == 0 {
+		return nil
+	}
+	line := w.partial
+	w.partial = nil
+	return w.emit(parseRecord(w.stream, line))
+}
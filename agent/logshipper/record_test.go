@@ -0,0 +1,62 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package logshipper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRecord(t *testing.T) {
+	r := parseRecord(streamStdout, []byte("2024-01-02T03:04:05.123456789Z hello world"))
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 123456789, time.UTC), r.timestamp)
+	assert.Equal(t, streamStdout, r.stream)
+	assert.Equal(t, "hello world", r.message)
+	assert.Equal(t, int64(len("hello world")), r.size())
+
+	// A line without a timestamp is kept whole, with the time it was read.
+	before := time.Now()
+	r = parseRecord(streamStderr, []byte("no timestamp"))
+	assert.Equal(t, "no timestamp", r.message)
+	assert.False(t, r.timestamp.Before(before))
+}
+
+func TestLineWriter(t *testing.T) {
+	var messages []string
+	writer := newLineWriter(streamStdout, func(r *record) error {
+		messages = append(messages, r.message)
+		return nil
+	})
+
+	_, err := writer.Write([]byte("2024-01-02T03:04:05Z first\n2024-01-02T03:04:06Z sec"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first"}, messages)
+
+	_, err = writer.Write([]byte("ond\r\n2024-01-02T03:04:07Z third"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first", "second"}, messages)
+
+	require.NoError(t, writer.flush())
+	assert.Equal(t, []string{"first", "second", "third"}, messages)
+
+	// Flushing again emits nothing.
+	require.NoError(t, writer.flush())
+	assert.Len(t, messages, 3)
+}
@@ -0,0 +1,409 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package logshipper ships the logs of the containers that use the awslogshipper log driver from the agent, to
+// CloudWatch Logs, to a file on the host or to an OpenTelemetry collector, without a log router container.
+package logshipper
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
+	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/eventstream"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/pkg/errors"
+)
+
+const (
+	containerChangeHandler = "LogShipperContainerChangeHandler"
+	// cleanupInterval is how often the checkpoints and the metrics of the tasks that are no longer managed by the
+	// agent are removed.
+	cleanupInterval = time.Minute
+)
+
+// Shipper ships the logs of the containers that use the awslogshipper log driver. It starts reading the logs of a
+// container when it starts running, and keeps shipping them until the container stops. The records of a task that
+// are read but not delivered yet are bounded by ECS_LOG_SHIPPING_TASK_BUFFER_LIMIT_BYTES.
+type Shipper struct {
+	cfg                        *config.Config
+	client                     dockerapi.DockerClient
+	state                      dockerstate.TaskEngineState
+	credentialsManager         credentials.Manager
+	containerChangeEventStream *eventstream.EventStream
+	newCloudWatchClient        cloudWatchClientCreator
+	checkpoints                *checkpoints
+
+	ctx   context.Context
+	lock  sync.Mutex
+	tasks map[string]*taskShipper
+}
+
+// taskShipper holds the containers of a task whose logs are shipped, and the buffer they share.
+type taskShipper struct {
+	taskID     string
+	buffer     *taskBuffer
+	containers map[string]*containerEntry
+}
+
+// containerEntry is a container whose logs are shipped, or were shipped. It is kept until the task is no longer
+// managed by the agent, so that the logs of a container that is restarted are shipped from its checkpoint.
+type containerEntry struct {
+	running bool
+	stats   *containerStats
+}
+
+// NewShipper creates the log shipper of the agent.
+func NewShipper(cfg *config.Config, client dockerapi.DockerClient, state dockerstate.TaskEngineState,
+	credentialsManager credentials.Manager, containerChangeEventStream *eventstream.EventStream) *Shipper {
+	return &Shipper{
+		cfg:                        cfg,
+		client:                     client,
+		state:                      state,
+		credentialsManager:         credentialsManager,
+		containerChangeEventStream: containerChangeEventStream,
+		newCloudWatchClient:        newCloudWatchClient,
+		checkpoints:                newCheckpoints(cfg.DataDir),
+		tasks:                      make(map[string]*taskShipper),
+	}
+}
+
+// Init starts shipping the logs of the containers that are running, and of the containers that start running
+// from now on, until the context is done.
+func (s *Shipper) Init(ctx context.Context) error {
+	s.ctx = ctx
+	logger.Info("Initializing log shipper")
+
+	err := s.containerChangeEventStream.Subscribe(containerChangeHandler, s.handleContainerChangeEvents)
+	if err != nil {
+		return errors.Wrap(err, "unable to subscribe to container change event stream")
+	}
+	s.synchronizeState()
+
+	go s.cleanup(ctx)
+	return nil
+}
+
+// synchronizeState ships the logs of the containers of the tasks managed by the agent when it starts. The logs of
+// the containers that stopped while the agent was not running are shipped too, from their checkpoints if they have
+// one, or from the start if the agent stopped before any of their logs were delivered.
+func (s *Shipper) synchronizeState() {
+	taskIDs := make(map[string]bool)
+	for _, task := range s.state.AllTasks() {
+		taskIDs[task.GetID()] = true
+		containers, ok := s.state.ContainerMapByArn(task.Arn)
+		if !ok {
+			continue
+		}
+		for _, dockerContainer := range containers {
+			container := dockerContainer.Container
+			if dockerContainer.DockerID == "" || container.GetLogDriver() != LogDriverName {
+				continue
+			}
+			status := container.GetKnownStatus()
+			if status == apicontainerstatus.ContainerRunning || status.Terminal() {
+				s.startContainer(dockerContainer.DockerID)
+			}
+		}
+	}
+
+	checkpointTaskIDs, err := s.checkpoints.taskIDs()
+	if err != nil {
+		logger.Warn("Unable to list log shipping checkpoints", logger.Fields{
+			field.Error: err,
+		})
+	}
+	for _, taskID := range checkpointTaskIDs {
+		if !taskIDs[taskID] {
+			s.removeTaskFiles(taskID)
+		}
+	}
+}
+
+// handleContainerChangeEvents starts shipping the logs of the containers that start running.
+func (s *Shipper) handleContainerChangeEvents(events ...interface{}) error {
+	for _, event := range events {
+		containerChangeEvent, ok := event.(dockerapi.DockerContainerChangeEvent)
+		if !ok {
+			return fmt.Errorf("unexpected event received, expected docker container change event")
+		}
+		if containerChangeEvent.Status == apicontainerstatus.ContainerRunning {
+			s.startContainer(containerChangeEvent.DockerID)
+		}
+	}
+	return nil
+}
+
+// startContainer starts shipping the logs of a container, unless they are already being shipped.
+func (s *Shipper) startContainer(dockerID string) {
+	task, ok := s.state.TaskByID(dockerID)
+	if !ok {
+		return
+	}
+	dockerContainer, ok := s.state.ContainerByID(dockerID)
+	if !ok || dockerContainer.Container.GetLogDriver() != LogDriverName {
+		return
+	}
+	container := dockerContainer.Container
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	ts, ok := s.tasks[task.Arn]
+	if !ok {
+		ts = &taskShipper{
+			taskID:     task.GetID(),
+			buffer:     newTaskBuffer(s.cfg.LogShippingTaskBufferLimitBytes),
+			containers: make(map[string]*containerEntry),
+		}
+		s.tasks[task.Arn] = ts
+	}
+	entry, ok := ts.containers[dockerID]
+	if !ok {
+		entry = &containerEntry{
+			stats: &containerStats{stats: ContainerStats{
+				ContainerName: container.Name,
+				DockerID:      dockerID,
+			}},
+		}
+		ts.containers[dockerID] = entry
+	}
+	if entry.running {
+		return
+	}
+	entry.running = true
+	entry.stats.setShipping(true)
+
+	go func() {
+		s.ship(task, container, dockerID, ts.buffer, entry.stats)
+
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		entry.running = false
+		entry.stats.setShipping(false)
+	}()
+}
+
+// ship ships the logs of a container until it stops.
+func (s *Shipper) ship(task *apitask.Task, container *apicontainer.Container, dockerID string, buffer *taskBuffer,
+	stats *containerStats) {
+	fields := logger.Fields{
+		field.TaskARN:   task.Arn,
+		field.Container: container.Name,
+		field.DockerId:  dockerID,
+	}
+	shipper, err := s.newContainerShipper(task, container, dockerID, buffer, stats)
+	if err != nil {
+		logger.Error("Unable to ship container logs", fields, logger.Fields{
+			field.Error: err,
+		})
+		return
+	}
+
+	logger.Info("Shipping container logs", fields, logger.Fields{
+		"destination": shipper.options.Destination,
+		"checkpoint":  shipper.checkpoint,
+	})
+	shipper.run(s.ctx)
+	logger.Info("Stopped shipping container logs", fields)
+}
+
+func (s *Shipper) newContainerShipper(task *apitask.Task, container *apicontainer.Container, dockerID string,
+	buffer *taskBuffer, stats *containerStats) (*containerShipper, error) {
+	logOptions := make(map[string]string)
+	for key, value := range container.GetLogOptions() {
+		logOptions[key] = value
+	}
+	secretOptions, err := task.GetLogDriverSecretOptions(container)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get secret log options")
+	}
+	for key, value := range secretOptions {
+		logOptions[key] = value
+	}
+	options, err := ParseOptions(logOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	inspected, err := s.client.InspectContainer(s.ctx, dockerID, dockerclient.InspectContainerTimeout)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to inspect container")
+	}
+	tty := inspected.Config != nil && inspected.Config.Tty
+
+	checkpoint, err := s.checkpoints.read(task.GetID(), container.Name)
+	if err != nil {
+		// The logs are shipped from the start rather than not at all.
+		logger.Warn("Unable to read log shipping checkpoint", logger.Fields{
+			field.TaskARN:   task.Arn,
+			field.Container: container.Name,
+			field.Error:     err,
+		})
+	}
+
+	dest, err := s.newDestination(task, container, dockerID, options)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.lock.Lock()
+	stats.stats.Destination = options.Destination
+	stats.lock.Unlock()
+
+	return &containerShipper{
+		taskID:        task.GetID(),
+		containerName: container.Name,
+		dockerID:      dockerID,
+		options:       options,
+		dest:          dest,
+		tty:           tty,
+		client:        s.client,
+		buffer:        buffer,
+		checkpoints:   s.checkpoints,
+		stats:         stats,
+		checkpoint:    checkpoint,
+	}, nil
+}
+
+func (s *Shipper) newDestination(task *apitask.Task, container *apicontainer.Container, dockerID string,
+	options *Options) (destination, error) {
+	switch options.Destination {
+	case DestinationCloudWatch:
+		region := options.CloudWatchRegion
+		if region == "" {
+			region = s.cfg.AWSRegion
+		}
+		var credentialsProvider aws.CredentialsProvider
+		if credentialsID := task.GetExecutionCredentialsID(); credentialsID != "" {
+			credentialsProvider = &executionRoleCredentialsProvider{
+				credentialsManager: s.credentialsManager,
+				credentialsID:      credentialsID,
+			}
+		}
+		client, err := s.newCloudWatchClient(region, credentialsProvider, s.cfg.InstanceIPCompatibility)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to create CloudWatch Logs client")
+		}
+		return newCloudWatchDestination(client, options, container.Name, task.GetID()), nil
+	case DestinationFile:
+		return newFileDestination(s.cfg.LogShippingFileDir, task.GetID(), container.Name, options.FileMaxSize,
+			options.FileMaxFiles)
+	case DestinationOTLP:
+		return newOTLPDestination(options, map[string]string{
+			"cloud.provider":        "aws",
+			"cloud.platform":        "aws_ecs",
+			"cloud.region":          s.cfg.AWSRegion,
+			"aws.ecs.task.arn":      task.Arn,
+			"aws.ecs.task.family":   task.Family,
+			"aws.ecs.task.revision": task.Version,
+			"aws.ecs.launchtype":    "ec2",
+			"container.name":        container.Name,
+			"container.id":          dockerID,
+		}), nil
+	}
+	return nil, errors.Errorf("unsupported log shipping destination %s", options.Destination)
+}
+
+// cleanup periodically removes the tasks that are no longer managed by the agent, along with their checkpoints and
+// the files their logs were shipped to.
+func (s *Shipper) cleanup(ctx context.Context) {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.removeStoppedTasks()
+		case <-ctx.Done():
+			s.containerChangeEventStream.Unsubscribe(containerChangeHandler)
+			return
+		}
+	}
+}
+
+func (s *Shipper) removeStoppedTasks() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for taskARN, ts := range s.tasks {
+		if _, ok := s.state.TaskByArn(taskARN); ok {
+			continue
+		}
+		running := false
+		for _, entry := range ts.containers {
+			running = running || entry.running
+		}
+		if running {
+			continue
+		}
+		delete(s.tasks, taskARN)
+		s.removeTaskFiles(ts.taskID)
+	}
+}
+
+// removeTaskFiles removes the checkpoints of the containers of a task and the files their logs were shipped to.
+func (s *Shipper) removeTaskFiles(taskID string) {
+	if err := s.checkpoints.removeTask(taskID); err != nil {
+		logger.Warn("Unable to remove log shipping checkpoints", logger.Fields{
+			field.TaskID: taskID,
+			field.Error:  err,
+		})
+	}
+	if err := removeFileDestinations(s.cfg.LogShippingFileDir, taskID); err != nil {
+		logger.Warn("Unable to remove shipped log files", logger.Fields{
+			field.TaskID: taskID,
+			field.Error:  err,
+		})
+	}
+}
+
+// Stats returns the log shipping metrics of the tasks, sorted by task ARN.
+func (s *Shipper) Stats() []TaskStats {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	stats := make([]TaskStats, 0, len(s.tasks))
+	for taskARN, ts := range s.tasks {
+		used, limit := ts.buffer.usage()
+		taskStats := TaskStats{
+			TaskARN:          taskARN,
+			BufferedBytes:    used,
+			BufferLimitBytes: limit,
+			Containers:       make([]ContainerStats, 0, len(ts.containers)),
+		}
+		for _, entry := range ts.containers {
+			taskStats.Containers = append(taskStats.Containers, entry.stats.snapshot())
+		}
+		sort.Slice(taskStats.Containers, func(i, j int) bool {
+			return taskStats.Containers[i].ContainerName < taskStats.Containers[j].ContainerName
+		})
+		stats = append(stats, taskStats)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].TaskARN < stats[j].TaskARN
+	})
+	return stats
+}
@@ -0,0 +1,207 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package logshipper
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
+	mock_dockerapi "github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi/mocks"
+	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/eventstream"
+
+	"github.com/docker/docker/api/types"
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testTaskARN  = "arn:aws:ecs:us-west-2:123456789012:task/cluster/task-id"
+	testDockerID = "docker-id"
+)
+
+func newTestTask(t *testing.T, logDriver string, status apicontainerstatus.ContainerStatus) (*apitask.Task,
+	*apicontainer.DockerContainer) {
+	rawHostConfig, err := json.Marshal(&dockercontainer.HostConfig{
+		LogConfig: dockercontainer.LogConfig{
+			Type: logDriver,
+			Config: map[string]string{
+				"destination":    "file",
+				"flush-interval": "100ms",
+			},
+		},
+	})
+	require.NoError(t, err)
+	hostConfig := string(rawHostConfig)
+	container := &apicontainer.Container{
+		Name:              "container",
+		DockerConfig:      apicontainer.DockerConfig{HostConfig: &hostConfig},
+		KnownStatusUnsafe: status,
+	}
+	task := &apitask.Task{
+		Arn:        testTaskARN,
+		Containers: []*apicontainer.Container{container},
+	}
+	return task, &apicontainer.DockerContainer{
+		DockerID:   testDockerID,
+		DockerName: "docker-name",
+		Container:  container,
+	}
+}
+
+func newTestShipper(t *testing.T, ctx context.Context, client dockerapi.DockerClient,
+	state dockerstate.TaskEngineState) (*Shipper, *config.Config) {
+	cfg := &config.Config{
+		DataDir:                         t.TempDir(),
+		LogShippingFileDir:              t.TempDir(),
+		LogShippingTaskBufferLimitBytes: 1024,
+	}
+	stream := eventstream.NewEventStream("test", ctx)
+	return NewShipper(cfg, client, state, nil, stream), cfg
+}
+
+func TestShipperShipsRunningContainers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+
+	state := dockerstate.NewTaskEngineState()
+	task, dockerContainer := newTestTask(t, LogDriverName, apicontainerstatus.ContainerRunning)
+	state.AddTask(task)
+	state.AddContainer(dockerContainer, task)
+	shipper, cfg := newTestShipper(t, ctx, client, state)
+
+	client.EXPECT().InspectContainer(gomock.Any(), testDockerID, gomock.Any()).Return(&types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{State: &types.ContainerState{Running: true}},
+		Config:            &dockercontainer.Config{},
+	}, nil)
+	client.EXPECT().ContainerLogs(gomock.Any(), testDockerID, time.Time{}).Return(
+		dockerLogs(t, "2024-01-02T03:04:01Z hello\n", ""), nil)
+	client.EXPECT().InspectContainer(gomock.Any(), testDockerID, gomock.Any()).Return(stoppedContainer(), nil)
+
+	require.NoError(t, shipper.Init(ctx))
+	require.Eventually(t, func() bool {
+		stats := shipper.Stats()
+		return len(stats) == 1 && len(stats[0].Containers) == 1 && !stats[0].Containers[0].Shipping
+	}, 5*time.Second, 10*time.Millisecond)
+
+	stats := shipper.Stats()
+	assert.Equal(t, testTaskARN, stats[0].TaskARN)
+	assert.Equal(t, int64(1024), stats[0].BufferLimitBytes)
+	assert.Zero(t, stats[0].BufferedBytes)
+	assert.Equal(t, "container", stats[0].Containers[0].ContainerName)
+	assert.Equal(t, DestinationFile, stats[0].Containers[0].Destination)
+	assert.Equal(t, int64(1), stats[0].Containers[0].RecordsDelivered)
+
+	data, err := os.ReadFile(filepath.Join(cfg.LogShippingFileDir, "task-id", "container.log"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"log":"hello"`)
+
+	// The checkpoints, the log files and the metrics of the task are removed once the task is no longer managed by
+	// the agent.
+	state.RemoveTask(task)
+	shipper.removeStoppedTasks()
+	assert.Empty(t, shipper.Stats())
+	taskIDs, err := shipper.checkpoints.taskIDs()
+	require.NoError(t, err)
+	assert.Empty(t, taskIDs)
+	assert.NoDirExists(t, filepath.Join(cfg.LogShippingFileDir, "task-id"))
+}
+
+func TestShipperShipsContainersStoppedWithoutCheckpoint(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+
+	// The container stopped before the agent delivered any of its logs.
+	state := dockerstate.NewTaskEngineState()
+	task, dockerContainer := newTestTask(t, LogDriverName, apicontainerstatus.ContainerStopped)
+	state.AddTask(task)
+	state.AddContainer(dockerContainer, task)
+	shipper, cfg := newTestShipper(t, ctx, client, state)
+
+	client.EXPECT().InspectContainer(gomock.Any(), testDockerID, gomock.Any()).Return(stoppedContainer(), nil)
+	client.EXPECT().ContainerLogs(gomock.Any(), testDockerID, time.Time{}).Return(
+		dockerLogs(t, "2024-01-02T03:04:01Z last words\n", ""), nil)
+	client.EXPECT().InspectContainer(gomock.Any(), testDockerID, gomock.Any()).Return(stoppedContainer(), nil)
+
+	require.NoError(t, shipper.Init(ctx))
+	require.Eventually(t, func() bool {
+		stats := shipper.Stats()
+		return len(stats) == 1 && len(stats[0].Containers) == 1 && !stats[0].Containers[0].Shipping
+	}, 5*time.Second, 10*time.Millisecond)
+
+	data, err := os.ReadFile(filepath.Join(cfg.LogShippingFileDir, "task-id", "container.log"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"log":"last words"`)
+}
+
+func TestShipperHandleContainerChangeEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+
+	state := dockerstate.NewTaskEngineState()
+	task, dockerContainer := newTestTask(t, "json-file", apicontainerstatus.ContainerRunning)
+	state.AddTask(task)
+	state.AddContainer(dockerContainer, task)
+	shipper, _ := newTestShipper(t, ctx, client, state)
+	require.NoError(t, shipper.Init(ctx))
+
+	// The logs of containers that use other log drivers are not shipped.
+	require.NoError(t, shipper.handleContainerChangeEvents(dockerapi.DockerContainerChangeEvent{
+		Status:                  apicontainerstatus.ContainerRunning,
+		DockerContainerMetadata: dockerapi.DockerContainerMetadata{DockerID: testDockerID},
+	}))
+	assert.Empty(t, shipper.Stats())
+
+	assert.Error(t, shipper.handleContainerChangeEvents("not an event"))
+}
+
+func TestShipperRemovesCheckpointsOfUnknownTasks(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	shipper, _ := newTestShipper(t, ctx, mock_dockerapi.NewMockDockerClient(ctrl), dockerstate.NewTaskEngineState())
+	require.NoError(t, shipper.checkpoints.write("unknown-task-id", "container", time.Now()))
+	require.NoError(t, os.MkdirAll(filepath.Join(shipper.cfg.LogShippingFileDir, "unknown-task-id"), 0755))
+
+	require.NoError(t, shipper.Init(ctx))
+	taskIDs, err := shipper.checkpoints.taskIDs()
+	require.NoError(t, err)
+	assert.Empty(t, taskIDs)
+	assert.NoDirExists(t, filepath.Join(shipper.cfg.LogShippingFileDir, "unknown-task-id"))
+}
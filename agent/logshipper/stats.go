@@ -0,0 +1,113 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package logshipper
+
+import (
+	"sync"
+	"time"
+)
+
+// TaskStats are the log shipping metrics of a task.
+type TaskStats struct {
+	TaskARN string `json:"TaskARN"`
+	// BufferedBytes is the size of the log records of the task read but not delivered yet.
+	BufferedBytes int64 `json:"BufferedBytes"`
+	// BufferLimitBytes is the limit of BufferedBytes, set by ECS_LOG_SHIPPING_TASK_BUFFER_LIMIT_BYTES.
+	BufferLimitBytes int64            `json:"BufferLimitBytes"`
+	Containers       []ContainerStats `json:"Containers"`
+}
+
+// ContainerStats are the log shipping metrics of a container.
+type ContainerStats struct {
+	ContainerName string `json:"ContainerName"`
+	DockerID      string `json:"DockerId"`
+	Destination   string `json:"Destination"`
+	// Shipping is set while the logs of the container are read, and until they are all delivered.
+	Shipping bool `json:"Shipping"`
+	// RecordsRead and BytesRead count the log records read from the container.
+	RecordsRead int64 `json:"RecordsRead"`
+	BytesRead   int64 `json:"BytesRead"`
+	// RecordsDelivered and BytesDelivered count the log records delivered to the destination.
+	RecordsDelivered int64 `json:"RecordsDelivered"`
+	BytesDelivered   int64 `json:"BytesDelivered"`
+	// RecordsDropped counts the log records dropped, because the buffer of the task was full with the drop
+	// backpressure mode, or because their delivery failed after all the retries.
+	RecordsDropped int64 `json:"RecordsDropped"`
+	// DeliveryErrors counts the failed attempts to deliver a batch of log records.
+	DeliveryErrors int64 `json:"DeliveryErrors"`
+	// BackpressureEvents counts how many times reading the logs of the container waited for the buffer of the
+	// task to have room, with the block backpressure mode.
+	BackpressureEvents int64 `json:"BackpressureEvents"`
+	// LastDeliveryTime is when a batch of log records was last delivered.
+	LastDeliveryTime *time.Time `json:"LastDeliveryTime,omitempty"`
+}
+
+// containerStats holds the metrics of a container while its logs are shipped.
+type containerStats struct {
+	lock  sync.Mutex
+	stats ContainerStats
+}
+
+func (s *containerStats) recordRead(size int64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.stats.RecordsRead++
+	s.stats.BytesRead += size
+}
+
+func (s *containerStats) recordsDelivered(records, size int64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := time.Now()
+	s.stats.RecordsDelivered += records
+	s.stats.BytesDelivered += size
+	s.stats.LastDeliveryTime = &now
+}
+
+func (s *containerStats) recordsDropped(records int64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.stats.RecordsDropped += records
+}
+
+func (s *containerStats) deliveryError() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.stats.DeliveryErrors++
+}
+
+func (s *containerStats) backpressureEvent() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.stats.BackpressureEvents++
+}
+
+func (s *containerStats) setShipping(shipping bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.stats.Shipping = shipping
+}
+
+func (s *containerStats) snapshot() ContainerStats {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.stats
+}
@@ -0,0 +1,34 @@
+//go:build linux
+// +build linux
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package packetcapture
+
+const (
+	nsenterCommand = "nsenter"
+	tcpdumpCommand = "tcpdump"
+)
+
+// captureCommand returns the command that captures the packets of every interface in the network
+// namespace, writing them to its standard output in the pcap format. Packets are written as soon
+// as they are captured so that the capture can be cut off at any point.
+func captureCommand(netNSPath, filter string) (string, []string, error) {
+	args := []string{"--net=" + netNSPath, tcpdumpCommand, "-i", "any", "-n", "-U", "-w", "-"}
+	if filter != "" {
+		// The filter must never be taken for tcpdump options.
+		args = append(args, "--", filter)
+	}
+	return nsenterCommand, args, nil
+}
@@ -0,0 +1,443 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package packetcapture runs bounded packet captures inside the network namespace of awsvpc tasks.
+package packetcapture
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+	netlibdata "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/data"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/execwrapper"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultDuration is how long a packet capture runs when no duration is requested.
+	DefaultDuration = 30 * time.Second
+	// MaxDuration is the longest a packet capture can run.
+	MaxDuration = 10 * time.Minute
+	// DefaultMaxSizeBytes is the size a packet capture is capped at when no size is requested.
+	DefaultMaxSizeBytes = 10 * 1024 * 1024
+	// MaxSizeBytes is the largest size a packet capture can be capped at.
+	MaxSizeBytes = 100 * 1024 * 1024
+
+	// StatusRunning is the status of a packet capture that is in progress.
+	StatusRunning = "RUNNING"
+	// StatusCompleted is the status of a packet capture that ran for its duration or up to its size cap.
+	StatusCompleted = "COMPLETED"
+	// StatusFailed is the status of a packet capture that could not be completed.
+	StatusFailed = "FAILED"
+
+	captureDirName       = "packetcaptures"
+	captureFileExtension = ".pcap"
+	maxRunningCaptures   = 4
+	cleanupInterval      = time.Minute
+	maxStderrBytes       = 4096
+)
+
+var (
+	// ErrCaptureInProgress is returned when a packet capture is requested for a task that is already
+	// being captured.
+	ErrCaptureInProgress = errors.New("a packet capture is already running for the task")
+	// ErrTooManyCaptures is returned when the number of packet captures running on the instance is
+	// at its limit.
+	ErrTooManyCaptures = errors.New("too many packet captures are running on the container instance")
+	// ErrNoTaskNetNS is returned when a packet capture is requested for a task without a network
+	// namespace of its own.
+	ErrNoTaskNetNS = errors.New("packet capture is only supported for running awsvpc tasks")
+	// ErrCaptureNotFound is returned when a packet capture is not known, or has already been removed.
+	ErrCaptureNotFound = errors.New("no packet capture found")
+)
+
+// Request describes a packet capture to start.
+type Request struct {
+	Duration     time.Duration
+	MaxSizeBytes int64
+	// Filter is a BPF expression, in the syntax of tcpdump, that selects the packets to capture.
+	Filter string
+}
+
+// Validate checks the request against the capture bounds, filling in the defaults for the fields
+// that are not set.
+func (r *Request) Validate() error {
+	if r.Duration == 0 {
+		r.Duration = DefaultDuration
+	}
+	if r.Duration < 0 || r.Duration > MaxDuration {
+		return errors.Errorf("duration must be between 0 and %s", MaxDuration)
+	}
+	if r.MaxSizeBytes == 0 {
+		r.MaxSizeBytes = DefaultMaxSizeBytes
+	}
+	if r.MaxSizeBytes < 0 || r.MaxSizeBytes > MaxSizeBytes {
+		return errors.Errorf("maximum size must be between 0 and %d bytes", MaxSizeBytes)
+	}
+	return nil
+}
+
+// Capture describes a packet capture.
+type Capture struct {
+	ID           string     `json:"ID"`
+	TaskARN      string     `json:"TaskARN"`
+	Filter       string     `json:"Filter,omitempty"`
+	Duration     string     `json:"Duration"`
+	MaxSizeBytes int64      `json:"MaxSizeBytes"`
+	SizeBytes    int64      `json:"SizeBytes"`
+	Status       string     `json:"Status"`
+	Error        string     `json:"Error,omitempty"`
+	StartedAt    time.Time  `json:"StartedAt"`
+	StoppedAt    *time.Time `json:"StoppedAt,omitempty"`
+}
+
+// capture is the state the manager keeps for a packet capture. Its fields are guarded by the
+// lock of the manager.
+type capture struct {
+	Capture
+	path string
+}
+
+// Manager starts packet captures inside the network namespace of tasks, and removes them once
+// they are older than the retention.
+type Manager struct {
+	dir        string
+	retention  time.Duration
+	networkDAO netlibdata.NetworkDataClient
+	exec       execwrapper.Exec
+
+	lock     sync.RWMutex
+	captures map[string]*capture
+}
+
+// NewManager returns a manager that writes packet captures under the data directory. The network
+// namespaces of tasks are looked up in the network configurations saved by networkDAO.
+func NewManager(dataDir string, retention time.Duration, networkDAO netlibdata.NetworkDataClient) *Manager {
+	return newManager(dataDir, retention, networkDAO, execwrapper.NewExec())
+}
+
+func newManager(
+	dataDir string,
+	retention time.Duration,
+	networkDAO netlibdata.NetworkDataClient,
+	exec execwrapper.Exec,
+) *Manager {
+	return &Manager{
+		dir:        filepath.Join(dataDir, captureDirName),
+		retention:  retention,
+		networkDAO: networkDAO,
+		exec:       exec,
+		captures:   make(map[string]*capture),
+	}
+}
+
+// taskNetNSPath returns the path of the network namespace of an awsvpc task, which is the primary
+// network namespace of the task network configuration. Tasks without a saved network configuration,
+// whose network is set up through their pause container, use the network namespace of that container.
+func (m *Manager) taskNetNSPath(task *apitask.Task) (string, error) {
+	if task.GetNetworkMode() != apitask.AWSVPCNetworkMode {
+		return "", ErrNoTaskNetNS
+	}
+	if m.networkDAO != nil {
+		netNSs, err := m.networkDAO.GetNetworkNamespacesByTaskID(task.GetID())
+		if err != nil {
+			return "", errors.Wrap(err, "unable to get the network configuration of the task")
+		}
+		if len(netNSs) > 0 {
+			taskNetworkConfig, err := tasknetworkconfig.New(types.NetworkModeAwsvpc, netNSs...)
+			if err != nil {
+				return "", err
+			}
+			if netNS := taskNetworkConfig.GetPrimaryNetNS(); netNS != nil && netNS.Path != "" {
+				return netNS.Path, nil
+			}
+			return "", ErrNoTaskNetNS
+		}
+	}
+	if netNSPath := task.GetNetworkNamespace(); netNSPath != "" {
+		return netNSPath, nil
+	}
+	return "", ErrNoTaskNetNS
+}
+
+// Start starts a packet capture of the traffic of the task and returns without waiting for it
+// to complete.
+func (m *Manager) Start(task *apitask.Task, request Request) (*Capture, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	netNSPath, err := m.taskNetNSPath(task)
+	if err != nil {
+		return nil, err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	running := 0
+	for _, c := range m.captures {
+		if c.Status != StatusRunning {
+			continue
+		}
+		if c.TaskARN == task.Arn {
+			return nil, ErrCaptureInProgress
+		}
+		running++
+	}
+	if running >= maxRunningCaptures {
+		return nil, ErrTooManyCaptures
+	}
+
+	command, args, err := captureCommand(netNSPath, request.Filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(m.dir, 0700); err != nil {
+		return nil, errors.Wrap(err, "failed to create the packet capture directory")
+	}
+	id := uuid.New().String()
+	path := filepath.Join(m.dir, id+captureFileExtension)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the packet capture file")
+	}
+
+	ctx, cancel := m.exec.NewExecContextWithTimeout(context.Background(), request.Duration)
+	cmd := m.exec.CommandContext(ctx, command, args...)
+	output := &cappedWriter{file: file, max: request.MaxSizeBytes, full: cancel}
+	stderr := &bytes.Buffer{}
+	cmd.SetIOStreams(nil, output, &cappedWriter{file: stderr, max: maxStderrBytes})
+	if err := cmd.Start(); err != nil {
+		cancel()
+		file.Close()
+		os.Remove(path)
+		return nil, errors.Wrap(err, "failed to start the packet capture")
+	}
+
+	c := &capture{
+		Capture: Capture{
+			ID:           id,
+			TaskARN:      task.Arn,
+			Filter:       request.Filter,
+			Duration:     request.Duration.String(),
+			MaxSizeBytes: request.MaxSizeBytes,
+			Status:       StatusRunning,
+			StartedAt:    time.Now(),
+		},
+		path: path,
+	}
+	m.captures[id] = c
+	logger.Info("Started packet capture", logger.Fields{
+		field.TaskARN: task.Arn,
+		"captureID":   id,
+		"duration":    request.Duration.String(),
+		"filter":      request.Filter,
+	})
+
+	go func() {
+		err := cmd.Wait()
+		// The capture is cut short by the context when it reaches its duration or its size cap,
+		// neither of which is a failure.
+		stopped := ctx.Err() != nil
+		cancel()
+		file.Close()
+		m.stopped(c, output.written(), stopped, err, stderr)
+	}()
+
+	snapshot := c.Capture
+	return &snapshot, nil
+}
+
+// stopped records the outcome of a packet capture once its process has exited.
+func (m *Manager) stopped(c *capture, size int64, cutShort bool, err error, stderr *bytes.Buffer) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	now := time.Now()
+	c.StoppedAt = &now
+	c.SizeBytes = size
+	c.Status = StatusCompleted
+	if err != nil && !cutShort {
+		c.Status = StatusFailed
+		c.Error = strings.TrimSpace(fmt.Sprintf("%v: %s", err, stderr.String()))
+		logger.Warn("Packet capture failed", logger.Fields{
+			field.TaskARN: c.TaskARN,
+			"captureID":   c.ID,
+			field.Error:   c.Error,
+		})
+		return
+	}
+	logger.Info("Packet capture completed", logger.Fields{
+		field.TaskARN: c.TaskARN,
+		"captureID":   c.ID,
+		"sizeBytes":   size,
+	})
+}
+
+// Get returns the packet capture with the ID.
+func (m *Manager) Get(id string) (*Capture, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	c, ok := m.captures[id]
+	if !ok {
+		return nil, ErrCaptureNotFound
+	}
+	snapshot := c.Capture
+	return &snapshot, nil
+}
+
+// List returns the packet captures of the task, or of all tasks when the task ARN is empty.
+func (m *Manager) List(taskARN string) []*Capture {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	captures := []*Capture{}
+	for _, c := range m.captures {
+		if taskARN != "" && c.TaskARN != taskARN {
+			continue
+		}
+		snapshot := c.Capture
+		captures = append(captures, &snapshot)
+	}
+	return captures
+}
+
+// Open returns the pcap file of a packet capture that is no longer running.
+func (m *Manager) Open(id string) (*os.File, *Capture, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	c, ok := m.captures[id]
+	if !ok {
+		return nil, nil, ErrCaptureNotFound
+	}
+	if c.Status == StatusRunning {
+		return nil, nil, ErrCaptureInProgress
+	}
+	file, err := os.Open(c.path)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to open the packet capture file")
+	}
+	snapshot := c.Capture
+	return file, &snapshot, nil
+}
+
+// StartCleanup removes the packet captures that were left behind by a previous run of the agent,
+// then periodically removes the ones that stopped longer than the retention ago, until the context
+// is cancelled.
+func (m *Manager) StartCleanup(ctx context.Context) {
+	m.removeOrphanedFiles()
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.removeExpired(time.Now())
+		}
+	}
+}
+
+// removeOrphanedFiles removes the pcap files in the capture directory that the manager does not
+// know about.
+func (m *Manager) removeOrphanedFiles() {
+	entries, err := os.ReadDir(m.dir)
+	if err != nil {
+		return
+	}
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	for _, entry := range entries {
+		id := strings.TrimSuffix(entry.Name(), captureFileExtension)
+		if _, ok := m.captures[id]; ok || entry.IsDir() {
+			continue
+		}
+		if err := os.Remove(filepath.Join(m.dir, entry.Name())); err != nil {
+			logger.Warn("Unable to remove orphaned packet capture", logger.Fields{
+				"file":      entry.Name(),
+				field.Error: err,
+			})
+		}
+	}
+}
+
+// removeExpired removes the packet captures that stopped longer than the retention ago.
+func (m *Manager) removeExpired(now time.Time) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for id, c := range m.captures {
+		if c.StoppedAt == nil || now.Sub(*c.StoppedAt) < m.retention {
+			continue
+		}
+		if err := os.Remove(c.path); err != nil && !os.IsNotExist(err) {
+			logger.Warn("Unable to remove expired packet capture", logger.Fields{
+				"captureID": id,
+				field.Error: err,
+			})
+			continue
+		}
+		delete(m.captures, id)
+	}
+}
+
+// cappedWriter writes to the underlying writer until a write would take it over its maximum size.
+// That write, and every one after it, is dropped and the full function is called so that the
+// capture can be stopped. As the capture is written packet by packet, this keeps the file within
+// the cap without cutting a packet in half in the common case.
+type cappedWriter struct {
+	file io.Writer
+	max  int64
+	full context.CancelFunc
+
+	lock    sync.Mutex
+	size    int64
+	dropped bool
+}
+
+func (w *cappedWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.dropped || w.size+int64(len(p)) > w.max {
+		if !w.dropped && w.full != nil {
+			w.full()
+		}
+		w.dropped = true
+		// Report the bytes as written so that the process is not failed on a short write.
+		return len(p), nil
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *cappedWriter) written() int64 {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.size
+}
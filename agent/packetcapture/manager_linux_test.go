@@ -0,0 +1,240 @@
+//go:build linux && unit
+// +build linux,unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package packetcapture
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	mock_data "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/data/mocks"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
+	mock_execwrapper "github.com/aws/amazon-ecs-agent/ecs-agent/utils/execwrapper/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testTaskARN   = "arn:aws:ecs:us-west-2:123456789012:task/cluster/1"
+	testNetNSPath = "/host/proc/1234/ns/net"
+)
+
+func testTask() *apitask.Task {
+	return &apitask.Task{
+		Arn:              testTaskARN,
+		NetworkMode:      apitask.AWSVPCNetworkMode,
+		NetworkNamespace: testNetNSPath,
+	}
+}
+
+// fakeCapture is a capture process that writes the packets it is given to its standard output
+// until it is stopped, by its context or by the test.
+type fakeCapture struct {
+	args    []string
+	stdout  io.Writer
+	stderr  io.Writer
+	packets chan []byte
+	exit    chan error
+}
+
+// expectCapture makes the exec mock start a fake capture process.
+func expectCapture(ctrl *gomock.Controller, execWrapper *mock_execwrapper.MockExec) *fakeCapture {
+	fake := &fakeCapture{packets: make(chan []byte), exit: make(chan error, 1)}
+	var ctx context.Context
+	execWrapper.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(parent context.Context, duration time.Duration) (context.Context, context.CancelFunc) {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(parent, duration)
+			return ctx, cancel
+		})
+	execWrapper.EXPECT().CommandContext(gomock.Any(), nsenterCommand, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, args ...string) *mock_execwrapper.MockCmd {
+			fake.args = args
+			cmd := mock_execwrapper.NewMockCmd(ctrl)
+			cmd.EXPECT().SetIOStreams(nil, gomock.Any(), gomock.Any()).Do(func(_ io.Reader, stdout, stderr io.Writer) {
+				fake.stdout = stdout
+				fake.stderr = stderr
+			})
+			cmd.EXPECT().Start().Return(nil)
+			cmd.EXPECT().Wait().DoAndReturn(func() error {
+				for {
+					select {
+					case packet := <-fake.packets:
+						fake.stdout.Write(packet)
+					case err := <-fake.exit:
+						return err
+					case <-ctx.Done():
+						return errors.New("signal: killed")
+					}
+				}
+			})
+			return cmd
+		})
+	return fake
+}
+
+func waitForStatus(t *testing.T, manager *Manager, id string) *Capture {
+	var capture *Capture
+	require.Eventually(t, func() bool {
+		var err error
+		capture, err = manager.Get(id)
+		require.NoError(t, err)
+		return capture.Status != StatusRunning
+	}, 5*time.Second, 10*time.Millisecond)
+	return capture
+}
+
+func TestStartCapture(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	execWrapper := mock_execwrapper.NewMockExec(ctrl)
+	manager := newManager(t.TempDir(), time.Hour, nil, execWrapper)
+	fake := expectCapture(ctrl, execWrapper)
+
+	capture, err := manager.Start(testTask(), Request{MaxSizeBytes: 10, Filter: "-w /etc/passwd"})
+	require.NoError(t, err)
+	assert.Equal(t, StatusRunning, capture.Status)
+	assert.Equal(t, DefaultDuration.String(), capture.Duration)
+	assert.Equal(t, []string{"--net=" + testNetNSPath, tcpdumpCommand, "-i", "any", "-n", "-U", "-w", "-",
+		"--", "-w /etc/passwd"}, fake.args)
+
+	// Only one capture runs per task, and its file cannot be downloaded until it stops.
+	_, err = manager.Start(testTask(), Request{})
+	assert.Equal(t, ErrCaptureInProgress, err)
+	_, _, err = manager.Open(capture.ID)
+	assert.Equal(t, ErrCaptureInProgress, err)
+
+	// The capture is stopped by the first packet that does not fit under its size cap.
+	fake.packets <- []byte("header")
+	fake.packets <- []byte("pkt")
+	fake.packets <- []byte("packet")
+	capture = waitForStatus(t, manager, capture.ID)
+	assert.Equal(t, StatusCompleted, capture.Status)
+	assert.Equal(t, int64(9), capture.SizeBytes)
+
+	file, _, err := manager.Open(capture.ID)
+	require.NoError(t, err)
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	require.NoError(t, err)
+	assert.Equal(t, "headerpkt", string(content))
+	assert.Len(t, manager.List(testTaskARN), 1)
+	assert.Empty(t, manager.List("other"))
+}
+
+func TestStartCaptureInPrimaryNetNS(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	execWrapper := mock_execwrapper.NewMockExec(ctrl)
+	networkDAO := mock_data.NewMockNetworkDataClient(ctrl)
+	manager := newManager(t.TempDir(), time.Hour, networkDAO, execWrapper)
+
+	// The capture runs in the primary network namespace of the task network configuration,
+	// rather than in the network namespace of the pause container.
+	primaryNetNSPath := "/var/run/netns/primary"
+	networkDAO.EXPECT().GetNetworkNamespacesByTaskID("1").Return([]*tasknetworkconfig.NetworkNamespace{
+		{Name: "secondary", Path: "/var/run/netns/secondary", Index: 1},
+		{Name: "primary", Path: primaryNetNSPath, Index: 0},
+	}, nil)
+	fake := expectCapture(ctrl, execWrapper)
+
+	capture, err := manager.Start(testTask(), Request{})
+	require.NoError(t, err)
+	assert.Equal(t, "--net="+primaryNetNSPath, fake.args[0])
+	fake.exit <- nil
+	waitForStatus(t, manager, capture.ID)
+
+	networkDAO.EXPECT().GetNetworkNamespacesByTaskID("1").Return(nil, errors.New("db error"))
+	_, err = manager.Start(testTask(), Request{})
+	assert.Error(t, err)
+}
+
+func TestStartCaptureFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	execWrapper := mock_execwrapper.NewMockExec(ctrl)
+	manager := newManager(t.TempDir(), time.Hour, nil, execWrapper)
+	fake := expectCapture(ctrl, execWrapper)
+
+	capture, err := manager.Start(testTask(), Request{Filter: "port eighty"})
+	require.NoError(t, err)
+	fake.stderr.Write([]byte("tcpdump: syntax error\n"))
+	fake.exit <- errors.New("exit status 1")
+	capture = waitForStatus(t, manager, capture.ID)
+	assert.Equal(t, StatusFailed, capture.Status)
+	assert.Equal(t, "exit status 1: tcpdump: syntax error", capture.Error)
+}
+
+func TestStartCaptureInvalidRequest(t *testing.T) {
+	manager := newManager(t.TempDir(), time.Hour, nil, nil)
+
+	_, err := manager.Start(testTask(), Request{Duration: time.Hour})
+	assert.Error(t, err)
+	_, err = manager.Start(testTask(), Request{MaxSizeBytes: -1})
+	assert.Error(t, err)
+
+	task := testTask()
+	task.NetworkMode = apitask.BridgeNetworkMode
+	_, err = manager.Start(task, Request{})
+	assert.Equal(t, ErrNoTaskNetNS, err)
+}
+
+func TestCleanup(t *testing.T) {
+	dataDir := t.TempDir()
+	manager := newManager(dataDir, time.Hour, nil, nil)
+	require.NoError(t, os.MkdirAll(manager.dir, 0700))
+
+	stoppedAt := time.Now()
+	for _, id := range []string{"expired", "retained", "running", "orphaned"} {
+		path := filepath.Join(manager.dir, id+captureFileExtension)
+		require.NoError(t, os.WriteFile(path, []byte(id), 0600))
+		if id == "orphaned" {
+			continue
+		}
+		c := &capture{Capture: Capture{ID: id, Status: StatusCompleted, StoppedAt: &stoppedAt}, path: path}
+		if id == "running" {
+			c.Status = StatusRunning
+			c.StoppedAt = nil
+		}
+		manager.captures[id] = c
+	}
+	expiredAt := stoppedAt.Add(-2 * time.Hour)
+	manager.captures["expired"].StoppedAt = &expiredAt
+
+	manager.removeOrphanedFiles()
+	manager.removeExpired(time.Now())
+
+	entries, err := os.ReadDir(manager.dir)
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.ElementsMatch(t, []string{"retained.pcap", "running.pcap"}, names)
+	_, err = manager.Get("expired")
+	assert.Equal(t, ErrCaptureNotFound, err)
+}
@@ -39,6 +39,7 @@ const (
 type S3ClientCreator interface {
 	NewS3ManagerClient(bucket, region string, creds credentials.IAMRoleCredentials, ipCompatibility ipcompatibility.IPCompatibility) (s3client.S3ManagerClient, error)
 	NewS3Client(bucket, region string, creds credentials.IAMRoleCredentials, ipCompatibility ipcompatibility.IPCompatibility) (s3client.S3Client, error)
+	NewS3UploadManagerClient(bucket, region string, creds credentials.IAMRoleCredentials, ipCompatibility ipcompatibility.IPCompatibility) (s3client.S3UploadManagerClient, error)
 }
 
 // NewS3ClientCreator provides 2 implementations
@@ -115,6 +116,29 @@ func (*s3ClientCreator) NewS3ManagerClient(bucket, region string, creds credenti
 	return s3manager.NewDownloader(s3.NewFromConfig(cfg)), nil
 }
 
+// NewS3UploadManagerClient returns a new S3 client based on the upload API of s3manager, which
+// uploads large objects in parts.
+func (*s3ClientCreator) NewS3UploadManagerClient(bucket, region string, creds credentials.IAMRoleCredentials, ipCompatibility ipcompatibility.IPCompatibility) (s3client.S3UploadManagerClient, error) {
+	// Create an initial AWS session to get the bucket region
+	cfg, err := createAWSConfig(region, creds, false, ipCompatibility.IsIPv6Only())
+	if err != nil {
+		return nil, err
+	}
+	client := s3.NewFromConfig(cfg)
+	bucketRegion, err := getRegionFromBucket(client, bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	// Determine if we should use FIPS endpoints based on the bucket region
+	useFIPSEndpoint := config.IsFIPSEnabled() && isS3FIPSCompliantRegion(bucketRegion)
+	cfg, err = createAWSConfig(bucketRegion, creds, useFIPSEndpoint, ipCompatibility.IsIPv6Only())
+	if err != nil {
+		return nil, err
+	}
+	return s3manager.NewUploader(s3.NewFromConfig(cfg)), nil
+}
+
 // NewS3Client returns a new S3 client to support S3 operations which are not provided by s3manager.
 func (*s3ClientCreator) NewS3Client(bucket, region string, creds credentials.IAMRoleCredentials, ipCompatibility ipcompatibility.IPCompatibility) (s3client.S3Client, error) {
 	// Create an initial AWS session to get the bucket region
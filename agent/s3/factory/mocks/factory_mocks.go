@@ -79,3 +79,18 @@ func (mr *MockS3ClientCreatorMockRecorder) NewS3ManagerClient(arg0, arg1, arg2,
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewS3ManagerClient", reflect.TypeOf((*MockS3ClientCreator)(nil).NewS3ManagerClient), arg0, arg1, arg2, arg3)
 }
+
+// NewS3UploadManagerClient mocks base method.
+func (m *MockS3ClientCreator) NewS3UploadManagerClient(arg0, arg1 string, arg2 credentials.IAMRoleCredentials, arg3 ipcompatibility.IPCompatibility) (s3.S3UploadManagerClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewS3UploadManagerClient", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(s3.S3UploadManagerClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewS3UploadManagerClient indicates an expected call of NewS3UploadManagerClient.
+func (mr *MockS3ClientCreatorMockRecorder) NewS3UploadManagerClient(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewS3UploadManagerClient", reflect.TypeOf((*MockS3ClientCreator)(nil).NewS3UploadManagerClient), arg0, arg1, arg2, arg3)
+}
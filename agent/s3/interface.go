@@ -27,6 +27,12 @@ type S3ManagerClient interface {
 	Download(ctx context.Context, w io.WriterAt, input *s3.GetObjectInput, options ...func(*s3manager.Downloader)) (n int64, err error)
 }
 
+// S3UploadManagerClient interface wraps the upload API of the S3Manager, which uploads large
+// objects in parts.
+type S3UploadManagerClient interface {
+	Upload(ctx context.Context, input *s3.PutObjectInput, options ...func(*s3manager.Uploader)) (*s3manager.UploadOutput, error)
+}
+
 // S3Client interface wraps the generic S3 APIs.
 // Any method that belongs to S3 service of AWS SDK Go goes here.
 type S3Client interface {
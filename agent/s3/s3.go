@@ -17,6 +17,7 @@ import (
 	"context"
 	"io"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -26,6 +27,7 @@ import (
 
 const (
 	s3ARNRegex = `arn:([^:]+):s3:::([^/]+)/(.+)`
+	s3URIRegex = `^s3://([^/]+)/?(.*)$`
 )
 
 // DownloadFile downloads a file from s3 and writes it with the writer.
@@ -41,6 +43,28 @@ func DownloadFile(bucket, key string, timeout time.Duration, w io.WriterAt, clie
 	return err
 }
 
+// UploadFile uploads the content read from the reader to s3, in parts if it is too large for a
+// single request.
+func UploadFile(ctx context.Context, bucket, key string, r io.Reader, client S3UploadManagerClient) error {
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Body:   r,
+	}
+	_, err := client.Upload(ctx, input)
+	return err
+}
+
+// ParseS3URI parses an s3 URI of the form s3://bucket/prefix. The prefix may be empty.
+func ParseS3URI(s3URI string) (bucket string, prefix string, err error) {
+	exp := regexp.MustCompile(s3URIRegex)
+	match := exp.FindStringSubmatch(s3URI)
+	if len(match) != 3 {
+		return "", "", errors.Errorf("invalid s3 uri: %s", s3URI)
+	}
+	return match[1], strings.TrimSuffix(match[2], "/"), nil
+}
+
 // ParseS3ARN parses an s3 ARN.
 func ParseS3ARN(s3ARN string) (bucket string, key string, err error) {
 	exp := regexp.MustCompile(s3ARNRegex)
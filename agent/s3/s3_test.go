@@ -32,6 +32,7 @@ import (
 
 	mock_s3 "github.com/aws/amazon-ecs-agent/agent/s3/mocks"
 	mock_s3manager "github.com/aws/amazon-ecs-agent/agent/s3/mocks/s3manager"
+	mock_s3uploadmanager "github.com/aws/amazon-ecs-agent/agent/s3/mocks/s3uploadmanager"
 	mock_oswrapper "github.com/aws/amazon-ecs-agent/agent/utils/oswrapper/mocks"
 )
 
@@ -111,3 +112,56 @@ func TestGetObjectErr(t *testing.T) {
 	_, err := GetObject(testBucket, testKey, mockS3Client)
 	assert.Error(t, err)
 }
+
+func TestUploadFile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	body := strings.NewReader("testdata")
+	mockS3UploadManagerClient := mock_s3uploadmanager.NewMockS3UploadManagerClient(ctrl)
+	mockS3UploadManagerClient.EXPECT().Upload(gomock.Any(), gomock.Any()).Do(
+		func(ctx context.Context, input *s3sdk.PutObjectInput, options ...func(*s3manager.Uploader)) {
+			assert.Equal(t, testBucket, aws.ToString(input.Bucket))
+			assert.Equal(t, testKey, aws.ToString(input.Key))
+			assert.Equal(t, body, input.Body)
+		}).Return(&s3manager.UploadOutput{}, nil)
+
+	err := UploadFile(context.TODO(), testBucket, testKey, body, mockS3UploadManagerClient)
+	assert.NoError(t, err)
+}
+
+func TestUploadFileError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockS3UploadManagerClient := mock_s3uploadmanager.NewMockS3UploadManagerClient(ctrl)
+	mockS3UploadManagerClient.EXPECT().Upload(gomock.Any(), gomock.Any()).Return(nil, errors.New("test error"))
+
+	err := UploadFile(context.TODO(), testBucket, testKey, strings.NewReader("testdata"), mockS3UploadManagerClient)
+	assert.Error(t, err)
+}
+
+func TestParseS3URI(t *testing.T) {
+	for _, tc := range []struct {
+		uri            string
+		expectedBucket string
+		expectedPrefix string
+	}{
+		{"s3://bucket", "bucket", ""},
+		{"s3://bucket/", "bucket", ""},
+		{"s3://bucket/prefix", "bucket", "prefix"},
+		{"s3://bucket/some/prefix/", "bucket", "some/prefix"},
+	} {
+		t.Run(tc.uri, func(t *testing.T) {
+			bucket, prefix, err := ParseS3URI(tc.uri)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedBucket, bucket)
+			assert.Equal(t, tc.expectedPrefix, prefix)
+		})
+	}
+}
+
+func TestParseS3URIInvalid(t *testing.T) {
+	_, _, err := ParseS3URI("arn:aws:s3:::bucket/key")
+	assert.Error(t, err)
+}
@@ -0,0 +1,84 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package secretcache
+
+import (
+	"fmt"
+
+	"github.com/aws/amazon-ecs-agent/agent/asm"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// GetASMSecret returns the secret string of the Secrets Manager secret in the region. The current
+// version of the secret is retrieved in batches with the other tasks requesting secrets with the
+// same execution role. A specific version, identified by its stage or ID, is retrieved on its own
+// since BatchGetSecretValue only returns current versions.
+func (c *Cache) GetASMSecret(region string, creds credentials.IAMRoleCredentials,
+	secretID, versionStage, versionID string) (string, error) {
+	key := cacheKey{
+		provider: providerASM,
+		role:     roleKey(creds),
+		region:   region,
+		secretID: secretID,
+	}
+	if versionStage != "" || versionID != "" {
+		key.version = versionStage + "/" + versionID
+	}
+	if value, ok := c.get(key); ok {
+		c.hits.Add(1)
+		return value, nil
+	}
+	c.misses.Add(1)
+
+	if key.version == "" {
+		b := c.enqueue(batchKey{provider: providerASM, role: key.role, region: region}, creds, secretID,
+			asm.MaxBatchGetSecretValueNum)
+		return b.result(secretID)
+	}
+
+	client, err := c.asmClientCreator.NewASMClient(region, creds)
+	if err != nil {
+		return "", fmt.Errorf("unable to create ASM client: %v", err)
+	}
+	c.apiCalls.Add(1)
+	value, err := asm.GetSecretFromASMWithInput(&secretsmanager.GetSecretValueInput{
+		SecretId:     aws.String(secretID),
+		VersionStage: pointerOrNil(versionStage),
+		VersionId:    pointerOrNil(versionID),
+	}, client, "")
+	if err != nil {
+		return "", err
+	}
+	c.set(key, value)
+	return value, nil
+}
+
+func (c *Cache) sendASM(b *batch) {
+	client, err := c.asmClientCreator.NewASMClient(b.key.region, b.creds)
+	if err != nil {
+		b.err = fmt.Errorf("unable to create ASM client: %v", err)
+		return
+	}
+	b.values, b.errs, b.err = asm.BatchGetSecretsFromASM(b.ids, client)
+}
+
+func pointerOrNil(in string) *string {
+	if in == "" {
+		return nil
+	}
+	return aws.String(in)
+}
@@ -0,0 +1,131 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package secretcache
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+)
+
+// batchKey identifies the secrets that can be retrieved with a single call.
+type batchKey struct {
+	provider string
+	role     string
+	region   string
+}
+
+// batch is a set of secrets retrieved with a single call on behalf of every task that requested
+// them. done is closed once the call has returned, after which the results may be read.
+type batch struct {
+	key   batchKey
+	creds credentials.IAMRoleCredentials
+	ids   []string
+
+	once sync.Once
+	done chan struct{}
+	// values are the retrieved secret values, keyed by secret ID
+	values map[string]string
+	// errs are the errors of the individual secrets that could not be retrieved
+	errs map[string]error
+	// err is set if the call failed as a whole
+	err error
+}
+
+func (b *batch) contains(id string) bool {
+	for _, batchID := range b.ids {
+		if batchID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// result returns the value of the secret once the batch is done.
+func (b *batch) result(id string) (string, error) {
+	<-b.done
+	if b.err != nil {
+		return "", b.err
+	}
+	if err, ok := b.errs[id]; ok {
+		return "", err
+	}
+	return b.values[id], nil
+}
+
+// enqueue adds the secret to the open batch for the key, opening a new batch if there is none.
+// The batch is sent once the batch window elapses, or as soon as it holds maxSize secrets.
+func (c *Cache) enqueue(key batchKey, creds credentials.IAMRoleCredentials, id string, maxSize int) *batch {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	b, ok := c.batches[key]
+	if ok && b.contains(id) {
+		c.coalesced.Add(1)
+		return b
+	}
+	if !ok {
+		b = &batch{
+			key:   key,
+			creds: creds,
+			done:  make(chan struct{}),
+		}
+		c.batches[key] = b
+		time.AfterFunc(c.batchWindow, func() { c.send(b) })
+	}
+	b.ids = append(b.ids, id)
+	if len(b.ids) >= maxSize {
+		delete(c.batches, key)
+		go c.send(b)
+	}
+	return b
+}
+
+// send retrieves the secrets of the batch and caches their values. It is called both when the
+// batch window elapses and when the batch is full, and only sends the batch once.
+func (c *Cache) send(b *batch) {
+	b.once.Do(func() {
+		c.lock.Lock()
+		if c.batches[b.key] == b {
+			delete(c.batches, b.key)
+		}
+		c.lock.Unlock()
+
+		logger.Debug("Retrieving batch of secrets", logger.Fields{
+			"provider":  b.key.provider,
+			"region":    b.key.region,
+			"batchSize": len(b.ids),
+		})
+		c.apiCalls.Add(1)
+		switch b.key.provider {
+		case providerSSM:
+			c.sendSSM(b)
+		case providerASM:
+			c.sendASM(b)
+		}
+		if b.err == nil {
+			for id, value := range b.values {
+				c.set(cacheKey{
+					provider: b.key.provider,
+					role:     b.key.role,
+					region:   b.key.region,
+					secretID: id,
+				}, value)
+			}
+		}
+		close(b.done)
+	})
+}
@@ -0,0 +1,205 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package secretcache implements a host level cache of the secret values retrieved from SSM
+// Parameter Store and Secrets Manager. It is shared by the secret resources of every task, so that
+// tasks which start together with the same execution role do not each fetch the same secrets.
+package secretcache
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	asmfactory "github.com/aws/amazon-ecs-agent/agent/asm/factory"
+	ssmfactory "github.com/aws/amazon-ecs-agent/agent/ssm/factory"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/ipcompatibility"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultBatchWindow is how long a batch collects the secrets requested by concurrently starting
+	// tasks before it is sent.
+	defaultBatchWindow = 50 * time.Millisecond
+
+	providerSSM = "ssm"
+	providerASM = "asm"
+)
+
+// Stats are the counters of the cache.
+type Stats struct {
+	// Hits is the number of secret values served from the cache.
+	Hits uint64 `json:"Hits"`
+	// Misses is the number of secret values that were not in the cache and had to be retrieved.
+	Misses uint64 `json:"Misses"`
+	// Coalesced is the number of misses that were served by a request already pending for the
+	// same secret, typically on behalf of another task.
+	Coalesced uint64 `json:"Coalesced"`
+	// APICalls is the number of calls made to SSM and Secrets Manager.
+	APICalls uint64 `json:"APICalls"`
+	// Entries is the number of secret values currently held by the cache.
+	Entries int `json:"Entries"`
+}
+
+// cacheKey identifies a secret value. Values are never shared between execution roles.
+type cacheKey struct {
+	provider string
+	role     string
+	region   string
+	secretID string
+	version  string
+}
+
+// additionalData binds the encrypted value to its key, so that values cannot be swapped.
+func (key cacheKey) additionalData() []byte {
+	return []byte(key.provider + "\x00" + key.role + "\x00" + key.region + "\x00" + key.secretID + "\x00" + key.version)
+}
+
+type entry struct {
+	sealed    []byte
+	expiresAt time.Time
+}
+
+// Cache holds secret values in memory, encrypted with a key that is generated when the cache is
+// created and never leaves the process. Values expire after the TTL of the cache. Values that are
+// not in the cache are retrieved in batches shared by every task that requests them within the
+// batch window.
+type Cache struct {
+	ttl              time.Duration
+	batchWindow      time.Duration
+	ssmClientCreator ssmfactory.SSMClientCreator
+	asmClientCreator asmfactory.ClientCreator
+	ipCompatibility  ipcompatibility.IPCompatibility
+	aead             cipher.AEAD
+
+	lock      sync.Mutex
+	entries   map[cacheKey]entry
+	batches   map[batchKey]*batch
+	lastSweep time.Time
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	coalesced atomic.Uint64
+	apiCalls  atomic.Uint64
+}
+
+// NewCache returns a cache whose values expire after the given TTL.
+func NewCache(ttl time.Duration,
+	ssmClientCreator ssmfactory.SSMClientCreator,
+	asmClientCreator asmfactory.ClientCreator,
+	ipCompatibility ipcompatibility.IPCompatibility) (*Cache, error) {
+	encryptionKey := make([]byte, 32)
+	if _, err := rand.Read(encryptionKey); err != nil {
+		return nil, errors.Wrap(err, "secret cache: unable to generate the encryption key")
+	}
+	block, err := aes.NewCipher(encryptionKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "secret cache: unable to create the cipher")
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "secret cache: unable to create the cipher")
+	}
+	return &Cache{
+		ttl:              ttl,
+		batchWindow:      defaultBatchWindow,
+		ssmClientCreator: ssmClientCreator,
+		asmClientCreator: asmClientCreator,
+		ipCompatibility:  ipCompatibility,
+		aead:             aead,
+		entries:          make(map[cacheKey]entry),
+		batches:          make(map[batchKey]*batch),
+	}, nil
+}
+
+// Stats returns the counters of the cache.
+func (c *Cache) Stats() Stats {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.sweepUnsafe(time.Now())
+	return Stats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Coalesced: c.coalesced.Load(),
+		APICalls:  c.apiCalls.Load(),
+		Entries:   len(c.entries),
+	}
+}
+
+// get returns the cached value of the secret, if it has not expired.
+func (c *Cache) get(key cacheKey) (string, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	if !time.Now().Before(e.expiresAt) {
+		delete(c.entries, key)
+		return "", false
+	}
+	nonceSize := c.aead.NonceSize()
+	value, err := c.aead.Open(nil, e.sealed[:nonceSize], e.sealed[nonceSize:], key.additionalData())
+	if err != nil {
+		delete(c.entries, key)
+		return "", false
+	}
+	return string(value), true
+}
+
+// set caches the value of the secret until the TTL of the cache elapses.
+func (c *Cache) set(key cacheKey, value string) {
+	if c.ttl <= 0 {
+		return
+	}
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		// Not caching the value only costs another retrieval
+		return
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(value), key.additionalData())
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := time.Now()
+	if now.Sub(c.lastSweep) >= c.ttl {
+		c.sweepUnsafe(now)
+	}
+	c.entries[key] = entry{sealed: sealed, expiresAt: now.Add(c.ttl)}
+}
+
+// sweepUnsafe removes the expired values. It must be called with the lock held.
+func (c *Cache) sweepUnsafe(now time.Time) {
+	for key, e := range c.entries {
+		if !now.Before(e.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+	c.lastSweep = now
+}
+
+// roleKey returns the identity that the cached values of the credentials are scoped to.
+func roleKey(creds credentials.IAMRoleCredentials) string {
+	if creds.RoleArn != "" {
+		return creds.RoleArn
+	}
+	return creds.AccessKeyID
+}
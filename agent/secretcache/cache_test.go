@@ -0,0 +1,288 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package secretcache
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	mock_factory "github.com/aws/amazon-ecs-agent/agent/asm/factory/mocks"
+	mock_secretsmanageriface "github.com/aws/amazon-ecs-agent/agent/asm/mocks"
+	mock_ssm_factory "github.com/aws/amazon-ecs-agent/agent/ssm/factory/mocks"
+	mock_ssmiface "github.com/aws/amazon-ecs-agent/agent/ssm/mocks"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/ipcompatibility"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	secretsmanagertypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmtypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testRegion    = "us-west-2"
+	testRoleARN   = "arn:aws:iam::123456789012:role/execution"
+	testSecretARN = "arn:aws:secretsmanager:us-west-2:123456789012:secret:secret-AbCdEf"
+)
+
+var testCreds = credentials.IAMRoleCredentials{RoleArn: testRoleARN, AccessKeyID: "id"}
+
+type testCache struct {
+	*Cache
+	ssmClientCreator *mock_ssm_factory.MockSSMClientCreator
+	ssmClient        *mock_ssmiface.MockSSMClient
+	asmClientCreator *mock_factory.MockClientCreator
+	asmClient        *mock_secretsmanageriface.MockSecretsManagerAPI
+}
+
+func newTestCache(t *testing.T, ctrl *gomock.Controller, ttl time.Duration) *testCache {
+	tc := &testCache{
+		ssmClientCreator: mock_ssm_factory.NewMockSSMClientCreator(ctrl),
+		ssmClient:        mock_ssmiface.NewMockSSMClient(ctrl),
+		asmClientCreator: mock_factory.NewMockClientCreator(ctrl),
+		asmClient:        mock_secretsmanageriface.NewMockSecretsManagerAPI(ctrl),
+	}
+	cache, err := NewCache(ttl, tc.ssmClientCreator, tc.asmClientCreator, ipcompatibility.NewIPv4OnlyCompatibility())
+	require.NoError(t, err)
+	cache.batchWindow = 10 * time.Millisecond
+	tc.Cache = cache
+	tc.ssmClientCreator.EXPECT().NewSSMClient(gomock.Any(), gomock.Any(), gomock.Any()).Return(tc.ssmClient, nil).AnyTimes()
+	tc.asmClientCreator.EXPECT().NewASMClient(gomock.Any(), gomock.Any()).Return(tc.asmClient, nil).AnyTimes()
+	return tc
+}
+
+func getParametersOutput(names ...string) *ssm.GetParametersOutput {
+	out := &ssm.GetParametersOutput{}
+	for _, name := range names {
+		out.Parameters = append(out.Parameters, ssmtypes.Parameter{
+			Name:  aws.String(name),
+			Value: aws.String(name + "-value"),
+		})
+	}
+	return out
+}
+
+func TestGetSSMSecretsBatchesConcurrentRequests(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cache := newTestCache(t, ctrl, time.Minute)
+
+	cache.ssmClient.EXPECT().GetParameters(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ interface{}, in *ssm.GetParametersInput, _ ...func(*ssm.Options)) (*ssm.GetParametersOutput, error) {
+			assert.ElementsMatch(t, []string{"a", "b", "c"}, in.Names)
+			assert.True(t, aws.ToBool(in.WithDecryption))
+			return getParametersOutput("a", "b", "c"), nil
+		})
+
+	var wg sync.WaitGroup
+	for _, names := range [][]string{{"a", "b"}, {"b", "c"}} {
+		wg.Add(1)
+		go func(names []string) {
+			defer wg.Done()
+			values, err := cache.GetSSMSecrets(testRegion, testCreds, names)
+			assert.NoError(t, err)
+			assert.Len(t, values, 2)
+			for _, name := range names {
+				assert.Equal(t, name+"-value", values[name])
+			}
+		}(names)
+	}
+	wg.Wait()
+
+	// Served from the cache without another call
+	values, err := cache.GetSSMSecrets(testRegion, testCreds, []string{"a", "c"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "a-value", "c": "c-value"}, values)
+
+	stats := cache.Stats()
+	assert.Equal(t, uint64(2), stats.Hits)
+	assert.Equal(t, uint64(4), stats.Misses)
+	assert.Equal(t, uint64(1), stats.Coalesced)
+	assert.Equal(t, uint64(1), stats.APICalls)
+	assert.Equal(t, 3, stats.Entries)
+}
+
+func TestGetSSMSecretsSplitsFullBatches(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cache := newTestCache(t, ctrl, time.Minute)
+	cache.batchWindow = time.Hour
+
+	names := []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11"}
+	cache.ssmClient.EXPECT().GetParameters(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ interface{}, in *ssm.GetParametersInput, _ ...func(*ssm.Options)) (*ssm.GetParametersOutput, error) {
+			assert.Len(t, in.Names, maxSSMBatchSize)
+			return getParametersOutput(in.Names...), nil
+		})
+
+	var values map[string]string
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		values, _ = cache.GetSSMSecrets(testRegion, testCreds, names)
+	}()
+	// The full batch is sent right away, the remaining parameter waits for the batch window
+	require.Eventually(t, func() bool { return cache.Stats().Entries == maxSSMBatchSize }, time.Second, time.Millisecond)
+
+	cache.ssmClient.EXPECT().GetParameters(gomock.Any(), gomock.Any()).Return(getParametersOutput("11"), nil)
+	cache.lock.Lock()
+	b := cache.batches[batchKey{provider: providerSSM, role: testRoleARN, region: testRegion}]
+	cache.lock.Unlock()
+	require.NotNil(t, b)
+	cache.send(b)
+	<-done
+	assert.Len(t, values, len(names))
+}
+
+func TestGetSSMSecretsInvalidParameterDoesNotFailOtherTasks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cache := newTestCache(t, ctrl, time.Minute)
+
+	out := getParametersOutput("valid")
+	out.InvalidParameters = []string{"invalid"}
+	cache.ssmClient.EXPECT().GetParameters(gomock.Any(), gomock.Any()).Return(out, nil)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_, err := cache.GetSSMSecrets(testRegion, testCreds, []string{"valid", "invalid"})
+		assert.EqualError(t, err, "invalid parameters: invalid")
+	}()
+	go func() {
+		defer wg.Done()
+		values, err := cache.GetSSMSecrets(testRegion, testCreds, []string{"valid"})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"valid": "valid-value"}, values)
+	}()
+	wg.Wait()
+}
+
+func TestGetSSMSecretsScopedToExecutionRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cache := newTestCache(t, ctrl, time.Minute)
+
+	cache.ssmClient.EXPECT().GetParameters(gomock.Any(), gomock.Any()).Return(getParametersOutput("a"), nil).Times(2)
+	_, err := cache.GetSSMSecrets(testRegion, testCreds, []string{"a"})
+	require.NoError(t, err)
+	otherCreds := credentials.IAMRoleCredentials{RoleArn: "arn:aws:iam::123456789012:role/other"}
+	_, err = cache.GetSSMSecrets(testRegion, otherCreds, []string{"a"})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), cache.Stats().Hits)
+}
+
+func TestCacheExpiry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cache := newTestCache(t, ctrl, 20*time.Millisecond)
+
+	cache.ssmClient.EXPECT().GetParameters(gomock.Any(), gomock.Any()).Return(getParametersOutput("a"), nil).Times(2)
+	_, err := cache.GetSSMSecrets(testRegion, testCreds, []string{"a"})
+	require.NoError(t, err)
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, 0, cache.Stats().Entries)
+	_, err = cache.GetSSMSecrets(testRegion, testCreds, []string{"a"})
+	require.NoError(t, err)
+}
+
+func TestCacheDisabledWithoutTTL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cache := newTestCache(t, ctrl, 0)
+
+	cache.ssmClient.EXPECT().GetParameters(gomock.Any(), gomock.Any()).Return(getParametersOutput("a"), nil).Times(2)
+	for i := 0; i < 2; i++ {
+		_, err := cache.GetSSMSecrets(testRegion, testCreds, []string{"a"})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 0, cache.Stats().Entries)
+}
+
+func TestCacheValuesEncrypted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cache := newTestCache(t, ctrl, time.Minute)
+
+	key := cacheKey{provider: providerSSM, role: testRoleARN, region: testRegion, secretID: "a"}
+	cache.set(key, "plaintext")
+	assert.False(t, bytes.Contains(cache.entries[key].sealed, []byte("plaintext")))
+
+	// A value cannot be read back under another key
+	otherKey := key
+	otherKey.secretID = "b"
+	cache.entries[otherKey] = cache.entries[key]
+	_, ok := cache.get(otherKey)
+	assert.False(t, ok)
+	value, ok := cache.get(key)
+	assert.True(t, ok)
+	assert.Equal(t, "plaintext", value)
+}
+
+func TestGetASMSecret(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cache := newTestCache(t, ctrl, time.Minute)
+
+	cache.asmClient.EXPECT().BatchGetSecretValue(gomock.Any(), &secretsmanager.BatchGetSecretValueInput{
+		SecretIdList: []string{testSecretARN},
+	}).Return(&secretsmanager.BatchGetSecretValueOutput{
+		SecretValues: []secretsmanagertypes.SecretValueEntry{
+			{ARN: aws.String(testSecretARN), SecretString: aws.String("current")},
+		},
+	}, nil)
+	cache.asmClient.EXPECT().GetSecretValue(gomock.Any(), &secretsmanager.GetSecretValueInput{
+		SecretId:     aws.String(testSecretARN),
+		VersionStage: aws.String("AWSPREVIOUS"),
+	}).Return(&secretsmanager.GetSecretValueOutput{SecretString: aws.String("previous")}, nil)
+
+	for i := 0; i < 2; i++ {
+		value, err := cache.GetASMSecret(testRegion, testCreds, testSecretARN, "", "")
+		require.NoError(t, err)
+		assert.Equal(t, "current", value)
+		value, err = cache.GetASMSecret(testRegion, testCreds, testSecretARN, "AWSPREVIOUS", "")
+		require.NoError(t, err)
+		assert.Equal(t, "previous", value)
+	}
+
+	stats := cache.Stats()
+	assert.Equal(t, uint64(2), stats.Hits)
+	assert.Equal(t, uint64(2), stats.APICalls)
+}
+
+func TestGetASMSecretError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cache := newTestCache(t, ctrl, time.Minute)
+
+	cache.asmClient.EXPECT().BatchGetSecretValue(gomock.Any(), gomock.Any()).Return(&secretsmanager.BatchGetSecretValueOutput{
+		Errors: []secretsmanagertypes.APIErrorType{
+			{SecretId: aws.String(testSecretARN), ErrorCode: aws.String("AccessDeniedException")},
+		},
+	}, nil)
+
+	_, err := cache.GetASMSecret(testRegion, testCreds, testSecretARN, "", "")
+	assert.Error(t, err)
+	assert.Equal(t, 0, cache.Stats().Entries)
+}
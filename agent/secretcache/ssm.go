@@ -0,0 +1,92 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package secretcache
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/amazon-ecs-agent/agent/ssm"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
+
+	"github.com/pkg/errors"
+)
+
+// maxSSMBatchSize is the maximum number of parameters that the SSM GetParameters API accepts at one time
+const maxSSMBatchSize = 10
+
+// GetSSMSecrets returns the decrypted values of the SSM parameters in the region, keyed by
+// parameter name. Parameters that are not cached are retrieved in batches with the other tasks
+// requesting parameters with the same execution role.
+func (c *Cache) GetSSMSecrets(region string, creds credentials.IAMRoleCredentials, names []string) (map[string]string, error) {
+	role := roleKey(creds)
+	values := make(map[string]string)
+	batches := make(map[string]*batch)
+	for _, name := range names {
+		if _, ok := values[name]; ok {
+			continue
+		}
+		if _, ok := batches[name]; ok {
+			continue
+		}
+		value, ok := c.get(cacheKey{provider: providerSSM, role: role, region: region, secretID: name})
+		if ok {
+			c.hits.Add(1)
+			values[name] = value
+			continue
+		}
+		c.misses.Add(1)
+		batches[name] = c.enqueue(batchKey{provider: providerSSM, role: role, region: region}, creds, name, maxSSMBatchSize)
+	}
+
+	var invalidParameters []string
+	for name, b := range batches {
+		value, err := b.result(name)
+		if err == errInvalidParameter {
+			invalidParameters = append(invalidParameters, name)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		values[name] = value
+	}
+	if len(invalidParameters) > 0 {
+		sort.Strings(invalidParameters)
+		return nil, fmt.Errorf("invalid parameters: %s", strings.Join(invalidParameters, ","))
+	}
+	return values, nil
+}
+
+// errInvalidParameter is the error of a parameter reported as invalid by SSM.
+var errInvalidParameter = errors.New("invalid parameter")
+
+func (c *Cache) sendSSM(b *batch) {
+	client, err := c.ssmClientCreator.NewSSMClient(b.key.region, b.creds, c.ipCompatibility)
+	if err != nil {
+		b.err = fmt.Errorf("unable to create SSM client in %s: %v", b.key.region, err)
+		return
+	}
+	values, invalidParameters, err := ssm.GetSecretsAndInvalidParametersFromSSM(b.ids, client)
+	if err != nil {
+		b.err = err
+		return
+	}
+	b.values = values
+	b.errs = make(map[string]error)
+	for _, name := range invalidParameters {
+		b.errs[name] = errInvalidParameter
+	}
+}
@@ -68,3 +68,23 @@ func (mr *MockSSMClientMockRecorder) GetParameters(arg0, arg1 interface{}, arg2
 	varargs := append([]interface{}{arg0, arg1}, arg2...)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetParameters", reflect.TypeOf((*MockSSMClient)(nil).GetParameters), varargs...)
 }
+
+// GetParametersByPath mocks base method.
+func (m *MockSSMClient) GetParametersByPath(arg0 context.Context, arg1 *ssm.GetParametersByPathInput, arg2 ...func(*ssm.Options)) (*ssm.GetParametersByPathOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetParametersByPath", varargs...)
+	ret0, _ := ret[0].(*ssm.GetParametersByPathOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetParametersByPath indicates an expected call of GetParametersByPath.
+func (mr *MockSSMClientMockRecorder) GetParametersByPath(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetParametersByPath", reflect.TypeOf((*MockSSMClient)(nil).GetParametersByPath), varargs...)
+}
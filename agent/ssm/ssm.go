@@ -35,13 +35,58 @@ func GetParametersFromSSM(names []string, client SSMClient) (map[string]string,
 	return getParameters(names, client, false)
 }
 
-func getParameters(names []string, client SSMClient, withDecryption bool) (map[string]string, error) {
-	in := &ssm.GetParametersInput{
-		Names:          names,
-		WithDecryption: aws.Bool(withDecryption),
+// GetSecretsAndInvalidParametersFromSSM makes the api call to the AWS SSM parameter store to
+// retrieve secrets value in batches. Unlike GetSecretsFromSSM, invalid parameters do not fail the
+// call but are returned alongside the values of the valid ones.
+func GetSecretsAndInvalidParametersFromSSM(names []string, client SSMClient) (map[string]string, []string, error) {
+	out, err := callGetParameters(names, client, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	if out == nil {
+		return nil, nil, errors.New("empty response")
 	}
 
-	out, err := client.GetParameters(context.TODO(), in)
+	parameterValues := make(map[string]string)
+	for _, parameter := range out.Parameters {
+		parameterValues[aws.ToString(parameter.Name)] = aws.ToString(parameter.Value)
+	}
+	return parameterValues, out.InvalidParameters, nil
+}
+
+// GetParametersByPathFromSSM makes the api calls to the AWS SSM parameter store to retrieve the
+// decrypted values of the parameters directly under the path, keyed by the name of the parameter
+// relative to the path
+func GetParametersByPathFromSSM(path string, client SSMClient) (map[string]string, error) {
+	in := &ssm.GetParametersByPathInput{
+		Path:           aws.String(path),
+		Recursive:      aws.Bool(false),
+		WithDecryption: aws.Bool(true),
+	}
+
+	parameterValues := make(map[string]string)
+	prefix := strings.TrimSuffix(path, "/") + "/"
+	for {
+		out, err := client.GetParametersByPath(context.TODO(), in)
+		if err != nil {
+			return nil, err
+		}
+		if out == nil {
+			return nil, errors.New("empty response")
+		}
+		for _, parameter := range out.Parameters {
+			name := strings.TrimPrefix(aws.ToString(parameter.Name), prefix)
+			parameterValues[name] = aws.ToString(parameter.Value)
+		}
+		if aws.ToString(out.NextToken) == "" {
+			return parameterValues, nil
+		}
+		in.NextToken = out.NextToken
+	}
+}
+
+func getParameters(names []string, client SSMClient, withDecryption bool) (map[string]string, error) {
+	out, err := callGetParameters(names, client, withDecryption)
 	if err != nil {
 		return nil, err
 	}
@@ -49,6 +94,15 @@ func getParameters(names []string, client SSMClient, withDecryption bool) (map[s
 	return extractSSMValues(out)
 }
 
+func callGetParameters(names []string, client SSMClient, withDecryption bool) (*ssm.GetParametersOutput, error) {
+	in := &ssm.GetParametersInput{
+		Names:          names,
+		WithDecryption: aws.Bool(withDecryption),
+	}
+
+	return client.GetParameters(context.TODO(), in)
+}
+
 func extractSSMValues(out *ssm.GetParametersOutput) (map[string]string, error) {
 	if out == nil {
 		return nil, errors.New(
@@ -128,3 +128,47 @@ func TestGetParametersFromSSM(t *testing.T) {
 		})
 	}
 }
+
+func TestGetSecretsAndInvalidParametersFromSSM(t *testing.T) {
+	ssmClient := mockGetParameters{Resp: ssm.GetParametersOutput{
+		InvalidParameters: []string{invalidParam1},
+		Parameters: []ssmtypes.Parameter{
+			{Name: aws.String(validParam1), Value: aws.String(validValue1)},
+		},
+	}}
+	values, invalid, err := GetSecretsAndInvalidParametersFromSSM([]string{validParam1, invalidParam1}, ssmClient)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{validParam1: validValue1}, values)
+	assert.Equal(t, []string{invalidParam1}, invalid)
+}
+
+type mockGetParametersByPath struct {
+	SSMClient
+	Pages []ssm.GetParametersByPathOutput
+}
+
+func (m *mockGetParametersByPath) GetParametersByPath(ctx context.Context, input *ssm.GetParametersByPathInput, optFns ...func(*ssm.Options)) (*ssm.GetParametersByPathOutput, error) {
+	page := m.Pages[0]
+	m.Pages = m.Pages[1:]
+	return &page, nil
+}
+
+func TestGetParametersByPathFromSSM(t *testing.T) {
+	ssmClient := &mockGetParametersByPath{Pages: []ssm.GetParametersByPathOutput{
+		{
+			Parameters: []ssmtypes.Parameter{
+				{Name: aws.String("/app/prod/DB_HOST"), Value: aws.String("db.example.com")},
+			},
+			NextToken: aws.String("token"),
+		},
+		{
+			Parameters: []ssmtypes.Parameter{
+				{Name: aws.String("/app/prod/DB_PORT"), Value: aws.String("5432")},
+			},
+		},
+	}}
+	values, err := GetParametersByPathFromSSM("/app/prod", ssmClient)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"DB_HOST": "db.example.com", "DB_PORT": "5432"}, values)
+	assert.Empty(t, ssmClient.Pages)
+}
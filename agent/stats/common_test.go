@@ -28,6 +28,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/data"
 	dm "github.com/aws/amazon-ecs-agent/agent/engine/daemonmanager"
 	"github.com/aws/amazon-ecs-agent/agent/statechange"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/ec2"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/eventstream"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/ipcompatibility"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/tcs/model/ecstcs"
@@ -359,12 +360,19 @@ func (engine *MockTaskEngine) StateChangeEvents() chan statechange.Event {
 func (engine *MockTaskEngine) SetDataClient(data.Client) {
 }
 
+func (engine *MockTaskEngine) SetInstanceMetadata(string, ec2.EC2MetadataClient) {
+}
+
 func (engine *MockTaskEngine) AddTask(*apitask.Task) {
 }
 
 func (engine *MockTaskEngine) UpsertTask(*apitask.Task) {
 }
 
+func (engine *MockTaskEngine) ReloadFirelensConfig(string, map[string]map[string]string) error {
+	return nil
+}
+
 func (engine *MockTaskEngine) ListTasks() ([]*apitask.Task, error) {
 	return nil, nil
 }
@@ -84,6 +84,8 @@ type Engine interface {
 	GetPublishServiceConnectTickerInterval() int32
 	SetPublishServiceConnectTickerInterval(int32)
 	GetPublishMetricsTicker() *time.Ticker
+	TaskNetworkFlowStats(taskARN string) (*stats.NetworkFlowStats, error)
+	TaskServiceConnectStats(taskARN string) (*stats.ServiceConnectStats, error)
 }
 
 // DockerStatsEngine is used to monitor docker container events and to report
@@ -325,7 +327,8 @@ func (engine *DockerStatsEngine) addToStatsTaskMapUnsafe(task *apitask.Task, doc
 			}
 			containerpid := strconv.Itoa(containerInspect.State.Pid)
 			statsTaskContainer, err = newStatsTaskContainer(task.Arn, task.GetID(), containerpid, numberOfContainers,
-				engine.resolver, engine.config.PollingMetricsWaitDuration, task.ENIs)
+				engine.resolver, engine.config.PollingMetricsWaitDuration, task.ENIs,
+				newNetworkFlowBuckets(engine.config))
 			if err != nil {
 				return
 			}
@@ -985,6 +988,40 @@ func (engine *DockerStatsEngine) resetStatsUnsafe() {
 	}
 }
 
+// TaskNetworkFlowStats returns the last traffic of an awsvpc task grouped by remote, when network
+// flow accounting is enabled
+func (engine *DockerStatsEngine) TaskNetworkFlowStats(taskARN string) (*stats.NetworkFlowStats, error) {
+	engine.lock.RLock()
+	defer engine.lock.RUnlock()
+
+	taskStats, ok := engine.taskToTaskStats[taskARN]
+	if !ok {
+		return nil, errors.Errorf("stats engine: task stats not found: %s", taskARN)
+	}
+	flowStats := taskStats.getNetworkFlowStats()
+	if flowStats == nil {
+		return nil, errors.Errorf("stats engine: network flow stats not available for task: %s", taskARN)
+	}
+	return flowStats, nil
+}
+
+// TaskServiceConnectStats returns the per upstream view of the Service Connect proxy of a task on
+// its traffic, as last retrieved from the AppNet agent
+func (engine *DockerStatsEngine) TaskServiceConnectStats(taskARN string) (*stats.ServiceConnectStats, error) {
+	engine.lock.RLock()
+	defer engine.lock.RUnlock()
+
+	serviceConnectStats, ok := engine.taskToServiceConnectStats[taskARN]
+	if !ok {
+		return nil, errors.Errorf("stats engine: service connect stats not found: %s", taskARN)
+	}
+	upstreamStats := serviceConnectStats.GetUpstreamStats()
+	if upstreamStats == nil {
+		return nil, errors.Errorf("stats engine: service connect stats not retrieved yet for task: %s", taskARN)
+	}
+	return upstreamStats, nil
+}
+
 // ContainerDockerStats returns the last stored raw docker stats object for a container
 func (engine *DockerStatsEngine) ContainerDockerStats(taskARN string, containerID string) (*types.StatsJSON, *stats.NetworkStatsPerSec, error) {
 	engine.lock.RLock()
@@ -138,3 +138,33 @@ func (mr *MockEngineMockRecorder) SetPublishServiceConnectTickerInterval(arg0 in
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPublishServiceConnectTickerInterval", reflect.TypeOf((*MockEngine)(nil).SetPublishServiceConnectTickerInterval), arg0)
 }
+
+// TaskNetworkFlowStats mocks base method.
+func (m *MockEngine) TaskNetworkFlowStats(arg0 string) (*stats.NetworkFlowStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TaskNetworkFlowStats", arg0)
+	ret0, _ := ret[0].(*stats.NetworkFlowStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TaskNetworkFlowStats indicates an expected call of TaskNetworkFlowStats.
+func (mr *MockEngineMockRecorder) TaskNetworkFlowStats(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TaskNetworkFlowStats", reflect.TypeOf((*MockEngine)(nil).TaskNetworkFlowStats), arg0)
+}
+
+// TaskServiceConnectStats mocks base method.
+func (m *MockEngine) TaskServiceConnectStats(arg0 string) (*stats.ServiceConnectStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TaskServiceConnectStats", arg0)
+	ret0, _ := ret[0].(*stats.ServiceConnectStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TaskServiceConnectStats indicates an expected call of TaskServiceConnectStats.
+func (mr *MockEngineMockRecorder) TaskServiceConnectStats(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TaskServiceConnectStats", reflect.TypeOf((*MockEngine)(nil).TaskServiceConnectStats), arg0)
+}
@@ -0,0 +1,59 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"strconv"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/stats"
+)
+
+// networkFlowBuckets are the remotes the traffic of awsvpc tasks is accounted to. Traffic is
+// accounted to the first matching remote, in the order of remotes().
+type networkFlowBuckets struct {
+	awsServiceCIDRs []string
+	remoteCIDRs     []string
+	remotePorts     []uint16
+}
+
+// newNetworkFlowBuckets returns the remotes configured for network flow accounting, or nil if
+// it is disabled.
+func newNetworkFlowBuckets(cfg *config.Config) *networkFlowBuckets {
+	if !cfg.TaskNetworkFlowStatsEnabled.Enabled() {
+		return nil
+	}
+	return &networkFlowBuckets{
+		awsServiceCIDRs: cfg.TaskNetworkFlowAWSServiceCIDRs,
+		remoteCIDRs:     cfg.TaskNetworkFlowRemoteCIDRs,
+		remotePorts:     cfg.TaskNetworkFlowRemotePorts,
+	}
+}
+
+// remotes returns the names of the remotes in the order traffic is matched against them.
+func (b *networkFlowBuckets) remotes() []string {
+	var remotes []string
+	if len(b.awsServiceCIDRs) > 0 {
+		remotes = append(remotes, stats.NetworkFlowRemoteAWSServices)
+	}
+	remotes = append(remotes, b.remoteCIDRs...)
+	for _, port := range b.remotePorts {
+		remotes = append(remotes, portRemote(port))
+	}
+	return append(remotes, stats.NetworkFlowRemoteOther)
+}
+
+func portRemote(port uint16) string {
+	return stats.NetworkFlowRemotePortPrefix + strconv.Itoa(int(port))
+}
@@ -0,0 +1,260 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecscni"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/stats"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/execwrapper"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// networkFlowTxChain and networkFlowRxChain are the chains counting the traffic of the task by
+	// remote inside its network namespace. They live in the mangle table, so that they see every
+	// packet regardless of the filter rules set up for the task.
+	networkFlowTxChain = "ECS-FLOW-TX"
+	networkFlowRxChain = "ECS-FLOW-RX"
+	networkFlowTable   = "mangle"
+
+	iptablesCommand           = "iptables"
+	ip6tablesCommand          = "ip6tables"
+	networkFlowCommandTimeout = 5 * time.Second
+	networkFlowChainHeader    = 2
+)
+
+var networkFlowCommentRegex = regexp.MustCompile(`/\* (.+) \*/`)
+
+// networkFlowDirection is the hook of a counting chain and how it matches the remote of a packet.
+type networkFlowDirection struct {
+	chain      string
+	hook       string
+	localIf    string
+	remoteIP   string
+	remotePort string
+}
+
+var (
+	networkFlowTx = networkFlowDirection{
+		chain:      networkFlowTxChain,
+		hook:       "POSTROUTING",
+		localIf:    "-o",
+		remoteIP:   "-d",
+		remotePort: "--dport",
+	}
+	networkFlowRx = networkFlowDirection{
+		chain:      networkFlowRxChain,
+		hook:       "INPUT",
+		localIf:    "-i",
+		remoteIP:   "-s",
+		remotePort: "--sport",
+	}
+)
+
+// retrieveNetworkFlowStatistics returns the traffic of the task by remote. The counting chains
+// are set up in the network namespace of the task the first time, which resets any counters
+// left behind by a previous agent.
+func (taskStat *StatsTask) retrieveNetworkFlowStatistics() (*stats.NetworkFlowStats, error) {
+	if taskStat.networkFlowCommands == nil {
+		commands, err := taskStat.setUpNetworkFlowAccounting()
+		if err != nil {
+			return nil, err
+		}
+		taskStat.networkFlowCommands = commands
+	}
+
+	flows := make(map[string]*stats.NetworkFlow)
+	for _, remote := range taskStat.networkFlowBuckets.remotes() {
+		flows[remote] = &stats.NetworkFlow{Remote: remote}
+	}
+	for _, command := range taskStat.networkFlowCommands {
+		for _, direction := range []networkFlowDirection{networkFlowTx, networkFlowRx} {
+			out, err := taskStat.runInTaskNetNS(command, "-w", "-t", networkFlowTable,
+				"-L", direction.chain, "-v", "-x", "-n")
+			if err != nil {
+				return nil, err
+			}
+			counters, err := parseNetworkFlowCounters(out)
+			if err != nil {
+				return nil, err
+			}
+			for remote, counter := range counters {
+				flow, ok := flows[remote]
+				if !ok {
+					continue
+				}
+				if direction == networkFlowTx {
+					flow.TxPackets += counter.packets
+					flow.TxBytes += counter.bytes
+				} else {
+					flow.RxPackets += counter.packets
+					flow.RxBytes += counter.bytes
+				}
+			}
+		}
+	}
+
+	flowStats := &stats.NetworkFlowStats{Read: time.Now()}
+	for _, remote := range taskStat.networkFlowBuckets.remotes() {
+		flowStats.Flows = append(flowStats.Flows, *flows[remote])
+	}
+	return flowStats, nil
+}
+
+// setUpNetworkFlowAccounting creates the counting chains of both IP families and returns the
+// iptables commands of the families they could be created for. IPv6 is best effort, since the
+// host may not support it.
+func (taskStat *StatsTask) setUpNetworkFlowAccounting() ([]string, error) {
+	var commands []string
+	for _, command := range []string{iptablesCommand, ip6tablesCommand} {
+		ipv6 := command == ip6tablesCommand
+		// Remove the chains of a previous agent, if any. These fail when there are none.
+		for _, direction := range []networkFlowDirection{networkFlowTx, networkFlowRx} {
+			for _, rule := range [][]string{
+				{"-D", direction.hook, "-j", direction.chain},
+				{"-F", direction.chain},
+				{"-X", direction.chain},
+			} {
+				taskStat.runInTaskNetNS(command, append([]string{"-w", "-t", networkFlowTable}, rule...)...)
+			}
+		}
+
+		var err error
+		for _, direction := range []networkFlowDirection{networkFlowTx, networkFlowRx} {
+			for _, rule := range networkFlowRules(taskStat.networkFlowBuckets, direction, ipv6) {
+				if _, err = taskStat.runInTaskNetNS(command,
+					append([]string{"-w", "-t", networkFlowTable}, rule...)...); err != nil {
+					break
+				}
+			}
+			if err != nil {
+				break
+			}
+		}
+		if err != nil {
+			if !ipv6 {
+				return nil, errors.Wrap(err, "failed to set up network flow accounting")
+			}
+			logger.Warn("Unable to set up IPv6 network flow accounting for task", logger.Fields{
+				field.TaskID: taskStat.TaskMetadata.TaskId,
+				field.Error:  err,
+			})
+			continue
+		}
+		commands = append(commands, command)
+	}
+	return commands, nil
+}
+
+// networkFlowRules returns the iptables commands that build the counting chain of a direction
+// for an IP family. Every rule returns on match, so that packets are only counted for the first
+// remote they match, and loopback traffic is not counted at all.
+func networkFlowRules(buckets *networkFlowBuckets, direction networkFlowDirection, ipv6 bool) [][]string {
+	chain := direction.chain
+	rules := [][]string{
+		{"-N", chain},
+		{"-A", chain, direction.localIf, "lo", "-j", "RETURN"},
+	}
+	comment := func(remote string) []string {
+		return []string{"-m", "comment", "--comment", remote, "-j", "RETURN"}
+	}
+
+	for _, cidr := range familyCIDRs(buckets.awsServiceCIDRs, ipv6) {
+		rules = append(rules, append([]string{"-A", chain, direction.remoteIP, cidr},
+			comment(stats.NetworkFlowRemoteAWSServices)...))
+	}
+	for _, cidr := range familyCIDRs(buckets.remoteCIDRs, ipv6) {
+		rules = append(rules, append([]string{"-A", chain, direction.remoteIP, cidr}, comment(cidr)...))
+	}
+	for _, port := range buckets.remotePorts {
+		for _, protocol := range []string{"tcp", "udp"} {
+			rules = append(rules, append([]string{"-A", chain, "-p", protocol,
+				direction.remotePort, strconv.Itoa(int(port))}, comment(portRemote(port))...))
+		}
+	}
+
+	return append(rules,
+		append([]string{"-A", chain}, comment(stats.NetworkFlowRemoteOther)...),
+		[]string{"-A", direction.hook, "-j", chain},
+	)
+}
+
+// familyCIDRs returns the CIDRs of an IP family.
+func familyCIDRs(cidrs []string, ipv6 bool) []string {
+	var family []string
+	for _, cidr := range cidrs {
+		ip, _, err := net.ParseCIDR(cidr)
+		if err != nil {
+			continue
+		}
+		if (ip.To4() == nil) == ipv6 {
+			family = append(family, cidr)
+		}
+	}
+	return family
+}
+
+type networkFlowCounter struct {
+	packets uint64
+	bytes   uint64
+}
+
+// parseNetworkFlowCounters returns the packet and byte counters of the rules in the verbose
+// listing of a counting chain, summed by the remote in their comment.
+func parseNetworkFlowCounters(out []byte) (map[string]networkFlowCounter, error) {
+	counters := make(map[string]networkFlowCounter)
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for line := 0; scanner.Scan(); line++ {
+		text := scanner.Text()
+		match := networkFlowCommentRegex.FindStringSubmatch(text)
+		fields := strings.Fields(text)
+		if line < networkFlowChainHeader || match == nil || len(fields) < 2 {
+			continue
+		}
+		packets, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		bytes, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		counter := counters[match[1]]
+		counter.packets += packets
+		counter.bytes += bytes
+		counters[match[1]] = counter
+	}
+	return counters, nil
+}
+
+// runInTaskNetNS runs an iptables command inside the network namespace of the task and returns
+// its output.
+func (taskStat *StatsTask) runInTaskNetNS(command string, args ...string) ([]byte, error) {
+	netNSPath := fmt.Sprintf(ecscni.NetnsFormat, taskStat.TaskMetadata.ContainerPID)
+	return execwrapper.RunInNetNS(context.Background(), taskStat.execwrapper, networkFlowCommandTimeout,
+		netNSPath, command, args...)
+}
@@ -0,0 +1,151 @@
+//go:build linux && unit
+// +build linux,unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/stats"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/execwrapper"
+	mock_execwrapper "github.com/aws/amazon-ecs-agent/ecs-agent/utils/execwrapper/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const networkFlowTxListing = `Chain ECS-FLOW-TX (1 references)
+    pkts      bytes target     prot opt in     out     source               destination
+      12     1200 RETURN     all  --  *      lo      0.0.0.0/0            0.0.0.0/0
+      10     5000 RETURN     all  --  *      *       0.0.0.0/0            52.216.0.0/15        /* aws-services */
+       4      400 RETURN     all  --  *      *       0.0.0.0/0            10.0.0.0/8           /* 10.0.0.0/8 */
+       3      300 RETURN     tcp  --  *      *       0.0.0.0/0            0.0.0.0/0            tcp dpt:443 /* port/443 */
+       1      100 RETURN     udp  --  *      *       0.0.0.0/0            0.0.0.0/0            udp dpt:443 /* port/443 */
+       2      128 RETURN     all  --  *      *       0.0.0.0/0            0.0.0.0/0            /* other */
+`
+
+func testNetworkFlowBuckets() *networkFlowBuckets {
+	return &networkFlowBuckets{
+		awsServiceCIDRs: []string{"52.216.0.0/15", "2600:1f00::/24"},
+		remoteCIDRs:     []string{"10.0.0.0/8"},
+		remotePorts:     []uint16{443},
+	}
+}
+
+func TestNetworkFlowRules(t *testing.T) {
+	buckets := testNetworkFlowBuckets()
+	join := func(rules [][]string) []string {
+		var joined []string
+		for _, rule := range rules {
+			joined = append(joined, strings.Join(rule, " "))
+		}
+		return joined
+	}
+
+	assert.Equal(t, []string{
+		"-N ECS-FLOW-TX",
+		"-A ECS-FLOW-TX -o lo -j RETURN",
+		"-A ECS-FLOW-TX -d 52.216.0.0/15 -m comment --comment aws-services -j RETURN",
+		"-A ECS-FLOW-TX -d 10.0.0.0/8 -m comment --comment 10.0.0.0/8 -j RETURN",
+		"-A ECS-FLOW-TX -p tcp --dport 443 -m comment --comment port/443 -j RETURN",
+		"-A ECS-FLOW-TX -p udp --dport 443 -m comment --comment port/443 -j RETURN",
+		"-A ECS-FLOW-TX -m comment --comment other -j RETURN",
+		"-A POSTROUTING -j ECS-FLOW-TX",
+	}, join(networkFlowRules(buckets, networkFlowTx, false)))
+
+	// Only the CIDRs of the IP family are matched, and the remote is the source of inbound traffic.
+	assert.Equal(t, []string{
+		"-N ECS-FLOW-RX",
+		"-A ECS-FLOW-RX -i lo -j RETURN",
+		"-A ECS-FLOW-RX -s 2600:1f00::/24 -m comment --comment aws-services -j RETURN",
+		"-A ECS-FLOW-RX -p tcp --sport 443 -m comment --comment port/443 -j RETURN",
+		"-A ECS-FLOW-RX -p udp --sport 443 -m comment --comment port/443 -j RETURN",
+		"-A ECS-FLOW-RX -m comment --comment other -j RETURN",
+		"-A INPUT -j ECS-FLOW-RX",
+	}, join(networkFlowRules(buckets, networkFlowRx, true)))
+}
+
+func TestParseNetworkFlowCounters(t *testing.T) {
+	counters, err := parseNetworkFlowCounters([]byte(networkFlowTxListing))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]networkFlowCounter{
+		stats.NetworkFlowRemoteAWSServices: {packets: 10, bytes: 5000},
+		"10.0.0.0/8":                       {packets: 4, bytes: 400},
+		"port/443":                         {packets: 4, bytes: 400},
+		stats.NetworkFlowRemoteOther:       {packets: 2, bytes: 128},
+	}, counters)
+
+	_, err = parseNetworkFlowCounters([]byte("header\nheader\nx 1 RETURN /* other */"))
+	assert.Error(t, err)
+}
+
+func TestRetrieveNetworkFlowStatistics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	execWrapper := mock_execwrapper.NewMockExec(ctrl)
+	taskStats := &StatsTask{
+		statsTaskCommon: &statsTaskCommon{
+			TaskMetadata:       &TaskMetadata{TaskId: "task1", ContainerPID: "23"},
+			networkFlowBuckets: testNetworkFlowBuckets(),
+		},
+		execwrapper: execWrapper,
+	}
+
+	var commands []string
+	execWrapper.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).
+		Return(context.TODO(), func() {}).AnyTimes()
+	execWrapper.EXPECT().CommandContext(gomock.Any(), execwrapper.NsenterCommand, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, args ...string) *mock_execwrapper.MockCmd {
+			command := strings.Join(args, " ")
+			commands = append(commands, command)
+			cmd := mock_execwrapper.NewMockCmd(ctrl)
+			switch {
+			case strings.Contains(command, "ip6tables"):
+				// IPv6 accounting is best effort.
+				cmd.EXPECT().CombinedOutput().Return(nil, errors.New("ip6tables unavailable"))
+			case strings.Contains(command, "-L ECS-FLOW-TX"):
+				cmd.EXPECT().CombinedOutput().Return([]byte(networkFlowTxListing), nil)
+			default:
+				cmd.EXPECT().CombinedOutput().Return(nil, nil)
+			}
+			return cmd
+		}).AnyTimes()
+
+	flowStats, err := taskStats.retrieveNetworkFlowStatistics()
+	require.NoError(t, err)
+	assert.Equal(t, []string{iptablesCommand}, taskStats.networkFlowCommands)
+	assert.Equal(t, []stats.NetworkFlow{
+		{Remote: stats.NetworkFlowRemoteAWSServices, TxPackets: 10, TxBytes: 5000},
+		{Remote: "10.0.0.0/8", TxPackets: 4, TxBytes: 400},
+		{Remote: "port/443", TxPackets: 4, TxBytes: 400},
+		{Remote: stats.NetworkFlowRemoteOther, TxPackets: 2, TxBytes: 128},
+	}, flowStats.Flows)
+	assert.Contains(t, commands, "--net=/host/proc/23/ns/net iptables -w -t mangle -A POSTROUTING -j ECS-FLOW-TX")
+
+	// The chains are only set up once.
+	commands = nil
+	_, err = taskStats.retrieveNetworkFlowStatistics()
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"--net=/host/proc/23/ns/net iptables -w -t mangle -L ECS-FLOW-TX -v -x -n",
+		"--net=/host/proc/23/ns/net iptables -w -t mangle -L ECS-FLOW-RX -v -x -n",
+	}, commands)
+}
@@ -20,21 +20,24 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/aws/amazon-ecs-agent/ecs-agent/api/appnet"
 
 	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/stats"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/tcs/model/ecstcs"
 	prometheus "github.com/prometheus/client_model/go"
 )
 
 type ServiceConnectStats struct {
-	stats        []*ecstcs.GeneralMetricsWrapper
-	appnetClient appnet.AppNetClient
-	sent         bool
-	lock         sync.RWMutex
+	stats         []*ecstcs.GeneralMetricsWrapper
+	upstreamStats *stats.ServiceConnectStats
+	appnetClient  appnet.AppNetClient
+	sent          bool
+	lock          sync.RWMutex
 }
 
 const (
@@ -77,6 +80,7 @@ func (sc *ServiceConnectStats) retrieveServiceConnectStats(task *apitask.Task) {
 	}
 	sc.resetStats()
 	sc.setStats(statsCollectedList)
+	sc.accumulateUpstreamStats(convertToUpstreamStats(stats))
 }
 
 func convertToTACSStats(mf map[string]*prometheus.MetricFamily, taskId string) ([]*ecstcs.GeneralMetricsWrapper, error) {
@@ -201,6 +205,50 @@ func (sc *ServiceConnectStats) GetStats() []*ecstcs.GeneralMetricsWrapper {
 	return sc.stats
 }
 
+// accumulateUpstreamStats adds the counters of the upstreams to the ones retrieved before, as the
+// AppNet agent returns the change of its counters since the previous retrieval. Upstreams without
+// traffic since the previous retrieval keep their counters, while their circuit breaker, a gauge that
+// is only reported along with traffic, is considered closed.
+func (sc *ServiceConnectStats) accumulateUpstreamStats(upstreamStats *stats.ServiceConnectStats) {
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+
+	if sc.upstreamStats != nil {
+		previous := make(map[string]stats.ServiceConnectUpstreamStat, len(sc.upstreamStats.Upstreams))
+		for _, upstream := range sc.upstreamStats.Upstreams {
+			previous[upstream.Direction+"/"+upstream.Name] = upstream
+		}
+		for i := range upstreamStats.Upstreams {
+			upstream := &upstreamStats.Upstreams[i]
+			key := upstream.Direction + "/" + upstream.Name
+			if prev, ok := previous[key]; ok {
+				upstream.RequestCount += prev.RequestCount
+				upstream.RetryCount += prev.RetryCount
+				delete(previous, key)
+			}
+		}
+		for _, prev := range previous {
+			upstreamStats.Upstreams = append(upstreamStats.Upstreams, stats.ServiceConnectUpstreamStat{
+				Name:         prev.Name,
+				Direction:    prev.Direction,
+				RequestCount: prev.RequestCount,
+				RetryCount:   prev.RetryCount,
+			})
+		}
+		sortUpstreams(upstreamStats.Upstreams)
+	}
+	sc.upstreamStats = upstreamStats
+}
+
+// GetUpstreamStats returns the per upstream view of the last retrieved stats, or nil if the stats
+// have not been retrieved yet.
+func (sc *ServiceConnectStats) GetUpstreamStats() *stats.ServiceConnectStats {
+	sc.lock.RLock()
+	defer sc.lock.RUnlock()
+
+	return sc.upstreamStats
+}
+
 func (sc *ServiceConnectStats) SetStatsSent(sent bool) {
 	sc.lock.Lock()
 	defer sc.lock.Unlock()
@@ -261,3 +309,139 @@ func convertDimensionsintoStrings(dimension []*ecstcs.Dimension) string {
 	}
 	return sb.String()
 }
+
+const (
+	// Metrics and labels of the AppNet agent that make up the per upstream view of the stats.
+	requestCountMetric       = "RequestCount"
+	retryCountMetric         = "RequestRetryCount"
+	targetResponseTimeMetric = "TargetResponseTime"
+	circuitBreakerOpenMetric = "CircuitBreakerOpen"
+	directionLabel           = "Direction"
+	discoveryNameLabel       = "DiscoveryName"
+	targetDiscoveryNameLabel = "TargetDiscoveryName"
+)
+
+// upstreamHistogram accumulates the cumulative bucket counts of the response time histograms of an
+// upstream, keyed by bucket upper bound.
+type upstreamHistogram map[float64]uint64
+
+// convertToUpstreamStats aggregates the stats of the proxy by upstream and direction. Egress
+// traffic is named after the upstream service it targets and ingress traffic after the listener
+// of the task it reaches.
+func convertToUpstreamStats(mf map[string]*prometheus.MetricFamily) *stats.ServiceConnectStats {
+	upstreams := make(map[string]*stats.ServiceConnectUpstreamStat)
+	histograms := make(map[string]upstreamHistogram)
+	for name, family := range mf {
+		for _, metric := range family.Metric {
+			upstream := upstreamFromLabels(metric.Label)
+			if upstream.Name == "" {
+				continue
+			}
+			key := upstream.Direction + "/" + upstream.Name
+			if existing, ok := upstreams[key]; ok {
+				upstream = existing
+			} else {
+				upstreams[key] = upstream
+			}
+
+			switch {
+			case name == requestCountMetric && metric.Counter != nil:
+				upstream.RequestCount += uint64(metric.Counter.GetValue())
+			case name == retryCountMetric && metric.Counter != nil:
+				upstream.RetryCount += uint64(metric.Counter.GetValue())
+			case name == circuitBreakerOpenMetric && metric.Gauge != nil:
+				upstream.CircuitBreakerOpen = upstream.CircuitBreakerOpen || metric.Gauge.GetValue() > 0
+			case name == targetResponseTimeMetric && metric.Histogram != nil:
+				histogram, ok := histograms[key]
+				if !ok {
+					histogram = make(upstreamHistogram)
+					histograms[key] = histogram
+				}
+				for _, bucket := range metric.Histogram.Bucket {
+					histogram[bucket.GetUpperBound()] += bucket.GetCumulativeCount()
+				}
+			}
+		}
+	}
+
+	serviceConnectStats := &stats.ServiceConnectStats{
+		Read:      time.Now(),
+		Upstreams: []stats.ServiceConnectUpstreamStat{},
+	}
+	for key, upstream := range upstreams {
+		if histogram, ok := histograms[key]; ok {
+			upstream.LatencyP50 = histogram.percentile(0.5)
+			upstream.LatencyP90 = histogram.percentile(0.9)
+			upstream.LatencyP99 = histogram.percentile(0.99)
+		}
+		serviceConnectStats.Upstreams = append(serviceConnectStats.Upstreams, *upstream)
+	}
+	sortUpstreams(serviceConnectStats.Upstreams)
+	return serviceConnectStats
+}
+
+func sortUpstreams(upstreams []stats.ServiceConnectUpstreamStat) {
+	sort.Slice(upstreams, func(i, j int) bool {
+		a, b := upstreams[i], upstreams[j]
+		if a.Direction != b.Direction {
+			return a.Direction < b.Direction
+		}
+		return a.Name < b.Name
+	})
+}
+
+func upstreamFromLabels(labels []*prometheus.LabelPair) *stats.ServiceConnectUpstreamStat {
+	upstream := &stats.ServiceConnectUpstreamStat{}
+	var discoveryName, targetDiscoveryName string
+	for _, label := range labels {
+		switch label.GetName() {
+		case directionLabel:
+			upstream.Direction = label.GetValue()
+		case discoveryNameLabel:
+			discoveryName = label.GetValue()
+		case targetDiscoveryNameLabel:
+			targetDiscoveryName = label.GetValue()
+		}
+	}
+	upstream.Name = discoveryName
+	if targetDiscoveryName != "" {
+		upstream.Name = targetDiscoveryName
+	}
+	return upstream
+}
+
+// percentile estimates the given percentile of the histogram, interpolating linearly within the
+// bucket it falls into. It returns nil when the histogram has no samples, and the largest finite
+// upper bound when the percentile falls into the +Inf bucket.
+func (h upstreamHistogram) percentile(p float64) *float64 {
+	upperBounds := make([]float64, 0, len(h))
+	for upperBound := range h {
+		upperBounds = append(upperBounds, upperBound)
+	}
+	sort.Float64s(upperBounds)
+	if len(upperBounds) == 0 {
+		return nil
+	}
+	total := h[upperBounds[len(upperBounds)-1]]
+	if total == 0 {
+		return nil
+	}
+
+	rank := p * float64(total)
+	lowerBound, lowerCount := 0.0, uint64(0)
+	for _, upperBound := range upperBounds {
+		count := h[upperBound]
+		if float64(count) >= rank {
+			if math.IsInf(upperBound, 1) {
+				return &lowerBound
+			}
+			value := upperBound
+			if count > lowerCount {
+				value = lowerBound + (upperBound-lowerBound)*(rank-float64(lowerCount))/float64(count-lowerCount)
+			}
+			return &value
+		}
+		lowerBound, lowerCount = upperBound, count
+	}
+	return &lowerBound
+}
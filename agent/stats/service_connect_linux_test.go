@@ -22,6 +22,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"sort"
+	"strings"
 	"testing"
 
 	"github.com/aws/amazon-ecs-agent/agent/api/serviceconnect"
@@ -31,11 +32,14 @@ import (
 	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
 	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
 	ni "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/stats"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/tcs/model/ecstcs"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/gorilla/mux"
+	"github.com/prometheus/common/expfmt"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRetrieveServiceConnectMetrics(t *testing.T) {
@@ -167,3 +171,66 @@ func sortMetrics(metricList []*ecstcs.GeneralMetricsWrapper) {
 		})
 	}
 }
+
+func TestConvertToUpstreamStats(t *testing.T) {
+	rawStats := `# TYPE RequestCount counter
+		RequestCount{DiscoveryName="web", Direction="ingress"} 20
+		RequestCount{DiscoveryName="web", TargetDiscoveryName="backend", Direction="egress"} 10
+		RequestCount{DiscoveryName="web", TargetDiscoveryName="backend", Direction="egress", TargetPort="8080"} 30
+		# TYPE RequestRetryCount counter
+		RequestRetryCount{DiscoveryName="web", TargetDiscoveryName="backend", Direction="egress"} 4
+		# TYPE CircuitBreakerOpen gauge
+		CircuitBreakerOpen{DiscoveryName="web", TargetDiscoveryName="backend", Direction="egress"} 1
+		# TYPE TargetResponseTime histogram
+		TargetResponseTime{DiscoveryName="web", TargetDiscoveryName="backend", Direction="egress", le="10"} 20
+		TargetResponseTime{DiscoveryName="web", TargetDiscoveryName="backend", Direction="egress", le="20"} 36
+		TargetResponseTime{DiscoveryName="web", TargetDiscoveryName="backend", Direction="egress", le="+Inf"} 40
+		# TYPE ActiveConnectionCount gauge
+		ActiveConnectionCount{Direction="ingress"} 3
+`
+	var parser expfmt.TextParser
+	mf, err := parser.TextToMetricFamilies(strings.NewReader(rawStats))
+	require.NoError(t, err)
+
+	upstreamStats := convertToUpstreamStats(mf)
+	require.Len(t, upstreamStats.Upstreams, 2)
+
+	egress := upstreamStats.Upstreams[0]
+	assert.Equal(t, "backend", egress.Name)
+	assert.Equal(t, "egress", egress.Direction)
+	assert.Equal(t, uint64(40), egress.RequestCount)
+	assert.Equal(t, uint64(4), egress.RetryCount)
+	assert.True(t, egress.CircuitBreakerOpen)
+	require.NotNil(t, egress.LatencyP50)
+	assert.Equal(t, 10.0, *egress.LatencyP50)
+	require.NotNil(t, egress.LatencyP90)
+	assert.Equal(t, 20.0, *egress.LatencyP90)
+	require.NotNil(t, egress.LatencyP99)
+	assert.Equal(t, 20.0, *egress.LatencyP99)
+
+	assert.Equal(t, stats.ServiceConnectUpstreamStat{
+		Name:         "web",
+		Direction:    "ingress",
+		RequestCount: 20,
+	}, upstreamStats.Upstreams[1])
+}
+
+func TestAccumulateUpstreamStats(t *testing.T) {
+	sc := &ServiceConnectStats{}
+	latency := 5.0
+	sc.accumulateUpstreamStats(&stats.ServiceConnectStats{Upstreams: []stats.ServiceConnectUpstreamStat{
+		{Name: "backend", Direction: "egress", RequestCount: 10, RetryCount: 1, LatencyP50: &latency,
+			CircuitBreakerOpen: true},
+		{Name: "web", Direction: "ingress", RequestCount: 20},
+	}})
+	// The AppNet agent only returns the upstreams whose counters changed since the previous retrieval.
+	// The circuit breaker of an upstream missing from the retrieval is no longer reported as open.
+	sc.accumulateUpstreamStats(&stats.ServiceConnectStats{Upstreams: []stats.ServiceConnectUpstreamStat{
+		{Name: "web", Direction: "ingress", RequestCount: 5},
+	}})
+
+	assert.Equal(t, []stats.ServiceConnectUpstreamStat{
+		{Name: "backend", Direction: "egress", RequestCount: 10, RetryCount: 1},
+		{Name: "web", Direction: "ingress", RequestCount: 25},
+	}, sc.GetUpstreamStats().Upstreams)
+}
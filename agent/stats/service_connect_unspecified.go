@@ -17,6 +17,7 @@ package stats
 
 import (
 	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/stats"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/tcs/model/ecstcs"
 	"github.com/pkg/errors"
 )
@@ -37,6 +38,10 @@ func (sc *ServiceConnectStats) GetStats() []*ecstcs.GeneralMetricsWrapper {
 	return nil
 }
 
+func (sc *ServiceConnectStats) GetUpstreamStats() *stats.ServiceConnectStats {
+	return nil
+}
+
 func (sc *ServiceConnectStats) SetStatsSent(sent bool) {
 	sc.sent = false
 }
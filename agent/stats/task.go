@@ -16,6 +16,7 @@ package stats
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
@@ -24,6 +25,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/config"
 	"github.com/aws/amazon-ecs-agent/agent/stats/resolver"
 	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/stats"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/retry"
 
 	"github.com/docker/docker/api/types"
@@ -39,6 +41,11 @@ type statsTaskCommon struct {
 	Cancel                context.CancelFunc
 	Resolver              resolver.ContainerMetadataResolver
 	metricPublishInterval time.Duration
+	// networkFlowBuckets are the remotes the task traffic is accounted to, nil when network
+	// flow accounting is disabled.
+	networkFlowBuckets   *networkFlowBuckets
+	networkFlowStats     *stats.NetworkFlowStats
+	networkFlowStatsLock sync.RWMutex
 }
 
 func (taskStat *StatsTask) StartStatsCollection() {
@@ -156,6 +163,7 @@ func (taskStat *StatsTask) getAWSVPCNetworkStats() (<-chan *types.StatsJSON, <-c
 					errC <- err
 					return
 				}
+				taskStat.updateNetworkFlowStats()
 
 				dockerStats := &types.StatsJSON{
 					Networks: networkStats,
@@ -174,3 +182,29 @@ func (taskStat *StatsTask) getAWSVPCNetworkStats() (<-chan *types.StatsJSON, <-c
 
 	return statsC, errC
 }
+
+// updateNetworkFlowStats refreshes the traffic of the task by remote when network flow
+// accounting is enabled. Errors are only logged, since they do not affect the interface stats.
+func (taskStat *StatsTask) updateNetworkFlowStats() {
+	if taskStat.networkFlowBuckets == nil {
+		return
+	}
+	flowStats, err := taskStat.retrieveNetworkFlowStatistics()
+	if err != nil {
+		logger.Warn("Error retrieving network flow stats for task", logger.Fields{
+			field.TaskID: taskStat.TaskMetadata.TaskId,
+			field.Error:  err,
+		})
+		return
+	}
+	taskStat.networkFlowStatsLock.Lock()
+	defer taskStat.networkFlowStatsLock.Unlock()
+	taskStat.networkFlowStats = flowStats
+}
+
+// getNetworkFlowStats returns the last traffic of the task by remote, or nil if there is none.
+func (taskStat *StatsTask) getNetworkFlowStats() *stats.NetworkFlowStats {
+	taskStat.networkFlowStatsLock.RLock()
+	defer taskStat.networkFlowStatsLock.RUnlock()
+	return taskStat.networkFlowStats
+}
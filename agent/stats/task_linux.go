@@ -26,6 +26,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/stats/resolver"
 	"github.com/aws/amazon-ecs-agent/agent/utils/netlinkwrapper"
 	"github.com/aws/amazon-ecs-agent/agent/utils/nswrapper"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/execwrapper"
 
 	"github.com/containernetworking/plugins/pkg/ns"
 	dockerstats "github.com/docker/docker/api/types"
@@ -47,10 +48,15 @@ type StatsTask struct {
 	*statsTaskCommon
 	nswrapperinterface nswrapper.NS
 	netlinkinterface   netlinkwrapper.NetLink
+	execwrapper        execwrapper.Exec
+	// networkFlowCommands are the iptables commands of the IP families whose network flow
+	// counting chains are set up in the task network namespace.
+	networkFlowCommands []string
 }
 
 func newStatsTaskContainer(taskARN, taskId, containerPID string, numberOfContainers int,
-	resolver resolver.ContainerMetadataResolver, publishInterval time.Duration, _ task.TaskENIs) (*StatsTask, error) {
+	resolver resolver.ContainerMetadataResolver, publishInterval time.Duration, _ task.TaskENIs,
+	flowBuckets *networkFlowBuckets) (*StatsTask, error) {
 	nsAgent := nswrapper.NewNS()
 	netlinkclient := netlinkwrapper.New()
 
@@ -67,9 +73,11 @@ func newStatsTaskContainer(taskARN, taskId, containerPID string, numberOfContain
 			Cancel:                cancel,
 			Resolver:              resolver,
 			metricPublishInterval: publishInterval,
+			networkFlowBuckets:    flowBuckets,
 		},
 		netlinkinterface:   netlinkclient,
 		nswrapperinterface: nsAgent,
+		execwrapper:        execwrapper.NewExec(),
 	}, nil
 }
 
@@ -21,6 +21,7 @@ import (
 
 	"github.com/aws/amazon-ecs-agent/agent/api/task"
 	"github.com/aws/amazon-ecs-agent/agent/stats/resolver"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/stats"
 
 	dockerstats "github.com/docker/docker/api/types"
 	"github.com/pkg/errors"
@@ -33,10 +34,15 @@ type StatsTask struct {
 }
 
 func newStatsTaskContainer(taskARN, taskId, containerPID string, numberOfContainers int,
-	resolver resolver.ContainerMetadataResolver, publishInterval time.Duration, _ task.TaskENIs) (*StatsTask, error) {
+	resolver resolver.ContainerMetadataResolver, publishInterval time.Duration, _ task.TaskENIs,
+	_ *networkFlowBuckets) (*StatsTask, error) {
 	return nil, errors.New("Unsupported platform")
 }
 
 func (taskStat *StatsTask) retrieveNetworkStatistics() (map[string]dockerstats.NetworkStats, error) {
 	return nil, errors.New("Unsupported platform")
 }
+
+func (taskStat *StatsTask) retrieveNetworkFlowStatistics() (*stats.NetworkFlowStats, error) {
+	return nil, errors.New("Unsupported platform")
+}
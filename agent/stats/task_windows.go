@@ -23,6 +23,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/api/task"
 	"github.com/aws/amazon-ecs-agent/agent/eni/networkutils"
 	"github.com/aws/amazon-ecs-agent/agent/stats/resolver"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/stats"
 
 	dockerstats "github.com/docker/docker/api/types"
 	"github.com/pkg/errors"
@@ -35,7 +36,8 @@ type StatsTask struct {
 }
 
 func newStatsTaskContainer(taskARN, taskId, containerPID string, numberOfContainers int,
-	resolver resolver.ContainerMetadataResolver, publishInterval time.Duration, taskENIs task.TaskENIs) (*StatsTask, error) {
+	resolver resolver.ContainerMetadataResolver, publishInterval time.Duration, taskENIs task.TaskENIs,
+	_ *networkFlowBuckets) (*StatsTask, error) {
 
 	// Instantiate an instance of network utils.
 	// This interface would be used to invoke Windows networking APIs.
@@ -73,6 +75,12 @@ func newStatsTaskContainer(taskARN, taskId, containerPID string, numberOfContain
 	}, nil
 }
 
+// retrieveNetworkFlowStatistics is not supported on Windows, so network flow accounting is
+// never enabled for Windows tasks.
+func (taskStat *StatsTask) retrieveNetworkFlowStatistics() (*stats.NetworkFlowStats, error) {
+	return nil, errors.New("network flow stats are not supported on windows")
+}
+
 // retrieveNetworkStatistics retrieves the network statistics for the task devices by querying
 // the Windows networking APIs.
 func (taskStat *StatsTask) retrieveNetworkStatistics() (map[string]dockerstats.NetworkStats, error) {
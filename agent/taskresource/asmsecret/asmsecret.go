@@ -27,6 +27,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/asm"
 	"github.com/aws/amazon-ecs-agent/agent/asm/factory"
 	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/secretcache"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
 	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
 	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
@@ -70,6 +71,8 @@ type ASMSecretResource struct {
 	// ssmClientCreator is a factory interface that creates new SSM clients. This is
 	// needed mostly for testing.
 	asmClientCreator factory.ClientCreator
+	// secretCache is the host level cache that secret values are read from, if set
+	secretCache *secretcache.Cache
 
 	// terminalReason should be set for resource creation failures. This ensures
 	// the resource object carries some context for why provisioning failed.
@@ -85,7 +88,8 @@ func NewASMSecretResource(taskARN string,
 	asmSecrets map[string]apicontainer.Secret,
 	executionCredentialsID string,
 	credentialsManager credentials.Manager,
-	asmClientCreator factory.ClientCreator) *ASMSecretResource {
+	asmClientCreator factory.ClientCreator,
+	secretCache *secretcache.Cache) *ASMSecretResource {
 
 	s := &ASMSecretResource{
 		taskARN:                taskARN,
@@ -93,6 +97,7 @@ func NewASMSecretResource(taskARN string,
 		credentialsManager:     credentialsManager,
 		executionCredentialsID: executionCredentialsID,
 		asmClientCreator:       asmClientCreator,
+		secretCache:            secretCache,
 	}
 
 	s.initStatusToTransition()
@@ -301,10 +306,14 @@ func (secret *ASMSecretResource) Create() error {
 func (secret *ASMSecretResource) retrieveASMSecretValue(apiSecret apicontainer.Secret, iamCredentials credentials.IAMRoleCredentials, wg *sync.WaitGroup, errorEvents chan error) {
 	defer wg.Done()
 
-	asmClient, err := secret.asmClientCreator.NewASMClient(apiSecret.Region, iamCredentials)
-	if err != nil {
-		errorEvents <- fmt.Errorf("unable to create ASM client: %v", err)
-		return
+	var asmClient asm.SecretsManagerAPI
+	if secret.secretCache == nil {
+		var err error
+		asmClient, err = secret.asmClientCreator.NewASMClient(apiSecret.Region, iamCredentials)
+		if err != nil {
+			errorEvents <- fmt.Errorf("unable to create ASM client: %v", err)
+			return
+		}
 	}
 	seelog.Debugf("ASM secret resource: retrieving resource for secret %v in region %s for task: [%s]", apiSecret.ValueFrom, apiSecret.Region, secret.taskARN)
 	input, jsonKey, err := getASMParametersFromInput(apiSecret.ValueFrom)
@@ -319,7 +328,17 @@ func (secret *ASMSecretResource) retrieveASMSecretValue(apiSecret apicontainer.S
 
 	}
 
-	secretValue, err := asm.GetSecretFromASMWithInput(input, asmClient, jsonKey)
+	var secretValue string
+	if secret.secretCache != nil {
+		var secretString string
+		secretString, err = secret.secretCache.GetASMSecret(apiSecret.Region, iamCredentials,
+			aws.ToString(input.SecretId), aws.ToString(input.VersionStage), aws.ToString(input.VersionId))
+		if err == nil {
+			secretValue, err = asm.GetSecretValueFromJSONKey(aws.ToString(input.SecretId), secretString, jsonKey)
+		}
+	} else {
+		secretValue, err = asm.GetSecretFromASMWithInput(input, asmClient, jsonKey)
+	}
 	if err != nil {
 		errorEvents <- err
 		return
@@ -454,6 +473,7 @@ func (secret *ASMSecretResource) Initialize(
 	secret.initStatusToTransition()
 	secret.credentialsManager = resourceFields.CredentialsManager
 	secret.asmClientCreator = resourceFields.ASMClientCreator
+	secret.secretCache = resourceFields.SecretCache
 
 	// if task hasn't turn to 'created' status, and it's desire status is 'running'
 	// the resource status needs to be reset to 'NONE' status so the secret value
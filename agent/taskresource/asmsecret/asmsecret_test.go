@@ -28,11 +28,13 @@ import (
 	mock_factory "github.com/aws/amazon-ecs-agent/agent/asm/factory/mocks"
 	mock_secretsmanageriface "github.com/aws/amazon-ecs-agent/agent/asm/mocks"
 	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/secretcache"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
 	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
 	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
 	mock_credentials "github.com/aws/amazon-ecs-agent/ecs-agent/credentials/mocks"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/ipcompatibility"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
@@ -350,3 +352,43 @@ func sampleSecret(secretName string, valueFrom string, region string) apicontain
 		Provider:  "asm",
 	}
 }
+
+func TestCreateWithSecretCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	credentialsManager := mock_credentials.NewMockManager(ctrl)
+	asmClientCreator := mock_factory.NewMockClientCreator(ctrl)
+	mockASMClient := mock_secretsmanageriface.NewMockSecretsManagerAPI(ctrl)
+
+	iamRoleCreds := credentials.IAMRoleCredentials{RoleArn: "arn:aws:iam::123456789012:role/execution"}
+	creds := credentials.TaskIAMRoleCredentials{IAMRoleCredentials: iamRoleCreds}
+	secretCache, err := secretcache.NewCache(time.Minute, nil, asmClientCreator, ipcompatibility.NewIPv4OnlyCompatibility())
+	require.NoError(t, err)
+
+	credentialsManager.EXPECT().GetTaskCredentials(executionCredentialsID).Return(creds, true).Times(2)
+	asmClientCreator.EXPECT().NewASMClient(region1, iamRoleCreds).Return(mockASMClient, nil)
+	mockASMClient.EXPECT().BatchGetSecretValue(gomock.Any(), &secretsmanager.BatchGetSecretValueInput{
+		SecretIdList: []string{valueFrom1},
+	}).Return(&secretsmanager.BatchGetSecretValueOutput{
+		SecretValues: []types.SecretValueEntry{
+			{ARN: aws.String(valueFrom1), SecretString: aws.String(secretValueJson)},
+		},
+	}, nil).Times(1)
+
+	// Both secrets are read from the single cached secret string
+	for _, valueFrom := range []string{valueFrom1, valueFrom1 + ":json-key::"} {
+		secretKey := valueFrom + secretCacheJoinChar + regionKeyWest
+		asmRes := NewASMSecretResource(taskARN, map[string]apicontainer.Secret{
+			secretKey: sampleSecret(secretName1, valueFrom, region1),
+		}, executionCredentialsID, credentialsManager, asmClientCreator, secretCache)
+		require.NoError(t, asmRes.Create())
+		value, ok := asmRes.GetCachedSecretValue(secretKey)
+		require.True(t, ok)
+		if valueFrom == valueFrom1 {
+			assert.Equal(t, secretValueJson, value)
+		} else {
+			assert.Equal(t, secretValue, value)
+		}
+	}
+}
@@ -0,0 +1,555 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package csivolume
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource"
+	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/csiclient"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	resourceProvisioningError = "VolumeError: Agent could not create task's volume resources"
+	csiVolumeType             = "csi"
+	// csiDir is the directory under the agent data directory that holds the staging and target
+	// paths of the CSI volumes of the tasks.
+	csiDir        = "csi"
+	stagingSubDir = "staging"
+	targetSubDir  = "mount"
+
+	defaultNodeStageTimeout   = 2 * time.Minute
+	defaultNodeUnstageTimeout = 30 * time.Second
+)
+
+// newCSIClient creates the client of the CSI node plugin listening on the given socket.
+var newCSIClient = func(socketPath string) csiclient.CSIClient {
+	client := csiclient.NewCSIClient(socketPath)
+	return &client
+}
+
+// CSIVolumeResource represents a task volume backed by a CSI node plugin running on the host
+type CSIVolumeResource struct {
+	Name         string
+	VolumeConfig CSIVolumeConfig
+	taskARN      string
+	// socketPath is the path of the socket of the node plugin of the volume driver.
+	socketPath string
+	// localDir and hostDir are the directory that holds the staging and target paths of the
+	// volume, as seen by the agent and on the host respectively. They differ when the agent runs
+	// in a container.
+	localDir string
+	hostDir  string
+	// staged is set when the volume was staged by the node plugin, which then expects it to be
+	// unstaged on cleanup.
+	staged bool
+
+	nodeStageTimeout   time.Duration
+	nodeUnstageTimeout time.Duration
+
+	// Fields for the common functionality of task resource. Access to these fields are protected by lock.
+	createdAtUnsafe     time.Time
+	knownStatusUnsafe   resourcestatus.ResourceStatus
+	desiredStatusUnsafe resourcestatus.ResourceStatus
+	appliedStatusUnsafe resourcestatus.ResourceStatus
+	statusToTransitions map[resourcestatus.ResourceStatus]func() error
+	terminalReason      string
+	terminalReasonOnce  sync.Once
+	lock                sync.RWMutex
+}
+
+// CSIVolumeConfig represents the configuration of a csi task volume.
+type CSIVolumeConfig struct {
+	// Driver is the name of the CSI driver, which is mapped to the socket of its node plugin by
+	// the agent configuration.
+	Driver       string   `json:"driver"`
+	VolumeID     string   `json:"volumeId"`
+	FSType       string   `json:"fsType,omitempty"`
+	ReadOnly     bool     `json:"readOnly,omitempty"`
+	MountOptions []string `json:"mountOptions,omitempty"`
+	// VolumeContext and PublishContext are passed through to the node plugin as is.
+	VolumeContext  map[string]string `json:"volumeContext,omitempty"`
+	PublishContext map[string]string `json:"publishContext,omitempty"`
+	// HostPath is used for bind mount as part of HostConfig.
+	HostPath string `json:"csiVolumeHostPath"`
+}
+
+// NewCSIVolumeResource creates a new CSIVolumeResource object. The volume is published under the
+// given data directory, which is hostDataDir on the host.
+func NewCSIVolumeResource(
+	taskARN string,
+	taskID string,
+	name string,
+	volumeConfig *CSIVolumeConfig,
+	socketPath string,
+	dataDir string,
+	hostDataDir string) (*CSIVolumeResource, error) {
+	if volumeConfig.Driver == "" {
+		return nil, errors.Errorf("csi volume %s: missing driver", name)
+	}
+	if volumeConfig.VolumeID == "" {
+		return nil, errors.Errorf("csi volume %s: missing volume id", name)
+	}
+	if hostDataDir == "" {
+		hostDataDir = dataDir
+	}
+
+	cv := &CSIVolumeResource{
+		Name:         name,
+		VolumeConfig: *volumeConfig,
+		taskARN:      taskARN,
+		socketPath:   socketPath,
+		localDir:     filepath.Join(dataDir, csiDir, taskID, name),
+		hostDir:      filepath.Join(hostDataDir, csiDir, taskID, name),
+	}
+	cv.VolumeConfig.HostPath = filepath.Join(cv.hostDir, targetSubDir)
+	cv.initStatusToTransition()
+	return cv, nil
+}
+
+func (cv *CSIVolumeResource) Initialize(
+	config *config.Config,
+	resourceFields *taskresource.ResourceFields,
+	taskKnownStatus status.TaskStatus,
+	taskDesiredStatus status.TaskStatus) {
+
+	cv.nodeStageTimeout = config.NodeStageTimeout
+	cv.nodeUnstageTimeout = config.NodeUnstageTimeout
+	cv.initStatusToTransition()
+}
+
+func (cv *CSIVolumeResource) initStatusToTransition() {
+	statusToTransitions := map[resourcestatus.ResourceStatus]func() error{
+		resourcestatus.ResourceStatus(CSIVolumeCreated): cv.Create,
+	}
+
+	cv.statusToTransitions = statusToTransitions
+}
+
+// DesiredTerminal returns true if the csivolume's desired status is REMOVED
+func (cv *CSIVolumeResource) DesiredTerminal() bool {
+	cv.lock.RLock()
+	defer cv.lock.RUnlock()
+
+	return cv.desiredStatusUnsafe == resourcestatus.ResourceStatus(CSIVolumeRemoved)
+}
+
+// GetTerminalReason returns an error string to propagate up through to task
+// state change messages
+func (cv *CSIVolumeResource) GetTerminalReason() string {
+	if cv.terminalReason == "" {
+		return resourceProvisioningError
+	}
+	return cv.terminalReason
+}
+
+func (cv *CSIVolumeResource) setTerminalReason(reason string) {
+	cv.terminalReasonOnce.Do(func() {
+		logger.Debug("Setting terminal reason for csivolume resource", logger.Fields{
+			field.TaskARN: cv.taskARN,
+			field.Volume:  cv.Name,
+			field.Reason:  reason,
+		})
+		cv.terminalReason = reason
+	})
+}
+
+// GetDesiredStatus safely returns the desired status of the task
+func (cv *CSIVolumeResource) GetDesiredStatus() resourcestatus.ResourceStatus {
+	cv.lock.RLock()
+	defer cv.lock.RUnlock()
+
+	return cv.desiredStatusUnsafe
+}
+
+// SetDesiredStatus safely sets the desired status of the resource
+func (cv *CSIVolumeResource) SetDesiredStatus(status resourcestatus.ResourceStatus) {
+	cv.lock.Lock()
+	defer cv.lock.Unlock()
+
+	cv.desiredStatusUnsafe = status
+}
+
+// GetKnownStatus safely returns the currently known status of the task
+func (cv *CSIVolumeResource) GetKnownStatus() resourcestatus.ResourceStatus {
+	cv.lock.RLock()
+	defer cv.lock.RUnlock()
+
+	return cv.knownStatusUnsafe
+}
+
+// SetKnownStatus safely sets the currently known status of the resource
+func (cv *CSIVolumeResource) SetKnownStatus(status resourcestatus.ResourceStatus) {
+	cv.lock.Lock()
+	defer cv.lock.Unlock()
+
+	cv.knownStatusUnsafe = status
+	cv.updateAppliedStatusUnsafe(status)
+}
+
+// KnownCreated returns true if the csivolume's known status is CREATED
+func (cv *CSIVolumeResource) KnownCreated() bool {
+	cv.lock.RLock()
+	defer cv.lock.RUnlock()
+
+	return cv.knownStatusUnsafe == resourcestatus.ResourceStatus(CSIVolumeCreated)
+}
+
+// TerminalStatus returns the last transition state of csivolume
+func (cv *CSIVolumeResource) TerminalStatus() resourcestatus.ResourceStatus {
+	return resourcestatus.ResourceStatus(CSIVolumeRemoved)
+}
+
+// NextKnownState returns the state that the resource should
+// progress to based on its `KnownState`.
+func (cv *CSIVolumeResource) NextKnownState() resourcestatus.ResourceStatus {
+	return cv.GetKnownStatus() + 1
+}
+
+// SteadyState returns the transition state of the resource defined as "ready"
+func (cv *CSIVolumeResource) SteadyState() resourcestatus.ResourceStatus {
+	return resourcestatus.ResourceStatus(CSIVolumeCreated)
+}
+
+// ApplyTransition calls the function required to move to the specified status
+func (cv *CSIVolumeResource) ApplyTransition(nextState resourcestatus.ResourceStatus) error {
+	transitionFunc, ok := cv.statusToTransitions[nextState]
+	if !ok {
+		err := errors.Errorf("resource [%s]: transition to %s impossible", cv.Name,
+			cv.StatusString(nextState))
+		cv.setTerminalReason(err.Error())
+		return err
+	}
+
+	return transitionFunc()
+}
+
+// SetAppliedStatus sets the applied status of resource and returns whether
+// the resource is already in a transition
+func (cv *CSIVolumeResource) SetAppliedStatus(status resourcestatus.ResourceStatus) bool {
+	cv.lock.Lock()
+	defer cv.lock.Unlock()
+
+	if cv.appliedStatusUnsafe != resourcestatus.ResourceStatus(CSIVolumeStatusNone) {
+		// return false to indicate the set operation failed
+		return false
+	}
+
+	cv.appliedStatusUnsafe = status
+	return true
+}
+
+// StatusString returns the string of the csivolume resource status
+func (cv *CSIVolumeResource) StatusString(status resourcestatus.ResourceStatus) string {
+	return CSIVolumeStatus(status).String()
+}
+
+// GetCreatedAt gets the timestamp for resource's creation time
+func (cv *CSIVolumeResource) GetCreatedAt() time.Time {
+	cv.lock.RLock()
+	defer cv.lock.RUnlock()
+
+	return cv.createdAtUnsafe
+}
+
+// SetCreatedAt sets the timestamp for resource's creation time
+func (cv *CSIVolumeResource) SetCreatedAt(createdAt time.Time) {
+	if createdAt.IsZero() {
+		return
+	}
+	cv.lock.Lock()
+	defer cv.lock.Unlock()
+
+	cv.createdAtUnsafe = createdAt
+}
+
+// Source returns the host path of the csivolume resource which is used as the source of the volume mount
+func (cfg *CSIVolumeConfig) Source() string {
+	return cfg.HostPath
+}
+
+func (cfg *CSIVolumeConfig) GetType() string {
+	return csiVolumeType
+}
+
+func (cfg *CSIVolumeConfig) GetVolumeId() string {
+	return cfg.VolumeID
+}
+
+// Currently not meant for use
+func (cfg *CSIVolumeConfig) GetVolumeName() string {
+	return ""
+}
+
+// GetName safely returns the name of the csivolume resource
+func (cv *CSIVolumeResource) GetName() string {
+	cv.lock.RLock()
+	defer cv.lock.RUnlock()
+
+	return cv.Name
+}
+
+// GetVolumeConfig safely returns the volume config of the csivolume resource
+func (cv *CSIVolumeResource) GetVolumeConfig() CSIVolumeConfig {
+	cv.lock.RLock()
+	defer cv.lock.RUnlock()
+
+	return cv.VolumeConfig
+}
+
+func (cv *CSIVolumeResource) isStaged() bool {
+	cv.lock.RLock()
+	defer cv.lock.RUnlock()
+
+	return cv.staged
+}
+
+func (cv *CSIVolumeResource) setStaged(staged bool) {
+	cv.lock.Lock()
+	defer cv.lock.Unlock()
+
+	cv.staged = staged
+}
+
+// Create stages the volume, if the node plugin supports it, and publishes it to the host path
+// of the volume.
+func (cv *CSIVolumeResource) Create() error {
+	volumeConfig := cv.GetVolumeConfig()
+	client := newCSIClient(cv.socketPath)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cv.getNodeStageTimeout())
+	defer cancel()
+
+	stageUnstage, err := supportsStageUnstage(ctx, client)
+	if err != nil {
+		err = errors.Wrapf(err, "csi volume %s: unable to get the capabilities of driver %s",
+			cv.Name, volumeConfig.Driver)
+		cv.setTerminalReason(err.Error())
+		return err
+	}
+
+	var stagingPath string
+	if stageUnstage {
+		if err := os.MkdirAll(filepath.Join(cv.localDir, stagingSubDir), 0750); err != nil {
+			err = errors.Wrapf(err, "csi volume %s: unable to create the staging path", cv.Name)
+			cv.setTerminalReason(err.Error())
+			return err
+		}
+		stagingPath = filepath.Join(cv.hostDir, stagingSubDir)
+		accessMode := v1.ReadWriteOnce
+		if volumeConfig.ReadOnly {
+			accessMode = v1.ReadOnlyMany
+		}
+		if err := client.NodeStageVolume(ctx, volumeConfig.VolumeID, volumeConfig.PublishContext, stagingPath,
+			volumeConfig.FSType, accessMode, nil, volumeConfig.VolumeContext, volumeConfig.MountOptions,
+			nil); err != nil {
+			err = errors.Wrapf(err, "csi volume %s", cv.Name)
+			cv.setTerminalReason(err.Error())
+			return err
+		}
+		cv.setStaged(true)
+	}
+
+	// The node plugin creates the target path, but its parent must exist.
+	if err := os.MkdirAll(cv.localDir, 0750); err != nil {
+		err = errors.Wrapf(err, "csi volume %s: unable to create the target path parent", cv.Name)
+		cv.setTerminalReason(err.Error())
+		return err
+	}
+	if err := client.NodePublishVolume(ctx, volumeConfig.VolumeID, volumeConfig.PublishContext, stagingPath,
+		volumeConfig.HostPath, volumeConfig.FSType, volumeConfig.ReadOnly, nil, volumeConfig.VolumeContext,
+		volumeConfig.MountOptions); err != nil {
+		err = errors.Wrapf(err, "csi volume %s", cv.Name)
+		cv.setTerminalReason(err.Error())
+		return err
+	}
+	return nil
+}
+
+// supportsStageUnstage returns whether the node plugin expects volumes to be staged before they
+// are published.
+func supportsStageUnstage(ctx context.Context, client csiclient.CSIClient) (bool, error) {
+	resp, err := client.NodeGetCapabilities(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, capability := range resp.GetCapabilities() {
+		if capability.GetRpc().GetType() == csi.NodeServiceCapability_RPC_STAGE_UNSTAGE_VOLUME {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Cleanup unpublishes the volume and unstages it if it was staged.
+func (cv *CSIVolumeResource) Cleanup() error {
+	volumeConfig := cv.GetVolumeConfig()
+	client := newCSIClient(cv.socketPath)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cv.getNodeUnstageTimeout())
+	defer cancel()
+
+	if err := client.NodeUnpublishVolume(ctx, volumeConfig.VolumeID, volumeConfig.HostPath); err != nil {
+		return errors.Wrapf(err, "csi volume %s", cv.Name)
+	}
+	if cv.isStaged() {
+		if err := client.NodeUnstageVolume(ctx, volumeConfig.VolumeID,
+			filepath.Join(cv.hostDir, stagingSubDir)); err != nil {
+			return errors.Wrapf(err, "csi volume %s", cv.Name)
+		}
+		cv.setStaged(false)
+	}
+	if err := os.RemoveAll(cv.localDir); err != nil {
+		logger.Warn("Unable to remove the csi volume directory", logger.Fields{
+			field.TaskARN: cv.taskARN,
+			field.Volume:  cv.Name,
+			field.Error:   err,
+		})
+	}
+	return nil
+}
+
+func (cv *CSIVolumeResource) getNodeStageTimeout() time.Duration {
+	if cv.nodeStageTimeout == 0 {
+		return defaultNodeStageTimeout
+	}
+	return cv.nodeStageTimeout
+}
+
+func (cv *CSIVolumeResource) getNodeUnstageTimeout() time.Duration {
+	if cv.nodeUnstageTimeout == 0 {
+		return defaultNodeUnstageTimeout
+	}
+	return cv.nodeUnstageTimeout
+}
+
+// CSIVolumeResourceJSON is the json representation of the csivolume resource
+type CSIVolumeResourceJSON struct {
+	Name          string           `json:"name"`
+	VolumeConfig  CSIVolumeConfig  `json:"csiVolumeConfiguration"`
+	TaskARN       string           `json:"taskARN"`
+	SocketPath    string           `json:"socketPath"`
+	LocalDir      string           `json:"localDir"`
+	HostDir       string           `json:"hostDir"`
+	Staged        bool             `json:"staged"`
+	CreatedAt     *time.Time       `json:"createdAt,omitempty"`
+	DesiredStatus *CSIVolumeStatus `json:"desiredStatus"`
+	KnownStatus   *CSIVolumeStatus `json:"knownStatus"`
+}
+
+// MarshalJSON serialises the CSIVolumeResourceJSON struct to JSON
+func (cv *CSIVolumeResource) MarshalJSON() ([]byte, error) {
+	if cv == nil {
+		return nil, errors.New("csivolume resource is nil")
+	}
+	createdAt := cv.GetCreatedAt()
+	return json.Marshal(CSIVolumeResourceJSON{
+		Name:         cv.Name,
+		VolumeConfig: cv.GetVolumeConfig(),
+		TaskARN:      cv.taskARN,
+		SocketPath:   cv.socketPath,
+		LocalDir:     cv.localDir,
+		HostDir:      cv.hostDir,
+		Staged:       cv.isStaged(),
+		CreatedAt:    &createdAt,
+		DesiredStatus: func() *CSIVolumeStatus {
+			desiredState := cv.GetDesiredStatus()
+			s := CSIVolumeStatus(desiredState)
+			return &s
+		}(),
+		KnownStatus: func() *CSIVolumeStatus {
+			knownState := cv.GetKnownStatus()
+			s := CSIVolumeStatus(knownState)
+			return &s
+		}(),
+	})
+}
+
+// UnmarshalJSON deserialises the raw JSON to a CSIVolumeResourceJSON struct
+func (cv *CSIVolumeResource) UnmarshalJSON(b []byte) error {
+	temp := CSIVolumeResourceJSON{}
+
+	if err := json.Unmarshal(b, &temp); err != nil {
+		return err
+	}
+
+	cv.Name = temp.Name
+	cv.VolumeConfig = temp.VolumeConfig
+	cv.taskARN = temp.TaskARN
+	cv.socketPath = temp.SocketPath
+	cv.localDir = temp.LocalDir
+	cv.hostDir = temp.HostDir
+	cv.staged = temp.Staged
+	if temp.DesiredStatus != nil {
+		cv.SetDesiredStatus(resourcestatus.ResourceStatus(*temp.DesiredStatus))
+	}
+	if temp.KnownStatus != nil {
+		cv.SetKnownStatus(resourcestatus.ResourceStatus(*temp.KnownStatus))
+	}
+	if temp.CreatedAt != nil && !temp.CreatedAt.IsZero() {
+		cv.SetCreatedAt(*temp.CreatedAt)
+	}
+	return nil
+}
+
+// updateAppliedStatusUnsafe updates the resource transitioning status
+func (cv *CSIVolumeResource) updateAppliedStatusUnsafe(knownStatus resourcestatus.ResourceStatus) {
+	if cv.appliedStatusUnsafe == resourcestatus.ResourceStatus(CSIVolumeStatusNone) {
+		return
+	}
+
+	// Check if the resource transition has already finished
+	if cv.appliedStatusUnsafe <= knownStatus {
+		cv.appliedStatusUnsafe = resourcestatus.ResourceStatus(CSIVolumeStatusNone)
+	}
+}
+
+// GetAppliedStatus safely returns the currently applied status of the resource
+func (cv *CSIVolumeResource) GetAppliedStatus() resourcestatus.ResourceStatus {
+	cv.lock.RLock()
+	defer cv.lock.RUnlock()
+
+	return cv.appliedStatusUnsafe
+}
+
+func (cv *CSIVolumeResource) DependOnTaskNetwork() bool {
+	return false
+}
+
+// BuildContainerDependency sets the container dependencies of the resource.
+func (cv *CSIVolumeResource) BuildContainerDependency(containerName string, satisfied apicontainerstatus.ContainerStatus,
+	dependent resourcestatus.ResourceStatus) {
+	return
+}
+
+// GetContainerDependencies returns the container dependencies of the resource.
+func (cv *CSIVolumeResource) GetContainerDependencies(dependent resourcestatus.ResourceStatus) []apicontainer.ContainerDependency {
+	return nil
+}
@@ -0,0 +1,189 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package csivolume
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/csiclient"
+	mock_csiclient "github.com/aws/amazon-ecs-agent/ecs-agent/csiclient/mocks"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	taskARN    = "arn:aws:ecs:us-west-2:123456789012:task/test-cluster/abc"
+	taskID     = "abc"
+	volumeName = "data"
+	driver     = "csi.vendor.example.com"
+	volumeID   = "vol-12345"
+	socketPath = "/var/run/csi/vendor/csi.sock"
+)
+
+func newTestCSIVolumeResource(t *testing.T, client csiclient.CSIClient) *CSIVolumeResource {
+	dataDir := t.TempDir()
+	cv, err := NewCSIVolumeResource(taskARN, taskID, volumeName, &CSIVolumeConfig{
+		Driver:        driver,
+		VolumeID:      volumeID,
+		FSType:        "ext4",
+		MountOptions:  []string{"noatime"},
+		VolumeContext: map[string]string{"tier": "fast"},
+	}, socketPath, dataDir, "")
+	require.NoError(t, err)
+
+	newCSIClient = func(socket string) csiclient.CSIClient {
+		assert.Equal(t, socketPath, socket)
+		return client
+	}
+	t.Cleanup(func() {
+		newCSIClient = func(socketPath string) csiclient.CSIClient {
+			client := csiclient.NewCSIClient(socketPath)
+			return &client
+		}
+	})
+	return cv
+}
+
+func stageUnstageCapabilities() *csi.NodeGetCapabilitiesResponse {
+	return &csi.NodeGetCapabilitiesResponse{
+		Capabilities: []*csi.NodeServiceCapability{
+			{
+				Type: &csi.NodeServiceCapability_Rpc{
+					Rpc: &csi.NodeServiceCapability_RPC{
+						Type: csi.NodeServiceCapability_RPC_STAGE_UNSTAGE_VOLUME,
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestNewCSIVolumeResource(t *testing.T) {
+	cv, err := NewCSIVolumeResource(taskARN, taskID, volumeName, &CSIVolumeConfig{Driver: driver, VolumeID: volumeID},
+		socketPath, "/data", "/var/lib/ecs/data")
+	require.NoError(t, err)
+	assert.Equal(t, "/data/csi/abc/data", cv.localDir)
+	assert.Equal(t, "/var/lib/ecs/data/csi/abc/data/mount", cv.VolumeConfig.Source())
+	assert.Equal(t, volumeID, cv.VolumeConfig.GetVolumeId())
+	assert.Equal(t, "csi", cv.VolumeConfig.GetType())
+
+	_, err = NewCSIVolumeResource(taskARN, taskID, volumeName, &CSIVolumeConfig{VolumeID: volumeID},
+		socketPath, "/data", "")
+	assert.Error(t, err)
+	_, err = NewCSIVolumeResource(taskARN, taskID, volumeName, &CSIVolumeConfig{Driver: driver},
+		socketPath, "/data", "")
+	assert.Error(t, err)
+}
+
+func TestCreateAndCleanupWithStaging(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_csiclient.NewMockCSIClient(ctrl)
+	cv := newTestCSIVolumeResource(t, client)
+	cv.Initialize(&config.Config{}, nil, 0, 0)
+	stagingPath := filepath.Join(cv.hostDir, stagingSubDir)
+	targetPath := cv.VolumeConfig.Source()
+
+	gomock.InOrder(
+		client.EXPECT().NodeGetCapabilities(gomock.Any()).Return(stageUnstageCapabilities(), nil),
+		client.EXPECT().NodeStageVolume(gomock.Any(), volumeID, nil, stagingPath, "ext4", v1.ReadWriteOnce,
+			nil, map[string]string{"tier": "fast"}, []string{"noatime"}, nil).Return(nil),
+		client.EXPECT().NodePublishVolume(gomock.Any(), volumeID, nil, stagingPath, targetPath, "ext4", false,
+			nil, map[string]string{"tier": "fast"}, []string{"noatime"}).Return(nil),
+		client.EXPECT().NodeUnpublishVolume(gomock.Any(), volumeID, targetPath).Return(nil),
+		client.EXPECT().NodeUnstageVolume(gomock.Any(), volumeID, stagingPath).Return(nil),
+	)
+
+	require.NoError(t, cv.ApplyTransition(resourcestatus.ResourceStatus(CSIVolumeCreated)))
+	assert.True(t, cv.isStaged())
+	assert.DirExists(t, filepath.Join(cv.localDir, stagingSubDir))
+
+	require.NoError(t, cv.Cleanup())
+	assert.False(t, cv.isStaged())
+	_, err := os.Stat(cv.localDir)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestCreateWithoutStaging(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_csiclient.NewMockCSIClient(ctrl)
+	cv := newTestCSIVolumeResource(t, client)
+	targetPath := cv.VolumeConfig.Source()
+
+	gomock.InOrder(
+		client.EXPECT().NodeGetCapabilities(gomock.Any()).Return(&csi.NodeGetCapabilitiesResponse{}, nil),
+		client.EXPECT().NodePublishVolume(gomock.Any(), volumeID, nil, "", targetPath, "ext4", false,
+			nil, map[string]string{"tier": "fast"}, []string{"noatime"}).Return(nil),
+		client.EXPECT().NodeUnpublishVolume(gomock.Any(), volumeID, targetPath).Return(nil),
+	)
+
+	require.NoError(t, cv.Create())
+	assert.False(t, cv.isStaged())
+	require.NoError(t, cv.Cleanup())
+}
+
+func TestCreateFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_csiclient.NewMockCSIClient(ctrl)
+	cv := newTestCSIVolumeResource(t, client)
+
+	client.EXPECT().NodeGetCapabilities(gomock.Any()).Return(stageUnstageCapabilities(), nil)
+	client.EXPECT().NodeStageVolume(gomock.Any(), volumeID, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
+		gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("stage failed"))
+
+	err := cv.Create()
+	require.Error(t, err)
+	assert.Contains(t, cv.GetTerminalReason(), "stage failed")
+	assert.False(t, cv.isStaged())
+}
+
+func TestMarshalUnmarshalJSON(t *testing.T) {
+	cv := newTestCSIVolumeResource(t, nil)
+	cv.SetDesiredStatus(resourcestatus.ResourceStatus(CSIVolumeCreated))
+	cv.SetKnownStatus(resourcestatus.ResourceStatus(CSIVolumeCreated))
+	cv.setStaged(true)
+
+	bytes, err := json.Marshal(cv)
+	require.NoError(t, err)
+
+	unmarshalled := &CSIVolumeResource{}
+	require.NoError(t, json.Unmarshal(bytes, unmarshalled))
+	assert.Equal(t, cv.Name, unmarshalled.Name)
+	assert.Equal(t, cv.VolumeConfig, unmarshalled.VolumeConfig)
+	assert.Equal(t, cv.taskARN, unmarshalled.taskARN)
+	assert.Equal(t, cv.socketPath, unmarshalled.socketPath)
+	assert.Equal(t, cv.localDir, unmarshalled.localDir)
+	assert.Equal(t, cv.hostDir, unmarshalled.hostDir)
+	assert.True(t, unmarshalled.isStaged())
+	assert.Equal(t, cv.GetDesiredStatus(), unmarshalled.GetDesiredStatus())
+	assert.Equal(t, cv.GetKnownStatus(), unmarshalled.GetKnownStatus())
+}
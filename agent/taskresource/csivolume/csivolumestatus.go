@@ -0,0 +1,79 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package csivolume
+
+import (
+	"errors"
+	"strings"
+
+	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
+)
+
+// CSIVolumeStatus defines resource statuses for csivolume resource
+type CSIVolumeStatus resourcestatus.ResourceStatus
+
+const (
+	// CSIVolumeStatusNone is the zero state of a task resource
+	CSIVolumeStatusNone CSIVolumeStatus = iota
+	// CSIVolumeCreated represents a task resource which has been created
+	CSIVolumeCreated
+	// CSIVolumeRemoved represents a task resource which has been cleaned up
+	CSIVolumeRemoved
+)
+
+var CSIVolumeStatusMap = map[string]CSIVolumeStatus{
+	"NONE":    CSIVolumeStatusNone,
+	"CREATED": CSIVolumeCreated,
+	"REMOVED": CSIVolumeRemoved,
+}
+
+// StatusString returns a human readable string representation of this object
+func (fs CSIVolumeStatus) String() string {
+	for k, v := range CSIVolumeStatusMap {
+		if v == fs {
+			return k
+		}
+	}
+	return "NONE"
+}
+
+// MarshalJSON overrides the logic for JSON-encoding the ResourceStatus type
+func (fs *CSIVolumeStatus) MarshalJSON() ([]byte, error) {
+	if fs == nil {
+		return nil, nil
+	}
+	return []byte(`"` + fs.String() + `"`), nil
+}
+
+// UnmarshalJSON overrides the logic for parsing the JSON-encoded ResourceStatus data
+func (fs *CSIVolumeStatus) UnmarshalJSON(b []byte) error {
+	if strings.ToLower(string(b)) == "null" {
+		*fs = CSIVolumeStatusNone
+		return nil
+	}
+
+	if b[0] != '"' || b[len(b)-1] != '"' {
+		*fs = CSIVolumeStatusNone
+		return errors.New("resource status unmarshal: status must be a string or null; Got " + string(b))
+	}
+
+	strStatus := b[1 : len(b)-1]
+	stat, ok := CSIVolumeStatusMap[string(strStatus)]
+	if !ok {
+		*fs = CSIVolumeStatusNone
+		return errors.New("resource status unmarshal: unrecognized status")
+	}
+	*fs = stat
+	return nil
+}
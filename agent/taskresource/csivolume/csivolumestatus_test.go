@@ -0,0 +1,89 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package csivolume
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusString(t *testing.T) {
+	var resourceStatus CSIVolumeStatus
+
+	resourceStatus = CSIVolumeStatusNone
+	assert.Equal(t, resourceStatus.String(), "NONE")
+	resourceStatus = CSIVolumeCreated
+	assert.Equal(t, resourceStatus.String(), "CREATED")
+	resourceStatus = CSIVolumeRemoved
+	assert.Equal(t, resourceStatus.String(), "REMOVED")
+}
+
+func TestMarshalCSIVolumeStatus(t *testing.T) {
+	status := CSIVolumeStatusNone
+	bytes, err := status.MarshalJSON()
+
+	assert.NoError(t, err)
+	assert.Equal(t, `"NONE"`, string(bytes[:]))
+}
+
+func TestMarshalNilCSIVolumeStatus(t *testing.T) {
+	var status *CSIVolumeStatus
+	bytes, err := status.MarshalJSON()
+
+	assert.Nil(t, bytes)
+	assert.Nil(t, err)
+}
+
+type testCSIVolumeStatus struct {
+	SomeStatus CSIVolumeStatus `json:"status"`
+}
+
+func TestUnmarshalCSIVolumeStatus(t *testing.T) {
+	status := CSIVolumeStatusNone
+
+	err := json.Unmarshal([]byte(`"CREATED"`), &status)
+	assert.NoError(t, err)
+	assert.Equal(t, CSIVolumeCreated, status, "CREATED should unmarshal to CREATED, not "+status.String())
+
+	var testStatus testCSIVolumeStatus
+	err = json.Unmarshal([]byte(`{"status":"REMOVED"}`), &testStatus)
+	assert.NoError(t, err)
+	assert.Equal(t, CSIVolumeRemoved, testStatus.SomeStatus, "REMOVED should unmarshal to REMOVED, not "+testStatus.SomeStatus.String())
+}
+
+func TestUnmarshalNullCSIVolumeStatus(t *testing.T) {
+	status := CSIVolumeCreated
+	err := json.Unmarshal([]byte("null"), &status)
+	assert.NoError(t, err)
+	assert.Equal(t, CSIVolumeStatusNone, status, "null should unmarshal to None, not "+status.String())
+}
+
+func TestUnmarshalNonStringCSIVolumeStatusDefaultNone(t *testing.T) {
+	status := CSIVolumeCreated
+	err := json.Unmarshal([]byte(`1`), &status)
+	assert.NotNil(t, err)
+	assert.Equal(t, CSIVolumeStatusNone, status, "non-string status should unmarshal to None, not "+status.String())
+}
+
+func TestUnmarshalUnmappedCSIVolumeStatusDefaultNone(t *testing.T) {
+	status := CSIVolumeRemoved
+	err := json.Unmarshal([]byte(`"SOMEOTHER"`), &status)
+	assert.NotNil(t, err)
+	assert.Equal(t, CSIVolumeStatusNone, status, "Unmapped status should unmarshal to None, not "+status.String())
+}
@@ -23,9 +23,11 @@ import (
 	"time"
 
 	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	asmfactory "github.com/aws/amazon-ecs-agent/agent/asm/factory"
 	"github.com/aws/amazon-ecs-agent/agent/config"
 	"github.com/aws/amazon-ecs-agent/agent/s3"
 	"github.com/aws/amazon-ecs-agent/agent/s3/factory"
+	ssmfactory "github.com/aws/amazon-ecs-agent/agent/ssm/factory"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
 	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
 	"github.com/aws/amazon-ecs-agent/agent/utils/bufiowrapper"
@@ -56,10 +58,25 @@ const (
 	renameRetryAttempts   = 5
 
 	s3DownloadTimeout = 30 * time.Second
+
+	// EnvironmentFileTypeS3 is an environment file stored in S3, identified by the ARN of the object.
+	// Environment files without a type are S3 environment files.
+	EnvironmentFileTypeS3 = "s3"
+	// EnvironmentFileTypeSSM is an environment file made of the SSM parameters directly under a path,
+	// identified by the path or its ARN, e.g. arn:aws:ssm:us-west-2:123456789012:parameter/app/prod.
+	// Each parameter is set as a variable named after the last element of the name of the parameter.
+	EnvironmentFileTypeSSM = "ssm"
+	// EnvironmentFileTypeSecretsManager is an environment file made of the keys of a Secrets Manager
+	// secret whose value is a JSON object, identified by the ARN or the name of the secret.
+	EnvironmentFileTypeSecretsManager = "secretsmanager"
+	// EnvironmentFileTypeHost is an environment file read from the host, identified by its absolute
+	// path, which must be under one of the directories of ECS_ENVFILE_HOST_PATH_ALLOWLIST. The path
+	// must not go through symbolic links under the directory.
+	EnvironmentFileTypeHost = "host"
 )
 
 // EnvironmentFileResource represents envfile as a task resource
-// these environment files are retrieved from s3
+// these environment files are retrieved from s3, ssm, secrets manager or the host
 type EnvironmentFileResource struct {
 	cluster       string
 	taskARN       string
@@ -73,9 +90,17 @@ type EnvironmentFileResource struct {
 	executionCredentialsID string
 	credentialsManager     credentials.Manager
 	s3ClientCreator        factory.S3ClientCreator
+	ssmClientCreator       ssmfactory.SSMClientCreator
+	asmClientCreator       asmfactory.ClientCreator
 	ioutil                 ioutilwrapper.IOUtil
 	bufio                  bufiowrapper.Bufio
 	ipCompatibility        ipcompatibility.IPCompatibility
+	hostPathAllowlist      []string
+
+	// secretEnvVarsUnsafe are the variables of the SSM and Secrets Manager environment files, by the
+	// index of the environment file. They are kept in memory only, so that the values of secrets are
+	// never written to disk, and retrieved again if the agent restarts before the containers are created.
+	secretEnvVarsUnsafe map[int]map[string]string
 
 	// Fields for the common functionality of task resource. Access to these fields are protected by lock.
 	createdAtUnsafe      time.Time
@@ -89,8 +114,17 @@ type EnvironmentFileResource struct {
 }
 
 // NewEnvironmentFileResource creates a new EnvironmentFileResource object
-func NewEnvironmentFileResource(cluster, taskARN, region, dataDir, containerName string, envfiles []apicontainer.EnvironmentFile,
-	credentialsManager credentials.Manager, executionCredentialsID string, ipCompatibility ipcompatibility.IPCompatibility) (*EnvironmentFileResource, error) {
+func NewEnvironmentFileResource(cluster, taskARN, region, dataDir, containerName string,
+	envfiles []apicontainer.EnvironmentFile, credentialsManager credentials.Manager, executionCredentialsID string,
+	ipCompatibility ipcompatibility.IPCompatibility, hostPathAllowlist []string) (*EnvironmentFileResource, error) {
+	for _, envfile := range envfiles {
+		switch envfile.Type {
+		case "", EnvironmentFileTypeS3, EnvironmentFileTypeSSM, EnvironmentFileTypeSecretsManager, EnvironmentFileTypeHost:
+		default:
+			return nil, errors.Errorf("unsupported type %q of environment file %s", envfile.Type, envfile.Value)
+		}
+	}
+
 	envfileResource := &EnvironmentFileResource{
 		cluster:                cluster,
 		taskARN:                taskARN,
@@ -100,9 +134,12 @@ func NewEnvironmentFileResource(cluster, taskARN, region, dataDir, containerName
 		ioutil:                 ioutilwrapper.NewIOUtil(),
 		bufio:                  bufiowrapper.NewBufio(),
 		s3ClientCreator:        factory.NewS3ClientCreator(),
+		ssmClientCreator:       ssmfactory.NewSSMClientCreator(),
+		asmClientCreator:       asmfactory.NewClientCreator(),
 		executionCredentialsID: executionCredentialsID,
 		credentialsManager:     credentialsManager,
 		ipCompatibility:        ipCompatibility,
+		hostPathAllowlist:      hostPathAllowlist,
 	}
 
 	taskARNFields := strings.Split(taskARN, "/")
@@ -125,9 +162,12 @@ func (envfile *EnvironmentFileResource) Initialize(
 	envfile.initStatusToTransition()
 	envfile.credentialsManager = resourceFields.CredentialsManager
 	envfile.s3ClientCreator = factory.NewS3ClientCreator()
+	envfile.ssmClientCreator = ssmfactory.NewSSMClientCreator()
+	envfile.asmClientCreator = asmfactory.NewClientCreator()
 	envfile.ioutil = ioutilwrapper.NewIOUtil()
 	envfile.bufio = bufiowrapper.NewBufio()
 	envfile.ipCompatibility = config.InstanceIPCompatibility
+	envfile.hostPathAllowlist = config.EnvironmentFileHostPathAllowlist
 	envfile.lock.Unlock()
 
 	// if task isn't in 'created' status and desired status is 'running',
@@ -296,26 +336,37 @@ func (envfile *EnvironmentFileResource) setTerminalReason(reason string) {
 }
 
 // Create performs resource creation. This retrieves env file contents concurrently
-// from s3 and writes them to disk
+// from their sources and writes them to disk
 func (envfile *EnvironmentFileResource) Create() error {
 	seelog.Debugf("Creating envfile resource.")
-	// make sure it has the task execution role
-	executionCredentials, ok := envfile.credentialsManager.GetTaskCredentials(envfile.executionCredentialsID)
-	if !ok {
-		err := errors.New("environment file resource: unable to find execution role credentials")
-		envfile.setTerminalReason(err.Error())
-		return err
+	var iamCredentials credentials.IAMRoleCredentials
+	if envfile.requiresExecutionCredentials() {
+		// make sure it has the task execution role
+		executionCredentials, ok := envfile.credentialsManager.GetTaskCredentials(envfile.executionCredentialsID)
+		if !ok {
+			err := errors.New("environment file resource: unable to find execution role credentials")
+			envfile.setTerminalReason(err.Error())
+			return err
+		}
+		iamCredentials = executionCredentials.GetIAMRoleCredentials()
 	}
 
 	var wg sync.WaitGroup
 	errorEvents := make(chan error, len(envfile.environmentFilesSource))
 
-	iamCredentials := executionCredentials.GetIAMRoleCredentials()
-	for _, envfileSource := range envfile.environmentFilesSource {
+	for i, envfileSource := range envfile.environmentFilesSource {
 		wg.Add(1)
-		// if we support types besides S3 ARN, we will need to add filtering before the below method is called
 		// call an additional go routine per env file
-		go envfile.downloadEnvfileFromS3(envfileSource.Value, iamCredentials, &wg, errorEvents)
+		switch envfileSource.Type {
+		case EnvironmentFileTypeSSM:
+			go envfile.retrieveEnvfileFromSSM(i, envfileSource.Value, iamCredentials, &wg, errorEvents)
+		case EnvironmentFileTypeSecretsManager:
+			go envfile.retrieveEnvfileFromASM(i, envfileSource.Value, iamCredentials, &wg, errorEvents)
+		case EnvironmentFileTypeHost:
+			go envfile.copyEnvfileFromHost(i, envfileSource.Value, &wg, errorEvents)
+		default:
+			go envfile.downloadEnvfileFromS3(envfileSource.Value, iamCredentials, &wg, errorEvents)
+		}
 	}
 
 	wg.Wait()
@@ -432,6 +483,10 @@ var removeAll = os.RemoveAll
 
 // Cleanup removes env file directory for the task
 func (envfile *EnvironmentFileResource) Cleanup() error {
+	envfile.lock.Lock()
+	envfile.secretEnvVarsUnsafe = nil
+	envfile.lock.Unlock()
+
 	err := removeAll(envfile.resourceDir)
 	if err != nil {
 		return fmt.Errorf("unable to remove envfile resource directory %s: %v", envfile.resourceDir, err)
@@ -513,35 +568,40 @@ func (envfile *EnvironmentFileResource) GetContainerName() string {
 	return envfile.containerName
 }
 
-// this method converts EnvironmentFile objects into the path that it would've been downloaded at
-// and returns the list
-func (envfile *EnvironmentFileResource) convertEnvfileToPath() ([]string, error) {
-	var envfileLocations []string
-
-	for _, envfileObj := range envfile.environmentFilesSource {
-		bucket, key, err := s3.ParseS3ARN(envfileObj.Value)
-		if err != nil {
-			seelog.Errorf("unable to parse bucket and key from s3 ARN specified in environmentFile %s", envfileObj.Value)
-			return nil, err
-		}
+// this method converts an EnvironmentFile object into the path that it would've been downloaded at
+func (envfile *EnvironmentFileResource) convertEnvfileToPath(index int, envfileObj apicontainer.EnvironmentFile) (string, error) {
+	if envfileObj.Type == EnvironmentFileTypeHost {
+		return envfile.sourcePath(index, envfileObj.Type), nil
+	}
 
-		downloadPath := filepath.Join(envfile.resourceDir, bucket, key)
-		envfileLocations = append(envfileLocations, downloadPath)
+	bucket, key, err := s3.ParseS3ARN(envfileObj.Value)
+	if err != nil {
+		seelog.Errorf("unable to parse bucket and key from s3 ARN specified in environmentFile %s", envfileObj.Value)
+		return "", err
 	}
 
-	return envfileLocations, nil
+	return filepath.Join(envfile.resourceDir, bucket, key), nil
 }
 
-// ReadEnvVarsFromEnvFiles reads the environment files that have been downloaded
-// and puts them into a list of maps
+// ReadEnvVarsFromEnvFiles reads the environment files that have been downloaded, and the variables
+// of the environment files that are kept in memory, and puts them into a list of maps
 func (envfile *EnvironmentFileResource) ReadEnvVarsFromEnvfiles() ([]map[string]string, error) {
 	var envVarsPerEnvfile []map[string]string
-	envfileLocations, err := envfile.convertEnvfileToPath()
-	if err != nil {
-		return nil, err
-	}
+	for i, envfileObj := range envfile.environmentFilesSource {
+		if isSecretEnvfileType(envfileObj.Type) {
+			envVars, ok := envfile.getSecretEnvVars(i)
+			if !ok {
+				return nil, errors.Errorf("the variables of %s environment file %s have not been retrieved",
+					envfileObj.Type, envfileObj.Value)
+			}
+			envVarsPerEnvfile = append(envVarsPerEnvfile, envVars)
+			continue
+		}
 
-	for _, envfilePath := range envfileLocations {
+		envfilePath, err := envfile.convertEnvfileToPath(i, envfileObj)
+		if err != nil {
+			return nil, err
+		}
 		envVars, err := envfile.readEnvVarsFromFile(envfilePath)
 		if err != nil {
 			return nil, err
@@ -0,0 +1,248 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package envFiles
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aws/amazon-ecs-agent/agent/asm"
+	"github.com/aws/amazon-ecs-agent/agent/ssm"
+	"github.com/aws/amazon-ecs-agent/agent/utils/oswrapper"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/cihub/seelog"
+	"github.com/pkg/errors"
+)
+
+const ssmParameterResourcePrefix = "parameter"
+
+// requiresExecutionCredentials returns true if any of the environment files is retrieved from an
+// AWS service, rather than read from the host.
+func (envfile *EnvironmentFileResource) requiresExecutionCredentials() bool {
+	for _, envfileSource := range envfile.environmentFilesSource {
+		if envfileSource.Type != EnvironmentFileTypeHost {
+			return true
+		}
+	}
+	return false
+}
+
+// isSecretEnvfileType returns true if the variables of the environment files of the type are secrets,
+// which are kept in memory rather than written to disk.
+func isSecretEnvfileType(envfileType string) bool {
+	return envfileType == EnvironmentFileTypeSSM || envfileType == EnvironmentFileTypeSecretsManager
+}
+
+// sourcePath returns the path that a host environment file is copied to.
+// The underscore keeps the file from colliding with the directories of the S3 environment files,
+// which are named after S3 buckets.
+func (envfile *EnvironmentFileResource) sourcePath(index int, envfileType string) string {
+	return filepath.Join(envfile.resourceDir, envfileType+"_"+strconv.Itoa(index)+envFileExtension)
+}
+
+// regionAndResource returns the region and the resource of an ARN, or the region of the resource
+// and the value itself if the value is not an ARN.
+func (envfile *EnvironmentFileResource) regionAndResource(value string) (string, string, error) {
+	if !arn.IsARN(value) {
+		return envfile.region, value, nil
+	}
+	parsedARN, err := arn.Parse(value)
+	if err != nil {
+		return "", "", err
+	}
+	return parsedARN.Region, parsedARN.Resource, nil
+}
+
+func (envfile *EnvironmentFileResource) retrieveEnvfileFromSSM(index int, value string,
+	iamCredentials credentials.IAMRoleCredentials, wg *sync.WaitGroup, errorEvents chan error) {
+	defer wg.Done()
+
+	region, path, err := envfile.regionAndResource(value)
+	if err != nil {
+		errorEvents <- fmt.Errorf("ssm environment file %s: unable to parse the ARN: %v", value, err)
+		return
+	}
+	if arn.IsARN(value) {
+		// The resource of a parameter ARN is the name of the parameter without its leading slash
+		path = "/" + strings.TrimPrefix(path, ssmParameterResourcePrefix+"/")
+	}
+
+	client, err := envfile.ssmClientCreator.NewSSMClient(region, iamCredentials, envfile.ipCompatibility)
+	if err != nil {
+		errorEvents <- fmt.Errorf("ssm environment file %s: unable to initialize ssm client in region %s: %v",
+			value, region, err)
+		return
+	}
+
+	envVars, err := ssm.GetParametersByPathFromSSM(path, client)
+	if err != nil {
+		errorEvents <- fmt.Errorf("ssm environment file %s: unable to retrieve the parameters under path %s: %v",
+			value, path, err)
+		return
+	}
+	if len(envVars) == 0 {
+		errorEvents <- fmt.Errorf("ssm environment file %s: no parameters found under path %s", value, path)
+		return
+	}
+
+	if err := envfile.setSecretEnvVars(index, envVars); err != nil {
+		errorEvents <- fmt.Errorf("ssm environment file %s: %v", value, err)
+		return
+	}
+	seelog.Debugf("Retrieved envfile from the ssm parameters under path %s", path)
+}
+
+func (envfile *EnvironmentFileResource) retrieveEnvfileFromASM(index int, value string,
+	iamCredentials credentials.IAMRoleCredentials, wg *sync.WaitGroup, errorEvents chan error) {
+	defer wg.Done()
+
+	region, _, err := envfile.regionAndResource(value)
+	if err != nil {
+		errorEvents <- fmt.Errorf("secretsmanager environment file %s: unable to parse the ARN: %v", value, err)
+		return
+	}
+
+	client, err := envfile.asmClientCreator.NewASMClient(region, iamCredentials)
+	if err != nil {
+		errorEvents <- fmt.Errorf("secretsmanager environment file %s: unable to initialize secrets manager client in region %s: %v",
+			value, region, err)
+		return
+	}
+
+	secretString, err := asm.GetSecretFromASMWithInput(&secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(value),
+	}, client, "")
+	if err != nil {
+		errorEvents <- fmt.Errorf("secretsmanager environment file %s: unable to retrieve the secret: %v", value, err)
+		return
+	}
+
+	secretMap := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(secretString), &secretMap); err != nil {
+		errorEvents <- fmt.Errorf("secretsmanager environment file %s: the secret is not a JSON object", value)
+		return
+	}
+	envVars := make(map[string]string, len(secretMap))
+	for key, secretValue := range secretMap {
+		switch v := secretValue.(type) {
+		case string:
+			envVars[key] = v
+		case nil:
+			envVars[key] = ""
+		default:
+			encoded, _ := json.Marshal(v)
+			envVars[key] = string(encoded)
+		}
+	}
+
+	if err := envfile.setSecretEnvVars(index, envVars); err != nil {
+		errorEvents <- fmt.Errorf("secretsmanager environment file %s: %v", value, err)
+		return
+	}
+	seelog.Debugf("Retrieved envfile from secrets manager secret %s", value)
+}
+
+func (envfile *EnvironmentFileResource) copyEnvfileFromHost(index int, value string, wg *sync.WaitGroup,
+	errorEvents chan error) {
+	defer wg.Done()
+
+	source, err := envfile.openHostFile(value)
+	if err != nil {
+		errorEvents <- fmt.Errorf("host environment file %s: %v", value, err)
+		return
+	}
+	defer source.Close()
+
+	if err := mkdirAll(envfile.resourceDir, os.ModePerm); err != nil {
+		errorEvents <- fmt.Errorf("host environment file %s: unable to initialize envfile resource directory, error: %v",
+			value, err)
+		return
+	}
+	err = envfile.writeEnvFile(func(file oswrapper.File) error {
+		_, err := io.Copy(file, source)
+		return err
+	}, envfile.sourcePath(index, EnvironmentFileTypeHost))
+	if err != nil {
+		errorEvents <- fmt.Errorf("host environment file %s: unable to copy the file: %v", value, err)
+		return
+	}
+	seelog.Debugf("Copied envfile from host path %s", value)
+}
+
+// openHostFile opens the environment file at the given path of the host, if it is under one of the
+// directories of the allowlist, which are bind mounted at the same path in the agent container when
+// the agent runs in one. The file is opened relative to the directory without following symbolic
+// links, so that the file that is checked is the one that is read.
+func (envfile *EnvironmentFileResource) openHostFile(value string) (*os.File, error) {
+	if !filepath.IsAbs(value) {
+		return nil, errors.New("the path is not absolute")
+	}
+	if len(envfile.hostPathAllowlist) == 0 {
+		return nil, errors.New("environment files cannot be read from the host, ECS_ENVFILE_HOST_PATH_ALLOWLIST is not set")
+	}
+	path := filepath.Clean(value)
+	for _, allowedDir := range envfile.hostPathAllowlist {
+		rel, err := filepath.Rel(filepath.Clean(allowedDir), path)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		file, err := openInDir(allowedDir, rel)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to open the file")
+		}
+		return file, nil
+	}
+	return nil, errors.New("the path is not under a directory of ECS_ENVFILE_HOST_PATH_ALLOWLIST")
+}
+
+// setSecretEnvVars keeps the variables of an environment file in memory, after checking that they
+// follow the format of environment files.
+func (envfile *EnvironmentFileResource) setSecretEnvVars(index int, envVars map[string]string) error {
+	for key, value := range envVars {
+		if key == "" || strings.ContainsAny(key, envVariableDelimiter+"\n") || strings.HasPrefix(key, commentIndicator) {
+			return errors.Errorf("%q is not a valid environment variable name", key)
+		}
+		if strings.Contains(value, "\n") {
+			return errors.Errorf("the value of %s spans multiple lines, which environment files do not support", key)
+		}
+	}
+
+	envfile.lock.Lock()
+	defer envfile.lock.Unlock()
+
+	if envfile.secretEnvVarsUnsafe == nil {
+		envfile.secretEnvVarsUnsafe = make(map[int]map[string]string)
+	}
+	envfile.secretEnvVarsUnsafe[index] = envVars
+	return nil
+}
+
+// getSecretEnvVars returns the variables of an environment file that are kept in memory.
+func (envfile *EnvironmentFileResource) getSecretEnvVars(index int) (map[string]string, bool) {
+	envfile.lock.RLock()
+	defer envfile.lock.RUnlock()
+
+	envVars, ok := envfile.secretEnvVarsUnsafe[index]
+	return envVars, ok
+}
@@ -0,0 +1,88 @@
+//go:build linux
+// +build linux
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package envFiles
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+var openat2 = unix.Openat2
+
+// openInDir opens the regular file at the given path relative to the directory, failing if the path
+// goes through a symbolic link. The file is opened with openat2, or one path element at a time on
+// kernels that do not support openat2. Opening does not block on special files such as FIFOs, which
+// are rejected.
+func openInDir(dir, rel string) (*os.File, error) {
+	root, err := os.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+
+	path := filepath.Join(dir, rel)
+	fd, err := openat2(int(root.Fd()), rel, &unix.OpenHow{
+		Flags:   unix.O_RDONLY | unix.O_CLOEXEC | unix.O_NONBLOCK,
+		Resolve: unix.RESOLVE_IN_ROOT | unix.RESOLVE_NO_SYMLINKS,
+	})
+	if err == unix.ENOSYS {
+		fd, err = openNoFollow(int(root.Fd()), rel)
+	}
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: path, Err: err}
+	}
+
+	file := os.NewFile(uintptr(fd), path)
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		file.Close()
+		return nil, errors.Errorf("%s is not a regular file", path)
+	}
+	return file, nil
+}
+
+// openNoFollow opens the file at the given path relative to the directory one path element at a
+// time, without following symbolic links. The path has no empty, '.' or '..' elements.
+func openNoFollow(dirfd int, rel string) (int, error) {
+	names := strings.Split(rel, string(filepath.Separator))
+	fd := dirfd
+	for i, name := range names {
+		flags := unix.O_RDONLY | unix.O_CLOEXEC | unix.O_NOFOLLOW
+		if i < len(names)-1 {
+			flags |= unix.O_DIRECTORY
+		} else {
+			flags |= unix.O_NONBLOCK
+		}
+		next, err := unix.Openat(fd, name, flags, 0)
+		if fd != dirfd {
+			unix.Close(fd)
+		}
+		if err != nil {
+			return -1, err
+		}
+		fd = next
+	}
+	return fd, nil
+}
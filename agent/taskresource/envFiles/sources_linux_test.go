@@ -0,0 +1,140 @@
+//go:build linux && unit
+// +build linux,unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package envFiles
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/api/container"
+	mock_credentials "github.com/aws/amazon-ecs-agent/ecs-agent/credentials/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestCreateWithHostEnvfile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	allowedDir := t.TempDir()
+	hostPath := filepath.Join(allowedDir, "app.env")
+	require.NoError(t, os.WriteFile(hostPath, []byte("# comment\nLOG_LEVEL=debug\n"), 0600))
+
+	// Host environment files do not need the execution role
+	mockCredentialsManager := mock_credentials.NewMockManager(ctrl)
+	envfileResource := newSourcesEnvfileResource(t, []container.EnvironmentFile{
+		sampleEnvironmentFile(hostPath, EnvironmentFileTypeHost),
+	}, mockCredentialsManager)
+	envfileResource.hostPathAllowlist = []string{allowedDir}
+
+	require.NoError(t, envfileResource.Create())
+	envVars, err := envfileResource.ReadEnvVarsFromEnvfiles()
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]string{{"LOG_LEVEL": "debug"}}, envVars)
+}
+
+func TestCreateWithHostEnvfileNotAllowed(t *testing.T) {
+	allowedDir := t.TempDir()
+	otherDir := t.TempDir()
+	outsidePath := filepath.Join(otherDir, "secrets.env")
+	require.NoError(t, os.WriteFile(outsidePath, []byte("PASSWORD=hunter2\n"), 0600))
+	dotDotPath := allowedDir + "/../" + filepath.Base(otherDir) + "/secrets.env"
+	symlinkPath := filepath.Join(allowedDir, "link.env")
+	require.NoError(t, os.Symlink(outsidePath, symlinkPath))
+
+	for name, tc := range map[string]struct {
+		allowlist []string
+		path      string
+		reason    string
+	}{
+		"no allowlist":      {nil, outsidePath, "ECS_ENVFILE_HOST_PATH_ALLOWLIST is not set"},
+		"relative path":     {[]string{allowedDir}, "secrets.env", "the path is not absolute"},
+		"outside allowlist": {[]string{allowedDir}, outsidePath, "not under a directory of ECS_ENVFILE_HOST_PATH_ALLOWLIST"},
+		"escaping symlink":  {[]string{allowedDir}, symlinkPath, "unable to open the file"},
+		"dot dot escaping":  {[]string{allowedDir}, dotDotPath, "not under a directory of ECS_ENVFILE_HOST_PATH_ALLOWLIST"},
+		"missing file":      {[]string{allowedDir}, filepath.Join(allowedDir, "missing.env"), "unable to open the file"},
+		"directory":         {[]string{filepath.Dir(allowedDir)}, allowedDir, "is not a regular file"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			envfileResource := newSourcesEnvfileResource(t, []container.EnvironmentFile{
+				sampleEnvironmentFile(tc.path, EnvironmentFileTypeHost),
+			}, nil)
+			envfileResource.hostPathAllowlist = tc.allowlist
+
+			assert.Error(t, envfileResource.Create())
+			assert.Contains(t, envfileResource.GetTerminalReason(), "host environment file "+tc.path)
+			assert.Contains(t, envfileResource.GetTerminalReason(), tc.reason)
+		})
+	}
+}
+
+func TestCreateWithHostEnvfileNoSymlinks(t *testing.T) {
+	// Symbolic links are not followed, even if they point under the directory of the allowlist.
+	allowedDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(allowedDir, "app"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(allowedDir, "app/app.env"), []byte("LOG_LEVEL=debug\n"), 0600))
+	require.NoError(t, os.Symlink(filepath.Join(allowedDir, "app/app.env"), filepath.Join(allowedDir, "app.env")))
+	require.NoError(t, os.Symlink("app", filepath.Join(allowedDir, "current")))
+	require.NoError(t, unix.Mkfifo(filepath.Join(allowedDir, "app/fifo.env"), 0600))
+
+	defer func() {
+		openat2 = unix.Openat2
+	}()
+	for _, tc := range []struct {
+		name     string
+		openat2  func(int, string, *unix.OpenHow) (int, error)
+		path     string
+		expected string
+	}{
+		{"file", unix.Openat2, "app/app.env", ""},
+		{"symlinked file", unix.Openat2, "app.env", "unable to open the file"},
+		{"symlinked dir", unix.Openat2, "current/app.env", "unable to open the file"},
+		{"fifo", unix.Openat2, "app/fifo.env", "is not a regular file"},
+		{"file without openat2", openat2NotSupported, "app/app.env", ""},
+		{"symlinked file without openat2", openat2NotSupported, "app.env", "unable to open the file"},
+		{"symlinked dir without openat2", openat2NotSupported, "current/app.env", "unable to open the file"},
+		{"fifo without openat2", openat2NotSupported, "app/fifo.env", "is not a regular file"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			openat2 = tc.openat2
+			path := filepath.Join(allowedDir, tc.path)
+			envfileResource := newSourcesEnvfileResource(t, []container.EnvironmentFile{
+				sampleEnvironmentFile(path, EnvironmentFileTypeHost),
+			}, nil)
+			envfileResource.hostPathAllowlist = []string{allowedDir}
+
+			err := envfileResource.Create()
+			if tc.expected != "" {
+				assert.Error(t, err)
+				assert.Contains(t, envfileResource.GetTerminalReason(), tc.expected)
+				return
+			}
+			require.NoError(t, err)
+			envVars, err := envfileResource.ReadEnvVarsFromEnvfiles()
+			require.NoError(t, err)
+			assert.Equal(t, []map[string]string{{"LOG_LEVEL": "debug"}}, envVars)
+		})
+	}
+}
+
+func openat2NotSupported(dirfd int, path string, how *unix.OpenHow) (int, error) {
+	return -1, unix.ENOSYS
+}
@@ -0,0 +1,198 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package envFiles
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/api/container"
+	mock_asm_factory "github.com/aws/amazon-ecs-agent/agent/asm/factory/mocks"
+	mock_asm "github.com/aws/amazon-ecs-agent/agent/asm/mocks"
+	mock_ssm_factory "github.com/aws/amazon-ecs-agent/agent/ssm/factory/mocks"
+	mock_ssm "github.com/aws/amazon-ecs-agent/agent/ssm/mocks"
+	"github.com/aws/amazon-ecs-agent/agent/utils/bufiowrapper"
+	"github.com/aws/amazon-ecs-agent/agent/utils/ioutilwrapper"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
+	mock_credentials "github.com/aws/amazon-ecs-agent/ecs-agent/credentials/mocks"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmtypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testCredentials = credentials.TaskIAMRoleCredentials{
+	ARN: iamRoleARN,
+	IAMRoleCredentials: credentials.IAMRoleCredentials{
+		AccessKeyID:     accessKeyId,
+		SecretAccessKey: secretAccessKey,
+	},
+}
+
+// newSourcesEnvfileResource returns an envfile resource that writes its env files to a temporary
+// directory, so that they can be read back.
+func newSourcesEnvfileResource(t *testing.T, envfiles []container.EnvironmentFile,
+	credentialsManager credentials.Manager) *EnvironmentFileResource {
+	return &EnvironmentFileResource{
+		cluster:                cluster,
+		taskARN:                taskARN,
+		region:                 region,
+		resourceDir:            t.TempDir(),
+		environmentFilesSource: envfiles,
+		executionCredentialsID: executionCredentialsID,
+		credentialsManager:     credentialsManager,
+		ioutil:                 ioutilwrapper.NewIOUtil(),
+		bufio:                  bufiowrapper.NewBufio(),
+		ipCompatibility:        testIPCompatibility,
+	}
+}
+
+func TestNewEnvironmentFileResourceUnsupportedType(t *testing.T) {
+	_, err := NewEnvironmentFileResource(cluster, taskARN, region, resourceDir, "container",
+		[]container.EnvironmentFile{sampleEnvironmentFile("/app/prod", "dynamodb")}, nil, executionCredentialsID,
+		testIPCompatibility, nil)
+	assert.Error(t, err)
+}
+
+func TestCreateWithSSMEnvfile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCredentialsManager := mock_credentials.NewMockManager(ctrl)
+	mockSSMClientCreator := mock_ssm_factory.NewMockSSMClientCreator(ctrl)
+	mockSSMClient := mock_ssm.NewMockSSMClient(ctrl)
+	envfileResource := newSourcesEnvfileResource(t, []container.EnvironmentFile{
+		sampleEnvironmentFile("arn:aws:ssm:us-east-1:123456789012:parameter/app/prod", EnvironmentFileTypeSSM),
+	}, mockCredentialsManager)
+	envfileResource.ssmClientCreator = mockSSMClientCreator
+
+	gomock.InOrder(
+		mockCredentialsManager.EXPECT().GetTaskCredentials(executionCredentialsID).Return(testCredentials, true),
+		mockSSMClientCreator.EXPECT().NewSSMClient("us-east-1", testCredentials.IAMRoleCredentials, testIPCompatibility).
+			Return(mockSSMClient, nil),
+		mockSSMClient.EXPECT().GetParametersByPath(gomock.Any(), gomock.Any()).Do(
+			func(ctx context.Context, input *ssm.GetParametersByPathInput, optFns ...func(*ssm.Options)) {
+				assert.Equal(t, "/app/prod", aws.ToString(input.Path))
+				assert.True(t, aws.ToBool(input.WithDecryption))
+			}).Return(&ssm.GetParametersByPathOutput{
+			Parameters: []ssmtypes.Parameter{
+				{Name: aws.String("/app/prod/DB_HOST"), Value: aws.String("db.example.com")},
+				{Name: aws.String("/app/prod/DB_URL"), Value: aws.String("postgres://db?sslmode=require")},
+			},
+		}, nil),
+	)
+
+	require.NoError(t, envfileResource.Create())
+	envVars, err := envfileResource.ReadEnvVarsFromEnvfiles()
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]string{{
+		"DB_HOST": "db.example.com",
+		"DB_URL":  "postgres://db?sslmode=require",
+	}}, envVars)
+
+	// The values of the parameters are not written to disk
+	entries, err := os.ReadDir(envfileResource.resourceDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	require.NoError(t, envfileResource.Cleanup())
+	_, err = envfileResource.ReadEnvVarsFromEnvfiles()
+	assert.Error(t, err)
+}
+
+func TestCreateWithSSMEnvfileMultilineValue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCredentialsManager := mock_credentials.NewMockManager(ctrl)
+	mockSSMClientCreator := mock_ssm_factory.NewMockSSMClientCreator(ctrl)
+	mockSSMClient := mock_ssm.NewMockSSMClient(ctrl)
+	envfileResource := newSourcesEnvfileResource(t, []container.EnvironmentFile{
+		sampleEnvironmentFile("/app/prod", EnvironmentFileTypeSSM),
+	}, mockCredentialsManager)
+	envfileResource.ssmClientCreator = mockSSMClientCreator
+
+	mockCredentialsManager.EXPECT().GetTaskCredentials(executionCredentialsID).Return(testCredentials, true)
+	mockSSMClientCreator.EXPECT().NewSSMClient(region, testCredentials.IAMRoleCredentials, testIPCompatibility).
+		Return(mockSSMClient, nil)
+	mockSSMClient.EXPECT().GetParametersByPath(gomock.Any(), gomock.Any()).Return(&ssm.GetParametersByPathOutput{
+		Parameters: []ssmtypes.Parameter{
+			{Name: aws.String("/app/prod/CERT"), Value: aws.String("line1\nline2")},
+		},
+	}, nil)
+
+	assert.Error(t, envfileResource.Create())
+	assert.Contains(t, envfileResource.GetTerminalReason(), "ssm environment file /app/prod: the value of CERT spans multiple lines")
+}
+
+func TestCreateWithASMEnvfile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	secretARN := "arn:aws:secretsmanager:eu-west-1:123456789012:secret:app-config-AbCdEf"
+	mockCredentialsManager := mock_credentials.NewMockManager(ctrl)
+	mockASMClientCreator := mock_asm_factory.NewMockClientCreator(ctrl)
+	mockASMClient := mock_asm.NewMockSecretsManagerAPI(ctrl)
+	envfileResource := newSourcesEnvfileResource(t, []container.EnvironmentFile{
+		sampleEnvironmentFile(secretARN, EnvironmentFileTypeSecretsManager),
+	}, mockCredentialsManager)
+	envfileResource.asmClientCreator = mockASMClientCreator
+
+	gomock.InOrder(
+		mockCredentialsManager.EXPECT().GetTaskCredentials(executionCredentialsID).Return(testCredentials, true),
+		mockASMClientCreator.EXPECT().NewASMClient("eu-west-1", testCredentials.IAMRoleCredentials).
+			Return(mockASMClient, nil),
+		mockASMClient.EXPECT().GetSecretValue(gomock.Any(), gomock.Any()).Do(
+			func(ctx context.Context, input *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) {
+				assert.Equal(t, secretARN, aws.ToString(input.SecretId))
+			}).Return(&secretsmanager.GetSecretValueOutput{
+			SecretString: aws.String(`{"API_KEY":"abc","PORT":8080,"DEBUG":false}`),
+		}, nil),
+	)
+
+	require.NoError(t, envfileResource.Create())
+	envVars, err := envfileResource.ReadEnvVarsFromEnvfiles()
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]string{{"API_KEY": "abc", "PORT": "8080", "DEBUG": "false"}}, envVars)
+}
+
+func TestCreateWithASMEnvfileNotJSON(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCredentialsManager := mock_credentials.NewMockManager(ctrl)
+	mockASMClientCreator := mock_asm_factory.NewMockClientCreator(ctrl)
+	mockASMClient := mock_asm.NewMockSecretsManagerAPI(ctrl)
+	envfileResource := newSourcesEnvfileResource(t, []container.EnvironmentFile{
+		sampleEnvironmentFile("app-config", EnvironmentFileTypeSecretsManager),
+	}, mockCredentialsManager)
+	envfileResource.asmClientCreator = mockASMClientCreator
+
+	mockCredentialsManager.EXPECT().GetTaskCredentials(executionCredentialsID).Return(testCredentials, true)
+	mockASMClientCreator.EXPECT().NewASMClient(region, testCredentials.IAMRoleCredentials).Return(mockASMClient, nil)
+	mockASMClient.EXPECT().GetSecretValue(gomock.Any(), gomock.Any()).Return(&secretsmanager.GetSecretValueOutput{
+		SecretString: aws.String("not json"),
+	}, nil)
+
+	assert.Error(t, envfileResource.Create())
+	assert.Contains(t, envfileResource.GetTerminalReason(), "secretsmanager environment file app-config: the secret is not a JSON object")
+}
@@ -0,0 +1,141 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package envFiles
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// The task metadata variables that the values of templated environment files can reference.
+const (
+	TemplateVariableTaskARN          = "ECS_TASK_ARN"
+	TemplateVariableTaskFamily       = "ECS_TASK_FAMILY"
+	TemplateVariableTaskRevision     = "ECS_TASK_REVISION"
+	TemplateVariableAvailabilityZone = "ECS_AVAILABILITY_ZONE"
+	TemplateVariableCluster          = "ECS_CLUSTER"
+	TemplateVariableContainerName    = "ECS_CONTAINER_NAME"
+	TemplateVariableHostIP           = "ECS_HOST_IP"
+
+	templateVariableHostPortPrefix = "ECS_HOST_PORT_"
+)
+
+// HostPortTemplateVariable returns the name of the variable that templated environment files
+// reference the host port bound to a container port with, e.g. ECS_HOST_PORT_8080 for TCP and
+// ECS_HOST_PORT_8080_UDP for UDP.
+func HostPortTemplateVariable(containerPort, protocol string) string {
+	name := templateVariableHostPortPrefix + containerPort
+	if protocol != "" && !strings.EqualFold(protocol, "tcp") {
+		name += "_" + strings.ToUpper(protocol)
+	}
+	return name
+}
+
+// ResolveTemplates expands the variable references in the values of the templated environment
+// files, in place. envVarsList holds the variables of each environment file, in the order of the
+// environment files, as returned by ReadEnvVarsFromEnvfiles.
+//
+// A value references a variable as ${NAME}, and $$ stands for a literal $. The variable is looked
+// up in variables first, then in the environment files preceding the templated one, the earliest
+// environment file taking precedence as it does when the variables are merged into the container.
+// Referencing a variable that is not defined is an error.
+func (envfile *EnvironmentFileResource) ResolveTemplates(envVarsList []map[string]string,
+	variables map[string]string) error {
+	if len(envVarsList) != len(envfile.environmentFilesSource) {
+		return errors.Errorf("expected the variables of %d environment files, got %d",
+			len(envfile.environmentFilesSource), len(envVarsList))
+	}
+
+	precedingVars := make(map[string]string)
+	lookup := func(name string) (string, bool) {
+		if value, ok := variables[name]; ok {
+			return value, true
+		}
+		value, ok := precedingVars[name]
+		return value, ok
+	}
+
+	for i, envVars := range envVarsList {
+		if envfile.environmentFilesSource[i].Templated {
+			for key, value := range envVars {
+				expanded, err := expandTemplate(value, lookup)
+				if err != nil {
+					return fmt.Errorf("environment file %s: variable %s: %v",
+						envfile.environmentFilesSource[i].Value, key, err)
+				}
+				envVars[key] = expanded
+			}
+		}
+		// Merge after expanding, so that a templated environment file cannot reference its own
+		// variables, which are not ordered.
+		for key, value := range envVars {
+			if _, ok := precedingVars[key]; !ok {
+				precedingVars[key] = value
+			}
+		}
+	}
+	return nil
+}
+
+// expandTemplate replaces the ${NAME} references in value with the value of the variable, and $$
+// with $. A $ that is followed by neither { nor $ is kept as is.
+func expandTemplate(value string, lookup func(string) (string, bool)) (string, error) {
+	var expanded strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '$' || i+1 == len(value) {
+			expanded.WriteByte(value[i])
+			continue
+		}
+		switch value[i+1] {
+		case '$':
+			expanded.WriteByte('$')
+			i++
+		case '{':
+			end := strings.IndexByte(value[i+2:], '}')
+			if end < 0 {
+				return "", errors.Errorf("unterminated variable reference at offset %d", i)
+			}
+			name := value[i+2 : i+2+end]
+			if !isValidTemplateVariableName(name) {
+				return "", errors.Errorf("%q is not a valid variable name", name)
+			}
+			variable, ok := lookup(name)
+			if !ok {
+				return "", errors.Errorf("variable %s is not defined", name)
+			}
+			expanded.WriteString(variable)
+			i += 2 + end
+		default:
+			expanded.WriteByte('$')
+		}
+	}
+	return expanded.String(), nil
+}
+
+func isValidTemplateVariableName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, c := range name {
+		switch {
+		case c == '_', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
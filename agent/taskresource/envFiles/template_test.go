@@ -0,0 +1,141 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package envFiles
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/api/container"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostPortTemplateVariable(t *testing.T) {
+	assert.Equal(t, "ECS_HOST_PORT_8080", HostPortTemplateVariable("8080", "tcp"))
+	assert.Equal(t, "ECS_HOST_PORT_8080", HostPortTemplateVariable("8080", ""))
+	assert.Equal(t, "ECS_HOST_PORT_53_UDP", HostPortTemplateVariable("53", "udp"))
+}
+
+func TestExpandTemplate(t *testing.T) {
+	variables := map[string]string{
+		"ECS_HOST_PORT_8080": "32768",
+		"ECS_HOST_IP":        "10.0.0.1",
+		"EMPTY":              "",
+	}
+	lookup := func(name string) (string, bool) {
+		value, ok := variables[name]
+		return value, ok
+	}
+
+	testCases := []struct {
+		name          string
+		value         string
+		expected      string
+		expectedError string
+	}{
+		{name: "no reference", value: "plain value", expected: "plain value"},
+		{name: "reference", value: "${ECS_HOST_IP}:${ECS_HOST_PORT_8080}", expected: "10.0.0.1:32768"},
+		{name: "empty variable", value: "a${EMPTY}b", expected: "ab"},
+		{name: "escaped dollar", value: "$${ECS_HOST_IP}", expected: "${ECS_HOST_IP}"},
+		{name: "lone dollar", value: "cost $5 $", expected: "cost $5 $"},
+		{name: "undefined variable", value: "${UNDEFINED}", expectedError: "variable UNDEFINED is not defined"},
+		{name: "unterminated reference", value: "${ECS_HOST_IP", expectedError: "unterminated variable reference"},
+		{name: "invalid name", value: "${1ABC}", expectedError: "not a valid variable name"},
+		{name: "empty name", value: "${}", expectedError: "not a valid variable name"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			expanded, err := expandTemplate(tc.value, lookup)
+			if tc.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, expanded)
+		})
+	}
+}
+
+func TestResolveTemplates(t *testing.T) {
+	envfile := &EnvironmentFileResource{
+		environmentFilesSource: []container.EnvironmentFile{
+			{Value: "s3://bucket/plain", Type: "s3"},
+			{Value: "s3://bucket/templated", Type: "s3", Templated: true},
+		},
+	}
+	envVarsList := []map[string]string{
+		{"SERVICE": "web", "PLAIN": "${ECS_CLUSTER}"},
+		{"ADVERTISE": "${ECS_HOST_IP}:${ECS_HOST_PORT_8080}", "NAME": "${SERVICE}-${ECS_AVAILABILITY_ZONE}"},
+	}
+	variables := map[string]string{
+		"ECS_CLUSTER":           "cluster",
+		"ECS_HOST_IP":           "10.0.0.1",
+		"ECS_HOST_PORT_8080":    "32768",
+		"ECS_AVAILABILITY_ZONE": "us-west-2a",
+	}
+
+	require.NoError(t, envfile.ResolveTemplates(envVarsList, variables))
+	assert.Equal(t, "${ECS_CLUSTER}", envVarsList[0]["PLAIN"], "untemplated environment files are used verbatim")
+	assert.Equal(t, "10.0.0.1:32768", envVarsList[1]["ADVERTISE"])
+	assert.Equal(t, "web-us-west-2a", envVarsList[1]["NAME"])
+}
+
+func TestResolveTemplatesVariablesTakePrecedence(t *testing.T) {
+	envfile := &EnvironmentFileResource{
+		environmentFilesSource: []container.EnvironmentFile{
+			{Value: "s3://bucket/first", Type: "s3"},
+			{Value: "s3://bucket/second", Type: "s3"},
+			{Value: "s3://bucket/templated", Type: "s3", Templated: true},
+		},
+	}
+	envVarsList := []map[string]string{
+		{"LEVEL": "debug", "ENV": "prod"},
+		{"LEVEL": "info"},
+		{"VALUE": "${LEVEL}-${ENV}"},
+	}
+
+	require.NoError(t, envfile.ResolveTemplates(envVarsList, map[string]string{"ENV": "staging"}))
+	assert.Equal(t, "debug-staging", envVarsList[2]["VALUE"])
+}
+
+func TestResolveTemplatesUndefinedVariable(t *testing.T) {
+	envfile := &EnvironmentFileResource{
+		environmentFilesSource: []container.EnvironmentFile{
+			{Value: "s3://bucket/templated", Type: "s3", Templated: true},
+		},
+	}
+	envVarsList := []map[string]string{
+		{"SELF": "value", "VALUE": "${SELF}"},
+	}
+
+	err := envfile.ResolveTemplates(envVarsList, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "s3://bucket/templated")
+	assert.Contains(t, err.Error(), "variable SELF is not defined")
+}
+
+func TestResolveTemplatesMismatchedEnvfiles(t *testing.T) {
+	envfile := &EnvironmentFileResource{
+		environmentFilesSource: []container.EnvironmentFile{
+			{Value: "s3://bucket/templated", Type: "s3", Templated: true},
+		},
+	}
+	assert.Error(t, envfile.ResolveTemplates(nil, nil))
+}
@@ -0,0 +1,493 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ephemeralvolume
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource"
+	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/execwrapper"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	resourceProvisioningError = "VolumeError: Agent could not create task's volume resources"
+	ephemeralVolumeType       = "ephemeral"
+
+	// BackendXFSQuota backs the volume by a directory of an XFS filesystem whose size is limited by a
+	// project quota.
+	BackendXFSQuota = "xfsquota"
+	// BackendTmpfs backs the volume by a docker volume of the local driver mounted as a size-limited
+	// tmpfs, which is accounted against the memory of the host. Docker mounts the tmpfs while at least
+	// one container that uses the volume runs, so its contents do not outlive the containers.
+	BackendTmpfs = "tmpfs"
+
+	// ephemeralDir is the directory under the XFS mount point that holds the xfsquota volumes of the
+	// tasks.
+	ephemeralDir = "ephemeral"
+	bytesPerMiB  = 1024 * 1024
+)
+
+// EphemeralVolumeResource represents a size-limited scratch volume of a task, which is removed
+// along with its contents when the task stops.
+type EphemeralVolumeResource struct {
+	Name         string
+	VolumeConfig EphemeralVolumeConfig
+	taskARN      string
+	// localPath is the path of the volume directory, for the xfsquota backend.
+	localPath string
+	// xfsMountPoint is the mount point of the XFS filesystem of the volume, for the xfsquota backend.
+	xfsMountPoint string
+	// projectID is the XFS project of the volume directory, for the xfsquota backend.
+	projectID uint32
+
+	exec   execwrapper.Exec
+	ctx    context.Context
+	client dockerapi.DockerClient
+
+	// Fields for the common functionality of task resource. Access to these fields are protected by lock.
+	createdAtUnsafe     time.Time
+	knownStatusUnsafe   resourcestatus.ResourceStatus
+	desiredStatusUnsafe resourcestatus.ResourceStatus
+	appliedStatusUnsafe resourcestatus.ResourceStatus
+	statusToTransitions map[resourcestatus.ResourceStatus]func() error
+	terminalReason      string
+	terminalReasonOnce  sync.Once
+	lock                sync.RWMutex
+}
+
+// EphemeralVolumeConfig represents the configuration of an ephemeral task volume.
+type EphemeralVolumeConfig struct {
+	// Backend is either 'xfsquota', the default, or 'tmpfs'.
+	Backend string `json:"backend,omitempty"`
+	SizeMiB int64  `json:"sizeMiB"`
+	// HostPath is used for bind mount as part of HostConfig. It is the name of the docker volume for
+	// the tmpfs backend.
+	HostPath string `json:"ephemeralVolumeHostPath"`
+}
+
+// NewEphemeralVolumeResource creates a new EphemeralVolumeResource object. Volumes with the xfsquota
+// backend are created under the given XFS mount point, and volumes with the tmpfs backend as docker
+// volumes.
+func NewEphemeralVolumeResource(
+	ctx context.Context,
+	taskARN string,
+	taskID string,
+	name string,
+	volumeConfig *EphemeralVolumeConfig,
+	xfsMountPoint string,
+	client dockerapi.DockerClient) (*EphemeralVolumeResource, error) {
+	if volumeConfig.SizeMiB <= 0 {
+		return nil, errors.Errorf("ephemeral volume %s: size must be a positive number of MiBs", name)
+	}
+
+	ev := &EphemeralVolumeResource{
+		Name:         name,
+		VolumeConfig: *volumeConfig,
+		taskARN:      taskARN,
+		exec:         execwrapper.NewExec(),
+		ctx:          ctx,
+		client:       client,
+	}
+	switch volumeConfig.Backend {
+	case "", BackendXFSQuota:
+		if xfsMountPoint == "" {
+			return nil, errors.Errorf(
+				"ephemeral volume %s: no XFS mount point configured for the %s backend", name, BackendXFSQuota)
+		}
+		ev.VolumeConfig.Backend = BackendXFSQuota
+		ev.xfsMountPoint = xfsMountPoint
+		ev.localPath = filepath.Join(xfsMountPoint, ephemeralDir, taskID, name)
+		ev.VolumeConfig.HostPath = ev.localPath
+	case BackendTmpfs:
+		ev.VolumeConfig.HostPath = dockerVolumeName(taskID, name)
+	default:
+		return nil, errors.Errorf("ephemeral volume %s: unsupported backend %q", name, volumeConfig.Backend)
+	}
+	ev.initStatusToTransition()
+	return ev, nil
+}
+
+func (ev *EphemeralVolumeResource) Initialize(
+	config *config.Config,
+	resourceFields *taskresource.ResourceFields,
+	taskKnownStatus status.TaskStatus,
+	taskDesiredStatus status.TaskStatus) {
+
+	ev.exec = execwrapper.NewExec()
+	ev.ctx = resourceFields.Ctx
+	ev.client = resourceFields.DockerClient
+	ev.initStatusToTransition()
+}
+
+func (ev *EphemeralVolumeResource) initStatusToTransition() {
+	statusToTransitions := map[resourcestatus.ResourceStatus]func() error{
+		resourcestatus.ResourceStatus(EphemeralVolumeCreated): ev.Create,
+	}
+
+	ev.statusToTransitions = statusToTransitions
+}
+
+// DesiredTerminal returns true if the ephemeralvolume's desired status is REMOVED
+func (ev *EphemeralVolumeResource) DesiredTerminal() bool {
+	ev.lock.RLock()
+	defer ev.lock.RUnlock()
+
+	return ev.desiredStatusUnsafe == resourcestatus.ResourceStatus(EphemeralVolumeRemoved)
+}
+
+// GetTerminalReason returns an error string to propagate up through to task
+// state change messages
+func (ev *EphemeralVolumeResource) GetTerminalReason() string {
+	if ev.terminalReason == "" {
+		return resourceProvisioningError
+	}
+	return ev.terminalReason
+}
+
+func (ev *EphemeralVolumeResource) setTerminalReason(reason string) {
+	ev.terminalReasonOnce.Do(func() {
+		logger.Debug("Setting terminal reason for ephemeralvolume resource", logger.Fields{
+			field.TaskARN: ev.taskARN,
+			field.Volume:  ev.Name,
+			field.Reason:  reason,
+		})
+		ev.terminalReason = reason
+	})
+}
+
+// GetDesiredStatus safely returns the desired status of the task
+func (ev *EphemeralVolumeResource) GetDesiredStatus() resourcestatus.ResourceStatus {
+	ev.lock.RLock()
+	defer ev.lock.RUnlock()
+
+	return ev.desiredStatusUnsafe
+}
+
+// SetDesiredStatus safely sets the desired status of the resource
+func (ev *EphemeralVolumeResource) SetDesiredStatus(status resourcestatus.ResourceStatus) {
+	ev.lock.Lock()
+	defer ev.lock.Unlock()
+
+	ev.desiredStatusUnsafe = status
+}
+
+// GetKnownStatus safely returns the currently known status of the task
+func (ev *EphemeralVolumeResource) GetKnownStatus() resourcestatus.ResourceStatus {
+	ev.lock.RLock()
+	defer ev.lock.RUnlock()
+
+	return ev.knownStatusUnsafe
+}
+
+// SetKnownStatus safely sets the currently known status of the resource
+func (ev *EphemeralVolumeResource) SetKnownStatus(status resourcestatus.ResourceStatus) {
+	ev.lock.Lock()
+	defer ev.lock.Unlock()
+
+	ev.knownStatusUnsafe = status
+	ev.updateAppliedStatusUnsafe(status)
+}
+
+// KnownCreated returns true if the ephemeralvolume's known status is CREATED
+func (ev *EphemeralVolumeResource) KnownCreated() bool {
+	ev.lock.RLock()
+	defer ev.lock.RUnlock()
+
+	return ev.knownStatusUnsafe == resourcestatus.ResourceStatus(EphemeralVolumeCreated)
+}
+
+// TerminalStatus returns the last transition state of ephemeralvolume
+func (ev *EphemeralVolumeResource) TerminalStatus() resourcestatus.ResourceStatus {
+	return resourcestatus.ResourceStatus(EphemeralVolumeRemoved)
+}
+
+// NextKnownState returns the state that the resource should
+// progress to based on its `KnownState`.
+func (ev *EphemeralVolumeResource) NextKnownState() resourcestatus.ResourceStatus {
+	return ev.GetKnownStatus() + 1
+}
+
+// SteadyState returns the transition state of the resource defined as "ready"
+func (ev *EphemeralVolumeResource) SteadyState() resourcestatus.ResourceStatus {
+	return resourcestatus.ResourceStatus(EphemeralVolumeCreated)
+}
+
+// ApplyTransition calls the function required to move to the specified status
+func (ev *EphemeralVolumeResource) ApplyTransition(nextState resourcestatus.ResourceStatus) error {
+	transitionFunc, ok := ev.statusToTransitions[nextState]
+	if !ok {
+		err := errors.Errorf("resource [%s]: transition to %s impossible", ev.Name,
+			ev.StatusString(nextState))
+		ev.setTerminalReason(err.Error())
+		return err
+	}
+
+	return transitionFunc()
+}
+
+// SetAppliedStatus sets the applied status of resource and returns whether
+// the resource is already in a transition
+func (ev *EphemeralVolumeResource) SetAppliedStatus(status resourcestatus.ResourceStatus) bool {
+	ev.lock.Lock()
+	defer ev.lock.Unlock()
+
+	if ev.appliedStatusUnsafe != resourcestatus.ResourceStatus(EphemeralVolumeStatusNone) {
+		// return false to indicate the set operation failed
+		return false
+	}
+
+	ev.appliedStatusUnsafe = status
+	return true
+}
+
+// StatusString returns the string of the ephemeralvolume resource status
+func (ev *EphemeralVolumeResource) StatusString(status resourcestatus.ResourceStatus) string {
+	return EphemeralVolumeStatus(status).String()
+}
+
+// GetCreatedAt gets the timestamp for resource's creation time
+func (ev *EphemeralVolumeResource) GetCreatedAt() time.Time {
+	ev.lock.RLock()
+	defer ev.lock.RUnlock()
+
+	return ev.createdAtUnsafe
+}
+
+// SetCreatedAt sets the timestamp for resource's creation time
+func (ev *EphemeralVolumeResource) SetCreatedAt(createdAt time.Time) {
+	if createdAt.IsZero() {
+		return
+	}
+	ev.lock.Lock()
+	defer ev.lock.Unlock()
+
+	ev.createdAtUnsafe = createdAt
+}
+
+// Source returns the host path of the ephemeralvolume resource which is used as the source of the volume mount
+func (cfg *EphemeralVolumeConfig) Source() string {
+	return cfg.HostPath
+}
+
+func (cfg *EphemeralVolumeConfig) GetType() string {
+	return ephemeralVolumeType
+}
+
+// Currently not meant for use
+func (cfg *EphemeralVolumeConfig) GetVolumeId() string {
+	return ""
+}
+
+// Currently not meant for use
+func (cfg *EphemeralVolumeConfig) GetVolumeName() string {
+	return ""
+}
+
+// GetName safely returns the name of the ephemeralvolume resource
+func (ev *EphemeralVolumeResource) GetName() string {
+	ev.lock.RLock()
+	defer ev.lock.RUnlock()
+
+	return ev.Name
+}
+
+// GetSizeMiB returns the size limit of the volume in MiBs
+func (ev *EphemeralVolumeResource) GetSizeMiB() int64 {
+	ev.lock.RLock()
+	defer ev.lock.RUnlock()
+
+	return ev.VolumeConfig.SizeMiB
+}
+
+// Create creates the volume directory and limits its size.
+func (ev *EphemeralVolumeResource) Create() error {
+	var err error
+	switch ev.VolumeConfig.Backend {
+	case BackendTmpfs:
+		err = ev.createTmpfs()
+	default:
+		err = ev.createXFSQuota()
+	}
+	if err != nil {
+		err = errors.Wrapf(err, "ephemeral volume %s", ev.Name)
+		ev.setTerminalReason(err.Error())
+		return err
+	}
+	return nil
+}
+
+// Cleanup removes the volume along with its contents.
+func (ev *EphemeralVolumeResource) Cleanup() error {
+	var err error
+	switch ev.VolumeConfig.Backend {
+	case BackendTmpfs:
+		err = ev.removeTmpfs()
+	default:
+		err = ev.removeXFSQuota()
+	}
+	if err != nil {
+		return errors.Wrapf(err, "ephemeral volume %s", ev.Name)
+	}
+	return nil
+}
+
+// GetUtilizedMiB returns how much of the volume is in use, in MiBs.
+func (ev *EphemeralVolumeResource) GetUtilizedMiB() (int64, error) {
+	var used uint64
+	var err error
+	switch ev.VolumeConfig.Backend {
+	case BackendTmpfs:
+		used, err = ev.tmpfsUsedBytes()
+	default:
+		used, err = usedBytes(ev.localPath)
+	}
+	if err != nil {
+		return 0, errors.Wrapf(err, "ephemeral volume %s", ev.Name)
+	}
+	return int64(used / bytesPerMiB), nil
+}
+
+// EphemeralVolumeResourceJSON is the json representation of the ephemeralvolume resource
+type EphemeralVolumeResourceJSON struct {
+	Name          string                 `json:"name"`
+	VolumeConfig  EphemeralVolumeConfig  `json:"ephemeralVolumeConfiguration"`
+	TaskARN       string                 `json:"taskARN"`
+	LocalPath     string                 `json:"localPath"`
+	XFSMountPoint string                 `json:"xfsMountPoint,omitempty"`
+	ProjectID     uint32                 `json:"projectID,omitempty"`
+	CreatedAt     *time.Time             `json:"createdAt,omitempty"`
+	DesiredStatus *EphemeralVolumeStatus `json:"desiredStatus"`
+	KnownStatus   *EphemeralVolumeStatus `json:"knownStatus"`
+}
+
+// MarshalJSON serialises the EphemeralVolumeResourceJSON struct to JSON
+func (ev *EphemeralVolumeResource) MarshalJSON() ([]byte, error) {
+	if ev == nil {
+		return nil, errors.New("ephemeralvolume resource is nil")
+	}
+	createdAt := ev.GetCreatedAt()
+	ev.lock.RLock()
+	volumeConfig := ev.VolumeConfig
+	projectID := ev.projectID
+	ev.lock.RUnlock()
+	return json.Marshal(EphemeralVolumeResourceJSON{
+		Name:          ev.Name,
+		VolumeConfig:  volumeConfig,
+		TaskARN:       ev.taskARN,
+		LocalPath:     ev.localPath,
+		XFSMountPoint: ev.xfsMountPoint,
+		ProjectID:     projectID,
+		CreatedAt:     &createdAt,
+		DesiredStatus: func() *EphemeralVolumeStatus {
+			desiredState := ev.GetDesiredStatus()
+			s := EphemeralVolumeStatus(desiredState)
+			return &s
+		}(),
+		KnownStatus: func() *EphemeralVolumeStatus {
+			knownState := ev.GetKnownStatus()
+			s := EphemeralVolumeStatus(knownState)
+			return &s
+		}(),
+	})
+}
+
+// UnmarshalJSON deserialises the raw JSON to a EphemeralVolumeResourceJSON struct
+func (ev *EphemeralVolumeResource) UnmarshalJSON(b []byte) error {
+	temp := EphemeralVolumeResourceJSON{}
+
+	if err := json.Unmarshal(b, &temp); err != nil {
+		return err
+	}
+
+	ev.Name = temp.Name
+	ev.VolumeConfig = temp.VolumeConfig
+	ev.taskARN = temp.TaskARN
+	ev.localPath = temp.LocalPath
+	ev.xfsMountPoint = temp.XFSMountPoint
+	ev.projectID = temp.ProjectID
+	if ev.projectID != 0 {
+		// Keep other volumes from reusing the project of a restored volume.
+		projectIDs.reserve(ev.projectID)
+	}
+	if temp.DesiredStatus != nil {
+		ev.SetDesiredStatus(resourcestatus.ResourceStatus(*temp.DesiredStatus))
+	}
+	if temp.KnownStatus != nil {
+		ev.SetKnownStatus(resourcestatus.ResourceStatus(*temp.KnownStatus))
+	}
+	if temp.CreatedAt != nil && !temp.CreatedAt.IsZero() {
+		ev.SetCreatedAt(*temp.CreatedAt)
+	}
+	return nil
+}
+
+// updateAppliedStatusUnsafe updates the resource transitioning status
+func (ev *EphemeralVolumeResource) updateAppliedStatusUnsafe(knownStatus resourcestatus.ResourceStatus) {
+	if ev.appliedStatusUnsafe == resourcestatus.ResourceStatus(EphemeralVolumeStatusNone) {
+		return
+	}
+
+	// Check if the resource transition has already finished
+	if ev.appliedStatusUnsafe <= knownStatus {
+		ev.appliedStatusUnsafe = resourcestatus.ResourceStatus(EphemeralVolumeStatusNone)
+	}
+}
+
+// GetAppliedStatus safely returns the currently applied status of the resource
+func (ev *EphemeralVolumeResource) GetAppliedStatus() resourcestatus.ResourceStatus {
+	ev.lock.RLock()
+	defer ev.lock.RUnlock()
+
+	return ev.appliedStatusUnsafe
+}
+
+func (ev *EphemeralVolumeResource) DependOnTaskNetwork() bool {
+	return false
+}
+
+// BuildContainerDependency sets the container dependencies of the resource.
+func (ev *EphemeralVolumeResource) BuildContainerDependency(containerName string, satisfied apicontainerstatus.ContainerStatus,
+	dependent resourcestatus.ResourceStatus) {
+	return
+}
+
+// GetContainerDependencies returns the container dependencies of the resource.
+func (ev *EphemeralVolumeResource) GetContainerDependencies(dependent resourcestatus.ResourceStatus) []apicontainer.ContainerDependency {
+	return nil
+}
+
+// sizeOption returns the size of the volume in the format of the tmpfs and xfs_quota size options.
+func (ev *EphemeralVolumeResource) sizeOption() string {
+	return fmt.Sprintf("%dm", ev.VolumeConfig.SizeMiB)
+}
+
+// dockerVolumeName returns the name of the docker volume of a tmpfs volume of the task.
+func dockerVolumeName(taskID, name string) string {
+	return "ecs-ephemeral-" + taskID + "-" + name
+}
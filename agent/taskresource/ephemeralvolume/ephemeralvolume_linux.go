@@ -0,0 +1,157 @@
+//go:build linux
+// +build linux
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ephemeralvolume
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	xfsQuotaCommand = "xfs_quota"
+	xfsQuotaTimeout = 30 * time.Second
+	// volumeDirMode lets the containers of the task write to the volume whatever user they run as.
+	volumeDirMode = 0777
+	// dockerLocalVolumeDriver is the docker volume driver that mounts the tmpfs of tmpfs volumes.
+	dockerLocalVolumeDriver = "local"
+)
+
+var statfs = unix.Statfs
+
+// createTmpfs creates the docker volume of the volume, which docker mounts on the host as a tmpfs
+// limited to the size of the volume.
+func (ev *EphemeralVolumeResource) createTmpfs() error {
+	response := ev.client.CreateVolume(ev.ctx, ev.VolumeConfig.HostPath, dockerLocalVolumeDriver,
+		map[string]string{
+			"type":   "tmpfs",
+			"device": "tmpfs",
+			"o":      fmt.Sprintf("size=%s,mode=%o,nosuid,nodev", ev.sizeOption(), volumeDirMode),
+		}, nil, dockerclient.CreateVolumeTimeout)
+	if response.Error != nil {
+		return errors.Wrap(response.Error, "unable to create the tmpfs docker volume")
+	}
+	return nil
+}
+
+// removeTmpfs removes the docker volume of the volume.
+func (ev *EphemeralVolumeResource) removeTmpfs() error {
+	if err := ev.client.RemoveVolume(ev.ctx, ev.VolumeConfig.HostPath, dockerclient.RemoveVolumeTimeout); err != nil {
+		return errors.Wrap(err, "unable to remove the tmpfs docker volume")
+	}
+	return nil
+}
+
+// tmpfsUsedBytes returns the bytes used on the tmpfs of the docker volume of the volume, which is
+// looked up at the mount point of the volume. ecs-init binds the volumes directory of docker at the
+// same path in the agent container with slave propagation, so that the mount point resolves to the
+// tmpfs whether the agent runs on the host or in a container. The tmpfs holds no data while it is
+// not mounted.
+func (ev *EphemeralVolumeResource) tmpfsUsedBytes() (uint64, error) {
+	response := ev.client.InspectVolume(ev.ctx, ev.VolumeConfig.HostPath, dockerclient.InspectVolumeTimeout)
+	if response.Error != nil {
+		return 0, errors.Wrap(response.Error, "unable to inspect the tmpfs docker volume")
+	}
+	var stat unix.Statfs_t
+	if err := statfs(response.DockerVolume.Mountpoint, &stat); err != nil {
+		return 0, err
+	}
+	if stat.Type != unix.TMPFS_MAGIC {
+		return 0, nil
+	}
+	return (stat.Blocks - stat.Bfree) * uint64(stat.Bsize), nil
+}
+
+// createXFSQuota creates the volume directory as the root of an XFS project, whose blocks are
+// limited to the size of the volume.
+func (ev *EphemeralVolumeResource) createXFSQuota() error {
+	ev.lock.Lock()
+	if ev.projectID == 0 {
+		id, err := projectIDs.allocate(ev.localPath)
+		if err != nil {
+			ev.lock.Unlock()
+			return err
+		}
+		ev.projectID = id
+	}
+	projectID := ev.projectID
+	ev.lock.Unlock()
+
+	if err := os.MkdirAll(ev.localPath, volumeDirMode); err != nil {
+		return errors.Wrap(err, "unable to create the volume directory")
+	}
+	// MkdirAll is subject to the umask of the agent.
+	if err := os.Chmod(ev.localPath, volumeDirMode); err != nil {
+		return errors.Wrap(err, "unable to set the mode of the volume directory")
+	}
+	if err := ev.xfsQuota(fmt.Sprintf("project -s -p %s %d", ev.localPath, projectID)); err != nil {
+		return err
+	}
+	return ev.xfsQuota(fmt.Sprintf("limit -p bhard=%s %d", ev.sizeOption(), projectID))
+}
+
+// removeXFSQuota removes the volume directory and lifts the limit of its XFS project.
+func (ev *EphemeralVolumeResource) removeXFSQuota() error {
+	if err := os.RemoveAll(ev.localPath); err != nil {
+		return errors.Wrap(err, "unable to remove the volume directory")
+	}
+
+	ev.lock.Lock()
+	projectID := ev.projectID
+	ev.lock.Unlock()
+	if projectID == 0 {
+		return nil
+	}
+	if err := ev.xfsQuota(fmt.Sprintf("limit -p bhard=0 %d", projectID)); err != nil {
+		return err
+	}
+	ev.lock.Lock()
+	ev.projectID = 0
+	ev.lock.Unlock()
+	projectIDs.release(projectID)
+	return nil
+}
+
+// xfsQuota runs the given xfs_quota expert command against the XFS mount point of the volume.
+func (ev *EphemeralVolumeResource) xfsQuota(command string) error {
+	ctx, cancel := ev.exec.NewExecContextWithTimeout(context.Background(), xfsQuotaTimeout)
+	defer cancel()
+
+	output, err := ev.exec.CommandContext(ctx, xfsQuotaCommand, "-x", "-c", command, ev.xfsMountPoint).CombinedOutput()
+	if err != nil {
+		return errors.Wrapf(err, "%s %q failed: %s", xfsQuotaCommand, command, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// usedBytes returns the bytes used on the filesystem of the given path. XFS reports the limit and
+// usage of the project of a directory, rather than those of the filesystem, for the root directory
+// of a project.
+func usedBytes(path string) (uint64, error) {
+	var stat unix.Statfs_t
+	if err := statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	return (stat.Blocks - stat.Bfree) * uint64(stat.Bsize), nil
+}
@@ -0,0 +1,260 @@
+//go:build linux && unit
+// +build linux,unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ephemeralvolume
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
+	mock_dockerapi "github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi/mocks"
+	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
+	mock_execwrapper "github.com/aws/amazon-ecs-agent/ecs-agent/utils/execwrapper/mocks"
+
+	"github.com/docker/docker/api/types/volume"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	taskARN    = "arn:aws:ecs:us-west-2:123456789012:task/test-cluster/abc"
+	taskID     = "abc"
+	volumeName = "scratch"
+)
+
+func TestNewEphemeralVolumeResource(t *testing.T) {
+	ev, err := NewEphemeralVolumeResource(context.TODO(), taskARN, taskID, volumeName,
+		&EphemeralVolumeConfig{SizeMiB: 512}, "/mnt/xfs", nil)
+	require.NoError(t, err)
+	assert.Equal(t, BackendXFSQuota, ev.VolumeConfig.Backend)
+	assert.Equal(t, "/mnt/xfs/ephemeral/abc/scratch", ev.VolumeConfig.Source())
+
+	ev, err = NewEphemeralVolumeResource(context.TODO(), taskARN, taskID, volumeName,
+		&EphemeralVolumeConfig{Backend: BackendTmpfs, SizeMiB: 512}, "", nil)
+	require.NoError(t, err)
+	assert.Empty(t, ev.localPath)
+	assert.Equal(t, "ecs-ephemeral-abc-scratch", ev.VolumeConfig.Source())
+
+	for name, volumeConfig := range map[string]*EphemeralVolumeConfig{
+		"no size":                     {},
+		"unknown backend":             {Backend: "loop", SizeMiB: 512},
+		"xfsquota without mountpoint": {Backend: BackendXFSQuota, SizeMiB: 512},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewEphemeralVolumeResource(context.TODO(), taskARN, taskID, volumeName, volumeConfig, "", nil)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func newTestTmpfsResource(t *testing.T, client dockerapi.DockerClient) *EphemeralVolumeResource {
+	ev, err := NewEphemeralVolumeResource(context.TODO(), taskARN, taskID, volumeName,
+		&EphemeralVolumeConfig{Backend: BackendTmpfs, SizeMiB: 64}, "", client)
+	require.NoError(t, err)
+	return ev
+}
+
+func TestCreateAndCleanupTmpfs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+	ev := newTestTmpfsResource(t, client)
+	gomock.InOrder(
+		client.EXPECT().CreateVolume(gomock.Any(), "ecs-ephemeral-abc-scratch", "local", map[string]string{
+			"type":   "tmpfs",
+			"device": "tmpfs",
+			"o":      "size=64m,mode=777,nosuid,nodev",
+		}, nil, dockerclient.CreateVolumeTimeout).Return(dockerapi.SDKVolumeResponse{
+			DockerVolume: &volume.Volume{Name: "ecs-ephemeral-abc-scratch"},
+		}),
+		client.EXPECT().RemoveVolume(gomock.Any(), "ecs-ephemeral-abc-scratch", dockerclient.RemoveVolumeTimeout).
+			Return(nil),
+	)
+
+	require.NoError(t, ev.ApplyTransition(resourcestatus.ResourceStatus(EphemeralVolumeCreated)))
+	require.NoError(t, ev.Cleanup())
+}
+
+func TestCreateTmpfsFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+	ev := newTestTmpfsResource(t, client)
+	client.EXPECT().CreateVolume(gomock.Any(), "ecs-ephemeral-abc-scratch", "local", gomock.Any(), nil,
+		dockerclient.CreateVolumeTimeout).Return(dockerapi.SDKVolumeResponse{Error: errors.New("error")})
+
+	assert.Error(t, ev.Create())
+	assert.Contains(t, ev.GetTerminalReason(), "unable to create the tmpfs docker volume")
+}
+
+func expectXFSQuota(ctrl *gomock.Controller, execWrapper *mock_execwrapper.MockExec, mountPoint, command string,
+	err error) {
+	execWrapper.EXPECT().NewExecContextWithTimeout(gomock.Any(), xfsQuotaTimeout).
+		Return(context.WithCancel(context.Background()))
+	cmd := mock_execwrapper.NewMockCmd(ctrl)
+	execWrapper.EXPECT().CommandContext(gomock.Any(), xfsQuotaCommand, "-x", "-c", command, mountPoint).Return(cmd)
+	cmd.EXPECT().CombinedOutput().Return([]byte("xfs_quota output"), err)
+}
+
+func newTestXFSQuotaResource(t *testing.T, execWrapper *mock_execwrapper.MockExec) *EphemeralVolumeResource {
+	ev, err := NewEphemeralVolumeResource(context.TODO(), taskARN, taskID, volumeName,
+		&EphemeralVolumeConfig{SizeMiB: 1024}, t.TempDir(), nil)
+	require.NoError(t, err)
+	ev.exec = execWrapper
+	projectIDs = newProjectIDAllocator()
+	return ev
+}
+
+func TestCreateAndCleanupXFSQuota(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	execWrapper := mock_execwrapper.NewMockExec(ctrl)
+	ev := newTestXFSQuotaResource(t, execWrapper)
+	expectXFSQuota(ctrl, execWrapper, ev.xfsMountPoint, "project -s -p "+ev.localPath+" 100000", nil)
+	expectXFSQuota(ctrl, execWrapper, ev.xfsMountPoint, "limit -p bhard=1024m 100000", nil)
+	expectXFSQuota(ctrl, execWrapper, ev.xfsMountPoint, "limit -p bhard=0 100000", nil)
+
+	require.NoError(t, ev.Create())
+	info, err := os.Stat(ev.localPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(volumeDirMode), info.Mode().Perm())
+	assert.Equal(t, uint32(100000), ev.projectID)
+
+	require.NoError(t, ev.Cleanup())
+	assert.Zero(t, ev.projectID)
+	_, err = os.Stat(ev.localPath)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestCreateXFSQuotaFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	execWrapper := mock_execwrapper.NewMockExec(ctrl)
+	ev := newTestXFSQuotaResource(t, execWrapper)
+	expectXFSQuota(ctrl, execWrapper, ev.xfsMountPoint, "project -s -p "+ev.localPath+" 100000",
+		errors.New("exit status 1"))
+
+	err := ev.Create()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "xfs_quota output")
+	assert.Contains(t, ev.GetTerminalReason(), "xfs_quota")
+}
+
+func TestGetUtilizedMiB(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	execWrapper := mock_execwrapper.NewMockExec(ctrl)
+	ev := newTestXFSQuotaResource(t, execWrapper)
+
+	defer func() {
+		statfs = unix.Statfs
+	}()
+	statfs = func(path string, buf *unix.Statfs_t) error {
+		assert.Equal(t, ev.localPath, path)
+		buf.Bsize = 4096
+		buf.Blocks = 16384
+		buf.Bfree = 16384 - 2560
+		return nil
+	}
+	utilized, err := ev.GetUtilizedMiB()
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), utilized)
+
+	statfs = func(path string, buf *unix.Statfs_t) error {
+		return unix.ENOENT
+	}
+	_, err = ev.GetUtilizedMiB()
+	assert.Error(t, err)
+}
+
+func TestGetUtilizedMiBTmpfs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+	ev := newTestTmpfsResource(t, client)
+	mountPoint := "/var/lib/docker/volumes/ecs-ephemeral-abc-scratch/_data"
+	client.EXPECT().InspectVolume(gomock.Any(), "ecs-ephemeral-abc-scratch", dockerclient.InspectVolumeTimeout).
+		Return(dockerapi.SDKVolumeResponse{
+			DockerVolume: &volume.Volume{Name: "ecs-ephemeral-abc-scratch", Mountpoint: mountPoint},
+		}).Times(3)
+
+	defer func() {
+		statfs = unix.Statfs
+	}()
+	fsType := int64(unix.TMPFS_MAGIC)
+	statfs = func(path string, buf *unix.Statfs_t) error {
+		assert.Equal(t, mountPoint, path)
+		buf.Type = fsType
+		buf.Bsize = 4096
+		buf.Blocks = 16384
+		buf.Bfree = 16384 - 2560
+		return nil
+	}
+	utilized, err := ev.GetUtilizedMiB()
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), utilized)
+
+	// The tmpfs is not mounted while no container uses the volume.
+	fsType = unix.XFS_SUPER_MAGIC
+	utilized, err = ev.GetUtilizedMiB()
+	require.NoError(t, err)
+	assert.Zero(t, utilized)
+
+	statfs = func(path string, buf *unix.Statfs_t) error {
+		return unix.ENOENT
+	}
+	_, err = ev.GetUtilizedMiB()
+	assert.Error(t, err)
+}
+
+func TestMarshalUnmarshalJSON(t *testing.T) {
+	ev, err := NewEphemeralVolumeResource(context.TODO(), taskARN, taskID, volumeName,
+		&EphemeralVolumeConfig{SizeMiB: 1024}, "/mnt/xfs", nil)
+	require.NoError(t, err)
+	ev.projectID = 100042
+	ev.SetDesiredStatus(resourcestatus.ResourceStatus(EphemeralVolumeCreated))
+	ev.SetKnownStatus(resourcestatus.ResourceStatus(EphemeralVolumeCreated))
+
+	bytes, err := json.Marshal(ev)
+	require.NoError(t, err)
+
+	projectIDs = newProjectIDAllocator()
+	unmarshalled := &EphemeralVolumeResource{}
+	require.NoError(t, json.Unmarshal(bytes, unmarshalled))
+	assert.Equal(t, ev.Name, unmarshalled.Name)
+	assert.Equal(t, ev.VolumeConfig, unmarshalled.VolumeConfig)
+	assert.Equal(t, ev.taskARN, unmarshalled.taskARN)
+	assert.Equal(t, ev.localPath, unmarshalled.localPath)
+	assert.Equal(t, ev.xfsMountPoint, unmarshalled.xfsMountPoint)
+	assert.Equal(t, ev.projectID, unmarshalled.projectID)
+	assert.Equal(t, ev.GetDesiredStatus(), unmarshalled.GetDesiredStatus())
+	assert.Equal(t, ev.GetKnownStatus(), unmarshalled.GetKnownStatus())
+	// The project of the restored volume is not handed out again.
+	assert.Contains(t, projectIDs.inUse, uint32(100042))
+}
@@ -0,0 +1,47 @@
+//go:build !linux
+// +build !linux
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ephemeralvolume
+
+import (
+	"github.com/pkg/errors"
+)
+
+var errUnsupported = errors.New("ephemeral volumes are only supported on Linux container instances")
+
+func (ev *EphemeralVolumeResource) createTmpfs() error {
+	return errUnsupported
+}
+
+func (ev *EphemeralVolumeResource) removeTmpfs() error {
+	return errUnsupported
+}
+
+func (ev *EphemeralVolumeResource) tmpfsUsedBytes() (uint64, error) {
+	return 0, errUnsupported
+}
+
+func (ev *EphemeralVolumeResource) createXFSQuota() error {
+	return errUnsupported
+}
+
+func (ev *EphemeralVolumeResource) removeXFSQuota() error {
+	return errUnsupported
+}
+
+func usedBytes(path string) (uint64, error) {
+	return 0, errUnsupported
+}
@@ -0,0 +1,79 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ephemeralvolume
+
+import (
+	"errors"
+	"strings"
+
+	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
+)
+
+// EphemeralVolumeStatus defines resource statuses for ephemeralvolume resource
+type EphemeralVolumeStatus resourcestatus.ResourceStatus
+
+const (
+	// EphemeralVolumeStatusNone is the zero state of a task resource
+	EphemeralVolumeStatusNone EphemeralVolumeStatus = iota
+	// EphemeralVolumeCreated represents a task resource which has been created
+	EphemeralVolumeCreated
+	// EphemeralVolumeRemoved represents a task resource which has been cleaned up
+	EphemeralVolumeRemoved
+)
+
+var EphemeralVolumeStatusMap = map[string]EphemeralVolumeStatus{
+	"NONE":    EphemeralVolumeStatusNone,
+	"CREATED": EphemeralVolumeCreated,
+	"REMOVED": EphemeralVolumeRemoved,
+}
+
+// StatusString returns a human readable string representation of this object
+func (fs EphemeralVolumeStatus) String() string {
+	for k, v := range EphemeralVolumeStatusMap {
+		if v == fs {
+			return k
+		}
+	}
+	return "NONE"
+}
+
+// MarshalJSON overrides the logic for JSON-encoding the ResourceStatus type
+func (fs *EphemeralVolumeStatus) MarshalJSON() ([]byte, error) {
+	if fs == nil {
+		return nil, nil
+	}
+	return []byte(`"` + fs.String() + `"`), nil
+}
+
+// UnmarshalJSON overrides the logic for parsing the JSON-encoded ResourceStatus data
+func (fs *EphemeralVolumeStatus) UnmarshalJSON(b []byte) error {
+	if strings.ToLower(string(b)) == "null" {
+		*fs = EphemeralVolumeStatusNone
+		return nil
+	}
+
+	if b[0] != '"' || b[len(b)-1] != '"' {
+		*fs = EphemeralVolumeStatusNone
+		return errors.New("resource status unmarshal: status must be a string or null; Got " + string(b))
+	}
+
+	strStatus := b[1 : len(b)-1]
+	stat, ok := EphemeralVolumeStatusMap[string(strStatus)]
+	if !ok {
+		*fs = EphemeralVolumeStatusNone
+		return errors.New("resource status unmarshal: unrecognized status")
+	}
+	*fs = stat
+	return nil
+}
@@ -0,0 +1,89 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ephemeralvolume
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusString(t *testing.T) {
+	var resourceStatus EphemeralVolumeStatus
+
+	resourceStatus = EphemeralVolumeStatusNone
+	assert.Equal(t, resourceStatus.String(), "NONE")
+	resourceStatus = EphemeralVolumeCreated
+	assert.Equal(t, resourceStatus.String(), "CREATED")
+	resourceStatus = EphemeralVolumeRemoved
+	assert.Equal(t, resourceStatus.String(), "REMOVED")
+}
+
+func TestMarshalEphemeralVolumeStatus(t *testing.T) {
+	status := EphemeralVolumeStatusNone
+	bytes, err := status.MarshalJSON()
+
+	assert.NoError(t, err)
+	assert.Equal(t, `"NONE"`, string(bytes[:]))
+}
+
+func TestMarshalNilEphemeralVolumeStatus(t *testing.T) {
+	var status *EphemeralVolumeStatus
+	bytes, err := status.MarshalJSON()
+
+	assert.Nil(t, bytes)
+	assert.Nil(t, err)
+}
+
+type testEphemeralVolumeStatus struct {
+	SomeStatus EphemeralVolumeStatus `json:"status"`
+}
+
+func TestUnmarshalEphemeralVolumeStatus(t *testing.T) {
+	status := EphemeralVolumeStatusNone
+
+	err := json.Unmarshal([]byte(`"CREATED"`), &status)
+	assert.NoError(t, err)
+	assert.Equal(t, EphemeralVolumeCreated, status, "CREATED should unmarshal to CREATED, not "+status.String())
+
+	var testStatus testEphemeralVolumeStatus
+	err = json.Unmarshal([]byte(`{"status":"REMOVED"}`), &testStatus)
+	assert.NoError(t, err)
+	assert.Equal(t, EphemeralVolumeRemoved, testStatus.SomeStatus, "REMOVED should unmarshal to REMOVED, not "+testStatus.SomeStatus.String())
+}
+
+func TestUnmarshalNullEphemeralVolumeStatus(t *testing.T) {
+	status := EphemeralVolumeCreated
+	err := json.Unmarshal([]byte("null"), &status)
+	assert.NoError(t, err)
+	assert.Equal(t, EphemeralVolumeStatusNone, status, "null should unmarshal to None, not "+status.String())
+}
+
+func TestUnmarshalNonStringEphemeralVolumeStatusDefaultNone(t *testing.T) {
+	status := EphemeralVolumeCreated
+	err := json.Unmarshal([]byte(`1`), &status)
+	assert.NotNil(t, err)
+	assert.Equal(t, EphemeralVolumeStatusNone, status, "non-string status should unmarshal to None, not "+status.String())
+}
+
+func TestUnmarshalUnmappedEphemeralVolumeStatusDefaultNone(t *testing.T) {
+	status := EphemeralVolumeRemoved
+	err := json.Unmarshal([]byte(`"SOMEOTHER"`), &status)
+	assert.NotNil(t, err)
+	assert.Equal(t, EphemeralVolumeStatusNone, status, "Unmapped status should unmarshal to None, not "+status.String())
+}
@@ -0,0 +1,144 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ephemeralvolume
+
+import (
+	"os"
+	"sync"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// minProjectID and maxProjectID bound the XFS projects used by the agent, away from the low
+	// project IDs that are usually managed by hand.
+	minProjectID = 100000
+	maxProjectID = minProjectID + 1<<20
+)
+
+// projectIDs tracks the XFS projects of the ephemeral volumes of the agent.
+var projectIDs = newProjectIDAllocator()
+
+// ProjectIDStore persists the XFS project IDs allocated to the directories of the ephemeral volumes
+// across agent restarts.
+type ProjectIDStore interface {
+	// SaveXFSProjectID saves the XFS project ID allocated to a directory.
+	SaveXFSProjectID(uint32, string) error
+	// DeleteXFSProjectID deletes an XFS project ID.
+	DeleteXFSProjectID(uint32) error
+	// GetXFSProjectIDs gets the directories of all the allocated XFS project IDs.
+	GetXFSProjectIDs() (map[uint32]string, error)
+}
+
+// projectIDAllocator hands out the XFS project IDs that are not used by another volume.
+type projectIDAllocator struct {
+	lock  sync.Mutex
+	inUse map[uint32]struct{}
+	next  uint32
+	store ProjectIDStore
+}
+
+func newProjectIDAllocator() *projectIDAllocator {
+	return &projectIDAllocator{
+		inUse: make(map[uint32]struct{}),
+		next:  minProjectID,
+	}
+}
+
+// ConfigureProjectIDStore sets the store of the XFS project IDs, and keeps the project IDs saved in
+// it from being allocated again while their directory exists. The project IDs of the directories that
+// do not exist anymore are deleted from the store.
+func ConfigureProjectIDStore(store ProjectIDStore) error {
+	return projectIDs.configure(store)
+}
+
+func (a *projectIDAllocator) configure(store ProjectIDStore) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.store = store
+	saved, err := store.GetXFSProjectIDs()
+	if err != nil {
+		return err
+	}
+	for id, path := range saved {
+		if _, err := os.Stat(path); err == nil {
+			a.inUse[id] = struct{}{}
+			continue
+		}
+		if _, ok := a.inUse[id]; ok {
+			continue
+		}
+		a.delete(id)
+	}
+	return nil
+}
+
+// allocate returns a project ID that is not in use, and saves it along with the directory it is
+// allocated to.
+func (a *projectIDAllocator) allocate(path string) (uint32, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	for i := 0; i < maxProjectID-minProjectID; i++ {
+		id := a.next
+		a.next++
+		if a.next >= maxProjectID {
+			a.next = minProjectID
+		}
+		if _, ok := a.inUse[id]; ok {
+			continue
+		}
+		if a.store != nil {
+			if err := a.store.SaveXFSProjectID(id, path); err != nil {
+				return 0, errors.Wrap(err, "unable to save the XFS project id")
+			}
+		}
+		a.inUse[id] = struct{}{}
+		return id, nil
+	}
+	return 0, errors.New("no XFS project id available")
+}
+
+// reserve marks the project ID as in use.
+func (a *projectIDAllocator) reserve(id uint32) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.inUse[id] = struct{}{}
+}
+
+// release makes the project ID available again.
+func (a *projectIDAllocator) release(id uint32) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	delete(a.inUse, id)
+	a.delete(id)
+}
+
+func (a *projectIDAllocator) delete(id uint32) {
+	if a.store == nil {
+		return
+	}
+	if err := a.store.DeleteXFSProjectID(id); err != nil {
+		logger.Warn("Unable to delete XFS project id", logger.Fields{
+			"projectID": id,
+			field.Error: err,
+		})
+	}
+}
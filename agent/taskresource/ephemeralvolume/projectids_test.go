@@ -0,0 +1,94 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ephemeralvolume
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectIDAllocator(t *testing.T) {
+	allocator := newProjectIDAllocator()
+	allocator.reserve(minProjectID + 1)
+
+	id, err := allocator.allocate("/mnt/xfs/ephemeral/task/scratch")
+	require.NoError(t, err)
+	assert.Equal(t, uint32(minProjectID), id)
+	// Reserved ids are skipped.
+	id, err = allocator.allocate("/mnt/xfs/ephemeral/task/scratch")
+	require.NoError(t, err)
+	assert.Equal(t, uint32(minProjectID+2), id)
+
+	// Released ids are handed out again once the allocator wraps around.
+	allocator.release(minProjectID)
+	allocator.next = maxProjectID - 1
+	id, err = allocator.allocate("/mnt/xfs/ephemeral/task/scratch")
+	require.NoError(t, err)
+	assert.Equal(t, uint32(maxProjectID-1), id)
+	id, err = allocator.allocate("/mnt/xfs/ephemeral/task/scratch")
+	require.NoError(t, err)
+	assert.Equal(t, uint32(minProjectID), id)
+}
+
+type memoryProjectIDStore map[uint32]string
+
+func (s memoryProjectIDStore) SaveXFSProjectID(id uint32, path string) error {
+	s[id] = path
+	return nil
+}
+
+func (s memoryProjectIDStore) DeleteXFSProjectID(id uint32) error {
+	delete(s, id)
+	return nil
+}
+
+func (s memoryProjectIDStore) GetXFSProjectIDs() (map[uint32]string, error) {
+	ids := make(map[uint32]string)
+	for id, path := range s {
+		ids[id] = path
+	}
+	return ids, nil
+}
+
+func TestProjectIDAllocatorStore(t *testing.T) {
+	existing := filepath.Join(t.TempDir(), "scratch")
+	require.NoError(t, os.Mkdir(existing, 0755))
+	removed := filepath.Join(t.TempDir(), "removed")
+	store := memoryProjectIDStore{
+		minProjectID:     existing,
+		minProjectID + 1: removed,
+	}
+
+	allocator := newProjectIDAllocator()
+	require.NoError(t, allocator.configure(store))
+	// The id of the directory that exists is kept, the other one is deleted.
+	assert.Contains(t, allocator.inUse, uint32(minProjectID))
+	assert.NotContains(t, allocator.inUse, uint32(minProjectID+1))
+	assert.Equal(t, memoryProjectIDStore{minProjectID: existing}, store)
+
+	id, err := allocator.allocate(removed)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(minProjectID+1), id)
+	assert.Equal(t, removed, store[id])
+
+	allocator.release(id)
+	assert.NotContains(t, store, id)
+}
@@ -0,0 +1,311 @@
+//go:build linux
+// +build linux
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package firelens
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// The validation below catches the errors that make fluentd or fluent bit fail to load a config file, so that the
+// task fails with the reason instead of the firelens container exiting on start. It checks the structure of the
+// config file, not the options of the plugins, which depend on the plugins installed in the firelens container. Only
+// the generated config file and the external config files from S3 are validated: an external config file of type
+// "file" is in the image of the firelens container, which the agent can't read.
+
+// fluentbitSectionsWithName are the fluent bit sections that fail to load without a Name entry.
+var fluentbitSectionsWithName = map[string]bool{
+	"INPUT":            true,
+	"FILTER":           true,
+	"OUTPUT":           true,
+	"CUSTOM":           true,
+	"PARSER":           true,
+	"MULTILINE_PARSER": true,
+}
+
+// fluentdDirectivesWithType are the fluentd directives that fail to load without a @type parameter.
+var fluentdDirectivesWithType = map[string]bool{
+	"source": true,
+	"match":  true,
+	"filter": true,
+}
+
+// configValidationError is an error in a config file, at a line of the file.
+type configValidationError struct {
+	line int
+	msg  string
+}
+
+func (err *configValidationError) Error() string {
+	return fmt.Sprintf("line %d: %s", err.line, err.msg)
+}
+
+func newConfigValidationError(line int, format string, args ...interface{}) error {
+	return &configValidationError{line: line, msg: fmt.Sprintf(format, args...)}
+}
+
+// validateConfig validates the content of a config file of the given firelens config type.
+func validateConfig(firelensConfigType string, content []byte) error {
+	if firelensConfigType == FirelensConfigTypeFluentd {
+		return validateFluentdConfig(content)
+	}
+	return validateFluentbitConfig(content)
+}
+
+// validateFluentbitConfig validates a config file in the classic fluent bit format: sections made of a [NAME]
+// header followed by indented "Key Value" entries, and the @INCLUDE and @SET commands.
+func validateFluentbitConfig(content []byte) error {
+	var section, indentation string
+	sectionLine := 0
+	hasName := false
+
+	endSection := func() error {
+		if section != "" && fluentbitSectionsWithName[section] && !hasName {
+			return newConfigValidationError(sectionLine, "[%s] section has no Name entry", section)
+		}
+		return nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		lineIndentation := line[:len(line)-len(trimmed)]
+
+		switch {
+		case strings.HasPrefix(trimmed, "["):
+			if lineIndentation != "" {
+				return newConfigValidationError(lineNum, "section header %s must not be indented", trimmed)
+			}
+			if !strings.HasSuffix(trimmed, "]") || len(trimmed) < 3 {
+				return newConfigValidationError(lineNum, "invalid section header %s", trimmed)
+			}
+			if err := endSection(); err != nil {
+				return err
+			}
+			section = strings.ToUpper(strings.TrimSpace(trimmed[1 : len(trimmed)-1]))
+			sectionLine = lineNum
+			indentation = ""
+			hasName = false
+		case strings.HasPrefix(trimmed, "@"):
+			if err := validateFluentbitCommand(lineNum, trimmed); err != nil {
+				return err
+			}
+		default:
+			if section == "" {
+				return newConfigValidationError(lineNum, "entry %q is not in a section", trimmed)
+			}
+			if lineIndentation == "" {
+				return newConfigValidationError(lineNum, "entry %q of [%s] section is not indented", trimmed, section)
+			}
+			if indentation == "" {
+				indentation = lineIndentation
+			} else if lineIndentation != indentation {
+				return newConfigValidationError(lineNum, "invalid indentation of entry %q of [%s] section", trimmed,
+					section)
+			}
+			fields := strings.Fields(trimmed)
+			if len(fields) < 2 {
+				return newConfigValidationError(lineNum, "entry %q of [%s] section has no value", trimmed, section)
+			}
+			if strings.EqualFold(fields[0], "Name") {
+				hasName = true
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return endSection()
+}
+
+func validateFluentbitCommand(lineNum int, command string) error {
+	fields := strings.Fields(command)
+	switch strings.ToUpper(fields[0]) {
+	case "@INCLUDE":
+		if len(fields) < 2 {
+			return newConfigValidationError(lineNum, "@INCLUDE has no path")
+		}
+	case "@SET":
+		if len(fields) < 2 || !strings.Contains(fields[1], "=") || strings.HasPrefix(fields[1], "=") {
+			return newConfigValidationError(lineNum, "@SET must be followed by KEY=VALUE")
+		}
+	default:
+		return newConfigValidationError(lineNum, "unknown command %s", fields[0])
+	}
+	return nil
+}
+
+// fluentdDirective is a <name argument> directive of a fluentd config file that has not been closed yet.
+type fluentdDirective struct {
+	name    string
+	line    int
+	hasType bool
+}
+
+// validateFluentdConfig validates a config file in the fluentd format: nested <name argument> ... </name>
+// directives of "key value" parameters, and the @include directive.
+func validateFluentdConfig(content []byte) error {
+	var directives []*fluentdDirective
+	// closer is the character that ends a multi line value, which is a quoted string or a JSON array or object.
+	var closer byte
+	var depth, multiLineValueLine int
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		trimmed := strings.TrimSpace(scanner.Text())
+
+		if closer != 0 {
+			closer, depth = continueFluentdValue(trimmed, closer, depth)
+			continue
+		}
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(trimmed, "</"):
+			if !strings.HasSuffix(trimmed, ">") {
+				return newConfigValidationError(lineNum, "invalid closing tag %s", trimmed)
+			}
+			name := strings.TrimSpace(trimmed[2 : len(trimmed)-1])
+			if len(directives) == 0 {
+				return newConfigValidationError(lineNum, "closing tag </%s> has no opening tag", name)
+			}
+			open := directives[len(directives)-1]
+			if name != open.name {
+				return newConfigValidationError(lineNum, "closing tag </%s> does not match <%s> opened on line %d",
+					name, open.name, open.line)
+			}
+			if fluentdDirectivesWithType[open.name] && !open.hasType {
+				return newConfigValidationError(open.line, "<%s> directive has no @type parameter", open.name)
+			}
+			directives = directives[:len(directives)-1]
+		case strings.HasPrefix(trimmed, "<"):
+			if !strings.HasSuffix(trimmed, ">") {
+				return newConfigValidationError(lineNum, "invalid tag %s", trimmed)
+			}
+			fields := strings.Fields(trimmed[1 : len(trimmed)-1])
+			if len(fields) == 0 {
+				return newConfigValidationError(lineNum, "empty tag")
+			}
+			directives = append(directives, &fluentdDirective{name: fields[0], line: lineNum})
+		case strings.HasPrefix(trimmed, "@include"):
+			if len(strings.Fields(trimmed)) < 2 {
+				return newConfigValidationError(lineNum, "@include has no path")
+			}
+		default:
+			key, value := trimmed, ""
+			if i := strings.IndexAny(trimmed, " \t"); i >= 0 {
+				key, value = trimmed[:i], strings.TrimSpace(trimmed[i:])
+			}
+			if len(directives) > 0 && (key == "@type" || key == "type") {
+				directives[len(directives)-1].hasType = true
+			}
+			closer, depth = startFluentdValue(value)
+			multiLineValueLine = lineNum
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if closer != 0 {
+		return newConfigValidationError(multiLineValueLine, "value is not terminated")
+	}
+	if len(directives) > 0 {
+		open := directives[len(directives)-1]
+		return newConfigValidationError(open.line, "<%s> directive is not closed", open.name)
+	}
+	return nil
+}
+
+// startFluentdValue returns the character that ends the value if it continues on the next lines, with the nesting
+// depth of the JSON array or object it starts, and 0 if the value ends on its line.
+func startFluentdValue(value string) (byte, int) {
+	if value == "" {
+		return 0, 0
+	}
+	switch value[0] {
+	case '"':
+		if closed, _ := scanQuoted(value[1:]); closed {
+			return 0, 0
+		}
+		return '"', 0
+	case '[':
+		return continueFluentdValue(value[1:], ']', 1)
+	case '{':
+		return continueFluentdValue(value[1:], '}', 1)
+	}
+	return 0, 0
+}
+
+// continueFluentdValue consumes a line of a multi line value, and returns the character that ends the value with
+// the remaining nesting depth, or 0 if the value ends on the line.
+func continueFluentdValue(line string, closer byte, depth int) (byte, int) {
+	if closer == '"' {
+		if closed, _ := scanQuoted(line); closed {
+			return 0, 0
+		}
+		return '"', 0
+	}
+	opener := byte('[')
+	if closer == '}' {
+		opener = '{'
+	}
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '"':
+			closed, end := scanQuoted(line[i+1:])
+			if !closed {
+				// Quoted strings of JSON values do not span lines.
+				return closer, depth
+			}
+			i += end + 1
+		case opener:
+			depth++
+		case closer:
+			depth--
+			if depth == 0 {
+				return 0, 0
+			}
+		}
+	}
+	return closer, depth
+}
+
+// scanQuoted returns whether a quoted string, whose opening quote precedes s, is closed in s, and the index of the
+// closing quote.
+func scanQuoted(s string) (bool, int) {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return true, i
+		}
+	}
+	return false, len(s)
+}
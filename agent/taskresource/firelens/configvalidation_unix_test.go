@@ -0,0 +1,202 @@
+//go:build linux && unit
+// +build linux,unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package firelens
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateFluentbitConfig(t *testing.T) {
+	testCases := []struct {
+		name          string
+		config        string
+		expectedError string
+	}{
+		{
+			name: "valid config",
+			config: `@SET region=us-west-2
+@INCLUDE /fluent-bit/etc/external.conf
+
+[SERVICE]
+    Flush 1
+
+# comment
+[INPUT]
+    Name forward
+    unix_path /var/run/fluent.sock
+
+[OUTPUT]
+	Name cloudwatch
+	Match *
+	region ${region}
+`,
+		},
+		{
+			name:          "entry outside of a section",
+			config:        "Name forward\n",
+			expectedError: `line 1: entry "Name forward" is not in a section`,
+		},
+		{
+			name:          "entry not indented",
+			config:        "[INPUT]\nName forward\n",
+			expectedError: `line 2: entry "Name forward" of [INPUT] section is not indented`,
+		},
+		{
+			name:          "inconsistent indentation",
+			config:        "[INPUT]\n    Name forward\n  Tag app\n",
+			expectedError: `line 3: invalid indentation of entry "Tag app" of [INPUT] section`,
+		},
+		{
+			name:          "entry without value",
+			config:        "[OUTPUT]\n    Name null\n    Match\n",
+			expectedError: `line 3: entry "Match" of [OUTPUT] section has no value`,
+		},
+		{
+			name:          "section without name",
+			config:        "[FILTER]\n    Match *\n\n[OUTPUT]\n    Name null\n",
+			expectedError: "line 1: [FILTER] section has no Name entry",
+		},
+		{
+			name:          "last section without name",
+			config:        "[OUTPUT]\n    Name null\n[OUTPUT]\n    Match *\n",
+			expectedError: "line 3: [OUTPUT] section has no Name entry",
+		},
+		{
+			name:          "invalid section header",
+			config:        "[INPUT\n    Name forward\n",
+			expectedError: "line 1: invalid section header [INPUT",
+		},
+		{
+			name:          "include without path",
+			config:        "@INCLUDE\n",
+			expectedError: "line 1: @INCLUDE has no path",
+		},
+		{
+			name:          "invalid set",
+			config:        "@SET region\n",
+			expectedError: "line 1: @SET must be followed by KEY=VALUE",
+		},
+		{
+			name:          "unknown command",
+			config:        "@IMPORT file.conf\n",
+			expectedError: "line 1: unknown command @IMPORT",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateConfig(FirelensConfigTypeFluentbit, []byte(tc.config))
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, tc.expectedError, err.Error())
+		})
+	}
+}
+
+func TestValidateFluentdConfig(t *testing.T) {
+	testCases := []struct {
+		name          string
+		config        string
+		expectedError string
+	}{
+		{
+			name: "valid config",
+			config: `@include /fluentd/etc/external.conf
+
+<source>
+  @type forward
+</source>
+
+<label @app>
+  <filter app-firelens**>
+    @type record_transformer
+    <record>
+      hostname "#{Socket.gethostname}"
+    </record>
+  </filter>
+  <match app-firelens**>
+    @type copy
+    <store>
+      @type stdout
+    </store>
+  </match>
+</label>
+
+<match **>
+	@type	cloudwatch_logs
+  tags ["a",
+        "b]"]
+  message "multi
+line"
+</match>
+`,
+		},
+		{
+			name:          "unclosed directive",
+			config:        "<source>\n  @type forward\n",
+			expectedError: "line 1: <source> directive is not closed",
+		},
+		{
+			name:          "mismatched closing tag",
+			config:        "<match **>\n  @type stdout\n</source>\n",
+			expectedError: "line 3: closing tag </source> does not match <match> opened on line 1",
+		},
+		{
+			name:          "closing tag without opening tag",
+			config:        "</match>\n",
+			expectedError: "line 1: closing tag </match> has no opening tag",
+		},
+		{
+			name:          "directive without type",
+			config:        "<match **>\n  <buffer>\n    @type file\n  </buffer>\n</match>\n",
+			expectedError: "line 1: <match> directive has no @type parameter",
+		},
+		{
+			name:          "invalid tag",
+			config:        "<match **\n  @type stdout\n</match>\n",
+			expectedError: "line 1: invalid tag <match **",
+		},
+		{
+			name:          "unterminated value",
+			config:        "<match **>\n  @type stdout\n  tags [\"a\",\n</match>\n",
+			expectedError: "line 3: value is not terminated",
+		},
+		{
+			name:          "include without path",
+			config:        "@include\n",
+			expectedError: "line 1: @include has no path",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateConfig(FirelensConfigTypeFluentd, []byte(tc.config))
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, tc.expectedError, err.Error())
+		})
+	}
+}
@@ -42,7 +42,8 @@ const (
 	ExternalConfigTypeOption = "config-file-type"
 	// ExternalConfigTypeS3 means the firelens container is using a config file from S3.
 	ExternalConfigTypeS3 = "s3"
-	// ExternalConfigTypeFile means the firelens container is using a config file inside the container.
+	// ExternalConfigTypeFile means the firelens container is using a config file inside the container. Unlike a config
+	// file from S3, the agent can't read it, so it's not validated before the firelens container starts.
 	ExternalConfigTypeFile = "file"
 	// S3ConfigPathFluentd and S3ConfigPathFluentbit are the paths where we bind mount the config downloaded from S3 to.
 	S3ConfigPathFluentd   = "/fluentd/etc/external.conf"
@@ -181,3 +182,23 @@ func (firelens *FirelensResource) BuildContainerDependency(containerName string,
 func (firelens *FirelensResource) GetContainerDependencies(dependent resourcestatus.ResourceStatus) []apicontainer.ContainerDependency {
 	return nil
 }
+
+// GetContainerToLogOptions returns the log options of the containers that use the firelens container.
+func (firelens *FirelensResource) GetContainerToLogOptions() map[string]map[string]string {
+	return nil
+}
+
+// HotReloadEnabled returns whether the config of the firelens container can be reloaded while the task is running.
+func (firelens *FirelensResource) HotReloadEnabled() bool {
+	return false
+}
+
+// ReloadSignal returns the signal that makes the firelens container reload its config.
+func (firelens *FirelensResource) ReloadSignal() string {
+	return ""
+}
+
+// ReloadConfig regenerates the config file of the firelens container with the given log options.
+func (firelens *FirelensResource) ReloadConfig(containerToLogOptions map[string]map[string]string) error {
+	return errors.New("not implemented")
+}
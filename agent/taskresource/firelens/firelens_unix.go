@@ -17,6 +17,7 @@
 package firelens
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -39,6 +40,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/ecs-agent/ipcompatibility"
 	firelensutils "github.com/aws/amazon-ecs-agent/ecs-agent/utils/firelens"
 
+	s3manager "github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/cihub/seelog"
 	"github.com/pkg/errors"
 )
@@ -57,12 +59,25 @@ const (
 	ExternalConfigTypeOption = "config-file-type"
 	// ExternalConfigTypeS3 means the firelens container is using a config file from S3.
 	ExternalConfigTypeS3 = "s3"
-	// ExternalConfigTypeFile means the firelens container is using a config file inside the container.
+	// ExternalConfigTypeFile means the firelens container is using a config file inside the container. Unlike a config
+	// file from S3, the agent can't read it, so it's not validated before the firelens container starts.
 	ExternalConfigTypeFile = "file"
 	// externalConfigValueOption is the option that specifies the location of the external config file. When
 	// ExternalConfigTypeOption is s3, the value for this option should be an s3 arn; when ExternalConfigTypeOption is
 	// file, the value for this option should be a path to the config file inside the firelens container.
 	externalConfigValueOption = "config-file-value"
+	// hotReloadEnableOption is the option that specifies whether the config of the firelens container can be
+	// reloaded while the task is running.
+	hotReloadEnableOption = "enable-hot-reload"
+
+	// reloadSignalFluentd and reloadSignalFluentbit are the signals that make fluentd and fluent bit reload their
+	// config. Fluent bit only reloads its config on SIGHUP when hot reload is turned on in its service section.
+	reloadSignalFluentd   = "SIGUSR2"
+	reloadSignalFluentbit = "SIGHUP"
+	// fluentbitServiceSection is the header of the section of a fluent bit config file that configures the service,
+	// and fluentbitHotReloadEntry is the entry of the section that turns on hot reload.
+	fluentbitServiceSection = "[SERVICE]"
+	fluentbitHotReloadEntry = "    Hot_Reload On"
 
 	s3DownloadTimeout = 30 * time.Second
 )
@@ -78,7 +93,7 @@ type FirelensResource struct {
 	firelensConfigType     string
 	region                 string
 	ecsMetadataEnabled     bool
-	containerToLogOptions  map[string]map[string]string
+	hotReloadEnabled       bool
 	credentialsManager     credentials.Manager
 	executionCredentialsID string
 	externalConfigType     string
@@ -90,6 +105,11 @@ type FirelensResource struct {
 	containerMemoryLimit   int64
 	ipCompatibility        ipcompatibility.IPCompatibility
 
+	// containerToLogOptions is replaced when the config is reloaded. Access to it is protected by lock.
+	containerToLogOptions map[string]map[string]string
+	// reloadLock serializes the reloads of the config.
+	reloadLock sync.Mutex
+
 	// Fields for the common functionality of task resource. Access to these fields are protected by lock.
 	createdAtUnsafe     time.Time
 	desiredStatusUnsafe resourcestatus.ResourceStatus
@@ -150,6 +170,15 @@ func (firelens *FirelensResource) parseOptions(options map[string]string) error
 		firelens.ecsMetadataEnabled = true
 	}
 
+	if val, ok := options[hotReloadEnableOption]; ok {
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			seelog.Warnf("Invalid value for firelens container option %s was specified: %s. Ignoring it.", hotReloadEnableOption, val)
+		} else {
+			firelens.hotReloadEnabled = b
+		}
+	}
+
 	if externalConfigType, ok := options[ExternalConfigTypeOption]; ok {
 		if externalConfigType != ExternalConfigTypeS3 && externalConfigType != ExternalConfigTypeFile {
 			return errors.Errorf("invalid value %s is specified for option %s", externalConfigType, ExternalConfigTypeOption)
@@ -198,9 +227,25 @@ func (firelens *FirelensResource) GetECSMetadataEnabled() bool {
 
 // GetContainerToLogOptions returns a map of containers' log options.
 func (firelens *FirelensResource) GetContainerToLogOptions() map[string]map[string]string {
+	firelens.lock.RLock()
+	defer firelens.lock.RUnlock()
+
 	return firelens.containerToLogOptions
 }
 
+// HotReloadEnabled returns whether the config of the firelens container can be reloaded while the task is running.
+func (firelens *FirelensResource) HotReloadEnabled() bool {
+	return firelens.hotReloadEnabled
+}
+
+// ReloadSignal returns the signal that makes the firelens container reload its config.
+func (firelens *FirelensResource) ReloadSignal() string {
+	if firelens.firelensConfigType == FirelensConfigTypeFluentd {
+		return reloadSignalFluentd
+	}
+	return reloadSignalFluentbit
+}
+
 func (firelens *FirelensResource) GetRegion() string {
 	return firelens.region
 }
@@ -430,7 +475,14 @@ func (firelens *FirelensResource) Create() error {
 	}
 
 	if firelens.externalConfigType == ExternalConfigTypeS3 {
-		err = firelens.downloadConfigFromS3()
+		var externalConfig []byte
+		externalConfig, err = firelens.downloadConfigFromS3()
+		if err == nil {
+			err = firelens.writeConfigFile(func(file oswrapper.File) error {
+				_, err := file.Write(externalConfig)
+				return err
+			}, firelens.externalConfigFilePath())
+		}
 		if err != nil {
 			err = errors.Wrap(err, "unable to download firelens s3 config file")
 			firelens.setTerminalReason(err.Error())
@@ -492,18 +544,16 @@ func (firelens *FirelensResource) createDirectories() error {
 // generateConfigFile generates a firelens config file at $(RESOURCE_DIR)/config/fluent.conf.
 // This contains configs needed by the firelens container.
 func (firelens *FirelensResource) generateConfigFile() error {
-	config, err := firelens.generateConfig()
+	// The lock is held by ApplyTransition, which creates the resource.
+	content, err := firelens.renderConfig(firelens.containerToLogOptions)
 	if err != nil {
-		return errors.Wrap(err, "unable to generate firelens config")
+		return err
 	}
 
-	confFilePath := filepath.Join(firelens.resourceDir, "config", "fluent.conf")
+	confFilePath := firelens.configFilePath()
 	err = firelens.writeConfigFile(func(file oswrapper.File) error {
-		if firelens.firelensConfigType == FirelensConfigTypeFluentd {
-			return config.WriteFluentdConfig(file)
-		} else {
-			return config.WriteFluentBitConfig(file)
-		}
+		_, err := file.Write(content)
+		return err
 	}, confFilePath)
 	if err != nil {
 		return errors.Wrapf(err, "unable to generate firelens config file")
@@ -513,37 +563,151 @@ func (firelens *FirelensResource) generateConfigFile() error {
 	return nil
 }
 
-// downloadConfigFromS3 downloads an external config file from S3 and saves it at ${RESOURCE_DIR}/config/external.conf.
-// The generated firelens config file fluent.conf will have a reference to include this file.
-func (firelens *FirelensResource) downloadConfigFromS3() error {
+// renderConfig renders the content of the firelens config file for the given log options of the containers that use
+// the firelens container, and validates it.
+func (firelens *FirelensResource) renderConfig(containerToLogOptions map[string]map[string]string) ([]byte, error) {
+	config, err := firelens.generateConfigWithLogOptions(containerToLogOptions)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to generate firelens config")
+	}
+
+	var content bytes.Buffer
+	if firelens.firelensConfigType == FirelensConfigTypeFluentd {
+		err = config.WriteFluentdConfig(&content)
+	} else {
+		err = config.WriteFluentBitConfig(&content)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to generate firelens config")
+	}
+
+	rendered := content.Bytes()
+	if firelens.firelensConfigType == FirelensConfigTypeFluentbit && firelens.hotReloadEnabled {
+		rendered = addFluentbitServiceEntry(rendered, fluentbitHotReloadEntry)
+	}
+	if err := validateConfig(firelens.firelensConfigType, rendered); err != nil {
+		return nil, errors.Wrap(err, "invalid firelens config")
+	}
+	return rendered, nil
+}
+
+// addFluentbitServiceEntry adds an entry to the service section of a fluent bit config file, which is added at the
+// top of the file if there is none, so that the file has a single service section.
+func addFluentbitServiceEntry(content []byte, entry string) []byte {
+	lines := strings.SplitAfter(string(content), "\n")
+	for i, line := range lines {
+		if strings.EqualFold(strings.TrimSpace(line), fluentbitServiceSection) {
+			if !strings.HasSuffix(line, "\n") {
+				lines[i] += "\n"
+			}
+			return []byte(strings.Join(lines[:i+1], "") + entry + "\n" + strings.Join(lines[i+1:], ""))
+		}
+	}
+	return []byte(fluentbitServiceSection + "\n" + entry + "\n" + string(content))
+}
+
+// configFilePath returns the path of the config file generated for the firelens container.
+func (firelens *FirelensResource) configFilePath() string {
+	return filepath.Join(firelens.resourceDir, "config", "fluent.conf")
+}
+
+// externalConfigFilePath returns the path that the external config file downloaded from S3 is saved at.
+func (firelens *FirelensResource) externalConfigFilePath() string {
+	return filepath.Join(firelens.resourceDir, "config", "external.conf")
+}
+
+// downloadConfigFromS3 downloads an external config file from S3 and validates it. It is saved at
+// ${RESOURCE_DIR}/config/external.conf, and the generated firelens config file fluent.conf will have a reference to
+// include this file.
+func (firelens *FirelensResource) downloadConfigFromS3() ([]byte, error) {
 	creds, ok := firelens.credentialsManager.GetTaskCredentials(firelens.executionCredentialsID)
 	if !ok {
-		return errors.New("unable to get execution role credentials")
+		return nil, errors.New("unable to get execution role credentials")
 	}
 
 	bucket, key, err := s3.ParseS3ARN(firelens.externalConfigValue)
 	if err != nil {
-		return errors.Wrap(err, "unable to parse bucket and key from s3 arn")
+		return nil, errors.Wrap(err, "unable to parse bucket and key from s3 arn")
 	}
 
 	s3Client, err := firelens.s3ClientCreator.NewS3ManagerClient(bucket, firelens.region, creds.GetIAMRoleCredentials(), firelens.ipCompatibility)
 	if err != nil {
-		return errors.Wrapf(err, "unable to initialize s3 client for bucket %s", bucket)
+		return nil, errors.Wrapf(err, "unable to initialize s3 client for bucket %s", bucket)
 	}
 
-	confFilePath := filepath.Join(firelens.resourceDir, "config", "external.conf")
-	err = firelens.writeConfigFile(func(file oswrapper.File) error {
-		return s3.DownloadFile(bucket, key, s3DownloadTimeout, file, s3Client)
-	}, confFilePath)
+	buffer := s3manager.NewWriteAtBuffer([]byte{})
+	if err := s3.DownloadFile(bucket, key, s3DownloadTimeout, buffer, s3Client); err != nil {
+		return nil, errors.Wrapf(err, "unable to download s3 config %s from bucket %s", key, bucket)
+	}
+	if err := validateConfig(firelens.firelensConfigType, buffer.Bytes()); err != nil {
+		return nil, errors.Wrapf(err, "invalid s3 config %s from bucket %s", key, bucket)
+	}
 
+	seelog.Debugf("Downloaded firelens config file from s3 bucket %s: %s", bucket, key)
+	return buffer.Bytes(), nil
+}
+
+// ReloadConfig regenerates the config file of the firelens container with new log options of the containers that
+// use it, and downloads the external config file from S3 again if there is one. The config files are validated before
+// any of them is replaced, so an invalid config leaves the current config in place. The firelens container loads the
+// new config once it is sent the ReloadSignal.
+func (firelens *FirelensResource) ReloadConfig(containerToLogOptions map[string]map[string]string) error {
+	if !firelens.hotReloadEnabled {
+		return errors.Errorf("firelens option %s is not enabled", hotReloadEnableOption)
+	}
+
+	firelens.reloadLock.Lock()
+	defer firelens.reloadLock.Unlock()
+
+	var externalConfig []byte
+	if firelens.externalConfigType == ExternalConfigTypeS3 {
+		var err error
+		externalConfig, err = firelens.downloadConfigFromS3()
+		if err != nil {
+			return errors.Wrap(err, "unable to download firelens s3 config file")
+		}
+	}
+	content, err := firelens.renderConfig(containerToLogOptions)
 	if err != nil {
-		return errors.Wrapf(err, "unable to download s3 config %s from bucket %s", key, bucket)
+		return err
+	}
+
+	if externalConfig != nil {
+		if err := rewriteConfigFile(firelens.externalConfigFilePath(), externalConfig); err != nil {
+			return errors.Wrap(err, "unable to rewrite firelens s3 config file")
+		}
+	}
+	if err := rewriteConfigFile(firelens.configFilePath(), content); err != nil {
+		return errors.Wrap(err, "unable to rewrite firelens config file")
 	}
 
-	seelog.Debugf("Downloaded firelens config file from s3 and saved to: %s", confFilePath)
+	firelens.lock.Lock()
+	firelens.containerToLogOptions = containerToLogOptions
+	firelens.lock.Unlock()
+
+	seelog.Infof("Regenerated firelens config file at: %s", firelens.configFilePath())
 	return nil
 }
 
+var openFile = func(name string, flag int, perm os.FileMode) (oswrapper.File, error) {
+	return os.OpenFile(name, flag, perm)
+}
+
+// rewriteConfigFile replaces the content of a config file in place. The config files are bind mounted to the
+// firelens container, which would keep seeing the previous file if it was replaced by another one.
+func rewriteConfigFile(filePath string, content []byte) error {
+	file, err := openFile(filePath, os.O_WRONLY|os.O_TRUNC, os.FileMode(configFilePerm))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := file.Write(content); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
 var rename = os.Rename
 
 // writeConfigFile writes a config file at a given path.
@@ -22,6 +22,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/aws/amazon-ecs-agent/agent/config"
@@ -141,6 +142,14 @@ func TestParseOptions(t *testing.T) {
 	assert.Equal(t, true, firelensResource.ecsMetadataEnabled)
 	assert.Equal(t, "file", firelensResource.externalConfigType)
 	assert.Equal(t, "/tmp/dummy.conf", firelensResource.externalConfigValue)
+	assert.False(t, firelensResource.HotReloadEnabled())
+}
+
+func TestParseOptionsHotReload(t *testing.T) {
+	firelensResource := FirelensResource{}
+	err := firelensResource.parseOptions(map[string]string{"enable-hot-reload": "true"})
+	assert.NoError(t, err)
+	assert.True(t, firelensResource.HotReloadEnabled())
 }
 
 func TestParseOptionsInvalidType(t *testing.T) {
@@ -374,13 +383,13 @@ func TestCreateFirelensResourceWithS3Config(t *testing.T) {
 	gomock.InOrder(
 		mockCredentialsManager.EXPECT().GetTaskCredentials(testExecutionCredentialsID).Return(creds, true),
 		mockS3ClientCreator.EXPECT().NewS3ManagerClient("bucket", testRegion, creds.IAMRoleCredentials, testIPCompatibility).Return(mockS3Client, nil),
-		// write external config file downloaded from s3
-		mockIOUtil.EXPECT().TempFile(testResourceDir, tempFile).Return(mockFile, nil),
-		mockS3Client.EXPECT().Download(gomock.Any(), mockFile, gomock.Any(), gomock.Any()).Do(
+		mockS3Client.EXPECT().Download(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
 			func(ctx context.Context, w io.WriterAt, input *s3.GetObjectInput, options ...func(*s3manager.Downloader)) {
 				assert.Equal(t, "bucket", aws.ToString(input.Bucket))
 				assert.Equal(t, "key", aws.ToString(input.Key))
 			}).Return(int64(0), nil),
+		// write external config file downloaded from s3
+		mockIOUtil.EXPECT().TempFile(testResourceDir, tempFile).Return(mockFile, nil),
 
 		// write main config file
 		mockIOUtil.EXPECT().TempFile(testResourceDir, tempFile).Return(mockFile, nil),
@@ -427,7 +436,7 @@ func TestCreateFirelensResourceWithS3ConfigInvalidS3ARN(t *testing.T) {
 }
 
 func TestCreateFirelensResourceWithS3ConfigDownloadFailure(t *testing.T) {
-	mockFile, mockIOUtil, mockCredentialsManager, mockS3ClientCreator, mockS3Client, done := setup(t)
+	_, mockIOUtil, mockCredentialsManager, mockS3ClientCreator, mockS3Client, done := setup(t)
 	defer done()
 
 	firelensResource := newMockFirelensResource(FirelensConfigTypeFluentd, bridgeNetworkMode, testFluentdOptions, mockIOUtil,
@@ -446,14 +455,45 @@ func TestCreateFirelensResourceWithS3ConfigDownloadFailure(t *testing.T) {
 	gomock.InOrder(
 		mockCredentialsManager.EXPECT().GetTaskCredentials(testExecutionCredentialsID).Return(creds, true),
 		mockS3ClientCreator.EXPECT().NewS3ManagerClient("bucket", testRegion, creds.IAMRoleCredentials, testIPCompatibility).Return(mockS3Client, nil),
-		mockIOUtil.EXPECT().TempFile(testResourceDir, tempFile).Return(mockFile, nil),
-		mockS3Client.EXPECT().Download(gomock.Any(), mockFile, gomock.Any()).Return(int64(0), errors.New("test error")),
+		mockS3Client.EXPECT().Download(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(0), errors.New("test error")),
 	)
 
 	assert.Error(t, firelensResource.Create())
 	assert.NotEmpty(t, firelensResource.terminalReason)
 }
 
+func TestCreateFirelensResourceWithInvalidS3Config(t *testing.T) {
+	_, mockIOUtil, mockCredentialsManager, mockS3ClientCreator, mockS3Client, done := setup(t)
+	defer done()
+
+	firelensResource := newMockFirelensResource(FirelensConfigTypeFluentbit, bridgeNetworkMode, testFluentbitOptions, mockIOUtil,
+		mockCredentialsManager, mockS3ClientCreator, testContainerMemoryLimit, testIPCompatibility)
+
+	err := firelensResource.parseOptions(testFirelensOptionsS3)
+	require.NoError(t, err)
+
+	creds := credentials.TaskIAMRoleCredentials{
+		ARN: "arn",
+		IAMRoleCredentials: credentials.IAMRoleCredentials{
+			AccessKeyID:     "id",
+			SecretAccessKey: "key",
+		},
+	}
+	gomock.InOrder(
+		mockCredentialsManager.EXPECT().GetTaskCredentials(testExecutionCredentialsID).Return(creds, true),
+		mockS3ClientCreator.EXPECT().NewS3ManagerClient("bucket", testRegion, creds.IAMRoleCredentials, testIPCompatibility).Return(mockS3Client, nil),
+		mockS3Client.EXPECT().Download(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, w io.WriterAt, input *s3.GetObjectInput, options ...func(*s3manager.Downloader)) (int64, error) {
+				content := []byte("[OUTPUT]\n    Match *\n")
+				_, err := w.WriteAt(content, 0)
+				return int64(len(content)), err
+			}),
+	)
+
+	assert.Error(t, firelensResource.Create())
+	assert.Contains(t, firelensResource.terminalReason, "line 1: [OUTPUT] section has no Name entry")
+}
+
 func TestCleanupFirelensResource(t *testing.T) {
 	_, mockIOUtil, mockCredentialsManager, mockS3ClientCreator, _, done := setup(t)
 	defer done()
@@ -657,3 +697,103 @@ func TestCreateDirectories(t *testing.T) {
 		})
 	}
 }
+
+func TestReloadConfig(t *testing.T) {
+	firelensResource := newMockFirelensResource(FirelensConfigTypeFluentbit, bridgeNetworkMode, testFluentbitOptions,
+		nil, nil, nil, testContainerMemoryLimit, testIPCompatibility)
+	firelensResource.resourceDir = t.TempDir()
+	firelensResource.hotReloadEnabled = true
+
+	configPath := filepath.Join(firelensResource.resourceDir, "config", "fluent.conf")
+	require.NoError(t, os.MkdirAll(filepath.Dir(configPath), os.ModePerm))
+	require.NoError(t, os.WriteFile(configPath, []byte("previous config"), configFilePerm))
+	previousInfo, err := os.Stat(configPath)
+	require.NoError(t, err)
+
+	newLogOptions := map[string]map[string]string{
+		"container": {
+			"Name":   "cloudwatch",
+			"region": "us-east-1",
+		},
+	}
+	require.NoError(t, firelensResource.ReloadConfig(newLogOptions))
+
+	content, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(content), "[SERVICE]\n    Hot_Reload On\n"))
+	assert.Equal(t, 1, strings.Count(string(content), "[SERVICE]"))
+	assert.Contains(t, string(content), "region us-east-1")
+	assert.Equal(t, newLogOptions, firelensResource.GetContainerToLogOptions())
+
+	// The file is rewritten in place, since it's bind mounted to the firelens container.
+	info, err := os.Stat(configPath)
+	require.NoError(t, err)
+	assert.True(t, os.SameFile(previousInfo, info))
+}
+
+func TestReloadConfigInvalidLogOptions(t *testing.T) {
+	firelensResource := newMockFirelensResource(FirelensConfigTypeFluentbit, bridgeNetworkMode, testFluentbitOptions,
+		nil, nil, nil, testContainerMemoryLimit, testIPCompatibility)
+	firelensResource.resourceDir = t.TempDir()
+	firelensResource.hotReloadEnabled = true
+
+	configPath := filepath.Join(firelensResource.resourceDir, "config", "fluent.conf")
+	require.NoError(t, os.MkdirAll(filepath.Dir(configPath), os.ModePerm))
+	require.NoError(t, os.WriteFile(configPath, []byte("previous config"), configFilePerm))
+
+	err := firelensResource.ReloadConfig(map[string]map[string]string{
+		"container": {
+			"Name":   "cloudwatch",
+			"region": "",
+		},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `entry "region" of [OUTPUT] section has no value`)
+
+	content, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "previous config", string(content))
+	assert.Equal(t, map[string]map[string]string{"container": testFluentbitOptions},
+		firelensResource.GetContainerToLogOptions())
+}
+
+func TestReloadConfigHotReloadNotEnabled(t *testing.T) {
+	firelensResource := newMockFirelensResource(FirelensConfigTypeFluentd, bridgeNetworkMode, testFluentdOptions,
+		nil, nil, nil, testContainerMemoryLimit, testIPCompatibility)
+
+	assert.Error(t, firelensResource.ReloadConfig(nil))
+}
+
+func TestReloadSignal(t *testing.T) {
+	fluentd := newMockFirelensResource(FirelensConfigTypeFluentd, bridgeNetworkMode, testFluentdOptions,
+		nil, nil, nil, testContainerMemoryLimit, testIPCompatibility)
+	assert.Equal(t, "SIGUSR2", fluentd.ReloadSignal())
+
+	fluentbit := newMockFirelensResource(FirelensConfigTypeFluentbit, bridgeNetworkMode, testFluentbitOptions,
+		nil, nil, nil, testContainerMemoryLimit, testIPCompatibility)
+	assert.Equal(t, "SIGHUP", fluentbit.ReloadSignal())
+}
+
+func TestAddFluentbitServiceEntry(t *testing.T) {
+	for name, tc := range map[string]struct {
+		content  string
+		expected string
+	}{
+		"no service section": {
+			content:  "[INPUT]\n    Name forward\n",
+			expected: "[SERVICE]\n    Hot_Reload On\n[INPUT]\n    Name forward\n",
+		},
+		"service section": {
+			content:  "[INPUT]\n    Name forward\n[SERVICE]\n    Flush 1\n",
+			expected: "[INPUT]\n    Name forward\n[SERVICE]\n    Hot_Reload On\n    Flush 1\n",
+		},
+		"service section at end of file": {
+			content:  "[Service]",
+			expected: "[Service]\n    Hot_Reload On\n",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, string(addFluentbitServiceEntry([]byte(tc.content), fluentbitHotReloadEntry)))
+		})
+	}
+}
@@ -122,6 +122,13 @@ const (
 // generateConfig generates a FluentConfig object that contains all necessary information to construct
 // a fluentd or fluentbit config file for a firelens container.
 func (firelens *FirelensResource) generateConfig() (generator.FluentConfig, error) {
+	return firelens.generateConfigWithLogOptions(firelens.containerToLogOptions)
+}
+
+// generateConfigWithLogOptions generates a FluentConfig object for the given log options of the containers that use
+// the firelens container.
+func (firelens *FirelensResource) generateConfigWithLogOptions(
+	containerToLogOptions map[string]map[string]string) (generator.FluentConfig, error) {
 	config := generator.New()
 	var defaultInputMap map[string]string
 	// Specify log stream input, which is a unix socket that will be used for communication between the Firelens
@@ -183,7 +190,7 @@ func (firelens *FirelensResource) generateConfig() (generator.FluentConfig, erro
 
 	// Specify log stream output. Each container that uses the firelens container to stream logs
 	// may have its own output section with options, constructed from container's log options.
-	for containerName, logOptions := range firelens.containerToLogOptions {
+	for containerName, logOptions := range containerToLogOptions {
 		tag := fmt.Sprintf(fluentTagOutputFormat, containerName, matchAnyWildcard) // Each output section is distinguished by a tag specific to a container.
 		newConfig, err := addOutputSection(tag, firelens.firelensConfigType, logOptions, config)
 		if err != nil {
@@ -34,6 +34,7 @@ type firelensResourceJSON struct {
 	FirelensConfigType     string
 	Region                 string
 	ECSMetadataEnabled     bool
+	HotReloadEnabled       bool
 	ContainersToLogOptions map[string]map[string]string
 	ExecutionCredentialsID string
 	ExternalConfigType     string
@@ -65,6 +66,7 @@ func (firelens *FirelensResource) MarshalJSON() ([]byte, error) {
 		FirelensConfigType:     firelens.firelensConfigType,
 		Region:                 firelens.region,
 		ECSMetadataEnabled:     firelens.ecsMetadataEnabled,
+		HotReloadEnabled:       firelens.hotReloadEnabled,
 		ContainersToLogOptions: firelens.containerToLogOptions,
 		ExecutionCredentialsID: firelens.executionCredentialsID,
 		ExternalConfigType:     firelens.externalConfigType,
@@ -112,6 +114,7 @@ func (firelens *FirelensResource) UnmarshalJSON(b []byte) error {
 	firelens.firelensConfigType = temp.FirelensConfigType
 	firelens.region = temp.Region
 	firelens.ecsMetadataEnabled = temp.ECSMetadataEnabled
+	firelens.hotReloadEnabled = temp.HotReloadEnabled
 	firelens.containerToLogOptions = temp.ContainersToLogOptions
 	firelens.executionCredentialsID = temp.ExecutionCredentialsID
 	firelens.externalConfigType = temp.ExternalConfigType
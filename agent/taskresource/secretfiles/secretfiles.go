@@ -0,0 +1,464 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package secretfiles
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource"
+	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	resourceProvisioningError = "SecretFilesError: Agent could not create the task's secret files"
+
+	// secretFilesDir is the directory under the agent data directory that holds the secret files of
+	// the tasks. It is a tmpfs that ecs-init mounts before the agent starts, so that secret values
+	// never reach the disk.
+	secretFilesDir = "secretfiles"
+	// hostDataDirFormat is the format of the path on the host of the agent data directory, given the
+	// DataDirOnHost of the agent config.
+	hostDataDirFormat = "%s/data"
+	// defaultFileMode only lets the owner of the file read it.
+	defaultFileMode = 0400
+	// dirMode lets the container users traverse to the files they own without listing the files
+	// of the other containers of the task.
+	dirMode = 0711
+)
+
+// File is a secret of a container that is written to the secretfiles resource of the task.
+type File struct {
+	// Name is the name of the file on the host, which is unique among the files of the container.
+	Name string
+	// ContainerPath is the absolute path of the file in the container.
+	ContainerPath string
+	Mode          os.FileMode
+	UID           int
+	GID           int
+	Value         string
+}
+
+// FileFromSecret returns the file of a MOUNT_POINT secret of the container, without its value. The
+// file is named after the index of the secret in the secrets of the container.
+func FileFromSecret(index int, secret apicontainer.Secret, containerUser string) (File, error) {
+	file := File{
+		Name:          strconv.Itoa(index),
+		ContainerPath: secret.ContainerPath,
+		Mode:          defaultFileMode,
+	}
+	if !filepath.IsAbs(file.ContainerPath) {
+		return File{}, errors.Errorf("secret %s: container path %q is not absolute", secret.Name, secret.ContainerPath)
+	}
+	file.ContainerPath = filepath.Clean(file.ContainerPath)
+
+	if secret.FileMode != "" {
+		mode, err := strconv.ParseUint(secret.FileMode, 8, 32)
+		if err != nil || mode > 0777 {
+			return File{}, errors.Errorf("secret %s: invalid file mode %q", secret.Name, secret.FileMode)
+		}
+		file.Mode = os.FileMode(mode)
+	}
+
+	file.UID, file.GID = ownerFromUser(containerUser)
+	if secret.UID != nil {
+		file.UID = int(*secret.UID)
+	}
+	if secret.GID != nil {
+		file.GID = int(*secret.GID)
+	}
+	if file.UID < 0 || file.GID < 0 {
+		return File{}, errors.Errorf("secret %s: file owner must not be negative", secret.Name)
+	}
+	return file, nil
+}
+
+// ownerFromUser returns the uid and gid of a container user of the form uid or uid:gid, and root
+// for user names, which cannot be resolved outside of the container.
+func ownerFromUser(user string) (int, int) {
+	uidPart, gidPart, hasGID := strings.Cut(user, ":")
+	uid, err := strconv.Atoi(uidPart)
+	if err != nil {
+		return 0, 0
+	}
+	if !hasGID {
+		return uid, uid
+	}
+	gid, err := strconv.Atoi(gidPart)
+	if err != nil {
+		return uid, 0
+	}
+	return uid, gid
+}
+
+// SecretFilesResource is a directory of the secret files tmpfs holding the secrets of the containers
+// of a task that are mounted as files, so that secret values never reach the disk. The directory is
+// removed along with the files when the task stops.
+type SecretFilesResource struct {
+	taskARN string
+	// localPath is the path of the directory as seen by the agent, which differs from hostPath when
+	// the agent runs in a container.
+	localPath string
+	hostPath  string
+
+	// Fields for the common functionality of task resource. Access to these fields are protected by lock.
+	createdAtUnsafe     time.Time
+	knownStatusUnsafe   resourcestatus.ResourceStatus
+	desiredStatusUnsafe resourcestatus.ResourceStatus
+	appliedStatusUnsafe resourcestatus.ResourceStatus
+	statusToTransitions map[resourcestatus.ResourceStatus]func() error
+	terminalReason      string
+	terminalReasonOnce  sync.Once
+	lock                sync.RWMutex
+}
+
+// NewSecretFilesResource creates a new SecretFilesResource object. The directory is created in the
+// secret files tmpfs under the given data directory, which is bound from the data directory under
+// dataDirOnHost on the host.
+func NewSecretFilesResource(taskARN string, taskID string, dataDir string, dataDirOnHost string) *SecretFilesResource {
+	sf := &SecretFilesResource{
+		taskARN:   taskARN,
+		localPath: filepath.Join(dataDir, secretFilesDir, taskID),
+		hostPath:  filepath.Join(fmt.Sprintf(hostDataDirFormat, dataDirOnHost), secretFilesDir, taskID),
+	}
+	sf.initStatusToTransition()
+	return sf
+}
+
+func (sf *SecretFilesResource) Initialize(
+	config *config.Config,
+	resourceFields *taskresource.ResourceFields,
+	taskKnownStatus status.TaskStatus,
+	taskDesiredStatus status.TaskStatus) {
+
+	sf.initStatusToTransition()
+}
+
+func (sf *SecretFilesResource) initStatusToTransition() {
+	statusToTransitions := map[resourcestatus.ResourceStatus]func() error{
+		resourcestatus.ResourceStatus(SecretFilesCreated): sf.Create,
+	}
+
+	sf.statusToTransitions = statusToTransitions
+}
+
+// DesiredTerminal returns true if the secretfiles's desired status is REMOVED
+func (sf *SecretFilesResource) DesiredTerminal() bool {
+	sf.lock.RLock()
+	defer sf.lock.RUnlock()
+
+	return sf.desiredStatusUnsafe == resourcestatus.ResourceStatus(SecretFilesRemoved)
+}
+
+// GetTerminalReason returns an error string to propagate up through to task
+// state change messages
+func (sf *SecretFilesResource) GetTerminalReason() string {
+	if sf.terminalReason == "" {
+		return resourceProvisioningError
+	}
+	return sf.terminalReason
+}
+
+func (sf *SecretFilesResource) setTerminalReason(reason string) {
+	sf.terminalReasonOnce.Do(func() {
+		logger.Debug("Setting terminal reason for secretfiles resource", logger.Fields{
+			field.TaskARN: sf.taskARN,
+			field.Reason:  reason,
+		})
+		sf.terminalReason = reason
+	})
+}
+
+// GetDesiredStatus safely returns the desired status of the task
+func (sf *SecretFilesResource) GetDesiredStatus() resourcestatus.ResourceStatus {
+	sf.lock.RLock()
+	defer sf.lock.RUnlock()
+
+	return sf.desiredStatusUnsafe
+}
+
+// SetDesiredStatus safely sets the desired status of the resource
+func (sf *SecretFilesResource) SetDesiredStatus(status resourcestatus.ResourceStatus) {
+	sf.lock.Lock()
+	defer sf.lock.Unlock()
+
+	sf.desiredStatusUnsafe = status
+}
+
+// GetKnownStatus safely returns the currently known status of the task
+func (sf *SecretFilesResource) GetKnownStatus() resourcestatus.ResourceStatus {
+	sf.lock.RLock()
+	defer sf.lock.RUnlock()
+
+	return sf.knownStatusUnsafe
+}
+
+// SetKnownStatus safely sets the currently known status of the resource
+func (sf *SecretFilesResource) SetKnownStatus(status resourcestatus.ResourceStatus) {
+	sf.lock.Lock()
+	defer sf.lock.Unlock()
+
+	sf.knownStatusUnsafe = status
+	sf.updateAppliedStatusUnsafe(status)
+}
+
+// KnownCreated returns true if the secretfiles's known status is CREATED
+func (sf *SecretFilesResource) KnownCreated() bool {
+	sf.lock.RLock()
+	defer sf.lock.RUnlock()
+
+	return sf.knownStatusUnsafe == resourcestatus.ResourceStatus(SecretFilesCreated)
+}
+
+// TerminalStatus returns the last transition state of secretfiles
+func (sf *SecretFilesResource) TerminalStatus() resourcestatus.ResourceStatus {
+	return resourcestatus.ResourceStatus(SecretFilesRemoved)
+}
+
+// NextKnownState returns the state that the resource should
+// progress to based on its `KnownState`.
+func (sf *SecretFilesResource) NextKnownState() resourcestatus.ResourceStatus {
+	return sf.GetKnownStatus() + 1
+}
+
+// SteadyState returns the transition state of the resource defined as "ready"
+func (sf *SecretFilesResource) SteadyState() resourcestatus.ResourceStatus {
+	return resourcestatus.ResourceStatus(SecretFilesCreated)
+}
+
+// ApplyTransition calls the function required to move to the specified status
+func (sf *SecretFilesResource) ApplyTransition(nextState resourcestatus.ResourceStatus) error {
+	transitionFunc, ok := sf.statusToTransitions[nextState]
+	if !ok {
+		err := errors.Errorf("resource [%s]: transition to %s impossible", ResourceName,
+			sf.StatusString(nextState))
+		sf.setTerminalReason(err.Error())
+		return err
+	}
+
+	return transitionFunc()
+}
+
+// SetAppliedStatus sets the applied status of resource and returns whether
+// the resource is already in a transition
+func (sf *SecretFilesResource) SetAppliedStatus(status resourcestatus.ResourceStatus) bool {
+	sf.lock.Lock()
+	defer sf.lock.Unlock()
+
+	if sf.appliedStatusUnsafe != resourcestatus.ResourceStatus(SecretFilesStatusNone) {
+		// return false to indicate the set operation failed
+		return false
+	}
+
+	sf.appliedStatusUnsafe = status
+	return true
+}
+
+// StatusString returns the string of the secretfiles resource status
+func (sf *SecretFilesResource) StatusString(status resourcestatus.ResourceStatus) string {
+	return SecretFilesStatus(status).String()
+}
+
+// GetCreatedAt gets the timestamp for resource's creation time
+func (sf *SecretFilesResource) GetCreatedAt() time.Time {
+	sf.lock.RLock()
+	defer sf.lock.RUnlock()
+
+	return sf.createdAtUnsafe
+}
+
+// SetCreatedAt sets the timestamp for resource's creation time
+func (sf *SecretFilesResource) SetCreatedAt(createdAt time.Time) {
+	if createdAt.IsZero() {
+		return
+	}
+	sf.lock.Lock()
+	defer sf.lock.Unlock()
+
+	sf.createdAtUnsafe = createdAt
+}
+
+// GetName returns the name of the secretfiles resource
+func (sf *SecretFilesResource) GetName() string {
+	return ResourceName
+}
+
+// Create creates the directory that holds the secret files.
+func (sf *SecretFilesResource) Create() error {
+	if err := sf.createDir(); err != nil {
+		err = errors.Wrap(err, "secret files")
+		sf.setTerminalReason(err.Error())
+		return err
+	}
+	return nil
+}
+
+// Cleanup removes the secret files along with the directory that holds them.
+func (sf *SecretFilesResource) Cleanup() error {
+	if err := sf.removeDir(); err != nil {
+		return errors.Wrap(err, "secret files")
+	}
+	return nil
+}
+
+// WriteContainerFiles writes the secret files of the container and returns the binds that mount
+// them read-only into the container. Files are written again if they already exist, so that the
+// container can be recreated.
+func (sf *SecretFilesResource) WriteContainerFiles(containerName string, files []File) ([]string, error) {
+	dir := filepath.Join(sf.localPath, containerName)
+	if err := os.MkdirAll(dir, dirMode); err != nil {
+		return nil, errors.Wrapf(err, "secret files: unable to create the directory of container %s", containerName)
+	}
+	// MkdirAll is subject to the umask of the agent.
+	if err := os.Chmod(dir, dirMode); err != nil {
+		return nil, errors.Wrapf(err, "secret files: unable to set the mode of the directory of container %s",
+			containerName)
+	}
+
+	binds := make([]string, 0, len(files))
+	for _, file := range files {
+		if err := writeFile(filepath.Join(dir, file.Name), file); err != nil {
+			return nil, errors.Wrapf(err, "secret files: unable to write the file %s of container %s",
+				file.ContainerPath, containerName)
+		}
+		binds = append(binds, filepath.Join(sf.hostPath, containerName, file.Name)+":"+file.ContainerPath+":ro")
+	}
+	return binds, nil
+}
+
+// writeFile writes the value of the file, which is only readable by the agent until it is handed
+// over to its owner.
+func writeFile(path string, file File) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(file.Value); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chown(path, file.UID, file.GID); err != nil {
+		return err
+	}
+	return os.Chmod(path, file.Mode)
+}
+
+// SecretFilesResourceJSON is the json representation of the secretfiles resource
+type SecretFilesResourceJSON struct {
+	TaskARN       string             `json:"taskARN"`
+	LocalPath     string             `json:"localPath"`
+	HostPath      string             `json:"hostPath"`
+	CreatedAt     *time.Time         `json:"createdAt,omitempty"`
+	DesiredStatus *SecretFilesStatus `json:"desiredStatus"`
+	KnownStatus   *SecretFilesStatus `json:"knownStatus"`
+}
+
+// MarshalJSON serialises the SecretFilesResourceJSON struct to JSON
+func (sf *SecretFilesResource) MarshalJSON() ([]byte, error) {
+	if sf == nil {
+		return nil, errors.New("secretfiles resource is nil")
+	}
+	createdAt := sf.GetCreatedAt()
+	return json.Marshal(SecretFilesResourceJSON{
+		TaskARN:   sf.taskARN,
+		LocalPath: sf.localPath,
+		HostPath:  sf.hostPath,
+		CreatedAt: &createdAt,
+		DesiredStatus: func() *SecretFilesStatus {
+			desiredState := sf.GetDesiredStatus()
+			s := SecretFilesStatus(desiredState)
+			return &s
+		}(),
+		KnownStatus: func() *SecretFilesStatus {
+			knownState := sf.GetKnownStatus()
+			s := SecretFilesStatus(knownState)
+			return &s
+		}(),
+	})
+}
+
+// UnmarshalJSON deserialises the raw JSON to a SecretFilesResourceJSON struct
+func (sf *SecretFilesResource) UnmarshalJSON(b []byte) error {
+	temp := SecretFilesResourceJSON{}
+
+	if err := json.Unmarshal(b, &temp); err != nil {
+		return err
+	}
+
+	sf.taskARN = temp.TaskARN
+	sf.localPath = temp.LocalPath
+	sf.hostPath = temp.HostPath
+	if temp.DesiredStatus != nil {
+		sf.SetDesiredStatus(resourcestatus.ResourceStatus(*temp.DesiredStatus))
+	}
+	if temp.KnownStatus != nil {
+		sf.SetKnownStatus(resourcestatus.ResourceStatus(*temp.KnownStatus))
+	}
+	if temp.CreatedAt != nil && !temp.CreatedAt.IsZero() {
+		sf.SetCreatedAt(*temp.CreatedAt)
+	}
+	return nil
+}
+
+// updateAppliedStatusUnsafe updates the resource transitioning status
+func (sf *SecretFilesResource) updateAppliedStatusUnsafe(knownStatus resourcestatus.ResourceStatus) {
+	if sf.appliedStatusUnsafe == resourcestatus.ResourceStatus(SecretFilesStatusNone) {
+		return
+	}
+
+	// Check if the resource transition has already finished
+	if sf.appliedStatusUnsafe <= knownStatus {
+		sf.appliedStatusUnsafe = resourcestatus.ResourceStatus(SecretFilesStatusNone)
+	}
+}
+
+// GetAppliedStatus safely returns the currently applied status of the resource
+func (sf *SecretFilesResource) GetAppliedStatus() resourcestatus.ResourceStatus {
+	sf.lock.RLock()
+	defer sf.lock.RUnlock()
+
+	return sf.appliedStatusUnsafe
+}
+
+func (sf *SecretFilesResource) DependOnTaskNetwork() bool {
+	return false
+}
+
+// BuildContainerDependency sets the container dependencies of the resource.
+func (sf *SecretFilesResource) BuildContainerDependency(containerName string, satisfied apicontainerstatus.ContainerStatus,
+	dependent resourcestatus.ResourceStatus) {
+	return
+}
+
+// GetContainerDependencies returns the container dependencies of the resource.
+func (sf *SecretFilesResource) GetContainerDependencies(dependent resourcestatus.ResourceStatus) []apicontainer.ContainerDependency {
+	return nil
+}
@@ -0,0 +1,56 @@
+//go:build linux
+// +build linux
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package secretfiles
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+var statfs = unix.Statfs
+
+// createDir creates the directory of the task in the secret files tmpfs. The secret files are not
+// written unless the directory is on a tmpfs, which ecs-init mounts on the host under the data
+// directory before the agent starts, and which has to be mounted when the agent runs otherwise.
+func (sf *SecretFilesResource) createDir() error {
+	tmpfsPath := filepath.Dir(sf.localPath)
+	var stat unix.Statfs_t
+	if err := statfs(tmpfsPath, &stat); err != nil {
+		return errors.Wrap(err, "unable to look up the secret files tmpfs")
+	}
+	if stat.Type != unix.TMPFS_MAGIC {
+		return errors.Errorf("%s is not a tmpfs", tmpfsPath)
+	}
+	if err := os.MkdirAll(sf.localPath, dirMode); err != nil {
+		return errors.Wrap(err, "unable to create the secret files directory")
+	}
+	// MkdirAll is subject to the umask of the agent.
+	if err := os.Chmod(sf.localPath, dirMode); err != nil {
+		return errors.Wrap(err, "unable to set the mode of the secret files directory")
+	}
+	return nil
+}
+
+func (sf *SecretFilesResource) removeDir() error {
+	if err := os.RemoveAll(sf.localPath); err != nil {
+		return errors.Wrap(err, "unable to remove the secret files directory")
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+//go:build linux && unit
+// +build linux,unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package secretfiles
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	taskARN = "arn:aws:ecs:us-west-2:123456789012:task/test-cluster/abc"
+	taskID  = "abc"
+)
+
+func TestNewSecretFilesResource(t *testing.T) {
+	sf := NewSecretFilesResource(taskARN, taskID, "/data", "/var/lib/ecs")
+	assert.Equal(t, "/data/secretfiles/abc", sf.localPath)
+	assert.Equal(t, "/var/lib/ecs/data/secretfiles/abc", sf.hostPath)
+}
+
+// newTestSecretFilesResource returns a resource whose secret files tmpfs stands in a temporary
+// directory.
+func newTestSecretFilesResource(t *testing.T) *SecretFilesResource {
+	sf := NewSecretFilesResource(taskARN, taskID, t.TempDir(), "/var/lib/ecs")
+	require.NoError(t, os.Mkdir(filepath.Dir(sf.localPath), dirMode))
+	return sf
+}
+
+func TestCreateAndCleanupDir(t *testing.T) {
+	sf := newTestSecretFilesResource(t)
+
+	defer func() {
+		statfs = unix.Statfs
+	}()
+	statfs = func(path string, buf *unix.Statfs_t) error {
+		assert.Equal(t, filepath.Dir(sf.localPath), path)
+		buf.Type = unix.TMPFS_MAGIC
+		return nil
+	}
+
+	require.NoError(t, sf.ApplyTransition(resourcestatus.ResourceStatus(SecretFilesCreated)))
+	info, err := os.Stat(sf.localPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(dirMode), info.Mode().Perm())
+
+	require.NoError(t, sf.Cleanup())
+	_, err = os.Stat(sf.localPath)
+	assert.True(t, os.IsNotExist(err))
+	// The tmpfs is shared with the other tasks.
+	assert.DirExists(t, filepath.Dir(sf.localPath))
+}
+
+func TestCreateDirNotTmpfs(t *testing.T) {
+	sf := newTestSecretFilesResource(t)
+
+	defer func() {
+		statfs = unix.Statfs
+	}()
+	statfs = func(path string, buf *unix.Statfs_t) error {
+		buf.Type = unix.EXT4_SUPER_MAGIC
+		return nil
+	}
+
+	assert.Error(t, sf.Create())
+	assert.Contains(t, sf.GetTerminalReason(), "is not a tmpfs")
+	_, err := os.Stat(sf.localPath)
+	assert.True(t, os.IsNotExist(err))
+
+	// The cleanup of the resource succeeds as the directory is not created.
+	assert.NoError(t, sf.Cleanup())
+}
+
+func TestCreateDirNoTmpfs(t *testing.T) {
+	sf := NewSecretFilesResource(taskARN, taskID, t.TempDir(), "/var/lib/ecs")
+
+	assert.Error(t, sf.Create())
+	assert.Contains(t, sf.GetTerminalReason(), "unable to look up the secret files tmpfs")
+}
+
+func TestWriteContainerFiles(t *testing.T) {
+	sf := NewSecretFilesResource(taskARN, taskID, "/data", "/var/lib/ecs")
+	sf.localPath = t.TempDir()
+	file := File{
+		Name:          "0",
+		ContainerPath: "/run/secrets/password",
+		Mode:          0440,
+		UID:           os.Getuid(),
+		GID:           os.Getgid(),
+		Value:         "hunter2",
+	}
+
+	// Writing the files again, e.g. when the container is recreated, replaces their values.
+	for _, value := range []string{"a longer previous value", file.Value} {
+		file.Value = value
+		binds, err := sf.WriteContainerFiles("app", []File{file})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"/var/lib/ecs/data/secretfiles/abc/app/0:/run/secrets/password:ro"}, binds)
+	}
+
+	path := filepath.Join(sf.localPath, "app", "0")
+	value, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", string(value))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0440), info.Mode().Perm())
+	info, err = os.Stat(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(dirMode), info.Mode().Perm())
+}
+
+func TestMarshalUnmarshalJSON(t *testing.T) {
+	sf := NewSecretFilesResource(taskARN, taskID, "/data", "/var/lib/ecs")
+	sf.SetDesiredStatus(resourcestatus.ResourceStatus(SecretFilesCreated))
+	sf.SetKnownStatus(resourcestatus.ResourceStatus(SecretFilesCreated))
+
+	data, err := json.Marshal(sf)
+	require.NoError(t, err)
+	restored := &SecretFilesResource{}
+	require.NoError(t, json.Unmarshal(data, restored))
+	assert.Equal(t, sf.taskARN, restored.taskARN)
+	assert.Equal(t, sf.localPath, restored.localPath)
+	assert.Equal(t, sf.hostPath, restored.hostPath)
+	assert.Equal(t, sf.GetKnownStatus(), restored.GetKnownStatus())
+	assert.Equal(t, sf.GetDesiredStatus(), restored.GetDesiredStatus())
+}
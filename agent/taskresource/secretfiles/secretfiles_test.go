@@ -0,0 +1,97 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package secretfiles
+
+import (
+	"os"
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileFromSecret(t *testing.T) {
+	uid := int64(1000)
+	for _, tc := range []struct {
+		name          string
+		secret        apicontainer.Secret
+		containerUser string
+		expected      File
+	}{
+		{
+			name:     "defaults",
+			secret:   apicontainer.Secret{Name: "password", ContainerPath: "/run/secrets/password"},
+			expected: File{Name: "3", ContainerPath: "/run/secrets/password", Mode: 0400},
+		},
+		{
+			name: "configured",
+			secret: apicontainer.Secret{
+				Name:          "password",
+				ContainerPath: "/etc/app/../app/password",
+				FileMode:      "0440",
+				UID:           &uid,
+			},
+			containerUser: "2000:3000",
+			expected:      File{Name: "3", ContainerPath: "/etc/app/password", Mode: 0440, UID: 1000, GID: 3000},
+		},
+		{
+			name:          "numeric container user",
+			secret:        apicontainer.Secret{Name: "password", ContainerPath: "/run/secrets/password"},
+			containerUser: "2000",
+			expected:      File{Name: "3", ContainerPath: "/run/secrets/password", Mode: 0400, UID: 2000, GID: 2000},
+		},
+		{
+			name:          "named container user",
+			secret:        apicontainer.Secret{Name: "password", ContainerPath: "/run/secrets/password"},
+			containerUser: "nginx",
+			expected:      File{Name: "3", ContainerPath: "/run/secrets/password", Mode: 0400},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			file, err := FileFromSecret(3, tc.secret, tc.containerUser)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, file)
+		})
+	}
+}
+
+func TestFileFromSecretInvalid(t *testing.T) {
+	negative := int64(-1)
+	for name, secret := range map[string]apicontainer.Secret{
+		"relative container path": {Name: "password", ContainerPath: "password"},
+		"non octal file mode":     {Name: "password", ContainerPath: "/run/secrets/password", FileMode: "0480"},
+		"file mode out of range":  {Name: "password", ContainerPath: "/run/secrets/password", FileMode: "01777"},
+		"negative uid":            {Name: "password", ContainerPath: "/run/secrets/password", UID: &negative},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := FileFromSecret(0, secret, "")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestFileModeIsPermissionBits(t *testing.T) {
+	file, err := FileFromSecret(0, apicontainer.Secret{
+		Name:          "password",
+		ContainerPath: "/run/secrets/password",
+		FileMode:      "644",
+	}, "")
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0644), file.Mode)
+}
@@ -0,0 +1,89 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package secretfiles
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusString(t *testing.T) {
+	var resourceStatus SecretFilesStatus
+
+	resourceStatus = SecretFilesStatusNone
+	assert.Equal(t, resourceStatus.String(), "NONE")
+	resourceStatus = SecretFilesCreated
+	assert.Equal(t, resourceStatus.String(), "CREATED")
+	resourceStatus = SecretFilesRemoved
+	assert.Equal(t, resourceStatus.String(), "REMOVED")
+}
+
+func TestMarshalSecretFilesStatus(t *testing.T) {
+	status := SecretFilesStatusNone
+	bytes, err := status.MarshalJSON()
+
+	assert.NoError(t, err)
+	assert.Equal(t, `"NONE"`, string(bytes[:]))
+}
+
+func TestMarshalNilSecretFilesStatus(t *testing.T) {
+	var status *SecretFilesStatus
+	bytes, err := status.MarshalJSON()
+
+	assert.Nil(t, bytes)
+	assert.Nil(t, err)
+}
+
+type testSecretFilesStatus struct {
+	SomeStatus SecretFilesStatus `json:"status"`
+}
+
+func TestUnmarshalSecretFilesStatus(t *testing.T) {
+	status := SecretFilesStatusNone
+
+	err := json.Unmarshal([]byte(`"CREATED"`), &status)
+	assert.NoError(t, err)
+	assert.Equal(t, SecretFilesCreated, status, "CREATED should unmarshal to CREATED, not "+status.String())
+
+	var testStatus testSecretFilesStatus
+	err = json.Unmarshal([]byte(`{"status":"REMOVED"}`), &testStatus)
+	assert.NoError(t, err)
+	assert.Equal(t, SecretFilesRemoved, testStatus.SomeStatus, "REMOVED should unmarshal to REMOVED, not "+testStatus.SomeStatus.String())
+}
+
+func TestUnmarshalNullSecretFilesStatus(t *testing.T) {
+	status := SecretFilesCreated
+	err := json.Unmarshal([]byte("null"), &status)
+	assert.NoError(t, err)
+	assert.Equal(t, SecretFilesStatusNone, status, "null should unmarshal to None, not "+status.String())
+}
+
+func TestUnmarshalNonStringSecretFilesStatusDefaultNone(t *testing.T) {
+	status := SecretFilesCreated
+	err := json.Unmarshal([]byte(`1`), &status)
+	assert.NotNil(t, err)
+	assert.Equal(t, SecretFilesStatusNone, status, "non-string status should unmarshal to None, not "+status.String())
+}
+
+func TestUnmarshalUnmappedSecretFilesStatusDefaultNone(t *testing.T) {
+	status := SecretFilesRemoved
+	err := json.Unmarshal([]byte(`"SOMEOTHER"`), &status)
+	assert.NotNil(t, err)
+	assert.Equal(t, SecretFilesStatusNone, status, "Unmapped status should unmarshal to None, not "+status.String())
+}
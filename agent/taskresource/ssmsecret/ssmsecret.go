@@ -24,6 +24,7 @@ import (
 
 	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
 	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/secretcache"
 	"github.com/aws/amazon-ecs-agent/agent/ssm"
 	"github.com/aws/amazon-ecs-agent/agent/ssm/factory"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
@@ -67,6 +68,8 @@ type SSMSecretResource struct {
 	// needed mostly for testing.
 	ssmClientCreator factory.SSMClientCreator
 	ipCompatibility  ipcompatibility.IPCompatibility
+	// secretCache is the host level cache that secret values are read from, if set
+	secretCache *secretcache.Cache
 
 	// terminalReason should be set for resource creation failures. This ensures
 	// the resource object carries some context for why provisioning failed.
@@ -83,7 +86,8 @@ func NewSSMSecretResource(taskARN string,
 	executionCredentialsID string,
 	credentialsManager credentials.Manager,
 	ssmClientCreator factory.SSMClientCreator,
-	ipCompatibility ipcompatibility.IPCompatibility) *SSMSecretResource {
+	ipCompatibility ipcompatibility.IPCompatibility,
+	secretCache *secretcache.Cache) *SSMSecretResource {
 
 	s := &SSMSecretResource{
 		taskARN:                taskARN,
@@ -92,6 +96,7 @@ func NewSSMSecretResource(taskARN string,
 		executionCredentialsID: executionCredentialsID,
 		ssmClientCreator:       ssmClientCreator,
 		ipCompatibility:        ipCompatibility,
+		secretCache:            secretCache,
 	}
 
 	s.initStatusToTransition()
@@ -310,6 +315,11 @@ func (secret *SSMSecretResource) retrieveSSMSecretValuesByRegion(region string,
 	seelog.Infof("ssm secret resource: retrieving secrets for region %s in task: [%s]", region, secret.taskARN)
 	defer wg.Done()
 
+	if secret.secretCache != nil {
+		secret.retrieveCachedSSMSecretValues(region, secrets, iamCredentials, errorEvents)
+		return
+	}
+
 	var wgPerRegion sync.WaitGroup
 	var secretNames []string
 
@@ -361,6 +371,35 @@ func (secret *SSMSecretResource) retrieveSSMSecretValues(region string, names []
 	}
 }
 
+// retrieveCachedSSMSecretValues reads secret values from the host level secret cache, which batches the
+// secrets that are not cached with the ones requested by other tasks
+func (secret *SSMSecretResource) retrieveCachedSSMSecretValues(region string, secrets []apicontainer.Secret, iamCredentials credentials.IAMRoleCredentials, errorEvents chan error) {
+	var secretNames []string
+	for _, s := range secrets {
+		if _, ok := secret.GetCachedSecretValue(s.GetSecretResourceCacheKey()); ok {
+			continue
+		}
+		secretNames = append(secretNames, s.ValueFrom)
+	}
+	if len(secretNames) == 0 {
+		return
+	}
+
+	secValueMap, err := secret.secretCache.GetSSMSecrets(region, iamCredentials, secretNames)
+	if err != nil {
+		errorEvents <- fmt.Errorf("fetching secret data from SSM Parameter Store in %s: %v", region, err)
+		return
+	}
+
+	secret.lock.Lock()
+	defer secret.lock.Unlock()
+
+	for secretName, secretValue := range secValueMap {
+		secretKey := secretName + "_" + region
+		secret.secretData[secretKey] = secretValue
+	}
+}
+
 // getRequiredSecrets returns the requiredSecrets field of ssmsecret task resource
 func (secret *SSMSecretResource) getRequiredSecrets() map[string][]apicontainer.Secret {
 	secret.lock.RLock()
@@ -424,6 +463,7 @@ func (secret *SSMSecretResource) Initialize(
 	secret.credentialsManager = resourceFields.CredentialsManager
 	secret.ssmClientCreator = resourceFields.SSMClientCreator
 	secret.ipCompatibility = config.InstanceIPCompatibility
+	secret.secretCache = resourceFields.SecretCache
 
 	// if task hasn't turn to 'created' status, and it's desire status is 'running'
 	// the resource status needs to be reset to 'NONE' status so the secret value
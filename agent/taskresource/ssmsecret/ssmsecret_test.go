@@ -25,6 +25,7 @@ import (
 
 	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
 	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/secretcache"
 	mock_factory "github.com/aws/amazon-ecs-agent/agent/ssm/factory/mocks"
 	mock_ssm "github.com/aws/amazon-ecs-agent/agent/ssm/mocks"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
@@ -534,3 +535,36 @@ func TestClearSSMSecretValue(t *testing.T) {
 	ssmRes.clearSSMSecretValue()
 	assert.Equal(t, 0, len(ssmRes.secretData))
 }
+
+func TestCreateWithSecretCacheSharedAcrossTasks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	credentialsManager := mock_credentials.NewMockManager(ctrl)
+	ssmClientCreator := mock_factory.NewMockSSMClientCreator(ctrl)
+	mockSSMClient := mock_ssm.NewMockSSMClient(ctrl)
+
+	iamRoleCreds := credentials.IAMRoleCredentials{RoleArn: "arn:aws:iam::123456789012:role/execution"}
+	creds := credentials.TaskIAMRoleCredentials{IAMRoleCredentials: iamRoleCreds}
+	secretCache, err := secretcache.NewCache(time.Minute, ssmClientCreator, nil, testIPCompatibility)
+	require.NoError(t, err)
+
+	credentialsManager.EXPECT().GetTaskCredentials(executionCredentialsID).Return(creds, true).Times(2)
+	ssmClientCreator.EXPECT().NewSSMClient(region1, iamRoleCreds, testIPCompatibility).Return(mockSSMClient, nil)
+	mockSSMClient.EXPECT().GetParameters(gomock.Any(), gomock.Any()).Return(&ssm.GetParametersOutput{
+		Parameters: []ssmtypes.Parameter{
+			{Name: aws.String(valueFrom1), Value: aws.String(secretValue)},
+		},
+	}, nil).Times(1)
+
+	for _, arn := range []string{"task1", "task2"} {
+		ssmRes := NewSSMSecretResource(arn, map[string][]apicontainer.Secret{
+			region1: {{Name: secretName1, ValueFrom: valueFrom1, Region: region1, Provider: "ssm"}},
+		}, executionCredentialsID, credentialsManager, ssmClientCreator, testIPCompatibility, secretCache)
+		require.NoError(t, ssmRes.Create())
+		value, ok := ssmRes.GetCachedSecretValue(secretKeyWest1)
+		require.True(t, ok)
+		assert.Equal(t, secretValue, value)
+	}
+	assert.Equal(t, uint64(1), secretCache.Stats().Hits)
+}
@@ -22,9 +22,12 @@ import (
 	asmsecretres "github.com/aws/amazon-ecs-agent/agent/taskresource/asmsecret"
 	cgroupres "github.com/aws/amazon-ecs-agent/agent/taskresource/cgroup"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/credentialspec"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource/csivolume"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/envFiles"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource/ephemeralvolume"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/firelens"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/fsxwindowsfileserver"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource/secretfiles"
 	ssmsecretres "github.com/aws/amazon-ecs-agent/agent/taskresource/ssmsecret"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/volume"
 )
@@ -48,6 +51,12 @@ const (
 	EnvironmentFilesKey = envFiles.ResourceName
 	// FSxWindowsFileServerKey is the string used in resources map to represent fsxwindowsfileserver resource
 	FSxWindowsFileServerKey = fsxwindowsfileserver.ResourceName
+	// CSIVolumeKey is the string used in resources map to represent csivolume resource
+	CSIVolumeKey = csivolume.ResourceName
+	// EphemeralVolumeKey is the string used in resources map to represent ephemeralvolume resource
+	EphemeralVolumeKey = ephemeralvolume.ResourceName
+	// SecretFilesKey is the string used in resources map to represent secretfiles resource
+	SecretFilesKey = secretfiles.ResourceName
 )
 
 // ResourcesMap represents the map of resource type to the corresponding resource
@@ -91,6 +100,12 @@ func unmarshalResource(key string, value json.RawMessage, result map[string][]ta
 		return unmarshalEnvironmentFilesKey(key, value, result)
 	case FSxWindowsFileServerKey:
 		return unmarshalFSxWindowsFileServerKey(key, value, result)
+	case CSIVolumeKey:
+		return unmarshalCSIVolumeKey(key, value, result)
+	case EphemeralVolumeKey:
+		return unmarshalEphemeralVolumeKey(key, value, result)
+	case SecretFilesKey:
+		return unmarshalSecretFilesKey(key, value, result)
 	default:
 		return errors.New("Unsupported resource type")
 	}
@@ -256,3 +271,57 @@ func unmarshalFSxWindowsFileServerKey(key string, value json.RawMessage, result
 	}
 	return nil
 }
+
+func unmarshalCSIVolumeKey(key string, value json.RawMessage, result map[string][]taskresource.TaskResource) error {
+	var csiVolumes []json.RawMessage
+	err := json.Unmarshal(value, &csiVolumes)
+	if err != nil {
+		return err
+	}
+
+	for _, csiVolume := range csiVolumes {
+		res := &csivolume.CSIVolumeResource{}
+		err := res.UnmarshalJSON(csiVolume)
+		if err != nil {
+			return err
+		}
+		result[key] = append(result[key], res)
+	}
+	return nil
+}
+
+func unmarshalEphemeralVolumeKey(key string, value json.RawMessage, result map[string][]taskresource.TaskResource) error {
+	var ephemeralVolumes []json.RawMessage
+	err := json.Unmarshal(value, &ephemeralVolumes)
+	if err != nil {
+		return err
+	}
+
+	for _, ephemeralVolume := range ephemeralVolumes {
+		res := &ephemeralvolume.EphemeralVolumeResource{}
+		err := res.UnmarshalJSON(ephemeralVolume)
+		if err != nil {
+			return err
+		}
+		result[key] = append(result[key], res)
+	}
+	return nil
+}
+
+func unmarshalSecretFilesKey(key string, value json.RawMessage, result map[string][]taskresource.TaskResource) error {
+	var secretFiles []json.RawMessage
+	err := json.Unmarshal(value, &secretFiles)
+	if err != nil {
+		return err
+	}
+
+	for _, secretFile := range secretFiles {
+		res := &secretfiles.SecretFilesResource{}
+		err := res.UnmarshalJSON(secretFile)
+		if err != nil {
+			return err
+		}
+		result[key] = append(result[key], res)
+	}
+	return nil
+}
@@ -17,6 +17,7 @@ import (
 	asmfactory "github.com/aws/amazon-ecs-agent/agent/asm/factory"
 	fsxfactory "github.com/aws/amazon-ecs-agent/agent/fsx/factory"
 	s3factory "github.com/aws/amazon-ecs-agent/agent/s3/factory"
+	"github.com/aws/amazon-ecs-agent/agent/secretcache"
 	ssmfactory "github.com/aws/amazon-ecs-agent/agent/ssm/factory"
 	"github.com/aws/amazon-ecs-agent/agent/utils/ioutilwrapper"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
@@ -30,4 +31,6 @@ type ResourceFieldsCommon struct {
 	S3ClientCreator    s3factory.S3ClientCreator
 	CredentialsManager credentials.Manager
 	EC2InstanceID      string
+	// SecretCache is the host level cache of secret values. Secrets are retrieved for each task when nil.
+	SecretCache *secretcache.Cache
 }
@@ -18,7 +18,6 @@ import (
 	"math/rand"
 	"net"
 	"strconv"
-	"strings"
 	"sync"
 	"time"
 
@@ -35,7 +34,6 @@ const (
 	portNotFoundErrMsg       = "a host port is unavailable"
 	portsNotFoundErrMsg      = "%v contiguous host ports are unavailable"
 	portRangeErrMsg          = "The host port range: %s found by ECS Agent is not within the expected host port range: %s"
-	portErrMsg               = "The host port: %s found by ECS Agent is not within the expected host port range: %s"
 )
 
 var (
@@ -110,7 +108,7 @@ func ResetTracker() {
 func GetHostPortRange(numberOfPorts int, protocol string, dynamicHostPortRange string) (string, error) {
 	portLock.Lock()
 	defer portLock.Unlock()
-	result, err := getNumOfHostPorts(numberOfPorts, protocol, dynamicHostPortRange)
+	result, err := getNumOfHostPorts(numberOfPorts, protocol, dynamicHostPortRange, isPortAvailableFunc)
 	if err == nil {
 		// Verify the found host port range is within the given dynamic host port range
 		if isInRange := verifyPortsWithinRange(result, dynamicHostPortRange); !isInRange {
@@ -121,28 +119,11 @@ func GetHostPortRange(numberOfPorts int, protocol string, dynamicHostPortRange s
 	return result, err
 }
 
-// GetHostPort gets 1 host port from the ephemeral host port range defined on the host.
-// dynamicHostPortRange can be set by customers using ECS Agent environment variable ECS_DYNAMIC_HOST_PORT_RANGE;
-// otherwise, ECS Agent will use the default value returned from GetDynamicHostPortRange() in the utils package.
-func GetHostPort(protocol string, dynamicHostPortRange string) (string, error) {
-	portLock.Lock()
-	defer portLock.Unlock()
-	numberOfPorts := 1
-	result, err := getNumOfHostPorts(numberOfPorts, protocol, dynamicHostPortRange)
-	foundHostPort := strings.Split(result, "-")[0]
-	if err == nil {
-		// Verify the found host port is within the given dynamic host port range
-		if isInRange := verifyPortsWithinRange(result, dynamicHostPortRange); !isInRange {
-			errMsg := fmt.Errorf(portErrMsg, foundHostPort, dynamicHostPortRange)
-			return "", errMsg
-		}
-	}
-	return foundHostPort, err
-}
-
 // getNumOfHostPorts returns the requested number of host ports using the given dynamic host port range
-// and protocol. If no host port(s) was/were found, an empty string along with the error message will be returned.
-func getNumOfHostPorts(numberOfPorts int, protocol, dynamicHostPortRange string) (string, error) {
+// and protocol, among the ports that isUsable accepts. If no host port(s) was/were found, an empty string
+// along with the error message will be returned.
+func getNumOfHostPorts(numberOfPorts int, protocol, dynamicHostPortRange string,
+	isUsable func(port int, protocol string) (bool, error)) (string, error) {
 	// get ephemeral port range, either default or if custom-defined
 	startHostPortRange, endHostPortRange, _ := nat.ParsePortRangeToInt(dynamicHostPortRange)
 	start := startHostPortRange
@@ -156,14 +137,14 @@ func getNumOfHostPorts(numberOfPorts int, protocol, dynamicHostPortRange string)
 		start = lastAssignedHostPort + 1
 	}
 
-	result, lastCheckedPort, err := getHostPortRange(numberOfPorts, start, end, protocol)
+	result, lastCheckedPort, err := getHostPortRange(numberOfPorts, start, end, protocol, isUsable)
 	if err != nil {
 		if lastAssignedHostPort != 0 {
 			// this implies that there are no contiguous host ports available from lastAssignedHostPort to endHostPortRange
 			// so, we need to loop back to the startHostPortRange and check for contiguous ports until lastCheckedPort
 			start = startHostPortRange
 			end = lastCheckedPort - 1
-			result, lastCheckedPort, err = getHostPortRange(numberOfPorts, start, end, protocol)
+			result, lastCheckedPort, err = getHostPortRange(numberOfPorts, start, end, protocol, isUsable)
 		}
 	}
 
@@ -175,10 +156,11 @@ func getNumOfHostPorts(numberOfPorts int, protocol, dynamicHostPortRange string)
 	return result, err
 }
 
-func getHostPortRange(numberOfPorts, start, end int, protocol string) (string, int, error) {
+func getHostPortRange(numberOfPorts, start, end int, protocol string,
+	isUsable func(port int, protocol string) (bool, error)) (string, int, error) {
 	var resultStartPort, resultEndPort, n int
 	for port := start; port <= end; port++ {
-		isAvailable, err := isPortAvailableFunc(port, protocol)
+		isAvailable, err := isUsable(port, protocol)
 		if !isAvailable || err != nil {
 			// either port is unavailable or some error occurred while listening or closing the listener,
 			// we proceed to the next port
@@ -201,16 +183,19 @@ func getHostPortRange(numberOfPorts, start, end int, protocol string) (string, i
 	}
 
 	if n != numberOfPorts {
-		errMsg := errors.New(portNotFoundErrMsg)
-		if numberOfPorts > 1 {
-			errMsg = fmt.Errorf(portsNotFoundErrMsg, numberOfPorts)
-		}
-		return "", resultEndPort, errMsg
+		return "", resultEndPort, hostPortsNotFoundError(numberOfPorts)
 	}
 
 	return fmt.Sprintf("%d-%d", resultStartPort, resultEndPort), resultEndPort, nil
 }
 
+func hostPortsNotFoundError(numberOfPorts int) error {
+	if numberOfPorts > 1 {
+		return fmt.Errorf(portsNotFoundErrMsg, numberOfPorts)
+	}
+	return errors.New(portNotFoundErrMsg)
+}
+
 // isPortAvailable checks if a port is available
 func isPortAvailable(port int, protocol string) (bool, error) {
 	portStr := strconv.Itoa(port)
@@ -180,63 +180,6 @@ func TestGetHostPortRange(t *testing.T) {
 	}
 }
 
-func TestGetHostPort(t *testing.T) {
-	numberOfPorts := 1
-	testCases := []struct {
-		testName                  string
-		testDynamicHostPortRange  string
-		protocol                  string
-		numberOfRequests          int
-		resetLastAssignedHostPort bool
-	}{
-		{
-			testName:                  "tcp protocol, a host port found",
-			testDynamicHostPortRange:  "40090-40099",
-			protocol:                  testTCPProtocol,
-			resetLastAssignedHostPort: true,
-			numberOfRequests:          1,
-		},
-		{
-			testName:                  "udp protocol, a host port found",
-			testDynamicHostPortRange:  "40090-40099",
-			protocol:                  testUDPProtocol,
-			resetLastAssignedHostPort: true,
-			numberOfRequests:          1,
-		},
-		{
-			testName:                  "5 requests for host port in succession, success",
-			testDynamicHostPortRange:  "50090-50099",
-			protocol:                  testTCPProtocol,
-			resetLastAssignedHostPort: true,
-			numberOfRequests:          5,
-		},
-		{
-			testName:                  "5 requests for host port in succession, success",
-			testDynamicHostPortRange:  "50090-50099",
-			protocol:                  testUDPProtocol,
-			resetLastAssignedHostPort: false,
-			numberOfRequests:          5,
-		},
-	}
-
-	for _, tc := range testCases {
-		if tc.resetLastAssignedHostPort {
-			// need to reset the tracker to avoid getting data from previous test cases
-			ResetTracker()
-		}
-
-		t.Run(tc.testName, func(t *testing.T) {
-			for i := 0; i < tc.numberOfRequests; i++ {
-				hostPortRange, err := GetHostPort(tc.protocol, tc.testDynamicHostPortRange)
-				assert.NoError(t, err)
-				numberOfHostPorts, err := getPortRangeLength(hostPortRange)
-				assert.NoError(t, err)
-				assert.Equal(t, numberOfPorts, numberOfHostPorts)
-			}
-		})
-	}
-}
-
 func TestPortIsInRange(t *testing.T) {
 	testCases := []struct {
 		testName       string
@@ -0,0 +1,380 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+
+	"github.com/docker/go-connections/nat"
+)
+
+const (
+	// HostPortAllocationSequential searches the dynamic host port range from the host port assigned last.
+	HostPortAllocationSequential = "sequential"
+	// HostPortAllocationRandom searches the dynamic host port range from a random host port.
+	HostPortAllocationRandom = "random"
+	// HostPortAllocationLeastRecentlyUsed picks the host ports that were allocated the longest time ago,
+	// preferring host ports that were never allocated.
+	HostPortAllocationLeastRecentlyUsed = "least-recently-used"
+	// HostPortAllocationSticky picks the host ports last allocated to the same container port of the same
+	// service, so that a service keeps its host ports across task replacements. Other container ports get
+	// the least recently used host ports.
+	HostPortAllocationSticky = "sticky"
+
+	// hostPortAllocationRetention is how long released allocations are remembered for, to pick least
+	// recently used and sticky host ports.
+	hostPortAllocationRetention = 24 * time.Hour
+)
+
+// HostPortAllocation is a host port range allocated to a container port of a task.
+type HostPortAllocation struct {
+	// Key identifies the container port across the tasks of a service.
+	Key      string
+	TaskARN  string
+	Protocol string
+	// HostPortRange is the allocated host port range, e.g. "32768-32768" for a single host port.
+	HostPortRange string
+	AllocatedAt   time.Time
+	// ReleasedAt is when the task of the allocation stopped, zero while it is running.
+	ReleasedAt time.Time
+}
+
+// ID returns the identifier of the allocation. Allocating the same host port range again replaces
+// the previous allocation.
+func (a *HostPortAllocation) ID() string {
+	return a.Protocol + "/" + a.HostPortRange
+}
+
+func (a *HostPortAllocation) released() bool {
+	return !a.ReleasedAt.IsZero()
+}
+
+func (a *HostPortAllocation) contains(port int, protocol string) bool {
+	start, end, err := nat.ParsePortRangeToInt(a.HostPortRange)
+	return err == nil && a.Protocol == protocol && portIsInRange(port, start, end)
+}
+
+// HostPortAllocationStore persists the recent host port allocations across agent restarts.
+type HostPortAllocationStore interface {
+	// SaveHostPortAllocation saves a host port allocation.
+	SaveHostPortAllocation(*HostPortAllocation) error
+	// DeleteHostPortAllocation deletes a host port allocation by ID.
+	DeleteHostPortAllocation(string) error
+	// GetHostPortAllocations gets all the host port allocations.
+	GetHostPortAllocations() ([]*HostPortAllocation, error)
+}
+
+// hostPortAllocator tracks the recent host port allocations. It is guarded by portLock.
+type hostPortAllocator struct {
+	strategy    string
+	cooldown    time.Duration
+	store       HostPortAllocationStore
+	allocations map[string]*HostPortAllocation
+}
+
+var allocator = &hostPortAllocator{
+	strategy:    HostPortAllocationSequential,
+	allocations: make(map[string]*HostPortAllocation),
+}
+
+// IsValidHostPortAllocationStrategy returns true if the strategy is supported.
+func IsValidHostPortAllocationStrategy(strategy string) bool {
+	switch strategy {
+	case HostPortAllocationSequential, HostPortAllocationRandom,
+		HostPortAllocationLeastRecentlyUsed, HostPortAllocationSticky:
+		return true
+	}
+	return false
+}
+
+// ConfigureHostPortAllocation sets the strategy used to allocate dynamic host ports, and how long host ports
+// released by a task are kept from other container ports. The recent allocations are loaded from the store,
+// and the ones of tasks that are not running anymore are released.
+func ConfigureHostPortAllocation(strategy string, cooldown time.Duration, store HostPortAllocationStore,
+	runningTaskARNs []string) error {
+	portLock.Lock()
+	defer portLock.Unlock()
+
+	allocator.strategy = strategy
+	allocator.cooldown = cooldown
+	allocator.store = store
+	allocator.allocations = make(map[string]*HostPortAllocation)
+	if store == nil {
+		return nil
+	}
+
+	allocations, err := store.GetHostPortAllocations()
+	if err != nil {
+		return err
+	}
+	running := make(map[string]struct{}, len(runningTaskARNs))
+	for _, arn := range runningTaskARNs {
+		running[arn] = struct{}{}
+	}
+	now := time.Now()
+	for _, allocation := range allocations {
+		allocator.allocations[allocation.ID()] = allocation
+		if _, ok := running[allocation.TaskARN]; !ok && !allocation.released() {
+			allocation.ReleasedAt = now
+			allocator.save(allocation)
+		}
+	}
+	allocator.prune(now)
+	return nil
+}
+
+// AllocateHostPort allocates 1 host port from the dynamic host port range to the container port identified
+// by key, for the task.
+func AllocateHostPort(taskARN, key, protocol, dynamicHostPortRange string) (string, error) {
+	result, err := AllocateHostPortRange(taskARN, key, 1, protocol, dynamicHostPortRange)
+	if err != nil {
+		return "", err
+	}
+	return strings.Split(result, "-")[0], nil
+}
+
+// AllocateHostPortRange allocates N contiguous host ports from the dynamic host port range to the container
+// port identified by key, for the task, using the configured strategy. Host ports allocated to running tasks,
+// and host ports released by the tasks of other container ports less than the cooldown ago, are skipped.
+func AllocateHostPortRange(taskARN, key string, numberOfPorts int, protocol,
+	dynamicHostPortRange string) (string, error) {
+	portLock.Lock()
+	defer portLock.Unlock()
+
+	now := time.Now()
+	allocator.prune(now)
+	isUsable := allocator.usableFunc(taskARN, key, now)
+
+	var result string
+	var err error
+	switch allocator.strategy {
+	case HostPortAllocationRandom:
+		result, err = allocator.findHostPorts(allocator.randomStarts(numberOfPorts, dynamicHostPortRange),
+			numberOfPorts, protocol, isUsable)
+	case HostPortAllocationLeastRecentlyUsed:
+		result, err = allocator.findHostPorts(allocator.leastRecentlyUsedStarts(numberOfPorts, protocol,
+			dynamicHostPortRange), numberOfPorts, protocol, isUsable)
+	case HostPortAllocationSticky:
+		starts := allocator.leastRecentlyUsedStarts(numberOfPorts, protocol, dynamicHostPortRange)
+		if previous := allocator.previousStart(key, numberOfPorts, protocol, dynamicHostPortRange); previous != 0 {
+			starts = append([]int{previous}, starts...)
+		}
+		result, err = allocator.findHostPorts(starts, numberOfPorts, protocol, isUsable)
+	default:
+		result, err = getNumOfHostPorts(numberOfPorts, protocol, dynamicHostPortRange, isUsable)
+	}
+	if err != nil {
+		return "", err
+	}
+	if !verifyPortsWithinRange(result, dynamicHostPortRange) {
+		return "", fmt.Errorf(portRangeErrMsg, result, dynamicHostPortRange)
+	}
+
+	allocation := &HostPortAllocation{
+		Key:           key,
+		TaskARN:       taskARN,
+		Protocol:      protocol,
+		HostPortRange: result,
+		AllocatedAt:   now,
+	}
+	allocator.allocations[allocation.ID()] = allocation
+	allocator.save(allocation)
+	return result, nil
+}
+
+// ReleaseHostPorts marks the host ports allocated to the task as released, which starts their cooldown.
+func ReleaseHostPorts(taskARN string) {
+	portLock.Lock()
+	defer portLock.Unlock()
+
+	now := time.Now()
+	for _, allocation := range allocator.allocations {
+		if allocation.TaskARN == taskARN && !allocation.released() {
+			allocation.ReleasedAt = now
+			allocator.save(allocation)
+		}
+	}
+}
+
+// usableFunc returns a function that checks whether a host port can be allocated to the container port
+// identified by key, for the task.
+func (a *hostPortAllocator) usableFunc(taskARN, key string,
+	now time.Time) func(port int, protocol string) (bool, error) {
+	return func(port int, protocol string) (bool, error) {
+		for _, allocation := range a.allocations {
+			if !allocation.contains(port, protocol) {
+				continue
+			}
+			if !allocation.released() && allocation.TaskARN != taskARN {
+				// The container of another task may not have bound the host port yet.
+				return false, nil
+			}
+			if allocation.released() && allocation.Key != key && now.Sub(allocation.ReleasedAt) < a.cooldown {
+				return false, nil
+			}
+		}
+		return isPortAvailableFunc(port, protocol)
+	}
+}
+
+// findHostPorts returns the first range of N contiguous usable host ports, among the ranges starting at
+// the given host ports.
+func (a *hostPortAllocator) findHostPorts(starts []int, numberOfPorts int, protocol string,
+	isUsable func(port int, protocol string) (bool, error)) (string, error) {
+	checked := make(map[int]bool)
+	usable := func(port int) bool {
+		ok, found := checked[port]
+		if !found {
+			available, err := isUsable(port, protocol)
+			ok = available && err == nil
+			checked[port] = ok
+		}
+		return ok
+	}
+
+	for _, start := range starts {
+		found := true
+		for port := start; port < start+numberOfPorts; port++ {
+			if !usable(port) {
+				found = false
+				break
+			}
+		}
+		if found {
+			return fmt.Sprintf("%d-%d", start, start+numberOfPorts-1), nil
+		}
+	}
+	return "", hostPortsNotFoundError(numberOfPorts)
+}
+
+// randomStarts returns the first host ports of all the ranges of N host ports within the dynamic host
+// port range, starting at a random one and wrapping around.
+func (a *hostPortAllocator) randomStarts(numberOfPorts int, dynamicHostPortRange string) []int {
+	starts := rangeStarts(numberOfPorts, dynamicHostPortRange)
+	if len(starts) == 0 {
+		return nil
+	}
+	offset := randIntFunc(len(starts))
+	return append(starts[offset:], starts[:offset]...)
+}
+
+// leastRecentlyUsedStarts returns the first host ports of all the ranges of N host ports within the
+// dynamic host port range, ordered by when any of their host ports was last allocated.
+func (a *hostPortAllocator) leastRecentlyUsedStarts(numberOfPorts int, protocol,
+	dynamicHostPortRange string) []int {
+	lastAllocated := make(map[int]time.Time)
+	for _, allocation := range a.allocations {
+		start, end, err := nat.ParsePortRangeToInt(allocation.HostPortRange)
+		if err != nil || allocation.Protocol != protocol {
+			continue
+		}
+		for port := start; port <= end; port++ {
+			if allocation.AllocatedAt.After(lastAllocated[port]) {
+				lastAllocated[port] = allocation.AllocatedAt
+			}
+		}
+	}
+
+	starts := rangeStarts(numberOfPorts, dynamicHostPortRange)
+	lastUsed := make(map[int]time.Time, len(starts))
+	for _, start := range starts {
+		var latest time.Time
+		for port := start; port < start+numberOfPorts; port++ {
+			if lastAllocated[port].After(latest) {
+				latest = lastAllocated[port]
+			}
+		}
+		lastUsed[start] = latest
+	}
+	sort.SliceStable(starts, func(i, j int) bool {
+		return lastUsed[starts[i]].Before(lastUsed[starts[j]])
+	})
+	return starts
+}
+
+// previousStart returns the first host port of the latest allocation of N host ports to the container
+// port identified by key within the dynamic host port range, or 0 if there is none.
+func (a *hostPortAllocator) previousStart(key string, numberOfPorts int, protocol,
+	dynamicHostPortRange string) int {
+	var previous *HostPortAllocation
+	for _, allocation := range a.allocations {
+		if allocation.Key != key || allocation.Protocol != protocol {
+			continue
+		}
+		start, end, err := nat.ParsePortRangeToInt(allocation.HostPortRange)
+		if err != nil || end-start+1 != numberOfPorts ||
+			!verifyPortsWithinRange(allocation.HostPortRange, dynamicHostPortRange) {
+			continue
+		}
+		if previous == nil || allocation.AllocatedAt.After(previous.AllocatedAt) {
+			previous = allocation
+		}
+	}
+	if previous == nil {
+		return 0
+	}
+	start, _, _ := nat.ParsePortRangeToInt(previous.HostPortRange)
+	return start
+}
+
+// prune forgets the allocations released longer than the retention ago.
+func (a *hostPortAllocator) prune(now time.Time) {
+	for id, allocation := range a.allocations {
+		if allocation.released() && now.Sub(allocation.ReleasedAt) > hostPortAllocationRetention {
+			delete(a.allocations, id)
+			if a.store == nil {
+				continue
+			}
+			if err := a.store.DeleteHostPortAllocation(id); err != nil {
+				logger.Warn("Unable to delete host port allocation", logger.Fields{
+					"hostPortAllocation": id,
+					field.Error:          err,
+				})
+			}
+		}
+	}
+}
+
+func (a *hostPortAllocator) save(allocation *HostPortAllocation) {
+	if a.store == nil {
+		return
+	}
+	if err := a.store.SaveHostPortAllocation(allocation); err != nil {
+		logger.Warn("Unable to save host port allocation", logger.Fields{
+			"hostPortAllocation": allocation.ID(),
+			field.TaskARN:        allocation.TaskARN,
+			field.Error:          err,
+		})
+	}
+}
+
+// rangeStarts returns the first host ports of all the ranges of N host ports within the dynamic host
+// port range, in increasing order.
+func rangeStarts(numberOfPorts int, dynamicHostPortRange string) []int {
+	start, end, err := nat.ParsePortRangeToInt(dynamicHostPortRange)
+	if err != nil {
+		return nil
+	}
+	var starts []int
+	for port := start; port+numberOfPorts-1 <= end; port++ {
+		starts = append(starts, port)
+	}
+	return starts
+}
@@ -0,0 +1,174 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testTaskARN1 = "arn:aws:ecs:us-west-2:123456789012:task/cluster/1"
+	testTaskARN2 = "arn:aws:ecs:us-west-2:123456789012:task/cluster/2"
+	testKeyA     = "service:a/web/80/tcp"
+	testKeyB     = "service:b/web/80/tcp"
+)
+
+// memoryAllocationStore is an in-memory HostPortAllocationStore.
+type memoryAllocationStore struct {
+	allocations map[string]HostPortAllocation
+}
+
+func newMemoryAllocationStore(allocations ...*HostPortAllocation) *memoryAllocationStore {
+	store := &memoryAllocationStore{allocations: make(map[string]HostPortAllocation)}
+	for _, allocation := range allocations {
+		store.allocations[allocation.ID()] = *allocation
+	}
+	return store
+}
+
+func (s *memoryAllocationStore) SaveHostPortAllocation(allocation *HostPortAllocation) error {
+	s.allocations[allocation.ID()] = *allocation
+	return nil
+}
+
+func (s *memoryAllocationStore) DeleteHostPortAllocation(id string) error {
+	delete(s.allocations, id)
+	return nil
+}
+
+func (s *memoryAllocationStore) GetHostPortAllocations() ([]*HostPortAllocation, error) {
+	var allocations []*HostPortAllocation
+	for _, allocation := range s.allocations {
+		allocation := allocation
+		allocations = append(allocations, &allocation)
+	}
+	return allocations, nil
+}
+
+// setUpHostPortAllocation configures the allocator for a test with all host ports available.
+func setUpHostPortAllocation(t *testing.T, strategy string, cooldown time.Duration,
+	store *memoryAllocationStore, runningTaskARNs ...string) {
+	isPortAvailableFuncTmp := isPortAvailableFunc
+	isPortAvailableFunc = func(int, string) (bool, error) { return true, nil }
+	t.Cleanup(func() {
+		isPortAvailableFunc = isPortAvailableFuncTmp
+		ResetTracker()
+		require.NoError(t, ConfigureHostPortAllocation(HostPortAllocationSequential, 0, nil, nil))
+	})
+	ResetTracker()
+	require.NoError(t, ConfigureHostPortAllocation(strategy, cooldown, store, runningTaskARNs))
+}
+
+func TestAllocateHostPortSequentialCooldown(t *testing.T) {
+	store := newMemoryAllocationStore()
+	setUpHostPortAllocation(t, HostPortAllocationSequential, time.Hour, store)
+
+	port, err := AllocateHostPort(testTaskARN1, testKeyA, testTCPProtocol, "40000-40002")
+	require.NoError(t, err)
+	assert.Equal(t, "40000", port)
+	assert.Len(t, store.allocations, 1)
+
+	// Host ports of running tasks are skipped, even before their containers bind them.
+	port, err = AllocateHostPort(testTaskARN2, testKeyB, testTCPProtocol, "40000-40002")
+	require.NoError(t, err)
+	assert.Equal(t, "40001", port)
+
+	// Released host ports are kept from other container ports during the cooldown.
+	ReleaseHostPorts(testTaskARN1)
+	assert.False(t, store.allocations["tcp/40000-40000"].ReleasedAt.IsZero())
+	ResetTracker()
+	port, err = AllocateHostPort("task3", testKeyB, testTCPProtocol, "40000-40002")
+	require.NoError(t, err)
+	assert.Equal(t, "40002", port)
+	_, err = AllocateHostPort("task4", testKeyB, testTCPProtocol, "40000-40002")
+	assert.Error(t, err)
+
+	// The container port the host port was released by can get it again.
+	ResetTracker()
+	port, err = AllocateHostPort("task4", testKeyA, testTCPProtocol, "40000-40002")
+	require.NoError(t, err)
+	assert.Equal(t, "40000", port)
+}
+
+func TestAllocateHostPortRangeRandom(t *testing.T) {
+	setUpHostPortAllocation(t, HostPortAllocationRandom, 0, newMemoryAllocationStore())
+	randIntFuncTmp := randIntFunc
+	defer func() {
+		randIntFunc = randIntFuncTmp
+	}()
+	randIntFunc = func(n int) int { return n - 1 }
+
+	// The search starts at the last range and wraps around.
+	hostPortRange, err := AllocateHostPortRange(testTaskARN1, testKeyA, 3, testTCPProtocol, "40000-40009")
+	require.NoError(t, err)
+	assert.Equal(t, "40007-40009", hostPortRange)
+	hostPortRange, err = AllocateHostPortRange(testTaskARN2, testKeyB, 3, testTCPProtocol, "40000-40009")
+	require.NoError(t, err)
+	assert.Equal(t, "40000-40002", hostPortRange)
+}
+
+func TestAllocateHostPortLeastRecentlyUsed(t *testing.T) {
+	now := time.Now()
+	store := newMemoryAllocationStore(
+		&HostPortAllocation{Key: testKeyA, TaskARN: "old1", Protocol: testTCPProtocol, HostPortRange: "40000-40000",
+			AllocatedAt: now.Add(-2 * time.Hour), ReleasedAt: now.Add(-time.Hour)},
+		&HostPortAllocation{Key: testKeyA, TaskARN: "old2", Protocol: testTCPProtocol, HostPortRange: "40001-40001",
+			AllocatedAt: now.Add(-3 * time.Hour), ReleasedAt: now.Add(-time.Hour)},
+	)
+	setUpHostPortAllocation(t, HostPortAllocationLeastRecentlyUsed, 0, store)
+
+	// Host ports that were never allocated come first, then the ones allocated the longest time ago.
+	for _, expected := range []string{"40002", "40001", "40000"} {
+		port, err := AllocateHostPort(testTaskARN1, testKeyB, testTCPProtocol, "40000-40002")
+		require.NoError(t, err)
+		assert.Equal(t, expected, port)
+		ReleaseHostPorts(testTaskARN1)
+	}
+}
+
+func TestAllocateHostPortSticky(t *testing.T) {
+	now := time.Now()
+	store := newMemoryAllocationStore(
+		// The previous task of service a was stopped while the agent was down.
+		&HostPortAllocation{Key: testKeyA, TaskARN: testTaskARN1, Protocol: testTCPProtocol,
+			HostPortRange: "40005-40005", AllocatedAt: now.Add(-time.Hour)},
+		// Allocations released longer than the retention ago are forgotten.
+		&HostPortAllocation{Key: testKeyB, TaskARN: "old", Protocol: testTCPProtocol, HostPortRange: "40003-40003",
+			AllocatedAt: now.Add(-50 * time.Hour), ReleasedAt: now.Add(-48 * time.Hour)},
+	)
+	setUpHostPortAllocation(t, HostPortAllocationSticky, time.Hour, store)
+	assert.False(t, store.allocations["tcp/40005-40005"].ReleasedAt.IsZero())
+	assert.NotContains(t, store.allocations, "tcp/40003-40003")
+
+	port, err := AllocateHostPort(testTaskARN2, testKeyA, testTCPProtocol, "40000-40009")
+	require.NoError(t, err)
+	assert.Equal(t, "40005", port)
+
+	// Other container ports get the least recently used host ports.
+	port, err = AllocateHostPort(testTaskARN2, testKeyB, testTCPProtocol, "40000-40009")
+	require.NoError(t, err)
+	assert.Equal(t, "40000", port)
+}
+
+func TestIsValidHostPortAllocationStrategy(t *testing.T) {
+	assert.True(t, IsValidHostPortAllocationStrategy(HostPortAllocationLeastRecentlyUsed))
+	assert.False(t, IsValidHostPortAllocationStrategy("round-robin"))
+}
@@ -0,0 +1,288 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package volumeexport archives the contents of task volumes before the task is cleaned up, so that
+// the data written by the task outlives its containers.
+package volumeexport
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
+	s3client "github.com/aws/amazon-ecs-agent/agent/s3"
+	s3factory "github.com/aws/amazon-ecs-agent/agent/s3/factory"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/ipcompatibility"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+)
+
+const (
+	// archiveFileExtension is the extension of the volume archives, which are tar archives
+	// compressed with zstd.
+	archiveFileExtension = ".tar.zst"
+	// exportTimeout bounds the time it takes to export all the volumes of a task, including the
+	// upload of their archives to S3, so that the cleanup of the task is not held up for longer.
+	exportTimeout = 30 * time.Minute
+)
+
+// Exporter archives the contents of the task volumes that have an export configuration, writing
+// them to the export directory or uploading them to S3.
+type Exporter struct {
+	dir                string
+	maxSizeBytes       int64
+	region             string
+	ipCompatibility    ipcompatibility.IPCompatibility
+	client             dockerapi.DockerClient
+	credentialsManager credentials.Manager
+	s3ClientCreator    s3factory.S3ClientCreator
+}
+
+// NewExporter returns an exporter that reads the volumes through the containers that mount them.
+func NewExporter(cfg *config.Config, client dockerapi.DockerClient, credentialsManager credentials.Manager) *Exporter {
+	return &Exporter{
+		dir:                cfg.VolumeExportDir,
+		maxSizeBytes:       cfg.VolumeExportMaxSizeBytes,
+		region:             cfg.AWSRegion,
+		ipCompatibility:    cfg.InstanceIPCompatibility,
+		client:             client,
+		credentialsManager: credentialsManager,
+		s3ClientCreator:    s3factory.NewS3ClientCreator(),
+	}
+}
+
+// ExportTask exports the volumes of the task that have an export configuration concurrently,
+// recording the status of each export in the task, and returns once every export has either
+// completed or failed. The exports that are still running when the export timeout elapses fail.
+// Volumes whose export has already completed or failed, e.g. before the agent restarted, are not
+// exported again.
+func (e *Exporter) ExportTask(ctx context.Context, task *apitask.Task) {
+	ctx, cancel := context.WithTimeout(ctx, exportTimeout)
+	defer cancel()
+
+	exported := make(map[string]bool)
+	for _, status := range task.GetVolumeExports() {
+		exported[status.VolumeName] = status.Terminal()
+	}
+	var wg sync.WaitGroup
+	for _, volume := range task.Volumes {
+		exportConfig := task.GetVolumeExportConfig(volume.Name)
+		if exportConfig == nil || exported[volume.Name] {
+			continue
+		}
+		wg.Add(1)
+		go func(volumeName string, exportConfig apitask.VolumeExportConfig) {
+			defer wg.Done()
+			status := e.exportVolume(ctx, task, volumeName, exportConfig)
+			task.SetVolumeExport(status)
+		}(volume.Name, *exportConfig)
+	}
+	wg.Wait()
+}
+
+// exportVolume archives the volume and returns the outcome of the export.
+func (e *Exporter) exportVolume(ctx context.Context, task *apitask.Task, volumeName string,
+	exportConfig apitask.VolumeExportConfig) apitask.VolumeExportStatus {
+	startedAt := time.Now()
+	status := apitask.VolumeExportStatus{
+		VolumeName: volumeName,
+		Status:     apitask.VolumeExportInProgress,
+		StartedAt:  &startedAt,
+	}
+	task.SetVolumeExport(status)
+
+	fail := func(err error) apitask.VolumeExportStatus {
+		completedAt := time.Now()
+		status.Status = apitask.VolumeExportFailed
+		status.Reason = err.Error()
+		status.CompletedAt = &completedAt
+		logger.Warn("Unable to export task volume", logger.Fields{
+			field.TaskID: task.GetID(),
+			field.Volume: volumeName,
+			field.Error:  err,
+		})
+		return status
+	}
+
+	runtimeID, containerPath, ok := findMountingContainer(task, volumeName)
+	if !ok {
+		return fail(errors.New("no created container of the task mounts the volume"))
+	}
+	maxSizeBytes := e.maxSizeBytes
+	if exportConfig.MaxSizeBytes > 0 && exportConfig.MaxSizeBytes < maxSizeBytes {
+		maxSizeBytes = exportConfig.MaxSizeBytes
+	}
+
+	path := filepath.Join(e.dir, task.GetID(), volumeName+archiveFileExtension)
+	status.Destination = path
+	size, err := e.archive(ctx, runtimeID, containerPath, path, maxSizeBytes)
+	if err != nil {
+		return fail(err)
+	}
+	status.SizeBytes = size
+
+	if exportConfig.S3Location != "" {
+		destination, err := e.upload(ctx, task, volumeName, path, exportConfig.S3Location)
+		if err != nil {
+			// The archive is kept in the export directory, so the data is not lost.
+			return fail(errors.Wrapf(err, "unable to upload the archive to %s", exportConfig.S3Location))
+		}
+		status.Destination = destination
+		if err := os.Remove(path); err != nil {
+			logger.Warn("Unable to remove the uploaded volume archive", logger.Fields{
+				field.TaskID: task.GetID(),
+				field.Volume: volumeName,
+				field.Error:  err,
+			})
+		}
+	}
+
+	completedAt := time.Now()
+	status.Status = apitask.VolumeExportCompleted
+	status.CompletedAt = &completedAt
+	logger.Info("Exported task volume", logger.Fields{
+		field.TaskID:  task.GetID(),
+		field.Volume:  volumeName,
+		"destination": status.Destination,
+		"sizeBytes":   size,
+	})
+	return status
+}
+
+// findMountingContainer returns the runtime ID of a container of the task that mounts the volume,
+// and the path the volume is mounted at in the container.
+func findMountingContainer(task *apitask.Task, volumeName string) (string, string, bool) {
+	for _, container := range task.Containers {
+		runtimeID := container.GetRuntimeID()
+		if runtimeID == "" {
+			continue
+		}
+		for _, mountPoint := range container.MountPoints {
+			if mountPoint.SourceVolume == volumeName {
+				return runtimeID, mountPoint.ContainerPath, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// archive copies the path of the container as a tar archive, compresses it with zstd and writes it
+// to the file at the given path. It returns the size of the compressed archive. The file is removed
+// if the archive could not be written in full, including when it goes over the maximum size.
+func (e *Exporter) archive(ctx context.Context, runtimeID, containerPath, path string, maxSizeBytes int64) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return 0, errors.Wrap(err, "failed to create the volume export directory")
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to create the volume archive")
+	}
+	size, err := e.compress(ctx, runtimeID, containerPath, file, maxSizeBytes)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = errors.Wrap(closeErr, "failed to write the volume archive")
+	}
+	if err != nil {
+		os.Remove(path)
+		return 0, err
+	}
+	return size, nil
+}
+
+func (e *Exporter) compress(ctx context.Context, runtimeID, containerPath string, w io.Writer, maxSizeBytes int64) (int64, error) {
+	tarball, err := e.client.CopyFromContainer(ctx, runtimeID, containerPath)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to copy the volume from the container")
+	}
+	defer tarball.Close()
+
+	output := &cappedWriter{w: w, max: maxSizeBytes}
+	// A single goroutine compresses the volume, so that the exports hold up at most one CPU each.
+	encoder, err := zstd.NewWriter(output, zstd.WithEncoderConcurrency(1))
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to compress the volume")
+	}
+	_, err = io.Copy(encoder, tarball)
+	if closeErr := encoder.Close(); err == nil {
+		err = closeErr
+	}
+	if output.exceeded {
+		return 0, errors.Errorf("the archive of the volume exceeds the maximum size of %d bytes", maxSizeBytes)
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to compress the volume")
+	}
+	return output.size, nil
+}
+
+// upload uploads the archive to the S3 location with the task execution role, and returns the S3
+// URI of the uploaded archive.
+func (e *Exporter) upload(ctx context.Context, task *apitask.Task, volumeName, path, s3Location string) (string, error) {
+	bucket, prefix, err := s3client.ParseS3URI(s3Location)
+	if err != nil {
+		return "", err
+	}
+	executionCredentials, ok := e.credentialsManager.GetTaskCredentials(task.GetExecutionCredentialsID())
+	if !ok {
+		return "", errors.New("unable to find execution role credentials")
+	}
+	client, err := e.s3ClientCreator.NewS3UploadManagerClient(bucket, e.region,
+		executionCredentials.GetIAMRoleCredentials(), e.ipCompatibility)
+	if err != nil {
+		return "", err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	key := strings.TrimPrefix(fmt.Sprintf("%s/%s/%s%s", prefix, task.GetID(), volumeName, archiveFileExtension), "/")
+	if err := s3client.UploadFile(ctx, bucket, key, file, client); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("s3://%s/%s", bucket, key), nil
+}
+
+// errArchiveTooLarge is returned by a cappedWriter once its maximum size is exceeded.
+var errArchiveTooLarge = errors.New("the archive exceeds its maximum size")
+
+// cappedWriter writes to the underlying writer until a write would take it over its maximum size.
+// That write, and every one after it, fails so that the archive is abandoned.
+type cappedWriter struct {
+	w        io.Writer
+	max      int64
+	size     int64
+	exceeded bool
+}
+
+func (w *cappedWriter) Write(p []byte) (int, error) {
+	if w.exceeded || w.size+int64(len(p)) > w.max {
+		w.exceeded = true
+		return 0, errArchiveTooLarge
+	}
+	n, err := w.w.Write(p)
+	w.size += int64(n)
+	return n, err
+}
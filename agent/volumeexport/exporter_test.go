@@ -0,0 +1,292 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package volumeexport
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	mock_dockerapi "github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi/mocks"
+	mock_factory "github.com/aws/amazon-ecs-agent/agent/s3/factory/mocks"
+	mock_s3uploadmanager "github.com/aws/amazon-ecs-agent/agent/s3/mocks/s3uploadmanager"
+	taskresourcevolume "github.com/aws/amazon-ecs-agent/agent/taskresource/volume"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
+	mock_credentials "github.com/aws/amazon-ecs-agent/ecs-agent/credentials/mocks"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	s3manager "github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/golang/mock/gomock"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testTaskARN       = "arn:aws:ecs:us-west-2:123456789012:task/cluster/taskid"
+	testTaskID        = "taskid"
+	testVolumeName    = "data"
+	testRuntimeID     = "runtimeid"
+	testContainerPath = "/var/data"
+	testCredentialsID = "credentialsid"
+	testTarball       = "tarball"
+)
+
+func testTask(exportConfig *apitask.VolumeExportConfig) *apitask.Task {
+	container := &apicontainer.Container{
+		Name: "app",
+		MountPoints: []apicontainer.MountPoint{
+			{SourceVolume: testVolumeName, ContainerPath: testContainerPath},
+		},
+	}
+	container.SetRuntimeID(testRuntimeID)
+	return &apitask.Task{
+		Arn:                    testTaskARN,
+		ExecutionCredentialsID: testCredentialsID,
+		Containers:             []*apicontainer.Container{container},
+		Volumes: []apitask.TaskVolume{
+			{Name: testVolumeName, Type: apitask.HostVolumeType, Volume: &taskresourcevolume.LocalDockerVolume{}},
+		},
+		VolumeExportConfig: exportConfig,
+	}
+}
+
+type testExporter struct {
+	*Exporter
+	client             *mock_dockerapi.MockDockerClient
+	credentialsManager *mock_credentials.MockManager
+	s3ClientCreator    *mock_factory.MockS3ClientCreator
+}
+
+func newTestExporter(t *testing.T, ctrl *gomock.Controller, maxSizeBytes int64) *testExporter {
+	te := &testExporter{
+		client:             mock_dockerapi.NewMockDockerClient(ctrl),
+		credentialsManager: mock_credentials.NewMockManager(ctrl),
+		s3ClientCreator:    mock_factory.NewMockS3ClientCreator(ctrl),
+	}
+	te.Exporter = &Exporter{
+		dir:                t.TempDir(),
+		maxSizeBytes:       maxSizeBytes,
+		region:             "us-west-2",
+		client:             te.client,
+		credentialsManager: te.credentialsManager,
+		s3ClientCreator:    te.s3ClientCreator,
+	}
+	return te
+}
+
+// expectArchive makes the docker client return the tarball of the volume.
+func (te *testExporter) expectArchive() {
+	te.client.EXPECT().CopyFromContainer(gomock.Any(), testRuntimeID, testContainerPath).
+		Return(io.NopCloser(strings.NewReader(testTarball)), nil)
+}
+
+// decompress returns the decompressed content of the zstd archive.
+func decompress(t *testing.T, archive []byte) string {
+	decoder, err := zstd.NewReader(nil)
+	require.NoError(t, err)
+	defer decoder.Close()
+	content, err := decoder.DecodeAll(archive, nil)
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestExportTaskToExportDir(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exporter := newTestExporter(t, ctrl, 1024)
+	exporter.expectArchive()
+	task := testTask(&apitask.VolumeExportConfig{})
+
+	exporter.ExportTask(context.Background(), task)
+
+	exports := task.GetVolumeExports()
+	require.Len(t, exports, 1)
+	export := exports[0]
+	path := filepath.Join(exporter.dir, testTaskID, testVolumeName+archiveFileExtension)
+	assert.Equal(t, testVolumeName, export.VolumeName)
+	assert.Equal(t, apitask.VolumeExportCompleted, export.Status)
+	assert.Equal(t, path, export.Destination)
+	assert.NotNil(t, export.StartedAt)
+	assert.NotNil(t, export.CompletedAt)
+	archive, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(archive)), export.SizeBytes)
+	assert.Equal(t, testTarball, decompress(t, archive))
+}
+
+func TestExportTaskExportsVolumesConcurrently(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exporter := newTestExporter(t, ctrl, 1024)
+	task := testTask(&apitask.VolumeExportConfig{})
+	task.Volumes = append(task.Volumes, apitask.TaskVolume{
+		Name: "logs", Type: apitask.HostVolumeType, Volume: &taskresourcevolume.LocalDockerVolume{},
+	})
+	task.Containers[0].MountPoints = append(task.Containers[0].MountPoints,
+		apicontainer.MountPoint{SourceVolume: "logs", ContainerPath: "/var/log"})
+
+	// The copy of each volume waits for the copy of the other one to start.
+	started := make(chan struct{}, 2)
+	copyVolume := func(context.Context, string, string) (io.ReadCloser, error) {
+		started <- struct{}{}
+		for len(started) < 2 {
+			runtime.Gosched()
+		}
+		return io.NopCloser(strings.NewReader(testTarball)), nil
+	}
+	exporter.client.EXPECT().CopyFromContainer(gomock.Any(), testRuntimeID, testContainerPath).DoAndReturn(copyVolume)
+	exporter.client.EXPECT().CopyFromContainer(gomock.Any(), testRuntimeID, "/var/log").DoAndReturn(copyVolume)
+
+	exporter.ExportTask(context.Background(), task)
+
+	exports := task.GetVolumeExports()
+	require.Len(t, exports, 2)
+	for _, export := range exports {
+		assert.Equal(t, apitask.VolumeExportCompleted, export.Status)
+	}
+}
+
+func TestExportTaskOverMaxSize(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exporter := newTestExporter(t, ctrl, 1024)
+	exporter.expectArchive()
+	// The limit of the task applies as it is lower than the one of the agent.
+	task := testTask(&apitask.VolumeExportConfig{MaxSizeBytes: 4})
+
+	exporter.ExportTask(context.Background(), task)
+
+	exports := task.GetVolumeExports()
+	require.Len(t, exports, 1)
+	assert.Equal(t, apitask.VolumeExportFailed, exports[0].Status)
+	assert.Contains(t, exports[0].Reason, "maximum size of 4 bytes")
+	_, err := os.Stat(filepath.Join(exporter.dir, testTaskID, testVolumeName+archiveFileExtension))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestExportTaskToS3(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exporter := newTestExporter(t, ctrl, 1024)
+	exporter.expectArchive()
+	task := testTask(&apitask.VolumeExportConfig{S3Location: "s3://bucket/exports/"})
+
+	s3Client := mock_s3uploadmanager.NewMockS3UploadManagerClient(ctrl)
+	exporter.credentialsManager.EXPECT().GetTaskCredentials(testCredentialsID).Return(credentials.TaskIAMRoleCredentials{
+		IAMRoleCredentials: credentials.IAMRoleCredentials{AccessKeyID: "akid"},
+	}, true)
+	exporter.s3ClientCreator.EXPECT().NewS3UploadManagerClient("bucket", "us-west-2",
+		credentials.IAMRoleCredentials{AccessKeyID: "akid"}, gomock.Any()).Return(s3Client, nil)
+	s3Client.EXPECT().Upload(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, input *s3.PutObjectInput, _ ...func(*s3manager.Uploader)) (*s3manager.UploadOutput, error) {
+			assert.Equal(t, "bucket", aws.ToString(input.Bucket))
+			assert.Equal(t, "exports/taskid/data.tar.zst", aws.ToString(input.Key))
+			body, err := io.ReadAll(input.Body)
+			require.NoError(t, err)
+			assert.Equal(t, testTarball, decompress(t, body))
+			return &s3manager.UploadOutput{}, nil
+		})
+
+	exporter.ExportTask(context.Background(), task)
+
+	exports := task.GetVolumeExports()
+	require.Len(t, exports, 1)
+	assert.Equal(t, apitask.VolumeExportCompleted, exports[0].Status)
+	assert.Equal(t, "s3://bucket/exports/taskid/data.tar.zst", exports[0].Destination)
+	// The archive is not kept once uploaded.
+	_, err := os.Stat(filepath.Join(exporter.dir, testTaskID, testVolumeName+archiveFileExtension))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestExportTaskS3UploadFailureKeepsArchive(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exporter := newTestExporter(t, ctrl, 1024)
+	exporter.expectArchive()
+	task := testTask(&apitask.VolumeExportConfig{S3Location: "s3://bucket"})
+
+	exporter.credentialsManager.EXPECT().GetTaskCredentials(testCredentialsID).
+		Return(credentials.TaskIAMRoleCredentials{}, false)
+
+	exporter.ExportTask(context.Background(), task)
+
+	exports := task.GetVolumeExports()
+	require.Len(t, exports, 1)
+	path := filepath.Join(exporter.dir, testTaskID, testVolumeName+archiveFileExtension)
+	assert.Equal(t, apitask.VolumeExportFailed, exports[0].Status)
+	assert.Equal(t, path, exports[0].Destination)
+	assert.Contains(t, exports[0].Reason, "execution role credentials")
+	_, err := os.Stat(path)
+	assert.NoError(t, err)
+}
+
+func TestExportTaskDockerError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exporter := newTestExporter(t, ctrl, 1024)
+	task := testTask(&apitask.VolumeExportConfig{})
+	exporter.client.EXPECT().CopyFromContainer(gomock.Any(), testRuntimeID, testContainerPath).
+		Return(nil, errors.New("no such container"))
+
+	exporter.ExportTask(context.Background(), task)
+
+	exports := task.GetVolumeExports()
+	require.Len(t, exports, 1)
+	assert.Equal(t, apitask.VolumeExportFailed, exports[0].Status)
+	assert.Contains(t, exports[0].Reason, "no such container")
+}
+
+func TestExportTaskSkipsVolumes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exporter := newTestExporter(t, ctrl, 1024)
+
+	// Volumes are not exported without an export configuration.
+	task := testTask(nil)
+	exporter.ExportTask(context.Background(), task)
+	assert.Empty(t, task.GetVolumeExports())
+
+	// Volumes are not exported again once their export has completed.
+	task = testTask(&apitask.VolumeExportConfig{})
+	task.SetVolumeExport(apitask.VolumeExportStatus{VolumeName: testVolumeName, Status: apitask.VolumeExportCompleted})
+	exporter.ExportTask(context.Background(), task)
+	require.Len(t, task.GetVolumeExports(), 1)
+
+	// Volumes that no created container mounts cannot be exported.
+	task = testTask(&apitask.VolumeExportConfig{})
+	task.Containers[0].SetRuntimeID("")
+	exporter.ExportTask(context.Background(), task)
+	exports := task.GetVolumeExports()
+	require.Len(t, exports, 1)
+	assert.Equal(t, apitask.VolumeExportFailed, exports[0].Status)
+}
@@ -487,6 +487,42 @@ func (s *BadRequestException) RequestID() string {
 	return s.RespMetadata.RequestID
 }
 
+type CSIVolumeConfiguration struct {
+	_ struct{} `type:"structure"`
+
+	Driver *string `json:"driver,omitempty" type:"string"`
+
+	FsType *string `json:"fsType,omitempty" type:"string"`
+
+	MountOptions []*string `json:"mountOptions,omitempty" type:"list"`
+
+	PublishContext map[string]*string `json:"publishContext,omitempty" type:"map"`
+
+	ReadOnly *bool `json:"readOnly,omitempty" type:"boolean"`
+
+	VolumeContext map[string]*string `json:"volumeContext,omitempty" type:"map"`
+
+	VolumeId *string `json:"volumeId,omitempty" type:"string"`
+}
+
+// String returns the string representation.
+//
+// API parameter values that are decorated as "sensitive" in the API will not
+// be included in the string output. The member name will be present, but the
+// value will be replaced with "sensitive".
+func (s CSIVolumeConfiguration) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation.
+//
+// API parameter values that are decorated as "sensitive" in the API will not
+// be included in the string output. The member name will be present, but the
+// value will be replaced with "sensitive".
+func (s CSIVolumeConfiguration) GoString() string {
+	return s.String()
+}
+
 type CloseMessage struct {
 	_ struct{} `type:"structure"`
 
@@ -667,12 +703,18 @@ type Container struct {
 
 	PortMappings []*PortMapping `json:"portMappings,omitempty" type:"list"`
 
+	PreStopHook *PreStopHook `json:"preStopHook,omitempty" type:"structure"`
+
 	Privileged *bool `json:"privileged,omitempty" type:"boolean"`
 
+	ReadinessProbe *ReadinessProbe `json:"readinessProbe,omitempty" type:"structure"`
+
 	RegistryAuthentication *RegistryAuthenticationData `json:"registryAuthentication,omitempty" type:"structure"`
 
 	RestartPolicy *RestartPolicy `json:"restartPolicy,omitempty" type:"structure"`
 
+	RoleArn *string `json:"roleArn,omitempty" type:"string"`
+
 	Secrets []*Secret `json:"secrets,omitempty" type:"list"`
 
 	StartTimeout *int64 `json:"startTimeout,omitempty" type:"integer"`
@@ -961,6 +1003,62 @@ func (s EFSVolumeConfiguration) GoString() string {
 	return s.String()
 }
 
+type EgressPolicy struct {
+	_ struct{} `type:"structure"`
+
+	AllowedCidrs []*string `json:"allowedCidrs,omitempty" type:"list"`
+
+	AllowedDnsNames []*string `json:"allowedDnsNames,omitempty" type:"list"`
+
+	AllowedPorts []*EgressPortRange `json:"allowedPorts,omitempty" type:"list"`
+}
+
+// String returns the string representation.
+//
+// API parameter values that are decorated as "sensitive" in the API will not
+// be included in the string output. The member name will be present, but the
+// value will be replaced with "sensitive".
+func (s EgressPolicy) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation.
+//
+// API parameter values that are decorated as "sensitive" in the API will not
+// be included in the string output. The member name will be present, but the
+// value will be replaced with "sensitive".
+func (s EgressPolicy) GoString() string {
+	return s.String()
+}
+
+type EgressPortRange struct {
+	_ struct{} `type:"structure"`
+
+	From *int64 `json:"from,omitempty" type:"integer"`
+
+	Protocol *string `json:"protocol,omitempty" type:"string"`
+
+	To *int64 `json:"to,omitempty" type:"integer"`
+}
+
+// String returns the string representation.
+//
+// API parameter values that are decorated as "sensitive" in the API will not
+// be included in the string output. The member name will be present, but the
+// value will be replaced with "sensitive".
+func (s EgressPortRange) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation.
+//
+// API parameter values that are decorated as "sensitive" in the API will not
+// be included in the string output. The member name will be present, but the
+// value will be replaced with "sensitive".
+func (s EgressPortRange) GoString() string {
+	return s.String()
+}
+
 type ElasticNetworkInterface struct {
 	_ struct{} `type:"structure"`
 
@@ -1044,6 +1142,8 @@ func (s EncodedString) GoString() string {
 type EnvironmentFile struct {
 	_ struct{} `type:"structure"`
 
+	Templated *bool `json:"templated,omitempty" type:"boolean"`
+
 	Type *string `json:"type,omitempty" type:"string" enum:"EnvironmentFileType"`
 
 	Value *string `json:"value,omitempty" type:"string"`
@@ -1067,6 +1167,32 @@ func (s EnvironmentFile) GoString() string {
 	return s.String()
 }
 
+type EphemeralVolumeConfiguration struct {
+	_ struct{} `type:"structure"`
+
+	Backend *string `json:"backend,omitempty" type:"string"`
+
+	SizeMiB *int64 `json:"sizeMiB,omitempty" type:"long"`
+}
+
+// String returns the string representation.
+//
+// API parameter values that are decorated as "sensitive" in the API will not
+// be included in the string output. The member name will be present, but the
+// value will be replaced with "sensitive".
+func (s EphemeralVolumeConfiguration) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation.
+//
+// API parameter values that are decorated as "sensitive" in the API will not
+// be included in the string output. The member name will be present, but the
+// value will be replaced with "sensitive".
+func (s EphemeralVolumeConfiguration) GoString() string {
+	return s.String()
+}
+
 type ErrorInput struct {
 	_ struct{} `type:"structure"`
 
@@ -1137,6 +1263,30 @@ func (s ErrorOutput) GoString() string {
 	return s.String()
 }
 
+type ExecAction struct {
+	_ struct{} `type:"structure"`
+
+	Command []*string `json:"command,omitempty" type:"list"`
+}
+
+// String returns the string representation.
+//
+// API parameter values that are decorated as "sensitive" in the API will not
+// be included in the string output. The member name will be present, but the
+// value will be replaced with "sensitive".
+func (s ExecAction) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation.
+//
+// API parameter values that are decorated as "sensitive" in the API will not
+// be included in the string output. The member name will be present, but the
+// value will be replaced with "sensitive".
+func (s ExecAction) GoString() string {
+	return s.String()
+}
+
 type FSxWindowsFileServerAuthorizationConfig struct {
 	_ struct{} `type:"structure"`
 
@@ -1341,6 +1491,32 @@ func (s HostVolumeProperties) GoString() string {
 	return s.String()
 }
 
+type HttpGetAction struct {
+	_ struct{} `type:"structure"`
+
+	Path *string `json:"path,omitempty" type:"string"`
+
+	Port *int64 `json:"port,omitempty" type:"integer"`
+}
+
+// String returns the string representation.
+//
+// API parameter values that are decorated as "sensitive" in the API will not
+// be included in the string output. The member name will be present, but the
+// value will be replaced with "sensitive".
+func (s HttpGetAction) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation.
+//
+// API parameter values that are decorated as "sensitive" in the API will not
+// be included in the string output. The member name will be present, but the
+// value will be replaced with "sensitive".
+func (s HttpGetAction) GoString() string {
+	return s.String()
+}
+
 type IAMRoleCredentials struct {
 	_ struct{} `type:"structure"`
 
@@ -2251,6 +2427,34 @@ func (s PortMapping) GoString() string {
 	return s.String()
 }
 
+type PreStopHook struct {
+	_ struct{} `type:"structure"`
+
+	Exec *ExecAction `json:"exec,omitempty" type:"structure"`
+
+	HttpGet *HttpGetAction `json:"httpGet,omitempty" type:"structure"`
+
+	Timeout *int64 `json:"timeout,omitempty" type:"integer"`
+}
+
+// String returns the string representation.
+//
+// API parameter values that are decorated as "sensitive" in the API will not
+// be included in the string output. The member name will be present, but the
+// value will be replaced with "sensitive".
+func (s PreStopHook) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation.
+//
+// API parameter values that are decorated as "sensitive" in the API will not
+// be included in the string output. The member name will be present, but the
+// value will be replaced with "sensitive".
+func (s PreStopHook) GoString() string {
+	return s.String()
+}
+
 type ProxyConfiguration struct {
 	_ struct{} `type:"structure"`
 
@@ -2279,6 +2483,38 @@ func (s ProxyConfiguration) GoString() string {
 	return s.String()
 }
 
+type ReadinessProbe struct {
+	_ struct{} `type:"structure"`
+
+	Exec *ExecAction `json:"exec,omitempty" type:"structure"`
+
+	HttpGet *HttpGetAction `json:"httpGet,omitempty" type:"structure"`
+
+	Interval *int64 `json:"interval,omitempty" type:"integer"`
+
+	TcpSocket *TcpSocketAction `json:"tcpSocket,omitempty" type:"structure"`
+
+	Timeout *int64 `json:"timeout,omitempty" type:"integer"`
+}
+
+// String returns the string representation.
+//
+// API parameter values that are decorated as "sensitive" in the API will not
+// be included in the string output. The member name will be present, but the
+// value will be replaced with "sensitive".
+func (s ReadinessProbe) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation.
+//
+// API parameter values that are decorated as "sensitive" in the API will not
+// be included in the string output. The member name will be present, but the
+// value will be replaced with "sensitive".
+func (s ReadinessProbe) GoString() string {
+	return s.String()
+}
+
 type RefreshTaskIAMRoleCredentialsInput struct {
 	_ struct{} `type:"structure"`
 
@@ -2375,6 +2611,8 @@ type RestartPolicy struct {
 	IgnoredExitCodes []*int64 `json:"ignoredExitCodes,omitempty" type:"list"`
 
 	RestartAttemptPeriod *int64 `json:"restartAttemptPeriod,omitempty" type:"integer"`
+
+	UnhealthyRestartPeriod *int64 `json:"unhealthyRestartPeriod,omitempty" type:"integer"`
 }
 
 // String returns the string representation.
@@ -2400,6 +2638,10 @@ type Secret struct {
 
 	ContainerPath *string `json:"containerPath,omitempty" type:"string"`
 
+	FileMode *string `json:"fileMode,omitempty" type:"string"`
+
+	Gid *int64 `json:"gid,omitempty" type:"integer"`
+
 	Name *string `json:"name,omitempty" type:"string"`
 
 	Provider *string `json:"provider,omitempty" type:"string" enum:"SecretProvider"`
@@ -2410,6 +2652,8 @@ type Secret struct {
 
 	Type *string `json:"type,omitempty" type:"string" enum:"SecretType"`
 
+	Uid *int64 `json:"uid,omitempty" type:"integer"`
+
 	ValueFrom *string `json:"valueFrom,omitempty" type:"string"`
 }
 
@@ -2599,6 +2843,8 @@ type Task struct {
 
 	DesiredStatus *string `json:"desiredStatus,omitempty" type:"string"`
 
+	EgressPolicy *EgressPolicy `json:"egressPolicy,omitempty" type:"structure"`
+
 	ElasticNetworkInterfaces []*ElasticNetworkInterface `json:"elasticNetworkInterfaces,omitempty" type:"list"`
 
 	EnableFaultInjection *bool `json:"enableFaultInjection,omitempty" type:"boolean"`
@@ -2631,6 +2877,8 @@ type Task struct {
 
 	Version *string `json:"version,omitempty" type:"string"`
 
+	VolumeExportConfiguration *VolumeExportConfiguration `json:"volumeExportConfiguration,omitempty" type:"structure"`
+
 	Volumes []*Volume `json:"volumes,omitempty" type:"list"`
 
 	Zone *string `json:"zone,omitempty" type:"string"`
@@ -2906,6 +3154,30 @@ func (s TaskStopVerificationOutput) GoString() string {
 	return s.String()
 }
 
+type TcpSocketAction struct {
+	_ struct{} `type:"structure"`
+
+	Port *int64 `json:"port,omitempty" type:"integer"`
+}
+
+// String returns the string representation.
+//
+// API parameter values that are decorated as "sensitive" in the API will not
+// be included in the string output. The member name will be present, but the
+// value will be replaced with "sensitive".
+func (s TcpSocketAction) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation.
+//
+// API parameter values that are decorated as "sensitive" in the API will not
+// be included in the string output. The member name will be present, but the
+// value will be replaced with "sensitive".
+func (s TcpSocketAction) GoString() string {
+	return s.String()
+}
+
 type UpdateFailureInput struct {
 	_ struct{} `type:"structure"`
 
@@ -3015,12 +3287,18 @@ func (s VersionInfo) GoString() string {
 type Volume struct {
 	_ struct{} `type:"structure"`
 
+	CsiVolumeConfiguration *CSIVolumeConfiguration `json:"csiVolumeConfiguration,omitempty" type:"structure"`
+
 	DockerVolumeConfiguration *DockerVolumeConfiguration `json:"dockerVolumeConfiguration,omitempty" type:"structure"`
 
 	EbsVolumeConfiguration *EBSVolumeConfiguration `json:"ebsVolumeConfiguration,omitempty" type:"structure"`
 
 	EfsVolumeConfiguration *EFSVolumeConfiguration `json:"efsVolumeConfiguration,omitempty" type:"structure"`
 
+	EphemeralVolumeConfiguration *EphemeralVolumeConfiguration `json:"ephemeralVolumeConfiguration,omitempty" type:"structure"`
+
+	ExportConfiguration *VolumeExportConfiguration `json:"exportConfiguration,omitempty" type:"structure"`
+
 	FsxWindowsFileServerVolumeConfiguration *FSxWindowsFileServerVolumeConfiguration `json:"fsxWindowsFileServerVolumeConfiguration,omitempty" type:"structure"`
 
 	Host *HostVolumeProperties `json:"host,omitempty" type:"structure"`
@@ -3048,6 +3326,32 @@ func (s Volume) GoString() string {
 	return s.String()
 }
 
+type VolumeExportConfiguration struct {
+	_ struct{} `type:"structure"`
+
+	MaxSizeBytes *int64 `json:"maxSizeBytes,omitempty" type:"long"`
+
+	S3Location *string `json:"s3Location,omitempty" type:"string"`
+}
+
+// String returns the string representation.
+//
+// API parameter values that are decorated as "sensitive" in the API will not
+// be included in the string output. The member name will be present, but the
+// value will be replaced with "sensitive".
+func (s VolumeExportConfiguration) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation.
+//
+// API parameter values that are decorated as "sensitive" in the API will not
+// be included in the string output. The member name will be present, but the
+// value will be replaced with "sensitive".
+func (s VolumeExportConfiguration) GoString() string {
+	return s.String()
+}
+
 type VolumeFrom struct {
 	_ struct{} `type:"structure"`
 
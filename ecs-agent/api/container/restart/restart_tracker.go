@@ -21,19 +21,39 @@ import (
 	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
 )
 
+const (
+	// RestartReasonExited is recorded when a container is restarted after it exited on its own.
+	RestartReasonExited = "ContainerExited"
+	// RestartReasonUnhealthy is recorded when a container is restarted after the agent stopped it
+	// for having been UNHEALTHY for longer than the policy's unhealthy restart period.
+	RestartReasonUnhealthy = "ContainerUnhealthy"
+)
+
 type RestartTracker struct {
-	RestartCount  int           `json:"restartCount,omitempty"`
-	LastRestartAt time.Time     `json:"lastRestartAt,omitempty"`
-	RestartPolicy RestartPolicy `json:"restartPolicy,omitempty"`
-	lock          sync.RWMutex
+	RestartCount      int           `json:"restartCount,omitempty"`
+	LastRestartAt     time.Time     `json:"lastRestartAt,omitempty"`
+	LastRestartReason string        `json:"lastRestartReason,omitempty"`
+	RestartPolicy     RestartPolicy `json:"restartPolicy,omitempty"`
+	// pendingRestartReason is the reason for a stop that was initiated by the agent in order to
+	// restart the container. It is consumed by the next call to RecordRestart.
+	pendingRestartReason string
+	lock                 sync.RWMutex
 }
 
 // RestartPolicy represents a policy that contains key information considered when
 // deciding whether or not a container should be restarted after it has exited.
+// When UnhealthyRestartPeriod is set, a container that has been UNHEALTHY for at
+// least that many seconds is also stopped by the agent and restarted.
 type RestartPolicy struct {
-	Enabled              bool  `json:"enabled"`
-	IgnoredExitCodes     []int `json:"ignoredExitCodes"`
-	RestartAttemptPeriod int   `json:"restartAttemptPeriod"`
+	Enabled                bool  `json:"enabled"`
+	IgnoredExitCodes       []int `json:"ignoredExitCodes"`
+	RestartAttemptPeriod   int   `json:"restartAttemptPeriod"`
+	UnhealthyRestartPeriod int   `json:"unhealthyRestartPeriod,omitempty"`
+}
+
+// UnhealthyRestartEnabled returns whether the policy restarts containers that stay UNHEALTHY.
+func (rp RestartPolicy) UnhealthyRestartEnabled() bool {
+	return rp.Enabled && rp.UnhealthyRestartPeriod > 0
 }
 
 func NewRestartTracker(restartPolicy RestartPolicy) *RestartTracker {
@@ -54,6 +74,35 @@ func (rt *RestartTracker) GetRestartCount() int {
 	return rt.RestartCount
 }
 
+func (rt *RestartTracker) GetLastRestartReason() string {
+	rt.lock.RLock()
+	defer rt.lock.RUnlock()
+	return rt.LastRestartReason
+}
+
+// RecordUnhealthyStop marks that the agent is stopping the container because it has been
+// UNHEALTHY for too long, so that the restart following the stop is attributed to it and is
+// not subject to the exit code checks. It returns false if such a stop is already pending, in
+// which case the caller must not stop the container again.
+func (rt *RestartTracker) RecordUnhealthyStop() bool {
+	rt.lock.Lock()
+	defer rt.lock.Unlock()
+	if rt.pendingRestartReason == RestartReasonUnhealthy {
+		return false
+	}
+	rt.pendingRestartReason = RestartReasonUnhealthy
+	return true
+}
+
+// ClearPendingRestart forgets a stop recorded by RecordUnhealthyStop. It is called when the
+// stop failed or the container is not restarted after it, so that a later restart is not
+// attributed to it.
+func (rt *RestartTracker) ClearPendingRestart() {
+	rt.lock.Lock()
+	defer rt.lock.Unlock()
+	rt.pendingRestartReason = ""
+}
+
 // RecordRestart updates the restart tracker's metadata after a restart has occurred.
 // This metadata is used to calculate when restarts should occur and track how many
 // have occurred. It is not the job of this method to determine if a restart should
@@ -63,11 +112,19 @@ func (rt *RestartTracker) RecordRestart() {
 	defer rt.lock.Unlock()
 	rt.RestartCount++
 	rt.LastRestartAt = time.Now()
+	rt.LastRestartReason = RestartReasonExited
+	if rt.pendingRestartReason != "" {
+		rt.LastRestartReason = rt.pendingRestartReason
+		rt.pendingRestartReason = ""
+	}
 }
 
 // ShouldRestart returns whether the container should restart and a reason string
 // explaining why not. The reset attempt period will be calculated first
 // with LastRestart at, using the passed in startedAt if it does not exist.
+// A container stopped by the agent because it was UNHEALTHY is always restarted
+// unless its desired status is stopped: the exit code of the stop is the agent's
+// doing, and the attempt period was already checked before stopping it.
 func (rt *RestartTracker) ShouldRestart(exitCode *int, startedAt time.Time,
 	desiredStatus apicontainerstatus.ContainerStatus) (bool, string) {
 	rt.lock.RLock()
@@ -79,6 +136,9 @@ func (rt *RestartTracker) ShouldRestart(exitCode *int, startedAt time.Time,
 	if desiredStatus == apicontainerstatus.ContainerStopped {
 		return false, "container's desired status is stopped"
 	}
+	if rt.pendingRestartReason == RestartReasonUnhealthy {
+		return true, ""
+	}
 	if exitCode == nil {
 		return false, "exit code is nil"
 	}
@@ -88,12 +148,47 @@ func (rt *RestartTracker) ShouldRestart(exitCode *int, startedAt time.Time,
 		}
 	}
 
-	startTime := startedAt
-	if !rt.LastRestartAt.IsZero() {
-		startTime = rt.LastRestartAt
+	if !rt.attemptPeriodElapsed(startedAt) {
+		return false, "attempt reset period has not elapsed"
+	}
+	return true, ""
+}
+
+// ShouldRestartUnhealthy returns whether a running container should be stopped by the agent
+// so that it gets restarted because it has been UNHEALTHY since unhealthySince, and a reason
+// string explaining why not. The same attempt period used for exited containers applies, so a
+// container is never restarted more often than the policy allows.
+func (rt *RestartTracker) ShouldRestartUnhealthy(healthStatus apicontainerstatus.ContainerHealthStatus,
+	unhealthySince time.Time, startedAt time.Time, desiredStatus apicontainerstatus.ContainerStatus) (bool, string) {
+	rt.lock.RLock()
+	defer rt.lock.RUnlock()
+
+	if !rt.RestartPolicy.UnhealthyRestartEnabled() {
+		return false, "unhealthy restart is not enabled"
+	}
+	if desiredStatus == apicontainerstatus.ContainerStopped {
+		return false, "container's desired status is stopped"
+	}
+	if healthStatus != apicontainerstatus.ContainerUnhealthy {
+		return false, "container is not unhealthy"
 	}
-	if time.Since(startTime).Seconds() < float64(rt.RestartPolicy.RestartAttemptPeriod) {
+	if unhealthySince.IsZero() ||
+		time.Since(unhealthySince).Seconds() < float64(rt.RestartPolicy.UnhealthyRestartPeriod) {
+		return false, "unhealthy restart period has not elapsed"
+	}
+	if !rt.attemptPeriodElapsed(startedAt) {
 		return false, "attempt reset period has not elapsed"
 	}
 	return true, ""
 }
+
+// attemptPeriodElapsed calculates whether the restart attempt period has elapsed since
+// the last restart, or since startedAt if the container has not been restarted yet.
+// The caller must hold the lock.
+func (rt *RestartTracker) attemptPeriodElapsed(startedAt time.Time) bool {
+	startTime := startedAt
+	if !rt.LastRestartAt.IsZero() {
+		startTime = rt.LastRestartAt
+	}
+	return time.Since(startTime).Seconds() >= float64(rt.RestartPolicy.RestartAttemptPeriod)
+}
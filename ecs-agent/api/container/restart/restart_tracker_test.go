@@ -184,3 +184,181 @@ func TestRecordRestartPolicy(t *testing.T) {
 	assert.Equal(t, 0, len(rt.RestartPolicy.IgnoredExitCodes))
 	assert.NotNil(t, rt.RestartPolicy)
 }
+
+func TestShouldRestartUnhealthy(t *testing.T) {
+	unhealthyPolicy := RestartPolicy{
+		Enabled:                true,
+		IgnoredExitCodes:       []int{0},
+		RestartAttemptPeriod:   60,
+		UnhealthyRestartPeriod: 30,
+	}
+	testCases := []struct {
+		name           string
+		rp             RestartPolicy
+		healthStatus   apicontainerstatus.ContainerHealthStatus
+		unhealthySince time.Time
+		startedAt      time.Time
+		desiredStatus  apicontainerstatus.ContainerStatus
+		expected       bool
+		expectedReason string
+	}{
+		{
+			name: "unhealthy restart period not set",
+			rp: RestartPolicy{
+				Enabled:              true,
+				RestartAttemptPeriod: 60,
+			},
+			healthStatus:   apicontainerstatus.ContainerUnhealthy,
+			unhealthySince: time.Now().Add(-time.Hour),
+			startedAt:      time.Now().Add(-time.Hour),
+			desiredStatus:  apicontainerstatus.ContainerRunning,
+			expected:       false,
+			expectedReason: "unhealthy restart is not enabled",
+		},
+		{
+			name: "restart policy disabled",
+			rp: RestartPolicy{
+				Enabled:                false,
+				RestartAttemptPeriod:   60,
+				UnhealthyRestartPeriod: 30,
+			},
+			healthStatus:   apicontainerstatus.ContainerUnhealthy,
+			unhealthySince: time.Now().Add(-time.Hour),
+			startedAt:      time.Now().Add(-time.Hour),
+			desiredStatus:  apicontainerstatus.ContainerRunning,
+			expected:       false,
+			expectedReason: "unhealthy restart is not enabled",
+		},
+		{
+			name:           "desired status stopped",
+			rp:             unhealthyPolicy,
+			healthStatus:   apicontainerstatus.ContainerUnhealthy,
+			unhealthySince: time.Now().Add(-time.Hour),
+			startedAt:      time.Now().Add(-time.Hour),
+			desiredStatus:  apicontainerstatus.ContainerStopped,
+			expected:       false,
+			expectedReason: "container's desired status is stopped",
+		},
+		{
+			name:           "container healthy",
+			rp:             unhealthyPolicy,
+			healthStatus:   apicontainerstatus.ContainerHealthy,
+			unhealthySince: time.Now().Add(-time.Hour),
+			startedAt:      time.Now().Add(-time.Hour),
+			desiredStatus:  apicontainerstatus.ContainerRunning,
+			expected:       false,
+			expectedReason: "container is not unhealthy",
+		},
+		{
+			name:           "unhealthy restart period not elapsed",
+			rp:             unhealthyPolicy,
+			healthStatus:   apicontainerstatus.ContainerUnhealthy,
+			unhealthySince: time.Now().Add(-10 * time.Second),
+			startedAt:      time.Now().Add(-time.Hour),
+			desiredStatus:  apicontainerstatus.ContainerRunning,
+			expected:       false,
+			expectedReason: "unhealthy restart period has not elapsed",
+		},
+		{
+			name:           "attempt reset period not elapsed",
+			rp:             unhealthyPolicy,
+			healthStatus:   apicontainerstatus.ContainerUnhealthy,
+			unhealthySince: time.Now().Add(-time.Minute),
+			startedAt:      time.Now().Add(-50 * time.Second),
+			desiredStatus:  apicontainerstatus.ContainerRunning,
+			expected:       false,
+			expectedReason: "attempt reset period has not elapsed",
+		},
+		{
+			name:           "unhealthy for longer than the period",
+			rp:             unhealthyPolicy,
+			healthStatus:   apicontainerstatus.ContainerUnhealthy,
+			unhealthySince: time.Now().Add(-time.Minute),
+			startedAt:      time.Now().Add(-time.Hour),
+			desiredStatus:  apicontainerstatus.ContainerRunning,
+			expected:       true,
+			expectedReason: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rt := NewRestartTracker(tc.rp)
+			shouldRestart, reason := rt.ShouldRestartUnhealthy(tc.healthStatus, tc.unhealthySince, tc.startedAt,
+				tc.desiredStatus)
+			assert.Equal(t, tc.expected, shouldRestart)
+			assert.Equal(t, tc.expectedReason, reason)
+		})
+	}
+}
+
+func TestRecordRestartReason(t *testing.T) {
+	rt := NewRestartTracker(RestartPolicy{
+		Enabled:                true,
+		RestartAttemptPeriod:   60,
+		UnhealthyRestartPeriod: 30,
+	})
+	assert.Empty(t, rt.GetLastRestartReason())
+
+	rt.RecordRestart()
+	assert.Equal(t, RestartReasonExited, rt.GetLastRestartReason())
+
+	require.True(t, rt.RecordUnhealthyStop())
+	rt.RecordRestart()
+	assert.Equal(t, RestartReasonUnhealthy, rt.GetLastRestartReason())
+	assert.Equal(t, 2, rt.GetRestartCount())
+
+	// The unhealthy reason only applies to the restart following the agent initiated stop.
+	rt.RecordRestart()
+	assert.Equal(t, RestartReasonExited, rt.GetLastRestartReason())
+}
+
+func TestRecordUnhealthyStopOnlyOnce(t *testing.T) {
+	rt := NewRestartTracker(RestartPolicy{
+		Enabled:                true,
+		RestartAttemptPeriod:   60,
+		UnhealthyRestartPeriod: 30,
+	})
+	assert.True(t, rt.RecordUnhealthyStop())
+	assert.False(t, rt.RecordUnhealthyStop())
+
+	rt.ClearPendingRestart()
+	assert.True(t, rt.RecordUnhealthyStop())
+	rt.RecordRestart()
+	assert.True(t, rt.RecordUnhealthyStop())
+}
+
+func TestShouldRestartAfterUnhealthyStop(t *testing.T) {
+	rt := NewRestartTracker(RestartPolicy{
+		Enabled:                true,
+		IgnoredExitCodes:       []int{0, 137},
+		RestartAttemptPeriod:   60,
+		UnhealthyRestartPeriod: 30,
+	})
+	exitCode := 137
+	startedAt := time.Now()
+
+	shouldRestart, reason := rt.ShouldRestart(&exitCode, startedAt, apicontainerstatus.ContainerRunning)
+	assert.False(t, shouldRestart)
+	assert.Equal(t, "exit code 137 should be ignored", reason)
+
+	// The exit code of a stop initiated by the agent does not prevent the restart.
+	require.True(t, rt.RecordUnhealthyStop())
+	shouldRestart, reason = rt.ShouldRestart(&exitCode, startedAt, apicontainerstatus.ContainerRunning)
+	assert.True(t, shouldRestart)
+	assert.Empty(t, reason)
+	shouldRestart, _ = rt.ShouldRestart(nil, startedAt, apicontainerstatus.ContainerRunning)
+	assert.True(t, shouldRestart)
+
+	shouldRestart, reason = rt.ShouldRestart(&exitCode, startedAt, apicontainerstatus.ContainerStopped)
+	assert.False(t, shouldRestart)
+	assert.Equal(t, "container's desired status is stopped", reason)
+}
+
+func TestUnmarshalUnhealthyRestartPeriod(t *testing.T) {
+	rp := RestartPolicy{}
+	err := json.Unmarshal([]byte(`{"enabled": true, "restartAttemptPeriod": 60, "unhealthyRestartPeriod": 120}`), &rp)
+	require.NoError(t, err)
+	assert.Equal(t, 120, rp.UnhealthyRestartPeriod)
+	assert.True(t, rp.UnhealthyRestartEnabled())
+}
@@ -31,6 +31,16 @@ const (
 	V1CredentialsPath = "/v1/credentials"
 	V2CredentialsPath = "/v2/credentials"
 
+	// V2ContainerCredentialsPath is the path to the credentials handler of containers with a role of their own.
+	V2ContainerCredentialsPath = V2CredentialsPath + "/container"
+
+	// V2SessionCredentialsPath is the path to the credentials handler of scoped sessions.
+	V2SessionCredentialsPath = V2CredentialsPath + "/session"
+
+	// CredentialsProcessPathSuffix is the suffix of the paths of the credentials handlers that
+	// return credentials in the output format of a credential_process.
+	CredentialsProcessPathSuffix = "/process"
+
 	// credentialsEndpointRelativeURIFormat defines the relative URI format
 	// for the credentials endpoint. The place holders are the API Path and
 	// credentials ID
@@ -81,6 +91,13 @@ func (roleCredentials *IAMRoleCredentials) GenerateCredentialsEndpointRelativeUR
 	return fmt.Sprintf(credentialsEndpointRelativeURIFormat, CredentialsPath, roleCredentials.CredentialsID)
 }
 
+// GenerateContainerCredentialsEndpointRelativeURI generates the relative URI for the
+// credentials endpoint of a container with a role of its own, for a given container
+// endpoint id.
+func GenerateContainerCredentialsEndpointRelativeURI(endpointContainerID string) string {
+	return fmt.Sprintf(v2CredentialsEndpointRelativeURIFormat, V2ContainerCredentialsPath, endpointContainerID)
+}
+
 // credentialsManager implements the Manager interface. It is used to
 // save credentials sent from ACS and to retrieve credentials from
 // the credentials endpoint
@@ -17,12 +17,14 @@
 package credentials
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 
 	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestIAMRoleCredentialsFromACS tests if credentials sent from ACS can be
@@ -144,6 +146,27 @@ func TestGenerateCredentialsEndpointRelativeURI(t *testing.T) {
 	assert.Equal(t, expectedURI, generatedURI, "Credentials endpoint mismatch")
 }
 
+func TestGenerateContainerCredentialsEndpointRelativeURI(t *testing.T) {
+	assert.Equal(t, "/v2/credentials/container/endpoint-id",
+		GenerateContainerCredentialsEndpointRelativeURI("endpoint-id"))
+}
+
+func TestProcessCredentials(t *testing.T) {
+	credentials := IAMRoleCredentials{
+		RoleArn:         "r1",
+		AccessKeyID:     "akid1",
+		SecretAccessKey: "skid1",
+		SessionToken:    "stkn",
+		Expiration:      "ts",
+		CredentialsID:   "cid1",
+	}
+	processCredentials, err := json.Marshal(credentials.ProcessCredentials())
+	require.NoError(t, err)
+	assert.JSONEq(t,
+		`{"Version":1,"AccessKeyId":"akid1","SecretAccessKey":"skid1","SessionToken":"stkn","Expiration":"ts"}`,
+		string(processCredentials))
+}
+
 // TestRemoveExistingCredentials tests that GetTaskCredentials returns false when
 // credentials are removed from the credentials manager
 func TestRemoveExistingCredentials(t *testing.T) {
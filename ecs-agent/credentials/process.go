@@ -0,0 +1,41 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package credentials
+
+const (
+	// processCredentialsVersion is the version of the output format of a credential_process.
+	processCredentialsVersion = 1
+)
+
+// ProcessCredentials is the representation of role credentials that the AWS SDKs and CLI expect
+// from the output of a credential_process, see
+// https://docs.aws.amazon.com/sdkref/latest/guide/feature-process-credentials.html
+type ProcessCredentials struct {
+	Version         int    `json:"Version"`
+	AccessKeyID     string `json:"AccessKeyId"`
+	SecretAccessKey string `json:"SecretAccessKey"`
+	SessionToken    string `json:"SessionToken"`
+	Expiration      string `json:"Expiration,omitempty"`
+}
+
+// ProcessCredentials returns the credentials in the output format of a credential_process.
+func (roleCredentials *IAMRoleCredentials) ProcessCredentials() ProcessCredentials {
+	return ProcessCredentials{
+		Version:         processCredentialsVersion,
+		AccessKeyID:     roleCredentials.AccessKeyID,
+		SecretAccessKey: roleCredentials.SecretAccessKey,
+		SessionToken:    roleCredentials.SessionToken,
+		Expiration:      roleCredentials.Expiration,
+	}
+}
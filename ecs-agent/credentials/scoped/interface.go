@@ -0,0 +1,57 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package scoped
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
+)
+
+// Manager hands out credentials that are narrower than the task role credentials held by the
+// credentials manager. They are obtained by assuming a role with the task role credentials,
+// either the task role itself with a session policy, or the role of a container.
+type Manager interface {
+	// CreateSession creates a session for the task role credentials with the given id and returns
+	// its token. The token can be exchanged for credentials of the task role that are scoped down
+	// by the session policy, for as long as the task role credentials exist. The task role assumes
+	// itself to get them, so its trust policy must allow the role itself to assume it. Sessions are
+	// kept in memory only: their tokens become invalid when the agent restarts, and sessions have
+	// to be created again.
+	CreateSession(credentialsID string, policy string, duration time.Duration) (string, error)
+	// GetSessionCredentials returns the credentials of the session with the given token. It fails
+	// with ErrSelfAssumeDenied if the trust policy of the task role does not allow it to assume itself.
+	GetSessionCredentials(ctx context.Context, token string) (credentials.TaskIAMRoleCredentials, error)
+	// GetContainerCredentials returns the credentials of the role of the container with the given
+	// endpoint id.
+	GetContainerCredentials(ctx context.Context, endpointContainerID string) (credentials.TaskIAMRoleCredentials, error)
+}
+
+// ContainerRole is the role of a container, which is assumed with the task role credentials of its task.
+type ContainerRole struct {
+	TaskARN       string
+	ContainerName string
+	// CredentialsID is the id of the task role credentials of the task.
+	CredentialsID string
+	RoleARN       string
+}
+
+// ContainerRoleResolver resolves the role of a container from the identity of the container,
+// which is the id of its metadata endpoints.
+type ContainerRoleResolver interface {
+	// ContainerRole returns the role of the container with the given endpoint id, and false
+	// if there is no such container or it has no role of its own.
+	ContainerRole(endpointContainerID string) (ContainerRole, bool)
+}
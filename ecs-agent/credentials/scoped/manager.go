@@ -0,0 +1,359 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package scoped
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/ipcompatibility"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/arn"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
+)
+
+const (
+	// MaximumDuration is the maximum duration of scoped credentials, which is the maximum duration
+	// of the credentials of a role that is assumed with the credentials of another role. It is
+	// also the duration of the credentials when none is requested.
+	MaximumDuration = time.Hour
+	// MinimumDuration is the minimum duration of scoped credentials allowed by STS.
+	MinimumDuration = 15 * time.Minute
+
+	// maximumPolicyLength is the maximum length of a session policy allowed by STS.
+	maximumPolicyLength = 2048
+	// maximumSessionsPerCredentials bounds the number of sessions of the task role credentials of a task.
+	maximumSessionsPerCredentials = 64
+	// sessionTokenBytes is the number of random bytes of a session token.
+	sessionTokenBytes = 32
+	// refreshWindow is how long before their expiration scoped credentials are obtained again.
+	refreshWindow = 5 * time.Minute
+	// assumeRoleTimeout bounds the time to assume a role, the AWS SDKs give up on the
+	// credentials endpoint soon after.
+	assumeRoleTimeout = 5 * time.Second
+	// maximumRoleSessionNameLength is the maximum length of a role session name allowed by STS.
+	maximumRoleSessionNameLength = 64
+	sessionRoleSessionNamePrefix = "ecs-session-"
+	// accessDeniedErrorCode is the error code of STS when the trust policy of a role does not allow
+	// the source credentials to assume it.
+	accessDeniedErrorCode = "AccessDenied"
+)
+
+var (
+	// ErrCredentialsNotFound is returned when there are no task role credentials with the given id.
+	ErrCredentialsNotFound = errors.New("task role credentials not found")
+	// ErrCredentialsUninitialized is returned when the task role credentials have not been
+	// initialized yet, which happens while the agent reconciles its state after a restart.
+	ErrCredentialsUninitialized = errors.New("task role credentials uninitialized")
+	// ErrInvalidSessionToken is returned when a session token is unknown, or its task has stopped.
+	ErrInvalidSessionToken = errors.New("invalid session token")
+	// ErrInvalidSessionRequest is returned when a session cannot be created with the given policy or duration.
+	ErrInvalidSessionRequest = errors.New("invalid session request")
+	// ErrTooManySessions is returned when the task role credentials already have the maximum number of sessions.
+	ErrTooManySessions = errors.New("too many sessions")
+	// ErrContainerRoleNotFound is returned when there is no container with a role of its own for the endpoint id.
+	ErrContainerRoleNotFound = errors.New("container role not found")
+	// ErrSelfAssumeDenied is returned when the credentials of a session cannot be obtained because
+	// the trust policy of the task role does not allow the role to assume itself.
+	ErrSelfAssumeDenied = errors.New("task role is not allowed to assume itself")
+
+	invalidRoleSessionNameCharacters = regexp.MustCompile(`[^\w+=,.@-]`)
+)
+
+// session is a scoped session of the task role credentials of a task.
+type session struct {
+	credentialsID string
+	policy        string
+	duration      time.Duration
+	// cached is the last credentials the session was exchanged for.
+	cached *cachedCredentials
+}
+
+// cachedCredentials are credentials obtained by assuming a role.
+type cachedCredentials struct {
+	roleARN     string
+	credentials credentials.IAMRoleCredentials
+	expiration  time.Time
+}
+
+// fresh returns true if the credentials are for the given role and do not need to be refreshed.
+func (c *cachedCredentials) fresh(roleARN string) bool {
+	return c != nil && c.roleARN == roleARN && time.Until(c.expiration) > refreshWindow
+}
+
+// manager implements the Manager interface.
+type manager struct {
+	credentialsManager credentials.Manager
+	containerRoles     ContainerRoleResolver
+	newSTSClient       stsClientCreator
+
+	lock sync.Mutex
+	// sessions maps session tokens to their session.
+	sessions map[string]*session
+	// containers maps endpoint container ids to the credentials of the role of the container.
+	containers map[string]*cachedCredentials
+}
+
+// NewManager creates a new scoped credentials manager. The roles are assumed with STS in the
+// given region. The container role resolver may be nil if containers have no roles of their own.
+func NewManager(credentialsManager credentials.Manager, containerRoles ContainerRoleResolver,
+	region string, ipCompatibility ipcompatibility.IPCompatibility) Manager {
+	return newManager(credentialsManager, containerRoles, newSTSClientCreator(region, ipCompatibility))
+}
+
+func newManager(credentialsManager credentials.Manager, containerRoles ContainerRoleResolver,
+	newSTSClient stsClientCreator) *manager {
+	return &manager{
+		credentialsManager: credentialsManager,
+		containerRoles:     containerRoles,
+		newSTSClient:       newSTSClient,
+		sessions:           make(map[string]*session),
+		containers:         make(map[string]*cachedCredentials),
+	}
+}
+
+// CreateSession creates a session for the task role credentials with the given id.
+func (m *manager) CreateSession(credentialsID string, policy string, duration time.Duration) (string, error) {
+	if duration == 0 {
+		duration = MaximumDuration
+	}
+	if duration < MinimumDuration || duration > MaximumDuration {
+		return "", fmt.Errorf("%w: duration must be between %s and %s", ErrInvalidSessionRequest,
+			MinimumDuration, MaximumDuration)
+	}
+	if err := validatePolicy(policy); err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidSessionRequest, err)
+	}
+	taskCredentials, ok := m.credentialsManager.GetTaskCredentials(credentialsID)
+	if !ok || taskCredentials.IAMRoleCredentials.RoleType != credentials.ApplicationRoleType {
+		return "", ErrCredentialsNotFound
+	}
+
+	token, err := newSessionToken()
+	if err != nil {
+		return "", err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	sessions := 0
+	for existingToken, existing := range m.sessions {
+		if _, ok := m.credentialsManager.GetTaskCredentials(existing.credentialsID); !ok {
+			// The task of the session has stopped.
+			delete(m.sessions, existingToken)
+			continue
+		}
+		if existing.credentialsID == credentialsID {
+			sessions++
+		}
+	}
+	if sessions >= maximumSessionsPerCredentials {
+		return "", ErrTooManySessions
+	}
+	m.sessions[token] = &session{
+		credentialsID: credentialsID,
+		policy:        policy,
+		duration:      duration,
+	}
+	logger.Info("Created scoped credentials session", logger.Fields{
+		field.TaskARN: taskCredentials.ARN,
+		"duration":    duration.String(),
+	})
+	return token, nil
+}
+
+// GetSessionCredentials returns the credentials of the session with the given token. The sessions
+// are kept in memory only, so the tokens of the sessions created before the agent restarted are
+// invalid.
+func (m *manager) GetSessionCredentials(ctx context.Context,
+	token string) (credentials.TaskIAMRoleCredentials, error) {
+	m.lock.Lock()
+	s, ok := m.sessions[token]
+	m.lock.Unlock()
+	if !ok {
+		return credentials.TaskIAMRoleCredentials{}, ErrInvalidSessionToken
+	}
+
+	taskCredentials, ok := m.credentialsManager.GetTaskCredentials(s.credentialsID)
+	if !ok {
+		m.lock.Lock()
+		delete(m.sessions, token)
+		m.lock.Unlock()
+		return credentials.TaskIAMRoleCredentials{}, ErrInvalidSessionToken
+	}
+	if taskCredentials.IAMRoleCredentials.AccessKeyID == "" {
+		return credentials.TaskIAMRoleCredentials{}, ErrCredentialsUninitialized
+	}
+
+	roleARN := taskCredentials.IAMRoleCredentials.RoleArn
+	m.lock.Lock()
+	cached := s.cached
+	m.lock.Unlock()
+	if !cached.fresh(roleARN) {
+		var err error
+		cached, err = m.assumeRole(ctx, taskCredentials.IAMRoleCredentials, roleARN,
+			roleSessionName(sessionRoleSessionNamePrefix, taskCredentials.ARN), s.policy, s.duration)
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorCode() == accessDeniedErrorCode {
+			// Scoped sessions are obtained by the task role assuming itself, which requires the
+			// role to be trusted by its own trust policy.
+			return credentials.TaskIAMRoleCredentials{}, fmt.Errorf(
+				"%w: the trust policy of role %s must allow the role itself to assume it: %v",
+				ErrSelfAssumeDenied, roleARN, err)
+		}
+		if err != nil {
+			return credentials.TaskIAMRoleCredentials{}, err
+		}
+		m.lock.Lock()
+		s.cached = cached
+		m.lock.Unlock()
+	}
+	return credentials.TaskIAMRoleCredentials{
+		ARN:                taskCredentials.ARN,
+		IAMRoleCredentials: cached.credentials,
+	}, nil
+}
+
+// GetContainerCredentials returns the credentials of the role of the container with the given endpoint id.
+func (m *manager) GetContainerCredentials(ctx context.Context,
+	endpointContainerID string) (credentials.TaskIAMRoleCredentials, error) {
+	if m.containerRoles == nil {
+		return credentials.TaskIAMRoleCredentials{}, ErrContainerRoleNotFound
+	}
+	role, ok := m.containerRoles.ContainerRole(endpointContainerID)
+	if !ok {
+		return credentials.TaskIAMRoleCredentials{}, ErrContainerRoleNotFound
+	}
+	taskCredentials, ok := m.credentialsManager.GetTaskCredentials(role.CredentialsID)
+	if !ok {
+		return credentials.TaskIAMRoleCredentials{}, ErrCredentialsNotFound
+	}
+	if taskCredentials.IAMRoleCredentials.AccessKeyID == "" {
+		return credentials.TaskIAMRoleCredentials{}, ErrCredentialsUninitialized
+	}
+
+	m.lock.Lock()
+	cached := m.containers[endpointContainerID]
+	m.lock.Unlock()
+	if !cached.fresh(role.RoleARN) {
+		var err error
+		cached, err = m.assumeRole(ctx, taskCredentials.IAMRoleCredentials, role.RoleARN,
+			roleSessionName(role.ContainerName+"-", role.TaskARN), "", MaximumDuration)
+		if err != nil {
+			return credentials.TaskIAMRoleCredentials{}, err
+		}
+		m.lock.Lock()
+		for id, existing := range m.containers {
+			// Forget the credentials of containers that have not asked for them in a while,
+			// they are likely stopped.
+			if time.Now().After(existing.expiration) {
+				delete(m.containers, id)
+			}
+		}
+		m.containers[endpointContainerID] = cached
+		m.lock.Unlock()
+	}
+	return credentials.TaskIAMRoleCredentials{
+		ARN:                role.TaskARN,
+		IAMRoleCredentials: cached.credentials,
+	}, nil
+}
+
+// assumeRole assumes the given role with the source credentials.
+func (m *manager) assumeRole(ctx context.Context, source credentials.IAMRoleCredentials, roleARN,
+	sessionName, policy string, duration time.Duration) (*cachedCredentials, error) {
+	ctx, cancel := context.WithTimeout(ctx, assumeRoleTimeout)
+	defer cancel()
+
+	input := &sts.AssumeRoleInput{
+		RoleArn:         aws.String(roleARN),
+		RoleSessionName: aws.String(sessionName),
+		DurationSeconds: aws.Int32(int32(duration / time.Second)),
+	}
+	if policy != "" {
+		input.Policy = aws.String(policy)
+	}
+	output, err := m.newSTSClient(staticCredentialsProvider(source)).AssumeRole(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("unable to assume role %s: %w", roleARN, err)
+	}
+	if output.Credentials == nil || output.Credentials.Expiration == nil {
+		return nil, fmt.Errorf("unable to assume role %s: no credentials in response", roleARN)
+	}
+
+	expiration := aws.ToTime(output.Credentials.Expiration)
+	return &cachedCredentials{
+		roleARN: roleARN,
+		credentials: credentials.IAMRoleCredentials{
+			RoleArn:         roleARN,
+			AccessKeyID:     aws.ToString(output.Credentials.AccessKeyId),
+			SecretAccessKey: aws.ToString(output.Credentials.SecretAccessKey),
+			SessionToken:    aws.ToString(output.Credentials.SessionToken),
+			Expiration:      expiration.UTC().Format(time.RFC3339),
+			RoleType:        credentials.ApplicationRoleType,
+		},
+		expiration: expiration,
+	}, nil
+}
+
+// validatePolicy validates a session policy, which must be a JSON object within the length
+// limit of STS. Whether it is a valid IAM policy is left to STS.
+func validatePolicy(policy string) error {
+	if strings.TrimSpace(policy) == "" {
+		return errors.New("a session policy is required")
+	}
+	if len(policy) > maximumPolicyLength {
+		return fmt.Errorf("the session policy is longer than %d characters", maximumPolicyLength)
+	}
+	var document map[string]interface{}
+	if err := json.Unmarshal([]byte(policy), &document); err != nil {
+		return fmt.Errorf("the session policy is not a JSON object: %v", err)
+	}
+	return nil
+}
+
+func newSessionToken() (string, error) {
+	token := make([]byte, sessionTokenBytes)
+	if _, err := rand.Read(token); err != nil {
+		return "", fmt.Errorf("unable to generate session token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+// roleSessionName returns the role session name of scoped credentials of a task, which
+// identifies the task in CloudTrail.
+func roleSessionName(prefix, taskARN string) string {
+	taskID, err := arn.TaskIdFromArn(taskARN)
+	if err != nil {
+		taskID = taskARN
+	}
+	name := invalidRoleSessionNameCharacters.ReplaceAllString(prefix+taskID, "_")
+	if len(name) > maximumRoleSessionNameLength {
+		name = name[:maximumRoleSessionNameLength]
+	}
+	return name
+}
@@ -0,0 +1,351 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package scoped
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/aws-sdk-go-v2/service/sts/types"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	taskARN        = "arn:aws:ecs:us-west-2:123456789012:task/cluster/0123456789abcdef0123456789abcdef"
+	taskID         = "0123456789abcdef0123456789abcdef"
+	credentialsID  = "credentials-id"
+	taskRoleARN    = "arn:aws:iam::123456789012:role/task"
+	containerRole  = "arn:aws:iam::123456789012:role/sidecar"
+	endpointID     = "endpoint-id"
+	sessionPolicy  = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:GetObject","Resource":"*"}]}`
+	taskAccessKey  = "task-access-key"
+	assumedKeyBase = "assumed-access-key-"
+)
+
+// fakeSTSClient records the AssumeRole calls it gets and the credentials they are signed with.
+type fakeSTSClient struct {
+	inputs      []*sts.AssumeRoleInput
+	signingKeys []string
+	expiration  time.Time
+	err         error
+}
+
+func (f *fakeSTSClient) creator() stsClientCreator {
+	return func(credentialsProvider aws.CredentialsProvider) stsClient {
+		creds, _ := credentialsProvider.Retrieve(context.Background())
+		f.signingKeys = append(f.signingKeys, creds.AccessKeyID)
+		return f
+	}
+}
+
+func (f *fakeSTSClient) AssumeRole(ctx context.Context, input *sts.AssumeRoleInput,
+	optFns ...func(*sts.Options)) (*sts.AssumeRoleOutput, error) {
+	f.inputs = append(f.inputs, input)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &sts.AssumeRoleOutput{
+		Credentials: &types.Credentials{
+			AccessKeyId:     aws.String(assumedKeyBase + aws.ToString(input.RoleSessionName)),
+			SecretAccessKey: aws.String("assumed-secret"),
+			SessionToken:    aws.String("assumed-token"),
+			Expiration:      aws.Time(f.expiration),
+		},
+	}, nil
+}
+
+// fakeContainerRoleResolver resolves the roles of containers from a map.
+type fakeContainerRoleResolver map[string]ContainerRole
+
+func (f fakeContainerRoleResolver) ContainerRole(endpointContainerID string) (ContainerRole, bool) {
+	role, ok := f[endpointContainerID]
+	return role, ok
+}
+
+func newTestCredentialsManager(t *testing.T) credentials.Manager {
+	credentialsManager := credentials.NewManager()
+	require.NoError(t, credentialsManager.SetTaskCredentials(&credentials.TaskIAMRoleCredentials{
+		ARN: taskARN,
+		IAMRoleCredentials: credentials.IAMRoleCredentials{
+			CredentialsID:   credentialsID,
+			RoleArn:         taskRoleARN,
+			AccessKeyID:     taskAccessKey,
+			SecretAccessKey: "task-secret",
+			SessionToken:    "task-token",
+			RoleType:        credentials.ApplicationRoleType,
+		},
+	}))
+	return credentialsManager
+}
+
+func TestCreateSessionValidation(t *testing.T) {
+	credentialsManager := newTestCredentialsManager(t)
+	require.NoError(t, credentialsManager.SetTaskCredentials(&credentials.TaskIAMRoleCredentials{
+		ARN: taskARN,
+		IAMRoleCredentials: credentials.IAMRoleCredentials{
+			CredentialsID: "execution-credentials-id",
+			RoleType:      credentials.ExecutionRoleType,
+		},
+	}))
+	m := newManager(credentialsManager, nil, (&fakeSTSClient{}).creator())
+
+	testCases := []struct {
+		name          string
+		credentialsID string
+		policy        string
+		duration      time.Duration
+		expectedErr   error
+	}{
+		{
+			name:          "no policy",
+			credentialsID: credentialsID,
+			expectedErr:   ErrInvalidSessionRequest,
+		},
+		{
+			name:          "policy is not a JSON object",
+			credentialsID: credentialsID,
+			policy:        `["s3:GetObject"]`,
+			expectedErr:   ErrInvalidSessionRequest,
+		},
+		{
+			name:          "policy is too long",
+			credentialsID: credentialsID,
+			policy:        `{"Version":"` + strings.Repeat("a", maximumPolicyLength) + `"}`,
+			expectedErr:   ErrInvalidSessionRequest,
+		},
+		{
+			name:          "duration is too short",
+			credentialsID: credentialsID,
+			policy:        sessionPolicy,
+			duration:      time.Minute,
+			expectedErr:   ErrInvalidSessionRequest,
+		},
+		{
+			name:          "duration is too long",
+			credentialsID: credentialsID,
+			policy:        sessionPolicy,
+			duration:      2 * time.Hour,
+			expectedErr:   ErrInvalidSessionRequest,
+		},
+		{
+			name:          "unknown credentials",
+			credentialsID: "unknown",
+			policy:        sessionPolicy,
+			expectedErr:   ErrCredentialsNotFound,
+		},
+		{
+			name:          "execution role credentials",
+			credentialsID: "execution-credentials-id",
+			policy:        sessionPolicy,
+			expectedErr:   ErrCredentialsNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := m.CreateSession(tc.credentialsID, tc.policy, tc.duration)
+			assert.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestSessionCredentials(t *testing.T) {
+	credentialsManager := newTestCredentialsManager(t)
+	client := &fakeSTSClient{expiration: time.Now().Add(time.Hour)}
+	m := newManager(credentialsManager, nil, client.creator())
+
+	token, err := m.CreateSession(credentialsID, sessionPolicy, 30*time.Minute)
+	require.NoError(t, err)
+	otherToken, err := m.CreateSession(credentialsID, sessionPolicy, 0)
+	require.NoError(t, err)
+	assert.NotEqual(t, token, otherToken)
+
+	sessionCredentials, err := m.GetSessionCredentials(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, taskARN, sessionCredentials.ARN)
+	assert.Equal(t, taskRoleARN, sessionCredentials.IAMRoleCredentials.RoleArn)
+	assert.Equal(t, assumedKeyBase+"ecs-session-"+taskID, sessionCredentials.IAMRoleCredentials.AccessKeyID)
+	assert.Equal(t, client.expiration.UTC().Format(time.RFC3339), sessionCredentials.IAMRoleCredentials.Expiration)
+	assert.Equal(t, credentials.ApplicationRoleType, sessionCredentials.IAMRoleCredentials.RoleType)
+
+	require.Len(t, client.inputs, 1)
+	assert.Equal(t, taskRoleARN, aws.ToString(client.inputs[0].RoleArn))
+	assert.Equal(t, sessionPolicy, aws.ToString(client.inputs[0].Policy))
+	assert.Equal(t, int32(1800), aws.ToInt32(client.inputs[0].DurationSeconds))
+	assert.Equal(t, []string{taskAccessKey}, client.signingKeys)
+
+	// The credentials are cached until they are about to expire.
+	_, err = m.GetSessionCredentials(context.Background(), token)
+	require.NoError(t, err)
+	assert.Len(t, client.inputs, 1)
+
+	_, err = m.GetSessionCredentials(context.Background(), otherToken)
+	require.NoError(t, err)
+	require.Len(t, client.inputs, 2)
+	assert.Equal(t, int32(3600), aws.ToInt32(client.inputs[1].DurationSeconds))
+
+	_, err = m.GetSessionCredentials(context.Background(), "unknown")
+	assert.ErrorIs(t, err, ErrInvalidSessionToken)
+
+	// The sessions end with the task.
+	credentialsManager.RemoveCredentials(credentialsID)
+	_, err = m.GetSessionCredentials(context.Background(), token)
+	assert.ErrorIs(t, err, ErrInvalidSessionToken)
+	assert.NotContains(t, m.sessions, token)
+}
+
+func TestSessionCredentialsRefresh(t *testing.T) {
+	client := &fakeSTSClient{expiration: time.Now().Add(refreshWindow / 2)}
+	m := newManager(newTestCredentialsManager(t), nil, client.creator())
+
+	token, err := m.CreateSession(credentialsID, sessionPolicy, 0)
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err = m.GetSessionCredentials(context.Background(), token)
+		require.NoError(t, err)
+	}
+	assert.Len(t, client.inputs, 2)
+}
+
+func TestSessionCredentialsAssumeRoleError(t *testing.T) {
+	client := &fakeSTSClient{err: errors.New("access denied")}
+	m := newManager(newTestCredentialsManager(t), nil, client.creator())
+
+	token, err := m.CreateSession(credentialsID, sessionPolicy, 0)
+	require.NoError(t, err)
+	_, err = m.GetSessionCredentials(context.Background(), token)
+	assert.ErrorContains(t, err, "access denied")
+}
+
+func TestSessionCredentialsSelfAssumeDenied(t *testing.T) {
+	client := &fakeSTSClient{err: &smithy.GenericAPIError{
+		Code:    "AccessDenied",
+		Message: "User is not authorized to perform: sts:AssumeRole",
+	}}
+	m := newManager(newTestCredentialsManager(t), nil, client.creator())
+
+	token, err := m.CreateSession(credentialsID, sessionPolicy, 0)
+	require.NoError(t, err)
+	_, err = m.GetSessionCredentials(context.Background(), token)
+	assert.ErrorIs(t, err, ErrSelfAssumeDenied)
+	assert.ErrorContains(t, err, "the trust policy of role "+taskRoleARN+" must allow the role itself to assume it")
+}
+
+func TestSessionCredentialsUninitialized(t *testing.T) {
+	credentialsManager := newTestCredentialsManager(t)
+	m := newManager(credentialsManager, nil, (&fakeSTSClient{}).creator())
+
+	token, err := m.CreateSession(credentialsID, sessionPolicy, 0)
+	require.NoError(t, err)
+	require.NoError(t, credentialsManager.SetTaskCredentials(&credentials.TaskIAMRoleCredentials{
+		ARN: taskARN,
+		IAMRoleCredentials: credentials.IAMRoleCredentials{
+			CredentialsID: credentialsID,
+			RoleType:      credentials.ApplicationRoleType,
+		},
+	}))
+	_, err = m.GetSessionCredentials(context.Background(), token)
+	assert.ErrorIs(t, err, ErrCredentialsUninitialized)
+}
+
+func TestCreateSessionLimit(t *testing.T) {
+	credentialsManager := newTestCredentialsManager(t)
+	m := newManager(credentialsManager, nil, (&fakeSTSClient{}).creator())
+
+	for i := 0; i < maximumSessionsPerCredentials; i++ {
+		_, err := m.CreateSession(credentialsID, sessionPolicy, 0)
+		require.NoError(t, err)
+	}
+	_, err := m.CreateSession(credentialsID, sessionPolicy, 0)
+	assert.ErrorIs(t, err, ErrTooManySessions)
+
+	// The sessions of stopped tasks are forgotten.
+	credentialsManager.RemoveCredentials(credentialsID)
+	require.NoError(t, credentialsManager.SetTaskCredentials(&credentials.TaskIAMRoleCredentials{
+		ARN: taskARN,
+		IAMRoleCredentials: credentials.IAMRoleCredentials{
+			CredentialsID: "other-credentials-id",
+			RoleType:      credentials.ApplicationRoleType,
+		},
+	}))
+	_, err = m.CreateSession("other-credentials-id", sessionPolicy, 0)
+	require.NoError(t, err)
+	assert.Len(t, m.sessions, 1)
+}
+
+func TestContainerCredentials(t *testing.T) {
+	resolver := fakeContainerRoleResolver{
+		endpointID: {
+			TaskARN:       taskARN,
+			ContainerName: "log_router",
+			CredentialsID: credentialsID,
+			RoleARN:       containerRole,
+		},
+	}
+	client := &fakeSTSClient{expiration: time.Now().Add(time.Hour)}
+	m := newManager(newTestCredentialsManager(t), resolver, client.creator())
+
+	for i := 0; i < 2; i++ {
+		containerCredentials, err := m.GetContainerCredentials(context.Background(), endpointID)
+		require.NoError(t, err)
+		assert.Equal(t, taskARN, containerCredentials.ARN)
+		assert.Equal(t, containerRole, containerCredentials.IAMRoleCredentials.RoleArn)
+		assert.Equal(t, assumedKeyBase+"log_router-"+taskID, containerCredentials.IAMRoleCredentials.AccessKeyID)
+	}
+	require.Len(t, client.inputs, 1)
+	assert.Equal(t, containerRole, aws.ToString(client.inputs[0].RoleArn))
+	assert.Nil(t, client.inputs[0].Policy)
+	assert.Equal(t, []string{taskAccessKey}, client.signingKeys)
+
+	_, err := m.GetContainerCredentials(context.Background(), "unknown")
+	assert.ErrorIs(t, err, ErrContainerRoleNotFound)
+}
+
+func TestContainerCredentialsTaskCredentialsNotFound(t *testing.T) {
+	resolver := fakeContainerRoleResolver{
+		endpointID: {
+			TaskARN:       taskARN,
+			CredentialsID: credentialsID,
+			RoleARN:       containerRole,
+		},
+	}
+	m := newManager(credentials.NewManager(), resolver, (&fakeSTSClient{}).creator())
+
+	_, err := m.GetContainerCredentials(context.Background(), endpointID)
+	assert.ErrorIs(t, err, ErrCredentialsNotFound)
+}
+
+func TestContainerCredentialsWithoutResolver(t *testing.T) {
+	m := newManager(newTestCredentialsManager(t), nil, (&fakeSTSClient{}).creator())
+	_, err := m.GetContainerCredentials(context.Background(), endpointID)
+	assert.ErrorIs(t, err, ErrContainerRoleNotFound)
+}
+
+func TestRoleSessionName(t *testing.T) {
+	assert.Equal(t, "ecs-session-"+taskID, roleSessionName(sessionRoleSessionNamePrefix, taskARN))
+	assert.Equal(t, "app-"+taskID, roleSessionName("app-", taskARN))
+	assert.Equal(t, "a_b-not_an_arn", roleSessionName("a b-", "not an arn"))
+	assert.Len(t, roleSessionName(strings.Repeat("c", 60)+"-", taskARN), maximumRoleSessionNameLength)
+}
@@ -0,0 +1,58 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package scoped
+
+import (
+	"context"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/ipcompatibility"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// stsClient is the subset of the STS API used to obtain scoped credentials.
+type stsClient interface {
+	AssumeRole(ctx context.Context, input *sts.AssumeRoleInput,
+		optFns ...func(*sts.Options)) (*sts.AssumeRoleOutput, error)
+}
+
+// stsClientCreator creates an STS client that signs its requests with the given credentials.
+type stsClientCreator func(credentialsProvider aws.CredentialsProvider) stsClient
+
+func newSTSClientCreator(region string, ipCompatibility ipcompatibility.IPCompatibility) stsClientCreator {
+	return func(credentialsProvider aws.CredentialsProvider) stsClient {
+		options := sts.Options{
+			Region:      region,
+			Credentials: credentialsProvider,
+		}
+		if ipCompatibility.IsIPv6Only() {
+			options.EndpointOptions.UseDualStackEndpoint = aws.DualStackEndpointStateEnabled
+		}
+		return sts.New(options)
+	}
+}
+
+// staticCredentialsProvider provides the given task role credentials.
+func staticCredentialsProvider(roleCredentials credentials.IAMRoleCredentials) aws.CredentialsProvider {
+	return aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+		return aws.Credentials{
+			AccessKeyID:     roleCredentials.AccessKeyID,
+			SecretAccessKey: roleCredentials.SecretAccessKey,
+			SessionToken:    roleCredentials.SessionToken,
+			Source:          "TaskRoleCredentials",
+		}, nil
+	})
+}
@@ -53,6 +53,18 @@ type CSIClient interface {
 		fsGroup *int64,
 	) error
 	NodeUnstageVolume(ctx context.Context, volumeId, stagingTargetPath string) error
+	NodePublishVolume(ctx context.Context,
+		volID string,
+		publishContext map[string]string,
+		stagingTargetPath string,
+		targetPath string,
+		fsType string,
+		readOnly bool,
+		secrets map[string]string,
+		volumeContext map[string]string,
+		mountOptions []string,
+	) error
+	NodeUnpublishVolume(ctx context.Context, volumeId, targetPath string) error
 	GetVolumeMetrics(ctx context.Context, volumeId string, hostMountPath string) (*Metrics, error)
 	NodeGetCapabilities(ctx context.Context) (*csi.NodeGetCapabilitiesResponse, error)
 }
@@ -154,6 +166,76 @@ func (cc *csiClient) NodeUnstageVolume(ctx context.Context, volumeId, stagingTar
 	return nil
 }
 
+// NodePublishVolume will mount the given volume to targetPath, from stagingTargetPath if the
+// volume was staged.
+func (cc *csiClient) NodePublishVolume(ctx context.Context,
+	volID string,
+	publishContext map[string]string,
+	stagingTargetPath string,
+	targetPath string,
+	fsType string,
+	readOnly bool,
+	secrets map[string]string,
+	volumeContext map[string]string,
+	mountOptions []string,
+) error {
+	conn, err := cc.grpcDialConnect(ctx)
+	if err != nil {
+		return fmt.Errorf("NodePublishVolume: failed to establish CSI connection: %w", err)
+	}
+	defer conn.Close()
+
+	client := csi.NewNodeClient(conn)
+	req := csi.NodePublishVolumeRequest{
+		VolumeId:          volID,
+		PublishContext:    publishContext,
+		StagingTargetPath: stagingTargetPath,
+		TargetPath:        targetPath,
+		VolumeCapability: &csi.VolumeCapability{
+			AccessType: &csi.VolumeCapability_Mount{
+				Mount: &csi.VolumeCapability_MountVolume{
+					FsType:     fsType,
+					MountFlags: mountOptions,
+				},
+			},
+			AccessMode: &csi.VolumeCapability_AccessMode{
+				Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER,
+			},
+		},
+		Readonly:      readOnly,
+		Secrets:       secrets,
+		VolumeContext: volumeContext,
+	}
+	if readOnly {
+		req.VolumeCapability.AccessMode.Mode = csi.VolumeCapability_AccessMode_SINGLE_NODE_READER_ONLY
+	}
+
+	_, err = client.NodePublishVolume(ctx, &req)
+	if err != nil {
+		return fmt.Errorf("failed to publish volume via CSI driver: %w", err)
+	}
+	return nil
+}
+
+// NodeUnpublishVolume will unmount the given volume from targetPath.
+func (cc *csiClient) NodeUnpublishVolume(ctx context.Context, volumeId, targetPath string) error {
+	conn, err := cc.grpcDialConnect(ctx)
+	if err != nil {
+		return fmt.Errorf("NodeUnpublishVolume: failed to establish CSI connection: %w", err)
+	}
+	defer conn.Close()
+
+	client := csi.NewNodeClient(conn)
+	_, err = client.NodeUnpublishVolume(ctx, &csi.NodeUnpublishVolumeRequest{
+		VolumeId:   volumeId,
+		TargetPath: targetPath,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to unpublish volume via CSI driver: %w", err)
+	}
+	return nil
+}
+
 // GetVolumeMetrics returns volume usage.
 func (cc *csiClient) GetVolumeMetrics(ctx context.Context, volumeId string, hostMountPath string) (*Metrics, error) {
 	conn, err := cc.grpcDialConnect(ctx)
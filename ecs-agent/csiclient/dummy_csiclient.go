@@ -51,6 +51,24 @@ func (c *dummyCSIClient) NodeUnstageVolume(ctx context.Context, volumeId, stagin
 	return nil
 }
 
+func (c *dummyCSIClient) NodePublishVolume(ctx context.Context,
+	volID string,
+	publishContext map[string]string,
+	stagingTargetPath string,
+	targetPath string,
+	fsType string,
+	readOnly bool,
+	secrets map[string]string,
+	volumeContext map[string]string,
+	mountOptions []string,
+) error {
+	return nil
+}
+
+func (c *dummyCSIClient) NodeUnpublishVolume(ctx context.Context, volumeId, targetPath string) error {
+	return nil
+}
+
 func (c *dummyCSIClient) NodeGetCapabilities(ctx context.Context) (*csi.NodeGetCapabilitiesResponse, error) {
 	return &csi.NodeGetCapabilitiesResponse{}, nil
 }
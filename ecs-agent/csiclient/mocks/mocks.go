@@ -81,6 +81,20 @@ func (mr *MockCSIClientMockRecorder) NodeGetCapabilities(arg0 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NodeGetCapabilities", reflect.TypeOf((*MockCSIClient)(nil).NodeGetCapabilities), arg0)
 }
 
+// NodePublishVolume mocks base method.
+func (m *MockCSIClient) NodePublishVolume(arg0 context.Context, arg1 string, arg2 map[string]string, arg3, arg4, arg5 string, arg6 bool, arg7, arg8 map[string]string, arg9 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NodePublishVolume", arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// NodePublishVolume indicates an expected call of NodePublishVolume.
+func (mr *MockCSIClientMockRecorder) NodePublishVolume(arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NodePublishVolume", reflect.TypeOf((*MockCSIClient)(nil).NodePublishVolume), arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9)
+}
+
 // NodeStageVolume mocks base method.
 func (m *MockCSIClient) NodeStageVolume(arg0 context.Context, arg1 string, arg2 map[string]string, arg3, arg4 string, arg5 v1.PersistentVolumeAccessMode, arg6, arg7 map[string]string, arg8 []string, arg9 *int64) error {
 	m.ctrl.T.Helper()
@@ -108,3 +122,17 @@ func (mr *MockCSIClientMockRecorder) NodeUnstageVolume(arg0, arg1, arg2 interfac
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NodeUnstageVolume", reflect.TypeOf((*MockCSIClient)(nil).NodeUnstageVolume), arg0, arg1, arg2)
 }
+
+// NodeUnpublishVolume mocks base method.
+func (m *MockCSIClient) NodeUnpublishVolume(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NodeUnpublishVolume", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// NodeUnpublishVolume indicates an expected call of NodeUnpublishVolume.
+func (mr *MockCSIClientMockRecorder) NodeUnpublishVolume(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NodeUnpublishVolume", reflect.TypeOf((*MockCSIClient)(nil).NodeUnpublishVolume), arg0, arg1, arg2)
+}
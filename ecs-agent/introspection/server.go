@@ -56,10 +56,17 @@ type rootResponse struct {
 
 // Configuration for Introspection Server
 type Config struct {
-	readTimeout        time.Duration // http server read timeout
-	writeTimeout       time.Duration // http server write timeout
-	enableRuntimeStats bool          // enable profiling handlers
-	hideAgentVersion   bool          // if true, do not show Version in metadata
+	readTimeout        time.Duration  // http server read timeout
+	writeTimeout       time.Duration  // http server write timeout
+	enableRuntimeStats bool           // enable profiling handlers
+	hideAgentVersion   bool           // if true, do not show Version in metadata
+	extraHandlers      []extraHandler // additional handlers registered by the caller
+}
+
+// extraHandler is a caller supplied handler served alongside the built-in ones
+type extraHandler struct {
+	path    string
+	handler http.HandlerFunc
 }
 
 // Function type for updating Introspection Server config
@@ -94,6 +101,14 @@ func HideAgentVersion(isHidden bool) ConfigOpt {
 	}
 }
 
+// Register an additional handler on the Introspection Server. The path is
+// included in the list of available commands served at the root path.
+func WithHandler(path string, handler http.HandlerFunc) ConfigOpt {
+	return func(c *Config) {
+		c.extraHandlers = append(c.extraHandlers, extraHandler{path: path, handler: handler})
+	}
+}
+
 // Create a new HTTP Introspection Server
 func NewServer(agentState v1.AgentState, metricsFactory metrics.EntryFactory, options ...ConfigOpt) (*http.Server, error) {
 	config := new(Config)
@@ -134,6 +149,10 @@ func setup(
 
 	paths := []string{handlers.V1AgentMetadataPath, handlers.V1TasksMetadataPath, licensePath}
 
+	for _, h := range config.extraHandlers {
+		paths = append(paths, h.path)
+	}
+
 	if config.enableRuntimeStats {
 		paths = append(paths, pprofBasePath, pprofCMDLinePath, pprofProfilePath, pprofSymbolPath, pprofTracePath)
 	}
@@ -153,6 +172,9 @@ func setup(
 	serveMux.HandleFunc("/", defaultHandler)
 
 	v1HandlersSetup(serveMux, agentState, metricsFactory, config.hideAgentVersion)
+	for _, h := range config.extraHandlers {
+		serveMux.HandleFunc(h.path, h.handler)
+	}
 	wTimeout := config.writeTimeout
 	if config.enableRuntimeStats {
 		pprofHandlerSetup(serveMux)
@@ -203,3 +203,33 @@ func TestPProfHandlerSetup(t *testing.T) {
 		})
 	}
 }
+
+func TestWithHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	agentState := mock_v1.NewMockAgentState(ctrl)
+	metricsFactory := mock_metrics.NewMockEntryFactory(ctrl)
+
+	extraHandler := func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("extra"))
+	}
+	server, err := NewServer(agentState, metricsFactory, WithHandler("/v1/extra", extraHandler))
+	require.NoError(t, err)
+
+	t.Run("handler is served", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/v1/extra", nil)
+		require.NoError(t, err)
+		recorder := httptest.NewRecorder()
+		server.Handler.ServeHTTP(recorder, req)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "extra", recorder.Body.String())
+	})
+
+	t.Run("path is listed in available commands", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/", nil)
+		require.NoError(t, err)
+		recorder := httptest.NewRecorder()
+		server.Handler.ServeHTTP(recorder, req)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, `{"AvailableCommands":["/v1/metadata","/v1/tasks","/license","/v1/extra"]}`, recorder.Body.String())
+	})
+}
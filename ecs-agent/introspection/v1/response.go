@@ -37,12 +37,13 @@ type UnversionedAgentMetadataResponse struct {
 
 // TaskResponse is the schema for the task response JSON object.
 type TaskResponse struct {
-	Arn           string              `json:"Arn"`
-	DesiredStatus string              `json:"DesiredStatus,omitempty"`
-	KnownStatus   string              `json:"KnownStatus"`
-	Family        string              `json:"Family"`
-	Version       string              `json:"Version"`
-	Containers    []ContainerResponse `json:"Containers"`
+	Arn           string                          `json:"Arn"`
+	DesiredStatus string                          `json:"DesiredStatus,omitempty"`
+	KnownStatus   string                          `json:"KnownStatus"`
+	Family        string                          `json:"Family"`
+	Version       string                          `json:"Version"`
+	Containers    []ContainerResponse             `json:"Containers"`
+	VolumeExports []response.VolumeExportResponse `json:"VolumeExports,omitempty"`
 }
 
 // TasksResponse is the schema for the tasks response JSON object.
@@ -24,6 +24,9 @@ const (
 	GetCredentialsEventType                = "GetCredentials"
 	GetCredentialsTaskExecutionEventType   = "GetCredentialsExecutionRole"
 	GetCredentialsInvalidRoleTypeEventType = "GetCredentialsInvalidRoleType"
+	CreateCredentialsSessionEventType      = "CreateCredentialsSession"
+	GetSessionCredentialsEventType         = "GetSessionCredentials"
+	GetContainerCredentialsEventType       = "GetContainerCredentials"
 )
 
 type AuditLogger interface {
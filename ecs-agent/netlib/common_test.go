@@ -17,6 +17,7 @@ import (
 	"fmt"
 
 	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/egresspolicy"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/status"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
@@ -86,6 +87,24 @@ func getSingleNetNSAWSVPCTestData(testTaskID string) (*ecsacs.Task, tasknetworkc
 	return taskPayload, taskNetConfig
 }
 
+// getSingleNetNSEgressPolicyTestData returns the test data for a task with an egress policy.
+func getSingleNetNSEgressPolicyTestData(testTaskID string) (*ecsacs.Task, tasknetworkconfig.TaskNetworkConfig) {
+	taskPayload, taskNetConfig := getSingleNetNSAWSVPCTestData(testTaskID)
+	taskPayload.EgressPolicy = &ecsacs.EgressPolicy{
+		AllowedCidrs: []*string{aws.String("10.1.0.0/16")},
+		AllowedPorts: []*ecsacs.EgressPortRange{
+			{From: aws.Int64(443), To: aws.Int64(443), Protocol: aws.String("tcp")},
+		},
+	}
+	taskNetConfig.NetworkNamespaces[0].EgressPolicy = &egresspolicy.EgressPolicy{
+		AllowedCIDRs:    []string{"10.1.0.0/16"},
+		AllowedDNSNames: []string{},
+		AllowedPorts:    []egresspolicy.PortRange{{From: 443, To: 443, Protocol: egresspolicy.ProtocolTCP}},
+	}
+
+	return taskPayload, taskNetConfig
+}
+
 // getSingleNetNSMultiIfaceWithNameTestData returns the test data for EKS like use cases but with names specified for interfaces.
 func getSingleNetNSMultiIfaceWithNameTestData(testTaskID string) (*ecsacs.Task, tasknetworkconfig.TaskNetworkConfig) {
 	taskPayload, taskNetConfig := getSingleNetNSMultiIfaceAWSVPCTestData(testTaskID)
@@ -63,6 +63,20 @@ func (mr *MockNetworkDataClientMockRecorder) AssignGeneveDstPort(arg0 interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignGeneveDstPort", reflect.TypeOf((*MockNetworkDataClient)(nil).AssignGeneveDstPort), arg0)
 }
 
+// DeleteNetworkNamespace mocks base method.
+func (m *MockNetworkDataClient) DeleteNetworkNamespace(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteNetworkNamespace", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteNetworkNamespace indicates an expected call of DeleteNetworkNamespace.
+func (mr *MockNetworkDataClientMockRecorder) DeleteNetworkNamespace(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNetworkNamespace", reflect.TypeOf((*MockNetworkDataClient)(nil).DeleteNetworkNamespace), arg0)
+}
+
 // GetNetworkNamespace mocks base method.
 func (m *MockNetworkDataClient) GetNetworkNamespace(arg0 string) (*tasknetworkconfig.NetworkNamespace, error) {
 	m.ctrl.T.Helper()
@@ -93,6 +107,21 @@ func (mr *MockNetworkDataClientMockRecorder) GetNetworkNamespacesByTaskID(arg0 i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNetworkNamespacesByTaskID", reflect.TypeOf((*MockNetworkDataClient)(nil).GetNetworkNamespacesByTaskID), arg0)
 }
 
+// ListNetworkNamespaces mocks base method.
+func (m *MockNetworkDataClient) ListNetworkNamespaces() ([]*tasknetworkconfig.NetworkNamespace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListNetworkNamespaces")
+	ret0, _ := ret[0].([]*tasknetworkconfig.NetworkNamespace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListNetworkNamespaces indicates an expected call of ListNetworkNamespaces.
+func (mr *MockNetworkDataClientMockRecorder) ListNetworkNamespaces() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNetworkNamespaces", reflect.TypeOf((*MockNetworkDataClient)(nil).ListNetworkNamespaces))
+}
+
 // ReleaseGeneveDstPort mocks base method.
 func (m *MockNetworkDataClient) ReleaseGeneveDstPort(arg0 uint16, arg1 string) error {
 	m.ctrl.T.Helper()
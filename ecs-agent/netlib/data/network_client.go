@@ -22,6 +22,12 @@ type NetworkDataClient interface {
 	SaveNetworkNamespace(netNS *tasknetworkconfig.NetworkNamespace) error
 	GetNetworkNamespace(netNSName string) (*tasknetworkconfig.NetworkNamespace, error)
 
+	// ListNetworkNamespaces returns every network namespace that has been persisted.
+	ListNetworkNamespaces() ([]*tasknetworkconfig.NetworkNamespace, error)
+
+	// DeleteNetworkNamespace removes the persisted state of a network namespace.
+	DeleteNetworkNamespace(netNSName string) error
+
 	// AssignGeneveDstPort returns an unused destination port number for GENEVE interfaces.
 	// By default for a particular VNI, it will return the default GENEVE destination port - 6081.
 	// In case port 6081 is taken by another interface using the same VNI, it will chose a
@@ -0,0 +1,119 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package dnscache
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// entry is a cached response to a question.
+type entry struct {
+	msg       dnsmessage.Message
+	negative  bool
+	expiresAt time.Time
+}
+
+// cache holds the responses of the upstream name servers until their TTL expires.
+type cache struct {
+	lock       sync.Mutex
+	entries    map[string]*entry
+	maxEntries int
+}
+
+func newCache(maxEntries int) *cache {
+	return &cache{
+		entries:    make(map[string]*entry),
+		maxEntries: maxEntries,
+	}
+}
+
+// cacheKey returns the key of a question. Names are case insensitive.
+func cacheKey(q dnsmessage.Question) string {
+	return strings.ToLower(q.Name.String()) + "/" + q.Type.String() + "/" + q.Class.String()
+}
+
+// get returns the response cached for the key, with the TTL of its records reduced to the time
+// left before the entry expires.
+func (c *cache) get(key string, now time.Time) (dnsmessage.Message, bool, bool) {
+	c.lock.Lock()
+	e, ok := c.entries[key]
+	if ok && !now.Before(e.expiresAt) {
+		delete(c.entries, key)
+		ok = false
+	}
+	c.lock.Unlock()
+	if !ok {
+		return dnsmessage.Message{}, false, false
+	}
+
+	remaining := uint32(e.expiresAt.Sub(now) / time.Second)
+	msg := e.msg
+	msg.Answers = withRemainingTTL(e.msg.Answers, remaining)
+	msg.Authorities = withRemainingTTL(e.msg.Authorities, remaining)
+	msg.Additionals = withRemainingTTL(e.msg.Additionals, remaining)
+	return msg, e.negative, true
+}
+
+// put caches a response for the TTL. When the cache is full, expired entries are dropped first,
+// then an arbitrary entry.
+func (c *cache) put(key string, msg dnsmessage.Message, negative bool, ttl time.Duration, now time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.maxEntries {
+		for k, e := range c.entries {
+			if !now.Before(e.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		for k := range c.entries {
+			if len(c.entries) < c.maxEntries {
+				break
+			}
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = &entry{
+		msg:       msg,
+		negative:  negative,
+		expiresAt: now.Add(ttl),
+	}
+}
+
+// len returns the number of cached responses, including the expired ones not dropped yet.
+func (c *cache) len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.entries)
+}
+
+// withRemainingTTL returns a copy of the records whose TTL does not exceed the remaining time.
+// The OPT pseudo record uses the TTL field for flags, so it is left unchanged.
+func withRemainingTTL(resources []dnsmessage.Resource, remaining uint32) []dnsmessage.Resource {
+	if resources == nil {
+		return nil
+	}
+	out := make([]dnsmessage.Resource, len(resources))
+	copy(out, resources)
+	for i := range out {
+		if out[i].Header.Type != dnsmessage.TypeOPT && out[i].Header.TTL > remaining {
+			out[i].Header.TTL = remaining
+		}
+	}
+	return out
+}
@@ -0,0 +1,76 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package dnscache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestCacheExpiry(t *testing.T) {
+	c := newCache(10)
+	now := time.Now()
+	msg := dnsmessage.Message{
+		Answers: []dnsmessage.Resource{
+			{Header: dnsmessage.ResourceHeader{Type: dnsmessage.TypeA, TTL: 60}},
+		},
+		Additionals: []dnsmessage.Resource{
+			{Header: dnsmessage.ResourceHeader{Type: dnsmessage.TypeOPT, TTL: 1 << 15}},
+		},
+	}
+	c.put("key", msg, false, time.Minute, now)
+
+	cached, negative, ok := c.get("key", now.Add(45*time.Second))
+	assert.True(t, ok)
+	assert.False(t, negative)
+	assert.EqualValues(t, 15, cached.Answers[0].Header.TTL)
+	assert.EqualValues(t, 1<<15, cached.Additionals[0].Header.TTL)
+	// The cached message is not modified.
+	assert.EqualValues(t, 60, msg.Answers[0].Header.TTL)
+
+	_, _, ok = c.get("key", now.Add(time.Minute))
+	assert.False(t, ok)
+	assert.Zero(t, c.len())
+}
+
+func TestCacheEviction(t *testing.T) {
+	c := newCache(2)
+	now := time.Now()
+	c.put("expired", dnsmessage.Message{}, false, time.Second, now)
+	c.put("a", dnsmessage.Message{}, false, time.Minute, now)
+
+	// Expired entries are evicted first.
+	c.put("b", dnsmessage.Message{}, true, time.Minute, now.Add(2*time.Second))
+	_, _, ok := c.get("a", now.Add(2*time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, 2, c.len())
+
+	c.put("c", dnsmessage.Message{}, false, time.Minute, now.Add(2*time.Second))
+	assert.Equal(t, 2, c.len())
+}
+
+func TestCacheKey(t *testing.T) {
+	q := dnsmessage.Question{
+		Name:  dnsmessage.MustNewName("Example.COM."),
+		Type:  dnsmessage.TypeAAAA,
+		Class: dnsmessage.ClassINET,
+	}
+	assert.Equal(t, "example.com./TypeAAAA/ClassINET", cacheKey(q))
+}
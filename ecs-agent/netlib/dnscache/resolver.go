@@ -0,0 +1,497 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package dnscache implements a small caching DNS forwarder. It is started inside task network
+// namespaces so that repeated queries of a task are answered locally instead of being sent to
+// the VPC resolver, which limits the number of packets per second of each network interface.
+package dnscache
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	// DefaultMaxTTL is the default upper bound of the time a response is cached.
+	DefaultMaxTTL = time.Hour
+	// DefaultNegativeTTL is the default upper bound of the time a negative response is cached.
+	DefaultNegativeTTL = 30 * time.Second
+	// DefaultMaxEntries is the default number of responses cached.
+	DefaultMaxEntries = 10000
+	// DefaultUpstreamTimeout is the default time to wait for a response of a name server.
+	DefaultUpstreamTimeout = 2 * time.Second
+
+	dnsPort          = "53"
+	maxMessageSize   = 65535
+	minUDPSize       = 512
+	tcpIdleTimeout   = 10 * time.Second
+	tcpLengthPrefix  = 2
+	messageIDLen     = 2
+	udpReadBufferLen = 4096
+)
+
+// Config contains the settings of a caching resolver.
+type Config struct {
+	// MinTTL is the lower bound of the time a positive response is cached. Responses with a
+	// shorter TTL are cached for MinTTL.
+	MinTTL time.Duration
+	// MaxTTL is the upper bound of the time a positive response is cached.
+	MaxTTL time.Duration
+	// NegativeTTL is the upper bound of the time a response for a name or a record that does
+	// not exist is cached. The TTL is otherwise taken from the SOA record of the response.
+	NegativeTTL time.Duration
+	// MaxEntries is the number of responses the cache can hold.
+	MaxEntries int
+	// UpstreamTimeout is the time to wait for a response of a name server before trying the
+	// next one.
+	UpstreamTimeout time.Duration
+}
+
+// Stats contains the query counters of a caching resolver.
+type Stats struct {
+	// Queries is the number of queries received.
+	Queries uint64
+	// CacheHits is the number of queries answered from the cache, including negative hits.
+	CacheHits uint64
+	// NegativeCacheHits is the number of queries answered from a cached negative response.
+	NegativeCacheHits uint64
+	// CacheMisses is the number of queries forwarded to the name servers.
+	CacheMisses uint64
+	// UpstreamErrors is the number of forwarded queries that no name server answered.
+	UpstreamErrors uint64
+	// CachedEntries is the number of responses currently cached.
+	CachedEntries int
+}
+
+// Dialer opens a connection to a name server. It allows the connections to be opened in the
+// network namespace of the task.
+type Dialer func(ctx context.Context, network, address string) (net.Conn, error)
+
+// Resolver answers DNS queries from its cache, and forwards the queries it cannot answer to the
+// name servers, in order.
+type Resolver struct {
+	config      Config
+	nameServers []string
+	dial        Dialer
+	cache       *cache
+
+	queries           atomic.Uint64
+	cacheHits         atomic.Uint64
+	negativeCacheHits atomic.Uint64
+	cacheMisses       atomic.Uint64
+	upstreamErrors    atomic.Uint64
+
+	lock      sync.Mutex
+	closed    bool
+	closers   map[io.Closer]struct{}
+	waitGroup sync.WaitGroup
+}
+
+// NewResolver creates a caching resolver forwarding to the name servers. Unset settings of the
+// config take their default value.
+func NewResolver(config Config, nameServers []string, dial Dialer) *Resolver {
+	if config.MaxTTL <= 0 {
+		config.MaxTTL = DefaultMaxTTL
+	}
+	if config.MinTTL > config.MaxTTL {
+		config.MinTTL = config.MaxTTL
+	}
+	if config.NegativeTTL <= 0 {
+		config.NegativeTTL = DefaultNegativeTTL
+	}
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = DefaultMaxEntries
+	}
+	if config.UpstreamTimeout <= 0 {
+		config.UpstreamTimeout = DefaultUpstreamTimeout
+	}
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+
+	servers := make([]string, 0, len(nameServers))
+	for _, nameServer := range nameServers {
+		servers = append(servers, net.JoinHostPort(nameServer, dnsPort))
+	}
+	return &Resolver{
+		config:      config,
+		nameServers: servers,
+		dial:        dial,
+		cache:       newCache(config.MaxEntries),
+		closers:     make(map[io.Closer]struct{}),
+	}
+}
+
+// Serve answers the queries received on the UDP connection and the TCP listener until the
+// resolver is closed. The resolver takes ownership of both.
+func (r *Resolver) Serve(udpConn net.PacketConn, tcpListener net.Listener) {
+	r.track(udpConn)
+	r.track(tcpListener)
+	r.waitGroup.Add(2)
+	go r.serveUDP(udpConn)
+	go r.serveTCP(tcpListener)
+}
+
+// Close stops serving queries and waits for the running ones to complete.
+func (r *Resolver) Close() error {
+	r.lock.Lock()
+	r.closed = true
+	closers := r.closers
+	r.closers = make(map[io.Closer]struct{})
+	r.lock.Unlock()
+
+	var err error
+	for closer := range closers {
+		if closeErr := closer.Close(); closeErr != nil {
+			err = closeErr
+		}
+	}
+	r.waitGroup.Wait()
+	return err
+}
+
+// Stats returns the query counters of the resolver.
+func (r *Resolver) Stats() Stats {
+	return Stats{
+		Queries:           r.queries.Load(),
+		CacheHits:         r.cacheHits.Load(),
+		NegativeCacheHits: r.negativeCacheHits.Load(),
+		CacheMisses:       r.cacheMisses.Load(),
+		UpstreamErrors:    r.upstreamErrors.Load(),
+		CachedEntries:     r.cache.len(),
+	}
+}
+
+// Resolve returns the response to a query. Queries that cannot be answered by any name server
+// get a SERVFAIL response, so that the client can move on to its next name server.
+func (r *Resolver) Resolve(ctx context.Context, query []byte) ([]byte, error) {
+	var req dnsmessage.Message
+	if err := req.Unpack(query); err != nil {
+		return nil, errors.Wrap(err, "unable to parse DNS query")
+	}
+	r.queries.Add(1)
+
+	// Only standard queries with a single question are cached, which is all that stub
+	// resolvers send.
+	cacheable := req.Header.OpCode == 0 && len(req.Questions) == 1
+	var key string
+	if cacheable {
+		key = cacheKey(req.Questions[0])
+		if msg, negative, ok := r.cache.get(key, time.Now()); ok {
+			r.cacheHits.Add(1)
+			if negative {
+				r.negativeCacheHits.Add(1)
+			}
+			msg.Header.ID = req.Header.ID
+			msg.Header.RecursionDesired = req.Header.RecursionDesired
+			// The question is echoed as sent, to preserve the case of the name.
+			msg.Questions = req.Questions
+			return msg.Pack()
+		}
+	}
+
+	r.cacheMisses.Add(1)
+	resp, err := r.forward(ctx, query)
+	if err != nil {
+		r.upstreamErrors.Add(1)
+		logger.Warn("Unable to forward DNS query", logger.Fields{
+			field.Error: err,
+		})
+		return serverFailure(req)
+	}
+	if cacheable {
+		r.store(key, resp)
+	}
+	return resp, nil
+}
+
+// store caches a response of a name server. Only successful and NXDOMAIN responses are cached.
+func (r *Resolver) store(key string, resp []byte) {
+	var msg dnsmessage.Message
+	if err := msg.Unpack(resp); err != nil || msg.Header.Truncated {
+		return
+	}
+	if msg.Header.RCode != dnsmessage.RCodeSuccess && msg.Header.RCode != dnsmessage.RCodeNameError {
+		return
+	}
+	ttl, negative := r.responseTTL(msg)
+	if ttl <= 0 {
+		return
+	}
+	r.cache.put(key, msg, negative, ttl, time.Now())
+}
+
+// responseTTL returns how long a response can be cached and whether it is negative, meaning that
+// the name or the record type does not exist.
+func (r *Resolver) responseTTL(msg dnsmessage.Message) (time.Duration, bool) {
+	if msg.Header.RCode == dnsmessage.RCodeNameError || len(msg.Answers) == 0 {
+		// RFC 2308: negative responses are cached for the lower of the TTL of the SOA record
+		// and its minimum field.
+		ttl := r.config.NegativeTTL
+		for _, rr := range msg.Authorities {
+			soa, ok := rr.Body.(*dnsmessage.SOAResource)
+			if !ok {
+				continue
+			}
+			soaTTL := rr.Header.TTL
+			if soa.MinTTL < soaTTL {
+				soaTTL = soa.MinTTL
+			}
+			if d := time.Duration(soaTTL) * time.Second; d < ttl {
+				ttl = d
+			}
+		}
+		return ttl, true
+	}
+
+	ttl := r.config.MaxTTL
+	for _, rr := range msg.Answers {
+		if d := time.Duration(rr.Header.TTL) * time.Second; d < ttl {
+			ttl = d
+		}
+	}
+	if ttl < r.config.MinTTL {
+		ttl = r.config.MinTTL
+	}
+	return ttl, false
+}
+
+// forward sends the query to the name servers in order and returns the first response. A
+// truncated UDP response is retried over TCP with the same name server.
+func (r *Resolver) forward(ctx context.Context, query []byte) ([]byte, error) {
+	if len(r.nameServers) == 0 {
+		return nil, errors.New("no name servers configured")
+	}
+
+	var err error
+	for _, nameServer := range r.nameServers {
+		var resp []byte
+		resp, err = r.exchange(ctx, "udp", nameServer, query)
+		if err == nil && isTruncated(resp) {
+			resp, err = r.exchange(ctx, "tcp", nameServer, query)
+		}
+		if err == nil {
+			return resp, nil
+		}
+	}
+	return nil, err
+}
+
+// exchange sends a query to a name server and reads its response.
+func (r *Resolver) exchange(ctx context.Context, network, nameServer string, query []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.config.UpstreamTimeout)
+	defer cancel()
+
+	conn, err := r.dial(ctx, network, nameServer)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to connect to name server %s", nameServer)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	var resp []byte
+	if network == "tcp" {
+		if err = writeTCPMessage(conn, query); err == nil {
+			resp, err = readTCPMessage(conn)
+		}
+	} else {
+		if _, err = conn.Write(query); err == nil {
+			buf := make([]byte, maxMessageSize)
+			var n int
+			n, err = conn.Read(buf)
+			resp = buf[:n]
+		}
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "DNS exchange with name server %s failed", nameServer)
+	}
+	if len(resp) < messageIDLen || binary.BigEndian.Uint16(resp) != binary.BigEndian.Uint16(query) {
+		return nil, errors.Errorf("unexpected response from name server %s", nameServer)
+	}
+	return resp, nil
+}
+
+func (r *Resolver) serveUDP(conn net.PacketConn) {
+	defer r.waitGroup.Done()
+
+	buf := make([]byte, udpReadBufferLen)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+		query := make([]byte, n)
+		copy(query, buf[:n])
+
+		r.waitGroup.Add(1)
+		go func() {
+			defer r.waitGroup.Done()
+			resp, err := r.Resolve(context.Background(), query)
+			if err != nil {
+				return
+			}
+			if resp, err = truncateForUDP(query, resp); err != nil {
+				return
+			}
+			conn.WriteTo(resp, addr)
+		}()
+	}
+}
+
+func (r *Resolver) serveTCP(listener net.Listener) {
+	defer r.waitGroup.Done()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+		r.waitGroup.Add(1)
+		go func() {
+			defer r.waitGroup.Done()
+			r.serveTCPConn(conn)
+		}()
+	}
+}
+
+// serveTCPConn answers the queries of a TCP connection until the client closes it or stays idle.
+func (r *Resolver) serveTCPConn(conn net.Conn) {
+	if !r.track(conn) {
+		return
+	}
+	defer r.untrack(conn)
+
+	for {
+		conn.SetDeadline(time.Now().Add(tcpIdleTimeout))
+		query, err := readTCPMessage(conn)
+		if err != nil {
+			return
+		}
+		resp, err := r.Resolve(context.Background(), query)
+		if err != nil {
+			return
+		}
+		if err := writeTCPMessage(conn, resp); err != nil {
+			return
+		}
+	}
+}
+
+// track registers a connection to close when the resolver is closed. It returns false if the
+// resolver is already closed.
+func (r *Resolver) track(closer io.Closer) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.closed {
+		closer.Close()
+		return false
+	}
+	r.closers[closer] = struct{}{}
+	return true
+}
+
+// untrack closes a connection and stops tracking it.
+func (r *Resolver) untrack(closer io.Closer) {
+	r.lock.Lock()
+	delete(r.closers, closer)
+	r.lock.Unlock()
+	closer.Close()
+}
+
+// truncateForUDP replaces a response that does not fit in the UDP payload size of the client by
+// an empty response with the TC bit set, so that the client retries over TCP.
+func truncateForUDP(query, resp []byte) ([]byte, error) {
+	var req dnsmessage.Message
+	if err := req.Unpack(query); err != nil {
+		return nil, err
+	}
+	size := minUDPSize
+	for _, rr := range req.Additionals {
+		if rr.Header.Type == dnsmessage.TypeOPT && int(rr.Header.Class) > size {
+			size = int(rr.Header.Class)
+		}
+	}
+	if len(resp) <= size {
+		return resp, nil
+	}
+
+	var msg dnsmessage.Message
+	if err := msg.Unpack(resp); err != nil {
+		return nil, err
+	}
+	msg.Header.Truncated = true
+	msg.Answers, msg.Authorities, msg.Additionals = nil, nil, nil
+	return msg.Pack()
+}
+
+// serverFailure returns a SERVFAIL response to the query.
+func serverFailure(req dnsmessage.Message) ([]byte, error) {
+	msg := dnsmessage.Message{
+		Header: dnsmessage.Header{
+			ID:                 req.Header.ID,
+			Response:           true,
+			OpCode:             req.Header.OpCode,
+			RecursionDesired:   req.Header.RecursionDesired,
+			RecursionAvailable: true,
+			RCode:              dnsmessage.RCodeServerFailure,
+		},
+		Questions: req.Questions,
+	}
+	return msg.Pack()
+}
+
+// isTruncated returns whether the TC bit of a DNS message is set.
+func isTruncated(msg []byte) bool {
+	var p dnsmessage.Parser
+	header, err := p.Start(msg)
+	return err == nil && header.Truncated
+}
+
+func readTCPMessage(conn net.Conn) ([]byte, error) {
+	var length [tcpLengthPrefix]byte
+	if _, err := io.ReadFull(conn, length[:]); err != nil {
+		return nil, err
+	}
+	msg := make([]byte, binary.BigEndian.Uint16(length[:]))
+	if _, err := io.ReadFull(conn, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func writeTCPMessage(conn net.Conn, msg []byte) error {
+	buf := make([]byte, tcpLengthPrefix+len(msg))
+	binary.BigEndian.PutUint16(buf, uint16(len(msg)))
+	copy(buf[tcpLengthPrefix:], msg)
+	_, err := conn.Write(buf)
+	return err
+}
@@ -0,0 +1,293 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package dnscache
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	testName        = "example.com."
+	testMissingName = "missing.example.com."
+)
+
+// fakeNameServer answers A queries for testName and NXDOMAIN for any other name over UDP.
+type fakeNameServer struct {
+	conn    net.PacketConn
+	queries atomic.Int32
+	ttl     uint32
+}
+
+func newFakeNameServer(t *testing.T, ttl uint32) *fakeNameServer {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	ns := &fakeNameServer{conn: conn, ttl: ttl}
+	go ns.serve()
+	return ns
+}
+
+func (ns *fakeNameServer) serve() {
+	buf := make([]byte, maxMessageSize)
+	for {
+		n, addr, err := ns.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		ns.queries.Add(1)
+		var req dnsmessage.Message
+		if err := req.Unpack(buf[:n]); err != nil {
+			continue
+		}
+		resp := dnsmessage.Message{
+			Header:    dnsmessage.Header{ID: req.Header.ID, Response: true, RecursionAvailable: true},
+			Questions: req.Questions,
+		}
+		if req.Questions[0].Name.String() == testName {
+			resp.Answers = []dnsmessage.Resource{{
+				Header: dnsmessage.ResourceHeader{
+					Name:  req.Questions[0].Name,
+					Type:  dnsmessage.TypeA,
+					Class: dnsmessage.ClassINET,
+					TTL:   ns.ttl,
+				},
+				Body: &dnsmessage.AResource{A: [4]byte{10, 0, 0, 1}},
+			}}
+		} else {
+			resp.Header.RCode = dnsmessage.RCodeNameError
+			resp.Authorities = []dnsmessage.Resource{{
+				Header: dnsmessage.ResourceHeader{
+					Name:  dnsmessage.MustNewName("example.com."),
+					Type:  dnsmessage.TypeSOA,
+					Class: dnsmessage.ClassINET,
+					TTL:   900,
+				},
+				Body: &dnsmessage.SOAResource{
+					NS:     dnsmessage.MustNewName("ns.example.com."),
+					MBox:   dnsmessage.MustNewName("admin.example.com."),
+					MinTTL: 10,
+				},
+			}}
+		}
+		out, _ := resp.Pack()
+		ns.conn.WriteTo(out, addr)
+	}
+}
+
+// dialTo returns a dialer that connects to the fake name server, whatever the address asked for.
+func dialTo(ns *fakeNameServer) Dialer {
+	return func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, ns.conn.LocalAddr().String())
+	}
+}
+
+func newQuery(t *testing.T, id uint16, name string) []byte {
+	msg := dnsmessage.Message{
+		Header: dnsmessage.Header{ID: id, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{
+			Name:  dnsmessage.MustNewName(name),
+			Type:  dnsmessage.TypeA,
+			Class: dnsmessage.ClassINET,
+		}},
+	}
+	query, err := msg.Pack()
+	require.NoError(t, err)
+	return query
+}
+
+func unpack(t *testing.T, resp []byte) dnsmessage.Message {
+	var msg dnsmessage.Message
+	require.NoError(t, msg.Unpack(resp))
+	return msg
+}
+
+func TestResolverCachesResponses(t *testing.T) {
+	ns := newFakeNameServer(t, 60)
+	resolver := NewResolver(Config{}, []string{"10.0.0.2"}, dialTo(ns))
+
+	for id := uint16(1); id <= 3; id++ {
+		resp, err := resolver.Resolve(context.TODO(), newQuery(t, id, testName))
+		require.NoError(t, err)
+		msg := unpack(t, resp)
+		assert.Equal(t, id, msg.Header.ID)
+		require.Len(t, msg.Answers, 1)
+		assert.LessOrEqual(t, msg.Answers[0].Header.TTL, uint32(60))
+	}
+	assert.EqualValues(t, 1, ns.queries.Load())
+
+	// Negative responses are cached too.
+	for id := uint16(4); id <= 5; id++ {
+		resp, err := resolver.Resolve(context.TODO(), newQuery(t, id, testMissingName))
+		require.NoError(t, err)
+		assert.Equal(t, dnsmessage.RCodeNameError, unpack(t, resp).Header.RCode)
+	}
+	assert.EqualValues(t, 2, ns.queries.Load())
+
+	assert.Equal(t, Stats{
+		Queries:           5,
+		CacheHits:         3,
+		NegativeCacheHits: 1,
+		CacheMisses:       2,
+		CachedEntries:     2,
+	}, resolver.Stats())
+}
+
+func TestResolverNameServerFailure(t *testing.T) {
+	ns := newFakeNameServer(t, 60)
+	var dials atomic.Int32
+	failFirst := func(ctx context.Context, network, address string) (net.Conn, error) {
+		if dials.Add(1) == 1 {
+			return nil, errors.New("connection refused")
+		}
+		return dialTo(ns)(ctx, network, address)
+	}
+
+	// The next name server is used when the first one fails.
+	resolver := NewResolver(Config{}, []string{"10.0.0.2", "10.0.0.3"}, failFirst)
+	resp, err := resolver.Resolve(context.TODO(), newQuery(t, 1, testName))
+	require.NoError(t, err)
+	assert.Len(t, unpack(t, resp).Answers, 1)
+
+	// Clients get a SERVFAIL response when no name server answers, which is not cached.
+	resolver = NewResolver(Config{}, []string{"10.0.0.2"},
+		func(context.Context, string, string) (net.Conn, error) { return nil, errors.New("unreachable") })
+	for i := 0; i < 2; i++ {
+		resp, err = resolver.Resolve(context.TODO(), newQuery(t, 2, testName))
+		require.NoError(t, err)
+		assert.Equal(t, dnsmessage.RCodeServerFailure, unpack(t, resp).Header.RCode)
+	}
+	assert.EqualValues(t, 2, resolver.Stats().UpstreamErrors)
+	assert.Zero(t, resolver.Stats().CachedEntries)
+
+	_, err = resolver.Resolve(context.TODO(), []byte("not a dns message"))
+	assert.Error(t, err)
+}
+
+func TestResolverResponseTTL(t *testing.T) {
+	resolver := NewResolver(Config{
+		MinTTL:      30 * time.Second,
+		MaxTTL:      5 * time.Minute,
+		NegativeTTL: 5 * time.Second,
+	}, nil, nil)
+	answer := func(ttl uint32) dnsmessage.Message {
+		return dnsmessage.Message{Answers: []dnsmessage.Resource{{Header: dnsmessage.ResourceHeader{TTL: ttl}}}}
+	}
+
+	testCases := []struct {
+		name             string
+		msg              dnsmessage.Message
+		expectedTTL      time.Duration
+		expectedNegative bool
+	}{
+		{"within bounds", answer(60), time.Minute, false},
+		{"below min TTL", answer(1), 30 * time.Second, false},
+		{"above max TTL", answer(86400), 5 * time.Minute, false},
+		{"no data without SOA", dnsmessage.Message{}, 5 * time.Second, true},
+		{
+			name: "NXDOMAIN with SOA",
+			msg: dnsmessage.Message{
+				Header: dnsmessage.Header{RCode: dnsmessage.RCodeNameError},
+				Authorities: []dnsmessage.Resource{{
+					Header: dnsmessage.ResourceHeader{TTL: 2},
+					Body:   &dnsmessage.SOAResource{MinTTL: 60},
+				}},
+			},
+			expectedTTL:      2 * time.Second,
+			expectedNegative: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ttl, negative := resolver.responseTTL(tc.msg)
+			assert.Equal(t, tc.expectedTTL, ttl)
+			assert.Equal(t, tc.expectedNegative, negative)
+		})
+	}
+}
+
+func TestResolverServe(t *testing.T) {
+	ns := newFakeNameServer(t, 60)
+	resolver := NewResolver(Config{}, []string{"10.0.0.2"}, dialTo(ns))
+
+	udpConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	tcpListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	resolver.Serve(udpConn, tcpListener)
+
+	conn, err := net.Dial("udp", udpConn.LocalAddr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	_, err = conn.Write(newQuery(t, 7, testName))
+	require.NoError(t, err)
+	buf := make([]byte, minUDPSize)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(7), unpack(t, buf[:n]).Header.ID)
+
+	tcpConn, err := net.Dial("tcp", tcpListener.Addr().String())
+	require.NoError(t, err)
+	tcpConn.SetDeadline(time.Now().Add(5 * time.Second))
+	require.NoError(t, writeTCPMessage(tcpConn, newQuery(t, 8, testName)))
+	resp, err := readTCPMessage(tcpConn)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(8), unpack(t, resp).Header.ID)
+
+	// Closing the resolver closes the open client connections too.
+	require.NoError(t, resolver.Close())
+	_, err = readTCPMessage(tcpConn)
+	assert.Error(t, err)
+	assert.EqualValues(t, 1, ns.queries.Load())
+}
+
+func TestTruncateForUDP(t *testing.T) {
+	query := newQuery(t, 1, testName)
+	msg := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: 1, Response: true},
+		Questions: unpack(t, query).Questions,
+	}
+	for i := 0; i < 40; i++ {
+		msg.Answers = append(msg.Answers, dnsmessage.Resource{
+			Header: dnsmessage.ResourceHeader{
+				Name:  msg.Questions[0].Name,
+				Type:  dnsmessage.TypeA,
+				Class: dnsmessage.ClassINET,
+			},
+			Body: &dnsmessage.AResource{A: [4]byte{10, 0, 0, byte(i)}},
+		})
+	}
+	resp, err := msg.Pack()
+	require.NoError(t, err)
+	require.Greater(t, len(resp), minUDPSize)
+
+	truncated, err := truncateForUDP(query, resp)
+	require.NoError(t, err)
+	out := unpack(t, truncated)
+	assert.True(t, out.Header.Truncated)
+	assert.Empty(t, out.Answers)
+}
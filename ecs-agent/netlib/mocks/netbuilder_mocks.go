@@ -23,6 +23,9 @@ import (
 	reflect "reflect"
 
 	ecsacs "github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
+	netlib "github.com/aws/amazon-ecs-agent/ecs-agent/netlib"
+	dnscache "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/dnscache"
+	egresspolicy "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/egresspolicy"
 	tasknetworkconfig "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
 	types "github.com/aws/aws-sdk-go-v2/service/ecs/types"
 	gomock "github.com/golang/mock/gomock"
@@ -66,6 +69,51 @@ func (mr *MockNetworkBuilderMockRecorder) BuildTaskNetworkConfiguration(arg0, ar
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BuildTaskNetworkConfiguration", reflect.TypeOf((*MockNetworkBuilder)(nil).BuildTaskNetworkConfiguration), arg0, arg1)
 }
 
+// GetDNSCacheStats mocks base method.
+func (m *MockNetworkBuilder) GetDNSCacheStats(arg0 *tasknetworkconfig.NetworkNamespace) (*dnscache.Stats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDNSCacheStats", arg0)
+	ret0, _ := ret[0].(*dnscache.Stats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDNSCacheStats indicates an expected call of GetDNSCacheStats.
+func (mr *MockNetworkBuilderMockRecorder) GetDNSCacheStats(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDNSCacheStats", reflect.TypeOf((*MockNetworkBuilder)(nil).GetDNSCacheStats), arg0)
+}
+
+// GetEgressPolicyStats mocks base method.
+func (m *MockNetworkBuilder) GetEgressPolicyStats(arg0 context.Context, arg1 *tasknetworkconfig.NetworkNamespace) (*egresspolicy.Stats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEgressPolicyStats", arg0, arg1)
+	ret0, _ := ret[0].(*egresspolicy.Stats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEgressPolicyStats indicates an expected call of GetEgressPolicyStats.
+func (mr *MockNetworkBuilderMockRecorder) GetEgressPolicyStats(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEgressPolicyStats", reflect.TypeOf((*MockNetworkBuilder)(nil).GetEgressPolicyStats), arg0, arg1)
+}
+
+// Reconcile mocks base method.
+func (m *MockNetworkBuilder) Reconcile(arg0 context.Context, arg1 func() ([]string, error)) (*netlib.ReconcileReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reconcile", arg0, arg1)
+	ret0, _ := ret[0].(*netlib.ReconcileReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reconcile indicates an expected call of Reconcile.
+func (mr *MockNetworkBuilderMockRecorder) Reconcile(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reconcile", reflect.TypeOf((*MockNetworkBuilder)(nil).Reconcile), arg0, arg1)
+}
+
 // Start mocks base method.
 func (m *MockNetworkBuilder) Start(arg0 context.Context, arg1 types.NetworkMode, arg2 string, arg3 *tasknetworkconfig.NetworkNamespace) error {
 	m.ctrl.T.Helper()
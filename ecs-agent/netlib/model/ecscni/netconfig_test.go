@@ -36,3 +36,17 @@ func TestBuildNetworkConfig(t *testing.T) {
 	require.Equal(t, DefaultInterfaceName, rt.IfName)
 	require.Equal(t, "container-id", rt.ContainerID)
 }
+
+func TestBuildRuntimeConfigForPortMap(t *testing.T) {
+	cfg := &PortMapConfig{
+		CNIConfig: CNIConfig{
+			NetNSPath: "nspath",
+		},
+		ID: "task-netns",
+	}
+
+	rt := BuildRuntimeConfig(cfg)
+	require.Equal(t, "nspath", rt.NetNS)
+	require.Equal(t, DefaultInterfaceName, rt.IfName)
+	require.Equal(t, "task-netns", rt.ContainerID)
+}
@@ -0,0 +1,74 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecscni
+
+import (
+	"fmt"
+
+	cnitypes "github.com/containernetworking/cni/pkg/types/100"
+)
+
+// PortMapConfig defines the configuration for the portmap plugin, which publishes
+// ports of a network namespace on the host.
+type PortMapConfig struct {
+	CNIConfig
+	// SNAT enables masquerading of hairpin traffic to the published ports.
+	SNAT bool `json:"snat"`
+	// RuntimeConfig holds the port mappings to publish.
+	RuntimeConfig PortMapRuntimeConfig `json:"runtimeConfig"`
+	// PrevResult is the result of the plugin that configured the interface whose
+	// addresses the ports are mapped to. It is required by the ADD command.
+	PrevResult *cnitypes.Result `json:"prevResult,omitempty"`
+	// ID uniquely identifies the network namespace. The portmap plugin uses it to
+	// name the iptables chains of the namespace, so it must not be shared.
+	ID string `json:"-"`
+}
+
+// PortMapRuntimeConfig defines the runtime configuration of the portmap plugin.
+type PortMapRuntimeConfig struct {
+	PortMappings []PortMapEntry `json:"portMappings"`
+}
+
+// PortMapEntry defines a single port published by the portmap plugin.
+type PortMapEntry struct {
+	HostPort      int    `json:"hostPort"`
+	ContainerPort int    `json:"containerPort"`
+	Protocol      string `json:"protocol"`
+}
+
+func (pc *PortMapConfig) String() string {
+	return fmt.Sprintf("%s, snat: %t, portMappings: %v", pc.CNIConfig.String(), pc.SNAT, pc.RuntimeConfig.PortMappings)
+}
+
+// InterfaceName returns the name of the interface the ports are mapped to.
+func (pc *PortMapConfig) InterfaceName() string {
+	return DefaultInterfaceName
+}
+
+func (pc *PortMapConfig) NSPath() string {
+	return pc.NetNSPath
+}
+
+func (pc *PortMapConfig) CNIVersion() string {
+	return pc.CNISpecVersion
+}
+
+func (pc *PortMapConfig) PluginName() string {
+	return pc.CNIPluginName
+}
+
+// ContainerID returns the unique identifier of the network namespace.
+func (pc *PortMapConfig) ContainerID() string {
+	return pc.ID
+}
@@ -0,0 +1,124 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package egresspolicy
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"strings"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ProtocolTCP is the protocol of a TCP port range.
+	ProtocolTCP = "tcp"
+	// ProtocolUDP is the protocol of a UDP port range.
+	ProtocolUDP = "udp"
+)
+
+// EgressPolicy restricts the outbound traffic of a task network namespace to an allow list.
+// Connections to destinations that are not allowed are rejected inside the network namespace,
+// in addition to whatever the security groups of the task ENI permit.
+//
+// Loopback traffic, replies to inbound connections and DNS queries to the name servers of
+// the task are always allowed.
+type EgressPolicy struct {
+	// AllowedCIDRs is the list of IPv4 and IPv6 destinations the task can connect to.
+	AllowedCIDRs []string
+	// AllowedDNSNames is the list of host names the task can connect to. The names are
+	// resolved when the policy is enforced, so later changes to their records are not applied.
+	AllowedDNSNames []string
+	// AllowedPorts restricts the destination ports of the allowed destinations. All ports
+	// are allowed when the list is empty.
+	AllowedPorts []PortRange
+}
+
+// PortRange is an inclusive range of destination ports.
+type PortRange struct {
+	From     uint16
+	To       uint16
+	Protocol string
+}
+
+// Stats contains the counters of an enforced egress policy.
+type Stats struct {
+	// DeniedConnections is the number of connection attempts rejected by the policy.
+	DeniedConnections uint64
+	// DeniedBytes is the number of bytes in the rejected connection attempts.
+	DeniedBytes uint64
+}
+
+// NewEgressPolicy returns the egress policy of a task payload, or nil if the task has none.
+func NewEgressPolicy(acsPolicy *ecsacs.EgressPolicy) (*EgressPolicy, error) {
+	if acsPolicy == nil {
+		return nil, nil
+	}
+
+	policy := &EgressPolicy{
+		AllowedCIDRs:    aws.ToStringSlice(acsPolicy.AllowedCidrs),
+		AllowedDNSNames: aws.ToStringSlice(acsPolicy.AllowedDnsNames),
+	}
+	for _, ports := range acsPolicy.AllowedPorts {
+		from, to := aws.ToInt64(ports.From), aws.ToInt64(ports.To)
+		if from < 0 || from > math.MaxUint16 || to < 0 || to > math.MaxUint16 {
+			return nil, errors.Errorf("invalid egress policy port range %d-%d", from, to)
+		}
+		policy.AllowedPorts = append(policy.AllowedPorts, PortRange{
+			From:     uint16(from),
+			To:       uint16(to),
+			Protocol: strings.ToLower(aws.ToString(ports.Protocol)),
+		})
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// Validate returns an error if the policy contains a malformed entry.
+func (p *EgressPolicy) Validate() error {
+	for _, cidr := range p.AllowedCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return errors.Wrapf(err, "invalid egress policy CIDR %s", cidr)
+		}
+	}
+	for _, name := range p.AllowedDNSNames {
+		if strings.TrimSpace(name) == "" {
+			return errors.New("invalid egress policy DNS name: empty name")
+		}
+	}
+	for _, ports := range p.AllowedPorts {
+		if ports.From == 0 || ports.From > ports.To {
+			return errors.Errorf("invalid egress policy port range %s", ports)
+		}
+		if ports.Protocol != ProtocolTCP && ports.Protocol != ProtocolUDP {
+			return errors.Errorf("invalid egress policy protocol %q, expected %s or %s",
+				ports.Protocol, ProtocolTCP, ProtocolUDP)
+		}
+	}
+	return nil
+}
+
+// String returns the port range in the format understood by iptables.
+func (pr PortRange) String() string {
+	if pr.From == pr.To {
+		return fmt.Sprintf("%d", pr.From)
+	}
+	return fmt.Sprintf("%d:%d", pr.From, pr.To)
+}
@@ -0,0 +1,116 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package egresspolicy
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEgressPolicyValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		policy  EgressPolicy
+		isValid bool
+	}{
+		{
+			name: "valid policy",
+			policy: EgressPolicy{
+				AllowedCIDRs:    []string{"10.0.0.0/16", "2001:db8::/32"},
+				AllowedDNSNames: []string{"example.com"},
+				AllowedPorts: []PortRange{
+					{From: 443, To: 443, Protocol: ProtocolTCP},
+					{From: 8000, To: 8100, Protocol: ProtocolUDP},
+				},
+			},
+			isValid: true,
+		},
+		{
+			name:    "empty policy",
+			isValid: true,
+		},
+		{
+			name:   "address without prefix",
+			policy: EgressPolicy{AllowedCIDRs: []string{"10.0.0.1"}},
+		},
+		{
+			name:   "empty dns name",
+			policy: EgressPolicy{AllowedDNSNames: []string{" "}},
+		},
+		{
+			name:   "reversed port range",
+			policy: EgressPolicy{AllowedPorts: []PortRange{{From: 443, To: 80, Protocol: ProtocolTCP}}},
+		},
+		{
+			name:   "zero port",
+			policy: EgressPolicy{AllowedPorts: []PortRange{{Protocol: ProtocolTCP}}},
+		},
+		{
+			name:   "unknown protocol",
+			policy: EgressPolicy{AllowedPorts: []PortRange{{From: 443, To: 443, Protocol: "sctp"}}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.policy.Validate()
+			if tc.isValid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestPortRangeString(t *testing.T) {
+	assert.Equal(t, "443", PortRange{From: 443, To: 443}.String())
+	assert.Equal(t, "8000:8100", PortRange{From: 8000, To: 8100}.String())
+}
+
+func TestNewEgressPolicy(t *testing.T) {
+	policy, err := NewEgressPolicy(nil)
+	require.NoError(t, err)
+	assert.Nil(t, policy)
+
+	policy, err = NewEgressPolicy(&ecsacs.EgressPolicy{
+		AllowedCidrs:    []*string{aws.String("10.0.0.0/16")},
+		AllowedDnsNames: []*string{aws.String("example.com")},
+		AllowedPorts: []*ecsacs.EgressPortRange{
+			{From: aws.Int64(443), To: aws.Int64(443), Protocol: aws.String("TCP")},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &EgressPolicy{
+		AllowedCIDRs:    []string{"10.0.0.0/16"},
+		AllowedDNSNames: []string{"example.com"},
+		AllowedPorts:    []PortRange{{From: 443, To: 443, Protocol: ProtocolTCP}},
+	}, policy)
+
+	_, err = NewEgressPolicy(&ecsacs.EgressPolicy{
+		AllowedPorts: []*ecsacs.EgressPortRange{
+			{From: aws.Int64(443), To: aws.Int64(70000), Protocol: aws.String("tcp")},
+		},
+	})
+	assert.Error(t, err)
+
+	_, err = NewEgressPolicy(&ecsacs.EgressPolicy{AllowedCidrs: []*string{aws.String("10.0.0.0")}})
+	assert.Error(t, err)
+}
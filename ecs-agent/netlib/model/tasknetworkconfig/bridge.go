@@ -0,0 +1,151 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tasknetworkconfig
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/pkg/errors"
+)
+
+const (
+	// PortMappingProtocolTCP is the default protocol of a port mapping.
+	PortMappingProtocolTCP = "tcp"
+	// PortMappingProtocolUDP is the protocol of a UDP port mapping.
+	PortMappingProtocolUDP = "udp"
+)
+
+// BridgeConfig holds the bridge mode parameters of a task network namespace.
+type BridgeConfig struct {
+	// Hostname is the hostname of the task inside the network namespace.
+	Hostname string
+	// IPv4Address is the address assigned to the task's veth interface by IPAM,
+	// in CIDR notation. It is empty until the interface has been configured.
+	IPv4Address string
+	// PortMappings are the container ports published on the host.
+	PortMappings []PortMapping
+}
+
+// PortMapping maps a port on the host to a port inside the task network namespace.
+type PortMapping struct {
+	ContainerPort uint16
+	// HostPort is the port published on the host. A zero value means that the host
+	// port has not been assigned, and the mapping is not published.
+	HostPort uint16
+	Protocol string
+}
+
+// HostPortAllocator allocates numberOfPorts contiguous host ports for protocol to the
+// container port, or container port range, identified by key. It returns the first
+// allocated port.
+type HostPortAllocator func(key string, numberOfPorts int, protocol string) (uint16, error)
+
+// NewBridgeConfig creates the bridge mode parameters of a task from the port
+// mappings of its containers. The host ports of dynamic mappings, which don't specify
+// one, and of container port ranges are assigned by allocateHostPorts. An error is
+// returned for such mappings if allocateHostPorts is nil.
+func NewBridgeConfig(
+	hostname string,
+	containers []*ecsacs.Container,
+	allocateHostPorts HostPortAllocator,
+) (*BridgeConfig, error) {
+	cfg := &BridgeConfig{
+		Hostname: hostname,
+	}
+	for _, container := range containers {
+		for _, pm := range container.PortMappings {
+			if pm == nil {
+				continue
+			}
+			protocol := strings.ToLower(aws.ToString(pm.Protocol))
+			if protocol == "" {
+				protocol = PortMappingProtocolTCP
+			}
+
+			var containerPort, numberOfPorts uint16
+			var containerPortKey string
+			hostPort := uint16(aws.ToInt64(pm.HostPort))
+			switch {
+			case pm.ContainerPort != nil:
+				containerPort, numberOfPorts = uint16(aws.ToInt64(pm.ContainerPort)), 1
+				containerPortKey = strconv.Itoa(int(containerPort))
+			case pm.ContainerPortRange != nil:
+				containerPortKey = aws.ToString(pm.ContainerPortRange)
+				start, end, err := parsePortRange(containerPortKey)
+				if err != nil {
+					return nil, err
+				}
+				// Host ports are always allocated for a container port range.
+				containerPort, numberOfPorts, hostPort = start, end-start+1, 0
+			default:
+				continue
+			}
+
+			if hostPort == 0 {
+				if allocateHostPorts == nil {
+					return nil, errors.Errorf(
+						"host port of container %s port %s is not set, and host ports cannot be allocated",
+						aws.ToString(container.Name), containerPortKey)
+				}
+				key := strings.Join([]string{aws.ToString(container.Name), containerPortKey, protocol}, "/")
+				var err error
+				hostPort, err = allocateHostPorts(key, int(numberOfPorts), protocol)
+				if err != nil {
+					return nil, errors.Wrapf(err, "unable to allocate host port of container %s port %s",
+						aws.ToString(container.Name), containerPortKey)
+				}
+			}
+			for i := uint16(0); i < numberOfPorts; i++ {
+				cfg.PortMappings = append(cfg.PortMappings, PortMapping{
+					ContainerPort: containerPort + i,
+					HostPort:      hostPort + i,
+					Protocol:      protocol,
+				})
+			}
+		}
+	}
+	return cfg, nil
+}
+
+// parsePortRange parses a port range in the "start-end" format.
+func parsePortRange(portRange string) (uint16, uint16, error) {
+	startStr, endStr, _ := strings.Cut(portRange, "-")
+	start, err := strconv.ParseUint(startStr, 10, 16)
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "invalid port range %s", portRange)
+	}
+	end, err := strconv.ParseUint(endStr, 10, 16)
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "invalid port range %s", portRange)
+	}
+	if start == 0 || start > end {
+		return 0, 0, errors.Errorf("invalid port range %s", portRange)
+	}
+	return uint16(start), uint16(end), nil
+}
+
+// PublishedPortMappings returns the port mappings that have a host port assigned.
+func (bc *BridgeConfig) PublishedPortMappings() []PortMapping {
+	var published []PortMapping
+	for _, pm := range bc.PortMappings {
+		if pm.HostPort != 0 {
+			published = append(published, pm)
+		}
+	}
+	return published
+}
@@ -0,0 +1,109 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tasknetworkconfig
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBridgeConfig(t *testing.T) {
+	containers := []*ecsacs.Container{
+		{
+			Name: aws.String("app"),
+			PortMappings: []*ecsacs.PortMapping{
+				{ContainerPort: aws.Int64(80), HostPort: aws.Int64(8080)},
+				{ContainerPort: aws.Int64(53), Protocol: aws.String("UDP")},
+				{ContainerPortRange: aws.String("9000-9002")},
+				nil,
+			},
+		},
+		{},
+	}
+
+	var allocated []string
+	allocateHostPorts := func(key string, numberOfPorts int, protocol string) (uint16, error) {
+		allocated = append(allocated, fmt.Sprintf("%s:%d", key, numberOfPorts))
+		if numberOfPorts == 1 {
+			return 32768, nil
+		}
+		return 33000, nil
+	}
+
+	cfg, err := NewBridgeConfig("task-id", containers, allocateHostPorts)
+	require.NoError(t, err)
+	assert.Equal(t, "task-id", cfg.Hostname)
+	assert.Empty(t, cfg.IPv4Address)
+	expected := []PortMapping{
+		{ContainerPort: 80, HostPort: 8080, Protocol: PortMappingProtocolTCP},
+		{ContainerPort: 53, HostPort: 32768, Protocol: PortMappingProtocolUDP},
+		{ContainerPort: 9000, HostPort: 33000, Protocol: PortMappingProtocolTCP},
+		{ContainerPort: 9001, HostPort: 33001, Protocol: PortMappingProtocolTCP},
+		{ContainerPort: 9002, HostPort: 33002, Protocol: PortMappingProtocolTCP},
+	}
+	assert.Equal(t, expected, cfg.PortMappings)
+	assert.Equal(t, expected, cfg.PublishedPortMappings())
+	assert.Equal(t, []string{"app/53/udp:1", "app/9000-9002/tcp:3"}, allocated)
+}
+
+func TestNewBridgeConfigErrors(t *testing.T) {
+	allocationErr := errors.New("no free host port")
+	testCases := []struct {
+		name              string
+		portMapping       *ecsacs.PortMapping
+		allocateHostPorts HostPortAllocator
+	}{
+		{
+			name:        "dynamic host port without allocator",
+			portMapping: &ecsacs.PortMapping{ContainerPort: aws.Int64(80)},
+		},
+		{
+			name:        "port range without allocator",
+			portMapping: &ecsacs.PortMapping{ContainerPortRange: aws.String("9000-9002")},
+		},
+		{
+			name:        "allocation failure",
+			portMapping: &ecsacs.PortMapping{ContainerPort: aws.Int64(80)},
+			allocateHostPorts: func(string, int, string) (uint16, error) {
+				return 0, allocationErr
+			},
+		},
+		{
+			name:        "invalid port range",
+			portMapping: &ecsacs.PortMapping{ContainerPortRange: aws.String("9002-9000")},
+			allocateHostPorts: func(string, int, string) (uint16, error) {
+				return 33000, nil
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			containers := []*ecsacs.Container{
+				{Name: aws.String("app"), PortMappings: []*ecsacs.PortMapping{tc.portMapping}},
+			}
+			_, err := NewBridgeConfig("task-id", containers, tc.allocateHostPorts)
+			assert.Error(t, err)
+		})
+	}
+}
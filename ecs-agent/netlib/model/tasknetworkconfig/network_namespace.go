@@ -19,6 +19,7 @@ import (
 
 	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/appmesh"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/egresspolicy"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/serviceconnect"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/status"
@@ -39,6 +40,14 @@ type NetworkNamespace struct {
 	// ServiceConnectConfig holds ServiceConnect related parameters for the particular netns.
 	ServiceConnectConfig *serviceconnect.ServiceConnectConfig
 
+	// BridgeConfig holds bridge mode parameters for the particular netns. It is set only
+	// for tasks in bridge network mode.
+	BridgeConfig *BridgeConfig
+
+	// EgressPolicy restricts the outbound traffic of the particular netns. It is enforced
+	// in addition to the security groups of the network interfaces.
+	EgressPolicy *egresspolicy.EgressPolicy
+
 	KnownState   status.NetworkStatus
 	DesiredState status.NetworkStatus
 
@@ -22,6 +22,8 @@ import (
 	"github.com/aws/amazon-ecs-agent/ecs-agent/metrics"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/data"
 	netlibdata "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/data"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/dnscache"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/egresspolicy"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/status"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/platform"
@@ -39,12 +41,24 @@ type NetworkBuilder interface {
 	Start(ctx context.Context, mode types.NetworkMode, taskID string, netNS *tasknetworkconfig.NetworkNamespace) error
 
 	Stop(ctx context.Context, mode types.NetworkMode, taskID string, netNS *tasknetworkconfig.NetworkNamespace) error
+
+	// GetEgressPolicyStats returns the counters of the egress policy enforced in the network namespace.
+	GetEgressPolicyStats(ctx context.Context, netNS *tasknetworkconfig.NetworkNamespace) (*egresspolicy.Stats, error)
+
+	// GetDNSCacheStats returns the query counters of the caching DNS resolver running in the
+	// network namespace.
+	GetDNSCacheStats(netNS *tasknetworkconfig.NetworkNamespace) (*dnscache.Stats, error)
+
+	// Reconcile cleans up the network resources left behind by tasks that are no longer running,
+	// as reported by activeTaskIDs, and returns what was found.
+	Reconcile(ctx context.Context, activeTaskIDs func() ([]string, error)) (*ReconcileReport, error)
 }
 
 type networkBuilder struct {
 	platformAPI    platform.API
 	metricsFactory metrics.EntryFactory
 	networkDAO     netlibdata.NetworkDataClient
+	taskLocks      taskLocks
 }
 
 func NewNetworkBuilder(
@@ -91,6 +105,7 @@ func (nb *networkBuilder) Start(
 	}
 	metricEntry := nb.metricsFactory.New(metrics.BuildNetworkNamespaceMetricName).WithFields(logFields)
 
+	defer nb.taskLocks.lock(taskID)()
 	netNS.Mutex.Lock()
 	defer netNS.Mutex.Unlock()
 
@@ -100,6 +115,8 @@ func (nb *networkBuilder) Start(
 	switch mode {
 	case types.NetworkModeAwsvpc:
 		err = nb.startAWSVPC(ctx, taskID, netNS)
+	case types.NetworkModeBridge:
+		err = nb.startBridge(ctx, taskID, netNS)
 	case types.NetworkModeHost:
 		err = nb.platformAPI.HandleHostMode()
 	default:
@@ -112,6 +129,16 @@ func (nb *networkBuilder) Start(
 }
 
 func (nb *networkBuilder) Stop(ctx context.Context, mode types.NetworkMode, taskID string, netNS *tasknetworkconfig.NetworkNamespace) error {
+	if taskID == "" {
+		taskID = netNSTaskID(netNS.Name)
+	}
+	defer nb.taskLocks.lock(taskID)()
+
+	return nb.stop(ctx, mode, netNS)
+}
+
+// stop tears down the network namespace. The caller holds the lock of its task.
+func (nb *networkBuilder) stop(ctx context.Context, mode types.NetworkMode, netNS *tasknetworkconfig.NetworkNamespace) error {
 	logFields := map[string]interface{}{
 		"NetworkMode":           mode,
 		"NetNSName":             netNS.Name,
@@ -130,6 +157,8 @@ func (nb *networkBuilder) Stop(ctx context.Context, mode types.NetworkMode, task
 	switch mode {
 	case types.NetworkModeAwsvpc:
 		err = nb.stopAWSVPC(ctx, netNS)
+	case types.NetworkModeBridge:
+		err = nb.stopBridge(ctx, netNS)
 	case types.NetworkModeHost:
 		err = nb.platformAPI.HandleHostMode()
 	default:
@@ -141,6 +170,23 @@ func (nb *networkBuilder) Stop(ctx context.Context, mode types.NetworkMode, task
 	return err
 }
 
+// GetEgressPolicyStats returns the counters of the egress policy enforced in the network namespace.
+func (nb *networkBuilder) GetEgressPolicyStats(
+	ctx context.Context,
+	netNS *tasknetworkconfig.NetworkNamespace,
+) (*egresspolicy.Stats, error) {
+	netNS.Mutex.Lock()
+	defer netNS.Mutex.Unlock()
+
+	return nb.platformAPI.GetEgressPolicyStats(ctx, netNS)
+}
+
+// GetDNSCacheStats returns the query counters of the caching DNS resolver running in the
+// network namespace.
+func (nb *networkBuilder) GetDNSCacheStats(netNS *tasknetworkconfig.NetworkNamespace) (*dnscache.Stats, error) {
+	return nb.platformAPI.GetDNSCacheStats(netNS)
+}
+
 // startAWSVPC executes the required platform API methods in order to configure
 // the task's network namespace running in AWSVPC mode.
 func (nb *networkBuilder) startAWSVPC(ctx context.Context, taskID string, netNS *tasknetworkconfig.NetworkNamespace) error {
@@ -200,6 +246,19 @@ func (nb *networkBuilder) startAWSVPC(ctx context.Context, taskID string, netNS
 				return errors.Wrapf(err, "failed to configure ServiceConnect in netns %s", netNS.Name)
 			}
 		}
+
+		// The egress policy is enforced last so that it does not interfere with the
+		// configuration of the netns.
+		if netNS.EgressPolicy != nil {
+			logger.Debug("Configuring egress policy", logger.Fields{
+				"EgressPolicy": netNS.EgressPolicy,
+			})
+
+			err = nb.platformAPI.ConfigureEgressPolicy(ctx, netNS)
+			if err != nil {
+				return errors.Wrapf(err, "failed to configure egress policy in netns %s", netNS.Name)
+			}
+		}
 	}
 
 	return err
@@ -258,6 +317,14 @@ func (nb *networkBuilder) stopAWSVPC(ctx context.Context, netNS *tasknetworkconf
 	logFields := logger.Fields{
 		"NetNSName": netNS.Name,
 	}
+	if netNS.EgressPolicy != nil {
+		err := nb.platformAPI.ConfigureEgressPolicy(ctx, netNS)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to remove egress policy: %v", err), logFields)
+			errs = multierror.Append(err, errs)
+		}
+	}
+
 	err := nb.configureNetNSInterfaces(ctx, netNS)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to cleanup interfaces in netns: %v", err), logFields)
@@ -278,3 +345,86 @@ func (nb *networkBuilder) stopAWSVPC(ctx context.Context, netNS *tasknetworkconf
 
 	return errs
 }
+
+// startBridge executes the required platform API methods in order to configure
+// the task's network namespace running in bridge mode.
+func (nb *networkBuilder) startBridge(ctx context.Context, taskID string, netNS *tasknetworkconfig.NetworkNamespace) error {
+	if netNS.DesiredState == status.NetworkDeleted {
+		return errors.New("invalid transition state encountered: " + netNS.DesiredState.String())
+	}
+
+	// Bridge mode tasks are fully configured in the first transition. There is
+	// nothing left to do once the netns has been connected to the task bridge.
+	if netNS.KnownState != status.NetworkNone ||
+		netNS.DesiredState != status.NetworkReadyPull {
+		return nil
+	}
+
+	logger.Debug("Creating netns: " + netNS.Path)
+	err := nb.platformAPI.CreateNetNS(netNS.Path)
+	if err != nil {
+		return err
+	}
+
+	// The DNS config files reference the address assigned on the bridge, so the
+	// netns has to be connected to the bridge first.
+	err = nb.platformAPI.ConfigureBridge(ctx, netNS)
+	if err != nil {
+		// The netns is not saved yet, so nothing else would ever delete it.
+		if delErr := nb.platformAPI.DeleteNetNS(netNS.Path); delErr != nil {
+			logger.Error(fmt.Sprintf("Failed to delete netns after bridge configuration failure: %v", delErr),
+				logger.Fields{"NetNSName": netNS.Name})
+		}
+		return errors.Wrapf(err, "failed to configure bridge in netns %s", netNS.Name)
+	}
+
+	logger.Debug("Creating DNS config files")
+	err = nb.platformAPI.CreateDNSConfig(taskID, netNS)
+	if err == nil {
+		// Save the address assigned to the task, which is needed to clean up the netns.
+		err = nb.networkDAO.SaveNetworkNamespace(netNS)
+	}
+	if err != nil {
+		// The netns is not saved, so disconnect it from the bridge and delete it
+		// the same way a stopped task's netns is.
+		desiredState := netNS.DesiredState
+		netNS.DesiredState = status.NetworkDeleted
+		if cleanupErr := nb.stopBridge(ctx, netNS); cleanupErr != nil {
+			logger.Error(fmt.Sprintf("Failed to clean up netns after bridge setup failure: %v", cleanupErr),
+				logger.Fields{"NetNSName": netNS.Name})
+		}
+		netNS.DesiredState = desiredState
+		return err
+	}
+	return nil
+}
+
+func (nb *networkBuilder) stopBridge(ctx context.Context, netNS *tasknetworkconfig.NetworkNamespace) error {
+	var errs error
+	if netNS.DesiredState != status.NetworkDeleted {
+		return errors.New("invalid transition state encountered: " + netNS.DesiredState.String())
+	}
+
+	logFields := logger.Fields{
+		"NetNSName": netNS.Name,
+	}
+	err := nb.platformAPI.ConfigureBridge(ctx, netNS)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to disconnect netns from the task bridge: %v", err), logFields)
+		errs = multierror.Append(err, errs)
+	}
+
+	err = nb.platformAPI.DeleteDNSConfig(netNS.Name)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to cleanup DNS config files: %v", err), logFields)
+		errs = multierror.Append(err, errs)
+	}
+
+	err = nb.platformAPI.DeleteNetNS(netNS.Path)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to delete network namespace: %v", err), logFields)
+		errs = multierror.Append(err, errs)
+	}
+
+	return errs
+}
@@ -19,6 +19,7 @@ package netlib
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"testing"
@@ -28,6 +29,7 @@ import (
 	mock_metrics "github.com/aws/amazon-ecs-agent/ecs-agent/metrics/mocks"
 	mock_data "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/data/mocks"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/appmesh"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/egresspolicy"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/serviceconnect"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/status"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
@@ -61,6 +63,7 @@ func TestNetworkBuilder_BuildTaskNetworkConfiguration(t *testing.T) {
 	t.Run("containerd-multi-interface", getTestFunc(getSingleNetNSMultiIfaceAWSVPCTestData, platform.WarmpoolPlatform))
 	t.Run("containerd-multi-interface-with-names", getTestFunc(getSingleNetNSMultiIfaceWithNameTestData, platform.WarmpoolPlatform))
 	t.Run("containerd-multi-netns", getTestFunc(getMultiNetNSMultiIfaceAWSVPCTestData, platform.WarmpoolPlatform))
+	t.Run("containerd-egress-policy", getTestFunc(getSingleNetNSEgressPolicyTestData, platform.WarmpoolPlatform))
 
 	t.Run("managed-linux-default", getTestFunc(getMultiNetNSMultiIfaceAWSVPCTestData, platform.ManagedPlatform))
 
@@ -70,11 +73,13 @@ func TestNetworkBuilder_BuildTaskNetworkConfiguration(t *testing.T) {
 
 func TestNetworkBuilder_Start(t *testing.T) {
 	t.Run("awsvpc", testNetworkBuilder_StartAWSVPC)
+	t.Run("bridge", testNetworkBuilder_StartBridge)
 }
 
-// TestNetworkBuilder_Stop verifies stop workflow for AWSVPC mode.
+// TestNetworkBuilder_Stop verifies stop workflow for AWSVPC and bridge modes.
 func TestNetworkBuilder_Stop(t *testing.T) {
 	t.Run("awsvpc", testNetworkBuilder_StopAWSVPC)
+	t.Run("bridge", testNetworkBuilder_StopBridge)
 }
 
 // getTestFunc returns a test function that verifies the capability of the networkBuilder
@@ -229,6 +234,19 @@ func testNetworkBuilder_StartAWSVPC(t *testing.T) {
 		netBuilder.Start(ctx, types.NetworkModeAwsvpc, taskID, netNS)
 	})
 
+	// Single ENI with an egress policy and desired state = READY.
+	// In this case, the egress policy should be enforced after ServiceConnect.
+	netNS.EgressPolicy = &egresspolicy.EgressPolicy{
+		AllowedCIDRs: []string{"10.0.0.0/16"},
+	}
+	mockEntry = mock_metrics.NewMockEntry(ctrl)
+	t.Run("single-eni-egresspolicy-ready", func(*testing.T) {
+		gomock.InOrder(
+			getExpectedCalls_StartAWSVPC(ctx, platformAPI, metricsFactory, mockEntry, netDao, netNS)...,
+		)
+		netBuilder.Start(ctx, types.NetworkModeAwsvpc, taskID, netNS)
+	})
+
 	// Single netns with multi interface case.
 	_, taskNetConfig = getSingleNetNSMultiIfaceAWSVPCTestData(taskID)
 	netNS = taskNetConfig.GetPrimaryNetNS()
@@ -310,6 +328,9 @@ func getExpectedCalls_StartAWSVPC(
 			calls = append(calls, platformAPI.EXPECT().ConfigureServiceConnect(ctx, netNS.Path,
 				netNS.GetPrimaryInterface(), netNS.ServiceConnectConfig).Return(nil).Times(1))
 		}
+		if netNS.EgressPolicy != nil {
+			calls = append(calls, platformAPI.EXPECT().ConfigureEgressPolicy(ctx, netNS).Return(nil).Times(1))
+		}
 	}
 
 	calls = append(calls, mockEntry.EXPECT().Done(nil).Times(1))
@@ -327,9 +348,11 @@ func testNetworkBuilder_StopAWSVPC(t *testing.T) {
 	platformAPI := mock_platform.NewMockAPI(ctrl)
 	metricsFactory := mock_metrics.NewMockEntryFactory(ctrl)
 	mockEntry := mock_metrics.NewMockEntry(ctrl)
+	netDao := mock_data.NewMockNetworkDataClient(ctrl)
 	netBuilder := &networkBuilder{
 		platformAPI:    platformAPI,
 		metricsFactory: metricsFactory,
+		networkDAO:     netDao,
 	}
 
 	// Single ENI use case without AppMesh and service connect configs.
@@ -338,7 +361,22 @@ func testNetworkBuilder_StopAWSVPC(t *testing.T) {
 	mockEntry = mock_metrics.NewMockEntry(ctrl)
 	t.Run("multi-eni", func(*testing.T) {
 		gomock.InOrder(
-			getExpectedCalls_StopAWSVPC(ctx, platformAPI, metricsFactory, mockEntry, netNS)...,
+			getExpectedCalls_StopAWSVPC(ctx, platformAPI, metricsFactory, mockEntry, netDao, netNS)...,
+		)
+		netBuilder.Stop(ctx, types.NetworkModeAwsvpc, taskID, netNS)
+	})
+
+	// The egress policy is removed before the interfaces are cleaned up.
+	_, taskNetConfig = getSingleNetNSAWSVPCTestData(taskID)
+	netNS = taskNetConfig.GetPrimaryNetNS()
+	netNS.DesiredState = status.NetworkDeleted
+	netNS.EgressPolicy = &egresspolicy.EgressPolicy{
+		AllowedCIDRs: []string{"10.0.0.0/16"},
+	}
+	mockEntry = mock_metrics.NewMockEntry(ctrl)
+	t.Run("single-eni-egresspolicy", func(*testing.T) {
+		gomock.InOrder(
+			getExpectedCalls_StopAWSVPC(ctx, platformAPI, metricsFactory, mockEntry, netDao, netNS)...,
 		)
 		netBuilder.Stop(ctx, types.NetworkModeAwsvpc, taskID, netNS)
 	})
@@ -351,6 +389,7 @@ func getExpectedCalls_StopAWSVPC(
 	platformAPI *mock_platform.MockAPI,
 	metricsFactory *mock_metrics.MockEntryFactory,
 	mockEntry *mock_metrics.MockEntry,
+	netDao *mock_data.MockNetworkDataClient,
 	netNS *tasknetworkconfig.NetworkNamespace,
 ) []*gomock.Call {
 	var calls []*gomock.Call
@@ -366,6 +405,10 @@ func getExpectedCalls_StopAWSVPC(
 		return calls
 	}
 
+	if netNS.EgressPolicy != nil {
+		calls = append(calls, platformAPI.EXPECT().ConfigureEgressPolicy(ctx, netNS).Return(nil).Times(1))
+	}
+
 	// For each interface inside the netns, the network builder needs to invoke the
 	// `ConfigureInterface` platformAPI.
 	for _, iface := range netNS.NetworkInterfaces {
@@ -373,10 +416,174 @@ func getExpectedCalls_StopAWSVPC(
 			continue
 		}
 		calls = append(calls,
-			platformAPI.EXPECT().ConfigureInterface(ctx, netNS.Path, iface, gomock.Any()).Return(nil).Times(1))
+			platformAPI.EXPECT().ConfigureInterface(ctx, netNS.Path, iface, gomock.Any()).Return(nil).Times(1),
+			netDao.EXPECT().SaveNetworkNamespace(netNS).Return(nil).Times(1))
 	}
 
 	return append(calls,
 		platformAPI.EXPECT().DeleteDNSConfig(netNS.Name).Return(nil).Times(1),
-		platformAPI.EXPECT().DeleteNetNS(netNS.Path).Return(nil).Times(1))
+		platformAPI.EXPECT().DeleteNetNS(netNS.Path).Return(nil).Times(1),
+		mockEntry.EXPECT().Done(nil).Times(1))
+}
+
+// getBridgeTestNetNS returns a bridge mode network namespace for the test task.
+func getBridgeTestNetNS(t *testing.T) *tasknetworkconfig.NetworkNamespace {
+	netNS, err := tasknetworkconfig.NewNetworkNamespace(
+		fmt.Sprintf("%s-bridge", taskID), fmt.Sprintf("/var/run/netns/%s-bridge", taskID), 0, nil)
+	require.NoError(t, err)
+	netNS.BridgeConfig = &tasknetworkconfig.BridgeConfig{
+		Hostname: taskID,
+		PortMappings: []tasknetworkconfig.PortMapping{
+			{ContainerPort: 80, HostPort: 8080, Protocol: tasknetworkconfig.PortMappingProtocolTCP},
+		},
+	}
+	return netNS
+}
+
+func testNetworkBuilder_StartBridge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+	platformAPI := mock_platform.NewMockAPI(ctrl)
+	metricsFactory := mock_metrics.NewMockEntryFactory(ctrl)
+	netDao := mock_data.NewMockNetworkDataClient(ctrl)
+	netBuilder := &networkBuilder{
+		platformAPI:    platformAPI,
+		metricsFactory: metricsFactory,
+		networkDAO:     netDao,
+	}
+
+	netNS := getBridgeTestNetNS(t)
+	t.Run("readypull", func(t *testing.T) {
+		mockEntry := mock_metrics.NewMockEntry(ctrl)
+		gomock.InOrder(
+			metricsFactory.EXPECT().New(metrics.BuildNetworkNamespaceMetricName).Return(mockEntry),
+			mockEntry.EXPECT().WithFields(gomock.Any()).Return(mockEntry),
+			platformAPI.EXPECT().CreateNetNS(netNS.Path).Return(nil),
+			platformAPI.EXPECT().ConfigureBridge(ctx, netNS).Return(nil),
+			platformAPI.EXPECT().CreateDNSConfig(taskID, netNS).Return(nil),
+			netDao.EXPECT().SaveNetworkNamespace(netNS).Return(nil),
+			mockEntry.EXPECT().Done(nil),
+		)
+		require.NoError(t, netBuilder.Start(ctx, types.NetworkModeBridge, taskID, netNS))
+	})
+
+	// The netns is deleted if it cannot be connected to the bridge.
+	t.Run("configure bridge failure", func(t *testing.T) {
+		mockEntry := mock_metrics.NewMockEntry(ctrl)
+		bridgeErr := errors.New("bridge error")
+		gomock.InOrder(
+			metricsFactory.EXPECT().New(metrics.BuildNetworkNamespaceMetricName).Return(mockEntry),
+			mockEntry.EXPECT().WithFields(gomock.Any()).Return(mockEntry),
+			platformAPI.EXPECT().CreateNetNS(netNS.Path).Return(nil),
+			platformAPI.EXPECT().ConfigureBridge(ctx, netNS).Return(bridgeErr),
+			platformAPI.EXPECT().DeleteNetNS(netNS.Path).Return(nil),
+			mockEntry.EXPECT().Done(gomock.Any()),
+		)
+		require.Error(t, netBuilder.Start(ctx, types.NetworkModeBridge, taskID, netNS))
+	})
+
+	// The netns is disconnected from the bridge and deleted if a step after connecting
+	// it to the bridge fails.
+	expectBridgeCleanup := func() []*gomock.Call {
+		return []*gomock.Call{
+			platformAPI.EXPECT().ConfigureBridge(ctx, netNS).DoAndReturn(
+				func(_ context.Context, netNS *tasknetworkconfig.NetworkNamespace) error {
+					require.Equal(t, status.NetworkDeleted, netNS.DesiredState)
+					return nil
+				}),
+			platformAPI.EXPECT().DeleteDNSConfig(netNS.Name).Return(nil),
+			platformAPI.EXPECT().DeleteNetNS(netNS.Path).Return(nil),
+		}
+	}
+	t.Run("dns config failure", func(t *testing.T) {
+		mockEntry := mock_metrics.NewMockEntry(ctrl)
+		dnsErr := errors.New("dns error")
+		calls := []*gomock.Call{
+			metricsFactory.EXPECT().New(metrics.BuildNetworkNamespaceMetricName).Return(mockEntry),
+			mockEntry.EXPECT().WithFields(gomock.Any()).Return(mockEntry),
+			platformAPI.EXPECT().CreateNetNS(netNS.Path).Return(nil),
+			platformAPI.EXPECT().ConfigureBridge(ctx, netNS).Return(nil),
+			platformAPI.EXPECT().CreateDNSConfig(taskID, netNS).Return(dnsErr),
+		}
+		calls = append(calls, expectBridgeCleanup()...)
+		calls = append(calls, mockEntry.EXPECT().Done(gomock.Any()))
+		gomock.InOrder(calls...)
+		require.ErrorIs(t, netBuilder.Start(ctx, types.NetworkModeBridge, taskID, netNS), dnsErr)
+		require.Equal(t, status.NetworkReadyPull, netNS.DesiredState)
+	})
+
+	t.Run("save failure", func(t *testing.T) {
+		mockEntry := mock_metrics.NewMockEntry(ctrl)
+		saveErr := errors.New("save error")
+		calls := []*gomock.Call{
+			metricsFactory.EXPECT().New(metrics.BuildNetworkNamespaceMetricName).Return(mockEntry),
+			mockEntry.EXPECT().WithFields(gomock.Any()).Return(mockEntry),
+			platformAPI.EXPECT().CreateNetNS(netNS.Path).Return(nil),
+			platformAPI.EXPECT().ConfigureBridge(ctx, netNS).Return(nil),
+			platformAPI.EXPECT().CreateDNSConfig(taskID, netNS).Return(nil),
+			netDao.EXPECT().SaveNetworkNamespace(netNS).Return(saveErr),
+		}
+		calls = append(calls, expectBridgeCleanup()...)
+		calls = append(calls, mockEntry.EXPECT().Done(gomock.Any()))
+		gomock.InOrder(calls...)
+		require.ErrorIs(t, netBuilder.Start(ctx, types.NetworkModeBridge, taskID, netNS), saveErr)
+		require.Equal(t, status.NetworkReadyPull, netNS.DesiredState)
+	})
+
+	// Nothing is left to configure once the netns has been connected to the bridge.
+	netNS.KnownState = status.NetworkReadyPull
+	netNS.DesiredState = status.NetworkReady
+	t.Run("ready", func(t *testing.T) {
+		mockEntry := mock_metrics.NewMockEntry(ctrl)
+		gomock.InOrder(
+			metricsFactory.EXPECT().New(metrics.BuildNetworkNamespaceMetricName).Return(mockEntry),
+			mockEntry.EXPECT().WithFields(gomock.Any()).Return(mockEntry),
+			mockEntry.EXPECT().Done(nil),
+		)
+		require.NoError(t, netBuilder.Start(ctx, types.NetworkModeBridge, taskID, netNS))
+	})
+
+	netNS.DesiredState = status.NetworkDeleted
+	t.Run("deleted", func(t *testing.T) {
+		mockEntry := mock_metrics.NewMockEntry(ctrl)
+		gomock.InOrder(
+			metricsFactory.EXPECT().New(metrics.BuildNetworkNamespaceMetricName).Return(mockEntry),
+			mockEntry.EXPECT().WithFields(gomock.Any()).Return(mockEntry),
+			mockEntry.EXPECT().Done(gomock.Any()),
+		)
+		require.Error(t, netBuilder.Start(ctx, types.NetworkModeBridge, taskID, netNS))
+	})
+}
+
+func testNetworkBuilder_StopBridge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+	platformAPI := mock_platform.NewMockAPI(ctrl)
+	metricsFactory := mock_metrics.NewMockEntryFactory(ctrl)
+	netBuilder := &networkBuilder{
+		platformAPI:    platformAPI,
+		metricsFactory: metricsFactory,
+	}
+
+	netNS := getBridgeTestNetNS(t)
+	netNS.KnownState = status.NetworkReady
+	netNS.DesiredState = status.NetworkDeleted
+
+	// Cleanup is best effort, so every step runs even if the bridge cannot be detached.
+	bridgeErr := errors.New("bridge error")
+	mockEntry := mock_metrics.NewMockEntry(ctrl)
+	gomock.InOrder(
+		metricsFactory.EXPECT().New(metrics.DeleteNetworkNamespaceMetricName).Return(mockEntry),
+		mockEntry.EXPECT().WithFields(gomock.Any()).Return(mockEntry),
+		platformAPI.EXPECT().ConfigureBridge(ctx, netNS).Return(bridgeErr),
+		platformAPI.EXPECT().DeleteDNSConfig(netNS.Name).Return(nil),
+		platformAPI.EXPECT().DeleteNetNS(netNS.Path).Return(nil),
+		mockEntry.EXPECT().Done(gomock.Any()),
+	)
+	err := netBuilder.Stop(ctx, types.NetworkModeBridge, taskID, netNS)
+	require.ErrorIs(t, err, bridgeErr)
 }
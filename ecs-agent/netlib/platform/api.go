@@ -19,7 +19,9 @@ import (
 	netlibdata "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/data"
 
 	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/dnscache"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/appmesh"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/egresspolicy"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/serviceconnect"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
@@ -57,6 +59,17 @@ type API interface {
 	// GetNetNSPath returns the path of a network namespace.
 	GetNetNSPath(netNSName string) string
 
+	// ListNetNSNames returns the names of the network namespaces present on the host.
+	ListNetNSNames() ([]string, error)
+
+	// ListDNSConfigNames returns the names of the network namespaces whose DNS config
+	// directory is present on the host.
+	ListDNSConfigNames() ([]string, error)
+
+	// ReleaseOrphanedAddresses releases the IPAM allocations held for a network namespace
+	// whose state has been lost, for instance because the agent stopped while configuring it.
+	ReleaseOrphanedAddresses(ctx context.Context, netNSName string) error
+
 	// ConfigureInterface configures an interface inside a network namespace
 	// for it to be able to serve traffic.
 	ConfigureInterface(
@@ -78,6 +91,23 @@ type API interface {
 		primaryIf *networkinterface.NetworkInterface,
 		scConfig *serviceconnect.ServiceConnectConfig,
 	) error
+
+	// ConfigureBridge connects a bridge mode task network namespace to the managed bridge on the host,
+	// assigns the task an address through IPAM and publishes its port mappings. When the network
+	// namespace is desired to be deleted, all of these are torn down instead.
+	ConfigureBridge(ctx context.Context, netNS *tasknetworkconfig.NetworkNamespace) error
+
+	// ConfigureEgressPolicy enforces the egress policy of a network namespace by rejecting
+	// outbound connections to destinations that are not allowed. When the network namespace
+	// is desired to be deleted, the policy is removed instead.
+	ConfigureEgressPolicy(ctx context.Context, netNS *tasknetworkconfig.NetworkNamespace) error
+
+	// GetEgressPolicyStats returns the counters of the egress policy enforced in a network namespace.
+	GetEgressPolicyStats(ctx context.Context, netNS *tasknetworkconfig.NetworkNamespace) (*egresspolicy.Stats, error)
+
+	// GetDNSCacheStats returns the query counters of the caching DNS resolver running in a
+	// network namespace.
+	GetDNSCacheStats(netNS *tasknetworkconfig.NetworkNamespace) (*dnscache.Stats, error)
 }
 
 // Config contains platform-specific data.
@@ -87,4 +117,16 @@ type Config struct {
 	// ResolvConfPath specifies path to resolv.conf file for DNS config.
 	// Different platforms may have different paths for this file.
 	ResolvConfPath string
+	// BridgeSubnet specifies the IPv4 subnet of the managed bridge that bridge mode
+	// tasks are connected to. DefaultBridgeSubnet is used if it is empty.
+	BridgeSubnet string
+	// DNSCache enables a caching DNS resolver inside each awsvpc network namespace when set.
+	// Task queries are then answered from the cache of the resolver, which forwards the
+	// queries it cannot answer to the name servers of the task ENI.
+	DNSCache *dnscache.Config
+	// HostPortAllocator allocates the host ports of the bridge mode port mappings that don't
+	// specify one, and of container port ranges. It receives the ID of the task the ports are
+	// allocated to, and is responsible for releasing them once the task stops. Tasks with such
+	// port mappings are rejected if it is nil.
+	HostPortAllocator func(taskID, key string, numberOfPorts int, protocol string) (uint16, error)
 }
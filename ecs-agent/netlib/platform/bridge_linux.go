@@ -0,0 +1,255 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package platform
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	loggerfield "github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/ecscni"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/status"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
+
+	"github.com/containernetworking/cni/pkg/types"
+	cnitypes "github.com/containernetworking/cni/pkg/types/100"
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+)
+
+const (
+	// BridgeNetNSSuffix is appended to the task ID to name the network namespace of a bridge mode task.
+	BridgeNetNSSuffix = "bridge"
+
+	iptablesCommand = "iptables"
+)
+
+// buildBridgeNetworkNamespace returns the network namespace used to create the network configuration
+// of a task in bridge mode. Bridge mode tasks have a single network namespace without any ENI.
+func (c *common) buildBridgeNetworkNamespace(
+	taskID string,
+	taskPayload *ecsacs.Task,
+) ([]*tasknetworkconfig.NetworkNamespace, error) {
+	netNSName := networkinterface.NetNSName(taskID, BridgeNetNSSuffix)
+	netNSPath := c.GetNetNSPath(netNSName)
+
+	logger.Info("Building network namespace model for bridge task", map[string]interface{}{
+		"NetNSName": netNSName,
+		"NetNSPath": netNSPath,
+	})
+	netNS, err := tasknetworkconfig.NewNetworkNamespace(netNSName, netNSPath, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	var allocateHostPorts tasknetworkconfig.HostPortAllocator
+	if c.hostPortAllocator != nil {
+		allocateHostPorts = func(key string, numberOfPorts int, protocol string) (uint16, error) {
+			return c.hostPortAllocator(taskID, key, numberOfPorts, protocol)
+		}
+	}
+	netNS.BridgeConfig, err = tasknetworkconfig.NewBridgeConfig(taskID, taskPayload.Containers, allocateHostPorts)
+	if err != nil {
+		return nil, err
+	}
+
+	return []*tasknetworkconfig.NetworkNamespace{netNS}, nil
+}
+
+// ConfigureBridge connects a bridge mode task network namespace to the managed task bridge, or
+// disconnects it, depending on the desired state of the network namespace.
+func (c *common) ConfigureBridge(ctx context.Context, netNS *tasknetworkconfig.NetworkNamespace) error {
+	if netNS.BridgeConfig == nil {
+		return errors.New("bridge configuration not found for netns " + netNS.Name)
+	}
+
+	c.os.Setenv(CNIPluginLogFileEnv, ecscni.PluginLogPath)
+	c.os.Setenv(IPAMDataPathEnv, filepath.Join(c.stateDBDir, IPAMDataFileName))
+
+	switch netNS.DesiredState {
+	case status.NetworkReadyPull:
+		return c.connectBridge(ctx, netNS)
+	case status.NetworkDeleted:
+		return c.disconnectBridge(ctx, netNS)
+	}
+	return nil
+}
+
+// connectBridge creates the veth pair between the task network namespace and the managed task bridge,
+// records the address assigned to the task and publishes the task's port mappings on the host.
+// Everything that was set up is removed again if a step fails.
+func (c *common) connectBridge(ctx context.Context, netNS *tasknetworkconfig.NetworkNamespace) error {
+	logger.Info("Connecting netns to the task bridge", map[string]interface{}{
+		"NetNSPath": netNS.Path,
+		"Bridge":    TaskBridgeName,
+	})
+
+	results, err := c.executeCNIPlugin(ctx, true, createTaskBridgePluginConfig(netNS.Path, c.bridgeSubnet, ""))
+	if err != nil {
+		return errors.Wrap(err, "failed to connect netns to the task bridge")
+	}
+
+	err = c.publishBridge(ctx, netNS, results)
+	if err != nil {
+		if cleanupErr := c.disconnectBridge(ctx, netNS); cleanupErr != nil {
+			logger.Warn("Failed to clean up task bridge connection", map[string]interface{}{
+				"NetNSPath":       netNS.Path,
+				loggerfield.Error: cleanupErr,
+			})
+		}
+		return err
+	}
+	return nil
+}
+
+// publishBridge records the address assigned to the task on the task bridge, masquerades the
+// task's traffic leaving the host and publishes the task's port mappings.
+func (c *common) publishBridge(
+	ctx context.Context,
+	netNS *tasknetworkconfig.NetworkNamespace,
+	results []*types.Result,
+) error {
+	if len(results) == 0 {
+		return errors.New("bridge configuration: empty result from network setup")
+	}
+	result, err := cnitypes.GetResult(*results[0])
+	if err != nil {
+		return err
+	}
+	if len(result.IPs) == 0 {
+		return errors.New("bridge configuration: no address assigned")
+	}
+	netNS.BridgeConfig.IPv4Address = result.IPs[0].Address.String()
+
+	if err = c.configureBridgeMasquerade(ctx, netNS.BridgeConfig.IPv4Address, true); err != nil {
+		return err
+	}
+
+	portMappings := netNS.BridgeConfig.PublishedPortMappings()
+	if len(portMappings) == 0 {
+		return nil
+	}
+	_, err = c.executeCNIPlugin(ctx, true, createPortMapPluginConfig(netNS.Path, netNS.Name, portMappings, result))
+	if err != nil {
+		return errors.Wrap(err, "failed to publish port mappings")
+	}
+	return nil
+}
+
+// disconnectBridge removes everything set up by connectBridge. It is best effort, so every
+// step is attempted even if an earlier one fails.
+func (c *common) disconnectBridge(ctx context.Context, netNS *tasknetworkconfig.NetworkNamespace) error {
+	logger.Info("Disconnecting netns from the task bridge", map[string]interface{}{
+		"NetNSPath": netNS.Path,
+		"Bridge":    TaskBridgeName,
+	})
+
+	var errs error
+	if portMappings := netNS.BridgeConfig.PublishedPortMappings(); len(portMappings) > 0 {
+		_, err := c.executeCNIPlugin(ctx, false, createPortMapPluginConfig(netNS.Path, netNS.Name, portMappings, nil))
+		if err != nil {
+			errs = multierror.Append(errs, errors.Wrap(err, "failed to remove port mappings"))
+		}
+	}
+
+	if netNS.BridgeConfig.IPv4Address != "" {
+		if err := c.configureBridgeMasquerade(ctx, netNS.BridgeConfig.IPv4Address, false); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+
+	_, err := c.executeCNIPlugin(ctx, false,
+		createTaskBridgePluginConfig(netNS.Path, c.bridgeSubnet, ipWithoutPrefix(netNS.BridgeConfig.IPv4Address)))
+	if err != nil {
+		errs = multierror.Append(errs, errors.Wrap(err, "failed to disconnect netns from the task bridge"))
+	}
+	return errs
+}
+
+// configureBridgeMasquerade adds or deletes the NAT rule that masquerades traffic from a bridge
+// mode task leaving the host.
+func (c *common) configureBridgeMasquerade(ctx context.Context, ipv4Address string, add bool) error {
+	action := "-D"
+	if add {
+		action = "-A"
+	}
+	args := []string{"-w", "-t", "nat", action, "POSTROUTING",
+		"-s", ipWithoutPrefix(ipv4Address) + "/32", "!", "-o", TaskBridgeName, "-j", "MASQUERADE"}
+
+	ctx, cancel := c.exec.NewExecContextWithTimeout(ctx, nsSetupTimeoutDuration)
+	defer cancel()
+	out, err := c.exec.CommandContext(ctx, iptablesCommand, args...).CombinedOutput()
+	if err != nil {
+		return errors.Wrapf(err, "failed to update masquerade rule for %s: %s", ipv4Address, string(out))
+	}
+	return nil
+}
+
+// createBridgeDNSConfig creates the DNS config files of a bridge mode task. The resolv.conf file
+// is copied from the host, while the hosts and hostname files map the task hostname to the address
+// assigned on the task bridge.
+func (c *common) createBridgeDNSConfig(taskID string, netNS *tasknetworkconfig.NetworkNamespace) error {
+	netNSDir := filepath.Join(networkConfigFileDirectory, netNS.Name)
+	_, err := c.os.Stat(netNSDir)
+	if err != nil && c.os.IsNotExist(err) {
+		err = c.os.MkdirAll(netNSDir, networkConfigFileMode)
+	}
+	if err != nil {
+		return errors.Wrap(err, "unable to create the dns config directory")
+	}
+
+	err = c.copyFile(filepath.Join(netNSDir, ResolveConfFileName),
+		filepath.Join(c.resolvConfPath, ResolveConfFileName),
+		taskDNSConfigFileMode)
+	if err != nil {
+		return err
+	}
+
+	hostname := netNS.BridgeConfig.Hostname
+	err = c.ioutil.WriteFile(filepath.Join(netNSDir, HostnameFileName),
+		[]byte(hostname+"\n"), networkConfigFileMode)
+	if err != nil {
+		return errors.Wrap(err, "unable to create hostname file for netns")
+	}
+
+	if err = c.createHostnameFileForDefaultNetNS(); err != nil {
+		return errors.Wrap(err, "unable to verify the existence of /etc/hostname on the host")
+	}
+
+	var contents bytes.Buffer
+	fmt.Fprintf(&contents, "%s\n", HostsLocalhostEntryIPv4)
+	if ip := ipWithoutPrefix(netNS.BridgeConfig.IPv4Address); ip != "" {
+		fmt.Fprintf(&contents, "%s %s\n", ip, hostname)
+	}
+	err = c.ioutil.WriteFile(filepath.Join(netNSDir, HostsFileName), contents.Bytes(), networkConfigFileMode)
+	if err != nil {
+		return errors.Wrap(err, "unable to create hosts file for netns")
+	}
+
+	return c.copyNetworkConfigFilesToTask(taskID, netNS.Name)
+}
+
+// ipWithoutPrefix returns the IP address of an address in CIDR notation.
+func ipWithoutPrefix(cidr string) string {
+	ip, _, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return cidr
+	}
+	return ip.String()
+}
@@ -0,0 +1,340 @@
+//go:build !windows && unit
+// +build !windows,unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package platform
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/ecscni"
+	mock_ecscni2 "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/ecscni/mocks_ecscni"
+	mock_ecscni "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/ecscni/mocks_nsutil"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/status"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
+	mock_execwrapper "github.com/aws/amazon-ecs-agent/ecs-agent/utils/execwrapper/mocks"
+	mock_ioutilwrapper "github.com/aws/amazon-ecs-agent/ecs-agent/utils/ioutilwrapper/mocks"
+	mock_oswrapper "github.com/aws/amazon-ecs-agent/ecs-agent/utils/oswrapper/mocks"
+	mock_volume "github.com/aws/amazon-ecs-agent/ecs-agent/volume/mocks"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	currentCNITypes "github.com/containernetworking/cni/pkg/types/100"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	bridgeTestTaskID    = "bridge-task-id"
+	bridgeTestNetNSName = bridgeTestTaskID + "-bridge"
+	bridgeTestNetNSPath = "/var/run/netns/" + bridgeTestNetNSName
+	bridgeTestIPv4CIDR  = "172.30.0.2/16"
+)
+
+func getTestBridgeNetNS(t *testing.T) *tasknetworkconfig.NetworkNamespace {
+	netNS, err := tasknetworkconfig.NewNetworkNamespace(bridgeTestNetNSName, bridgeTestNetNSPath, 0, nil)
+	require.NoError(t, err)
+	netNS.BridgeConfig = &tasknetworkconfig.BridgeConfig{
+		Hostname: bridgeTestTaskID,
+		PortMappings: []tasknetworkconfig.PortMapping{
+			{ContainerPort: 80, HostPort: 8080, Protocol: tasknetworkconfig.PortMappingProtocolTCP},
+			// Not published since the host port is unassigned.
+			{ContainerPort: 53, Protocol: tasknetworkconfig.PortMappingProtocolUDP},
+		},
+	}
+	return netNS
+}
+
+func masqueradeArgs(action string) []string {
+	return []string{"-w", "-t", "nat", action, "POSTROUTING",
+		"-s", "172.30.0.2/32", "!", "-o", TaskBridgeName, "-j", "MASQUERADE"}
+}
+
+func TestCommon_BuildBridgeNetworkNamespace(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	nsUtil := mock_ecscni.NewMockNetNSUtil(ctrl)
+	commonPlatform := &common{
+		nsUtil: nsUtil,
+		hostPortAllocator: func(taskID, key string, numberOfPorts int, protocol string) (uint16, error) {
+			require.Equal(t, bridgeTestTaskID, taskID)
+			require.Equal(t, "app/53/udp", key)
+			require.Equal(t, 1, numberOfPorts)
+			return 32768, nil
+		},
+	}
+
+	taskPayload := &ecsacs.Task{
+		Containers: []*ecsacs.Container{
+			{
+				Name: aws.String("app"),
+				PortMappings: []*ecsacs.PortMapping{
+					{ContainerPort: aws.Int64(80), HostPort: aws.Int64(8080)},
+					{ContainerPort: aws.Int64(53), Protocol: aws.String("UDP")},
+				},
+			},
+		},
+	}
+	nsUtil.EXPECT().GetNetNSPath(bridgeTestNetNSName).Return(bridgeTestNetNSPath)
+
+	namespaces, err := commonPlatform.buildBridgeNetworkNamespace(bridgeTestTaskID, taskPayload)
+	require.NoError(t, err)
+	require.Len(t, namespaces, 1)
+
+	netNS := namespaces[0]
+	require.Equal(t, bridgeTestNetNSName, netNS.Name)
+	require.Equal(t, bridgeTestNetNSPath, netNS.Path)
+	require.Empty(t, netNS.NetworkInterfaces)
+	require.Equal(t, &tasknetworkconfig.BridgeConfig{
+		Hostname: bridgeTestTaskID,
+		PortMappings: []tasknetworkconfig.PortMapping{
+			{ContainerPort: 80, HostPort: 8080, Protocol: tasknetworkconfig.PortMappingProtocolTCP},
+			{ContainerPort: 53, HostPort: 32768, Protocol: tasknetworkconfig.PortMappingProtocolUDP},
+		},
+	}, netNS.BridgeConfig)
+
+	// The allocated host port of the dynamic mapping is published by the portmap plugin.
+	portMapConfig := createPortMapPluginConfig(netNS.Path, netNS.Name,
+		netNS.BridgeConfig.PublishedPortMappings(), nil).(*ecscni.PortMapConfig)
+	require.Contains(t, portMapConfig.RuntimeConfig.PortMappings,
+		ecscni.PortMapEntry{HostPort: 32768, ContainerPort: 53, Protocol: tasknetworkconfig.PortMappingProtocolUDP})
+}
+
+func TestCommon_BuildBridgeNetworkNamespaceWithoutHostPortAllocator(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	nsUtil := mock_ecscni.NewMockNetNSUtil(ctrl)
+	commonPlatform := &common{
+		nsUtil: nsUtil,
+	}
+
+	taskPayload := &ecsacs.Task{
+		Containers: []*ecsacs.Container{
+			{
+				Name:         aws.String("app"),
+				PortMappings: []*ecsacs.PortMapping{{ContainerPort: aws.Int64(80)}},
+			},
+		},
+	}
+	nsUtil.EXPECT().GetNetNSPath(bridgeTestNetNSName).Return(bridgeTestNetNSPath)
+
+	_, err := commonPlatform.buildBridgeNetworkNamespace(bridgeTestTaskID, taskPayload)
+	require.Error(t, err)
+}
+
+func TestCommon_ConfigureBridge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+	osWrapper := mock_oswrapper.NewMockOS(ctrl)
+	cniClient := mock_ecscni2.NewMockCNI(ctrl)
+	execWrapper := mock_execwrapper.NewMockExec(ctrl)
+	cmd := mock_execwrapper.NewMockCmd(ctrl)
+	commonPlatform := &common{
+		os:           osWrapper,
+		cniClient:    cniClient,
+		exec:         execWrapper,
+		stateDBDir:   "dummy-db-dir",
+		bridgeSubnet: DefaultBridgeSubnet,
+	}
+
+	netNS := getTestBridgeNetNS(t)
+	publishedPorts := netNS.BridgeConfig.PublishedPortMappings()
+
+	ip, ipNet, err := net.ParseCIDR(bridgeTestIPv4CIDR)
+	require.NoError(t, err)
+	ipNet.IP = ip
+	bridgeResult := &currentCNITypes.Result{
+		CNIVersion: portMapCNISpecVersion,
+		IPs:        []*currentCNITypes.IPConfig{{Address: *ipNet}},
+	}
+
+	t.Run("connect", func(t *testing.T) {
+		netNS.DesiredState = status.NetworkReadyPull
+		gomock.InOrder(
+			osWrapper.EXPECT().Setenv("ECS_CNI_LOG_FILE", ecscni.PluginLogPath),
+			osWrapper.EXPECT().Setenv("IPAM_DB_PATH", filepath.Join(commonPlatform.stateDBDir, "eni-ipam.db")),
+			cniClient.EXPECT().Add(gomock.Any(),
+				createTaskBridgePluginConfig(bridgeTestNetNSPath, DefaultBridgeSubnet, "")).Return(bridgeResult, nil),
+			execWrapper.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).Return(ctx, func() {}),
+			execWrapper.EXPECT().CommandContext(gomock.Any(), iptablesCommand, masqueradeArgs("-A")).Return(cmd),
+			cmd.EXPECT().CombinedOutput().Return(nil, nil),
+			cniClient.EXPECT().Add(gomock.Any(),
+				createPortMapPluginConfig(bridgeTestNetNSPath, bridgeTestNetNSName, publishedPorts, bridgeResult)).
+				Return(nil, nil),
+		)
+		require.NoError(t, commonPlatform.ConfigureBridge(ctx, netNS))
+		require.Equal(t, bridgeTestIPv4CIDR, netNS.BridgeConfig.IPv4Address)
+	})
+
+	t.Run("disconnect", func(t *testing.T) {
+		netNS.DesiredState = status.NetworkDeleted
+		portMapErr := errors.New("portmap error")
+		gomock.InOrder(
+			osWrapper.EXPECT().Setenv("ECS_CNI_LOG_FILE", ecscni.PluginLogPath),
+			osWrapper.EXPECT().Setenv("IPAM_DB_PATH", filepath.Join(commonPlatform.stateDBDir, "eni-ipam.db")),
+			cniClient.EXPECT().Del(gomock.Any(),
+				createPortMapPluginConfig(bridgeTestNetNSPath, bridgeTestNetNSName, publishedPorts, nil)).
+				Return(portMapErr),
+			execWrapper.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).Return(ctx, func() {}),
+			execWrapper.EXPECT().CommandContext(gomock.Any(), iptablesCommand, masqueradeArgs("-D")).Return(cmd),
+			cmd.EXPECT().CombinedOutput().Return(nil, nil),
+			cniClient.EXPECT().Del(gomock.Any(),
+				createTaskBridgePluginConfig(bridgeTestNetNSPath, DefaultBridgeSubnet, "172.30.0.2")).Return(nil),
+		)
+		// Cleanup continues after a failed step, and the failure is reported.
+		err := commonPlatform.ConfigureBridge(ctx, netNS)
+		require.ErrorIs(t, err, portMapErr)
+	})
+
+	t.Run("missing bridge config", func(t *testing.T) {
+		require.Error(t, commonPlatform.ConfigureBridge(ctx, &tasknetworkconfig.NetworkNamespace{}))
+	})
+}
+
+func TestCommon_ConnectBridgeCleanup(t *testing.T) {
+	ip, ipNet, err := net.ParseCIDR(bridgeTestIPv4CIDR)
+	require.NoError(t, err)
+	ipNet.IP = ip
+	bridgeResult := &currentCNITypes.Result{
+		CNIVersion: portMapCNISpecVersion,
+		IPs:        []*currentCNITypes.IPConfig{{Address: *ipNet}},
+	}
+	masqueradeErr := errors.New("iptables error")
+	portMapErr := errors.New("portmap error")
+
+	testCases := []struct {
+		name string
+		// failAfterAdd sets up the expectations of the steps that follow the bridge ADD,
+		// up to the one that fails.
+		failAfterAdd func(ctx context.Context, cniClient *mock_ecscni2.MockCNI,
+			execWrapper *mock_execwrapper.MockExec, cmd *mock_execwrapper.MockCmd,
+			publishedPorts []tasknetworkconfig.PortMapping)
+		expectedErr error
+	}{
+		{
+			name: "masquerade failure",
+			failAfterAdd: func(ctx context.Context, cniClient *mock_ecscni2.MockCNI,
+				execWrapper *mock_execwrapper.MockExec, cmd *mock_execwrapper.MockCmd,
+				_ []tasknetworkconfig.PortMapping) {
+				execWrapper.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).Return(ctx, func() {})
+				execWrapper.EXPECT().CommandContext(gomock.Any(), iptablesCommand, masqueradeArgs("-A")).Return(cmd)
+				cmd.EXPECT().CombinedOutput().Return(nil, masqueradeErr)
+			},
+			expectedErr: masqueradeErr,
+		},
+		{
+			name: "portmap failure",
+			failAfterAdd: func(ctx context.Context, cniClient *mock_ecscni2.MockCNI,
+				execWrapper *mock_execwrapper.MockExec, cmd *mock_execwrapper.MockCmd,
+				publishedPorts []tasknetworkconfig.PortMapping) {
+				execWrapper.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).Return(ctx, func() {})
+				execWrapper.EXPECT().CommandContext(gomock.Any(), iptablesCommand, masqueradeArgs("-A")).Return(cmd)
+				cmd.EXPECT().CombinedOutput().Return(nil, nil)
+				cniClient.EXPECT().Add(gomock.Any(),
+					createPortMapPluginConfig(bridgeTestNetNSPath, bridgeTestNetNSName, publishedPorts, bridgeResult)).
+					Return(nil, portMapErr)
+			},
+			expectedErr: portMapErr,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ctx := context.TODO()
+			osWrapper := mock_oswrapper.NewMockOS(ctrl)
+			cniClient := mock_ecscni2.NewMockCNI(ctrl)
+			execWrapper := mock_execwrapper.NewMockExec(ctrl)
+			cmd := mock_execwrapper.NewMockCmd(ctrl)
+			commonPlatform := &common{
+				os:           osWrapper,
+				cniClient:    cniClient,
+				exec:         execWrapper,
+				stateDBDir:   "dummy-db-dir",
+				bridgeSubnet: DefaultBridgeSubnet,
+			}
+
+			netNS := getTestBridgeNetNS(t)
+			netNS.DesiredState = status.NetworkReadyPull
+			publishedPorts := netNS.BridgeConfig.PublishedPortMappings()
+
+			osWrapper.EXPECT().Setenv(gomock.Any(), gomock.Any()).AnyTimes()
+			cniClient.EXPECT().Add(gomock.Any(),
+				createTaskBridgePluginConfig(bridgeTestNetNSPath, DefaultBridgeSubnet, "")).Return(bridgeResult, nil)
+			tc.failAfterAdd(ctx, cniClient, execWrapper, cmd, publishedPorts)
+			// Everything set up after the bridge ADD is removed again, and the bridge is deleted.
+			cniClient.EXPECT().Del(gomock.Any(),
+				createPortMapPluginConfig(bridgeTestNetNSPath, bridgeTestNetNSName, publishedPorts, nil)).Return(nil)
+			execWrapper.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).Return(ctx, func() {})
+			execWrapper.EXPECT().CommandContext(gomock.Any(), iptablesCommand, masqueradeArgs("-D")).Return(cmd)
+			cmd.EXPECT().CombinedOutput().Return(nil, nil)
+			cniClient.EXPECT().Del(gomock.Any(),
+				createTaskBridgePluginConfig(bridgeTestNetNSPath, DefaultBridgeSubnet, "172.30.0.2")).Return(nil)
+
+			err := commonPlatform.ConfigureBridge(ctx, netNS)
+			require.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestCommon_CreateBridgeDNSConfig(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ioutil := mock_ioutilwrapper.NewMockIOUtil(ctrl)
+	osWrapper := mock_oswrapper.NewMockOS(ctrl)
+	mockFile := mock_oswrapper.NewMockFile(ctrl)
+	volumeAccessor := mock_volume.NewMockTaskVolumeAccessor(ctrl)
+	commonPlatform := &common{
+		ioutil:            ioutil,
+		os:                osWrapper,
+		dnsVolumeAccessor: volumeAccessor,
+		resolvConfPath:    "/etc",
+	}
+
+	netNS := getTestBridgeNetNS(t)
+	netNS.BridgeConfig.IPv4Address = bridgeTestIPv4CIDR
+	netNSDir := "/etc/netns/" + bridgeTestNetNSName
+	resolvData := []byte("nameserver 10.0.0.2\n")
+	hostsData := []byte("127.0.0.1 localhost\n172.30.0.2 " + bridgeTestTaskID + "\n")
+
+	gomock.InOrder(
+		osWrapper.EXPECT().Stat(netNSDir).Return(nil, os.ErrNotExist),
+		osWrapper.EXPECT().IsNotExist(os.ErrNotExist).Return(true),
+		osWrapper.EXPECT().MkdirAll(netNSDir, fs.FileMode(0644)),
+		ioutil.EXPECT().ReadFile("/etc/resolv.conf").Return(resolvData, nil),
+		ioutil.EXPECT().WriteFile(netNSDir+"/resolv.conf", resolvData, fs.FileMode(0666)),
+		ioutil.EXPECT().WriteFile(netNSDir+"/hostname", []byte(bridgeTestTaskID+"\n"), fs.FileMode(0644)),
+		osWrapper.EXPECT().OpenFile("/etc/hostname", os.O_RDONLY|os.O_CREATE, fs.FileMode(0644)).Return(mockFile, nil),
+		mockFile.EXPECT().Close(),
+		ioutil.EXPECT().WriteFile(netNSDir+"/hosts", hostsData, fs.FileMode(0644)),
+		volumeAccessor.EXPECT().CopyToVolume(bridgeTestTaskID, netNSDir+"/hosts", "hosts", fs.FileMode(0644)).Return(nil),
+		volumeAccessor.EXPECT().CopyToVolume(bridgeTestTaskID, netNSDir+"/resolv.conf", "resolv.conf", fs.FileMode(0644)).Return(nil),
+		volumeAccessor.EXPECT().CopyToVolume(bridgeTestTaskID, netNSDir+"/hostname", "hostname", fs.FileMode(0644)).Return(nil),
+	)
+	require.NoError(t, commonPlatform.createDNSConfig(bridgeTestTaskID, false, netNS))
+}
@@ -21,6 +21,9 @@ const (
 	ECSSubNet     = "169.254.172.0/22"
 	AgentEndpoint = "169.254.170.2/32"
 
+	// DefaultBridgeSubnet is the default subnet of the managed bridge for bridge mode tasks.
+	DefaultBridgeSubnet = "172.30.0.0/16"
+
 	CNIPluginLogFileEnv    = "ECS_CNI_LOG_FILE"
 	VPCCNIPluginLogFileEnv = "VPC_CNI_LOG_FILE"
 	IPAMDataPathEnv        = "IPAM_DB_PATH"
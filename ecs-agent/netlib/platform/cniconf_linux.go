@@ -26,8 +26,10 @@ import (
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/ecscni"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/serviceconnect"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
 
 	"github.com/containernetworking/cni/pkg/types"
+	cnitypes "github.com/containernetworking/cni/pkg/types/100"
 )
 
 const (
@@ -42,12 +44,16 @@ const (
 	IPAMPluginName           = "ecs-ipam"
 	AppMeshPluginName        = "aws-appmesh"
 	ServiceConnectPluginName = "ecs-serviceconnect"
+	PortMapPluginName        = "portmap"
 
 	VPCBranchENIPluginName        = "vpc-branch-eni"
 	vpcBranchENICNISpecVersion    = "0.3.1"
 	VPCBranchENIInterfaceTypeVlan = "vlan"
 	VPCBranchENIInterfaceTypeTap  = "tap"
 
+	// The portmap plugin requires a spec version that supports chained plugins.
+	portMapCNISpecVersion = "1.0.0"
+
 	VPCTunnelPluginName          = "vpc-tunnel"
 	vpcTunnelCNISpecVersion      = "0.3.1"
 	VPCTunnelInterfaceTypeGeneve = "geneve"
@@ -55,6 +61,11 @@ const (
 
 	BridgeInterfaceName = "fargate-bridge"
 
+	// TaskBridgeName is the name of the managed bridge that bridge mode tasks are connected to.
+	TaskBridgeName = "ecs-task-br0"
+	// defaultRouteIPv4 is the destination of the default route in a bridge mode task.
+	defaultRouteIPv4 = "0.0.0.0/0"
+
 	IPAMDataFileName = "eni-ipam.db"
 
 	// Timeout duration for each network setup and cleanup operation before it is cancelled.
@@ -121,6 +132,68 @@ func createBridgePluginConfig(netNSPath string) ecscni.PluginConfig {
 	return bridgeConfig
 }
 
+// createTaskBridgePluginConfig constructs the configuration object for bridge plugin
+// that connects a bridge mode task to the managed task bridge. The address is
+// only required when releasing it back to IPAM.
+func createTaskBridgePluginConfig(netNSPath, subnet, ipv4Address string) ecscni.PluginConfig {
+	_, routeIPNet, _ := net.ParseCIDR(defaultRouteIPv4)
+	route := &types.Route{
+		Dst: *routeIPNet,
+	}
+
+	ipamConfig := ecscni.IPAMConfig{
+		CNIConfig: ecscni.CNIConfig{
+			NetNSPath:      netNSPath,
+			CNISpecVersion: cniSpecVersion,
+			CNIPluginName:  IPAMPluginName,
+		},
+		IPV4Subnet:  subnet,
+		IPV4Address: ipv4Address,
+		IPV4Routes:  []*types.Route{route},
+		ID:          netNSPath,
+	}
+
+	return &ecscni.BridgeConfig{
+		CNIConfig: ecscni.CNIConfig{
+			NetNSPath:      netNSPath,
+			CNISpecVersion: cniSpecVersion,
+			CNIPluginName:  BridgePluginName,
+		},
+		Name: TaskBridgeName,
+		IPAM: ipamConfig,
+	}
+}
+
+// createPortMapPluginConfig constructs the configuration object for portmap plugin
+// that publishes the port mappings of a bridge mode task on the host.
+func createPortMapPluginConfig(
+	netNSPath string,
+	netNSName string,
+	portMappings []tasknetworkconfig.PortMapping,
+	prevResult *cnitypes.Result,
+) ecscni.PluginConfig {
+	entries := make([]ecscni.PortMapEntry, 0, len(portMappings))
+	for _, pm := range portMappings {
+		entries = append(entries, ecscni.PortMapEntry{
+			HostPort:      int(pm.HostPort),
+			ContainerPort: int(pm.ContainerPort),
+			Protocol:      pm.Protocol,
+		})
+	}
+
+	return &ecscni.PortMapConfig{
+		CNIConfig: ecscni.CNIConfig{
+			NetNSPath:      netNSPath,
+			CNISpecVersion: portMapCNISpecVersion,
+			CNIPluginName:  PortMapPluginName,
+		},
+		SNAT:          true,
+		RuntimeConfig: ecscni.PortMapRuntimeConfig{PortMappings: entries},
+		PrevResult:    prevResult,
+		ID:            netNSName,
+	}
+}
+
 func createAppMeshPluginConfig(
 	netNSPath string,
 	cfg *appmesh.AppMesh,
@@ -23,17 +23,21 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 
 	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/ec2"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
 	netlibdata "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/data"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/dnscache"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/appmesh"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/ecscni"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/egresspolicy"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/serviceconnect"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/status"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/execwrapper"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/ioutilwrapper"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/netlinkwrapper"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/netwrapper"
@@ -80,6 +84,11 @@ type common struct {
 	cniClient         ecscni.CNI
 	net               netwrapper.Net
 	resolvConfPath    string
+	exec              execwrapper.Exec
+	bridgeSubnet      string
+	dnsCacheConfig    *dnscache.Config
+	dnsCaches         *dnsCaches
+	hostPortAllocator func(taskID, key string, numberOfPorts int, protocol string) (uint16, error)
 }
 
 // NewPlatform creates an implementation of the platform API depending on the
@@ -100,6 +109,14 @@ func NewPlatform(
 		cniClient:         ecscni.NewCNIClient([]string{CNIPluginPathDefault}),
 		net:               netWrapper,
 		resolvConfPath:    platformConfig.ResolvConfPath,
+		exec:              execwrapper.NewExec(),
+		bridgeSubnet:      platformConfig.BridgeSubnet,
+		dnsCacheConfig:    platformConfig.DNSCache,
+		dnsCaches:         newDNSCaches(),
+		hostPortAllocator: platformConfig.HostPortAllocator,
+	}
+	if commonPlatform.bridgeSubnet == "" {
+		commonPlatform.bridgeSubnet = DefaultBridgeSubnet
 	}
 
 	switch platformConfig.Name {
@@ -154,7 +171,10 @@ func (c *common) buildTaskNetworkConfiguration(
 			return nil, errors.Wrap(err, "failed to translate network configuration")
 		}
 	case types.NetworkModeBridge:
-		return nil, errors.New("not implemented")
+		netNSs, err = c.buildBridgeNetworkNamespace(taskID, taskPayload)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to translate network configuration")
+		}
 	case types.NetworkModeHost:
 		return nil, errors.New("not implemented")
 	case types.NetworkModeNone:
@@ -169,6 +189,51 @@ func (c *common) buildTaskNetworkConfiguration(
 	}, nil
 }
 
+// ListNetNSNames returns the names of the network namespaces present on the host.
+func (c *common) ListNetNSNames() ([]string, error) {
+	return c.listDirNames(ecscni.NETNS_PATH_DEFAULT)
+}
+
+// ListDNSConfigNames returns the names of the network namespaces that have a DNS config
+// directory on the host.
+func (c *common) ListDNSConfigNames() ([]string, error) {
+	return c.listDirNames(networkConfigFileDirectory)
+}
+
+// ReleaseOrphanedAddresses releases the IPAM allocations held for a network namespace whose
+// state has been lost. Allocations are keyed by the netns path, so they can be released
+// without knowing the addresses.
+func (c *common) ReleaseOrphanedAddresses(ctx context.Context, netNSName string) error {
+	netNSPath := c.GetNetNSPath(netNSName)
+	c.os.Setenv(CNIPluginLogFileEnv, ecscni.PluginLogPath)
+	c.os.Setenv(IPAMDataPathEnv, filepath.Join(c.stateDBDir, IPAMDataFileName))
+
+	cfg := createBridgePluginConfig(netNSPath)
+	if strings.HasSuffix(netNSName, "-"+BridgeNetNSSuffix) {
+		cfg = createTaskBridgePluginConfig(netNSPath, c.bridgeSubnet, "")
+	}
+	_, err := c.executeCNIPlugin(ctx, false, cfg)
+	if err != nil {
+		return errors.Wrapf(err, "failed to release addresses of netns %s", netNSName)
+	}
+	return nil
+}
+
+// listDirNames returns the names of the entries in a directory. A missing directory has no entries.
+func (c *common) listDirNames(dir string) ([]string, error) {
+	entries, err := c.os.ReadDir(dir)
+	if c.os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "unable to list %s", dir)
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names, nil
+}
+
 func (c *common) GetNetNSPath(netNSName string) string {
 	return c.nsUtil.GetNetNSPath(netNSName)
 }
@@ -196,6 +261,12 @@ func (c *common) buildAWSVPCNetworkNamespaces(
 		return nil, err
 	}
 
+	// The egress policy applies to all the traffic of the task, so it is enforced in every netns.
+	egressPolicy, err := egresspolicy.NewEgressPolicy(taskPayload.EgressPolicy)
+	if err != nil {
+		return nil, err
+	}
+
 	logger.Info("Building network configuration for awsvpc task", map[string]interface{}{
 		"SingleNetNS":            singleNetNS,
 		"ENICount":               len(taskPayload.ElasticNetworkInterfaces),
@@ -216,6 +287,7 @@ func (c *common) buildAWSVPCNetworkNamespaces(
 		if err != nil {
 			return nil, err
 		}
+		primaryNetNS.EgressPolicy = egressPolicy
 
 		return []*tasknetworkconfig.NetworkNamespace{primaryNetNS}, nil
 	}
@@ -274,6 +346,7 @@ func (c *common) buildAWSVPCNetworkNamespaces(
 		if err != nil {
 			return nil, err
 		}
+		netNS.EgressPolicy = egressPolicy
 		netNSs = append(netNSs, netNS)
 		nsIndex += 1
 	}
@@ -379,6 +452,11 @@ func (c *common) DeleteDNSConfig(netNSName string) error {
 	if netNSName == "" {
 		return errors.New("netns name cannot be empty")
 	}
+	if err := c.stopDNSCache(netNSName); err != nil {
+		logger.Warn(fmt.Sprintf("Unable to stop DNS cache: %v", err), map[string]interface{}{
+			"NetNSName": netNSName,
+		})
+	}
 	netNSDir := filepath.Join(networkConfigFileDirectory, netNSName)
 	_, err := c.os.Stat(netNSDir)
 	if c.os.IsNotExist(err) {
@@ -427,6 +505,12 @@ func (c *common) createDNSConfig(
 		"ReuseHostDNSConfig": reuseHostDNSConfig,
 	})
 
+	// Bridge mode tasks have no ENI, so their DNS config is derived from the host and
+	// the address assigned on the task bridge.
+	if netNS.BridgeConfig != nil {
+		return c.createBridgeDNSConfig(taskID, netNS)
+	}
+
 	// For debug mode, resolv.conf and hosts files are same as the host machine if no available
 	// data in the ENI, like DNS resolver.
 	// But for non debug mode they are all created using the data available in the ENI.
@@ -439,6 +523,15 @@ func (c *common) createDNSConfig(
 			return errors.Wrap(err, "unable to copy dns config files")
 		}
 	} else {
+		if c.dnsCacheConfig != nil && len(primaryIF.DomainNameServers) > 0 {
+			// The cache only saves queries to the name servers, so the task can still use
+			// them directly if it cannot be started.
+			if err := c.startDNSCache(netNS, primaryIF); err != nil {
+				logger.Warn(fmt.Sprintf("Unable to start DNS cache: %v", err), map[string]interface{}{
+					"NetNSName": netNS.Name,
+				})
+			}
+		}
 		if err := c.createNetworkConfigFiles(netNS.Name, primaryIF); err != nil {
 			return errors.Wrap(err, "unable to create dns config file")
 		}
@@ -613,7 +706,12 @@ func (c *common) createResolvConfigFile(netConfigFilesDir string, iface *network
 		"NetConfigFilesDir": netConfigFilesDir,
 	})
 
-	data := c.nsUtil.BuildResolvConfig(iface.DomainNameServers, iface.DomainNameSearchList)
+	nameServers := iface.DomainNameServers
+	if cache := c.dnsCacheFor(netConfigFilesDir); cache != nil {
+		// The name servers stay listed after the cache, for when the cache is not running.
+		nameServers = append([]string{cache.address}, nameServers...)
+	}
+	data := c.nsUtil.BuildResolvConfig(nameServers, iface.DomainNameSearchList)
 
 	return c.ioutil.WriteFile(
 		filepath.Join(networkConfigFileDirectory, netConfigFilesDir, ResolveConfFileName),
@@ -495,3 +495,60 @@ func testGeneveInterfaceConfiguration(t *testing.T) {
 	err = commonPlatform.configureInterface(ctx, netNSPath, v2nIface, netDAO)
 	require.NoError(t, err)
 }
+
+func TestCommon_ListNetNSNames(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	osWrapper := mock_oswrapper.NewMockOS(ctrl)
+	commonPlatform := &common{
+		os: osWrapper,
+	}
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "task-netns"), nil, 0644))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	osWrapper.EXPECT().ReadDir(ecscni.NETNS_PATH_DEFAULT).Return(entries, nil)
+	osWrapper.EXPECT().IsNotExist(nil).Return(false)
+	names, err := commonPlatform.ListNetNSNames()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"task-netns"}, names)
+
+	// A missing DNS config directory means that there is nothing to clean up.
+	notExistErr := &fs.PathError{Op: "open", Path: networkConfigFileDirectory, Err: fs.ErrNotExist}
+	osWrapper.EXPECT().ReadDir(networkConfigFileDirectory).Return(nil, notExistErr)
+	osWrapper.EXPECT().IsNotExist(notExistErr).Return(true)
+	names, err = commonPlatform.ListDNSConfigNames()
+	require.NoError(t, err)
+	assert.Empty(t, names)
+}
+
+func TestCommon_ReleaseOrphanedAddresses(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+	osWrapper := mock_oswrapper.NewMockOS(ctrl)
+	cniClient := mock_ecscni2.NewMockCNI(ctrl)
+	nsUtil := mock_ecscni.NewMockNetNSUtil(ctrl)
+	commonPlatform := &common{
+		os:         osWrapper,
+		cniClient:  cniClient,
+		nsUtil:     nsUtil,
+		stateDBDir: "dummy-db-dir",
+	}
+
+	netNSPath := "/var/run/netns/task-bridge"
+	osWrapper.EXPECT().Setenv(gomock.Any(), gomock.Any()).AnyTimes()
+	gomock.InOrder(
+		nsUtil.EXPECT().GetNetNSPath("task-bridge").Return(netNSPath),
+		cniClient.EXPECT().Del(gomock.Any(), createTaskBridgePluginConfig(netNSPath, commonPlatform.bridgeSubnet, "")).
+			Return(nil),
+		nsUtil.EXPECT().GetNetNSPath("task-eni").Return(netNSPath),
+		cniClient.EXPECT().Del(gomock.Any(), createBridgePluginConfig(netNSPath)).Return(errors.New("ipam error")),
+	)
+	require.NoError(t, commonPlatform.ReleaseOrphanedAddresses(ctx, "task-bridge"))
+	require.Error(t, commonPlatform.ReleaseOrphanedAddresses(ctx, "task-eni"))
+}
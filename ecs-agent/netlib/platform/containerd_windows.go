@@ -22,8 +22,10 @@ import (
 	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
 	netlibdata "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/data"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/dnscache"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/appmesh"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/ecscni"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/egresspolicy"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/serviceconnect"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/status"
@@ -212,6 +214,18 @@ func (c *containerd) GetNetNSPath(_ string) string {
 	return c.nsUtil.NewNetNSID()
 }
 
+func (c *containerd) ListNetNSNames() ([]string, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *containerd) ListDNSConfigNames() ([]string, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *containerd) ReleaseOrphanedAddresses(ctx context.Context, netNSName string) error {
+	return errors.New("not implemented")
+}
+
 // ConfigureInterface configures task network interface.
 func (c *containerd) ConfigureInterface(
 	ctx context.Context,
@@ -241,6 +255,25 @@ func (c *containerd) ConfigureServiceConnect(
 	return errors.New("not implemented")
 }
 
+func (c *containerd) ConfigureBridge(ctx context.Context, netNS *tasknetworkconfig.NetworkNamespace) error {
+	return errors.New("bridge network mode is not supported on windows")
+}
+
+func (c *containerd) ConfigureEgressPolicy(ctx context.Context, netNS *tasknetworkconfig.NetworkNamespace) error {
+	return errors.New("not implemented")
+}
+
+func (c *containerd) GetEgressPolicyStats(
+	ctx context.Context,
+	netNS *tasknetworkconfig.NetworkNamespace,
+) (*egresspolicy.Stats, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *containerd) GetDNSCacheStats(netNS *tasknetworkconfig.NetworkNamespace) (*dnscache.Stats, error) {
+	return nil, errors.New("not implemented")
+}
+
 // configureRegularENI configures a network interface for an ENI.
 func (c *containerd) configureRegularENI(ctx context.Context, netNSID string, iface *networkinterface.NetworkInterface) error {
 	var cniNetConf []ecscni.PluginConfig
@@ -0,0 +1,149 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package platform
+
+import (
+	"context"
+	"net"
+	"sync"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/dnscache"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
+
+	cnins "github.com/containernetworking/plugins/pkg/ns"
+	"github.com/pkg/errors"
+)
+
+const (
+	// dnsCacheIPv4Address is the address the caching resolver listens on in IPv4 network namespaces.
+	dnsCacheIPv4Address = "127.0.0.1"
+	// dnsCacheIPv6Address is the address the caching resolver listens on in IPv6-only network namespaces.
+	dnsCacheIPv6Address = "::1"
+	dnsCachePort        = "53"
+)
+
+// dnsCache is a caching resolver running inside a network namespace.
+type dnsCache struct {
+	resolver *dnscache.Resolver
+	address  string
+}
+
+// dnsCaches tracks the caching resolvers running in the network namespaces, by netns name.
+type dnsCaches struct {
+	lock   sync.Mutex
+	caches map[string]*dnsCache
+}
+
+func newDNSCaches() *dnsCaches {
+	return &dnsCaches{caches: make(map[string]*dnsCache)}
+}
+
+// GetDNSCacheStats returns the query counters of the caching resolver running in the network
+// namespace.
+func (c *common) GetDNSCacheStats(netNS *tasknetworkconfig.NetworkNamespace) (*dnscache.Stats, error) {
+	cache := c.dnsCacheFor(netNS.Name)
+	if cache == nil {
+		return nil, errors.New("no DNS cache running in netns " + netNS.Name)
+	}
+	stats := cache.resolver.Stats()
+	return &stats, nil
+}
+
+// startDNSCache starts a caching resolver on the loopback interface of the network namespace,
+// forwarding to the name servers of the interface. The sockets are created inside the network
+// namespace, so that the queries reach the name servers through the task ENI.
+//
+// The resolver stops with the agent. Tasks then fall back to the name servers listed after it
+// in resolv.conf, since the loopback address rejects their queries right away.
+func (c *common) startDNSCache(netNS *tasknetworkconfig.NetworkNamespace, iface *networkinterface.NetworkInterface) error {
+	if c.dnsCacheFor(netNS.Name) != nil {
+		return nil
+	}
+
+	address := dnsCacheIPv4Address
+	if iface.IPv6Only() {
+		address = dnsCacheIPv6Address
+	}
+	listenAddress := net.JoinHostPort(address, dnsCachePort)
+
+	var udpConn net.PacketConn
+	var tcpListener net.Listener
+	err := c.nsUtil.ExecInNSPath(netNS.Path, func(cnins.NetNS) error {
+		var err error
+		if udpConn, err = net.ListenPacket("udp", listenAddress); err != nil {
+			return err
+		}
+		if tcpListener, err = net.Listen("tcp", listenAddress); err != nil {
+			udpConn.Close()
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrapf(err, "unable to listen on %s in netns %s", listenAddress, netNS.Path)
+	}
+
+	dial := func(ctx context.Context, network, nameServer string) (net.Conn, error) {
+		var conn net.Conn
+		err := c.nsUtil.ExecInNSPath(netNS.Path, func(cnins.NetNS) error {
+			var err error
+			conn, err = (&net.Dialer{}).DialContext(ctx, network, nameServer)
+			return err
+		})
+		return conn, err
+	}
+	resolver := dnscache.NewResolver(*c.dnsCacheConfig, iface.DomainNameServers, dial)
+	resolver.Serve(udpConn, tcpListener)
+
+	logger.Info("Started DNS cache", map[string]interface{}{
+		"NetNSName":   netNS.Name,
+		"Address":     address,
+		"NameServers": iface.DomainNameServers,
+	})
+	c.dnsCaches.lock.Lock()
+	c.dnsCaches.caches[netNS.Name] = &dnsCache{resolver: resolver, address: address}
+	c.dnsCaches.lock.Unlock()
+	return nil
+}
+
+// stopDNSCache stops the caching resolver of the network namespace, if any.
+func (c *common) stopDNSCache(netNSName string) error {
+	if c.dnsCaches == nil {
+		return nil
+	}
+	c.dnsCaches.lock.Lock()
+	cache, ok := c.dnsCaches.caches[netNSName]
+	delete(c.dnsCaches.caches, netNSName)
+	c.dnsCaches.lock.Unlock()
+	if !ok {
+		return nil
+	}
+
+	logger.Info("Stopping DNS cache", map[string]interface{}{
+		"NetNSName": netNSName,
+	})
+	return cache.resolver.Close()
+}
+
+// dnsCacheFor returns the caching resolver of the network namespace, or nil if there is none.
+func (c *common) dnsCacheFor(netNSName string) *dnsCache {
+	if c.dnsCaches == nil {
+		return nil
+	}
+	c.dnsCaches.lock.Lock()
+	defer c.dnsCaches.lock.Unlock()
+	return c.dnsCaches.caches[netNSName]
+}
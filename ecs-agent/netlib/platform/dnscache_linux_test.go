@@ -0,0 +1,100 @@
+//go:build !windows && unit
+// +build !windows,unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package platform
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/dnscache"
+	mock_ecscni "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/ecscni/mocks_nsutil"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
+	mock_ioutilwrapper "github.com/aws/amazon-ecs-agent/ecs-agent/utils/ioutilwrapper/mocks"
+	mock_oswrapper "github.com/aws/amazon-ecs-agent/ecs-agent/utils/oswrapper/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const dnsCacheTestNetNS = "dnscache-netns"
+
+func TestCommon_DNSCacheResolvConf(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	nsUtil := mock_ecscni.NewMockNetNSUtil(ctrl)
+	ioutil := mock_ioutilwrapper.NewMockIOUtil(ctrl)
+	osWrapper := mock_oswrapper.NewMockOS(ctrl)
+	commonPlatform := &common{
+		nsUtil:    nsUtil,
+		ioutil:    ioutil,
+		os:        osWrapper,
+		dnsCaches: newDNSCaches(),
+	}
+	iface := &networkinterface.NetworkInterface{
+		DomainNameServers:    []string{"10.0.0.2"},
+		DomainNameSearchList: []string{"us-west-2.compute.internal"},
+	}
+	resolvConf := filepath.Join(networkConfigFileDirectory, dnsCacheTestNetNS, ResolveConfFileName)
+
+	// Without a cache, the name servers of the interface are used directly.
+	nsUtil.EXPECT().BuildResolvConfig(iface.DomainNameServers, iface.DomainNameSearchList).Return("direct")
+	ioutil.EXPECT().WriteFile(resolvConf, []byte("direct"), gomock.Any())
+	require.NoError(t, commonPlatform.createResolvConfigFile(dnsCacheTestNetNS, iface))
+
+	// With a cache, it is listed first and the name servers are kept as a fallback.
+	resolver := dnscache.NewResolver(dnscache.Config{}, iface.DomainNameServers, nil)
+	commonPlatform.dnsCaches.caches[dnsCacheTestNetNS] = &dnsCache{resolver: resolver, address: dnsCacheIPv4Address}
+	nsUtil.EXPECT().BuildResolvConfig([]string{dnsCacheIPv4Address, "10.0.0.2"}, iface.DomainNameSearchList).
+		Return("cached")
+	ioutil.EXPECT().WriteFile(resolvConf, []byte("cached"), gomock.Any())
+	require.NoError(t, commonPlatform.createResolvConfigFile(dnsCacheTestNetNS, iface))
+
+	netNS := &tasknetworkconfig.NetworkNamespace{Name: dnsCacheTestNetNS}
+	stats, err := commonPlatform.GetDNSCacheStats(netNS)
+	require.NoError(t, err)
+	assert.Equal(t, &dnscache.Stats{}, stats)
+
+	// The cache is stopped with the DNS config of the netns.
+	osWrapper.EXPECT().Stat(gomock.Any()).Return(nil, nil)
+	osWrapper.EXPECT().IsNotExist(nil).Return(false)
+	osWrapper.EXPECT().RemoveAll(filepath.Join(networkConfigFileDirectory, dnsCacheTestNetNS)).Return(nil)
+	require.NoError(t, commonPlatform.DeleteDNSConfig(dnsCacheTestNetNS))
+	_, err = commonPlatform.GetDNSCacheStats(netNS)
+	assert.Error(t, err)
+}
+
+func TestCommon_StartDNSCacheError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	nsUtil := mock_ecscni.NewMockNetNSUtil(ctrl)
+	commonPlatform := &common{
+		nsUtil:         nsUtil,
+		dnsCacheConfig: &dnscache.Config{},
+		dnsCaches:      newDNSCaches(),
+	}
+	netNS := &tasknetworkconfig.NetworkNamespace{Name: dnsCacheTestNetNS, Path: "/var/run/netns/" + dnsCacheTestNetNS}
+	iface := &networkinterface.NetworkInterface{DomainNameServers: []string{"10.0.0.2"}}
+
+	nsUtil.EXPECT().ExecInNSPath(netNS.Path, gomock.Any()).Return(errors.New("address in use"))
+	require.Error(t, commonPlatform.startDNSCache(netNS, iface))
+	assert.Nil(t, commonPlatform.dnsCacheFor(dnsCacheTestNetNS))
+}
@@ -0,0 +1,296 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package platform
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/egresspolicy"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/status"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/execwrapper"
+
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+)
+
+const (
+	// EgressPolicyChain is the iptables chain holding the egress policy rules inside a task netns.
+	EgressPolicyChain = "ECS-EGRESS-POLICY"
+
+	ip6tablesCommand  = "ip6tables"
+	restoreSuffix     = "-restore"
+	egressRejectJump  = "REJECT"
+	dnsPort           = "53"
+	taskMetadataIPv4  = "169.254.170.2/32"
+	egressChainHeader = 2
+	anyDestination    = ""
+)
+
+// egressFamily holds the destinations of an egress policy for one IP family.
+type egressFamily struct {
+	command      string
+	destinations []string
+	nameServers  []string
+	alwaysAllow  []string
+	// alwaysAllowProtocols are accepted to any destination, for the protocols the IP
+	// family does not work without.
+	alwaysAllowProtocols []string
+}
+
+// ConfigureEgressPolicy enforces the egress policy of the network namespace when it is desired
+// to be ready and removes it when the network namespace is desired to be deleted.
+func (c *common) ConfigureEgressPolicy(ctx context.Context, netNS *tasknetworkconfig.NetworkNamespace) error {
+	if netNS.EgressPolicy == nil {
+		return nil
+	}
+
+	switch netNS.DesiredState {
+	case status.NetworkReady:
+		return c.applyEgressPolicy(ctx, netNS)
+	case status.NetworkDeleted:
+		return c.removeEgressPolicy(ctx, netNS)
+	}
+	return nil
+}
+
+// GetEgressPolicyStats returns the number of connection attempts and bytes rejected by the egress
+// policy of the network namespace, across both IP families.
+func (c *common) GetEgressPolicyStats(
+	ctx context.Context,
+	netNS *tasknetworkconfig.NetworkNamespace,
+) (*egresspolicy.Stats, error) {
+	if netNS.EgressPolicy == nil {
+		return nil, errors.New("no egress policy configured for netns " + netNS.Name)
+	}
+
+	stats := &egresspolicy.Stats{}
+	for _, command := range []string{iptablesCommand, ip6tablesCommand} {
+		out, err := c.runInNetNS(ctx, netNS.Path, command, "-w", "-L", EgressPolicyChain, "-v", "-x", "-n")
+		if err != nil {
+			return nil, err
+		}
+		packets, bytes, err := parseRejectCounters(out)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to parse %s counters", command)
+		}
+		stats.DeniedConnections += packets
+		stats.DeniedBytes += bytes
+	}
+	return stats, nil
+}
+
+// applyEgressPolicy creates the egress policy chain for both IP families and hooks it into the
+// OUTPUT chain of the network namespace.
+func (c *common) applyEgressPolicy(ctx context.Context, netNS *tasknetworkconfig.NetworkNamespace) error {
+	policy := netNS.EgressPolicy
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+
+	var nameServers []string
+	if primaryIf := netNS.GetPrimaryInterface(); primaryIf != nil {
+		nameServers = primaryIf.DomainNameServers
+	}
+	families, err := c.egressFamilies(ctx, policy, nameServers)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Applying egress policy", map[string]interface{}{
+		"NetNSName":    netNS.Name,
+		"EgressPolicy": policy,
+	})
+	for _, family := range families {
+		// The chain is already hooked into OUTPUT if the policy was applied before.
+		_, err := c.runInNetNS(ctx, netNS.Path, family.command, "-w", "-C", "OUTPUT", "-j", EgressPolicyChain)
+		input := egressPolicyRestoreInput(family, policy.AllowedPorts, err != nil)
+		_, err = execwrapper.RunInNetNSWithInput(ctx, c.exec, nsSetupTimeoutDuration, netNS.Path,
+			bytes.NewReader(input), family.command+restoreSuffix, "-w", "--noflush")
+		if err != nil {
+			return errors.Wrap(err, "failed to apply egress policy")
+		}
+	}
+	return nil
+}
+
+// egressPolicyRestoreInput returns the iptables-restore input that replaces the rules of the
+// egress policy chain of an IP family, and hooks the chain into the OUTPUT chain when hook is
+// set. Declaring the chain creates it, or flushes it if it already exists, and the whole input
+// is committed in a single transaction, so the traffic of the task never goes through a
+// partially filled chain.
+func egressPolicyRestoreInput(family *egressFamily, ports []egresspolicy.PortRange, hook bool) []byte {
+	var input bytes.Buffer
+	fmt.Fprintf(&input, "*filter\n:%s - [0:0]\n", EgressPolicyChain)
+	for _, rule := range egressPolicyRules(family, ports) {
+		fmt.Fprintln(&input, strings.Join(rule, " "))
+	}
+	if hook {
+		fmt.Fprintf(&input, "-I OUTPUT -j %s\n", EgressPolicyChain)
+	}
+	input.WriteString("COMMIT\n")
+	return input.Bytes()
+}
+
+// removeEgressPolicy removes the egress policy chain of both IP families. It is best effort, so
+// every step is attempted even if an earlier one fails.
+func (c *common) removeEgressPolicy(ctx context.Context, netNS *tasknetworkconfig.NetworkNamespace) error {
+	logger.Info("Removing egress policy", map[string]interface{}{
+		"NetNSName": netNS.Name,
+	})
+
+	var errs error
+	for _, command := range []string{iptablesCommand, ip6tablesCommand} {
+		for _, rule := range [][]string{
+			{"-D", "OUTPUT", "-j", EgressPolicyChain},
+			{"-F", EgressPolicyChain},
+			{"-X", EgressPolicyChain},
+		} {
+			if _, err := c.runInNetNS(ctx, netNS.Path, command, append([]string{"-w"}, rule...)...); err != nil {
+				errs = multierror.Append(errs, err)
+			}
+		}
+	}
+	return errs
+}
+
+// egressFamilies splits the destinations of the policy and the name servers of the task by IP
+// family. DNS names are resolved to the addresses they currently point to.
+func (c *common) egressFamilies(
+	ctx context.Context,
+	policy *egresspolicy.EgressPolicy,
+	nameServers []string,
+) ([]*egressFamily, error) {
+	ipv4 := &egressFamily{command: iptablesCommand, alwaysAllow: []string{taskMetadataIPv4}}
+	// Neighbor discovery, which IPv6 needs to reach any destination, relies on ICMPv6.
+	ipv6 := &egressFamily{command: ip6tablesCommand, alwaysAllowProtocols: []string{"ipv6-icmp"}}
+	familyOf := func(ip net.IP) *egressFamily {
+		if ip.To4() != nil {
+			return ipv4
+		}
+		return ipv6
+	}
+
+	for _, cidr := range policy.AllowedCIDRs {
+		ip, _, _ := net.ParseCIDR(cidr)
+		family := familyOf(ip)
+		family.destinations = append(family.destinations, cidr)
+	}
+	for _, name := range policy.AllowedDNSNames {
+		addrs, err := c.net.LookupIPAddr(ctx, name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to resolve egress policy DNS name %s", name)
+		}
+		for _, addr := range addrs {
+			family := familyOf(addr.IP)
+			family.destinations = append(family.destinations, hostCIDR(addr.IP))
+		}
+	}
+	for _, nameServer := range nameServers {
+		if ip := net.ParseIP(nameServer); ip != nil {
+			family := familyOf(ip)
+			family.nameServers = append(family.nameServers, hostCIDR(ip))
+		}
+	}
+	// Without name servers the task uses the resolver configured on the host, whose address
+	// is not known here, so DNS queries are allowed to any destination.
+	if len(ipv4.nameServers) == 0 && len(ipv6.nameServers) == 0 {
+		ipv4.nameServers = []string{anyDestination}
+		ipv6.nameServers = []string{anyDestination}
+	}
+	return []*egressFamily{ipv4, ipv6}, nil
+}
+
+// egressPolicyRules returns the iptables commands that fill the empty egress policy chain of an
+// IP family. Loopback traffic, replies to inbound connections, DNS queries and the protocols the
+// IP family depends on are always accepted.
+// New connections to any other destination that is not allowed are rejected, so that the
+// counters of the reject rule track denied connection attempts.
+func egressPolicyRules(family *egressFamily, ports []egresspolicy.PortRange) [][]string {
+	rules := [][]string{
+		{"-A", EgressPolicyChain, "-o", "lo", "-j", "ACCEPT"},
+		{"-A", EgressPolicyChain, "-m", "conntrack", "--ctstate", "ESTABLISHED,RELATED", "-j", "ACCEPT"},
+	}
+	for _, protocol := range family.alwaysAllowProtocols {
+		rules = append(rules, []string{"-A", EgressPolicyChain, "-p", protocol, "-j", "ACCEPT"})
+	}
+
+	for _, nameServer := range family.nameServers {
+		for _, protocol := range []string{egresspolicy.ProtocolUDP, egresspolicy.ProtocolTCP} {
+			rule := []string{"-A", EgressPolicyChain}
+			if nameServer != anyDestination {
+				rule = append(rule, "-d", nameServer)
+			}
+			rules = append(rules, append(rule, "-p", protocol, "--dport", dnsPort, "-j", "ACCEPT"))
+		}
+	}
+
+	for _, destination := range family.alwaysAllow {
+		rules = append(rules, []string{"-A", EgressPolicyChain, "-d", destination, "-j", "ACCEPT"})
+	}
+	for _, destination := range family.destinations {
+		if len(ports) == 0 {
+			rules = append(rules, []string{"-A", EgressPolicyChain, "-d", destination, "-j", "ACCEPT"})
+			continue
+		}
+		for _, pr := range ports {
+			rules = append(rules, []string{"-A", EgressPolicyChain, "-d", destination,
+				"-p", pr.Protocol, "--dport", pr.String(), "-j", "ACCEPT"})
+		}
+	}
+
+	return append(rules, []string{"-A", EgressPolicyChain, "-j", egressRejectJump})
+}
+
+// runInNetNS runs an iptables command inside the network namespace and returns its output.
+func (c *common) runInNetNS(ctx context.Context, netNSPath, command string, args ...string) ([]byte, error) {
+	return execwrapper.RunInNetNS(ctx, c.exec, nsSetupTimeoutDuration, netNSPath, command, args...)
+}
+
+// parseRejectCounters returns the packet and byte counters of the reject rule in the verbose
+// listing of the egress policy chain.
+func parseRejectCounters(out []byte) (uint64, uint64, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for line := 0; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if line < egressChainHeader || len(fields) < 3 || fields[2] != egressRejectJump {
+			continue
+		}
+		packets, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+		bytes, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+		return packets, bytes, nil
+	}
+	return 0, 0, errors.New("reject rule not found in " + EgressPolicyChain)
+}
+
+// hostCIDR returns the single address CIDR of an IP address.
+func hostCIDR(ip net.IP) string {
+	if ip.To4() != nil {
+		return ip.String() + "/32"
+	}
+	return ip.String() + "/128"
+}
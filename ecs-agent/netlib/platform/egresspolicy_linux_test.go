@@ -0,0 +1,240 @@
+//go:build !windows && unit
+// +build !windows,unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package platform
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/egresspolicy"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/status"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/execwrapper"
+	mock_execwrapper "github.com/aws/amazon-ecs-agent/ecs-agent/utils/execwrapper/mocks"
+	mock_netwrapper "github.com/aws/amazon-ecs-agent/ecs-agent/utils/netwrapper/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const egressTestNetNSPath = "/var/run/netns/egress-netns"
+
+// recordCommands makes the exec mock run every command with the given result, recording each of
+// them as a single string. The standard input of a command, if any, is recorded after it.
+func recordCommands(
+	ctrl *gomock.Controller,
+	execWrapper *mock_execwrapper.MockExec,
+	result func(args []string) ([]byte, error),
+) *[]string {
+	var commands []string
+	execWrapper.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).
+		Return(context.TODO(), func() {}).AnyTimes()
+	execWrapper.EXPECT().CommandContext(gomock.Any(), execwrapper.NsenterCommand, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, args ...string) *mock_execwrapper.MockCmd {
+			commands = append(commands, strings.Join(args, " "))
+			cmd := mock_execwrapper.NewMockCmd(ctrl)
+			cmd.EXPECT().SetIOStreams(gomock.Any(), nil, nil).Do(func(stdin io.Reader, _, _ io.Writer) {
+				input, _ := io.ReadAll(stdin)
+				commands = append(commands, string(input))
+			}).AnyTimes()
+			cmd.EXPECT().CombinedOutput().Return(result(args))
+			return cmd
+		}).AnyTimes()
+	return &commands
+}
+
+func getTestEgressNetNS(policy *egresspolicy.EgressPolicy) *tasknetworkconfig.NetworkNamespace {
+	return &tasknetworkconfig.NetworkNamespace{
+		Name: "egress-netns",
+		Path: egressTestNetNSPath,
+		NetworkInterfaces: []*networkinterface.NetworkInterface{
+			{
+				Default:           true,
+				DomainNameServers: []string{"10.0.0.2"},
+			},
+		},
+		EgressPolicy: policy,
+		DesiredState: status.NetworkReady,
+	}
+}
+
+func TestCommon_ConfigureEgressPolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	execWrapper := mock_execwrapper.NewMockExec(ctrl)
+	netWrapper := mock_netwrapper.NewMockNet(ctrl)
+	commonPlatform := &common{
+		exec: execWrapper,
+		net:  netWrapper,
+	}
+	// The chain does not exist yet, nor is it hooked into OUTPUT.
+	chainHooked := false
+	commands := recordCommands(ctrl, execWrapper, func(args []string) ([]byte, error) {
+		if !chainHooked && strings.Join(args[2:], " ") == "-w -C OUTPUT -j ECS-EGRESS-POLICY" {
+			return nil, errors.New("no chain/target/match by that name")
+		}
+		return nil, nil
+	})
+
+	netNS := getTestEgressNetNS(&egresspolicy.EgressPolicy{
+		AllowedCIDRs:    []string{"10.1.0.0/16"},
+		AllowedDNSNames: []string{"example.com"},
+		AllowedPorts:    []egresspolicy.PortRange{{From: 443, To: 443, Protocol: egresspolicy.ProtocolTCP}},
+	})
+	netWrapper.EXPECT().LookupIPAddr(gomock.Any(), "example.com").Return([]net.IPAddr{
+		{IP: net.ParseIP("93.184.216.34")},
+		{IP: net.ParseIP("2606:2800:220:1::1")},
+	}, nil)
+
+	require.NoError(t, commonPlatform.ConfigureEgressPolicy(context.TODO(), netNS))
+
+	v4 := "--net=" + egressTestNetNSPath + " iptables -w "
+	v6 := "--net=" + egressTestNetNSPath + " ip6tables -w "
+	v4Restore := "--net=" + egressTestNetNSPath + " iptables-restore -w --noflush"
+	v6Restore := "--net=" + egressTestNetNSPath + " ip6tables-restore -w --noflush"
+	v4Rules := "*filter\n" +
+		":ECS-EGRESS-POLICY - [0:0]\n" +
+		"-A ECS-EGRESS-POLICY -o lo -j ACCEPT\n" +
+		"-A ECS-EGRESS-POLICY -m conntrack --ctstate ESTABLISHED,RELATED -j ACCEPT\n" +
+		"-A ECS-EGRESS-POLICY -d 10.0.0.2/32 -p udp --dport 53 -j ACCEPT\n" +
+		"-A ECS-EGRESS-POLICY -d 10.0.0.2/32 -p tcp --dport 53 -j ACCEPT\n" +
+		"-A ECS-EGRESS-POLICY -d 169.254.170.2/32 -j ACCEPT\n" +
+		"-A ECS-EGRESS-POLICY -d 10.1.0.0/16 -p tcp --dport 443 -j ACCEPT\n" +
+		"-A ECS-EGRESS-POLICY -d 93.184.216.34/32 -p tcp --dport 443 -j ACCEPT\n" +
+		"-A ECS-EGRESS-POLICY -j REJECT\n"
+	// IPv6 name servers are not known, so DNS over IPv6 is rejected as well. ICMPv6 is
+	// accepted, since neighbor discovery depends on it.
+	v6Rules := "*filter\n" +
+		":ECS-EGRESS-POLICY - [0:0]\n" +
+		"-A ECS-EGRESS-POLICY -o lo -j ACCEPT\n" +
+		"-A ECS-EGRESS-POLICY -m conntrack --ctstate ESTABLISHED,RELATED -j ACCEPT\n" +
+		"-A ECS-EGRESS-POLICY -p ipv6-icmp -j ACCEPT\n" +
+		"-A ECS-EGRESS-POLICY -d 2606:2800:220:1::1/128 -p tcp --dport 443 -j ACCEPT\n" +
+		"-A ECS-EGRESS-POLICY -j REJECT\n"
+	hook := "-I OUTPUT -j ECS-EGRESS-POLICY\n"
+	commit := "COMMIT\n"
+	assert.Equal(t, []string{
+		v4 + "-C OUTPUT -j ECS-EGRESS-POLICY",
+		v4Restore, v4Rules + hook + commit,
+		v6 + "-C OUTPUT -j ECS-EGRESS-POLICY",
+		v6Restore, v6Rules + hook + commit,
+	}, *commands)
+
+	// Applying the policy again, for instance after an agent restart, replaces the rules of the
+	// existing chain and does not hook it into OUTPUT a second time.
+	*commands = nil
+	chainHooked = true
+	netWrapper.EXPECT().LookupIPAddr(gomock.Any(), "example.com").Return([]net.IPAddr{
+		{IP: net.ParseIP("93.184.216.34")},
+		{IP: net.ParseIP("2606:2800:220:1::1")},
+	}, nil)
+	require.NoError(t, commonPlatform.ConfigureEgressPolicy(context.TODO(), netNS))
+	assert.Equal(t, []string{
+		v4 + "-C OUTPUT -j ECS-EGRESS-POLICY",
+		v4Restore, v4Rules + commit,
+		v6 + "-C OUTPUT -j ECS-EGRESS-POLICY",
+		v6Restore, v6Rules + commit,
+	}, *commands)
+
+	// The rules are removed from both IP families when the netns is deleted.
+	*commands = nil
+	netNS.DesiredState = status.NetworkDeleted
+	require.NoError(t, commonPlatform.ConfigureEgressPolicy(context.TODO(), netNS))
+	assert.Equal(t, []string{
+		v4 + "-D OUTPUT -j ECS-EGRESS-POLICY",
+		v4 + "-F ECS-EGRESS-POLICY",
+		v4 + "-X ECS-EGRESS-POLICY",
+		v6 + "-D OUTPUT -j ECS-EGRESS-POLICY",
+		v6 + "-F ECS-EGRESS-POLICY",
+		v6 + "-X ECS-EGRESS-POLICY",
+	}, *commands)
+}
+
+func TestCommon_ConfigureEgressPolicyErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	netWrapper := mock_netwrapper.NewMockNet(ctrl)
+	commonPlatform := &common{
+		net: netWrapper,
+	}
+
+	// Nothing is configured without a policy.
+	require.NoError(t, commonPlatform.ConfigureEgressPolicy(context.TODO(), getTestEgressNetNS(nil)))
+
+	netNS := getTestEgressNetNS(&egresspolicy.EgressPolicy{AllowedCIDRs: []string{"10.1.0.0"}})
+	require.Error(t, commonPlatform.ConfigureEgressPolicy(context.TODO(), netNS))
+
+	netNS = getTestEgressNetNS(&egresspolicy.EgressPolicy{AllowedDNSNames: []string{"unknown.invalid"}})
+	netWrapper.EXPECT().LookupIPAddr(gomock.Any(), "unknown.invalid").Return(nil, errors.New("no such host"))
+	require.Error(t, commonPlatform.ConfigureEgressPolicy(context.TODO(), netNS))
+}
+
+func TestCommon_ConfigureEgressPolicyWithoutNameServers(t *testing.T) {
+	commonPlatform := &common{}
+	families, err := commonPlatform.egressFamilies(context.TODO(), &egresspolicy.EgressPolicy{}, nil)
+	require.NoError(t, err)
+	for _, family := range families {
+		assert.Equal(t, []string{anyDestination}, family.nameServers)
+	}
+	assert.Contains(t, egressPolicyRules(families[0], nil),
+		[]string{"-A", EgressPolicyChain, "-p", "udp", "--dport", dnsPort, "-j", "ACCEPT"})
+}
+
+func TestCommon_GetEgressPolicyStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	execWrapper := mock_execwrapper.NewMockExec(ctrl)
+	commonPlatform := &common{
+		exec: execWrapper,
+	}
+	listings := map[string]string{
+		iptablesCommand: `Chain ECS-EGRESS-POLICY (1 references)
+    pkts      bytes target     prot opt in     out     source               destination
+     120     9600 ACCEPT     all  --  *      lo      0.0.0.0/0            0.0.0.0/0
+       4      240 REJECT     all  --  *      *       0.0.0.0/0            0.0.0.0/0            reject-with icmp-port-unreachable
+`,
+		ip6tablesCommand: `Chain ECS-EGRESS-POLICY (1 references)
+    pkts      bytes target     prot opt in     out     source               destination
+       1       80 REJECT     all      *      *       ::/0                 ::/0                 reject-with icmp6-port-unreachable
+`,
+	}
+	commands := recordCommands(ctrl, execWrapper, func(args []string) ([]byte, error) {
+		return []byte(listings[args[1]]), nil
+	})
+
+	stats, err := commonPlatform.GetEgressPolicyStats(context.TODO(), getTestEgressNetNS(&egresspolicy.EgressPolicy{}))
+	require.NoError(t, err)
+	assert.Equal(t, &egresspolicy.Stats{DeniedConnections: 5, DeniedBytes: 320}, stats)
+	assert.Len(t, *commands, 2)
+
+	_, err = commonPlatform.GetEgressPolicyStats(context.TODO(), getTestEgressNetNS(nil))
+	assert.Error(t, err)
+}
+
+func TestParseRejectCounters(t *testing.T) {
+	_, _, err := parseRejectCounters([]byte("Chain ECS-EGRESS-POLICY (1 references)\n"))
+	assert.Error(t, err)
+}
@@ -53,6 +53,11 @@ func (m *managedLinux) BuildTaskNetworkConfiguration(
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to translate network configuration")
 		}
+	case types.NetworkModeBridge:
+		netNSs, err = m.common.buildBridgeNetworkNamespace(taskID, taskPayload)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to translate network configuration")
+		}
 	case types.NetworkModeHost:
 		netNSs, err = m.buildDefaultNetworkNamespace(taskID)
 		if err != nil {
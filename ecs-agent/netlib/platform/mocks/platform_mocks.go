@@ -24,7 +24,9 @@ import (
 
 	ecsacs "github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
 	data "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/data"
+	dnscache "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/dnscache"
 	appmesh "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/appmesh"
+	egresspolicy "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/egresspolicy"
 	networkinterface "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
 	serviceconnect "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/serviceconnect"
 	tasknetworkconfig "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
@@ -83,6 +85,34 @@ func (mr *MockAPIMockRecorder) ConfigureAppMesh(arg0, arg1, arg2 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfigureAppMesh", reflect.TypeOf((*MockAPI)(nil).ConfigureAppMesh), arg0, arg1, arg2)
 }
 
+// ConfigureBridge mocks base method.
+func (m *MockAPI) ConfigureBridge(arg0 context.Context, arg1 *tasknetworkconfig.NetworkNamespace) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfigureBridge", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfigureBridge indicates an expected call of ConfigureBridge.
+func (mr *MockAPIMockRecorder) ConfigureBridge(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfigureBridge", reflect.TypeOf((*MockAPI)(nil).ConfigureBridge), arg0, arg1)
+}
+
+// ConfigureEgressPolicy mocks base method.
+func (m *MockAPI) ConfigureEgressPolicy(arg0 context.Context, arg1 *tasknetworkconfig.NetworkNamespace) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfigureEgressPolicy", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfigureEgressPolicy indicates an expected call of ConfigureEgressPolicy.
+func (mr *MockAPIMockRecorder) ConfigureEgressPolicy(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfigureEgressPolicy", reflect.TypeOf((*MockAPI)(nil).ConfigureEgressPolicy), arg0, arg1)
+}
+
 // ConfigureInterface mocks base method.
 func (m *MockAPI) ConfigureInterface(arg0 context.Context, arg1 string, arg2 *networkinterface.NetworkInterface, arg3 data.NetworkDataClient) error {
 	m.ctrl.T.Helper()
@@ -167,6 +197,36 @@ func (mr *MockAPIMockRecorder) DeleteNetNS(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNetNS", reflect.TypeOf((*MockAPI)(nil).DeleteNetNS), arg0)
 }
 
+// GetDNSCacheStats mocks base method.
+func (m *MockAPI) GetDNSCacheStats(arg0 *tasknetworkconfig.NetworkNamespace) (*dnscache.Stats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDNSCacheStats", arg0)
+	ret0, _ := ret[0].(*dnscache.Stats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDNSCacheStats indicates an expected call of GetDNSCacheStats.
+func (mr *MockAPIMockRecorder) GetDNSCacheStats(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDNSCacheStats", reflect.TypeOf((*MockAPI)(nil).GetDNSCacheStats), arg0)
+}
+
+// GetEgressPolicyStats mocks base method.
+func (m *MockAPI) GetEgressPolicyStats(arg0 context.Context, arg1 *tasknetworkconfig.NetworkNamespace) (*egresspolicy.Stats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEgressPolicyStats", arg0, arg1)
+	ret0, _ := ret[0].(*egresspolicy.Stats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEgressPolicyStats indicates an expected call of GetEgressPolicyStats.
+func (mr *MockAPIMockRecorder) GetEgressPolicyStats(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEgressPolicyStats", reflect.TypeOf((*MockAPI)(nil).GetEgressPolicyStats), arg0, arg1)
+}
+
 // GetNetNSPath mocks base method.
 func (m *MockAPI) GetNetNSPath(arg0 string) string {
 	m.ctrl.T.Helper()
@@ -194,3 +254,47 @@ func (mr *MockAPIMockRecorder) HandleHostMode() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleHostMode", reflect.TypeOf((*MockAPI)(nil).HandleHostMode))
 }
+
+// ListDNSConfigNames mocks base method.
+func (m *MockAPI) ListDNSConfigNames() ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDNSConfigNames")
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDNSConfigNames indicates an expected call of ListDNSConfigNames.
+func (mr *MockAPIMockRecorder) ListDNSConfigNames() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDNSConfigNames", reflect.TypeOf((*MockAPI)(nil).ListDNSConfigNames))
+}
+
+// ListNetNSNames mocks base method.
+func (m *MockAPI) ListNetNSNames() ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListNetNSNames")
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListNetNSNames indicates an expected call of ListNetNSNames.
+func (mr *MockAPIMockRecorder) ListNetNSNames() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNetNSNames", reflect.TypeOf((*MockAPI)(nil).ListNetNSNames))
+}
+
+// ReleaseOrphanedAddresses mocks base method.
+func (m *MockAPI) ReleaseOrphanedAddresses(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseOrphanedAddresses", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseOrphanedAddresses indicates an expected call of ReleaseOrphanedAddresses.
+func (mr *MockAPIMockRecorder) ReleaseOrphanedAddresses(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseOrphanedAddresses", reflect.TypeOf((*MockAPI)(nil).ReleaseOrphanedAddresses), arg0, arg1)
+}
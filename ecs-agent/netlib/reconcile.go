@@ -0,0 +1,272 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package netlib
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/metrics"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/status"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+)
+
+// taskNetNSNameRegex matches the names netlib gives to task network namespaces, which start
+// with the ID of the task. Only network resources with such a name are ever cleaned up, so
+// that namespaces created by other software on the host are left alone.
+var taskNetNSNameRegex = regexp.MustCompile(
+	`^([0-9a-f]{32}|[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})-.+$`)
+
+// ReconcileReport describes the orphaned network resources found, and cleaned up, by a
+// reconciliation pass.
+type ReconcileReport struct {
+	StartedAt time.Time
+	// OrphanedNetNS lists the persisted network namespaces of tasks that are no longer running.
+	OrphanedNetNS []string
+	// UntrackedNetNS lists the task network namespaces present on the host without any persisted
+	// state, typically because the agent stopped while creating them.
+	UntrackedNetNS []string
+	// OrphanedDNSConfigs lists the DNS config directories left behind by deleted network namespaces.
+	OrphanedDNSConfigs []string
+	// Errors lists the failures to clean up orphaned resources. They are retried on the next pass.
+	Errors []string
+}
+
+// Reconcile compares the network namespaces persisted by netlib and present on the host with the
+// tasks that are running, and cleans up the network resources that do not belong to any of them.
+// The running tasks are only fetched after the host has been inspected, so that a network
+// namespace created concurrently always belongs to a task that is reported as running.
+func (nb *networkBuilder) Reconcile(
+	ctx context.Context,
+	activeTaskIDs func() ([]string, error),
+) (*ReconcileReport, error) {
+	report := &ReconcileReport{StartedAt: time.Now()}
+	metricEntry := nb.metricsFactory.New(metrics.ReconcileNetworkMetricName)
+
+	hostNetNS, dnsConfigs, persisted, taskIDs, err := nb.reconcileInputs(activeTaskIDs)
+	if err != nil {
+		metricEntry.Done(err)
+		return nil, err
+	}
+	isActive := func(netNSName string) bool {
+		for _, taskID := range taskIDs {
+			if strings.HasPrefix(netNSName, taskID+"-") {
+				return true
+			}
+		}
+		return false
+	}
+	onHost := make(map[string]bool)
+	for _, name := range hostNetNS {
+		onHost[name] = true
+	}
+
+	var errs error
+	handled := make(map[string]bool)
+	for _, netNS := range persisted {
+		handled[netNS.Name] = true
+		if isActive(netNS.Name) {
+			continue
+		}
+		report.OrphanedNetNS = append(report.OrphanedNetNS, netNS.Name)
+		err := nb.reconcileOrphanedNetNS(ctx, netNS.Name, onHost[netNS.Name], contains(dnsConfigs, netNS.Name))
+		if err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+
+	for _, name := range hostNetNS {
+		if handled[name] || isActive(name) || !taskNetNSNameRegex.MatchString(name) {
+			continue
+		}
+		handled[name] = true
+		report.UntrackedNetNS = append(report.UntrackedNetNS, name)
+		if err := nb.cleanupUntrackedNetNS(ctx, name, contains(dnsConfigs, name)); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+
+	for _, name := range dnsConfigs {
+		if handled[name] || isActive(name) || !taskNetNSNameRegex.MatchString(name) {
+			continue
+		}
+		report.OrphanedDNSConfigs = append(report.OrphanedDNSConfigs, name)
+		if err := nb.platformAPI.DeleteDNSConfig(name); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+
+	if merr, ok := errs.(*multierror.Error); ok {
+		for _, err := range merr.Errors {
+			report.Errors = append(report.Errors, err.Error())
+		}
+	}
+	logFields := logger.Fields{
+		"OrphanedNetNS":      len(report.OrphanedNetNS),
+		"UntrackedNetNS":     len(report.UntrackedNetNS),
+		"OrphanedDNSConfigs": len(report.OrphanedDNSConfigs),
+		"Errors":             len(report.Errors),
+	}
+	logger.Info("Reconciled task network resources", logFields)
+	metricEntry.WithFields(logFields).
+		WithCount(len(report.OrphanedNetNS) + len(report.UntrackedNetNS) + len(report.OrphanedDNSConfigs)).
+		Done(errs)
+
+	return report, errs
+}
+
+// reconcileInputs returns the state that is compared by a reconciliation pass. The running tasks
+// are fetched last.
+func (nb *networkBuilder) reconcileInputs(
+	activeTaskIDs func() ([]string, error),
+) ([]string, []string, []*tasknetworkconfig.NetworkNamespace, []string, error) {
+	hostNetNS, err := nb.platformAPI.ListNetNSNames()
+	if err != nil {
+		return nil, nil, nil, nil, errors.Wrap(err, "unable to list network namespaces on the host")
+	}
+	dnsConfigs, err := nb.platformAPI.ListDNSConfigNames()
+	if err != nil {
+		return nil, nil, nil, nil, errors.Wrap(err, "unable to list DNS configs on the host")
+	}
+	persisted, err := nb.networkDAO.ListNetworkNamespaces()
+	if err != nil {
+		return nil, nil, nil, nil, errors.Wrap(err, "unable to list persisted network namespaces")
+	}
+	taskIDs, err := activeTaskIDs()
+	if err != nil {
+		return nil, nil, nil, nil, errors.Wrap(err, "unable to list running tasks")
+	}
+	return hostNetNS, dnsConfigs, persisted, taskIDs, nil
+}
+
+// reconcileOrphanedNetNS cleans up a persisted network namespace while holding the lock of its
+// task, so that it is never torn down concurrently with a Stop of the same task. The state is
+// read again under the lock since that Stop may have changed it.
+func (nb *networkBuilder) reconcileOrphanedNetNS(
+	ctx context.Context,
+	netNSName string,
+	onHost bool,
+	hasDNSConfig bool,
+) error {
+	taskID := netNSTaskID(netNSName)
+	defer nb.taskLocks.lock(taskID)()
+
+	netNSs, err := nb.networkDAO.GetNetworkNamespacesByTaskID(taskID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read state of orphaned netns %s", netNSName)
+	}
+	for _, netNS := range netNSs {
+		if netNS.Name != netNSName {
+			continue
+		}
+		if netNS.KnownState == status.NetworkDeleted {
+			return nb.deleteOrphanedNetNSState(netNSName)
+		}
+		return nb.cleanupOrphanedNetNS(ctx, netNS, onHost, hasDNSConfig)
+	}
+	// The state was deleted in the meantime.
+	return nil
+}
+
+// cleanupOrphanedNetNS tears down a persisted network namespace the same way it is done when its
+// task stops, then deletes its persisted state. The state is kept for the next pass if the
+// teardown failed, so that it is retried. When the network namespace is already gone from the
+// host, only its addresses and DNS config are left to release.
+func (nb *networkBuilder) cleanupOrphanedNetNS(
+	ctx context.Context,
+	netNS *tasknetworkconfig.NetworkNamespace,
+	onHost bool,
+	hasDNSConfig bool,
+) error {
+	logger.Warn("Cleaning up orphaned network namespace", logger.Fields{
+		"NetNSName": netNS.Name,
+		"OnHost":    onHost,
+	})
+
+	if onHost {
+		mode := types.NetworkModeAwsvpc
+		if netNS.BridgeConfig != nil {
+			mode = types.NetworkModeBridge
+		}
+		netNS.DesiredState = status.NetworkDeleted
+		if err := nb.stop(ctx, mode, netNS); err != nil {
+			return errors.Wrapf(err, "failed to clean up orphaned netns %s", netNS.Name)
+		}
+	} else {
+		if err := nb.platformAPI.ReleaseOrphanedAddresses(ctx, netNS.Name); err != nil {
+			return err
+		}
+		if hasDNSConfig {
+			if err := nb.platformAPI.DeleteDNSConfig(netNS.Name); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nb.deleteOrphanedNetNSState(netNS.Name)
+}
+
+func (nb *networkBuilder) deleteOrphanedNetNSState(netNSName string) error {
+	if err := nb.networkDAO.DeleteNetworkNamespace(netNSName); err != nil {
+		return errors.Wrapf(err, "failed to delete state of orphaned netns %s", netNSName)
+	}
+	return nil
+}
+
+// cleanupUntrackedNetNS deletes a task network namespace that has no persisted state, along with
+// its DNS config and addresses. Veth pairs are removed by the kernel with the network namespace.
+func (nb *networkBuilder) cleanupUntrackedNetNS(ctx context.Context, netNSName string, hasDNSConfig bool) error {
+	logger.Warn("Cleaning up untracked network namespace", logger.Fields{
+		"NetNSName": netNSName,
+	})
+
+	var errs error
+	if err := nb.platformAPI.ReleaseOrphanedAddresses(ctx, netNSName); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+	if hasDNSConfig {
+		if err := nb.platformAPI.DeleteDNSConfig(netNSName); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+	if err := nb.platformAPI.DeleteNetNS(nb.platformAPI.GetNetNSPath(netNSName)); err != nil {
+		errs = multierror.Append(errs, errors.Wrapf(err, "failed to delete untracked netns %s", netNSName))
+	}
+	return errs
+}
+
+// netNSTaskID returns the ID of the task a network namespace belongs to, or its name when it
+// was not named by netlib.
+func netNSTaskID(netNSName string) string {
+	if match := taskNetNSNameRegex.FindStringSubmatch(netNSName); match != nil {
+		return match[1]
+	}
+	return netNSName
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
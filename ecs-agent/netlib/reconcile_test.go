@@ -0,0 +1,287 @@
+//go:build !windows && unit
+// +build !windows,unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package netlib
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/metrics"
+	mock_metrics "github.com/aws/amazon-ecs-agent/ecs-agent/metrics/mocks"
+	mock_data "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/data/mocks"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/status"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
+	mock_platform "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/platform/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	activeTaskID    = "0123456789abcdef0123456789abcdef"
+	stoppedTaskID   = "fedcba9876543210fedcba9876543210"
+	untrackedTaskID = "11111111-2222-3333-4444-555555555555"
+)
+
+func TestNetworkBuilder_Reconcile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+	platformAPI := mock_platform.NewMockAPI(ctrl)
+	metricsFactory := mock_metrics.NewMockEntryFactory(ctrl)
+	netDao := mock_data.NewMockNetworkDataClient(ctrl)
+	netBuilder := &networkBuilder{
+		platformAPI:    platformAPI,
+		metricsFactory: metricsFactory,
+		networkDAO:     netDao,
+	}
+
+	activeNetNS := &tasknetworkconfig.NetworkNamespace{Name: activeTaskID + "-bridge"}
+	stoppedNetNS := &tasknetworkconfig.NetworkNamespace{
+		Name:         stoppedTaskID + "-bridge",
+		Path:         "/var/run/netns/" + stoppedTaskID + "-bridge",
+		BridgeConfig: &tasknetworkconfig.BridgeConfig{},
+		KnownState:   status.NetworkReady,
+	}
+	untrackedNetNS := untrackedTaskID + "-bridge"
+	leakedDNSConfig := "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa-eni"
+
+	reconcileEntry := mock_metrics.NewMockEntry(ctrl)
+	stopEntry := mock_metrics.NewMockEntry(ctrl)
+	gomock.InOrder(
+		metricsFactory.EXPECT().New(metrics.ReconcileNetworkMetricName).Return(reconcileEntry),
+		platformAPI.EXPECT().ListNetNSNames().Return([]string{
+			activeNetNS.Name, stoppedNetNS.Name, untrackedNetNS, "cni-not-a-task",
+		}, nil),
+		platformAPI.EXPECT().ListDNSConfigNames().Return([]string{
+			activeNetNS.Name, untrackedNetNS, leakedDNSConfig, "resolv.conf.d",
+		}, nil),
+		netDao.EXPECT().ListNetworkNamespaces().Return(
+			[]*tasknetworkconfig.NetworkNamespace{activeNetNS, stoppedNetNS}, nil),
+
+		// The persisted netns of the stopped task is torn down like a regular bridge netns.
+		netDao.EXPECT().GetNetworkNamespacesByTaskID(stoppedTaskID).Return(
+			[]*tasknetworkconfig.NetworkNamespace{stoppedNetNS}, nil),
+		metricsFactory.EXPECT().New(metrics.DeleteNetworkNamespaceMetricName).Return(stopEntry),
+		stopEntry.EXPECT().WithFields(gomock.Any()).Return(stopEntry),
+		platformAPI.EXPECT().ConfigureBridge(ctx, stoppedNetNS).Return(nil),
+		platformAPI.EXPECT().DeleteDNSConfig(stoppedNetNS.Name).Return(nil),
+		platformAPI.EXPECT().DeleteNetNS(stoppedNetNS.Path).Return(nil),
+		stopEntry.EXPECT().Done(nil),
+		netDao.EXPECT().DeleteNetworkNamespace(stoppedNetNS.Name).Return(nil),
+
+		// The untracked netns has no state, so its addresses are released by name.
+		platformAPI.EXPECT().ReleaseOrphanedAddresses(ctx, untrackedNetNS).Return(nil),
+		platformAPI.EXPECT().DeleteDNSConfig(untrackedNetNS).Return(nil),
+		platformAPI.EXPECT().GetNetNSPath(untrackedNetNS).Return("/var/run/netns/"+untrackedNetNS),
+		platformAPI.EXPECT().DeleteNetNS("/var/run/netns/"+untrackedNetNS).Return(errors.New("busy")),
+
+		platformAPI.EXPECT().DeleteDNSConfig(leakedDNSConfig).Return(nil),
+
+		reconcileEntry.EXPECT().WithFields(gomock.Any()).Return(reconcileEntry),
+		reconcileEntry.EXPECT().WithCount(3).Return(reconcileEntry),
+		reconcileEntry.EXPECT().Done(gomock.Any()),
+	)
+
+	report, err := netBuilder.Reconcile(ctx, func() ([]string, error) {
+		return []string{activeTaskID}, nil
+	})
+	require.Error(t, err)
+	assert.Equal(t, []string{stoppedNetNS.Name}, report.OrphanedNetNS)
+	assert.Equal(t, []string{untrackedNetNS}, report.UntrackedNetNS)
+	assert.Equal(t, []string{leakedDNSConfig}, report.OrphanedDNSConfigs)
+	assert.Len(t, report.Errors, 1)
+	assert.Equal(t, status.NetworkDeleted, stoppedNetNS.DesiredState)
+}
+
+func TestNetworkBuilder_ReconcileKeepsStateOnFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+	platformAPI := mock_platform.NewMockAPI(ctrl)
+	metricsFactory := mock_metrics.NewMockEntryFactory(ctrl)
+	netDao := mock_data.NewMockNetworkDataClient(ctrl)
+	netBuilder := &networkBuilder{
+		platformAPI:    platformAPI,
+		metricsFactory: metricsFactory,
+		networkDAO:     netDao,
+	}
+
+	stoppedNetNS := &tasknetworkconfig.NetworkNamespace{
+		Name:         stoppedTaskID + "-bridge",
+		Path:         "/var/run/netns/" + stoppedTaskID + "-bridge",
+		BridgeConfig: &tasknetworkconfig.BridgeConfig{},
+	}
+
+	mockEntry := mock_metrics.NewMockEntry(ctrl)
+	metricsFactory.EXPECT().New(gomock.Any()).Return(mockEntry).AnyTimes()
+	mockEntry.EXPECT().WithFields(gomock.Any()).Return(mockEntry).AnyTimes()
+	mockEntry.EXPECT().WithCount(gomock.Any()).Return(mockEntry).AnyTimes()
+	mockEntry.EXPECT().Done(gomock.Any()).AnyTimes()
+	platformAPI.EXPECT().ListNetNSNames().Return([]string{stoppedNetNS.Name}, nil)
+	platformAPI.EXPECT().ListDNSConfigNames().Return(nil, nil)
+	netDao.EXPECT().ListNetworkNamespaces().Return([]*tasknetworkconfig.NetworkNamespace{stoppedNetNS}, nil)
+	netDao.EXPECT().GetNetworkNamespacesByTaskID(stoppedTaskID).Return(
+		[]*tasknetworkconfig.NetworkNamespace{stoppedNetNS}, nil)
+	platformAPI.EXPECT().ConfigureBridge(ctx, stoppedNetNS).Return(errors.New("ipam error"))
+	platformAPI.EXPECT().DeleteDNSConfig(stoppedNetNS.Name).Return(nil)
+	platformAPI.EXPECT().DeleteNetNS(stoppedNetNS.Path).Return(nil)
+	// DeleteNetworkNamespace is not expected: the addresses are released on the next pass.
+
+	report, err := netBuilder.Reconcile(ctx, func() ([]string, error) { return nil, nil })
+	require.Error(t, err)
+	assert.Equal(t, []string{stoppedNetNS.Name}, report.OrphanedNetNS)
+	assert.Empty(t, report.UntrackedNetNS)
+
+	// The netns is gone on the next pass, so its addresses are released by name.
+	platformAPI.EXPECT().ListNetNSNames().Return(nil, nil)
+	platformAPI.EXPECT().ListDNSConfigNames().Return(nil, nil)
+	netDao.EXPECT().ListNetworkNamespaces().Return([]*tasknetworkconfig.NetworkNamespace{stoppedNetNS}, nil)
+	netDao.EXPECT().GetNetworkNamespacesByTaskID(stoppedTaskID).Return(
+		[]*tasknetworkconfig.NetworkNamespace{stoppedNetNS}, nil)
+	platformAPI.EXPECT().ReleaseOrphanedAddresses(ctx, stoppedNetNS.Name).Return(nil)
+	netDao.EXPECT().DeleteNetworkNamespace(stoppedNetNS.Name).Return(nil)
+	report, err = netBuilder.Reconcile(ctx, func() ([]string, error) { return nil, nil })
+	require.NoError(t, err)
+	assert.Equal(t, []string{stoppedNetNS.Name}, report.OrphanedNetNS)
+
+	// Nothing is cleaned up if the running tasks are not known.
+	platformAPI.EXPECT().ListNetNSNames().Return([]string{stoppedNetNS.Name}, nil)
+	platformAPI.EXPECT().ListDNSConfigNames().Return(nil, nil)
+	netDao.EXPECT().ListNetworkNamespaces().Return([]*tasknetworkconfig.NetworkNamespace{stoppedNetNS}, nil)
+	report, err = netBuilder.Reconcile(ctx, func() ([]string, error) { return nil, errors.New("engine error") })
+	require.Error(t, err)
+	assert.Nil(t, report)
+}
+
+func TestNetworkBuilder_ReconcileConcurrentStop(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+	platformAPI := mock_platform.NewMockAPI(ctrl)
+	metricsFactory := mock_metrics.NewMockEntryFactory(ctrl)
+	netDao := mock_data.NewMockNetworkDataClient(ctrl)
+	netBuilder := &networkBuilder{
+		platformAPI:    platformAPI,
+		metricsFactory: metricsFactory,
+		networkDAO:     netDao,
+	}
+
+	stoppedNetNS := &tasknetworkconfig.NetworkNamespace{
+		Name:         stoppedTaskID + "-bridge",
+		Path:         "/var/run/netns/" + stoppedTaskID + "-bridge",
+		BridgeConfig: &tasknetworkconfig.BridgeConfig{},
+		KnownState:   status.NetworkReady,
+	}
+	deletedNetNS := &tasknetworkconfig.NetworkNamespace{
+		Name:       stoppedNetNS.Name,
+		KnownState: status.NetworkDeleted,
+	}
+
+	mockEntry := mock_metrics.NewMockEntry(ctrl)
+	metricsFactory.EXPECT().New(gomock.Any()).Return(mockEntry).AnyTimes()
+	mockEntry.EXPECT().WithFields(gomock.Any()).Return(mockEntry).AnyTimes()
+	mockEntry.EXPECT().WithCount(gomock.Any()).Return(mockEntry).AnyTimes()
+	mockEntry.EXPECT().Done(gomock.Any()).AnyTimes()
+
+	// The task stopped its netns between the listing and the cleanup, so only the state is deleted.
+	platformAPI.EXPECT().ListNetNSNames().Return([]string{stoppedNetNS.Name}, nil)
+	platformAPI.EXPECT().ListDNSConfigNames().Return(nil, nil)
+	netDao.EXPECT().ListNetworkNamespaces().Return([]*tasknetworkconfig.NetworkNamespace{stoppedNetNS}, nil)
+	netDao.EXPECT().GetNetworkNamespacesByTaskID(stoppedTaskID).Return(
+		[]*tasknetworkconfig.NetworkNamespace{deletedNetNS}, nil)
+	netDao.EXPECT().DeleteNetworkNamespace(stoppedNetNS.Name).Return(nil)
+	_, err := netBuilder.Reconcile(ctx, func() ([]string, error) { return nil, nil })
+	require.NoError(t, err)
+
+	// The state is already gone, so nothing is torn down.
+	platformAPI.EXPECT().ListNetNSNames().Return([]string{stoppedNetNS.Name}, nil)
+	platformAPI.EXPECT().ListDNSConfigNames().Return(nil, nil)
+	netDao.EXPECT().ListNetworkNamespaces().Return([]*tasknetworkconfig.NetworkNamespace{stoppedNetNS}, nil)
+	netDao.EXPECT().GetNetworkNamespacesByTaskID(stoppedTaskID).Return(nil, nil)
+	_, err = netBuilder.Reconcile(ctx, func() ([]string, error) { return nil, nil })
+	require.NoError(t, err)
+}
+
+func TestTaskLocks(t *testing.T) {
+	var locks taskLocks
+	unlock := locks.lock(stoppedTaskID)
+
+	locked := make(chan struct{})
+	go func() {
+		locks.lock(stoppedTaskID)()
+		close(locked)
+	}()
+	select {
+	case <-locked:
+		t.Fatal("task lock acquired twice")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Other tasks are not blocked.
+	locks.lock(activeTaskID)()
+
+	unlock()
+	<-locked
+	locks.mu.Lock()
+	defer locks.mu.Unlock()
+	assert.Empty(t, locks.locks)
+}
+
+// reconcileOnlyBuilder is a network builder that only implements Reconcile. The netlib mocks
+// cannot be used inside the netlib package.
+type reconcileOnlyBuilder struct {
+	NetworkBuilder
+	reconcile func() (*ReconcileReport, error)
+}
+
+func (b *reconcileOnlyBuilder) Reconcile(context.Context, func() ([]string, error)) (*ReconcileReport, error) {
+	return b.reconcile()
+}
+
+func TestNetworkReconciler(t *testing.T) {
+	builder := &reconcileOnlyBuilder{}
+	reconciler := NewNetworkReconciler(builder, func() ([]string, error) { return nil, nil }, time.Hour)
+	assert.Nil(t, reconciler.LastReport())
+
+	recorder := httptest.NewRecorder()
+	reconciler.IntrospectionHandler()(recorder, httptest.NewRequest(http.MethodGet, ReconcilerIntrospectionPath, nil))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+
+	// Run reconciles as soon as it starts.
+	ctx, cancel := context.WithCancel(context.TODO())
+	report := &ReconcileReport{UntrackedNetNS: []string{untrackedTaskID + "-bridge"}}
+	builder.reconcile = func() (*ReconcileReport, error) {
+		cancel()
+		return report, nil
+	}
+	reconciler.Run(ctx)
+	assert.Equal(t, report, reconciler.LastReport())
+
+	recorder = httptest.NewRecorder()
+	reconciler.IntrospectionHandler()(recorder, httptest.NewRequest(http.MethodGet, ReconcilerIntrospectionPath, nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), untrackedTaskID)
+}
@@ -0,0 +1,114 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package netlib
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	tmdsutils "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/utils"
+)
+
+const (
+	// ReconcilerIntrospectionPath is the introspection path serving the last reconciliation report.
+	ReconcilerIntrospectionPath = "/v1/netlib/reconcile"
+
+	// DefaultReconcileInterval is the default interval between two reconciliation passes.
+	DefaultReconcileInterval = 15 * time.Minute
+
+	reconcileRequestType = "netlib reconcile"
+)
+
+// NetworkReconciler runs the reconciliation of the network builder when the agent starts, and then
+// periodically, and keeps the report of the last pass.
+type NetworkReconciler struct {
+	builder       NetworkBuilder
+	activeTaskIDs func() ([]string, error)
+	interval      time.Duration
+
+	lock       sync.RWMutex
+	lastReport *ReconcileReport
+}
+
+// NewNetworkReconciler creates a reconciler for the network builder. activeTaskIDs must return the
+// IDs of all the tasks known to the task engine that have not been cleaned up yet.
+func NewNetworkReconciler(
+	builder NetworkBuilder,
+	activeTaskIDs func() ([]string, error),
+	interval time.Duration,
+) *NetworkReconciler {
+	if interval <= 0 {
+		interval = DefaultReconcileInterval
+	}
+	return &NetworkReconciler{
+		builder:       builder,
+		activeTaskIDs: activeTaskIDs,
+		interval:      interval,
+	}
+}
+
+// Run reconciles immediately, then at every interval until the context is cancelled.
+func (r *NetworkReconciler) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.reconcile(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// LastReport returns the report of the last completed reconciliation pass, or nil if no pass
+// completed yet.
+func (r *NetworkReconciler) LastReport() *ReconcileReport {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.lastReport
+}
+
+// IntrospectionHandler serves the last reconciliation report. It is meant to be registered on the
+// introspection server at ReconcilerIntrospectionPath.
+func (r *NetworkReconciler) IntrospectionHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		report := r.LastReport()
+		if report == nil {
+			tmdsutils.WriteJSONResponse(w, http.StatusNotFound,
+				"no network reconciliation has completed yet", reconcileRequestType)
+			return
+		}
+		tmdsutils.WriteJSONResponse(w, http.StatusOK, report, reconcileRequestType)
+	}
+}
+
+func (r *NetworkReconciler) reconcile(ctx context.Context) {
+	report, err := r.builder.Reconcile(ctx, r.activeTaskIDs)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Network reconciliation failed: %v", err))
+	}
+	// Partial reports are kept, since they still list the resources found.
+	if report == nil {
+		return
+	}
+	r.lock.Lock()
+	r.lastReport = report
+	r.lock.Unlock()
+}
@@ -0,0 +1,55 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package netlib
+
+import "sync"
+
+// taskLocks holds one lock per task, so that the network namespaces of a task are never set up
+// or torn down while they are being reconciled. The zero value is ready to use.
+type taskLocks struct {
+	mu    sync.Mutex
+	locks map[string]*taskLock
+}
+
+type taskLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lock acquires the lock of the task and returns the function that releases it. Locks are
+// dropped once no one holds or waits for them.
+func (l *taskLocks) lock(taskID string) func() {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*taskLock)
+	}
+	tl, ok := l.locks[taskID]
+	if !ok {
+		tl = &taskLock{}
+		l.locks[taskID] = tl
+	}
+	tl.refs++
+	l.mu.Unlock()
+
+	tl.Lock()
+	return func() {
+		tl.Unlock()
+		l.mu.Lock()
+		tl.refs--
+		if tl.refs == 0 {
+			delete(l.locks, taskID)
+		}
+		l.mu.Unlock()
+	}
+}
@@ -12,7 +12,58 @@
 // permissions and limitations under the License.
 package stats
 
+import "time"
+
 type NetworkStatsPerSec struct {
 	RxBytesPerSecond float64 `json:"rx_bytes_per_sec"`
 	TxBytesPerSecond float64 `json:"tx_bytes_per_sec"`
 }
+
+const (
+	// NetworkFlowRemoteAWSServices is the remote of the traffic to the AWS service prefix lists.
+	NetworkFlowRemoteAWSServices = "aws-services"
+	// NetworkFlowRemoteOther is the remote of the traffic that matches no other remote.
+	NetworkFlowRemoteOther = "other"
+	// NetworkFlowRemotePortPrefix prefixes the remote of the traffic grouped by remote port, e.g. "port/443".
+	NetworkFlowRemotePortPrefix = "port/"
+)
+
+// NetworkFlowStats is the traffic of a task grouped by remote endpoint.
+type NetworkFlowStats struct {
+	Read  time.Time     `json:"read"`
+	Flows []NetworkFlow `json:"flows"`
+}
+
+// NetworkFlow is the traffic exchanged by a task with a remote, which is either a CIDR, a port
+// prefixed with NetworkFlowRemotePortPrefix, NetworkFlowRemoteAWSServices or NetworkFlowRemoteOther.
+// The counters are cumulative since the accounting started for the task.
+type NetworkFlow struct {
+	Remote    string `json:"remote"`
+	RxBytes   uint64 `json:"rx_bytes"`
+	RxPackets uint64 `json:"rx_packets"`
+	TxBytes   uint64 `json:"tx_bytes"`
+	TxPackets uint64 `json:"tx_packets"`
+}
+
+// ServiceConnectStats is the view of the Service Connect proxy of a task on its traffic, as last
+// retrieved from the AppNet agent.
+type ServiceConnectStats struct {
+	Read      time.Time                    `json:"read"`
+	Upstreams []ServiceConnectUpstreamStat `json:"upstreams"`
+}
+
+// ServiceConnectUpstreamStat is the traffic of a task through a Service Connect listener, to an
+// upstream service for egress traffic or from downstream clients for ingress traffic. The counters
+// are cumulative since the agent started retrieving the stats of the proxy. Latency percentiles are
+// estimated, in milliseconds, from the response time histogram of the requests since the previous
+// retrieval, and are unset when there was none.
+type ServiceConnectUpstreamStat struct {
+	Name               string   `json:"name"`
+	Direction          string   `json:"direction"`
+	RequestCount       uint64   `json:"request_count"`
+	RetryCount         uint64   `json:"retry_count"`
+	LatencyP50         *float64 `json:"latency_p50_ms,omitempty"`
+	LatencyP90         *float64 `json:"latency_p90_ms,omitempty"`
+	LatencyP99         *float64 `json:"latency_p99_ms,omitempty"`
+	CircuitBreakerOpen bool     `json:"circuit_breaker_open"`
+}
@@ -14,6 +14,10 @@
 // This package defines some common types used by all task metadata functions
 package response
 
+import (
+	"time"
+)
+
 // VolumeResponse is the schema for the volume response JSON object
 type VolumeResponse struct {
 	DockerName  string `json:"DockerName,omitempty"`
@@ -36,3 +40,15 @@ type Network struct {
 	IPv4Addresses []string `json:"IPv4Addresses,omitempty"`
 	IPv6Addresses []string `json:"IPv6Addresses,omitempty"`
 }
+
+// VolumeExportResponse is the schema for the status of the export of a task volume, archived before
+// the task is cleaned up
+type VolumeExportResponse struct {
+	VolumeName  string     `json:"VolumeName"`
+	Destination string     `json:"Destination,omitempty"`
+	Status      string     `json:"Status"`
+	SizeBytes   int64      `json:"SizeBytes,omitempty"`
+	Reason      string     `json:"Reason,omitempty"`
+	StartedAt   *time.Time `json:"StartedAt,omitempty"`
+	CompletedAt *time.Time `json:"CompletedAt,omitempty"`
+}
@@ -0,0 +1,344 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
+	mock_credentials "github.com/aws/amazon-ecs-agent/ecs-agent/credentials/mocks"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials/scoped"
+	mock_scoped "github.com/aws/amazon-ecs-agent/ecs-agent/credentials/scoped/mocks"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/audit"
+	mock_audit "github.com/aws/amazon-ecs-agent/ecs-agent/logger/audit/mocks"
+	v1 "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/v1"
+	v2 "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/v2"
+	"github.com/gorilla/mux"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	processCredentialsResponseJSON = `{"Version":1,"AccessKeyId":"%s","SecretAccessKey":"%s","SessionToken":"%s","Expiration":"%s"}`
+	scopedSessionPolicy            = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:GetObject","Resource":"*"}]}`
+)
+
+var (
+	happyProcessCredentialsResponseJSON = fmt.Sprintf(processCredentialsResponseJSON,
+		accessKeyId,
+		secretAccessKey,
+		sessionToken,
+		expiration,
+	)
+
+	scopedTaskCredentials = credentials.TaskIAMRoleCredentials{
+		ARN: "taskArn",
+		IAMRoleCredentials: credentials.IAMRoleCredentials{
+			RoleArn:         roleArn,
+			AccessKeyID:     accessKeyId,
+			SecretAccessKey: secretAccessKey,
+			SessionToken:    sessionToken,
+			Expiration:      expiration,
+			RoleType:        credentials.ApplicationRoleType,
+		},
+	}
+)
+
+// newScopedCredentialsRouter registers the credentials handlers in the order they have to be
+// registered in, the generic v2 credentials path last.
+func newScopedCredentialsRouter(
+	credManager credentials.Manager,
+	scopedManager scoped.Manager,
+	auditLogger audit.AuditLogger,
+) http.Handler {
+	router := mux.NewRouter()
+	router.HandleFunc(v2.SessionCredentialsProcessPath,
+		v2.SessionCredentialsHandler(scopedManager, auditLogger, v1.FormatProcessCredentials))
+	router.HandleFunc(v2.SessionCredentialsPath,
+		v2.SessionCredentialsHandler(scopedManager, auditLogger, v1.FormatCredentials))
+	router.HandleFunc(v2.ContainerCredentialsProcessPath,
+		v2.ContainerCredentialsHandler(scopedManager, auditLogger, v1.FormatProcessCredentials))
+	router.HandleFunc(v2.ContainerCredentialsPath,
+		v2.ContainerCredentialsHandler(scopedManager, auditLogger, v1.FormatCredentials))
+	router.HandleFunc(v2.CredentialsProcessPath, v2.CredentialsProcessHandler(credManager, auditLogger))
+	router.HandleFunc(v2.SessionPath, v2.SessionHandler(scopedManager, auditLogger))
+	router.HandleFunc(v2.CredentialsPath, v2.CredentialsHandler(credManager, auditLogger))
+	return router
+}
+
+func recordScopedCredentialsRequest(handler http.Handler, method, path, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestCredentialsProcessHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	auditLogger := mock_audit.NewMockAuditLogger(ctrl)
+	credManager := mock_credentials.NewMockManager(ctrl)
+	scopedManager := mock_scoped.NewMockManager(ctrl)
+	handler := newScopedCredentialsRouter(credManager, scopedManager, auditLogger)
+
+	credManager.EXPECT().GetTaskCredentials("credsid").Return(scopedTaskCredentials, true).Times(2)
+	auditLogger.EXPECT().Log(gomock.Any(), http.StatusOK, audit.GetCredentialsEventType).Times(2)
+
+	recorder := recordScopedCredentialsRequest(handler, http.MethodGet, "/v2/credentials/credsid/process", "", "")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, happyProcessCredentialsResponseJSON, recorder.Body.String())
+
+	// The credentials of the task are still served in the SDK format.
+	recorder = recordScopedCredentialsRequest(handler, http.MethodGet, "/v2/credentials/credsid", "", "")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, happycredentialsResponseJSON, recorder.Body.String())
+}
+
+func TestSessionHandler(t *testing.T) {
+	testCases := []struct {
+		name             string
+		method           string
+		body             string
+		setExpectations  func(scopedManager *mock_scoped.MockManager)
+		expectedCode     int
+		expectedResponse string
+	}{
+		{
+			name:   "session created",
+			method: http.MethodPost,
+			body:   fmt.Sprintf(`{"Policy":%q,"DurationSeconds":900}`, scopedSessionPolicy),
+			setExpectations: func(scopedManager *mock_scoped.MockManager) {
+				scopedManager.EXPECT().CreateSession("credsid", scopedSessionPolicy, 15*time.Minute).
+					Return("token", nil)
+			},
+			expectedCode:     http.StatusOK,
+			expectedResponse: `{"Token":"token"}`,
+		},
+		{
+			name:             "not a POST request",
+			method:           http.MethodGet,
+			expectedCode:     http.StatusMethodNotAllowed,
+			expectedResponse: `{"code":"InvalidSessionRequest","message":"CredentialsSessionV2Request: Sessions are created with POST requests","HTTPErrorCode":405}`,
+		},
+		{
+			name:             "invalid body",
+			method:           http.MethodPost,
+			body:             "policy",
+			expectedCode:     http.StatusBadRequest,
+			expectedResponse: `{"code":"InvalidSessionRequest","message":"CredentialsSessionV2Request: Unable to parse the session request","HTTPErrorCode":400}`,
+		},
+		{
+			name:   "invalid policy",
+			method: http.MethodPost,
+			body:   `{"Policy":"[]"}`,
+			setExpectations: func(scopedManager *mock_scoped.MockManager) {
+				scopedManager.EXPECT().CreateSession("credsid", "[]", time.Duration(0)).
+					Return("", fmt.Errorf("%w: the session policy is not a JSON object", scoped.ErrInvalidSessionRequest))
+			},
+			expectedCode:     http.StatusBadRequest,
+			expectedResponse: `{"code":"InvalidSessionRequest","message":"CredentialsSessionV2Request: invalid session request: the session policy is not a JSON object","HTTPErrorCode":400}`,
+		},
+		{
+			name:   "unknown credentials",
+			method: http.MethodPost,
+			body:   fmt.Sprintf(`{"Policy":%q}`, scopedSessionPolicy),
+			setExpectations: func(scopedManager *mock_scoped.MockManager) {
+				scopedManager.EXPECT().CreateSession("credsid", scopedSessionPolicy, time.Duration(0)).
+					Return("", scoped.ErrCredentialsNotFound)
+			},
+			expectedCode:     http.StatusBadRequest,
+			expectedResponse: `{"code":"InvalidIdInRequest","message":"CredentialsSessionV2Request: Credentials not found","HTTPErrorCode":400}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			auditLogger := mock_audit.NewMockAuditLogger(ctrl)
+			scopedManager := mock_scoped.NewMockManager(ctrl)
+			handler := newScopedCredentialsRouter(mock_credentials.NewMockManager(ctrl), scopedManager, auditLogger)
+
+			if tc.setExpectations != nil {
+				tc.setExpectations(scopedManager)
+			}
+			auditLogger.EXPECT().Log(gomock.Any(), tc.expectedCode, audit.CreateCredentialsSessionEventType)
+
+			recorder := recordScopedCredentialsRequest(handler, tc.method, "/v2/credentials/credsid/session", "", tc.body)
+			assert.Equal(t, tc.expectedCode, recorder.Code)
+			assert.Equal(t, tc.expectedResponse, recorder.Body.String())
+		})
+	}
+}
+
+func TestSessionCredentialsHandler(t *testing.T) {
+	testCases := []struct {
+		name             string
+		path             string
+		token            string
+		setExpectations  func(scopedManager *mock_scoped.MockManager)
+		expectedCode     int
+		expectedResponse string
+	}{
+		{
+			name:  "credentials",
+			path:  "/v2/credentials/session",
+			token: "token",
+			setExpectations: func(scopedManager *mock_scoped.MockManager) {
+				scopedManager.EXPECT().GetSessionCredentials(gomock.Any(), "token").Return(scopedTaskCredentials, nil)
+			},
+			expectedCode:     http.StatusOK,
+			expectedResponse: happycredentialsResponseJSON,
+		},
+		{
+			name:  "process credentials",
+			path:  "/v2/credentials/session/process",
+			token: "token",
+			setExpectations: func(scopedManager *mock_scoped.MockManager) {
+				scopedManager.EXPECT().GetSessionCredentials(gomock.Any(), "token").Return(scopedTaskCredentials, nil)
+			},
+			expectedCode:     http.StatusOK,
+			expectedResponse: happyProcessCredentialsResponseJSON,
+		},
+		{
+			name:             "no token",
+			path:             "/v2/credentials/session",
+			expectedCode:     http.StatusUnauthorized,
+			expectedResponse: `{"code":"InvalidSessionToken","message":"SessionCredentialsV2Request: No session token in the request","HTTPErrorCode":401}`,
+		},
+		{
+			name:  "invalid token",
+			path:  "/v2/credentials/session",
+			token: "token",
+			setExpectations: func(scopedManager *mock_scoped.MockManager) {
+				scopedManager.EXPECT().GetSessionCredentials(gomock.Any(), "token").
+					Return(credentials.TaskIAMRoleCredentials{}, scoped.ErrInvalidSessionToken)
+			},
+			expectedCode:     http.StatusUnauthorized,
+			expectedResponse: `{"code":"InvalidSessionToken","message":"SessionCredentialsV2Request: Invalid session token","HTTPErrorCode":401}`,
+		},
+		{
+			name:  "credentials uninitialized",
+			path:  "/v2/credentials/session",
+			token: "token",
+			setExpectations: func(scopedManager *mock_scoped.MockManager) {
+				scopedManager.EXPECT().GetSessionCredentials(gomock.Any(), "token").
+					Return(credentials.TaskIAMRoleCredentials{}, scoped.ErrCredentialsUninitialized)
+			},
+			expectedCode:     http.StatusServiceUnavailable,
+			expectedResponse: `{"code":"CredentialsUninitialized","message":"SessionCredentialsV2Request: Credentials uninitialized for ID","HTTPErrorCode":503}`,
+		},
+		{
+			name:  "task role does not trust itself",
+			path:  "/v2/credentials/session",
+			token: "token",
+			setExpectations: func(scopedManager *mock_scoped.MockManager) {
+				scopedManager.EXPECT().GetSessionCredentials(gomock.Any(), "token").
+					Return(credentials.TaskIAMRoleCredentials{}, fmt.Errorf("%w: access denied", scoped.ErrSelfAssumeDenied))
+			},
+			expectedCode:     http.StatusForbidden,
+			expectedResponse: `{"code":"SelfAssumeDenied","message":"SessionCredentialsV2Request: task role is not allowed to assume itself: access denied","HTTPErrorCode":403}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			auditLogger := mock_audit.NewMockAuditLogger(ctrl)
+			scopedManager := mock_scoped.NewMockManager(ctrl)
+			handler := newScopedCredentialsRouter(mock_credentials.NewMockManager(ctrl), scopedManager, auditLogger)
+
+			if tc.setExpectations != nil {
+				tc.setExpectations(scopedManager)
+			}
+			auditLogger.EXPECT().Log(gomock.Any(), tc.expectedCode, audit.GetSessionCredentialsEventType)
+
+			recorder := recordScopedCredentialsRequest(handler, http.MethodGet, tc.path, tc.token, "")
+			assert.Equal(t, tc.expectedCode, recorder.Code)
+			assert.Equal(t, tc.expectedResponse, recorder.Body.String())
+		})
+	}
+}
+
+func TestContainerCredentialsHandler(t *testing.T) {
+	testCases := []struct {
+		name             string
+		path             string
+		err              error
+		expectedCode     int
+		expectedResponse string
+	}{
+		{
+			name:             "credentials",
+			path:             "/v2/credentials/container/endpoint-id",
+			expectedCode:     http.StatusOK,
+			expectedResponse: happycredentialsResponseJSON,
+		},
+		{
+			name:             "process credentials",
+			path:             "/v2/credentials/container/endpoint-id/process",
+			expectedCode:     http.StatusOK,
+			expectedResponse: happyProcessCredentialsResponseJSON,
+		},
+		{
+			name:             "no container role",
+			path:             "/v2/credentials/container/endpoint-id",
+			err:              scoped.ErrContainerRoleNotFound,
+			expectedCode:     http.StatusBadRequest,
+			expectedResponse: `{"code":"NoContainerRole","message":"ContainerCredentialsV2Request: No container with a role of its own for ID","HTTPErrorCode":400}`,
+		},
+		{
+			name:             "role cannot be assumed",
+			path:             "/v2/credentials/container/endpoint-id",
+			err:              errors.New("unable to assume role: access denied"),
+			expectedCode:     http.StatusInternalServerError,
+			expectedResponse: `{"code":"AssumeRoleFailed","message":"ContainerCredentialsV2Request: unable to assume role: access denied","HTTPErrorCode":500}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			auditLogger := mock_audit.NewMockAuditLogger(ctrl)
+			scopedManager := mock_scoped.NewMockManager(ctrl)
+			handler := newScopedCredentialsRouter(mock_credentials.NewMockManager(ctrl), scopedManager, auditLogger)
+
+			taskCredentials := scopedTaskCredentials
+			if tc.err != nil {
+				taskCredentials = credentials.TaskIAMRoleCredentials{}
+			}
+			scopedManager.EXPECT().GetContainerCredentials(gomock.Any(), "endpoint-id").Return(taskCredentials, tc.err)
+			auditLogger.EXPECT().Log(gomock.Any(), tc.expectedCode, audit.GetContainerCredentialsEventType)
+
+			recorder := recordScopedCredentialsRequest(handler, http.MethodGet, tc.path, "", "")
+			assert.Equal(t, tc.expectedCode, recorder.Code)
+			assert.Equal(t, tc.expectedResponse, recorder.Body.String())
+		})
+	}
+}
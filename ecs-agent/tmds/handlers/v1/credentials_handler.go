@@ -68,6 +68,20 @@ func CredentialsHandler(
 	}
 }
 
+// CredentialsFormatter marshals role credentials into the body of a credentials response.
+type CredentialsFormatter func(credentials.IAMRoleCredentials) ([]byte, error)
+
+// FormatCredentials marshals role credentials in the format the AWS SDKs expect from the
+// credentials endpoint.
+func FormatCredentials(roleCredentials credentials.IAMRoleCredentials) ([]byte, error) {
+	return json.Marshal(roleCredentials)
+}
+
+// FormatProcessCredentials marshals role credentials in the output format of a credential_process.
+func FormatProcessCredentials(roleCredentials credentials.IAMRoleCredentials) ([]byte, error) {
+	return json.Marshal(roleCredentials.ProcessCredentials())
+}
+
 // CredentialsHandlerImpl is the major logic in CredentialsHandler, abstract this out
 // because v2.CredentialsHandler also uses the same logic.
 func CredentialsHandlerImpl(
@@ -77,9 +91,23 @@ func CredentialsHandlerImpl(
 	credentialsManager credentials.Manager,
 	credentialsID string,
 	errPrefix string,
+) {
+	FormattedCredentialsHandlerImpl(w, r, auditLogger, credentialsManager, credentialsID, errPrefix,
+		FormatCredentials)
+}
+
+// FormattedCredentialsHandlerImpl is CredentialsHandlerImpl with credentials in the given format.
+func FormattedCredentialsHandlerImpl(
+	w http.ResponseWriter,
+	r *http.Request,
+	auditLogger auditinterface.AuditLogger,
+	credentialsManager credentials.Manager,
+	credentialsID string,
+	errPrefix string,
+	format CredentialsFormatter,
 ) {
 	responseJSON, arn, roleType, errorMessage, err := processCredentialsRequest(
-		credentialsManager, r, credentialsID, errPrefix)
+		credentialsManager, r, credentialsID, errPrefix, format)
 	if err != nil {
 		errResponseJSON, err := json.Marshal(errorMessage)
 		if e := handlersutils.WriteResponseIfMarshalError(w, err); e != nil {
@@ -101,6 +129,7 @@ func processCredentialsRequest(
 	r *http.Request,
 	credentialsID string,
 	errPrefix string,
+	format CredentialsFormatter,
 ) ([]byte, string, string, *handlersutils.ErrorMessage, error) {
 	if credentialsID == "" {
 		errText := errPrefix + "No Credential ID in the request"
@@ -141,7 +170,7 @@ func processCredentialsRequest(
 		return nil, "", "", msg, errors.New(errText)
 	}
 
-	credentialsJSON, err := json.Marshal(credentials.IAMRoleCredentials)
+	credentialsJSON, err := format(credentials.IAMRoleCredentials)
 	if err != nil {
 		errText := errPrefix + "Error marshaling credentials"
 		seelog.Errorf("Error processing credential request credentialType=%s taskARN=%s: %s",
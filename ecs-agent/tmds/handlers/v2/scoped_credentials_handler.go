@@ -0,0 +1,289 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v2
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials/scoped"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	auditinterface "github.com/aws/amazon-ecs-agent/ecs-agent/logger/audit"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/audit/request"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/utils"
+	v1 "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/v1"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// ErrInvalidSessionRequest is the error code indicating that a session could not be
+	// created with the requested policy or duration.
+	ErrInvalidSessionRequest = "InvalidSessionRequest"
+
+	// ErrTooManySessions is the error code indicating that the task has too many sessions.
+	ErrTooManySessions = "TooManySessions"
+
+	// ErrInvalidSessionToken is the error code indicating that the session token is unknown,
+	// or that the task of the session has stopped.
+	ErrInvalidSessionToken = "InvalidSessionToken"
+
+	// ErrNoContainerRole is the error code indicating that there is no container with a role
+	// of its own for the endpoint id.
+	ErrNoContainerRole = "NoContainerRole"
+
+	// ErrSelfAssumeDenied is the error code indicating that the task role is not allowed to assume
+	// itself, which scoped sessions require.
+	ErrSelfAssumeDenied = "SelfAssumeDenied"
+
+	// ErrAssumeRoleFailed is the error code indicating that the role could not be assumed.
+	ErrAssumeRoleFailed = "AssumeRoleFailed"
+
+	// endpointContainerIDMuxName is the key that's used in gorilla/mux to get the endpoint container ID.
+	endpointContainerIDMuxName = "endpointContainerIDMuxName"
+
+	// sessionPathSuffix is the suffix of the path that creates sessions of task role credentials.
+	sessionPathSuffix = "/session"
+
+	// maximumSessionRequestBytes bounds the size of the body of a session request.
+	maximumSessionRequestBytes = 16 * 1024
+
+	// authorizationHeader is the header that carries the session token, which is where the AWS
+	// SDKs put the value of AWS_CONTAINER_AUTHORIZATION_TOKEN.
+	authorizationHeader = "Authorization"
+)
+
+// The paths below are all under credentials.V2CredentialsPath, and have to be registered before
+// CredentialsPath, which matches any path under it.
+var (
+	// CredentialsProcessPath specifies the relative URI path for serving task IAM credentials in
+	// the output format of a credential_process.
+	CredentialsProcessPath = credentials.V2CredentialsPath + "/" +
+		utils.ConstructMuxVar(credentialsIDMuxName, utils.AnythingButSlashRegEx) + credentials.CredentialsProcessPathSuffix
+
+	// SessionPath specifies the relative URI path for creating sessions of task IAM credentials.
+	SessionPath = credentials.V2CredentialsPath + "/" +
+		utils.ConstructMuxVar(credentialsIDMuxName, utils.AnythingButSlashRegEx) + sessionPathSuffix
+
+	// SessionCredentialsPath specifies the relative URI path for exchanging a session token for
+	// scoped credentials.
+	SessionCredentialsPath = credentials.V2SessionCredentialsPath
+
+	// SessionCredentialsProcessPath specifies the relative URI path for exchanging a session token
+	// for scoped credentials in the output format of a credential_process.
+	SessionCredentialsProcessPath = credentials.V2SessionCredentialsPath + credentials.CredentialsProcessPathSuffix
+
+	// ContainerCredentialsPath specifies the relative URI path for serving the credentials of
+	// containers with a role of their own.
+	ContainerCredentialsPath = credentials.V2ContainerCredentialsPath + "/" +
+		utils.ConstructMuxVar(endpointContainerIDMuxName, utils.AnythingButSlashRegEx)
+
+	// ContainerCredentialsProcessPath specifies the relative URI path for serving the credentials
+	// of containers with a role of their own in the output format of a credential_process.
+	ContainerCredentialsProcessPath = ContainerCredentialsPath + credentials.CredentialsProcessPathSuffix
+)
+
+// SessionRequest is the body of a request to create a session.
+type SessionRequest struct {
+	// Policy is the session policy that scopes down the credentials of the session.
+	Policy string `json:"Policy"`
+	// DurationSeconds is the duration of the credentials of the session.
+	DurationSeconds int64 `json:"DurationSeconds,omitempty"`
+}
+
+// SessionResponse is the response to a request to create a session.
+type SessionResponse struct {
+	// Token is the session token, to be sent in the Authorization header of requests to
+	// SessionCredentialsPath.
+	Token string `json:"Token"`
+}
+
+// CredentialsProcessHandler creates response for the 'v2/credentials/<id>/process' API.
+func CredentialsProcessHandler(credentialsManager credentials.Manager,
+	auditLogger auditinterface.AuditLogger) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		credentialsID := getCredentialsID(r)
+		errPrefix := fmt.Sprintf("CredentialsV%dRequest: ", apiVersion)
+		v1.FormattedCredentialsHandlerImpl(w, r, auditLogger, credentialsManager, credentialsID, errPrefix,
+			v1.FormatProcessCredentials)
+	}
+}
+
+// SessionHandler creates response for the 'v2/credentials/<id>/session' API. It creates a session
+// of the task role credentials with the session policy in the body of the request.
+func SessionHandler(scopedManager scoped.Manager,
+	auditLogger auditinterface.AuditLogger) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		errPrefix := fmt.Sprintf("CredentialsSessionV%dRequest: ", apiVersion)
+		if r.Method != http.MethodPost {
+			writeScopedCredentialsError(w, r, auditLogger, auditinterface.CreateCredentialsSessionEventType,
+				&utils.ErrorMessage{
+					Code:          ErrInvalidSessionRequest,
+					Message:       errPrefix + "Sessions are created with POST requests",
+					HTTPErrorCode: http.StatusMethodNotAllowed,
+				})
+			return
+		}
+
+		var sessionRequest SessionRequest
+		body, err := io.ReadAll(io.LimitReader(r.Body, maximumSessionRequestBytes))
+		if err == nil {
+			err = json.Unmarshal(body, &sessionRequest)
+		}
+		if err != nil {
+			writeScopedCredentialsError(w, r, auditLogger, auditinterface.CreateCredentialsSessionEventType,
+				&utils.ErrorMessage{
+					Code:          ErrInvalidSessionRequest,
+					Message:       errPrefix + "Unable to parse the session request",
+					HTTPErrorCode: http.StatusBadRequest,
+				})
+			return
+		}
+
+		token, err := scopedManager.CreateSession(getCredentialsID(r), sessionRequest.Policy,
+			time.Duration(sessionRequest.DurationSeconds)*time.Second)
+		if err != nil {
+			writeScopedCredentialsError(w, r, auditLogger, auditinterface.CreateCredentialsSessionEventType,
+				scopedCredentialsErrorMessage(err, errPrefix))
+			return
+		}
+		auditLogger.Log(request.LogRequest{Request: r}, http.StatusOK,
+			auditinterface.CreateCredentialsSessionEventType)
+		utils.WriteJSONResponse(w, http.StatusOK, SessionResponse{Token: token}, utils.RequestTypeCreds)
+	}
+}
+
+// SessionCredentialsHandler creates response for the 'v2/credentials/session' API. It exchanges
+// the session token in the Authorization header for the scoped credentials of the session.
+func SessionCredentialsHandler(scopedManager scoped.Manager, auditLogger auditinterface.AuditLogger,
+	format v1.CredentialsFormatter) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		errPrefix := fmt.Sprintf("SessionCredentialsV%dRequest: ", apiVersion)
+		token := r.Header.Get(authorizationHeader)
+		if token == "" {
+			writeScopedCredentialsError(w, r, auditLogger, auditinterface.GetSessionCredentialsEventType,
+				&utils.ErrorMessage{
+					Code:          ErrInvalidSessionToken,
+					Message:       errPrefix + "No session token in the request",
+					HTTPErrorCode: http.StatusUnauthorized,
+				})
+			return
+		}
+		taskCredentials, err := scopedManager.GetSessionCredentials(r.Context(), token)
+		writeScopedCredentials(w, r, auditLogger, auditinterface.GetSessionCredentialsEventType,
+			taskCredentials, err, errPrefix, format)
+	}
+}
+
+// ContainerCredentialsHandler creates response for the 'v2/credentials/container/<id>' API. It
+// returns the credentials of the role of the container with the endpoint id.
+func ContainerCredentialsHandler(scopedManager scoped.Manager, auditLogger auditinterface.AuditLogger,
+	format v1.CredentialsFormatter) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		errPrefix := fmt.Sprintf("ContainerCredentialsV%dRequest: ", apiVersion)
+		endpointContainerID := mux.Vars(r)[endpointContainerIDMuxName]
+		taskCredentials, err := scopedManager.GetContainerCredentials(r.Context(), endpointContainerID)
+		writeScopedCredentials(w, r, auditLogger, auditinterface.GetContainerCredentialsEventType,
+			taskCredentials, err, errPrefix, format)
+	}
+}
+
+func writeScopedCredentials(w http.ResponseWriter, r *http.Request, auditLogger auditinterface.AuditLogger,
+	eventType string, taskCredentials credentials.TaskIAMRoleCredentials, err error, errPrefix string,
+	format v1.CredentialsFormatter) {
+	if err != nil {
+		writeScopedCredentialsError(w, r, auditLogger, eventType, scopedCredentialsErrorMessage(err, errPrefix))
+		return
+	}
+	credentialsJSON, err := format(taskCredentials.IAMRoleCredentials)
+	if err != nil {
+		writeScopedCredentialsError(w, r, auditLogger, eventType, &utils.ErrorMessage{
+			Code:          v1.ErrInternalServer,
+			Message:       "Internal server error",
+			HTTPErrorCode: http.StatusInternalServerError,
+		})
+		return
+	}
+	auditLogger.Log(request.LogRequest{Request: r, ARN: taskCredentials.ARN}, http.StatusOK, eventType)
+	utils.WriteJSONToResponse(w, http.StatusOK, credentialsJSON, utils.RequestTypeCreds)
+}
+
+func writeScopedCredentialsError(w http.ResponseWriter, r *http.Request, auditLogger auditinterface.AuditLogger,
+	eventType string, errorMessage *utils.ErrorMessage) {
+	logger.Error("Error processing scoped credentials request", logger.Fields{
+		field.Error: errorMessage.Message,
+	})
+	auditLogger.Log(request.LogRequest{Request: r}, errorMessage.HTTPErrorCode, eventType)
+	utils.WriteJSONResponse(w, errorMessage.HTTPErrorCode, errorMessage, utils.RequestTypeCreds)
+}
+
+// scopedCredentialsErrorMessage translates an error of the scoped credentials manager to the
+// error response of the request.
+func scopedCredentialsErrorMessage(err error, errPrefix string) *utils.ErrorMessage {
+	switch {
+	case errors.Is(err, scoped.ErrInvalidSessionRequest):
+		return &utils.ErrorMessage{
+			Code:          ErrInvalidSessionRequest,
+			Message:       errPrefix + err.Error(),
+			HTTPErrorCode: http.StatusBadRequest,
+		}
+	case errors.Is(err, scoped.ErrTooManySessions):
+		return &utils.ErrorMessage{
+			Code:          ErrTooManySessions,
+			Message:       errPrefix + "Too many sessions for the task",
+			HTTPErrorCode: http.StatusBadRequest,
+		}
+	case errors.Is(err, scoped.ErrInvalidSessionToken):
+		return &utils.ErrorMessage{
+			Code:          ErrInvalidSessionToken,
+			Message:       errPrefix + "Invalid session token",
+			HTTPErrorCode: http.StatusUnauthorized,
+		}
+	case errors.Is(err, scoped.ErrCredentialsNotFound):
+		return &utils.ErrorMessage{
+			Code:          v1.ErrInvalidIDInRequest,
+			Message:       errPrefix + "Credentials not found",
+			HTTPErrorCode: http.StatusBadRequest,
+		}
+	case errors.Is(err, scoped.ErrCredentialsUninitialized):
+		return &utils.ErrorMessage{
+			Code:          v1.ErrCredentialsUninitialized,
+			Message:       errPrefix + "Credentials uninitialized for ID",
+			HTTPErrorCode: http.StatusServiceUnavailable,
+		}
+	case errors.Is(err, scoped.ErrContainerRoleNotFound):
+		return &utils.ErrorMessage{
+			Code:          ErrNoContainerRole,
+			Message:       errPrefix + "No container with a role of its own for ID",
+			HTTPErrorCode: http.StatusBadRequest,
+		}
+	case errors.Is(err, scoped.ErrSelfAssumeDenied):
+		return &utils.ErrorMessage{
+			Code:          ErrSelfAssumeDenied,
+			Message:       errPrefix + err.Error(),
+			HTTPErrorCode: http.StatusForbidden,
+		}
+	default:
+		return &utils.ErrorMessage{
+			Code:          ErrAssumeRoleFailed,
+			Message:       errPrefix + err.Error(),
+			HTTPErrorCode: http.StatusInternalServerError,
+		}
+	}
+}
@@ -25,20 +25,24 @@ import (
 const (
 	ClockStatusSynchronized    = "SYNCHRONIZED"
 	ClockStatusNotSynchronized = "NOT_SYNCHRONIZED"
+
+	ReadinessStatusReady    = "READY"
+	ReadinessStatusNotReady = "NOT_READY"
 )
 
 // TaskResponse is the v4 Task response. It augments the v4 Container response
 // with the v2 task response object.
 type TaskResponse struct {
 	*v2.TaskResponse
-	Containers              []ContainerResponse      `json:"Containers,omitempty"`
-	VPCID                   string                   `json:"VPCID,omitempty"`
-	ServiceName             string                   `json:"ServiceName,omitempty"`
-	ClockDrift              *ClockDrift              `json:"ClockDrift,omitempty"`
-	EphemeralStorageMetrics *EphemeralStorageMetrics `json:"EphemeralStorageMetrics,omitempty"`
-	CredentialsID           string                   `json:"-"`
-	TaskNetworkConfig       *TaskNetworkConfig       `json:"-"`
-	FaultInjectionEnabled   bool                     `json:"FaultInjectionEnabled"`
+	Containers              []ContainerResponse             `json:"Containers,omitempty"`
+	VPCID                   string                          `json:"VPCID,omitempty"`
+	ServiceName             string                          `json:"ServiceName,omitempty"`
+	ClockDrift              *ClockDrift                     `json:"ClockDrift,omitempty"`
+	EphemeralStorageMetrics *EphemeralStorageMetrics        `json:"EphemeralStorageMetrics,omitempty"`
+	CredentialsID           string                          `json:"-"`
+	TaskNetworkConfig       *TaskNetworkConfig              `json:"-"`
+	FaultInjectionEnabled   bool                            `json:"FaultInjectionEnabled"`
+	VolumeExports           []response.VolumeExportResponse `json:"VolumeExports,omitempty"`
 }
 
 // TaskNetworkConfig contains required network configurations for network faults injection.
@@ -93,9 +97,25 @@ type EphemeralStorageMetrics struct {
 // with the v2 container response object.
 type ContainerResponse struct {
 	*v2.ContainerResponse
-	Networks     []Network `json:"Networks,omitempty"`
-	Snapshotter  string    `json:"Snapshotter,omitempty"`
-	RestartCount *int      `json:"RestartCount,omitempty"`
+	Networks          []Network        `json:"Networks,omitempty"`
+	Snapshotter       string           `json:"Snapshotter,omitempty"`
+	RestartCount      *int             `json:"RestartCount,omitempty"`
+	LastRestartReason string           `json:"LastRestartReason,omitempty"`
+	Readiness         *ReadinessStatus `json:"Readiness,omitempty"`
+}
+
+// ReadinessStatus is the result of the agent executed readiness probe of a container.
+type ReadinessStatus struct {
+	// Status is READY once the probe has succeeded, NOT_READY otherwise
+	Status string `json:"status"`
+	// Since is the timestamp when the container became ready
+	Since *time.Time `json:"statusSince,omitempty"`
+	// LastProbedAt is the timestamp of the last probe attempt
+	LastProbedAt *time.Time `json:"lastProbedAt,omitempty"`
+	// Attempts is the number of probe attempts
+	Attempts int `json:"attempts,omitempty"`
+	// Output is the error of the last failed probe attempt
+	Output string `json:"output,omitempty"`
 }
 
 // Network is the v4 Network response. It adds a bunch of information about network
@@ -138,5 +158,7 @@ type NetworkInterfaceProperties struct {
 // StatsResponse is the v4 Stats response for a container.
 type StatsResponse struct {
 	*types.StatsJSON
-	Network_rate_stats *stats.NetworkStatsPerSec `json:"network_rate_stats,omitempty"`
+	Network_rate_stats    *stats.NetworkStatsPerSec  `json:"network_rate_stats,omitempty"`
+	Network_flow_stats    *stats.NetworkFlowStats    `json:"network_flow_stats,omitempty"`
+	Service_connect_stats *stats.ServiceConnectStats `json:"service_connect_stats,omitempty"`
 }
@@ -15,7 +15,9 @@ package tmds
 import (
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/audit"
@@ -33,6 +35,10 @@ const (
 	Port         = 51679
 	IPForTasks   = "169.254.170.2"
 	PortForTasks = 80
+	// IPv6 address of TMDS. It is assigned to the loopback interface of the host and is also
+	// the address tasks use to reach TMDS over IPv6, as the AWS SDKs allow it for container
+	// credentials endpoints.
+	IPv6 = "fd00:ec2::23"
 )
 
 // IPv4 address for TMDS
@@ -40,6 +46,11 @@ func AddressIPv4() string {
 	return fmt.Sprintf("%s:%d", IPv4, Port)
 }
 
+// IPv6 address for TMDS
+func AddressIPv6() string {
+	return net.JoinHostPort(IPv6, strconv.Itoa(Port))
+}
+
 // Configuration for TMDS
 type Config struct {
 	listenAddress   string        // http server listen address
@@ -53,3 +53,7 @@ func TestServerSettings(t *testing.T) {
 func TestAddressIPv4(t *testing.T) {
 	assert.Equal(t, "127.0.0.1:51679", AddressIPv4())
 }
+
+func TestAddressIPv6(t *testing.T) {
+	assert.Equal(t, "[fd00:ec2::23]:51679", AddressIPv6())
+}
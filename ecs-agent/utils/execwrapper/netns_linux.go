@@ -0,0 +1,63 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package execwrapper
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// NsenterCommand is the command used to run commands inside a network namespace.
+const NsenterCommand = "nsenter"
+
+// RunInNetNS runs a command inside the network namespace at netNSPath and returns its combined
+// output. The command is killed if it does not complete within the timeout.
+func RunInNetNS(
+	ctx context.Context,
+	e Exec,
+	timeout time.Duration,
+	netNSPath, command string,
+	args ...string,
+) ([]byte, error) {
+	return RunInNetNSWithInput(ctx, e, timeout, netNSPath, nil, command, args...)
+}
+
+// RunInNetNSWithInput is like RunInNetNS, with input fed to the standard input of the command.
+func RunInNetNSWithInput(
+	ctx context.Context,
+	e Exec,
+	timeout time.Duration,
+	netNSPath string,
+	input io.Reader,
+	command string,
+	args ...string,
+) ([]byte, error) {
+	ctx, cancel := e.NewExecContextWithTimeout(ctx, timeout)
+	defer cancel()
+
+	nsArgs := append([]string{"--net=" + netNSPath, command}, args...)
+	cmd := e.CommandContext(ctx, NsenterCommand, nsArgs...)
+	if input != nil {
+		cmd.SetIOStreams(input, nil, nil)
+	}
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to run %s %s: %s", command, strings.Join(args, " "), string(out))
+	}
+	return out, nil
+}
@@ -29,6 +29,8 @@ type NetLink interface {
 	RouteAdd(route *netlink.Route) error
 	RouteDel(route *netlink.Route) error
 	AddrList(link netlink.Link, family int) ([]netlink.Addr, error)
+	AddrAdd(link netlink.Link, addr *netlink.Addr) error
+	AddrDel(link netlink.Link, addr *netlink.Addr) error
 }
 
 type netLink struct{}
@@ -71,3 +73,13 @@ func (nl *netLink) RouteDel(route *netlink.Route) error {
 func (nl *netLink) AddrList(link netlink.Link, family int) ([]netlink.Addr, error) {
 	return netlink.AddrList(link, family)
 }
+
+// AddrAdd adds an IP address to a link device. Equivalent to: `ip addr add $addr dev $link`
+func (nl *netLink) AddrAdd(link netlink.Link, addr *netlink.Addr) error {
+	return netlink.AddrAdd(link, addr)
+}
+
+// AddrDel deletes an IP address from a link device. Equivalent to: `ip addr del $addr dev $link`
+func (nl *netLink) AddrDel(link netlink.Link, addr *netlink.Addr) error {
+	return netlink.AddrDel(link, addr)
+}
@@ -19,6 +19,7 @@
 package mock_netwrapper
 
 import (
+	context "context"
 	net "net"
 	reflect "reflect"
 
@@ -92,3 +93,18 @@ func (mr *MockNetMockRecorder) Interfaces() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Interfaces", reflect.TypeOf((*MockNet)(nil).Interfaces))
 }
+
+// LookupIPAddr mocks base method.
+func (m *MockNet) LookupIPAddr(arg0 context.Context, arg1 string) ([]net.IPAddr, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LookupIPAddr", arg0, arg1)
+	ret0, _ := ret[0].([]net.IPAddr)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LookupIPAddr indicates an expected call of LookupIPAddr.
+func (mr *MockNetMockRecorder) LookupIPAddr(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LookupIPAddr", reflect.TypeOf((*MockNet)(nil).LookupIPAddr), arg0, arg1)
+}
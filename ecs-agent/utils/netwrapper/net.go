@@ -1,6 +1,7 @@
 package netwrapper
 
 import (
+	"context"
 	n "net"
 )
 
@@ -8,6 +9,7 @@ type Net interface {
 	Interfaces() ([]n.Interface, error)
 	InterfaceByName(intfName string) (*n.Interface, error)
 	Addrs(intf *n.Interface) ([]n.Addr, error)
+	LookupIPAddr(ctx context.Context, host string) ([]n.IPAddr, error)
 }
 
 type net struct {
@@ -27,3 +29,7 @@ func (*net) InterfaceByName(intfName string) (*n.Interface, error) {
 func (*net) Addrs(intf *n.Interface) ([]n.Addr, error) {
 	return intf.Addrs()
 }
+
+func (*net) LookupIPAddr(ctx context.Context, host string) ([]n.IPAddr, error) {
+	return n.DefaultResolver.LookupIPAddr(ctx, host)
+}
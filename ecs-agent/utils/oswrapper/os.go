@@ -22,6 +22,7 @@ type OS interface {
 	Rename(string, string) error
 	MkdirAll(string, os.FileMode) error
 	RemoveAll(string) error
+	ReadDir(string) ([]os.DirEntry, error)
 	IsNotExist(error) bool
 	Stat(string) (os.FileInfo, error)
 	Setenv(string, string)
@@ -64,6 +65,10 @@ func (*_os) RemoveAll(name string) error {
 	return os.RemoveAll(name)
 }
 
+func (*_os) ReadDir(name string) ([]os.DirEntry, error) {
+	return os.ReadDir(name)
+}
+
 func (*_os) IsNotExist(err error) bool {
 	return os.IsNotExist(err)
 }
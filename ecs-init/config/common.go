@@ -189,6 +189,12 @@ func AgentDataDirectory() string {
 	return directoryPrefix + "/var/lib/ecs/data"
 }
 
+// AgentSecretFilesDirectory returns the location on disk of the tmpfs that holds the secrets that
+// tasks mount as files
+func AgentSecretFilesDirectory() string {
+	return AgentDataDirectory() + "/secretfiles"
+}
+
 // CacheDirectory returns the location on disk where Agent images should be cached
 func CacheDirectory() string {
 	return directoryPrefix + "/var/cache/ecs"
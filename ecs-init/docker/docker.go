@@ -111,6 +111,13 @@ const (
 
 	execAgentLogRelativePath = "/exec"
 
+	// dockerVolumesDir specifies the location where docker mounts the local volumes. The ECS Agent
+	// reads the usage of the tmpfs docker volumes that back the ephemeral volumes of tasks there
+	dockerVolumesDir = "/var/lib/docker/volumes"
+	// slavePropagation specifies the propagation suffix for mounting host volumes whose submounts,
+	// including the ones that are mounted after the Agent container starts, are seen by the Agent
+	slavePropagation = ",rslave"
+
 	// nvidiaGPUDevicesPresentRetryTime specifies the duration of time to wait before retrying to check if NVIDIA
 	// GPU devices are present.
 	nvidiaGPUDevicesPresentRetryTime = 3 * time.Second
@@ -499,6 +506,7 @@ func (c *client) getHostConfig(envVarsFromFiles map[string]string) *godocker.Hos
 
 	// only add bind mounts when the src file/directory exists on host; otherwise docker API create an empty directory on host
 	binds = append(binds, getCapabilityBinds()...)
+	binds = append(binds, getDockerVolumesBinds()...)
 
 	return createHostConfig(binds)
 }
@@ -574,6 +582,15 @@ func getCapabilityBinds() []string {
 	return binds
 }
 
+// getDockerVolumesBinds returns the read-only bind of the docker volumes directory, at the same path
+// in the Agent container, if the directory exists on the host.
+func getDockerVolumesBinds() []string {
+	if !isPathValid(dockerVolumesDir, true) {
+		return []string{}
+	}
+	return []string{dockerVolumesDir + ":" + dockerVolumesDir + readOnly + slavePropagation}
+}
+
 func defaultIsPathValid(path string, shouldBeDirectory bool) bool {
 	fileInfo, err := config.OsStat(path)
 	if err != nil {
@@ -989,6 +989,22 @@ func TestGetCapabilityExecBinds(t *testing.T) {
 	}
 }
 
+func TestGetDockerVolumesBinds(t *testing.T) {
+	defer func() {
+		isPathValid = defaultIsPathValid
+	}()
+
+	isPathValid = func(path string, isDir bool) bool {
+		return path == dockerVolumesDir && isDir
+	}
+	assert.Equal(t, []string{"/var/lib/docker/volumes:/var/lib/docker/volumes:ro,rslave"}, getDockerVolumesBinds())
+
+	isPathValid = func(path string, isDir bool) bool {
+		return false
+	}
+	assert.Empty(t, getDockerVolumesBinds())
+}
+
 func TestDefaultIsPathValid(t *testing.T) {
 	rootDir := t.TempDir()
 
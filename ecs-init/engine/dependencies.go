@@ -57,6 +57,12 @@ type tmdsRouteManagerForIPv6Only interface {
 	RemoveRoute() error
 }
 
+// Provides methods to assign the IPv6 address of the Task Metadata Server to the host.
+type tmdsIPv6AddressManager interface {
+	AssignAddress() error
+	RemoveAddress() error
+}
+
 type ipv6RouterAdvertisements interface {
 	Disable() error
 }
@@ -410,6 +410,57 @@ func (mr *MocktmdsRouteManagerForIPv6OnlyMockRecorder) RemoveRoute() *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveRoute", reflect.TypeOf((*MocktmdsRouteManagerForIPv6Only)(nil).RemoveRoute))
 }
 
+// MocktmdsIPv6AddressManager is a mock of tmdsIPv6AddressManager interface.
+type MocktmdsIPv6AddressManager struct {
+	ctrl     *gomock.Controller
+	recorder *MocktmdsIPv6AddressManagerMockRecorder
+}
+
+// MocktmdsIPv6AddressManagerMockRecorder is the mock recorder for MocktmdsIPv6AddressManager.
+type MocktmdsIPv6AddressManagerMockRecorder struct {
+	mock *MocktmdsIPv6AddressManager
+}
+
+// NewMocktmdsIPv6AddressManager creates a new mock instance.
+func NewMocktmdsIPv6AddressManager(ctrl *gomock.Controller) *MocktmdsIPv6AddressManager {
+	mock := &MocktmdsIPv6AddressManager{ctrl: ctrl}
+	mock.recorder = &MocktmdsIPv6AddressManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocktmdsIPv6AddressManager) EXPECT() *MocktmdsIPv6AddressManagerMockRecorder {
+	return m.recorder
+}
+
+// AssignAddress mocks base method.
+func (m *MocktmdsIPv6AddressManager) AssignAddress() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssignAddress")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AssignAddress indicates an expected call of AssignAddress.
+func (mr *MocktmdsIPv6AddressManagerMockRecorder) AssignAddress() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignAddress", reflect.TypeOf((*MocktmdsIPv6AddressManager)(nil).AssignAddress))
+}
+
+// RemoveAddress mocks base method.
+func (m *MocktmdsIPv6AddressManager) RemoveAddress() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveAddress")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveAddress indicates an expected call of RemoveAddress.
+func (mr *MocktmdsIPv6AddressManagerMockRecorder) RemoveAddress() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveAddress", reflect.TypeOf((*MocktmdsIPv6AddressManager)(nil).RemoveAddress))
+}
+
 // Mockipv6RouterAdvertisements is a mock of ipv6RouterAdvertisements interface.
 type Mockipv6RouterAdvertisements struct {
 	ctrl     *gomock.Controller
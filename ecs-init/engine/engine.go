@@ -34,6 +34,7 @@ import (
 
 	log "github.com/cihub/seelog"
 	ctrdapparmor "github.com/containerd/containerd/pkg/apparmor"
+	"golang.org/x/sys/unix"
 )
 
 const (
@@ -49,6 +50,9 @@ const (
 	serviceStartMaxRetries        = math.MaxInt64 // essentially retry forever
 	failedContainerLogWindowSize  = "200"         // as string for log config
 	mountFilePermission           = 0755
+	// secretFilesTmpfsOptions lets the containers traverse to the secret files they own without
+	// listing the files of the other tasks
+	secretFilesTmpfsOptions = "size=128m,mode=0711"
 )
 
 // Injection point for testing purposes
@@ -58,6 +62,8 @@ var (
 	}
 	hostSupports       = ctrdapparmor.HostSupports
 	loadDefaultProfile = apparmor.LoadDefaultProfile
+	mountTmpfs         = unix.Mount
+	statfs             = unix.Statfs
 )
 
 func dockerError(err error) error {
@@ -70,6 +76,7 @@ type Engine struct {
 	loopbackRouting               loopbackRouting
 	credentialsProxyRoute         credentialsProxyRoute
 	tmdsRoutesForIPv6OnlyInstance tmdsRouteManagerForIPv6Only
+	tmdsIPv6Address               tmdsIPv6AddressManager
 	ipv6RouterAdvertisements      ipv6RouterAdvertisements
 	nvidiaGPUManager              gpu.GPUManager
 }
@@ -107,11 +114,16 @@ func New() (*Engine, error) {
 	if err != nil {
 		return nil, err
 	}
+	tmdsIPv6AddressManager, err := routes.NewTMDSIPv6AddressManager(iptables.CredentialsProxyIPv6Address)
+	if err != nil {
+		return nil, err
+	}
 	return &Engine{
 		downloader:                    downloader,
 		loopbackRouting:               loopbackRouting,
 		credentialsProxyRoute:         credentialsProxyRoute,
 		tmdsRoutesForIPv6OnlyInstance: tmdsIPv6OnlyRouteManager,
+		tmdsIPv6Address:               tmdsIPv6AddressManager,
 		ipv6RouterAdvertisements:      ipv6RouterAdvertisements,
 		nvidiaGPUManager:              gpu.NewNvidiaGPUManager(),
 	}, nil
@@ -149,6 +161,12 @@ func (e *Engine) PreStart() error {
 	if err != nil {
 		return engineError("could not create routes for task metadata server", err)
 	}
+	// Assign the TMDS IPv6 address to the host if it has IPv6 connectivity
+	log.Info("pre-start: assigning IPv6 address for TMDS access if applicable")
+	err = e.tmdsIPv6Address.AssignAddress()
+	if err != nil {
+		return engineError("could not assign IPv6 address for task metadata server", err)
+	}
 	// Add the rerouting netfilter rule for credentials endpoint
 	log.Info("pre-start: creating credentials proxy route")
 	err = e.credentialsProxyRoute.Create()
@@ -162,6 +180,14 @@ func (e *Engine) PreStart() error {
 		// If directory creation fails, set ECS_EBSTA_SUPPORTED=false in docker/docker.go
 		log.Error("could not create EBS mount directory", err)
 	}
+	// Mount the tmpfs that holds the secret files of the tasks in the Agent data directory
+	log.Info("pre-start: mounting the secret files tmpfs")
+	err = mountSecretFilesTmpfs(config.AgentSecretFilesDirectory())
+	if err != nil {
+		// Log error and continue
+		// The Agent fails the tasks that mount secrets as files if the directory is not a tmpfs
+		log.Error("could not mount the secret files tmpfs", err)
+	}
 
 	docker, err := getDockerClient()
 	if err != nil {
@@ -244,6 +270,25 @@ func (e *Engine) ReloadCache() error {
 	return e.load(docker, e.downloader.LoadCachedAgent)
 }
 
+// mountSecretFilesTmpfs mounts a tmpfs on the given directory, unless one is still mounted there
+// from a previous run along with the secret files of the running tasks. The tmpfs is mounted before
+// the Agent container starts, so that the bind of the Agent data directory includes it.
+func mountSecretFilesTmpfs(dir string) error {
+	err := os.MkdirAll(dir, mountFilePermission)
+	if err != nil {
+		return err
+	}
+	var stat unix.Statfs_t
+	err = statfs(dir, &stat)
+	if err != nil {
+		return err
+	}
+	if stat.Type == unix.TMPFS_MAGIC {
+		return nil
+	}
+	return mountTmpfs("tmpfs", dir, "tmpfs", unix.MS_NOSUID|unix.MS_NODEV|unix.MS_NOEXEC, secretFilesTmpfsOptions)
+}
+
 func (e *Engine) downloadAndLoadCache(docker dockerClient) error {
 	err := e.downloadAgent()
 	if err != nil {
@@ -355,6 +400,10 @@ func (e *Engine) PostStop() error {
 	if ipv6OnlyRouteError := e.tmdsRoutesForIPv6OnlyInstance.RemoveRoute(); ipv6OnlyRouteError != nil {
 		log.Warn("Error when removing TMDS routes for IPv6-only instances: %v", ipv6OnlyRouteError)
 	}
+	log.Info("Cleaning up the IPv6 address assigned for TMDS access")
+	if ipv6AddressError := e.tmdsIPv6Address.RemoveAddress(); ipv6AddressError != nil {
+		log.Warnf("Error when removing TMDS IPv6 address: %v", ipv6AddressError)
+	}
 	// Ignore error from Remove() as the netfilter might never have been added in the first place
 	e.credentialsProxyRoute.Remove()
 	return err
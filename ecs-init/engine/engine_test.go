@@ -31,8 +31,18 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	ctrdapparmor "github.com/containerd/containerd/pkg/apparmor"
+	"golang.org/x/sys/unix"
 )
 
+// TestMain stubs the mount of the secret files tmpfs, so that the pre-start tests do not mount a
+// tmpfs on the test host.
+func TestMain(m *testing.M) {
+	mountTmpfs = func(source, target, fstype string, flags uintptr, data string) error {
+		return nil
+	}
+	os.Exit(m.Run())
+}
+
 // getDockerClientMock backs up getDockerClient package-level function and replaces it with the mock passed as
 // parameter. The backup can be restored by executing the returned function in a deferred manner.
 // (e.g. defer getDockerClientMock(mock)() )
@@ -68,6 +78,8 @@ func TestPreStartImageAlreadyCachedAndLoaded(t *testing.T) {
 	mockRoute.EXPECT().Create().Return(nil)
 	mockTMDSIPv6Routes := NewMocktmdsRouteManagerForIPv6Only(mockCtrl)
 	mockTMDSIPv6Routes.EXPECT().CreateRoute().Return(nil)
+	mockTMDSIPv6Address := NewMocktmdsIPv6AddressManager(mockCtrl)
+	mockTMDSIPv6Address.EXPECT().AssignAddress().Return(nil)
 
 	engine := &Engine{
 		downloader:                    mockDownloader,
@@ -75,6 +87,7 @@ func TestPreStartImageAlreadyCachedAndLoaded(t *testing.T) {
 		ipv6RouterAdvertisements:      mockIpv6RouterAdvertisements,
 		credentialsProxyRoute:         mockRoute,
 		tmdsRoutesForIPv6OnlyInstance: mockTMDSIPv6Routes,
+		tmdsIPv6Address:               mockTMDSIPv6Address,
 	}
 	err := engine.PreStart()
 	if err != nil {
@@ -109,6 +122,8 @@ func TestPreStartReloadNeeded(t *testing.T) {
 	mockRoute.EXPECT().Create().Return(nil)
 	mockTMDSIPv6OnlyRoutes := NewMocktmdsRouteManagerForIPv6Only(mockCtrl)
 	mockTMDSIPv6OnlyRoutes.EXPECT().CreateRoute().Return(nil)
+	mockTMDSIPv6Address := NewMocktmdsIPv6AddressManager(mockCtrl)
+	mockTMDSIPv6Address.EXPECT().AssignAddress().Return(nil)
 
 	engine := &Engine{
 		downloader:                    mockDownloader,
@@ -116,6 +131,7 @@ func TestPreStartReloadNeeded(t *testing.T) {
 		ipv6RouterAdvertisements:      mockIpv6RouterAdvertisements,
 		credentialsProxyRoute:         mockRoute,
 		tmdsRoutesForIPv6OnlyInstance: mockTMDSIPv6OnlyRoutes,
+		tmdsIPv6Address:               mockTMDSIPv6Address,
 	}
 	err := engine.PreStart()
 	if err != nil {
@@ -142,6 +158,8 @@ func TestPreStartImageNotLoadedCached(t *testing.T) {
 	mockIpv6RouterAdvertisements.EXPECT().Disable().Return(nil)
 	mockTMDSIPv6OnlyRoutes := NewMocktmdsRouteManagerForIPv6Only(mockCtrl)
 	mockTMDSIPv6OnlyRoutes.EXPECT().CreateRoute().Return(nil)
+	mockTMDSIPv6Address := NewMocktmdsIPv6AddressManager(mockCtrl)
+	mockTMDSIPv6Address.EXPECT().AssignAddress().Return(nil)
 	mockDocker.EXPECT().IsAgentImageLoaded().Return(false, nil)
 	mockDownloader.EXPECT().AgentCacheStatus().Return(cache.StatusCached)
 	mockDownloader.EXPECT().LoadCachedAgent().Return(cachedAgentBuffer, nil)
@@ -154,6 +172,7 @@ func TestPreStartImageNotLoadedCached(t *testing.T) {
 		ipv6RouterAdvertisements:      mockIpv6RouterAdvertisements,
 		credentialsProxyRoute:         mockRoute,
 		tmdsRoutesForIPv6OnlyInstance: mockTMDSIPv6OnlyRoutes,
+		tmdsIPv6Address:               mockTMDSIPv6Address,
 	}
 	err := engine.PreStart()
 	if err != nil {
@@ -187,6 +206,8 @@ func TestPreStartImageNotCached(t *testing.T) {
 	mockRoute.EXPECT().Create().Return(nil)
 	mockTMDSIPv6OnlyRoutes := NewMocktmdsRouteManagerForIPv6Only(mockCtrl)
 	mockTMDSIPv6OnlyRoutes.EXPECT().CreateRoute().Return(nil)
+	mockTMDSIPv6Address := NewMocktmdsIPv6AddressManager(mockCtrl)
+	mockTMDSIPv6Address.EXPECT().AssignAddress().Return(nil)
 
 	engine := &Engine{
 		downloader:                    mockDownloader,
@@ -194,6 +215,7 @@ func TestPreStartImageNotCached(t *testing.T) {
 		ipv6RouterAdvertisements:      mockIpv6RouterAdvertisements,
 		credentialsProxyRoute:         mockRoute,
 		tmdsRoutesForIPv6OnlyInstance: mockTMDSIPv6OnlyRoutes,
+		tmdsIPv6Address:               mockTMDSIPv6Address,
 	}
 	err := engine.PreStart()
 	if err != nil {
@@ -227,6 +249,8 @@ func TestPreStartGPUSetupSuccessful(t *testing.T) {
 	mockRoute.EXPECT().Create().Return(nil)
 	mockTMDSIPv6OnlyRoutes := NewMocktmdsRouteManagerForIPv6Only(mockCtrl)
 	mockTMDSIPv6OnlyRoutes.EXPECT().CreateRoute().Return(nil)
+	mockTMDSIPv6Address := NewMocktmdsIPv6AddressManager(mockCtrl)
+	mockTMDSIPv6Address.EXPECT().AssignAddress().Return(nil)
 
 	engine := &Engine{
 		downloader:                    mockDownloader,
@@ -235,6 +259,7 @@ func TestPreStartGPUSetupSuccessful(t *testing.T) {
 		credentialsProxyRoute:         mockRoute,
 		nvidiaGPUManager:              mockGPUManager,
 		tmdsRoutesForIPv6OnlyInstance: mockTMDSIPv6OnlyRoutes,
+		tmdsIPv6Address:               mockTMDSIPv6Address,
 	}
 	err := engine.PreStart()
 	if err != nil {
@@ -536,6 +561,8 @@ func TestPrestartCredentialsProxyRouteNotCreated(t *testing.T) {
 	mockRoute.EXPECT().Create().Return(fmt.Errorf("iptables not found"))
 	mockTMDSIPv6OnlyRoutes := NewMocktmdsRouteManagerForIPv6Only(mockCtrl)
 	mockTMDSIPv6OnlyRoutes.EXPECT().CreateRoute().Return(nil)
+	mockTMDSIPv6Address := NewMocktmdsIPv6AddressManager(mockCtrl)
+	mockTMDSIPv6Address.EXPECT().AssignAddress().Return(nil)
 
 	engine := &Engine{
 		downloader:                    mockDownloader,
@@ -543,6 +570,7 @@ func TestPrestartCredentialsProxyRouteNotCreated(t *testing.T) {
 		ipv6RouterAdvertisements:      mockIpv6RouterAdvertisements,
 		credentialsProxyRoute:         mockRoute,
 		tmdsRoutesForIPv6OnlyInstance: mockTMDSIPv6OnlyRoutes,
+		tmdsIPv6Address:               mockTMDSIPv6Address,
 	}
 	err := engine.PreStart()
 	if err == nil {
@@ -576,6 +604,35 @@ func TestPrestartTMDSIpv6OnlyRouteNotCreated(t *testing.T) {
 	assert.EqualError(t, err, "could not create routes for task metadata server: some error")
 }
 
+func TestPrestartTMDSIPv6AddressNotAssigned(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockDocker := NewMockdockerClient(mockCtrl)
+	defer getDockerClientMock(mockDocker)()
+	mockDownloader := NewMockdownloader(mockCtrl)
+	mockLoopbackRouting := NewMockloopbackRouting(mockCtrl)
+	mockIpv6RouterAdvertisements := NewMockipv6RouterAdvertisements(mockCtrl)
+	mockIpv6RouterAdvertisements.EXPECT().Disable().Return(nil)
+
+	mockDocker.EXPECT().LoadEnvVars().Return(nil)
+	mockLoopbackRouting.EXPECT().Enable().Return(nil)
+	mockTMDSIPv6OnlyRoutes := NewMocktmdsRouteManagerForIPv6Only(mockCtrl)
+	mockTMDSIPv6OnlyRoutes.EXPECT().CreateRoute().Return(nil)
+	mockTMDSIPv6Address := NewMocktmdsIPv6AddressManager(mockCtrl)
+	mockTMDSIPv6Address.EXPECT().AssignAddress().Return(errors.New("some error"))
+
+	engine := &Engine{
+		downloader:                    mockDownloader,
+		loopbackRouting:               mockLoopbackRouting,
+		ipv6RouterAdvertisements:      mockIpv6RouterAdvertisements,
+		tmdsRoutesForIPv6OnlyInstance: mockTMDSIPv6OnlyRoutes,
+		tmdsIPv6Address:               mockTMDSIPv6Address,
+	}
+	err := engine.PreStart()
+	assert.EqualError(t, err, "could not assign IPv6 address for task metadata server: some error")
+}
+
 func TestPostStop(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -586,11 +643,14 @@ func TestPostStop(t *testing.T) {
 	mockRoute.EXPECT().Remove().Return(nil)
 	mockTMDSIPv6OnlyRoutes := NewMocktmdsRouteManagerForIPv6Only(mockCtrl)
 	mockTMDSIPv6OnlyRoutes.EXPECT().RemoveRoute().Return(nil)
+	mockTMDSIPv6Address := NewMocktmdsIPv6AddressManager(mockCtrl)
+	mockTMDSIPv6Address.EXPECT().RemoveAddress().Return(nil)
 
 	engine := &Engine{
 		loopbackRouting:               mockLoopbackRouting,
 		credentialsProxyRoute:         mockRoute,
 		tmdsRoutesForIPv6OnlyInstance: mockTMDSIPv6OnlyRoutes,
+		tmdsIPv6Address:               mockTMDSIPv6Address,
 	}
 	err := engine.PostStop()
 	if err != nil {
@@ -608,11 +668,14 @@ func lTestPostStopLoopbackRoutingError(t *testing.T) {
 	mockRoute.EXPECT().Remove().Return(nil)
 	mockTMDSIPv6OnlyRoutes := NewMocktmdsRouteManagerForIPv6Only(mockCtrl)
 	mockTMDSIPv6OnlyRoutes.EXPECT().RemoveRoute().Return(nil)
+	mockTMDSIPv6Address := NewMocktmdsIPv6AddressManager(mockCtrl)
+	mockTMDSIPv6Address.EXPECT().RemoveAddress().Return(nil)
 
 	engine := &Engine{
 		loopbackRouting:               mockLoopbackRouting,
 		credentialsProxyRoute:         mockRoute,
 		tmdsRoutesForIPv6OnlyInstance: mockTMDSIPv6OnlyRoutes,
+		tmdsIPv6Address:               mockTMDSIPv6Address,
 	}
 	err := engine.PostStop()
 	if err == nil {
@@ -630,11 +693,14 @@ func TestPostStopCredentialsProxyRouteRemoveError(t *testing.T) {
 	mockRoute.EXPECT().Remove().Return(fmt.Errorf("cannot remove"))
 	mockTMDSIPv6OnlyRoutes := NewMocktmdsRouteManagerForIPv6Only(mockCtrl)
 	mockTMDSIPv6OnlyRoutes.EXPECT().RemoveRoute().Return(nil)
+	mockTMDSIPv6Address := NewMocktmdsIPv6AddressManager(mockCtrl)
+	mockTMDSIPv6Address.EXPECT().RemoveAddress().Return(nil)
 
 	engine := &Engine{
 		loopbackRouting:               mockLoopbackRouting,
 		credentialsProxyRoute:         mockRoute,
 		tmdsRoutesForIPv6OnlyInstance: mockTMDSIPv6OnlyRoutes,
+		tmdsIPv6Address:               mockTMDSIPv6Address,
 	}
 	err := engine.PostStop()
 	if err != nil {
@@ -654,11 +720,14 @@ func TestPostStopTMDSIPv6OnlyRouteRemoveError(t *testing.T) {
 	mockRoute.EXPECT().Remove().Return(nil)
 	mockTMDSIPv6OnlyRoutes := NewMocktmdsRouteManagerForIPv6Only(mockCtrl)
 	mockTMDSIPv6OnlyRoutes.EXPECT().RemoveRoute().Return(errors.New("some error"))
+	mockTMDSIPv6Address := NewMocktmdsIPv6AddressManager(mockCtrl)
+	mockTMDSIPv6Address.EXPECT().RemoveAddress().Return(nil)
 
 	engine := &Engine{
 		loopbackRouting:               mockLoopbackRouting,
 		credentialsProxyRoute:         mockRoute,
 		tmdsRoutesForIPv6OnlyInstance: mockTMDSIPv6OnlyRoutes,
+		tmdsIPv6Address:               mockTMDSIPv6Address,
 	}
 	err := engine.PostStop()
 	if err != nil {
@@ -710,3 +779,61 @@ func TestPreStartAppArmorSetup(t *testing.T) {
 		})
 	}
 }
+
+func TestPostStopTMDSIPv6AddressRemoveError(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockLoopbackRouting := NewMockloopbackRouting(mockCtrl)
+	mockLoopbackRouting.EXPECT().RestoreDefault().Return(nil)
+	mockRoute := NewMockcredentialsProxyRoute(mockCtrl)
+	mockRoute.EXPECT().Remove().Return(nil)
+	mockTMDSIPv6OnlyRoutes := NewMocktmdsRouteManagerForIPv6Only(mockCtrl)
+	mockTMDSIPv6OnlyRoutes.EXPECT().RemoveRoute().Return(nil)
+	mockTMDSIPv6Address := NewMocktmdsIPv6AddressManager(mockCtrl)
+	mockTMDSIPv6Address.EXPECT().RemoveAddress().Return(errors.New("some error"))
+
+	engine := &Engine{
+		loopbackRouting:               mockLoopbackRouting,
+		credentialsProxyRoute:         mockRoute,
+		tmdsRoutesForIPv6OnlyInstance: mockTMDSIPv6OnlyRoutes,
+		tmdsIPv6Address:               mockTMDSIPv6Address,
+	}
+	err := engine.PostStop()
+	assert.NoError(t, err, "Errors removing the TMDS IPv6 address should be ignored")
+}
+
+func TestMountSecretFilesTmpfs(t *testing.T) {
+	mountTmpfsBkp := mountTmpfs
+	defer func() {
+		mountTmpfs = mountTmpfsBkp
+		statfs = unix.Statfs
+	}()
+
+	dir := t.TempDir() + "/secretfiles"
+	var fsType int64
+	statfs = func(path string, buf *unix.Statfs_t) error {
+		assert.Equal(t, dir, path)
+		buf.Type = fsType
+		return nil
+	}
+	mounted := false
+	mountTmpfs = func(source, target, fstype string, flags uintptr, data string) error {
+		assert.Equal(t, dir, target)
+		assert.Equal(t, "tmpfs", fstype)
+		assert.Equal(t, uintptr(unix.MS_NOSUID|unix.MS_NODEV|unix.MS_NOEXEC), flags)
+		assert.Equal(t, "size=128m,mode=0711", data)
+		mounted = true
+		return nil
+	}
+
+	assert.NoError(t, mountSecretFilesTmpfs(dir))
+	assert.DirExists(t, dir)
+	assert.True(t, mounted)
+
+	// The tmpfs that is still mounted from a previous run is kept along with its files.
+	mounted = false
+	fsType = unix.TMPFS_MAGIC
+	assert.NoError(t, mountSecretFilesTmpfs(dir))
+	assert.False(t, mounted)
+}
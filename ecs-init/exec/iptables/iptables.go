@@ -32,7 +32,9 @@ type iptablesAction string
 
 const (
 	iptablesExecutable            = "iptables"
+	ip6tablesExecutable           = "ip6tables"
 	CredentialsProxyIpAddress     = "169.254.170.2"
+	CredentialsProxyIPv6Address   = "fd00:ec2::23"
 	credentialsProxyPort          = "80"
 	localhostIpAddress            = "127.0.0.1"
 	localhostCredentialsProxyPort = "51679"
@@ -64,9 +66,11 @@ var (
 )
 
 // NetfilterRoute implements the engine.credentialsProxyRoute interface by
-// running the external 'iptables' command
+// running the external 'iptables' command, and the 'ip6tables' command for the
+// IPv6 credentials endpoint when it is available
 type NetfilterRoute struct {
-	cmdExec exec.Exec
+	cmdExec     exec.Exec
+	ipv6Enabled bool
 }
 
 // getNetfilterChainArgsFunc defines a function pointer type that returns
@@ -90,8 +94,17 @@ func NewNetfilterRoute(cmdExec exec.Exec) (*NetfilterRoute, error) {
 		defaultOffhostIntrospectionInterface = fallbackOffhostIntrospectionInterface
 	}
 
+	// The IPv6 credentials endpoint is only set up if 'ip6tables' can be found in the path
+	ipv6Enabled := true
+	if _, err := cmdExec.LookPath(ip6tablesExecutable); err != nil {
+		log.Warnf("Error searching '%s' executable, the credentials endpoint will not be available over IPv6: %v",
+			ip6tablesExecutable, err)
+		ipv6Enabled = false
+	}
+
 	return &NetfilterRoute{
-		cmdExec: cmdExec,
+		cmdExec:     cmdExec,
+		ipv6Enabled: ipv6Enabled,
 	}, nil
 }
 
@@ -116,7 +129,41 @@ func (route *NetfilterRoute) Create() error {
 		}
 	}
 
-	return route.modifyNetfilterEntry(iptablesTableNat, iptablesAppend, getOutputChainArgs)
+	err = route.modifyNetfilterEntry(iptablesTableNat, iptablesAppend, getOutputChainArgs)
+	if err != nil {
+		return err
+	}
+
+	if route.ipv6Enabled {
+		// The IPv4 endpoint is enough for most tasks, so failing to set up the IPv6 one
+		// (e.g. on hosts with IPv6 disabled) must not prevent the agent from starting
+		if err := route.createIPv6(); err != nil {
+			log.Errorf("Error creating IPv6 credentials proxy route: %v", err)
+		}
+	}
+	return nil
+}
+
+// createIPv6 creates the IPv6 credentials proxy endpoint route in the netfilter table
+func (route *NetfilterRoute) createIPv6() error {
+	err := route.modifyIPv6NetfilterEntry(iptablesTableNat, iptablesAppend, getIPv6PreroutingChainArgs)
+	if err != nil {
+		return err
+	}
+
+	err = route.modifyIPv6NetfilterEntry(iptablesTableFilter, iptablesInsert, getIPv6TrafficFilterInputChainArgs)
+	if err != nil {
+		return err
+	}
+
+	if !allowOffhostIntrospection() {
+		err = route.modifyIPv6NetfilterEntry(iptablesTableFilter, iptablesInsert, getBlockIntrospectionOffhostAccessInputChainArgs)
+		if err != nil {
+			log.Errorf("Error adding IPv6 input chain entry to block offhost introspection access: %v", err)
+		}
+	}
+
+	return route.modifyIPv6NetfilterEntry(iptablesTableNat, iptablesAppend, getIPv6OutputChainArgs)
 }
 
 // Remove removes the route for the credentials endpoint from the netfilter
@@ -147,7 +194,37 @@ func (route *NetfilterRoute) Remove() error {
 		outputErr = fmt.Errorf("error removing output chain entry: %v", outputErr)
 	}
 
-	return combinedError(preroutingErr, localhostInputError, introspectionInputError, outputErr)
+	var ipv6Err error
+	if route.ipv6Enabled {
+		ipv6Err = route.removeIPv6()
+	}
+
+	return combinedError(preroutingErr, localhostInputError, introspectionInputError, outputErr, ipv6Err)
+}
+
+// removeIPv6 removes the route for the IPv6 credentials endpoint from the netfilter table
+func (route *NetfilterRoute) removeIPv6() error {
+	preroutingErr := route.modifyIPv6NetfilterEntry(iptablesTableNat, iptablesDelete, getIPv6PreroutingChainArgs)
+	if preroutingErr != nil {
+		preroutingErr = fmt.Errorf("error removing IPv6 prerouting chain entry: %v", preroutingErr)
+	}
+
+	inputErr := route.modifyIPv6NetfilterEntry(iptablesTableFilter, iptablesDelete, getIPv6TrafficFilterInputChainArgs)
+	if inputErr != nil {
+		inputErr = fmt.Errorf("error removing IPv6 input chain entry: %v", inputErr)
+	}
+
+	introspectionInputErr := route.modifyIPv6NetfilterEntry(iptablesTableFilter, iptablesDelete, getBlockIntrospectionOffhostAccessInputChainArgs)
+	if introspectionInputErr != nil {
+		introspectionInputErr = fmt.Errorf("error removing IPv6 input chain entry: %v", introspectionInputErr)
+	}
+
+	outputErr := route.modifyIPv6NetfilterEntry(iptablesTableNat, iptablesDelete, getIPv6OutputChainArgs)
+	if outputErr != nil {
+		outputErr = fmt.Errorf("error removing IPv6 output chain entry: %v", outputErr)
+	}
+
+	return combinedError(preroutingErr, inputErr, introspectionInputErr, outputErr)
 }
 
 func combinedError(errs ...error) error {
@@ -169,12 +246,22 @@ func combinedError(errs ...error) error {
 // the action and the function pointer to get arguments for modifying the
 // chain
 func (route *NetfilterRoute) modifyNetfilterEntry(table string, action iptablesAction, getNetfilterChainArgs getNetfilterChainArgsFunc) error {
+	return route.runNetfilterCommand(iptablesExecutable, table, action, getNetfilterChainArgs)
+}
+
+// modifyIPv6NetfilterEntry is the equivalent of modifyNetfilterEntry for the
+// IPv6 netfilter table
+func (route *NetfilterRoute) modifyIPv6NetfilterEntry(table string, action iptablesAction, getNetfilterChainArgs getNetfilterChainArgsFunc) error {
+	return route.runNetfilterCommand(ip6tablesExecutable, table, action, getNetfilterChainArgs)
+}
+
+func (route *NetfilterRoute) runNetfilterCommand(executable string, table string, action iptablesAction, getNetfilterChainArgs getNetfilterChainArgsFunc) error {
 	args := append(getTableArgs(table), string(action))
 	args = append(args, getNetfilterChainArgs()...)
-	cmd := route.cmdExec.Command(iptablesExecutable, args...)
+	cmd := route.cmdExec.Command(executable, args...)
 	out, err := cmd.CombinedOutput()
 	if err != nil {
-		log.Errorf("Error performing action '%s' for iptables route: %v; raw output: %s", getActionName(action), err, out)
+		log.Errorf("Error performing action '%s' for %s route: %v; raw output: %s", getActionName(action), executable, err, out)
 	}
 
 	return err
@@ -195,6 +282,21 @@ func getPreroutingChainArgs() []string {
 	}
 }
 
+// getIPv6PreroutingChainArgs returns the arguments to redirect the IPv6 credentials
+// endpoint to the agent. Unlike IPv4, IPv6 does not allow routing to the loopback
+// address from other interfaces, so the traffic is sent to the endpoint address
+// itself, which is assigned to the loopback interface.
+func getIPv6PreroutingChainArgs() []string {
+	return []string{
+		"PREROUTING",
+		"-p", "tcp",
+		"-d", CredentialsProxyIPv6Address,
+		"--dport", credentialsProxyPort,
+		"-j", "DNAT",
+		"--to-destination", "[" + CredentialsProxyIPv6Address + "]:" + localhostCredentialsProxyPort,
+	}
+}
+
 func getLocalhostTrafficFilterInputChainArgs() []string {
 	return []string{
 		"INPUT",
@@ -206,6 +308,22 @@ func getLocalhostTrafficFilterInputChainArgs() []string {
 	}
 }
 
+// getIPv6TrafficFilterInputChainArgs returns the arguments to drop the traffic to the
+// agent on the IPv6 credentials endpoint address, unless it comes from the host or was
+// redirected to it
+func getIPv6TrafficFilterInputChainArgs() []string {
+	return []string{
+		"INPUT",
+		"-p", "tcp",
+		"-d", CredentialsProxyIPv6Address,
+		"--dport", localhostCredentialsProxyPort,
+		"!", "-i", loopbackInterfaceName,
+		"-m", "conntrack",
+		"!", "--ctstate", "RELATED,ESTABLISHED,DNAT",
+		"-j", "DROP",
+	}
+}
+
 func getBlockIntrospectionOffhostAccessInputChainArgs() []string {
 	return []string{
 		"INPUT",
@@ -296,6 +414,19 @@ func getOutputChainArgs() []string {
 	}
 }
 
+// getIPv6OutputChainArgs returns the arguments to redirect the IPv6 credentials endpoint
+// to the agent for tasks using the host network
+func getIPv6OutputChainArgs() []string {
+	return []string{
+		"OUTPUT",
+		"-p", "tcp",
+		"-d", CredentialsProxyIPv6Address,
+		"--dport", credentialsProxyPort,
+		"-j", "DNAT",
+		"--to-destination", "[" + CredentialsProxyIPv6Address + "]:" + localhostCredentialsProxyPort,
+	}
+}
+
 func getActionName(action iptablesAction) string {
 	switch action {
 	case iptablesAppend:
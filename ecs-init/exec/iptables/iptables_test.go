@@ -61,6 +61,29 @@ var (
 		"-j", "REDIRECT",
 		"--to-ports", localhostCredentialsProxyPort,
 	}
+	ipv6PreroutingRouteArgs = []string{
+		"-p", "tcp",
+		"-d", "fd00:ec2::23",
+		"--dport", "80",
+		"-j", "DNAT",
+		"--to-destination", "[fd00:ec2::23]:51679",
+	}
+	ipv6TrafficFilterInputRouteArgs = []string{
+		"-p", "tcp",
+		"-d", "fd00:ec2::23",
+		"--dport", "51679",
+		"!", "-i", "lo",
+		"-m", "conntrack",
+		"!", "--ctstate", "RELATED,ESTABLISHED,DNAT",
+		"-j", "DROP",
+	}
+	ipv6OutputRouteArgs = []string{
+		"-p", "tcp",
+		"-d", "fd00:ec2::23",
+		"--dport", "80",
+		"-j", "DNAT",
+		"--to-destination", "[fd00:ec2::23]:51679",
+	}
 
 	testIPV4RouteInput = `Iface	Destination	Gateway 	Flags	RefCnt	Use	Metric	Mask		MTU	Window	IRTT
 ens5	00000000	01201FAC	0003	0	0	0	00000000	0	0	0
@@ -187,6 +210,7 @@ func TestNewNetfilterRouteWithDefaultOffhostIntrospectionInterfaceFallback(t *te
 
 	mockExec := NewMockExec(ctrl)
 	mockExec.EXPECT().LookPath(iptablesExecutable).Return("", nil)
+	mockExec.EXPECT().LookPath(ip6tablesExecutable).Return("", testErr)
 
 	_, err := NewNetfilterRoute(mockExec)
 	assert.NoError(t, err)
@@ -222,6 +246,7 @@ func TestCreate(t *testing.T) {
 		mockExec := NewMockExec(ctrl)
 		gomock.InOrder(
 			mockExec.EXPECT().LookPath(iptablesExecutable).Return("", nil),
+			mockExec.EXPECT().LookPath(ip6tablesExecutable).Return("", testErr),
 			mockExec.EXPECT().Command(iptablesExecutable,
 				expectedArgs("nat", "-A", "PREROUTING", preroutingRouteArgs)).Return(mockCmd),
 			mockCmd.EXPECT().CombinedOutput().Return([]byte{0}, nil),
@@ -257,6 +282,7 @@ func TestCreateSkipLocalTrafficFilter(t *testing.T) {
 	mockExec := NewMockExec(ctrl)
 	gomock.InOrder(
 		mockExec.EXPECT().LookPath(iptablesExecutable).Return("", nil),
+		mockExec.EXPECT().LookPath(ip6tablesExecutable).Return("", testErr),
 		mockExec.EXPECT().Command(iptablesExecutable,
 			expectedArgs("nat", "-A", "PREROUTING", preroutingRouteArgs)).Return(mockCmd),
 		mockCmd.EXPECT().CombinedOutput().Return([]byte{0}, nil),
@@ -286,6 +312,7 @@ func TestCreateAllowOffhostIntrospectionAccess(t *testing.T) {
 	mockExec := NewMockExec(ctrl)
 	gomock.InOrder(
 		mockExec.EXPECT().LookPath(iptablesExecutable).Return("", nil),
+		mockExec.EXPECT().LookPath(ip6tablesExecutable).Return("", testErr),
 		mockExec.EXPECT().Command(iptablesExecutable,
 			expectedArgs("nat", "-A", "PREROUTING", preroutingRouteArgs)).Return(mockCmd),
 		mockCmd.EXPECT().CombinedOutput().Return([]byte{0}, nil),
@@ -312,6 +339,7 @@ func TestCreateErrorOnPreRoutingCommandError(t *testing.T) {
 	mockExec := NewMockExec(ctrl)
 	gomock.InOrder(
 		mockExec.EXPECT().LookPath(iptablesExecutable).Return("", nil),
+		mockExec.EXPECT().LookPath(ip6tablesExecutable).Return("", testErr),
 		mockExec.EXPECT().Command(iptablesExecutable,
 			expectedArgs("nat", "-A", "PREROUTING", preroutingRouteArgs)).Return(mockCmd),
 		// Mock a failed execution of the iptables command to create the route
@@ -333,6 +361,7 @@ func TestCreateErrorOnInputChainCommandError(t *testing.T) {
 	mockExec := NewMockExec(ctrl)
 	gomock.InOrder(
 		mockExec.EXPECT().LookPath(iptablesExecutable).Return("", nil),
+		mockExec.EXPECT().LookPath(ip6tablesExecutable).Return("", testErr),
 		mockExec.EXPECT().Command(iptablesExecutable,
 			expectedArgs("nat", "-A", "PREROUTING", preroutingRouteArgs)).Return(mockCmd),
 		mockCmd.EXPECT().CombinedOutput().Return([]byte{0}, nil),
@@ -357,6 +386,7 @@ func TestCreateErrorOnOutputChainCommandError(t *testing.T) {
 	mockExec := NewMockExec(ctrl)
 	gomock.InOrder(
 		mockExec.EXPECT().LookPath(iptablesExecutable).Return("", nil),
+		mockExec.EXPECT().LookPath(ip6tablesExecutable).Return("", testErr),
 		mockExec.EXPECT().Command(iptablesExecutable,
 			expectedArgs("nat", "-A", "PREROUTING", preroutingRouteArgs)).Return(mockCmd),
 		mockCmd.EXPECT().CombinedOutput().Return([]byte{0}, nil),
@@ -388,6 +418,7 @@ func TestRemove(t *testing.T) {
 	mockExec := NewMockExec(ctrl)
 	gomock.InOrder(
 		mockExec.EXPECT().LookPath(iptablesExecutable).Return("", nil),
+		mockExec.EXPECT().LookPath(ip6tablesExecutable).Return("", testErr),
 		mockExec.EXPECT().Command(iptablesExecutable,
 			expectedArgs("nat", "-D", "PREROUTING", preroutingRouteArgs)).Return(mockCmd),
 		// Mock a successful execution of the iptables command to delete the
@@ -423,6 +454,7 @@ func TestRemoveSkipLocalTrafficFilter(t *testing.T) {
 	mockExec := NewMockExec(ctrl)
 	gomock.InOrder(
 		mockExec.EXPECT().LookPath(iptablesExecutable).Return("", nil),
+		mockExec.EXPECT().LookPath(ip6tablesExecutable).Return("", testErr),
 		mockExec.EXPECT().Command(iptablesExecutable,
 			expectedArgs("nat", "-D", "PREROUTING", preroutingRouteArgs)).Return(mockCmd),
 		mockCmd.EXPECT().CombinedOutput().Return([]byte{0}, nil),
@@ -453,6 +485,7 @@ func TestRemoveAllowIntrospectionOffhostAccess(t *testing.T) {
 	mockExec := NewMockExec(ctrl)
 	gomock.InOrder(
 		mockExec.EXPECT().LookPath(iptablesExecutable).Return("", nil),
+		mockExec.EXPECT().LookPath(ip6tablesExecutable).Return("", testErr),
 		mockExec.EXPECT().Command(iptablesExecutable,
 			expectedArgs("nat", "-D", "PREROUTING", preroutingRouteArgs)).Return(mockCmd),
 		mockCmd.EXPECT().CombinedOutput().Return([]byte{0}, nil),
@@ -483,6 +516,7 @@ func TestRemoveErrorOnPreroutingChainCommandError(t *testing.T) {
 	mockExec := NewMockExec(ctrl)
 	gomock.InOrder(
 		mockExec.EXPECT().LookPath(iptablesExecutable).Return("", nil),
+		mockExec.EXPECT().LookPath(ip6tablesExecutable).Return("", testErr),
 		mockExec.EXPECT().Command(iptablesExecutable,
 			expectedArgs("nat", "-D", "PREROUTING", preroutingRouteArgs)).Return(mockCmd),
 		// Mock a failed execution of the iptables command to delete the route
@@ -514,6 +548,7 @@ func TestRemoveErrorOnOutputChainCommandError(t *testing.T) {
 	mockExec := NewMockExec(ctrl)
 	gomock.InOrder(
 		mockExec.EXPECT().LookPath(iptablesExecutable).Return("", nil),
+		mockExec.EXPECT().LookPath(ip6tablesExecutable).Return("", testErr),
 		mockExec.EXPECT().Command(iptablesExecutable,
 			expectedArgs("nat", "-D", "PREROUTING", preroutingRouteArgs)).Return(mockCmd),
 		mockCmd.EXPECT().CombinedOutput().Return([]byte{0}, nil),
@@ -545,6 +580,7 @@ func TestRemoveErrorOnInputChainCommandsErrors(t *testing.T) {
 	mockExec := NewMockExec(ctrl)
 	gomock.InOrder(
 		mockExec.EXPECT().LookPath(iptablesExecutable).Return("", nil),
+		mockExec.EXPECT().LookPath(ip6tablesExecutable).Return("", testErr),
 		mockExec.EXPECT().Command(iptablesExecutable,
 			expectedArgs("nat", "-D", "PREROUTING", preroutingRouteArgs)).Return(mockCmd),
 		mockCmd.EXPECT().CombinedOutput().Return([]byte{0}, nil),
@@ -566,6 +602,131 @@ func TestRemoveErrorOnInputChainCommandsErrors(t *testing.T) {
 	assert.Error(t, err, "Expected error removing route")
 }
 
+// expectIPv4Create sets up the expectations for creating the IPv4 credentials proxy route
+func expectIPv4Create(mockExec *MockExec, mockCmd *MockCmd) []*gomock.Call {
+	return []*gomock.Call{
+		mockExec.EXPECT().Command(iptablesExecutable,
+			expectedArgs("nat", "-A", "PREROUTING", preroutingRouteArgs)).Return(mockCmd),
+		mockCmd.EXPECT().CombinedOutput().Return([]byte{0}, nil),
+		mockExec.EXPECT().Command(iptablesExecutable,
+			expectedArgs("filter", "-I", "INPUT", localhostTrafficFilterInputRouteArgs)).Return(mockCmd),
+		mockCmd.EXPECT().CombinedOutput().Return([]byte{0}, nil),
+		mockExec.EXPECT().Command(iptablesExecutable,
+			expectedArgs("filter", "-I", "INPUT", blockIntrospectionOffhostAccessInputRouteArgs)).Return(mockCmd),
+		mockCmd.EXPECT().CombinedOutput().Return([]byte{0}, nil),
+		mockExec.EXPECT().Command(iptablesExecutable,
+			expectedArgs("nat", "-A", "OUTPUT", outputRouteArgs)).Return(mockCmd),
+		mockCmd.EXPECT().CombinedOutput().Return([]byte{0}, nil),
+	}
+}
+
+func TestCreateIPv6(t *testing.T) {
+	defer overrideIPRouteInput(testIPV4RouteInput)()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCmd := NewMockCmd(ctrl)
+	mockExec := NewMockExec(ctrl)
+	calls := []*gomock.Call{
+		mockExec.EXPECT().LookPath(iptablesExecutable).Return("", nil),
+		mockExec.EXPECT().LookPath(ip6tablesExecutable).Return("", nil),
+	}
+	calls = append(calls, expectIPv4Create(mockExec, mockCmd)...)
+	calls = append(calls,
+		mockExec.EXPECT().Command(ip6tablesExecutable,
+			expectedArgs("nat", "-A", "PREROUTING", ipv6PreroutingRouteArgs)).Return(mockCmd),
+		mockCmd.EXPECT().CombinedOutput().Return([]byte{0}, nil),
+		mockExec.EXPECT().Command(ip6tablesExecutable,
+			expectedArgs("filter", "-I", "INPUT", ipv6TrafficFilterInputRouteArgs)).Return(mockCmd),
+		mockCmd.EXPECT().CombinedOutput().Return([]byte{0}, nil),
+		mockExec.EXPECT().Command(ip6tablesExecutable,
+			expectedArgs("filter", "-I", "INPUT", blockIntrospectionOffhostAccessInputRouteArgs)).Return(mockCmd),
+		mockCmd.EXPECT().CombinedOutput().Return([]byte{0}, nil),
+		mockExec.EXPECT().Command(ip6tablesExecutable,
+			expectedArgs("nat", "-A", "OUTPUT", ipv6OutputRouteArgs)).Return(mockCmd),
+		mockCmd.EXPECT().CombinedOutput().Return([]byte{0}, nil),
+	)
+	gomock.InOrder(calls...)
+
+	route, err := NewNetfilterRoute(mockExec)
+	require.NoError(t, err, "Error creating netfilter route object")
+
+	err = route.Create()
+	assert.NoError(t, err, "Error creating route")
+}
+
+func TestCreateIPv6ErrorIgnored(t *testing.T) {
+	defer overrideIPRouteInput(testIPV4RouteInput)()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCmd := NewMockCmd(ctrl)
+	mockExec := NewMockExec(ctrl)
+	calls := []*gomock.Call{
+		mockExec.EXPECT().LookPath(iptablesExecutable).Return("", nil),
+		mockExec.EXPECT().LookPath(ip6tablesExecutable).Return("", nil),
+	}
+	calls = append(calls, expectIPv4Create(mockExec, mockCmd)...)
+	calls = append(calls,
+		mockExec.EXPECT().Command(ip6tablesExecutable,
+			expectedArgs("nat", "-A", "PREROUTING", ipv6PreroutingRouteArgs)).Return(mockCmd),
+		// Mock a host with IPv6 disabled
+		mockCmd.EXPECT().CombinedOutput().Return([]byte{0}, testErr),
+	)
+	gomock.InOrder(calls...)
+
+	route, err := NewNetfilterRoute(mockExec)
+	require.NoError(t, err, "Error creating netfilter route object")
+
+	err = route.Create()
+	assert.NoError(t, err, "IPv6 route errors should not fail the creation of the route")
+}
+
+func TestRemoveIPv6(t *testing.T) {
+	defer overrideIPRouteInput(testIPV4RouteInput)()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCmd := NewMockCmd(ctrl)
+	mockExec := NewMockExec(ctrl)
+	gomock.InOrder(
+		mockExec.EXPECT().LookPath(iptablesExecutable).Return("", nil),
+		mockExec.EXPECT().LookPath(ip6tablesExecutable).Return("", nil),
+		mockExec.EXPECT().Command(iptablesExecutable,
+			expectedArgs("nat", "-D", "PREROUTING", preroutingRouteArgs)).Return(mockCmd),
+		mockCmd.EXPECT().CombinedOutput().Return([]byte{0}, nil),
+		mockExec.EXPECT().Command(iptablesExecutable,
+			expectedArgs("filter", "-D", "INPUT", localhostTrafficFilterInputRouteArgs)).Return(mockCmd),
+		mockCmd.EXPECT().CombinedOutput().Return([]byte{0}, nil),
+		mockExec.EXPECT().Command(iptablesExecutable,
+			expectedArgs("filter", "-D", "INPUT", blockIntrospectionOffhostAccessInputRouteArgs)).Return(mockCmd),
+		mockCmd.EXPECT().CombinedOutput().Return([]byte{0}, nil),
+		mockExec.EXPECT().Command(iptablesExecutable,
+			expectedArgs("nat", "-D", "OUTPUT", outputRouteArgs)).Return(mockCmd),
+		mockCmd.EXPECT().CombinedOutput().Return([]byte{0}, nil),
+		mockExec.EXPECT().Command(ip6tablesExecutable,
+			expectedArgs("nat", "-D", "PREROUTING", ipv6PreroutingRouteArgs)).Return(mockCmd),
+		mockCmd.EXPECT().CombinedOutput().Return([]byte{0}, nil),
+		mockExec.EXPECT().Command(ip6tablesExecutable,
+			expectedArgs("filter", "-D", "INPUT", ipv6TrafficFilterInputRouteArgs)).Return(mockCmd),
+		mockCmd.EXPECT().CombinedOutput().Return([]byte{0}, nil),
+		mockExec.EXPECT().Command(ip6tablesExecutable,
+			expectedArgs("filter", "-D", "INPUT", blockIntrospectionOffhostAccessInputRouteArgs)).Return(mockCmd),
+		mockCmd.EXPECT().CombinedOutput().Return([]byte{0}, nil),
+		mockExec.EXPECT().Command(ip6tablesExecutable,
+			expectedArgs("nat", "-D", "OUTPUT", ipv6OutputRouteArgs)).Return(mockCmd),
+		// Mock a failed execution of the ip6tables command to delete the route
+		mockCmd.EXPECT().CombinedOutput().Return([]byte{0}, testErr),
+	)
+
+	route, err := NewNetfilterRoute(mockExec)
+	require.NoError(t, err, "Error creating netfilter route object")
+
+	err = route.Remove()
+	require.Error(t, err, "Expected error removing route")
+	assert.Contains(t, err.Error(), "error removing IPv6 output chain entry")
+}
+
 func TestCombinedError(t *testing.T) {
 	err1 := errors.New("err1")
 	err2 := errors.New("err2")
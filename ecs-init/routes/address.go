@@ -0,0 +1,103 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+
+	netutils "github.com/aws/amazon-ecs-agent/ecs-agent/utils/net"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/netlinkwrapper"
+	"github.com/cihub/seelog"
+
+	"github.com/vishvananda/netlink"
+)
+
+// Manages the IPv6 address of TMDS on hosts with IPv6 connectivity.
+//
+// Linux does not route traffic from other interfaces to the IPv6 loopback address, so the
+// traffic of tasks to TMDS over IPv6 cannot be redirected to it like it is done for IPv4.
+// Instead, the TMDS IPv6 address is assigned to the loopback interface and the agent listens
+// on it directly.
+type TMDSIPv6AddressManager struct {
+	nl       netlinkwrapper.NetLink
+	tmdsAddr net.IP
+}
+
+func NewTMDSIPv6AddressManager(tmdsAddr string) (*TMDSIPv6AddressManager, error) {
+	addr := net.ParseIP(tmdsAddr)
+	if addr == nil || addr.To4() != nil {
+		return nil, fmt.Errorf("invalid IPv6 address: %s", tmdsAddr)
+	}
+	return &TMDSIPv6AddressManager{nl: netlinkwrapper.New(), tmdsAddr: addr}, nil
+}
+
+// Assigns the TMDS IPv6 address to the loopback interface.
+// No-op on instances without default IPv6 routes.
+func (t *TMDSIPv6AddressManager) AssignAddress() error {
+	hasIPv6DefaultRoutes, err := netutils.HasDefaultRoute(t.nl, nil, netlink.FAMILY_V6)
+	if err != nil {
+		return fmt.Errorf("error when looking up IPv6 default routes: %w", err)
+	}
+	if !hasIPv6DefaultRoutes {
+		// Host has no IPv6 connectivity
+		return nil
+	}
+
+	lo, err := netutils.GetLoopbackInterface(t.nl)
+	if err != nil {
+		return fmt.Errorf("error getting lo interface: %v", err)
+	}
+
+	seelog.Infof("Detected IPv6 instance: assigning TMDS address %s to loopback", t.tmdsAddr)
+	addr := t.loopbackAddr()
+	if err := t.nl.AddrAdd(lo, addr); err != nil {
+		if os.IsExist(err) {
+			seelog.Infof("Address %s already assigned to loopback", t.tmdsAddr)
+			return nil
+		}
+		return fmt.Errorf("error adding address %s to lo: %w", addr, err)
+	}
+	return nil
+}
+
+// Removes the address assigned by AssignAddress, if any.
+func (t *TMDSIPv6AddressManager) RemoveAddress() error {
+	lo, err := netutils.GetLoopbackInterface(t.nl)
+	if err != nil {
+		return fmt.Errorf("error getting lo interface: %v", err)
+	}
+
+	addr := t.loopbackAddr()
+	if err := t.nl.AddrDel(lo, addr); err != nil {
+		if errors.Is(err, syscall.EADDRNOTAVAIL) {
+			// Address was never assigned
+			return nil
+		}
+		return fmt.Errorf("error deleting address %s from lo: %w", addr, err)
+	}
+	return nil
+}
+
+func (t *TMDSIPv6AddressManager) loopbackAddr() *netlink.Addr {
+	return &netlink.Addr{
+		IPNet: &net.IPNet{
+			IP:   t.tmdsAddr,
+			Mask: net.CIDRMask(128, 128),
+		},
+	}
+}
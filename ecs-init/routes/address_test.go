@@ -0,0 +1,163 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package routes
+
+import (
+	"net"
+	"os"
+	"syscall"
+	"testing"
+
+	mock_netlinkwrapper "github.com/aws/amazon-ecs-agent/ecs-agent/utils/netlinkwrapper/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vishvananda/netlink"
+)
+
+const testTMDSIPv6Addr = "fd00:ec2::23"
+
+func TestNewTMDSIPv6AddressManager(t *testing.T) {
+	_, err := NewTMDSIPv6AddressManager(testTMDSIPv6Addr)
+	assert.NoError(t, err)
+
+	_, err = NewTMDSIPv6AddressManager("169.254.170.2")
+	assert.EqualError(t, err, "invalid IPv6 address: 169.254.170.2")
+
+	_, err = NewTMDSIPv6AddressManager("invalid")
+	assert.EqualError(t, err, "invalid IPv6 address: invalid")
+}
+
+func TestAssignAddress(t *testing.T) {
+	loLink := &netlink.Dummy{LinkAttrs: netlink.LinkAttrs{Flags: net.FlagLoopback}}
+	expectedAddr := &netlink.Addr{
+		IPNet: &net.IPNet{
+			IP:   net.ParseIP(testTMDSIPv6Addr),
+			Mask: net.CIDRMask(128, 128),
+		},
+	}
+	ipv6DefaultRoute := netlink.Route{Dst: nil, Gw: net.ParseIP("2600:1f14::1")}
+
+	testCases := []struct {
+		name        string
+		mockSetup   func(*mock_netlinkwrapper.MockNetLink)
+		expectedErr string
+	}{
+		{
+			name: "success - address assigned",
+			mockSetup: func(mock *mock_netlinkwrapper.MockNetLink) {
+				mock.EXPECT().RouteList(nil, netlink.FAMILY_V6).Return([]netlink.Route{ipv6DefaultRoute}, nil)
+				mock.EXPECT().LinkList().Return([]netlink.Link{loLink}, nil)
+				mock.EXPECT().AddrAdd(loLink, expectedAddr).Return(nil)
+			},
+		},
+		{
+			name: "success - address already assigned",
+			mockSetup: func(mock *mock_netlinkwrapper.MockNetLink) {
+				mock.EXPECT().RouteList(nil, netlink.FAMILY_V6).Return([]netlink.Route{ipv6DefaultRoute}, nil)
+				mock.EXPECT().LinkList().Return([]netlink.Link{loLink}, nil)
+				mock.EXPECT().AddrAdd(loLink, expectedAddr).Return(os.ErrExist)
+			},
+		},
+		{
+			name: "success - host without IPv6 routes, no-op",
+			mockSetup: func(mock *mock_netlinkwrapper.MockNetLink) {
+				mock.EXPECT().RouteList(nil, netlink.FAMILY_V6).Return([]netlink.Route{}, nil)
+			},
+		},
+		{
+			name: "error - failed to check IPv6 routes",
+			mockSetup: func(mock *mock_netlinkwrapper.MockNetLink) {
+				mock.EXPECT().RouteList(nil, netlink.FAMILY_V6).Return(nil, assert.AnError)
+			},
+			expectedErr: "error when looking up IPv6 default routes: failed to list routes: " + assert.AnError.Error(),
+		},
+		{
+			name: "error - failed to add address",
+			mockSetup: func(mock *mock_netlinkwrapper.MockNetLink) {
+				mock.EXPECT().RouteList(nil, netlink.FAMILY_V6).Return([]netlink.Route{ipv6DefaultRoute}, nil)
+				mock.EXPECT().LinkList().Return([]netlink.Link{loLink}, nil)
+				mock.EXPECT().AddrAdd(loLink, expectedAddr).Return(assert.AnError)
+			},
+			expectedErr: "error adding address fd00:ec2::23/128 to lo: " + assert.AnError.Error(),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockNetLink := mock_netlinkwrapper.NewMockNetLink(ctrl)
+			tc.mockSetup(mockNetLink)
+
+			manager, err := NewTMDSIPv6AddressManager(testTMDSIPv6Addr)
+			require.NoError(t, err)
+			manager.nl = mockNetLink
+
+			err = manager.AssignAddress()
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRemoveAddress(t *testing.T) {
+	loLink := &netlink.Dummy{LinkAttrs: netlink.LinkAttrs{Flags: net.FlagLoopback}}
+
+	testCases := []struct {
+		name        string
+		delErr      error
+		expectedErr string
+	}{
+		{
+			name: "success - address removed",
+		},
+		{
+			name:   "success - address was not assigned",
+			delErr: syscall.EADDRNOTAVAIL,
+		},
+		{
+			name:        "error - failed to delete address",
+			delErr:      assert.AnError,
+			expectedErr: "error deleting address fd00:ec2::23/128 from lo: " + assert.AnError.Error(),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockNetLink := mock_netlinkwrapper.NewMockNetLink(ctrl)
+			mockNetLink.EXPECT().LinkList().Return([]netlink.Link{loLink}, nil)
+			mockNetLink.EXPECT().AddrDel(loLink, gomock.Any()).Return(tc.delErr)
+
+			manager, err := NewTMDSIPv6AddressManager(testTMDSIPv6Addr)
+			require.NoError(t, err)
+			manager.nl = mockNetLink
+
+			err = manager.RemoveAddress()
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}